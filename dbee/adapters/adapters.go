@@ -14,7 +14,10 @@ var (
 	ErrUnsupportedTypeAlias = errors.New("no driver registered for provided type alias")
 )
 
-var _ core.Adapter = (*wrappedAdapter)(nil)
+var (
+	_ core.Adapter   = (*wrappedAdapter)(nil)
+	_ core.Validator = (*wrappedAdapter)(nil)
+)
 
 // wrappedAdapter is returned from Mux and adds extra helpers to internal adapter.
 type wrappedAdapter struct {
@@ -95,6 +98,16 @@ func (wa *wrappedAdapter) Connect(url string) (core.Driver, error) {
 	return wa.adapter.Connect(url)
 }
 
+// Validate validates the url with the internal adapter.
+// Urls of adapters which don't implement core.Validator are always valid.
+func (wa *wrappedAdapter) Validate(url string) error {
+	validator, ok := wa.adapter.(core.Validator)
+	if !ok {
+		return nil
+	}
+	return validator.Validate(url)
+}
+
 func (wa *wrappedAdapter) GetHelpers(opts *core.TableOptions) map[string]string {
 	helpers := wa.adapter.GetHelpers(opts)
 	if helpers == nil {
@@ -130,3 +143,120 @@ func NewConnection(params *core.ConnectionParams) (*core.Connection, error) {
 
 	return c, nil
 }
+
+// ValidateConnection validates the url of connection params without connecting.
+func ValidateConnection(params *core.ConnectionParams) error {
+	expanded := params.Expand()
+
+	adapter, err := new(Mux).GetAdapter(expanded.Type)
+	if err != nil {
+		return fmt.Errorf("Mux.GetAdapters: %w", err)
+	}
+
+	if validator, ok := adapter.(core.Validator); ok {
+		return validator.Validate(expanded.URL)
+	}
+	return nil
+}
+
+// foreignKeysFromResultStream converts the result stream to foreign keys.
+// A result stream should return rows that are 5 columns wide and
+// have the following structure:
+//
+//	constraint name, column, referenced schema, referenced table, referenced column
+func foreignKeysFromResultStream(rows core.ResultStream) ([]*core.ForeignKey, error) {
+	var fks []*core.ForeignKey
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 5 {
+			return nil, errors.New("could not retrieve foreign keys: insufficient info")
+		}
+
+		str := func(v any) string {
+			if v == nil {
+				return ""
+			}
+			return fmt.Sprint(v)
+		}
+
+		fks = append(fks, &core.ForeignKey{
+			Name:             str(row[0]),
+			Column:           str(row[1]),
+			ReferencedSchema: str(row[2]),
+			ReferencedTable:  str(row[3]),
+			ReferencedColumn: str(row[4]),
+		})
+	}
+
+	return fks, nil
+}
+
+// uniqueKeysFromResultStream groups rows of key names and their columns (in order)
+// into the columns of each key.
+func uniqueKeysFromResultStream(rows core.ResultStream) ([][]string, error) {
+	defer rows.Close()
+
+	var keys [][]string
+	last := ""
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 2 {
+			return nil, errors.New("could not retrieve unique keys: insufficient info")
+		}
+
+		name, column := fmt.Sprint(row[0]), fmt.Sprint(row[1])
+		if len(keys) == 0 || name != last {
+			keys = append(keys, nil)
+			last = name
+		}
+		keys[len(keys)-1] = append(keys[len(keys)-1], column)
+	}
+
+	return keys, nil
+}
+
+// tableSizeFromResultStream reads the table size from the first row of a query
+// returning total, data, index and overhead sizes in bytes.
+func tableSizeFromResultStream(rows core.ResultStream) (*core.TableSize, error) {
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return nil, errors.New("could not retrieve table size: table not found")
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return core.TableSizeFromRow(row)
+}
+
+// sequencesFromResultStream converts the result stream to sequences.
+// See core.SequenceFromRow for the structure of rows.
+func sequencesFromResultStream(rows core.ResultStream) ([]*core.Sequence, error) {
+	defer rows.Close()
+
+	var sequences []*core.Sequence
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := core.SequenceFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		sequences = append(sequences, s)
+	}
+
+	return sequences, nil
+}
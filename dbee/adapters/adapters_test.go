@@ -0,0 +1,45 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestValidateConnection(t *testing.T) {
+	type testCase struct {
+		typ           string
+		url           string
+		expectedError string
+	}
+
+	testCases := []testCase{
+		{typ: "bigtable", url: "bigtable://project/instance"},
+		{typ: "bigtable", url: "bigtable://project", expectedError: "bigtable://project/instance"},
+		{typ: "bigtable", url: "bigquery://project/instance", expectedError: "unexpected scheme"},
+		{typ: "surrealdb", url: "surrealdb://localhost:8000/ns/db?auth=database"},
+		{typ: "surrealdb", url: "surrealdb://localhost:8000/ns", expectedError: "namespace/database"},
+		{typ: "surrealdb", url: "surrealdb://localhost:8000/ns/db?auth=scope", expectedError: "unknown auth level"},
+		{typ: "mysql_cdc", url: "user:pass@tcp(localhost:3306)/?server_id=100"},
+		{typ: "mysql_cdc", url: "user:pass@tcp(localhost:3306)/", expectedError: "server_id"},
+		{typ: "mysql_cdc", url: "user:pass@tcp(localhost:3306)/?server_id=abc", expectedError: "server_id"},
+		{typ: "iceberg", url: "iceberg://localhost:8181?warehouse=lake&engine=surrealdb&engine-url=surrealdb://localhost/ns/db"},
+		{typ: "iceberg", url: "iceberg://localhost:8181?engine=unknown", expectedError: "no driver registered"},
+		{typ: "iceberg", url: "iceberg://localhost:8181?engine=surrealdb&engine-url=surrealdb://localhost", expectedError: "engine-url"},
+		{typ: "iceberg", url: "iceberg://localhost:8181?oauth-token-url=http://auth", expectedError: "oauth"},
+		// adapters without validation accept any url
+		{typ: "redis", url: "anything"},
+		{typ: "unknown", url: "anything", expectedError: "no driver registered"},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateConnection(&core.ConnectionParams{Type: tc.typ, URL: tc.url})
+		if tc.expectedError == "" {
+			require.NoError(t, err, tc.url)
+			continue
+		}
+		require.ErrorContains(t, err, tc.expectedError, tc.url)
+	}
+}
@@ -37,9 +37,13 @@ type BigQuery struct{}
 //   - enable-storage-read=true|false
 //   - use-legacy-sql=true|false
 //   - location=google-cloud-location
+//   - oauth-token-url=url, oauth-client-id=id, oauth-client-secret=secret,
+//     oauth-refresh-token=token and oauth-scopes=scope1,scope2 (see newOAuthTokenSource)
 //
 // If credentials are not explicitly specified, credentials will attempt
 // to be located according to the Google Default Credentials process.
+// Tokens obtained with oauth parameters are refreshed when they expire or
+// when a request is rejected as unauthorized.
 func (bq *BigQuery) Connect(rawURL string) (core.Driver, error) {
 	ctx := context.TODO()
 
@@ -66,6 +70,21 @@ func (bq *BigQuery) Connect(rawURL string) (core.Driver, error) {
 		return nil
 	})
 
+	oauth, err := newOAuthTokenSource(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// storage read client uses grpc, which doesn't accept a custom http client
+	storageOptions := options
+	if oauth != nil {
+		if params.Has("credentials") {
+			return nil, fmt.Errorf("%q can't be used together with oauth parameters", "credentials")
+		}
+		storageOptions = append(options[:len(options):len(options)], option.WithTokenSource(oauth))
+		options = append(options, option.WithHTTPClient(oauth.client()))
+	}
+
 	bqc, err := bigquery.NewClient(ctx, u.Host, options...)
 	if err != nil {
 		return nil, err
@@ -90,7 +109,7 @@ func (bq *BigQuery) Connect(rawURL string) (core.Driver, error) {
 	}
 
 	if err := callIfBoolSet("enable-storage-read", params, func() error {
-		return client.c.EnableStorageReadClient(ctx, options...)
+		return client.c.EnableStorageReadClient(ctx, storageOptions...)
 	}, nil); err != nil {
 		return nil, err
 	}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -13,10 +15,17 @@ import (
 	"google.golang.org/api/iterator"
 )
 
-var _ core.Driver = (*bigQueryDriver)(nil)
+var (
+	_ core.DatabaseSwitcher = (*bigQueryDriver)(nil)
+	_ core.Driver           = (*bigQueryDriver)(nil)
+	_ core.Limiter          = (*bigQueryDriver)(nil)
+	_ core.TopValuer        = (*bigQueryDriver)(nil)
+)
 
 type bigQueryDriver struct {
-	c                 *bigquery.Client
+	c *bigquery.Client
+	// dataset is the default dataset of unqualified table names in queries
+	dataset           string
 	location          string
 	maxBytesBilled    int64
 	disableQueryCache bool
@@ -29,6 +38,10 @@ func (c *bigQueryDriver) Query(ctx context.Context, queryStr string) (core.Resul
 	query.MaxBytesBilled = c.maxBytesBilled
 	query.UseLegacySQL = c.useLegacySQL
 	query.Location = c.location
+	if c.dataset != "" {
+		query.DefaultProjectID = c.c.Project()
+		query.DefaultDatasetID = c.dataset
+	}
 
 	iter, err := query.Read(ctx)
 	if err != nil {
@@ -37,13 +50,17 @@ func (c *bigQueryDriver) Query(ctx context.Context, queryStr string) (core.Resul
 
 	// schema isn't available until the first call to iter.Next()
 	var firstRowLoader bigqueryRowLoader
+	hasNext := true
 	if err := iter.Next(&firstRowLoader); err != nil {
-		return nil, err
+		if !errors.Is(err, iterator.Done) {
+			return nil, err
+		}
+		// statements and queries without rows
+		hasNext = false
 	}
 
 	header := c.buildHeader("", iter.Schema)
 
-	hasNext := true
 	nextFn := func() (core.Row, error) {
 		if firstRowLoader.row != nil {
 			row := firstRowLoader.row
@@ -85,56 +102,152 @@ func (c *bigQueryDriver) Columns(opts *core.TableOptions) ([]*core.Column, error
 	return builders.ColumnsFromResultStream(result)
 }
 
+// Structure lists tables of every dataset of the project. Tables are read from the
+// region-level INFORMATION_SCHEMA.TABLES with a single query per region, which is the
+// location of the connection or the locations of the datasets.
 func (c *bigQueryDriver) Structure() (layouts []*core.Structure, err error) {
 	ctx := context.TODO()
 
-	datasetsIter := c.c.Datasets(ctx)
-	for {
-		dataset, err := datasetsIter.Next()
+	datasets, err := c.listDatasets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := c.datasetRegions(ctx, datasets)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]*core.Structure, len(datasets))
+	for _, region := range regions {
+		query := fmt.Sprintf("SELECT table_schema, table_name, table_type FROM `%s`.`region-%s`.INFORMATION_SCHEMA.TABLES ORDER BY table_schema, table_name", c.c.Project(), strings.ToLower(region))
+
+		rows, err := c.Query(ctx, query)
 		if err != nil {
-			if !errors.Is(err, iterator.Done) {
+			return nil, err
+		}
+
+		for rows.HasNext() {
+			row, err := rows.Next()
+			if err != nil {
+				if errors.Is(err, iterator.Done) {
+					break
+				}
 				return nil, err
 			}
 
-			break
+			// We know for a fact there are 3 string fields (see query above)
+			dataset := row[0].(string)
+			children[dataset] = append(children[dataset], &core.Structure{
+				Name:   row[1].(string),
+				Schema: dataset,
+				Type:   getBigQueryStructureType(row[2].(string)),
+			})
 		}
+	}
 
+	for _, dataset := range datasets {
 		datasetLayout := &core.Structure{
-			Name:     dataset.DatasetID,
-			Schema:   dataset.DatasetID,
+			Name:     dataset,
+			Schema:   dataset,
 			Type:     core.StructureTypeNone,
 			Children: []*core.Structure{},
 		}
+		datasetLayout.Children = append(datasetLayout.Children, children[dataset]...)
 
-		tablesIter := dataset.Tables(ctx)
-		for {
-			table, err := tablesIter.Next()
-			if err != nil {
-				if !errors.Is(err, iterator.Done) {
-					return nil, err
-				}
+		layouts = append(layouts, datasetLayout)
+	}
 
-				break
-			}
+	return layouts, nil
+}
 
-			datasetLayout.Children = append(datasetLayout.Children, &core.Structure{
-				Name:     table.TableID,
-				Schema:   table.DatasetID,
-				Type:     core.StructureTypeTable,
-				Children: nil,
-			})
+// datasetRegions returns the regions of tables of the datasets. Locations of datasets
+// are read from their metadata (which isn't billed), unless the location is set.
+func (c *bigQueryDriver) datasetRegions(ctx context.Context, datasets []string) ([]string, error) {
+	if c.location != "" {
+		return []string{c.location}, nil
+	}
+
+	var regions []string
+	for _, dataset := range datasets {
+		meta, err := c.c.Dataset(dataset).Metadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(regions, meta.Location) {
+			regions = append(regions, meta.Location)
 		}
+	}
 
-		layouts = append(layouts, datasetLayout)
+	return regions, nil
+}
+
+// getBigQueryStructureType returns the structure type of the table_type
+// column of INFORMATION_SCHEMA.TABLES.
+func getBigQueryStructureType(typ string) core.StructureType {
+	switch typ {
+	case "VIEW", "MATERIALIZED VIEW":
+		return core.StructureTypeView
+	case "EXTERNAL":
+		return core.StructureTypeExternalTable
+	default:
+		return core.StructureTypeTable
 	}
+}
 
-	return layouts, nil
+// ListDatabases returns the default dataset and the other datasets of the project.
+func (c *bigQueryDriver) ListDatabases() (current string, available []string, err error) {
+	datasets, err := c.listDatasets(context.TODO())
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, dataset := range datasets {
+		if dataset != c.dataset {
+			available = append(available, dataset)
+		}
+	}
+
+	return c.dataset, available, nil
+}
+
+// SelectDatabase sets the default dataset of unqualified table names in queries.
+func (c *bigQueryDriver) SelectDatabase(name string) error {
+	c.dataset = name
+	return nil
+}
+
+func (c *bigQueryDriver) listDatasets(ctx context.Context) ([]string, error) {
+	var datasets []string
+
+	iter := c.c.Datasets(ctx)
+	for {
+		dataset, err := iter.Next()
+		if err != nil {
+			if !errors.Is(err, iterator.Done) {
+				return nil, err
+			}
+
+			break
+		}
+		datasets = append(datasets, dataset.DatasetID)
+	}
+
+	return datasets, nil
 }
 
 func (c *bigQueryDriver) Close() {
 	_ = c.c.Close()
 }
 
+func (c *bigQueryDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *bigQueryDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteBacktick, core.LimitDialectLimit)
+}
+
 func (c *bigQueryDriver) buildHeader(parentName string, schema bigquery.Schema) (columns core.Header) {
 	for _, field := range schema {
 		if field.Type == bigquery.RecordFieldType {
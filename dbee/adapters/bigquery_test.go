@@ -0,0 +1,146 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestBigQueryDriver_SelectDatabase(t *testing.T) {
+	r := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/projects/shop/datasets" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"datasets": [
+			{"datasetReference": {"projectId": "shop", "datasetId": "sales"}},
+			{"datasetReference": {"projectId": "shop", "datasetId": "staging"}}
+		]}`))
+	}))
+	defer srv.Close()
+
+	bqc, err := bigquery.NewClient(context.Background(), "shop",
+		option.WithEndpoint(srv.URL),
+		option.WithoutAuthentication(),
+		option.WithTelemetryDisabled(),
+	)
+	r.NoError(err)
+
+	d := &bigQueryDriver{c: bqc}
+	defer d.Close()
+
+	current, available, err := d.ListDatabases()
+	r.NoError(err)
+	r.Equal("", current)
+	r.Equal([]string{"sales", "staging"}, available)
+
+	r.NoError(d.SelectDatabase("sales"))
+
+	current, available, err = d.ListDatabases()
+	r.NoError(err)
+	r.Equal("sales", current)
+	r.Equal([]string{"staging"}, available)
+}
+
+func TestBigQueryDriver_Structure(t *testing.T) {
+	r := require.New(t)
+
+	var queries, lookups []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/projects/shop/datasets":
+			_, _ = w.Write([]byte(`{"datasets": [
+				{"datasetReference": {"projectId": "shop", "datasetId": "sales"}},
+				{"datasetReference": {"projectId": "shop", "datasetId": "staging"}},
+				{"datasetReference": {"projectId": "shop", "datasetId": "archive"}}
+			]}`))
+		case "/projects/shop/datasets/sales", "/projects/shop/datasets/staging":
+			lookups = append(lookups, req.URL.Path)
+			_, _ = w.Write([]byte(`{"location": "US"}`))
+		case "/projects/shop/datasets/archive":
+			lookups = append(lookups, req.URL.Path)
+			_, _ = w.Write([]byte(`{"location": "europe-west1"}`))
+		case "/projects/shop/queries":
+			var body struct {
+				Query string `json:"query"`
+			}
+			r.NoError(json.NewDecoder(req.Body).Decode(&body))
+			queries = append(queries, body.Query)
+
+			rows := `[]`
+			if strings.Contains(body.Query, "region-us") {
+				rows = `[
+					{"f": [{"v": "sales"}, {"v": "orders"}, {"v": "BASE TABLE"}]},
+					{"f": [{"v": "sales"}, {"v": "orders_v"}, {"v": "VIEW"}]},
+					{"f": [{"v": "staging"}, {"v": "raw"}, {"v": "EXTERNAL"}]}
+				]`
+			}
+			_, _ = w.Write([]byte(`{
+				"jobComplete": true,
+				"jobReference": {"projectId": "shop", "jobId": "job"},
+				"schema": {"fields": [
+					{"name": "table_schema", "type": "STRING"},
+					{"name": "table_name", "type": "STRING"},
+					{"name": "table_type", "type": "STRING"}
+				]},
+				"rows": ` + rows + `
+			}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+
+	bqc, err := bigquery.NewClient(context.Background(), "shop",
+		option.WithEndpoint(srv.URL),
+		option.WithoutAuthentication(),
+		option.WithTelemetryDisabled(),
+	)
+	r.NoError(err)
+
+	d := &bigQueryDriver{c: bqc}
+	defer d.Close()
+
+	structure, err := d.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Name: "sales", Schema: "sales", Type: core.StructureTypeNone, Children: []*core.Structure{
+			{Name: "orders", Schema: "sales", Type: core.StructureTypeTable},
+			{Name: "orders_v", Schema: "sales", Type: core.StructureTypeView},
+		}},
+		{Name: "staging", Schema: "staging", Type: core.StructureTypeNone, Children: []*core.Structure{
+			{Name: "raw", Schema: "staging", Type: core.StructureTypeExternalTable},
+		}},
+		{Name: "archive", Schema: "archive", Type: core.StructureTypeNone, Children: []*core.Structure{}},
+	}, structure)
+	// a query per region instead of a query per dataset
+	r.Equal([]string{
+		"SELECT table_schema, table_name, table_type FROM `shop`.`region-us`.INFORMATION_SCHEMA.TABLES ORDER BY table_schema, table_name",
+		"SELECT table_schema, table_name, table_type FROM `shop`.`region-europe-west1`.INFORMATION_SCHEMA.TABLES ORDER BY table_schema, table_name",
+	}, queries)
+
+	r.Len(lookups, 3)
+
+	// datasets aren't looked up with a location
+	queries, lookups = nil, nil
+	d.location = "EU"
+	_, err = d.Structure()
+	r.NoError(err)
+	r.Empty(lookups)
+	r.Equal([]string{
+		"SELECT table_schema, table_name, table_type FROM `shop`.`region-eu`.INFORMATION_SCHEMA.TABLES ORDER BY table_schema, table_name",
+	}, queries)
+}
@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"google.golang.org/api/option"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Bigtable{}, "bigtable")
+}
+
+var (
+	_ core.Adapter   = (*Bigtable)(nil)
+	_ core.Validator = (*Bigtable)(nil)
+)
+
+type Bigtable struct{}
+
+// Connect creates a [Bigtable] client connected to the instance specified
+// in the url. The format of the url is as follows:
+//
+//	bigtable://project/instance[?options]
+//
+// The supported "options" are:
+//   - credentials=path/to/creds/file.json
+//
+// If credentials are not explicitly specified, credentials will attempt
+// to be located according to the Google Default Credentials process.
+// The BIGTABLE_EMULATOR_HOST environment variable is respected.
+func (bt *Bigtable) Connect(rawURL string) (core.Driver, error) {
+	ctx := context.TODO()
+
+	if err := bt.Validate(rawURL); err != nil {
+		return nil, err
+	}
+
+	u, _ := url.Parse(rawURL)
+	project := u.Host
+	instance := strings.Trim(u.Path, "/")
+
+	options := []option.ClientOption{
+		option.WithTelemetryDisabled(),
+	}
+
+	params := u.Query()
+	_ = callIfStringSet("credentials", params, func(file string) error {
+		options = append(options, option.WithCredentialsFile(file))
+		return nil
+	})
+
+	client, err := bigtable.NewClient(ctx, project, instance, options...)
+	if err != nil {
+		return nil, fmt.Errorf("bigtable.NewClient: %w", err)
+	}
+
+	admin, err := bigtable.NewAdminClient(ctx, project, instance, options...)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("bigtable.NewAdminClient: %w", err)
+	}
+
+	return &bigtableDriver{
+		c:     client,
+		admin: admin,
+	}, nil
+}
+
+func (*Bigtable) Validate(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "bigtable" {
+		return fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	if u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return fmt.Errorf("url must be in form bigtable://project/instance, got: %q", rawURL)
+	}
+	return nil
+}
+
+func (*Bigtable) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List": fmt.Sprintf("%s limit 100", opts.Table),
+	}
+}
@@ -0,0 +1,339 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"cloud.google.com/go/bigtable"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var _ core.Driver = (*bigtableDriver)(nil)
+
+const bigtableQueryUsage = "usage: <table> [row <key>]... [prefix <prefix>] [range <start> [<end>]] [family <regex>] [column <regex>] [limit <n>]"
+
+type bigtableDriver struct {
+	c     *bigtable.Client
+	admin *bigtable.AdminClient
+}
+
+// bigtableQuery is a parsed read request.
+//
+// Bigtable has no query language, so queries have the following form:
+//
+//	<table> [row <key>]... [prefix <prefix>] [range <start> [<end>]] [family <regex>] [column <regex>] [limit <n>]
+//
+// Where:
+//   - "row" reads a single row by its key and can be repeated.
+//   - "prefix" reads all rows with keys starting with prefix.
+//   - "range" reads rows with keys in [start, end). If end is omitted, the range is open.
+//   - "family" and "column" only return cells of matching column families
+//     and qualifiers (RE2 regular expressions).
+//   - "limit" limits the number of returned rows.
+//
+// Values with spaces can be quoted with single or double quotes.
+// Only the latest version of each cell is returned.
+type bigtableQuery struct {
+	table  string
+	rows   []string
+	prefix string
+	start  string
+	end    string
+	family string
+	column string
+	limit  int64
+}
+
+func parseBigtableQuery(query string) (*bigtableQuery, error) {
+	q, err := parseBigtableArgs(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, bigtableQueryUsage)
+	}
+	return q, nil
+}
+
+func parseBigtableArgs(query string) (*bigtableQuery, error) {
+	query = strings.Join(strings.Fields(query), " ")
+
+	parsed, err := parseRedisCmd(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	for _, p := range parsed {
+		if s, ok := p.(string); ok && s != "" {
+			args = append(args, s)
+		}
+	}
+
+	if len(args) < 1 {
+		return nil, errors.New("empty query: table name expected")
+	}
+
+	q := &bigtableQuery{table: args[0]}
+
+	// value returns the argument after keyword
+	value := func(i int, keyword string) (string, error) {
+		if i+1 >= len(args) {
+			return "", fmt.Errorf("syntax error: missing value after %q", keyword)
+		}
+		return args[i+1], nil
+	}
+
+	ranges := 0
+	for i := 1; i < len(args); i += 2 {
+		keyword := strings.ToLower(args[i])
+		val, err := value(i, keyword)
+		if err != nil {
+			return nil, err
+		}
+
+		switch keyword {
+		case "row":
+			q.rows = append(q.rows, val)
+		case "prefix":
+			q.prefix = val
+			ranges++
+		case "range":
+			q.start = val
+			ranges++
+			// optional end of range
+			if i+2 < len(args) && !isBigtableKeyword(args[i+2]) {
+				q.end = args[i+2]
+				i++
+			}
+		case "family":
+			q.family = val
+		case "column":
+			q.column = val
+		case "limit":
+			q.limit, err = strconv.ParseInt(val, 10, 64)
+			if err != nil || q.limit < 0 {
+				return nil, fmt.Errorf("syntax error: invalid limit: %q", val)
+			}
+		default:
+			return nil, fmt.Errorf("syntax error: unknown keyword: %q", args[i])
+		}
+	}
+
+	if ranges > 1 || (ranges > 0 && len(q.rows) > 0) {
+		return nil, errors.New("syntax error: only one of row, prefix or range can be used")
+	}
+
+	return q, nil
+}
+
+func isBigtableKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "row", "prefix", "range", "family", "column", "limit":
+		return true
+	}
+	return false
+}
+
+func (q *bigtableQuery) rowSet() bigtable.RowSet {
+	switch {
+	case len(q.rows) > 0:
+		return bigtable.RowList(q.rows)
+	case q.prefix != "":
+		return bigtable.PrefixRange(q.prefix)
+	case q.start != "" && q.end != "":
+		return bigtable.NewRange(q.start, q.end)
+	case q.start != "":
+		return bigtable.InfiniteRange(q.start)
+	default:
+		return bigtable.InfiniteRange("")
+	}
+}
+
+func (q *bigtableQuery) readOptions() []bigtable.ReadOption {
+	filters := []bigtable.Filter{bigtable.LatestNFilter(1)}
+	if q.family != "" {
+		filters = append(filters, bigtable.FamilyFilter(q.family))
+	}
+	if q.column != "" {
+		filters = append(filters, bigtable.ColumnFilter(q.column))
+	}
+
+	// chain needs at least two filters
+	filter := filters[0]
+	if len(filters) > 1 {
+		filter = bigtable.ChainFilters(filters...)
+	}
+
+	opts := []bigtable.ReadOption{bigtable.RowFilter(filter)}
+	if q.limit > 0 {
+		opts = append(opts, bigtable.LimitRows(q.limit))
+	}
+
+	return opts
+}
+
+// bigtableValue converts cell value to a string. Binary values are hex encoded.
+func bigtableValue(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return fmt.Sprintf("0x%x", b)
+}
+
+// bigtableRowsToResult converts rows to a table with a "row_key" column
+// followed by sorted "family:qualifier" columns.
+func bigtableRowsToResult(rows []bigtable.Row) (core.Header, []core.Row) {
+	columnSet := make(map[string]struct{})
+	for _, row := range rows {
+		for _, items := range row {
+			for _, item := range items {
+				columnSet[item.Column] = struct{}{}
+			}
+		}
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for col := range columnSet {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	index := make(map[string]int, len(columns))
+	for i, col := range columns {
+		index[col] = i + 1
+	}
+
+	header := append(core.Header{"row_key"}, columns...)
+
+	var result []core.Row
+	for _, row := range rows {
+		r := make(core.Row, len(header))
+		r[0] = row.Key()
+		for _, items := range row {
+			for _, item := range items {
+				r[index[item.Column]] = bigtableValue(item.Value)
+			}
+		}
+		result = append(result, r)
+	}
+
+	return header, result
+}
+
+func (c *bigtableDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	q, err := parseBigtableQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// rows are collected first, since the columns are only known after reading
+	var rows []bigtable.Row
+	err = c.c.Open(q.table).ReadRows(ctx, q.rowSet(), func(row bigtable.Row) bool {
+		rows = append(rows, row)
+		return true
+	}, q.readOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("ReadRows: %w", err)
+	}
+
+	header, result := bigtableRowsToResult(rows)
+
+	i := 0
+	hasNext := func() bool {
+		return i < len(result)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		i++
+		return result[i-1], nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build(), nil
+}
+
+func (c *bigtableDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	ctx := context.TODO()
+
+	info, err := c.admin.TableInfo(ctx, opts.Table)
+	if err != nil {
+		return nil, fmt.Errorf("c.admin.TableInfo: %w", err)
+	}
+
+	var columns []*core.Column
+	for _, family := range info.FamilyInfos {
+		typ := "column family"
+		if family.GCPolicy != "" {
+			typ += " (gc: " + family.GCPolicy + ")"
+		}
+		columns = append(columns, &core.Column{
+			Name: family.Name,
+			Type: typ,
+		})
+	}
+
+	// qualifiers are not part of the schema, so sample a few rows
+	qualifiers := make(map[string]struct{})
+	err = c.c.Open(opts.Table).ReadRows(ctx, bigtable.InfiniteRange(""), func(row bigtable.Row) bool {
+		for _, items := range row {
+			for _, item := range items {
+				qualifiers[item.Column] = struct{}{}
+			}
+		}
+		return true
+	},
+		bigtable.LimitRows(20),
+		bigtable.RowFilter(bigtable.ChainFilters(bigtable.LatestNFilter(1), bigtable.StripValueFilter())),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ReadRows: %w", err)
+	}
+
+	sampled := make([]string, 0, len(qualifiers))
+	for q := range qualifiers {
+		sampled = append(sampled, q)
+	}
+	sort.Strings(sampled)
+
+	for _, q := range sampled {
+		columns = append(columns, &core.Column{
+			Name: q,
+			Type: "qualifier (sampled)",
+		})
+	}
+
+	return columns, nil
+}
+
+func (c *bigtableDriver) Structure() ([]*core.Structure, error) {
+	tables, err := c.admin.Tables(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("c.admin.Tables: %w", err)
+	}
+	sort.Strings(tables)
+
+	var structure []*core.Structure
+	for _, table := range tables {
+		structure = append(structure, &core.Structure{
+			Name:   table,
+			Schema: "",
+			Type:   core.StructureTypeTable,
+		})
+	}
+
+	return structure, nil
+}
+
+func (c *bigtableDriver) Close() {
+	_ = c.c.Close()
+	_ = c.admin.Close()
+}
@@ -0,0 +1,154 @@
+package adapters
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/bigtable/bttest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestParseBigtableQuery(t *testing.T) {
+	type testCase struct {
+		name          string
+		query         string
+		expected      *bigtableQuery
+		expectedError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "table only",
+			query:    "users",
+			expected: &bigtableQuery{table: "users"},
+		},
+		{
+			name:     "rows",
+			query:    "users row alice row 'bob smith'",
+			expected: &bigtableQuery{table: "users", rows: []string{"alice", "bob smith"}},
+		},
+		{
+			name:     "prefix with filters and limit",
+			query:    "users  prefix user#\n family ^info$ column name LIMIT 10",
+			expected: &bigtableQuery{table: "users", prefix: "user#", family: "^info$", column: "name", limit: 10},
+		},
+		{
+			name:     "closed range",
+			query:    "users range a m limit 5",
+			expected: &bigtableQuery{table: "users", start: "a", end: "m", limit: 5},
+		},
+		{
+			name:     "open range",
+			query:    "users range m limit 5",
+			expected: &bigtableQuery{table: "users", start: "m", limit: 5},
+		},
+		{
+			name:          "empty",
+			query:         "  ",
+			expectedError: true,
+		},
+		{
+			name:          "missing value",
+			query:         "users limit",
+			expectedError: true,
+		},
+		{
+			name:          "unknown keyword",
+			query:         "users where a",
+			expectedError: true,
+		},
+		{
+			name:          "multiple row sets",
+			query:         "users prefix a range b",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseBigtableQuery(tc.query)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestBigtable(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	server, err := bttest.NewServer("localhost:0")
+	r.NoError(err)
+	defer server.Close()
+
+	conn, err := grpc.Dial(server.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	r.NoError(err)
+	defer conn.Close()
+
+	admin, err := bigtable.NewAdminClient(ctx, "project", "instance", option.WithGRPCConn(conn))
+	r.NoError(err)
+	client, err := bigtable.NewClient(ctx, "project", "instance", option.WithGRPCConn(conn))
+	r.NoError(err)
+
+	r.NoError(admin.CreateTable(ctx, "users"))
+	r.NoError(admin.CreateColumnFamily(ctx, "users", "info"))
+	r.NoError(admin.CreateColumnFamily(ctx, "users", "stats"))
+
+	tbl := client.Open("users")
+	for key, cells := range map[string]map[string]string{
+		"user#1": {"info:name": "alice", "stats:visits": "3"},
+		"user#2": {"info:name": "bob"},
+		"other":  {"info:email": "x@y.z"},
+	} {
+		mut := bigtable.NewMutation()
+		for col, val := range cells {
+			family, qualifier, _ := strings.Cut(col, ":")
+			mut.Set(family, qualifier, bigtable.Now(), []byte(val))
+		}
+		r.NoError(tbl.Apply(ctx, key, mut))
+	}
+
+	driver := &bigtableDriver{c: client, admin: admin}
+
+	// structure
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{{Name: "users", Type: core.StructureTypeTable}}, structure)
+
+	// query
+	result, err := driver.Query(ctx, "users prefix user#")
+	r.NoError(err)
+	r.Equal(core.Header{"row_key", "info:name", "stats:visits"}, result.Header())
+
+	var rows []core.Row
+	for result.HasNext() {
+		row, err := result.Next()
+		r.NoError(err)
+		rows = append(rows, row)
+	}
+	r.Equal([]core.Row{
+		{"user#1", "alice", "3"},
+		{"user#2", "bob", nil},
+	}, rows)
+
+	// columns
+	columns, err := driver.Columns(&core.TableOptions{Table: "users"})
+	r.NoError(err)
+
+	var names []string
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	r.ElementsMatch([]string{"info", "stats", "info:email", "info:name", "stats:visits"}, names)
+}
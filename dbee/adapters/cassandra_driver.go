@@ -0,0 +1,367 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"gopkg.in/inf.v0"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver            = (*cassandraDriver)(nil)
+	_ core.ContextStructurer = (*cassandraDriver)(nil)
+	_ core.DatabaseSwitcher  = (*cassandraDriver)(nil)
+	_ core.IdentifierFolder  = (*cassandraDriver)(nil)
+)
+
+type cassandraDriver struct {
+	session *gocql.Session
+	// cluster is the config of the session, its keyspace is the current one
+	cluster *gocql.ClusterConfig
+}
+
+// Query runs the CQL statement as is (e.g. "ORDER BY embedding ANN OF [...] LIMIT n"
+// of vector search), so no limit is injected. Rows are fetched page by page.
+func (c *cassandraDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	iter := c.session.Query(query).IterContext(ctx)
+
+	columns := iter.Columns()
+	if len(columns) < 1 {
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			Build(), nil
+	}
+
+	header := make(core.Header, len(columns))
+	dest := make([]any, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+		dest[i] = cassandraScanDest(col.TypeInfo)
+	}
+
+	next, hasNext := builders.NextYield(func(yield func(...any)) error {
+		for iter.Scan(dest...) {
+			row := make([]any, len(dest))
+			for i, d := range dest {
+				row[i] = cassandraValue(columns[i].TypeInfo, d)
+			}
+			yield(row...)
+		}
+		return iter.Close()
+	})
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build(), nil
+}
+
+// cassandraScanDest returns a destination for scanning values of the type. It's a
+// pointer to a pointer, so null values can be told apart from zero values.
+func cassandraScanDest(info gocql.TypeInfo) any {
+	zero := info.Zero()
+	if zero == nil {
+		return new(any)
+	}
+	return reflect.New(reflect.PointerTo(reflect.TypeOf(zero))).Interface()
+}
+
+// cassandraValue converts the scanned value to a displayable one. Vectors are
+// formatted as CQL literals (e.g. "[0.1, 0.2]"), so they can be pasted into ANN queries.
+func cassandraValue(info gocql.TypeInfo, dest any) any {
+	rv := reflect.ValueOf(dest)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	val := rv.Interface()
+
+	if _, ok := info.(gocql.VectorType); ok {
+		return cassandraVector(val)
+	}
+
+	switch v := val.(type) {
+	case string, bool, int, int8, int16, int32, int64, float32, float64, []byte, time.Time:
+		return v
+	case gocql.UUID:
+		return v.String()
+	case *inf.Dec:
+		return v.String()
+	case *big.Int:
+		return v.String()
+	case net.IP:
+		return v.String()
+	case time.Duration:
+		return v.String()
+	case gocql.Duration:
+		return fmt.Sprintf("%dmo%dd%dns", v.Months, v.Days, v.Nanoseconds)
+	}
+
+	// collections, tuples and user defined types
+	out, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(out)
+}
+
+// cassandraVector formats the vector as a CQL literal.
+func cassandraVector(val any) string {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprint(val)
+	}
+
+	elems := make([]string, rv.Len())
+	for i := range elems {
+		switch e := rv.Index(i).Interface().(type) {
+		case float32:
+			elems[i] = strconv.FormatFloat(float64(e), 'g', -1, 32)
+		case float64:
+			elems[i] = strconv.FormatFloat(e, 'g', -1, 64)
+		default:
+			elems[i] = fmt.Sprint(cassandraValue(nil, e))
+		}
+	}
+
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// cassandraColumn is a row of system_schema.columns.
+type cassandraColumn struct {
+	name string
+	typ  string
+	kind string
+	// position in the partition key or clustering columns (-1 for others)
+	position int
+	// clustering order ("asc", "desc" or "none")
+	order string
+}
+
+// cassandraIndex is a row of system_schema.indexes.
+type cassandraIndex struct {
+	name    string
+	options map[string]string
+}
+
+// cassandraIndexTarget returns the column of the index target
+// (e.g. "embedding", "\"Embedding\"" or "values(tags)").
+func cassandraIndexTarget(target string) string {
+	if open := strings.IndexByte(target, '('); open >= 0 && strings.HasSuffix(target, ")") {
+		target = target[open+1 : len(target)-1]
+	}
+	if len(target) > 1 && strings.HasPrefix(target, `"`) && strings.HasSuffix(target, `"`) {
+		return strings.ReplaceAll(target[1:len(target)-1], `""`, `"`)
+	}
+	return target
+}
+
+// cassandraTableColumns returns columns in schema order (partition key, clustering, static
+// and regular columns). Types of columns other than regular ones mention their kind, e.g.
+// "int (partition key)" or "timestamp (clustering, desc)". Types of vector columns with
+// an index (usable for ANN queries) mention the index and its similarity function,
+// e.g. "vector<float, 3> (ann: idx, cosine)".
+func cassandraTableColumns(columns []cassandraColumn, indexes []cassandraIndex) []*core.Column {
+	kindOrder := map[string]int{"partition_key": 0, "clustering": 1, "static": 2, "regular": 3}
+	sort.SliceStable(columns, func(i, j int) bool {
+		a, b := columns[i], columns[j]
+		if kindOrder[a.kind] != kindOrder[b.kind] {
+			return kindOrder[a.kind] < kindOrder[b.kind]
+		}
+		return a.position < b.position
+	})
+
+	vectorIndexes := make(map[string][]string)
+	for _, idx := range indexes {
+		col := cassandraIndexTarget(idx.options["target"])
+		similarity := idx.options["similarity_function"]
+		if similarity == "" {
+			similarity = "cosine"
+		}
+		vectorIndexes[col] = append(vectorIndexes[col], idx.name+", "+strings.ToLower(similarity))
+	}
+
+	out := make([]*core.Column, len(columns))
+	for i, col := range columns {
+		typ := col.typ
+		switch col.kind {
+		case "partition_key":
+			typ += " (partition key)"
+		case "clustering":
+			if order := strings.ToLower(col.order); order != "" && order != "none" {
+				typ += " (clustering, " + order + ")"
+			} else {
+				typ += " (clustering)"
+			}
+		case "static":
+			typ += " (static)"
+		}
+		if strings.HasPrefix(col.typ, "vector<") {
+			for _, idx := range vectorIndexes[col.name] {
+				typ += " (ann: " + idx + ")"
+			}
+		}
+		out[i] = &core.Column{Name: col.name, Type: typ}
+	}
+
+	return out
+}
+
+func (c *cassandraDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	ctx := context.Background()
+
+	var columns []cassandraColumn
+	iter := c.session.Query(
+		"SELECT column_name, type, kind, position, clustering_order FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?",
+		opts.Schema, opts.Table,
+	).IterContext(ctx)
+	var col cassandraColumn
+	for iter.Scan(&col.name, &col.typ, &col.kind, &col.position, &col.order) {
+		columns = append(columns, col)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("system_schema.columns: %w", err)
+	}
+
+	var indexes []cassandraIndex
+	iter = c.session.Query(
+		"SELECT index_name, options FROM system_schema.indexes WHERE keyspace_name = ? AND table_name = ?",
+		opts.Schema, opts.Table,
+	).IterContext(ctx)
+	for {
+		var idx cassandraIndex
+		if !iter.Scan(&idx.name, &idx.options) {
+			break
+		}
+		indexes = append(indexes, idx)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("system_schema.indexes: %w", err)
+	}
+
+	return cassandraTableColumns(columns, indexes), nil
+}
+
+func (c *cassandraDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+// StructureCtx lists keyspaces with their tables and materialized views.
+func (c *cassandraDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	keyspaces := make(map[string]*core.Structure)
+
+	add := func(query string, typ core.StructureType) error {
+		iter := c.session.Query(query).IterContext(ctx)
+		var keyspace, name string
+		for iter.Scan(&keyspace, &name) {
+			node, ok := keyspaces[keyspace]
+			if !ok {
+				node = &core.Structure{Name: keyspace, Schema: keyspace, Type: core.StructureTypeNone}
+				keyspaces[keyspace] = node
+			}
+			node.Children = append(node.Children, &core.Structure{
+				Name:   name,
+				Schema: keyspace,
+				Type:   typ,
+			})
+		}
+		return iter.Close()
+	}
+
+	if err := add("SELECT keyspace_name, table_name FROM system_schema.tables", core.StructureTypeTable); err != nil {
+		return nil, fmt.Errorf("system_schema.tables: %w", err)
+	}
+	if err := add("SELECT keyspace_name, view_name FROM system_schema.views", core.StructureTypeView); err != nil {
+		return nil, fmt.Errorf("system_schema.views: %w", err)
+	}
+
+	return cassandraStructure(keyspaces), nil
+}
+
+// cassandraStructure sorts keyspaces (system keyspaces last) and their children.
+func cassandraStructure(keyspaces map[string]*core.Structure) []*core.Structure {
+	structure := make([]*core.Structure, 0, len(keyspaces))
+	for _, node := range keyspaces {
+		sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Name < node.Children[j].Name })
+		structure = append(structure, node)
+	}
+
+	sort.Slice(structure, func(i, j int) bool {
+		return cassandraKeyspaceLess(structure[i].Name, structure[j].Name)
+	})
+
+	return structure
+}
+
+// cassandraKeyspaceLess orders keyspaces by name, system keyspaces last.
+func cassandraKeyspaceLess(a, b string) bool {
+	isSystem := func(name string) bool {
+		return strings.HasPrefix(name, "system")
+	}
+	if isSystem(a) != isSystem(b) {
+		return !isSystem(a)
+	}
+	return a < b
+}
+
+// ListDatabases returns keyspaces of system_schema.keyspaces (system keyspaces last).
+func (c *cassandraDriver) ListDatabases() (current string, available []string, err error) {
+	iter := c.session.Query("SELECT keyspace_name FROM system_schema.keyspaces").Iter()
+	var name string
+	for iter.Scan(&name) {
+		if name != c.cluster.Keyspace {
+			available = append(available, name)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return "", nil, fmt.Errorf("system_schema.keyspaces: %w", err)
+	}
+
+	sort.Slice(available, func(i, j int) bool { return cassandraKeyspaceLess(available[i], available[j]) })
+
+	return c.cluster.Keyspace, available, nil
+}
+
+// SelectDatabase creates a new session with the keyspace, since the keyspace
+// of a session can't be changed (a USE statement would only apply to a single connection).
+func (c *cassandraDriver) SelectDatabase(name string) error {
+	cluster := *c.cluster
+	cluster.Keyspace = name
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return fmt.Errorf("unable to switch keyspaces: %w", err)
+	}
+
+	c.session.Close()
+	c.session = session
+	c.cluster = &cluster
+
+	return nil
+}
+
+func (c *cassandraDriver) IdentifierCase() core.IdentifierCase {
+	return core.IdentifierCaseLower
+}
+
+func (c *cassandraDriver) Close() {
+	c.session.Close()
+}
@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestCassandraClusterConfig(t *testing.T) {
+	r := require.New(t)
+
+	u, err := url.Parse("cassandra://user:secret@a:9042,b:9043/vectors?consistency=local_quorum&timeout=30s&tls=true")
+	r.NoError(err)
+
+	cluster, err := cassandraClusterConfig(u)
+	r.NoError(err)
+	r.Equal([]string{"a:9042", "b:9043"}, cluster.Hosts)
+	r.Equal("vectors", cluster.Keyspace)
+	r.Equal(gocql.LocalQuorum, cluster.Consistency)
+	r.Equal(30*time.Second, cluster.Timeout)
+	r.Equal(gocql.PasswordAuthenticator{Username: "user", Password: "secret"}, cluster.Authenticator)
+	r.NotNil(cluster.SslOpts)
+
+	u, err = url.Parse("cassandra://a?keyspace=shop")
+	r.NoError(err)
+	cluster, err = cassandraClusterConfig(u)
+	r.NoError(err)
+	r.Equal("shop", cluster.Keyspace)
+
+	for _, raw := range []string{
+		"postgres://a",
+		"cassandra://a,,b",
+		"cassandra://a?consistency=most",
+		"cassandra://a?timeout=never",
+		"cassandra://a/vectors?keyspace=other",
+	} {
+		u, err := url.Parse(raw)
+		r.NoError(err)
+		_, err = cassandraClusterConfig(u)
+		r.Error(err, raw)
+	}
+}
+
+func TestCassandraValue(t *testing.T) {
+	r := require.New(t)
+
+	vector := gocql.VectorType{SubType: gocql.NewNativeType(5, gocql.TypeFloat, ""), Dimensions: 3}
+
+	// scan destinations are pointers to pointers (nil for null values)
+	dest := cassandraScanDest(vector)
+	r.IsType((**[]float32)(nil), dest)
+	r.Nil(cassandraValue(vector, dest))
+
+	data, err := gocql.Marshal(vector, []float32{0.1, -2, 3.5e-7})
+	r.NoError(err)
+	r.NoError(gocql.Unmarshal(vector, data, dest))
+	r.Equal("[0.1, -2, 3.5e-07]", cassandraValue(vector, dest))
+
+	id := 42
+	r.Equal(42, cassandraValue(nil, &id))
+
+	uuid, err := gocql.ParseUUID("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")
+	r.NoError(err)
+	r.Equal("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", cassandraValue(nil, &uuid))
+
+	r.Equal(`{"a":1}`, cassandraValue(nil, map[string]int{"a": 1}))
+	r.Equal(`["x","y"]`, cassandraValue(nil, []string{"x", "y"}))
+}
+
+func TestCassandraTableColumns(t *testing.T) {
+	r := require.New(t)
+
+	columns := cassandraTableColumns(
+		[]cassandraColumn{
+			{name: "embedding", typ: "vector<float, 3>", kind: "regular", position: -1, order: "none"},
+			{name: "name", typ: "text", kind: "regular", position: -1, order: "none"},
+			{name: "region", typ: "text", kind: "partition_key", position: 1, order: "none"},
+			{name: "id", typ: "int", kind: "partition_key", position: 0, order: "none"},
+			{name: "day", typ: "date", kind: "clustering", position: 0, order: "desc"},
+			{name: "owner", typ: "text", kind: "static", position: -1, order: "none"},
+			{name: "Other", typ: "vector<float, 2>", kind: "regular", position: -1, order: "none"},
+		},
+		[]cassandraIndex{
+			{name: "embedding_idx", options: map[string]string{"target": "embedding", "class_name": "StorageAttachedIndex"}},
+			{name: "other_idx", options: map[string]string{"target": `"Other"`, "similarity_function": "DOT_PRODUCT"}},
+			{name: "name_idx", options: map[string]string{"target": "name"}},
+		},
+	)
+
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "int (partition key)"},
+		{Name: "region", Type: "text (partition key)"},
+		{Name: "day", Type: "date (clustering, desc)"},
+		{Name: "owner", Type: "text (static)"},
+		{Name: "embedding", Type: "vector<float, 3> (ann: embedding_idx, cosine)"},
+		{Name: "name", Type: "text"},
+		{Name: "Other", Type: "vector<float, 2> (ann: other_idx, dot_product)"},
+	}, columns)
+
+	r.Equal("tags", cassandraIndexTarget("values(tags)"))
+}
+
+func TestCassandraHelpers(t *testing.T) {
+	r := require.New(t)
+
+	helpers := (&Cassandra{}).GetHelpers(&core.TableOptions{Schema: "shop", Table: "Items"})
+	r.Equal(`SELECT * FROM shop."Items" LIMIT 500`, helpers["List"])
+	r.Equal("SELECT index_name, kind, options FROM system_schema.indexes WHERE keyspace_name = 'shop' AND table_name = 'Items'", helpers["Indexes"])
+
+	// mixed-case names of the structure round-trip
+	ic := (&cassandraDriver{}).IdentifierCase()
+	r.Equal(`"Items"`, ic.Display("Items"))
+	r.Equal("Items", ic.Catalog(ic.Display("Items")))
+	r.Equal("items", ic.Catalog("Items"))
+}
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/kndndrj/nvim-dbee/dbee/core"
@@ -9,8 +10,17 @@ import (
 )
 
 var (
-	_ core.Driver           = (*clickhouseDriver)(nil)
-	_ core.DatabaseSwitcher = (*clickhouseDriver)(nil)
+	_ core.Driver            = (*clickhouseDriver)(nil)
+	_ core.ContextStructurer = (*clickhouseDriver)(nil)
+	_ core.DatabaseSwitcher  = (*clickhouseDriver)(nil)
+	_ core.IdleCloser        = (*clickhouseDriver)(nil)
+	_ core.Limiter           = (*clickhouseDriver)(nil)
+	_ core.ParamQuerier      = (*clickhouseDriver)(nil)
+	_ core.Peeker            = (*clickhouseDriver)(nil)
+	_ core.ProgressQuerier   = (*clickhouseDriver)(nil)
+	_ core.SafeScanner       = (*clickhouseDriver)(nil)
+	_ core.StatementLogger   = (*clickhouseDriver)(nil)
+	_ core.TopValuer         = (*clickhouseDriver)(nil)
 )
 
 type clickhouseDriver struct {
@@ -23,6 +33,36 @@ func (c *clickhouseDriver) Query(ctx context.Context, query string) (core.Result
 	return c.c.QueryUntilNotEmpty(ctx, query, "select changes() as 'Rows Affected'")
 }
 
+func (c *clickhouseDriver) QueryProgress(ctx context.Context, query string, settings map[string]string, onProgress func(*core.Progress)) (core.ResultStream, error) {
+	return c.Query(clickhouse.Context(ctx, clickhouseQueryOptions(settings, onProgress)...), query)
+}
+
+// clickhouseQueryOptions returns options of a query which report its progress.
+// Settings are applied only if there are any.
+func clickhouseQueryOptions(settings map[string]string, onProgress func(*core.Progress)) []clickhouse.QueryOption {
+	opts := []clickhouse.QueryOption{
+		clickhouse.WithProgress(func(p *clickhouse.Progress) {
+			onProgress(&core.Progress{
+				RowsRead:     p.Rows,
+				BytesRead:    p.Bytes,
+				TotalRows:    p.TotalRows,
+				RowsWritten:  p.WroteRows,
+				BytesWritten: p.WroteBytes,
+			})
+		}),
+	}
+
+	if len(settings) > 0 {
+		chSettings := make(clickhouse.Settings, len(settings))
+		for name, value := range settings {
+			chSettings[name] = value
+		}
+		opts = append(opts, clickhouse.WithSettings(chSettings))
+	}
+
+	return opts
+}
+
 func (c *clickhouseDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return c.c.ColumnsFromQuery(`
 		SELECT name, type
@@ -34,6 +74,10 @@ func (c *clickhouseDriver) Columns(opts *core.TableOptions) ([]*core.Column, err
 }
 
 func (c *clickhouseDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+func (c *clickhouseDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
 	query := `
         SELECT
             table_schema, table_name, table_type
@@ -45,18 +89,50 @@ func (c *clickhouseDriver) Structure() ([]*core.Structure, error) {
             FROM information_schema.tables
             WHERE lower(table_schema) = 'information_schema'`
 
-	rows, err := c.Query(context.TODO(), query)
+	rows, err := c.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return getPGStructure(rows)
+	return getPGStructure(ctx, rows)
 }
 
 func (c *clickhouseDriver) Close() {
 	c.c.Close()
 }
 
+func (c *clickhouseDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *clickhouseDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *clickhouseDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderQuestion
+}
+
+func (c *clickhouseDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteBacktick, core.LimitDialectLimit)
+}
+
+func (c *clickhouseDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return c.c.Peek(ctx, query)
+}
+
+func (c *clickhouseDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *clickhouseDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+func (c *clickhouseDriver) SetIdleTimeout(timeout time.Duration) {
+	c.c.SetIdleTimeout(timeout)
+}
+
 func (c *clickhouseDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT currentDatabase(), schema_name
@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"fmt"
+	"net/http"
+	nurl "net/url"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&ClickhouseHTTP{}, "clickhouse_http", "clickhouse-http", "chhttp")
+}
+
+var _ core.Adapter = (*ClickhouseHTTP)(nil)
+
+// ClickhouseHTTP talks to the HTTP interface of clickhouse (port 8123 by default),
+// which is useful when the native protocol port is not reachable (e.g. behind a proxy).
+type ClickhouseHTTP struct{}
+
+// Connect creates a [ClickhouseHTTP] client.
+// The format of the url is as follows:
+//
+//	clickhouse://[user:password@]host:port[/path][?settings]
+//
+// Where:
+//   - "clickhouses" (or "https") scheme can be used instead of "clickhouse" (or "http") to connect over https.
+//   - "path" is the path of the http endpoint (if clickhouse is exposed under a path by a proxy).
+//   - "settings" is an ampersand-separated list of clickhouse settings passed with each query
+//     (e.g. database=default&max_execution_time=60). The "database" setting selects the default database.
+//
+// Instead of basic auth, OAuth2 can be used by passing the "oauth-token-url",
+// "oauth-client-id", "oauth-client-secret", "oauth-refresh-token" and "oauth-scopes"
+// settings (e.g. when clickhouse is behind an authenticating proxy).
+func (*ClickhouseHTTP) Connect(rawURL string) (core.Driver, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	var scheme string
+	switch u.Scheme {
+	case "clickhouse", "http":
+		scheme = "http"
+	case "clickhouses", "https":
+		scheme = "https"
+	default:
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	settings := u.Query()
+	oauth, err := newOAuthTokenSource(settings)
+	if err != nil {
+		return nil, err
+	}
+	database := settings.Get("database")
+	settings.Del("database")
+
+	driver := &clickhouseHTTPDriver{
+		c: &http.Client{},
+		url: &nurl.URL{
+			Scheme: scheme,
+			Host:   u.Host,
+			Path:   strings.TrimSuffix(u.Path, "/") + "/",
+		},
+		database: database,
+		settings: settings,
+	}
+
+	if oauth != nil {
+		driver.c = oauth.client()
+	} else if u.User != nil {
+		driver.user = u.User.Username()
+		driver.password, _ = u.User.Password()
+	}
+
+	return driver, nil
+}
+
+func (*ClickhouseHTTP) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List": fmt.Sprintf(
+			"SELECT * FROM %q.%q LIMIT 500",
+			opts.Schema, opts.Table,
+		),
+		"Columns": fmt.Sprintf(
+			"DESCRIBE %q.%q",
+			opts.Schema, opts.Table,
+		),
+		"Info": fmt.Sprintf(
+			"SELECT * FROM system.tables WHERE database = '%s' AND name = '%s'",
+			opts.Schema, opts.Table,
+		),
+	}
+}
@@ -0,0 +1,304 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver            = (*clickhouseHTTPDriver)(nil)
+	_ core.ContextStructurer = (*clickhouseHTTPDriver)(nil)
+	_ core.DatabaseSwitcher  = (*clickhouseHTTPDriver)(nil)
+	_ core.Limiter           = (*clickhouseHTTPDriver)(nil)
+	_ core.TopValuer         = (*clickhouseHTTPDriver)(nil)
+)
+
+// clickhouseHTTPFormat is the default output format of queries.
+// Unlike plain JSONEachRow, it keeps the column order and sends
+// the header even if there are no rows.
+const clickhouseHTTPFormat = "JSONCompactEachRowWithNamesAndTypes"
+
+type clickhouseHTTPDriver struct {
+	c        *http.Client
+	url      *nurl.URL
+	user     string
+	password string
+	database string
+	settings nurl.Values
+}
+
+// clickhouseHTTPSummary is the content of "X-ClickHouse-Summary" response header.
+type clickhouseHTTPSummary struct {
+	WrittenRows json.Number `json:"written_rows"`
+}
+
+// post sends the query to the http interface and returns the (decompressed) response body.
+func (c *clickhouseHTTPDriver) post(ctx context.Context, query string) (io.ReadCloser, http.Header, error) {
+	params := nurl.Values{}
+	for k, v := range c.settings {
+		params[k] = v
+	}
+	if c.database != "" {
+		params.Set("database", c.database)
+	}
+	params.Set("default_format", clickhouseHTTPFormat)
+	params.Set("enable_http_compression", "1")
+
+	u := *c.url
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(query))
+	if err != nil {
+		return nil, nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	// setting the header disables transparent decompression of the transport,
+	// so gzip is handled below
+	req.Header.Set("Accept-Encoding", "gzip")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("c.c.Do: %w", err)
+	}
+
+	body := resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, nil, fmt.Errorf("gzip.NewReader: %w", err)
+		}
+		body = &clickhouseHTTPBody{Reader: gz, closers: []io.Closer{gz, resp.Body}}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer body.Close()
+		msg, _ := io.ReadAll(body)
+		return nil, nil, fmt.Errorf("clickhouse responded with %q: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return body, resp.Header, nil
+}
+
+// clickhouseHTTPBody closes both the decompressor and the underlying body.
+type clickhouseHTTPBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *clickhouseHTTPBody) Close() error {
+	var errs []error
+	for _, c := range b.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (c *clickhouseHTTPDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	body, header, err := c.post(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	nextLine := func() ([]byte, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) > 0 {
+				return line, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	// statements without output (e.g. inserts) return an empty body,
+	// fallback to affected rows
+	line, err := nextLine()
+	if errors.Is(err, io.EOF) {
+		body.Close()
+
+		var summary clickhouseHTTPSummary
+		_ = json.Unmarshal([]byte(header.Get("X-ClickHouse-Summary")), &summary)
+		written := summary.WrittenRows
+		if written == "" {
+			written = "0"
+		}
+
+		next, hasNext := builders.NextSingle(written)
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(next, hasNext).
+			WithHeader(core.Header{"Rows Affected"}).
+			Build(), nil
+	}
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("failed reading response: %w", err)
+	}
+
+	var pending []byte
+	var pendingErr error
+
+	// exceptions can be sent in the middle of the stream as plain text
+	toRow := func(line []byte) (core.Row, error) {
+		var values []json.RawMessage
+		if err := json.Unmarshal(line, &values); err != nil {
+			return nil, fmt.Errorf("clickhouse error: %s", line)
+		}
+
+		row := make(core.Row, len(values))
+		for i, v := range values {
+			row[i] = rawJSONValue(v)
+		}
+		return row, nil
+	}
+
+	var columns core.Header
+	if err := json.Unmarshal(line, &columns); err != nil {
+		// queries with an explicit format other than the default one
+		// are displayed line by line
+		columns = core.Header{"Result"}
+		toRow = func(line []byte) (core.Row, error) {
+			return core.Row{string(line)}, nil
+		}
+		pending = line
+	} else if _, err := nextLine(); err != nil {
+		// second line holds the column types
+		body.Close()
+		return nil, fmt.Errorf("failed reading column types: %w", err)
+	}
+
+	hasNext := func() bool {
+		if pending != nil || pendingErr != nil {
+			return true
+		}
+
+		line, err := nextLine()
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+		if err != nil {
+			pendingErr = err
+			return true
+		}
+		pending = line
+		return true
+	}
+
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		if pendingErr != nil {
+			return nil, pendingErr
+		}
+
+		line := pending
+		pending = nil
+
+		return toRow(line)
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(columns).
+		WithCloseFunc(func() {
+			_ = body.Close()
+		}).
+		Build(), nil
+}
+
+func (c *clickhouseHTTPDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	result, err := c.Query(context.Background(), fmt.Sprintf(`
+		SELECT name, type
+		FROM system.columns
+		WHERE
+			database='%s' AND
+			table='%s'
+		`, opts.Schema, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return builders.ColumnsFromResultStream(result)
+}
+
+func (c *clickhouseHTTPDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+func (c *clickhouseHTTPDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	query := `
+		SELECT
+			database, name, if(engine LIKE '%View', 'VIEW', 'BASE TABLE')
+			FROM system.tables`
+
+	rows, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return getPGStructure(ctx, rows)
+}
+
+func (c *clickhouseHTTPDriver) Close() {
+	c.c.CloseIdleConnections()
+}
+
+func (c *clickhouseHTTPDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *clickhouseHTTPDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteBacktick, core.LimitDialectLimit)
+}
+
+func (c *clickhouseHTTPDriver) ListDatabases() (current string, available []string, err error) {
+	query := `
+		SELECT currentDatabase(), name
+		FROM system.databases
+		WHERE name NOT IN (currentDatabase(), 'INFORMATION_SCHEMA')
+	`
+
+	rows, err := c.Query(context.TODO(), query)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return "", nil, err
+		}
+
+		current = fmt.Sprint(row[0])
+		available = append(available, fmt.Sprint(row[1]))
+	}
+
+	return current, available, nil
+}
+
+func (c *clickhouseHTTPDriver) SelectDatabase(name string) error {
+	c.database = name
+	return nil
+}
@@ -0,0 +1,153 @@
+package adapters
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func newClickhouseHTTPTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, "Code: 516. DB::Exception: user: Authentication failed")
+			return
+		}
+
+		params := r.URL.Query()
+		require.Equal(t, "/ch/", r.URL.Path)
+		require.Equal(t, clickhouseHTTPFormat, params.Get("default_format"))
+		require.Equal(t, "60", params.Get("max_execution_time"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		query := string(body)
+
+		if strings.Contains(query, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "Code: 60. DB::Exception: Table default.missing does not exist.")
+			return
+		}
+
+		var out io.Writer = w
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+
+		switch {
+		case strings.HasPrefix(query, "INSERT"):
+			w.Header().Set("X-ClickHouse-Summary", `{"read_rows":"0","written_rows":"3"}`)
+		case strings.Contains(query, "FORMAT CSV"):
+			fmt.Fprint(out, "1,\"a\"\n2,\"b\"\n")
+		case strings.Contains(query, "system.tables"):
+			fmt.Fprintln(out, `["database","name","type"]`)
+			fmt.Fprintln(out, `["String","String","String"]`)
+			fmt.Fprintln(out, `["default","events","BASE TABLE"]`)
+			fmt.Fprintln(out, `["default","events_mv","VIEW"]`)
+		default:
+			fmt.Fprintln(out, `["id","name","tags","db"]`)
+			fmt.Fprintln(out, `["UInt64","Nullable(String)","Array(String)","String"]`)
+			fmt.Fprintln(out, `["12345678901234567","alice",["a","b"],"`+params.Get("database")+`"]`)
+			fmt.Fprintln(out, `["2",null,[],"`+params.Get("database")+`"]`)
+			if strings.Contains(query, "broken") {
+				fmt.Fprintln(out, `Code: 241. DB::Exception: Memory limit exceeded`)
+			}
+		}
+	}))
+}
+
+func TestClickhouseHTTP(t *testing.T) {
+	r := require.New(t)
+
+	server := newClickhouseHTTPTestServer(t)
+	defer server.Close()
+
+	url := strings.Replace(server.URL, "http://", "clickhouse://user:secret@", 1) + "/ch?database=analytics&max_execution_time=60"
+	driver, err := (&ClickhouseHTTP{}).Connect(url)
+	r.NoError(err)
+	defer driver.Close()
+
+	// streamed query
+	result, err := driver.Query(context.Background(), "SELECT * FROM events")
+	r.NoError(err)
+	r.Equal(core.Header{"id", "name", "tags", "db"}, result.Header())
+
+	rows, err := drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{
+		{"12345678901234567", "alice", `["a","b"]`, "analytics"},
+		{"2", nil, `[]`, "analytics"},
+	}, rows)
+
+	// exception in the middle of the stream
+	result, err = driver.Query(context.Background(), "SELECT * FROM broken")
+	r.NoError(err)
+	rows, err = drainKsqlDBResult(t, result)
+	r.ErrorContains(err, "Memory limit exceeded")
+	r.Len(rows, 2)
+
+	// error response
+	_, err = driver.Query(context.Background(), "SELECT * FROM missing")
+	r.ErrorContains(err, "does not exist")
+
+	// statement without output
+	result, err = driver.Query(context.Background(), "INSERT INTO events VALUES (1), (2), (3)")
+	r.NoError(err)
+	rows, err = drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal(core.Header{"Rows Affected"}, result.Header())
+	r.Equal([]core.Row{{json.Number("3")}}, rows)
+
+	// explicit format
+	result, err = driver.Query(context.Background(), "SELECT * FROM events FORMAT CSV")
+	r.NoError(err)
+	rows, err = drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal(core.Header{"Result"}, result.Header())
+	r.Equal([]core.Row{{`1,"a"`}, {`2,"b"`}}, rows)
+
+	// database switching
+	r.NoError(driver.(core.DatabaseSwitcher).SelectDatabase("logs"))
+	result, err = driver.Query(context.Background(), "SELECT * FROM events")
+	r.NoError(err)
+	rows, err = drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal("logs", rows[0][3])
+
+	// structure
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name:   "default",
+			Schema: "default",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "events", Schema: "default", Type: core.StructureTypeTable},
+				{Name: "events_mv", Schema: "default", Type: core.StructureTypeView},
+			},
+		},
+	}, structure)
+
+	// invalid credentials
+	driver, err = (&ClickhouseHTTP{}).Connect(strings.Replace(server.URL, "http://", "clickhouse://user:wrong@", 1))
+	r.NoError(err)
+	_, err = driver.Query(context.Background(), "SELECT 1")
+	r.ErrorContains(err, "Authentication failed")
+}
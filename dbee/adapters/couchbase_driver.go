@@ -0,0 +1,357 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/couchbase/gocb/v2"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver           = (*couchbaseDriver)(nil)
+	_ core.DatabaseSwitcher = (*couchbaseDriver)(nil)
+)
+
+// couchbaseHeaderRows is the number of rows buffered to determine the columns of the result.
+const couchbaseHeaderRows = 100
+
+type couchbaseDriver struct {
+	cluster *gocb.Cluster
+	// selected bucket (query context), can be empty
+	bucket string
+}
+
+func (c *couchbaseDriver) query(ctx context.Context, query string) (*gocb.QueryResult, error) {
+	opts := &gocb.QueryOptions{
+		Context: ctx,
+		Metrics: true,
+	}
+
+	if c.bucket != "" {
+		return c.cluster.Bucket(c.bucket).DefaultScope().Query(query, opts)
+	}
+	return c.cluster.Query(query, opts)
+}
+
+// Query runs the N1QL statement. Result objects are flattened to "parent.child"
+// columns, which are determined from the first rows of the result (fields that
+// only appear later are not displayed). Query metrics are reported as result stats.
+func (c *couchbaseDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	res, err := c.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	readRecord := func() (map[string]any, error) {
+		var raw json.RawMessage
+		if err := res.Row(&raw); err != nil {
+			return nil, fmt.Errorf("res.Row: %w", err)
+		}
+		return couchbaseRecord(raw), nil
+	}
+
+	var buffered []map[string]any
+	for len(buffered) < couchbaseHeaderRows && res.Next() {
+		rec, err := readRecord()
+		if err != nil {
+			_ = res.Close()
+			return nil, err
+		}
+		buffered = append(buffered, rec)
+	}
+	if err := res.Err(); err != nil {
+		_ = res.Close()
+		return nil, err
+	}
+
+	header := couchbaseHeader(buffered)
+	if len(header) < 1 {
+		header = core.Header{"No Results"}
+	}
+
+	meta := &core.Meta{}
+
+	next, hasNext := builders.NextYield(func(yield func(...any)) error {
+		defer res.Close()
+
+		for _, rec := range buffered {
+			yield(couchbaseRow(header, rec)...)
+		}
+		for res.Next() {
+			rec, err := readRecord()
+			if err != nil {
+				return err
+			}
+			yield(couchbaseRow(header, rec)...)
+		}
+		if err := res.Err(); err != nil {
+			return err
+		}
+
+		if md, err := res.MetaData(); err == nil {
+			meta.Stats = couchbaseStats(md)
+		}
+		return nil
+	})
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		WithMeta(meta).
+		Build(), nil
+}
+
+// couchbaseRecord flattens the result row. Rows that are not objects
+// (e.g. of SELECT RAW) are returned in a "value" field.
+func couchbaseRecord(raw json.RawMessage) map[string]any {
+	rec := make(map[string]any)
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		rec["value"] = rawJSONValue(raw)
+		return rec
+	}
+
+	couchbaseFlatten("", obj, rec)
+	return rec
+}
+
+// couchbaseFlatten flattens nested objects into dst.
+func couchbaseFlatten(prefix string, obj map[string]json.RawMessage, dst map[string]any) {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		var nested map[string]json.RawMessage
+		if bytes.HasPrefix(bytes.TrimSpace(v), []byte("{")) && json.Unmarshal(v, &nested) == nil && len(nested) > 0 {
+			couchbaseFlatten(key, nested, dst)
+			continue
+		}
+		dst[key] = rawJSONValue(v)
+	}
+}
+
+// couchbaseHeader returns sorted field names of all records.
+func couchbaseHeader(records []map[string]any) core.Header {
+	fields := make(map[string]struct{})
+	for _, rec := range records {
+		for k := range rec {
+			fields[k] = struct{}{}
+		}
+	}
+
+	header := make(core.Header, 0, len(fields))
+	for k := range fields {
+		header = append(header, k)
+	}
+	sort.Strings(header)
+
+	return header
+}
+
+func couchbaseRow(header core.Header, rec map[string]any) []any {
+	row := make([]any, len(header))
+	for i, h := range header {
+		row[i] = rec[h]
+	}
+	return row
+}
+
+// couchbaseStats converts the query metadata to result stats.
+func couchbaseStats(md *gocb.QueryMetaData) map[string]string {
+	metrics := md.Metrics
+
+	stats := map[string]string{
+		"status":         string(md.Status),
+		"elapsed_time":   metrics.ElapsedTime.String(),
+		"execution_time": metrics.ExecutionTime.String(),
+		"result_count":   strconv.FormatUint(metrics.ResultCount, 10),
+		"result_size":    strconv.FormatUint(metrics.ResultSize, 10),
+	}
+
+	counts := map[string]uint64{
+		"mutation_count": metrics.MutationCount,
+		"sort_count":     metrics.SortCount,
+		"error_count":    metrics.ErrorCount,
+		"warning_count":  metrics.WarningCount,
+	}
+	for name, count := range counts {
+		if count > 0 {
+			stats[name] = strconv.FormatUint(count, 10)
+		}
+	}
+
+	if len(md.Warnings) > 0 {
+		warnings := make([]string, len(md.Warnings))
+		for i, w := range md.Warnings {
+			warnings[i] = fmt.Sprintf("%d: %s", w.Code, w.Message)
+		}
+		stats["warnings"] = strings.Join(warnings, "; ")
+	}
+
+	return stats
+}
+
+// Columns infers columns from a sample of documents of the collection.
+func (c *couchbaseDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	bucket, scope := couchbaseSchema(opts.Schema)
+	query := fmt.Sprintf("SELECT c.* FROM `%s`.`%s`.`%s` AS c LIMIT %d", bucket, scope, opts.Table, couchbaseHeaderRows)
+
+	res, err := c.cluster.Query(query, &gocb.QueryOptions{Context: context.Background()})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	var records []map[string]any
+	for res.Next() {
+		var raw json.RawMessage
+		if err := res.Row(&raw); err != nil {
+			return nil, fmt.Errorf("res.Row: %w", err)
+		}
+		records = append(records, couchbaseRecord(raw))
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+
+	return couchbaseColumns(records), nil
+}
+
+// couchbaseColumns returns fields of the records with the json type
+// of the first non-null value.
+func couchbaseColumns(records []map[string]any) []*core.Column {
+	header := couchbaseHeader(records)
+
+	columns := make([]*core.Column, len(header))
+	for i, name := range header {
+		typ := "null"
+		for _, rec := range records {
+			if t := couchbaseType(rec[name]); t != "null" {
+				typ = t
+				break
+			}
+		}
+		columns[i] = &core.Column{
+			Name: name,
+			Type: typ,
+		}
+	}
+
+	return columns
+}
+
+// couchbaseType returns the json type of the flattened value (see rawJSONValue).
+func couchbaseType(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		// nested values are kept as compact json
+		if strings.HasPrefix(val, "[") && json.Valid([]byte(val)) {
+			return "array"
+		}
+		return "string"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Structure lists buckets, their scopes and collections.
+func (c *couchbaseDriver) Structure() ([]*core.Structure, error) {
+	buckets, err := c.cluster.Buckets().GetAllBuckets(nil)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllBuckets: %w", err)
+	}
+
+	names := make([]string, 0, len(buckets))
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	structure := make([]*core.Structure, 0, len(names))
+	for _, name := range names {
+		scopes, err := c.cluster.Bucket(name).Collections().GetAllScopes(nil)
+		if err != nil {
+			return nil, fmt.Errorf("GetAllScopes: %w", err)
+		}
+		structure = append(structure, couchbaseStructure(name, scopes))
+	}
+
+	return structure, nil
+}
+
+// couchbaseStructure returns the bucket node with nested scopes and collections.
+// Schema of collections is in "bucket.scope" form.
+func couchbaseStructure(bucket string, scopes []gocb.ScopeSpec) *core.Structure {
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].Name < scopes[j].Name })
+
+	node := &core.Structure{
+		Name:   bucket,
+		Schema: bucket,
+		Type:   core.StructureTypeNone,
+	}
+
+	for _, scope := range scopes {
+		schema := bucket + "." + scope.Name
+
+		collections := scope.Collections
+		sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+
+		scopeNode := &core.Structure{
+			Name:   scope.Name,
+			Schema: schema,
+			Type:   core.StructureTypeNone,
+		}
+		for _, coll := range collections {
+			scopeNode.Children = append(scopeNode.Children, &core.Structure{
+				Name:   coll.Name,
+				Schema: schema,
+				Type:   core.StructureTypeTable,
+			})
+		}
+
+		node.Children = append(node.Children, scopeNode)
+	}
+
+	return node
+}
+
+func (c *couchbaseDriver) Close() {
+	_ = c.cluster.Close(nil)
+}
+
+func (c *couchbaseDriver) ListDatabases() (current string, available []string, err error) {
+	buckets, err := c.cluster.Buckets().GetAllBuckets(nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("GetAllBuckets: %w", err)
+	}
+
+	for name := range buckets {
+		if name != c.bucket {
+			available = append(available, name)
+		}
+	}
+	sort.Strings(available)
+
+	return c.bucket, available, nil
+}
+
+func (c *couchbaseDriver) SelectDatabase(name string) error {
+	c.bucket = name
+	return nil
+}
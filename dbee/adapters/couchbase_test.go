@@ -0,0 +1,135 @@
+package adapters
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestCouchbaseRecord(t *testing.T) {
+	r := require.New(t)
+
+	rec := couchbaseRecord(json.RawMessage(`{"id":"a1","geo":{"lat":1.5,"lon":2,"tags":{"x":true}},"list":[1, 2],"empty":{},"none":null}`))
+	r.Equal(map[string]any{
+		"id":         "a1",
+		"geo.lat":    json.Number("1.5"),
+		"geo.lon":    json.Number("2"),
+		"geo.tags.x": true,
+		"list":       "[1,2]",
+		"empty":      "{}",
+		"none":       nil,
+	}, rec)
+
+	// SELECT RAW rows
+	r.Equal(map[string]any{"value": json.Number("42")}, couchbaseRecord(json.RawMessage(`42`)))
+	r.Equal(map[string]any{"value": "name"}, couchbaseRecord(json.RawMessage(`"name"`)))
+}
+
+func TestCouchbaseHeaderAndRow(t *testing.T) {
+	r := require.New(t)
+
+	records := []map[string]any{
+		{"b": 1, "a.x": 2},
+		{"c": 3},
+	}
+
+	header := couchbaseHeader(records)
+	r.Equal(core.Header{"a.x", "b", "c"}, header)
+
+	r.Equal([]any{2, 1, nil}, couchbaseRow(header, records[0]))
+	r.Equal([]any{nil, nil, 3}, couchbaseRow(header, records[1]))
+}
+
+func TestCouchbaseColumns(t *testing.T) {
+	r := require.New(t)
+
+	records := []map[string]any{
+		couchbaseRecord(json.RawMessage(`{"name":"x","age":null,"tags":["a"]}`)),
+		couchbaseRecord(json.RawMessage(`{"name":"y","age":30,"active":false}`)),
+	}
+
+	r.Equal([]*core.Column{
+		{Name: "active", Type: "boolean"},
+		{Name: "age", Type: "number"},
+		{Name: "name", Type: "string"},
+		{Name: "tags", Type: "array"},
+	}, couchbaseColumns(records))
+}
+
+func TestCouchbaseStats(t *testing.T) {
+	r := require.New(t)
+
+	md := &gocb.QueryMetaData{
+		RequestID: "req-1",
+		Status:    gocb.QueryStatusSuccess,
+		Metrics: gocb.QueryMetrics{
+			ElapsedTime:   12 * time.Millisecond,
+			ExecutionTime: 10 * time.Millisecond,
+			ResultCount:   3,
+			ResultSize:    120,
+			SortCount:     3,
+			WarningCount:  1,
+		},
+		Warnings: []gocb.QueryWarning{
+			{Code: 4000, Message: "no index"},
+		},
+	}
+
+	r.Equal(map[string]string{
+		"status":         "success",
+		"elapsed_time":   "12ms",
+		"execution_time": "10ms",
+		"result_count":   "3",
+		"result_size":    "120",
+		"sort_count":     "3",
+		"warning_count":  "1",
+		"warnings":       "4000: no index",
+	}, couchbaseStats(md))
+}
+
+func TestCouchbaseStructure(t *testing.T) {
+	r := require.New(t)
+
+	node := couchbaseStructure("travel.sample", []gocb.ScopeSpec{
+		{Name: "inventory", Collections: []gocb.CollectionSpec{{Name: "route"}, {Name: "airline"}}},
+		{Name: "_default", Collections: []gocb.CollectionSpec{{Name: "_default"}}},
+	})
+
+	r.Equal(&core.Structure{
+		Name:   "travel.sample",
+		Schema: "travel.sample",
+		Type:   core.StructureTypeNone,
+		Children: []*core.Structure{
+			{
+				Name:   "_default",
+				Schema: "travel.sample._default",
+				Type:   core.StructureTypeNone,
+				Children: []*core.Structure{
+					{Name: "_default", Schema: "travel.sample._default", Type: core.StructureTypeTable},
+				},
+			},
+			{
+				Name:   "inventory",
+				Schema: "travel.sample.inventory",
+				Type:   core.StructureTypeNone,
+				Children: []*core.Structure{
+					{Name: "airline", Schema: "travel.sample.inventory", Type: core.StructureTypeTable},
+					{Name: "route", Schema: "travel.sample.inventory", Type: core.StructureTypeTable},
+				},
+			},
+		},
+	}, node)
+
+	bucket, scope := couchbaseSchema("travel.sample.inventory")
+	r.Equal("travel.sample", bucket)
+	r.Equal("inventory", scope)
+
+	bucket, scope = couchbaseSchema("beer")
+	r.Equal("beer", bucket)
+	r.Equal("_default", scope)
+}
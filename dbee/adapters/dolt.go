@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// Register client
+func init() {
+	_ = register(&Dolt{}, "dolt")
+}
+
+var _ core.Adapter = (*Dolt)(nil)
+
+// Dolt connects to dolt sql servers over the mysql protocol. Besides everything
+// [MySQL] supports, branches can be listed and checked out.
+type Dolt struct{}
+
+// Connect creates a [Dolt] client. The url is a mysql dsn, e.g.
+//
+//	user:password@tcp(host:port)/dbname?branch=name&param=value
+//
+// Where "branch" (optional) is the branch checked out after connecting.
+func (*Dolt) Connect(url string) (core.Driver, error) {
+	cfg, branch, err := parseDoltDSN(url)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openDolt(cfg, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doltDriver{
+		mySQLDriver: &mySQLDriver{
+			c: builders.NewClient(db, builders.WithWarnings(mySQLWarnings)),
+		},
+		cfg: cfg,
+	}, nil
+}
+
+// parseDoltDSN parses the dsn into the mysql config (without the "branch" parameter).
+func parseDoltDSN(dsn string) (*mysql.Config, string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("mysql.ParseDSN: %w", err)
+	}
+
+	// unknown parameters would be sent to the server as system variables
+	branch := cfg.Params["branch"]
+	delete(cfg.Params, "branch")
+
+	// add multiple statements support parameter
+	cfg.MultiStatements = true
+
+	return cfg, branch, nil
+}
+
+// openDolt opens a pool of connections which have the branch checked out.
+func openDolt(cfg *mysql.Config, branch string) (*sql.DB, error) {
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to dolt database: %v", err)
+	}
+
+	return sql.OpenDB(&doltConnector{Connector: connector, branch: branch}), nil
+}
+
+func (*Dolt) GetHelpers(opts *core.TableOptions) map[string]string {
+	helpers := (&MySQL{}).GetHelpers(opts)
+
+	helpers["Diff"] = fmt.Sprintf("SELECT * FROM `dolt_diff_%s` ORDER BY to_commit_date DESC LIMIT 500", opts.Table)
+	helpers["Working Changes"] = fmt.Sprintf("SELECT * FROM dolt_diff('HEAD', 'WORKING', %s)", core.QuoteLiteral(opts.Table))
+	helpers["History"] = fmt.Sprintf("SELECT * FROM `dolt_history_%s` ORDER BY commit_date DESC LIMIT 500", opts.Table)
+	helpers["Log"] = "SELECT * FROM dolt_log LIMIT 500"
+	helpers["Branches"] = "SELECT * FROM dolt_branches"
+
+	return helpers
+}
@@ -0,0 +1,114 @@
+package adapters
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	_ core.Driver           = (*doltDriver)(nil)
+	_ core.BranchSwitcher   = (*doltDriver)(nil)
+	_ core.DatabaseSwitcher = (*doltDriver)(nil)
+)
+
+// doltDriver is a mysql driver that can switch branches.
+type doltDriver struct {
+	*mySQLDriver
+	cfg *mysql.Config
+}
+
+// doltConnector checks out the branch on every new connection of the pool,
+// as checkouts only apply to the session they are run in.
+type doltConnector struct {
+	driver.Connector
+	branch string
+}
+
+func (c *doltConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil || c.branch == "" {
+		return conn, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, errors.New("connection can't execute statements")
+	}
+
+	_, err = execer.ExecContext(ctx, doltCheckoutQuery(c.branch), nil)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("checkout of branch %q: %w", c.branch, err)
+	}
+
+	return conn, nil
+}
+
+func doltCheckoutQuery(branch string) string {
+	return fmt.Sprintf("CALL DOLT_CHECKOUT(%s)", core.QuoteLiteral(branch))
+}
+
+// ListBranches lists branches of the current database.
+func (c *doltDriver) ListBranches() (current string, available []string, err error) {
+	rows, err := c.Query(context.TODO(), "SELECT active_branch(), name FROM dolt_branches ORDER BY name")
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return "", nil, err
+		}
+
+		current = fmt.Sprint(row[0])
+		if name := fmt.Sprint(row[1]); name != current {
+			available = append(available, name)
+		}
+	}
+
+	return current, available, nil
+}
+
+// SelectBranch checks out the branch. The connection pool is replaced with one
+// whose connections check out the branch, so it applies to all of them.
+func (c *doltDriver) SelectBranch(name string) error {
+	db, err := openDolt(c.cfg, name)
+	if err != nil {
+		return fmt.Errorf("unable to switch branches: %w", err)
+	}
+
+	// the checkout fails on connecting if the branch doesn't exist
+	if err := db.PingContext(context.TODO()); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("unable to switch branches: %w", err)
+	}
+
+	c.c.Swap(db)
+
+	return nil
+}
+
+// SelectDatabase reconnects to the database, which has its default branch checked out.
+func (c *doltDriver) SelectDatabase(name string) error {
+	cfg := c.cfg.Clone()
+	cfg.DBName = name
+
+	db, err := openDolt(cfg, "")
+	if err != nil {
+		return fmt.Errorf("unable to switch databases: %w", err)
+	}
+
+	c.cfg = cfg
+	c.c.Swap(db)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package adapters
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// doltTestConn records executed statements. Checkouts of the "missing" branch fail.
+type doltTestConn struct {
+	driver.Conn
+	executed *[]string
+	closed   bool
+}
+
+func (c *doltTestConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	*c.executed = append(*c.executed, query)
+	if query == "CALL DOLT_CHECKOUT('missing')" {
+		return nil, errors.New("branch not found")
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *doltTestConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type doltTestConnector struct {
+	driver.Connector
+	executed []string
+	conns    []*doltTestConn
+}
+
+func (c *doltTestConnector) Connect(_ context.Context) (driver.Conn, error) {
+	conn := &doltTestConn{executed: &c.executed}
+	c.conns = append(c.conns, conn)
+	return conn, nil
+}
+
+func TestDoltConnector(t *testing.T) {
+	r := require.New(t)
+
+	base := &doltTestConnector{}
+
+	// default branch
+	_, err := (&doltConnector{Connector: base}).Connect(context.Background())
+	r.NoError(err)
+	r.Empty(base.executed)
+
+	_, err = (&doltConnector{Connector: base, branch: "feature/o'neil"}).Connect(context.Background())
+	r.NoError(err)
+	r.Equal([]string{"CALL DOLT_CHECKOUT('feature/o''neil')"}, base.executed)
+
+	_, err = (&doltConnector{Connector: base, branch: "missing"}).Connect(context.Background())
+	r.ErrorContains(err, `checkout of branch "missing": branch not found`)
+	r.True(base.conns[2].closed)
+}
+
+func TestDolt_Connect(t *testing.T) {
+	r := require.New(t)
+
+	cfg, branch, err := parseDoltDSN("root:pass@tcp(localhost:3306)/shop?branch=dev&parseTime=true")
+	r.NoError(err)
+	r.Equal("dev", branch)
+	r.Equal("shop", cfg.DBName)
+	r.NotContains(cfg.Params, "branch")
+	r.True(cfg.ParseTime)
+	r.True(cfg.MultiStatements)
+
+	d, err := (&Dolt{}).Connect("root@tcp(localhost:3306)/shop")
+	r.NoError(err)
+	defer d.Close()
+	r.Implements((*core.BranchSwitcher)(nil), d)
+	r.Implements((*core.ContextStructurer)(nil), d)
+	r.Implements((*core.DatabaseSwitcher)(nil), d)
+
+	helpers := (&Dolt{}).GetHelpers(&core.TableOptions{Schema: "shop", Table: "orders"})
+	r.Equal("SELECT * FROM `dolt_diff_orders` ORDER BY to_commit_date DESC LIMIT 500", helpers["Diff"])
+	r.Equal("SELECT * FROM dolt_diff('HEAD', 'WORKING', 'orders')", helpers["Working Changes"])
+	r.Equal("SELECT * FROM `orders` LIMIT 500", helpers["List"])
+}
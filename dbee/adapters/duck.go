@@ -21,7 +21,13 @@ var _ core.Adapter = (*Duck)(nil)
 
 type Duck struct{}
 
+// Connect opens the database file at the path in the url or an in-memory
+// database if the url is empty or ":memory:".
 func (d *Duck) Connect(url string) (core.Driver, error) {
+	if url == ":memory:" {
+		url = ""
+	}
+
 	db, err := sql.Open("duckdb", url)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to duckdb database: %v", err)
@@ -33,10 +39,13 @@ func (d *Duck) Connect(url string) (core.Driver, error) {
 }
 
 func (*Duck) GetHelpers(opts *core.TableOptions) map[string]string {
+	database, schema := duckCatalog(opts)
+	filter := fmt.Sprintf("database_name = %s AND schema_name = %s AND table_name = %s", database, schema, duckString(opts.Table))
+
 	return map[string]string{
-		"List":        fmt.Sprintf("SELECT * FROM %q LIMIT 500", opts.Table),
-		"Columns":     fmt.Sprintf("DESCRIBE %q", opts.Table),
-		"Indexes":     fmt.Sprintf("SELECT * FROM duckdb_indexes() WHERE table_name = '%s'", opts.Table),
-		"Constraints": fmt.Sprintf("SELECT * FROM duckdb_constraints() WHERE table_name = '%s'", opts.Table),
+		"List":        fmt.Sprintf("SELECT * FROM %s LIMIT 500", duckTable(opts)),
+		"Columns":     fmt.Sprintf("DESCRIBE %s", duckTable(opts)),
+		"Indexes":     fmt.Sprintf("SELECT * FROM duckdb_indexes() WHERE %s", filter),
+		"Constraints": fmt.Sprintf("SELECT * FROM duckdb_constraints() WHERE %s", filter),
 	}
 }
@@ -2,12 +2,24 @@ package adapters
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*duckDriver)(nil)
+var (
+	_ core.Driver            = (*duckDriver)(nil)
+	_ core.ContextStructurer = (*duckDriver)(nil)
+	_ core.Limiter           = (*duckDriver)(nil)
+	_ core.ParamQuerier      = (*duckDriver)(nil)
+	_ core.Peeker            = (*duckDriver)(nil)
+	_ core.SafeScanner       = (*duckDriver)(nil)
+	_ core.StatementLogger   = (*duckDriver)(nil)
+	_ core.TableImporter     = (*duckDriver)(nil)
+	_ core.TopValuer         = (*duckDriver)(nil)
+)
 
 type duckDriver struct {
 	c *builders.Client
@@ -17,37 +29,171 @@ func (c *duckDriver) Query(ctx context.Context, query string) (core.ResultStream
 	return c.c.QueryUntilNotEmpty(ctx, query)
 }
 
+// Columns reads information_schema.columns. Tables of attached databases have
+// the schema prefixed with the database (see StructureCtx).
 func (c *duckDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
-	return c.c.ColumnsFromQuery("DESCRIBE %q", opts.Table)
+	database, schema := duckCatalog(opts)
+
+	return c.c.ColumnsFromQuery(`
+		SELECT
+			column_name,
+			data_type,
+			character_maximum_length,
+			numeric_precision,
+			numeric_scale
+		FROM information_schema.columns
+		WHERE table_catalog = %s AND table_schema = %s AND table_name = %s
+		ORDER BY ordinal_position`,
+		database, schema, duckString(opts.Table))
 }
 
 func (c *duckDriver) Structure() ([]*core.Structure, error) {
-	query := `SHOW TABLES;`
+	return c.StructureCtx(context.Background())
+}
 
-	rows, err := c.Query(context.TODO(), query)
+// StructureCtx lists tables and views of all databases, including the ones added with ATTACH,
+// grouped by database (the current one first). Schema of tables in attached databases is
+// prefixed with the database name ("other.main"), so they can be referred to from the current one.
+func (c *duckDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	query := `
+		SELECT database_name, schema_name, name, type, database_name = current_database() AS is_current
+		FROM (
+			SELECT database_name, schema_name, table_name AS name, 'TABLE' AS type FROM duckdb_tables() WHERE NOT internal
+			UNION ALL
+			SELECT database_name, schema_name, view_name AS name, 'VIEW' AS type FROM duckdb_views() WHERE NOT internal
+		)
+		ORDER BY NOT is_current, database_name, schema_name, name`
+
+	rows, err := c.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	var schema []*core.Structure
+	var structure []*core.Structure
+	databases := make(map[string]*core.Structure)
+
 	for rows.HasNext() {
 		row, err := rows.Next()
 		if err != nil {
 			return nil, err
 		}
 
-		// We know for a fact there is only one string field (see query above)
-		table := row[0].(string)
-		schema = append(schema, &core.Structure{
-			Name:   table,
-			Schema: "",
-			Type:   core.StructureTypeTable,
+		// We know for a fact there are 4 string fields and a boolean (see query above)
+		database, schema, name, typ := row[0].(string), row[1].(string), row[2].(string), row[3].(string)
+		if current, _ := row[4].(bool); !current {
+			schema = database + "." + schema
+		}
+
+		parent, ok := databases[database]
+		if !ok {
+			parent = &core.Structure{
+				Name:   database,
+				Schema: database,
+				Type:   core.StructureTypeNone,
+			}
+			databases[database] = parent
+			structure = append(structure, parent)
+		}
+
+		materialization := core.StructureTypeTable
+		if typ == "VIEW" {
+			materialization = core.StructureTypeView
+		}
+
+		parent.Children = append(parent.Children, &core.Structure{
+			Name:   name,
+			Schema: schema,
+			Type:   materialization,
 		})
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	return schema, nil
+	return structure, nil
 }
 
 func (c *duckDriver) Close() {
 	c.c.Close()
 }
+
+func (c *duckDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *duckDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *duckDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderQuestion
+}
+
+func (c *duckDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQueryFrom(duckTable(opts), column, n, builders.QuoteDouble, core.LimitDialectLimit)
+}
+
+func (c *duckDriver) ImportTable(ctx context.Context, table string, rows core.ResultStream) (int, error) {
+	return c.c.ImportTable(ctx, table, rows, builders.QuoteDouble, duckColumnType)
+}
+
+// duckColumnType returns the type of imported columns.
+func duckColumnType(kind builders.ColumnKind) string {
+	switch kind {
+	case builders.ColumnKindInteger:
+		return "BIGINT"
+	case builders.ColumnKindReal:
+		return "DOUBLE"
+	case builders.ColumnKindBoolean:
+		return "BOOLEAN"
+	case builders.ColumnKindTime:
+		return "TIMESTAMP"
+	case builders.ColumnKindBlob:
+		return "BLOB"
+	}
+	return "VARCHAR"
+}
+
+func (c *duckDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return c.c.Peek(ctx, query)
+}
+
+func (c *duckDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *duckDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+// duckTable returns the quoted table reference. The schema is either a schema of the
+// current database or "database.schema" for attached databases (see StructureCtx).
+func duckTable(opts *core.TableOptions) string {
+	if opts.Schema == "" {
+		return core.QuoteIdentifier(opts.Table)
+	}
+
+	parts := strings.SplitN(opts.Schema, ".", 2)
+	for i, p := range parts {
+		parts[i] = core.QuoteIdentifier(p)
+	}
+	return strings.Join(append(parts, core.QuoteIdentifier(opts.Table)), ".")
+}
+
+// duckCatalog returns the database (current_database() or a literal) and the schema
+// (a literal) of the table as sql expressions.
+func duckCatalog(opts *core.TableOptions) (database string, schema string) {
+	database, schema = "current_database()", opts.Schema
+	if db, s, ok := strings.Cut(opts.Schema, "."); ok {
+		database, schema = duckString(db), s
+	}
+	if schema == "" {
+		schema = "main"
+	}
+	return database, duckString(schema)
+}
+
+// duckString returns the value as a string literal.
+func duckString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
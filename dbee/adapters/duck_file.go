@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// duckFileReaders are DuckDB table functions reading files by extension.
+var duckFileReaders = map[string]string{
+	".parquet": "read_parquet",
+	".parq":    "read_parquet",
+	".csv":     "read_csv_auto",
+	".tsv":     "read_csv_auto",
+	".txt":     "read_csv_auto",
+	".json":    "read_json_auto",
+	".jsonl":   "read_json_auto",
+	".ndjson":  "read_json_auto",
+}
+
+// DuckFileQuery returns a DuckDB query selecting all rows of a local Parquet, CSV or JSON
+// file (e.g. a stored result). The path can be a glob (e.g. "data/*.parquet"), files
+// matching it are read as a single table. Compressed files (".gz", ".zst") are supported.
+func DuckFileQuery(path string) (string, error) {
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return "", fmt.Errorf("filepath.Glob: %w", err)
+	}
+	if len(matches) < 1 {
+		return "", fmt.Errorf("no files match %q", path)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".gz" || ext == ".zst" {
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path))))
+	}
+
+	reader, ok := duckFileReaders[ext]
+	if !ok {
+		return "", fmt.Errorf("unsupported file type %q: expected parquet, csv or json", ext)
+	}
+
+	return fmt.Sprintf("SELECT * FROM %s('%s')", reader, strings.ReplaceAll(path, "'", "''")), nil
+}
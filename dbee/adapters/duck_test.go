@@ -0,0 +1,176 @@
+//go:build cgo && ((darwin && (amd64 || arm64)) || (linux && (amd64 || arm64 || riscv64)))
+
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestDuckDriver_Booleans(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&Duck{}).Connect("")
+	r.NoError(err)
+	defer driver.Close()
+
+	rows, err := driver.Query(context.Background(), `SELECT 1 AS id, true AS active, CAST(NULL AS BOOLEAN) AS deleted`)
+	r.NoError(err)
+	defer rows.Close()
+
+	r.True(rows.HasNext())
+	row, err := rows.Next()
+	r.NoError(err)
+	r.Equal(core.Row{int32(1), true, nil}, row)
+}
+
+func TestDuckFileQuery(t *testing.T) {
+	r := require.New(t)
+
+	dir := filepath.Join(t.TempDir(), "it's data")
+	r.NoError(os.Mkdir(dir, 0o755))
+	r.NoError(os.WriteFile(filepath.Join(dir, "a.csv"), []byte("id,name\n1,alice\n2,bob\n"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(dir, "b.csv"), []byte("id,name\n3,carol\n"), 0o644))
+	r.NoError(os.WriteFile(filepath.Join(dir, "c.json"), []byte(`[{"id": 4, "name": "dave"}]`), 0o644))
+
+	driver, err := (&Duck{}).Connect("")
+	r.NoError(err)
+	defer driver.Close()
+
+	count := func(path string) int64 {
+		t.Helper()
+
+		query, err := DuckFileQuery(path)
+		r.NoError(err)
+
+		rows, err := driver.Query(context.Background(), fmt.Sprintf("SELECT count(*) FROM (%s)", query))
+		r.NoError(err)
+		defer rows.Close()
+
+		r.True(rows.HasNext())
+		row, err := rows.Next()
+		r.NoError(err)
+		return row[0].(int64)
+	}
+
+	r.EqualValues(3, count(filepath.Join(dir, "*.csv")))
+
+	// parquet and json readers are extensions, which might not be available offline
+	r.NoError(os.WriteFile(filepath.Join(dir, "d.parquet"), nil, 0o644))
+	query, err := DuckFileQuery(filepath.Join(dir, "*.parquet"))
+	r.NoError(err)
+	r.Equal(fmt.Sprintf("SELECT * FROM read_parquet('%s')", strings.ReplaceAll(filepath.Join(dir, "*.parquet"), "'", "''")), query)
+
+	query, err = DuckFileQuery(filepath.Join(dir, "c.json"))
+	r.NoError(err)
+	r.Contains(query, "read_json_auto(")
+
+	_, err = DuckFileQuery(filepath.Join(dir, "*.xlsx"))
+	r.ErrorContains(err, "no files match")
+	_, err = DuckFileQuery(filepath.Join(dir, "a.csv") + "x")
+	r.ErrorContains(err, "no files match")
+	r.NoError(os.WriteFile(filepath.Join(dir, "e.xlsx"), nil, 0o644))
+	_, err = DuckFileQuery(filepath.Join(dir, "e.xlsx"))
+	r.ErrorContains(err, "unsupported file type")
+}
+
+func TestDuckDriver_ImportTable(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&Duck{}).Connect("")
+	r.NoError(err)
+	defer driver.Close()
+
+	importer := driver.(core.TableImporter)
+
+	count, err := importer.ImportTable(context.Background(), "pg_public_users", mock.NewResultStream(
+		[]core.Row{{1, "alice", 1.5, true}, {2, nil, 2, false}},
+		mock.ResultStreamWithHeader(core.Header{"id", "name", "score", "active"}),
+	))
+	r.NoError(err)
+	r.Equal(2, count)
+
+	rows, err := driver.Query(context.Background(), `SELECT typeof(id), typeof(score), typeof(active), count(*) FROM pg_public_users GROUP BY ALL`)
+	r.NoError(err)
+	defer rows.Close()
+
+	r.True(rows.HasNext())
+	row, err := rows.Next()
+	r.NoError(err)
+	r.Equal(core.Row{"BIGINT", "DOUBLE", "BOOLEAN", int64(2)}, row)
+}
+
+func TestDuckDriver_Structure(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&Duck{}).Connect(":memory:")
+	r.NoError(err)
+	defer driver.Close()
+
+	other := filepath.Join(t.TempDir(), "other.db")
+	for _, query := range []string{
+		`CREATE TABLE users (id INTEGER, name VARCHAR(20))`,
+		`CREATE VIEW active_users AS SELECT * FROM users`,
+		`CREATE SCHEMA sales`,
+		`CREATE TABLE sales.orders (id INTEGER, total DECIMAL(10, 2))`,
+		fmt.Sprintf(`ATTACH '%s'`, other),
+		`CREATE TABLE other.events (day DATE)`,
+	} {
+		rows, err := driver.Query(context.Background(), query)
+		r.NoError(err, query)
+		rows.Close()
+	}
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name:   "memory",
+			Schema: "memory",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "active_users", Schema: "main", Type: core.StructureTypeView},
+				{Name: "users", Schema: "main", Type: core.StructureTypeTable},
+				{Name: "orders", Schema: "sales", Type: core.StructureTypeTable},
+			},
+		},
+		{
+			Name:   "other",
+			Schema: "other",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "events", Schema: "other.main", Type: core.StructureTypeTable},
+			},
+		},
+	}, structure)
+
+	columns, err := driver.Columns(&core.TableOptions{Schema: "sales", Table: "orders"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "INTEGER", Precision: 32},
+		{Name: "total", Type: "DECIMAL(10,2)", Precision: 10, Scale: 2},
+	}, columns)
+
+	opts := &core.TableOptions{Schema: "other.main", Table: "events"}
+	columns, err = driver.Columns(opts)
+	r.NoError(err)
+	r.Equal([]*core.Column{{Name: "day", Type: "DATE"}}, columns)
+
+	// helpers refer to the attached database
+	for name, helper := range (&Duck{}).GetHelpers(opts) {
+		rows, err := driver.Query(context.Background(), helper)
+		r.NoError(err, name)
+		rows.Close()
+	}
+	r.Equal(`SELECT "day", COUNT(*) AS cnt FROM "other"."main"."events" GROUP BY "day" ORDER BY cnt DESC LIMIT 3`,
+		driver.(*duckDriver).TopValues(opts, "day", 3))
+}
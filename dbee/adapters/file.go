@@ -0,0 +1,58 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&File{}, "file")
+}
+
+var _ core.Adapter = (*File)(nil)
+
+// File browses CSV, TSV and JSON files of a local directory as tables, without any database.
+// Queries are a small subset of SQL evaluated in memory (see parseFileQuery), for anything
+// more, use DuckDB (see DuckFileQuery).
+type File struct{}
+
+// Connect creates a [File] client of the directory. The url is the path of the directory,
+// optionally with a "file://" prefix (e.g. "~/data" or "file:///home/user/data").
+func (*File) Connect(url string) (core.Driver, error) {
+	dir := strings.TrimPrefix(url, "file://")
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("os.UserHomeDir: %w", err)
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.Stat: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", dir)
+	}
+
+	return &fileDriver{dir: dir}, nil
+}
+
+func (*File) GetHelpers(opts *core.TableOptions) map[string]string {
+	table := fileQuote(opts.Table)
+	return map[string]string{
+		"List":  fmt.Sprintf("SELECT * FROM %s LIMIT 500", table),
+		"Count": fmt.Sprintf("SELECT COUNT(*) FROM %s", table),
+	}
+}
+
+// fileQuote returns the quoted name of the table (file).
+func fileQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
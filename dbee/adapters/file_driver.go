@@ -0,0 +1,490 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver  = (*fileDriver)(nil)
+	_ core.Limiter = (*fileDriver)(nil)
+)
+
+// fileInferRows is the number of rows column types are inferred from.
+const fileInferRows = 100
+
+// fileExtensions are extensions of files listed as tables.
+var fileExtensions = map[string]bool{
+	".csv":  true,
+	".tsv":  true,
+	".json": true,
+}
+
+type fileDriver struct {
+	dir string
+}
+
+// fileTable is the (partially) loaded content of a file.
+type fileTable struct {
+	header core.Header
+	types  []string
+	rows   []core.Row
+}
+
+func (d *fileDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	q, err := parseFileQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := d.path(q.table)
+	if err != nil {
+		return nil, err
+	}
+	table, err := loadFileTable(path, -1)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	header, rows, err := q.run(table.header, table.rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return fileResult(header, rows), nil
+}
+
+// fileResult builds a result stream of the rows.
+func fileResult(header core.Header, rows []core.Row) core.ResultStream {
+	i := 0
+	hasNext := func() bool {
+		return i < len(rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		i++
+		return rows[i-1], nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build()
+}
+
+// Columns returns columns of the file with types inferred from the first rows
+// ("integer", "double", "boolean", "json" or "text").
+func (d *fileDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	path, err := d.path(opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	table, err := loadFileTable(path, fileInferRows)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*core.Column, len(table.header))
+	for i, name := range table.header {
+		columns[i] = &core.Column{
+			Name: name,
+			Type: table.types[i],
+		}
+	}
+	return columns, nil
+}
+
+// Structure lists the files of the directory as tables.
+func (d *fileDriver) Structure() ([]*core.Structure, error) {
+	files, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+
+	structure := make([]*core.Structure, len(files))
+	for i, name := range files {
+		structure[i] = &core.Structure{
+			Name: name,
+			Type: core.StructureTypeTable,
+		}
+	}
+	return structure, nil
+}
+
+func (d *fileDriver) Close() {}
+
+func (d *fileDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+// files returns sorted names of the supported files of the directory.
+func (d *fileDriver) files() ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && fileExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// path returns the path of the table. Tables are named by files, but the extension
+// can be left out if no other file has the same name.
+func (d *fileDriver) path(table string) (string, error) {
+	files, err := d.files()
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, name := range files {
+		if name == table {
+			return filepath.Join(d.dir, name), nil
+		}
+		if strings.TrimSuffix(name, filepath.Ext(name)) == table {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("table %q not found in %q", table, d.dir)
+	case 1:
+		return filepath.Join(d.dir, matches[0]), nil
+	default:
+		return "", fmt.Errorf("table %q is ambiguous: %s", table, strings.Join(matches, ", "))
+	}
+}
+
+// loadFileTable loads at most maxRows rows (all if negative) of the file.
+func loadFileTable(path string, maxRows int) (*fileTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return loadJSONTable(f, maxRows)
+	case ".tsv":
+		return loadCSVTable(f, '\t', maxRows)
+	default:
+		return loadCSVTable(f, ',', maxRows)
+	}
+}
+
+// loadCSVTable reads the header from the first record. Empty fields are NULL and
+// others are converted to the type of the column inferred from the first rows.
+func loadCSVTable(r io.Reader, delimiter rune, maxRows int) (*fileTable, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &fileTable{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reader.Read: %w", err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	var records [][]string
+	for maxRows < 0 || len(records) < maxRows {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reader.Read: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	types := make([]string, len(header))
+	for i := range header {
+		types[i] = inferCSVType(records, i)
+	}
+
+	rows := make([]core.Row, len(records))
+	for r, record := range records {
+		row := make(core.Row, len(header))
+		for i := range header {
+			if i < len(record) && record[i] != "" {
+				row[i] = csvValue(record[i], types[i])
+			}
+		}
+		rows[r] = row
+	}
+
+	return &fileTable{header: header, types: types, rows: rows}, nil
+}
+
+// inferCSVType returns the type of all non-empty values of the column in the first rows.
+func inferCSVType(records [][]string, column int) string {
+	typ := ""
+	for _, record := range records[:min(len(records), fileInferRows)] {
+		if column >= len(record) || record[column] == "" {
+			continue
+		}
+		value := record[column]
+
+		valueType := "text"
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			valueType = "integer"
+		} else if _, err := strconv.ParseFloat(value, 64); err == nil {
+			valueType = "double"
+		} else if _, err := strconv.ParseBool(strings.ToLower(value)); err == nil && len(value) > 1 {
+			valueType = "boolean"
+		}
+
+		typ = mergeFileTypes(typ, valueType)
+	}
+
+	if typ == "" {
+		return "text"
+	}
+	return typ
+}
+
+// mergeFileTypes returns the type of values of both types.
+func mergeFileTypes(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case a == "integer" && b == "double" || a == "double" && b == "integer":
+		return "double"
+	default:
+		return "text"
+	}
+}
+
+// csvValue converts the value to the type of its column. Values which can't be
+// converted (e.g. after the rows the type is inferred from) are kept as text.
+func csvValue(value, typ string) any {
+	switch typ {
+	case "integer":
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case "double":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(strings.ToLower(value)); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// loadJSONTable reads either an array of objects or newline delimited objects.
+// Columns are keys of the objects in the order they first appear. Nested objects
+// and arrays are kept as json text.
+func loadJSONTable(r io.Reader, maxRows int) (*fileTable, error) {
+	reader := bufio.NewReader(r)
+	if bom, _ := reader.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		_, _ = reader.Discard(3)
+	}
+
+	// the first character tells if the file is an array
+	var first byte
+	for {
+		b, err := reader.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return &fileTable{}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reader.ReadByte: %w", err)
+		}
+		if !unicode.IsSpace(rune(b)) {
+			first = b
+			_ = reader.UnreadByte()
+			break
+		}
+	}
+
+	dec := json.NewDecoder(reader)
+	if first == '[' {
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("dec.Token: %w", err)
+		}
+	}
+
+	var (
+		header  core.Header
+		indexes = make(map[string]int)
+		rows    []core.Row
+	)
+	for (maxRows < 0 || len(rows) < maxRows) && dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("dec.Decode: %w", err)
+		}
+		keys, values, err := decodeJSONObject(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if _, ok := indexes[key]; !ok {
+				indexes[key] = len(header)
+				header = append(header, key)
+			}
+		}
+
+		row := make(core.Row, len(header))
+		for i, key := range keys {
+			row[indexes[key]] = values[i]
+		}
+		rows = append(rows, row)
+	}
+
+	// rows before new keys appeared are shorter
+	for i, row := range rows {
+		for len(row) < len(header) {
+			row = append(row, nil)
+		}
+		rows[i] = row
+	}
+
+	types := make([]string, len(header))
+	for i := range header {
+		typ := ""
+		for _, row := range rows[:min(len(rows), fileInferRows)] {
+			if row[i] != nil {
+				typ = mergeFileTypes(typ, jsonType(row[i]))
+			}
+		}
+		if typ == "" {
+			typ = "text"
+		}
+		types[i] = typ
+
+		// whole numbers of columns with fractions
+		if typ == "double" {
+			for _, row := range rows {
+				if v, ok := row[i].(int64); ok {
+					row[i] = float64(v)
+				}
+			}
+		}
+	}
+
+	return &fileTable{header: header, types: types, rows: rows}, nil
+}
+
+// decodeJSONObject returns keys of the object in order with their values. Numbers are
+// converted to int64 (if whole) or float64, nested objects and arrays to json text.
+func decodeJSONObject(raw json.RawMessage) ([]string, []any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, fmt.Errorf("expected an object, got: %.50s", raw)
+	}
+
+	var (
+		keys   []string
+		values []any
+	)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, fmt.Errorf("dec.Token: %w", err)
+		}
+		key, _ := tok.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, fmt.Errorf("dec.Decode: %w", err)
+		}
+
+		keys = append(keys, key)
+		values = append(values, jsonValue(value))
+	}
+
+	return keys, values, nil
+}
+
+// jsonValue converts a json value to a value of a row.
+func jsonValue(raw json.RawMessage) any {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) < 1 {
+		return nil
+	}
+
+	switch raw[0] {
+	case 'n':
+		return nil
+	case 't', 'f':
+		return raw[0] == 't'
+	case '"':
+		var s string
+		_ = json.Unmarshal(raw, &s)
+		return s
+	case '{', '[':
+		var b bytes.Buffer
+		if err := json.Compact(&b, raw); err != nil {
+			return string(raw)
+		}
+		return b.String()
+	default:
+		if v, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+			return v
+		}
+		if v, err := strconv.ParseFloat(string(raw), 64); err == nil {
+			return v
+		}
+		return string(raw)
+	}
+}
+
+// jsonType returns the column type of a converted json value. Nested objects and arrays
+// are told from strings by their first character.
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case int64:
+		return "integer"
+	case float64:
+		return "double"
+	case bool:
+		return "boolean"
+	case string:
+		if strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[") {
+			return "json"
+		}
+		return "text"
+	default:
+		return "text"
+	}
+}
@@ -0,0 +1,836 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// fileQuery is a parsed query of the file adapter. The supported subset of SQL is:
+//
+//	SELECT * | COUNT(*) | column [[AS] alias], ...
+//	FROM table
+//	[WHERE condition]
+//	[ORDER BY column [ASC | DESC], ...]
+//	[LIMIT count [OFFSET skip]]
+//
+// Conditions compare columns and literals (=, <>, !=, <, <=, >, >=, [NOT] LIKE,
+// [NOT] IN (...), [NOT] BETWEEN ... AND ..., IS [NOT] NULL), combined with AND, OR,
+// NOT and parentheses. Identifiers can be quoted with double quotes or backticks.
+type fileQuery struct {
+	// selected columns, none if all are selected
+	columns []fileColumn
+	count   bool
+	table   string
+	where   fileCondition
+	// column operands of the condition
+	operands []*fileOperand
+	orderBy  []fileOrder
+	// -1 if not limited
+	limit  int
+	offset int
+}
+
+type fileColumn struct {
+	name  string
+	alias string
+}
+
+type fileOrder struct {
+	column string
+	desc   bool
+}
+
+type fileTokenKind int
+
+const (
+	// keywords and plain identifiers
+	fileTokenWord fileTokenKind = iota
+	fileTokenQuoted
+	fileTokenString
+	fileTokenNumber
+	fileTokenSymbol
+)
+
+type fileToken struct {
+	kind fileTokenKind
+	text string
+}
+
+// tokenizeFileQuery splits the query into tokens. Comments are skipped.
+func tokenizeFileQuery(query string) ([]fileToken, error) {
+	var tokens []fileToken
+	i := 0
+	for i < len(query) {
+		ch := query[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += end + 4
+		case ch == '\'' || ch == '"' || ch == '`':
+			end, ok := fileQuotedEnd(query, i)
+			if !ok {
+				return nil, errors.New("unterminated string")
+			}
+			text := strings.ReplaceAll(query[i+1:end-1], string(ch)+string(ch), string(ch))
+			kind := fileTokenQuoted
+			if ch == '\'' {
+				kind = fileTokenString
+			}
+			tokens = append(tokens, fileToken{kind: kind, text: text})
+			i = end
+		case ch >= '0' && ch <= '9' || ch == '.' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			start := i
+			for i < len(query) && (query[i] >= '0' && query[i] <= '9' || query[i] == '.' ||
+				query[i] == 'e' || query[i] == 'E' ||
+				(query[i] == '-' || query[i] == '+') && (query[i-1] == 'e' || query[i-1] == 'E')) {
+				i++
+			}
+			tokens = append(tokens, fileToken{kind: fileTokenNumber, text: query[start:i]})
+		case isFileWordChar(ch):
+			start := i
+			for i < len(query) && isFileWordChar(query[i]) {
+				i++
+			}
+			tokens = append(tokens, fileToken{kind: fileTokenWord, text: query[start:i]})
+		default:
+			symbol := query[i : i+1]
+			if i+1 < len(query) {
+				switch two := query[i : i+2]; two {
+				case "<=", ">=", "<>", "!=":
+					symbol = two
+				}
+			}
+			if !strings.Contains("*,().;=<>!-", symbol[:1]) {
+				return nil, fmt.Errorf("unexpected character %q", symbol[:1])
+			}
+			tokens = append(tokens, fileToken{kind: fileTokenSymbol, text: symbol})
+			i += len(symbol)
+		}
+	}
+
+	// trailing semicolons
+	for len(tokens) > 0 && tokens[len(tokens)-1] == (fileToken{kind: fileTokenSymbol, text: ";"}) {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	return tokens, nil
+}
+
+// fileQuotedEnd returns the position after the quoted string (or identifier) starting at i.
+// Doubled quotes are escaped quotes.
+func fileQuotedEnd(query string, i int) (int, bool) {
+	quote := query[i]
+	j := i + 1
+	for {
+		end := strings.IndexByte(query[j:], quote)
+		if end < 0 {
+			return 0, false
+		}
+		j += end + 1
+		if j < len(query) && query[j] == quote {
+			j++
+			continue
+		}
+		return j, true
+	}
+}
+
+func isFileWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch >= 0x80
+}
+
+// fileParser parses tokens of a query.
+type fileParser struct {
+	tokens []fileToken
+	pos    int
+	// column operands of conditions, resolved when the query is run
+	operands []*fileOperand
+}
+
+func (p *fileParser) peek() fileToken {
+	if p.pos >= len(p.tokens) {
+		return fileToken{kind: fileTokenSymbol}
+	}
+	return p.tokens[p.pos]
+}
+
+// keyword consumes the next token if it's one of the keywords.
+func (p *fileParser) keyword(keywords ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != fileTokenWord {
+		return "", false
+	}
+	for _, kw := range keywords {
+		if strings.EqualFold(tok.text, kw) {
+			p.pos++
+			return kw, true
+		}
+	}
+	return "", false
+}
+
+// symbol consumes the next token if it's one of the symbols.
+func (p *fileParser) symbol(symbols ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != fileTokenSymbol {
+		return "", false
+	}
+	for _, sym := range symbols {
+		if tok.text == sym {
+			p.pos++
+			return sym, true
+		}
+	}
+	return "", false
+}
+
+func (p *fileParser) expectKeyword(keyword string) error {
+	if _, ok := p.keyword(keyword); !ok {
+		return p.unexpected(keyword)
+	}
+	return nil
+}
+
+func (p *fileParser) expectSymbol(symbol string) error {
+	if _, ok := p.symbol(symbol); !ok {
+		return p.unexpected(fmt.Sprintf("%q", symbol))
+	}
+	return nil
+}
+
+func (p *fileParser) unexpected(expected string) error {
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("expected %s, got end of query", expected)
+	}
+	return fmt.Errorf("expected %s, got %q", expected, p.tokens[p.pos].text)
+}
+
+// fileKeywords can't be used as plain identifiers.
+var fileKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "ORDER": true, "BY": true, "LIMIT": true,
+	"OFFSET": true, "AS": true, "AND": true, "OR": true, "NOT": true, "ASC": true, "DESC": true,
+	"LIKE": true, "IN": true, "IS": true, "NULL": true, "BETWEEN": true, "TRUE": true, "FALSE": true,
+}
+
+// identifier consumes a (possibly dotted) identifier. Dotted parts are joined, as file
+// names are used as table names (e.g. users.csv).
+func (p *fileParser) identifier() (string, error) {
+	var parts []string
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == fileTokenQuoted:
+		case tok.kind == fileTokenWord && !fileKeywords[strings.ToUpper(tok.text)]:
+		default:
+			return "", p.unexpected("identifier")
+		}
+		p.pos++
+		parts = append(parts, tok.text)
+
+		if _, ok := p.symbol("."); !ok {
+			return strings.Join(parts, "."), nil
+		}
+	}
+}
+
+// parseFileQuery parses the query (see fileQuery for the supported syntax).
+func parseFileQuery(query string) (*fileQuery, error) {
+	tokens, err := tokenizeFileQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &fileParser{tokens: tokens}
+
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, fmt.Errorf("%w (only SELECT queries are supported)", err)
+	}
+
+	q := &fileQuery{limit: -1}
+	switch {
+	case p.peek() == fileToken{kind: fileTokenSymbol, text: "*"}:
+		p.pos++
+	case strings.EqualFold(p.peek().text, "COUNT") && p.peek().kind == fileTokenWord:
+		p.pos++
+		for _, sym := range []string{"(", "*", ")"} {
+			if err := p.expectSymbol(sym); err != nil {
+				return nil, err
+			}
+		}
+		q.count = true
+	default:
+		for {
+			name, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			col := fileColumn{name: name, alias: name}
+			_, hasAs := p.keyword("AS")
+			if tok := p.peek(); hasAs || tok.kind == fileTokenQuoted ||
+				tok.kind == fileTokenWord && !fileKeywords[strings.ToUpper(tok.text)] {
+				if col.alias, err = p.identifier(); err != nil {
+					return nil, err
+				}
+			}
+			q.columns = append(q.columns, col)
+
+			if _, ok := p.symbol(","); !ok {
+				break
+			}
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if q.table, err = p.identifier(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.keyword("WHERE"); ok {
+		if q.where, err = p.or(); err != nil {
+			return nil, err
+		}
+		q.operands = p.operands
+	}
+
+	if _, ok := p.keyword("ORDER"); ok {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			name, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			dir, _ := p.keyword("ASC", "DESC")
+			q.orderBy = append(q.orderBy, fileOrder{column: name, desc: dir == "DESC"})
+
+			if _, ok := p.symbol(","); !ok {
+				break
+			}
+		}
+	}
+
+	if _, ok := p.keyword("LIMIT"); ok {
+		if q.limit, err = p.count(); err != nil {
+			return nil, err
+		}
+		if _, ok := p.keyword("OFFSET"); ok {
+			if q.offset, err = p.count(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q (the file adapter supports SELECT with WHERE, ORDER BY and LIMIT only)", p.tokens[p.pos].text)
+	}
+
+	return q, nil
+}
+
+// count consumes a non-negative integer.
+func (p *fileParser) count() (int, error) {
+	tok := p.peek()
+	n, err := strconv.Atoi(tok.text)
+	if tok.kind != fileTokenNumber || err != nil || n < 0 {
+		return 0, p.unexpected("a number")
+	}
+	p.pos++
+	return n, nil
+}
+
+// fileTruth is a value of three-valued logic (comparisons with NULL are unknown).
+type fileTruth int
+
+const (
+	fileFalse fileTruth = iota
+	fileTrue
+	fileUnknown
+)
+
+func fileTruthOf(b bool) fileTruth {
+	if b {
+		return fileTrue
+	}
+	return fileFalse
+}
+
+func (t fileTruth) not() fileTruth {
+	switch t {
+	case fileTrue:
+		return fileFalse
+	case fileFalse:
+		return fileTrue
+	default:
+		return fileUnknown
+	}
+}
+
+// fileCondition is a condition of the WHERE clause.
+type fileCondition func(core.Row) fileTruth
+
+// fileOperand is a column or a literal.
+type fileOperand struct {
+	column string
+	// index of the column in rows
+	index   int
+	literal any
+}
+
+func (o *fileOperand) value(row core.Row) any {
+	if o.column == "" {
+		return o.literal
+	}
+	return row[o.index]
+}
+
+func (p *fileParser) or() (fileCondition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.keyword("OR"); !ok {
+			return left, nil
+		}
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row core.Row) fileTruth {
+			a, b := l(row), right(row)
+			switch {
+			case a == fileTrue || b == fileTrue:
+				return fileTrue
+			case a == fileUnknown || b == fileUnknown:
+				return fileUnknown
+			default:
+				return fileFalse
+			}
+		}
+	}
+}
+
+func (p *fileParser) and() (fileCondition, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.keyword("AND"); !ok {
+			return left, nil
+		}
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row core.Row) fileTruth {
+			a, b := l(row), right(row)
+			switch {
+			case a == fileFalse || b == fileFalse:
+				return fileFalse
+			case a == fileUnknown || b == fileUnknown:
+				return fileUnknown
+			default:
+				return fileTrue
+			}
+		}
+	}
+}
+
+func (p *fileParser) not() (fileCondition, error) {
+	if _, ok := p.keyword("NOT"); ok {
+		cond, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return func(row core.Row) fileTruth { return cond(row).not() }, nil
+	}
+
+	if _, ok := p.symbol("("); ok {
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return cond, p.expectSymbol(")")
+	}
+
+	return p.predicate()
+}
+
+// predicate parses a comparison of an operand.
+func (p *fileParser) predicate() (fileCondition, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	if op, ok := p.symbol("=", "<>", "!=", "<", "<=", ">", ">="); ok {
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return func(row core.Row) fileTruth {
+			cmp, ok := compareFileValues(left.value(row), right.value(row))
+			if !ok {
+				return fileUnknown
+			}
+			return fileTruthOf(fileCompare(op, cmp))
+		}, nil
+	}
+
+	if _, ok := p.keyword("IS"); ok {
+		_, negated := p.keyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return func(row core.Row) fileTruth {
+			return fileTruthOf((left.value(row) == nil) != negated)
+		}, nil
+	}
+
+	_, negated := p.keyword("NOT")
+	kw, ok := p.keyword("LIKE", "IN", "BETWEEN")
+	if !ok {
+		return nil, p.unexpected("a comparison")
+	}
+
+	var cond fileCondition
+	switch kw {
+	case "LIKE":
+		tok := p.peek()
+		if tok.kind != fileTokenString {
+			return nil, p.unexpected("a pattern string")
+		}
+		p.pos++
+		pattern := likePattern(tok.text)
+		cond = func(row core.Row) fileTruth {
+			v := left.value(row)
+			if v == nil {
+				return fileUnknown
+			}
+			return fileTruthOf(pattern.MatchString(fmt.Sprint(v)))
+		}
+	case "IN":
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		var list []*fileOperand
+		for {
+			operand, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, operand)
+			if _, ok := p.symbol(","); !ok {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		cond = func(row core.Row) fileTruth {
+			result := fileFalse
+			for _, operand := range list {
+				cmp, ok := compareFileValues(left.value(row), operand.value(row))
+				if !ok {
+					result = fileUnknown
+				} else if cmp == 0 {
+					return fileTrue
+				}
+			}
+			return result
+		}
+	case "BETWEEN":
+		low, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		cond = func(row core.Row) fileTruth {
+			v := left.value(row)
+			lowCmp, ok1 := compareFileValues(v, low.value(row))
+			highCmp, ok2 := compareFileValues(v, high.value(row))
+			if !ok1 || !ok2 {
+				return fileUnknown
+			}
+			return fileTruthOf(lowCmp >= 0 && highCmp <= 0)
+		}
+	}
+
+	if negated {
+		return func(row core.Row) fileTruth { return cond(row).not() }, nil
+	}
+	return cond, nil
+}
+
+// operand parses a column or a literal.
+func (p *fileParser) operand() (*fileOperand, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == fileTokenString:
+		p.pos++
+		return &fileOperand{literal: tok.text}, nil
+	case tok.kind == fileTokenNumber:
+		p.pos++
+		return &fileOperand{literal: fileNumber(tok.text)}, nil
+	case tok.kind == fileTokenSymbol && tok.text == "-":
+		p.pos++
+		num := p.peek()
+		if num.kind != fileTokenNumber {
+			return nil, p.unexpected("a number")
+		}
+		p.pos++
+		return &fileOperand{literal: fileNumber("-" + num.text)}, nil
+	case tok.kind == fileTokenWord && strings.EqualFold(tok.text, "NULL"):
+		p.pos++
+		return &fileOperand{}, nil
+	case tok.kind == fileTokenWord && (strings.EqualFold(tok.text, "TRUE") || strings.EqualFold(tok.text, "FALSE")):
+		p.pos++
+		return &fileOperand{literal: strings.EqualFold(tok.text, "TRUE")}, nil
+	}
+
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	operand := &fileOperand{column: name}
+	p.operands = append(p.operands, operand)
+	return operand, nil
+}
+
+func fileNumber(text string) any {
+	if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return v
+	}
+	v, _ := strconv.ParseFloat(text, 64)
+	return v
+}
+
+// likePattern converts the pattern of LIKE to a regular expression.
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	for _, ch := range pattern {
+		switch ch {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func fileCompare(op string, cmp int) bool {
+	switch op {
+	case "=":
+		return cmp == 0
+	case "<>", "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// compareFileValues compares two values: numbers numerically (also with strings that
+// are numbers), booleans with booleans and anything else as text. It's not ok if
+// either of the values is NULL.
+func compareFileValues(a, b any) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	if x, ok := fileFloat(a); ok {
+		if y, ok := fileFloat(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case !x:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)), true
+}
+
+func fileFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, !math.IsNaN(v)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// run evaluates the query on rows of the table.
+func (q *fileQuery) run(header core.Header, rows []core.Row) (core.Header, []core.Row, error) {
+	columns := make(map[string]int, len(header))
+	for i := len(header) - 1; i >= 0; i-- {
+		columns[header[i]] = i
+	}
+	// resolve returns the name of the column as in the header (case-insensitive if there's no exact match)
+	resolve := func(name string) (string, error) {
+		if _, ok := columns[name]; ok {
+			return name, nil
+		}
+		for _, col := range header {
+			if strings.EqualFold(col, name) {
+				return col, nil
+			}
+		}
+		return "", fmt.Errorf("unknown column %q", name)
+	}
+
+	selected := make([]fileColumn, len(q.columns))
+	for i, col := range q.columns {
+		name, err := resolve(col.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		selected[i] = fileColumn{name: name, alias: col.alias}
+	}
+
+	for _, operand := range q.operands {
+		name, err := resolve(operand.column)
+		if err != nil {
+			return nil, nil, err
+		}
+		operand.index = columns[name]
+	}
+
+	var filtered []core.Row
+	for _, row := range rows {
+		if q.where == nil || q.where(row) == fileTrue {
+			filtered = append(filtered, row)
+		}
+	}
+
+	if q.count {
+		return core.Header{"count"}, []core.Row{{int64(len(filtered))}}, nil
+	}
+
+	if len(q.orderBy) > 0 {
+		indexes := make([]int, len(q.orderBy))
+		for i, order := range q.orderBy {
+			name := order.column
+			// aliases of selected columns
+			for _, col := range selected {
+				if col.alias == order.column {
+					name = col.name
+				}
+			}
+			name, err := resolve(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			indexes[i] = columns[name]
+		}
+
+		sort.SliceStable(filtered, func(i, j int) bool {
+			for k, idx := range indexes {
+				a, b := filtered[i][idx], filtered[j][idx]
+				// NULLs are last (first if descending)
+				cmp, ok := compareFileValues(a, b)
+				if !ok {
+					switch {
+					case a == nil && b == nil:
+						cmp = 0
+					case a == nil:
+						cmp = 1
+					default:
+						cmp = -1
+					}
+				}
+				if q.orderBy[k].desc {
+					cmp = -cmp
+				}
+				if cmp != 0 {
+					return cmp < 0
+				}
+			}
+			return false
+		})
+	}
+
+	filtered = filtered[min(q.offset, len(filtered)):]
+	if q.limit >= 0 && q.limit < len(filtered) {
+		filtered = filtered[:q.limit]
+	}
+
+	if len(selected) < 1 {
+		return header, filtered, nil
+	}
+
+	projected := make(core.Header, len(selected))
+	for i, col := range selected {
+		projected[i] = col.alias
+	}
+	out := make([]core.Row, len(filtered))
+	for r, row := range filtered {
+		values := make(core.Row, len(selected))
+		for i, col := range selected {
+			values[i] = row[columns[col.name]]
+		}
+		out[r] = values
+	}
+
+	return projected, out, nil
+}
@@ -0,0 +1,151 @@
+package adapters
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func newFileTestDriver(t *testing.T) core.Driver {
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.csv": "id,name,score,active\n" +
+			"1,alice,9.5,true\n" +
+			"2,bob,,false\n" +
+			"3,\"carol, jr\",7,true\n" +
+			"4,dave,12,\n",
+		"events.tsv":  "day\tkind\n2024-01-01\tlogin\n2024-01-02\tlogout\n",
+		"orders.json": `[{"id": 1, "user": 1, "items": ["a", "b"], "total": 10.5}, {"id": 2, "user": 3, "total": 3, "note": null}]`,
+		"lines.json":  "{\"b\": 1, \"a\": \"x\"}\n{\"a\": \"y\", \"c\": {\"d\": true}}\n",
+		"notes.txt":   "not a table",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	d, err := (&File{}).Connect(dir)
+	require.NoError(t, err)
+	return d
+}
+
+func fileTestRows(t *testing.T, d core.Driver, query string) (core.Header, []core.Row) {
+	result, err := d.Query(context.Background(), query)
+	require.NoError(t, err)
+	defer result.Close()
+
+	var rows []core.Row
+	for result.HasNext() {
+		row, err := result.Next()
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+	return result.Header(), rows
+}
+
+func TestFileDriver_Structure(t *testing.T) {
+	r := require.New(t)
+
+	d := newFileTestDriver(t)
+
+	structure, err := d.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Name: "events.tsv", Type: core.StructureTypeTable},
+		{Name: "lines.json", Type: core.StructureTypeTable},
+		{Name: "orders.json", Type: core.StructureTypeTable},
+		{Name: "users.csv", Type: core.StructureTypeTable},
+	}, structure)
+
+	columns, err := d.Columns(&core.TableOptions{Table: "users.csv"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "integer"},
+		{Name: "name", Type: "text"},
+		{Name: "score", Type: "double"},
+		{Name: "active", Type: "boolean"},
+	}, columns)
+
+	columns, err = d.Columns(&core.TableOptions{Table: "orders.json"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "integer"},
+		{Name: "user", Type: "integer"},
+		{Name: "items", Type: "json"},
+		{Name: "total", Type: "double"},
+		{Name: "note", Type: "text"},
+	}, columns)
+
+	_, err = (&File{}).Connect(filepath.Join(t.TempDir(), "missing"))
+	r.Error(err)
+}
+
+func TestFileDriver_Query(t *testing.T) {
+	r := require.New(t)
+
+	d := newFileTestDriver(t)
+
+	header, rows := fileTestRows(t, d, `SELECT * FROM users.csv`)
+	r.Equal(core.Header{"id", "name", "score", "active"}, header)
+	r.Equal([]core.Row{
+		{int64(1), "alice", 9.5, true},
+		{int64(2), "bob", nil, false},
+		{int64(3), "carol, jr", float64(7), true},
+		{int64(4), "dave", float64(12), nil},
+	}, rows)
+
+	// the extension can be left out
+	header, rows = fileTestRows(t, d, `SELECT name AS n, score FROM users WHERE score > 8 OR score IS NULL ORDER BY score DESC`)
+	r.Equal(core.Header{"n", "score"}, header)
+	r.Equal([]core.Row{{"bob", nil}, {"dave", float64(12)}, {"alice", 9.5}}, rows)
+
+	_, rows = fileTestRows(t, d, `SELECT id FROM "users.csv" WHERE NOT (name LIKE 'c%' OR id IN (1, 2)) AND active IS NOT NULL`)
+	r.Empty(rows)
+
+	_, rows = fileTestRows(t, d, `SELECT id FROM users WHERE id BETWEEN 2 AND 4 ORDER BY active DESC, id LIMIT 2 OFFSET 1`)
+	r.Equal([]core.Row{{int64(3)}, {int64(2)}}, rows)
+
+	// comparisons with NULL are unknown, so neither the condition nor its negation is true
+	_, rows = fileTestRows(t, d, `SELECT id FROM users WHERE NOT score < 10`)
+	r.Equal([]core.Row{{int64(4)}}, rows)
+
+	header, rows = fileTestRows(t, d, `SELECT COUNT(*) FROM users WHERE active = true;`)
+	r.Equal(core.Header{"count"}, header)
+	r.Equal([]core.Row{{int64(2)}}, rows)
+
+	_, rows = fileTestRows(t, d, `SELECT kind FROM events WHERE day >= '2024-01-02'`)
+	r.Equal([]core.Row{{"logout"}}, rows)
+
+	header, rows = fileTestRows(t, d, `SELECT * FROM orders WHERE total < 5`)
+	r.Equal(core.Header{"id", "user", "items", "total", "note"}, header)
+	r.Equal([]core.Row{{int64(2), int64(3), nil, float64(3), nil}}, rows)
+
+	header, rows = fileTestRows(t, d, `SELECT * FROM lines`)
+	r.Equal(core.Header{"b", "a", "c"}, header)
+	r.Equal([]core.Row{{int64(1), "x", nil}, {nil, "y", `{"d":true}`}}, rows)
+}
+
+func TestFileDriver_QueryErrors(t *testing.T) {
+	r := require.New(t)
+
+	d := newFileTestDriver(t)
+
+	for query, msg := range map[string]string{
+		`DELETE FROM users`:                     "expected SELECT, got \"DELETE\" (only SELECT queries are supported)",
+		`SELECT * FROM missing`:                 "table \"missing\" not found in",
+		`SELECT * FROM notes`:                   "table \"notes\" not found in",
+		`SELECT nope FROM users`:                "unknown column \"nope\"",
+		`SELECT * FROM users WHERE nope = 1`:    "unknown column \"nope\"",
+		`SELECT * FROM users GROUP BY name`:     "unexpected \"GROUP\"",
+		`SELECT * FROM users WHERE name LIKE 1`: "expected a pattern string, got \"1\"",
+		`SELECT * FROM users LIMIT`:             "expected a number, got end of query",
+		`SELECT * FROM users WHERE name = 'x`:   "unterminated string",
+	} {
+		_, err := d.Query(context.Background(), query)
+		r.ErrorContains(err, msg, query)
+	}
+}
@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"fmt"
+	"net/http"
+	nurl "net/url"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Iceberg{}, "iceberg")
+}
+
+var (
+	_ core.Adapter   = (*Iceberg)(nil)
+	_ core.Validator = (*Iceberg)(nil)
+)
+
+// Iceberg browses namespaces and tables of an Apache Iceberg REST catalog.
+// Queries are delegated to a query engine connection (e.g. Trino or DuckDB), if one is configured.
+type Iceberg struct{}
+
+// Connect creates an [Iceberg] client.
+// The format of the url is as follows:
+//
+//	iceberg://catalog-host[:port][/path][?options]
+//
+// Where:
+//   - "icebergs" (or "https") scheme can be used instead of "iceberg" (or "http") to connect over https.
+//   - "path" is the base path of the catalog api (without "/v1").
+//   - "options" is an ampersand-separated list of key=value arguments.
+//
+// The supported "options" are:
+//   - warehouse=name - warehouse requested from the catalog.
+//   - token=value - bearer token sent to the catalog.
+//   - engine=type - type of the connection queries are delegated to (e.g. "duck").
+//   - engine-url=url - url of the engine connection.
+//
+// Instead of a static token, OAuth2 can be used by passing the "oauth-token-url",
+// "oauth-client-id", "oauth-client-secret", "oauth-refresh-token" and "oauth-scopes"
+// options (see newOAuthTokenSource).
+func (i *Iceberg) Connect(rawURL string) (core.Driver, error) {
+	if err := i.Validate(rawURL); err != nil {
+		return nil, err
+	}
+
+	u, _ := nurl.Parse(rawURL)
+	scheme := "http"
+	if u.Scheme == "icebergs" || u.Scheme == "https" {
+		scheme = "https"
+	}
+
+	params := u.Query()
+	oauth, _ := newOAuthTokenSource(params)
+
+	driver := &icebergDriver{
+		c:         &http.Client{},
+		url:       &nurl.URL{Scheme: scheme, Host: u.Host, Path: strings.TrimSuffix(u.Path, "/")},
+		warehouse: params.Get("warehouse"),
+		token:     params.Get("token"),
+	}
+	if oauth != nil {
+		driver.c = oauth.client()
+	}
+
+	if typ := params.Get("engine"); typ != "" {
+		adapter, _ := new(Mux).GetAdapter(typ)
+
+		var err error
+		driver.engine, err = adapter.Connect(params.Get("engine-url"))
+		if err != nil {
+			return nil, fmt.Errorf("adapter.Connect: %w", err)
+		}
+	}
+
+	return driver, nil
+}
+
+// Validate checks the url and the url of the engine connection.
+func (*Iceberg) Validate(rawURL string) error {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	switch u.Scheme {
+	case "iceberg", "http", "icebergs", "https":
+	default:
+		return fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	params := u.Query()
+	if _, err := newOAuthTokenSource(params); err != nil {
+		return err
+	}
+
+	typ := params.Get("engine")
+	if typ == "" {
+		return nil
+	}
+	adapter, err := new(Mux).GetAdapter(typ)
+	if err != nil {
+		return fmt.Errorf("engine %q: %w", typ, err)
+	}
+	if validator, ok := adapter.(core.Validator); ok {
+		if err := validator.Validate(params.Get("engine-url")); err != nil {
+			return fmt.Errorf("engine-url: %w", err)
+		}
+	}
+	return nil
+}
+
+func (*Iceberg) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List":      fmt.Sprintf("SELECT * FROM %s.%q LIMIT 500", icebergQuoteNamespace(opts.Schema), opts.Table),
+		"Snapshots": fmt.Sprintf("SHOW SNAPSHOTS %s.%s", opts.Schema, opts.Table),
+	}
+}
+
+// icebergQuoteNamespace quotes every level of a dot separated namespace.
+func icebergQuoteNamespace(namespace string) string {
+	levels := strings.Split(namespace, ".")
+	for i, level := range levels {
+		levels[i] = fmt.Sprintf("%q", level)
+	}
+	return strings.Join(levels, ".")
+}
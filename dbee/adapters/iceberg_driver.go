@@ -0,0 +1,441 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var _ core.Driver = (*icebergDriver)(nil)
+
+// icebergSnapshotsQuery is a pseudo statement handled by the driver,
+// which lists snapshots of a table from the catalog.
+var icebergSnapshotsQuery = regexp.MustCompile(`(?i)^\s*SHOW\s+SNAPSHOTS\s+([^\s;]+)\s*;?\s*$`)
+
+// icebergNamespaceSeparator separates levels of multipart namespaces in the urls.
+const icebergNamespaceSeparator = "\x1f"
+
+type icebergDriver struct {
+	c         *http.Client
+	url       *nurl.URL
+	warehouse string
+	token     string
+
+	// engine runs the queries (nil if not configured)
+	engine core.Driver
+
+	mu sync.Mutex
+	// prefix of the catalog paths returned by the config endpoint (nil until fetched)
+	prefix *string
+}
+
+// icebergError is the error response of the rest catalog.
+type icebergError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// icebergField is a field of an iceberg schema. The type is either a
+// primitive type name or a nested struct, list or map type.
+type icebergField struct {
+	Name     string          `json:"name"`
+	Required bool            `json:"required"`
+	Type     json.RawMessage `json:"type"`
+}
+
+type icebergSchema struct {
+	SchemaID int            `json:"schema-id"`
+	Fields   []icebergField `json:"fields"`
+}
+
+// icebergMetadata is the part of the table metadata used by the driver.
+type icebergMetadata struct {
+	CurrentSchemaID   int             `json:"current-schema-id"`
+	Schemas           []icebergSchema `json:"schemas"`
+	Schema            *icebergSchema  `json:"schema"`
+	CurrentSnapshotID *int64          `json:"current-snapshot-id"`
+	Snapshots         []struct {
+		SnapshotID       int64             `json:"snapshot-id"`
+		ParentSnapshotID *int64            `json:"parent-snapshot-id"`
+		TimestampMs      int64             `json:"timestamp-ms"`
+		ManifestList     string            `json:"manifest-list"`
+		Summary          map[string]string `json:"summary"`
+	} `json:"snapshots"`
+}
+
+func (c *icebergDriver) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("c.c.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var ierr icebergError
+		if err := json.Unmarshal(body, &ierr); err == nil && ierr.Error.Message != "" {
+			return nil, fmt.Errorf("iceberg error %d (%s): %s", ierr.Error.Code, ierr.Error.Type, ierr.Error.Message)
+		}
+		return nil, fmt.Errorf("iceberg catalog responded with %q: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
+
+// get sends a get request to the "/v1" api and decodes the json response.
+func (c *icebergDriver) get(ctx context.Context, path []string, params nurl.Values, v any) error {
+	u := c.url.JoinPath(append([]string{"v1"}, path...)...)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return nil
+}
+
+// catalogPath returns the path of the catalog resource, prefixed
+// with the prefix the catalog returns for the warehouse.
+func (c *icebergDriver) catalogPath(ctx context.Context, path ...string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.prefix == nil {
+		var params nurl.Values
+		if c.warehouse != "" {
+			params = nurl.Values{"warehouse": {c.warehouse}}
+		}
+
+		var config struct {
+			Defaults  map[string]string `json:"defaults"`
+			Overrides map[string]string `json:"overrides"`
+		}
+		if err := c.get(ctx, []string{"config"}, params, &config); err != nil {
+			return nil, err
+		}
+
+		prefix := config.Defaults["prefix"]
+		if p, ok := config.Overrides["prefix"]; ok {
+			prefix = p
+		}
+		c.prefix = &prefix
+	}
+
+	if *c.prefix == "" {
+		return path, nil
+	}
+	return append([]string{*c.prefix}, path...), nil
+}
+
+// icebergList follows the pages of a list endpoint and collects values of the field of every page.
+func icebergList[T any](ctx context.Context, c *icebergDriver, path []string, params nurl.Values, field func(page json.RawMessage) ([]T, error)) ([]T, error) {
+	path, err := c.catalogPath(ctx, path...)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = nurl.Values{}
+	}
+
+	var items []T
+	for {
+		var page json.RawMessage
+		if err := c.get(ctx, path, params, &page); err != nil {
+			return nil, err
+		}
+
+		values, err := field(page)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, values...)
+
+		var next struct {
+			NextPageToken string `json:"next-page-token"`
+		}
+		_ = json.Unmarshal(page, &next)
+		if next.NextPageToken == "" {
+			return items, nil
+		}
+		params.Set("pageToken", next.NextPageToken)
+	}
+}
+
+func (c *icebergDriver) namespaces(ctx context.Context, parent []string) ([][]string, error) {
+	var params nurl.Values
+	if len(parent) > 0 {
+		params = nurl.Values{"parent": {strings.Join(parent, icebergNamespaceSeparator)}}
+	}
+
+	return icebergList(ctx, c, []string{"namespaces"}, params, func(page json.RawMessage) ([][]string, error) {
+		var resp struct {
+			Namespaces [][]string `json:"namespaces"`
+		}
+		if err := json.Unmarshal(page, &resp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		return resp.Namespaces, nil
+	})
+}
+
+func (c *icebergDriver) tables(ctx context.Context, namespace []string) ([]string, error) {
+	path := []string{"namespaces", strings.Join(namespace, icebergNamespaceSeparator), "tables"}
+
+	return icebergList(ctx, c, path, nil, func(page json.RawMessage) ([]string, error) {
+		var resp struct {
+			Identifiers []struct {
+				Name string `json:"name"`
+			} `json:"identifiers"`
+		}
+		if err := json.Unmarshal(page, &resp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+
+		names := make([]string, len(resp.Identifiers))
+		for i, ident := range resp.Identifiers {
+			names[i] = ident.Name
+		}
+		return names, nil
+	})
+}
+
+// loadTable returns the metadata of the table in the dot separated namespace.
+func (c *icebergDriver) loadTable(ctx context.Context, namespace, table string) (*icebergMetadata, error) {
+	path, err := c.catalogPath(ctx, "namespaces", strings.ReplaceAll(namespace, ".", icebergNamespaceSeparator), "tables", table)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Metadata icebergMetadata `json:"metadata"`
+	}
+	if err := c.get(ctx, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Metadata, nil
+}
+
+func (c *icebergDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	if m := icebergSnapshotsQuery.FindStringSubmatch(query); m != nil {
+		return c.snapshots(ctx, m[1])
+	}
+
+	if c.engine == nil {
+		return nil, errors.New(`no query engine configured: set the "engine" and "engine-url" options to run queries`)
+	}
+	return c.engine.Query(ctx, query)
+}
+
+// snapshots returns the snapshots of the table ("namespace.table") with a row per snapshot.
+func (c *icebergDriver) snapshots(ctx context.Context, ident string) (core.ResultStream, error) {
+	i := strings.LastIndex(ident, ".")
+	if i < 0 {
+		return nil, fmt.Errorf("expected a table in form of namespace.table, got %q", ident)
+	}
+
+	metadata, err := c.loadTable(ctx, ident[:i], ident[i+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := metadata.Snapshots
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].TimestampMs < snapshots[j].TimestampMs
+	})
+
+	idx := 0
+	hasNext := func() bool {
+		return idx < len(snapshots)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+
+		s := snapshots[idx]
+		idx++
+
+		var parent any
+		if s.ParentSnapshotID != nil {
+			parent = *s.ParentSnapshotID
+		}
+		current := metadata.CurrentSnapshotID != nil && *metadata.CurrentSnapshotID == s.SnapshotID
+
+		return core.Row{
+			s.SnapshotID,
+			parent,
+			time.UnixMilli(s.TimestampMs).UTC(),
+			s.Summary["operation"],
+			current,
+			s.ManifestList,
+		}, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(core.Header{"snapshot_id", "parent_id", "committed_at", "operation", "current", "manifest_list"}).
+		Build(), nil
+}
+
+func (c *icebergDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	metadata, err := c.loadTable(context.Background(), opts.Schema, opts.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	// format v1 tables might only have a single schema
+	schema := metadata.Schema
+	for i := range metadata.Schemas {
+		if metadata.Schemas[i].SchemaID == metadata.CurrentSchemaID {
+			schema = &metadata.Schemas[i]
+		}
+	}
+	if schema == nil {
+		return nil, errors.New("table metadata has no current schema")
+	}
+
+	columns := make([]*core.Column, len(schema.Fields))
+	for i, field := range schema.Fields {
+		typ := icebergTypeString(field.Type)
+		columns[i] = &core.Column{
+			Name: field.Name,
+			Type: typ,
+		}
+
+		var precision, scale int64
+		if n, _ := fmt.Sscanf(typ, "decimal(%d,%d)", &precision, &scale); n == 2 {
+			columns[i].Precision, columns[i].Scale = precision, scale
+		}
+		var length int64
+		if n, _ := fmt.Sscanf(typ, "fixed[%d]", &length); n == 1 {
+			columns[i].MaxLength = length
+		}
+	}
+
+	return columns, nil
+}
+
+// icebergTypeString converts a field type to a string, nested types are
+// written as struct<name: type, ...>, list<type> and map<key, value>.
+func icebergTypeString(raw json.RawMessage) string {
+	var primitive string
+	if err := json.Unmarshal(raw, &primitive); err == nil {
+		return primitive
+	}
+
+	var nested struct {
+		Type    string          `json:"type"`
+		Fields  []icebergField  `json:"fields"`
+		Element json.RawMessage `json:"element"`
+		Key     json.RawMessage `json:"key"`
+		Value   json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		return string(raw)
+	}
+
+	switch nested.Type {
+	case "struct":
+		fields := make([]string, len(nested.Fields))
+		for i, f := range nested.Fields {
+			fields[i] = f.Name + ": " + icebergTypeString(f.Type)
+		}
+		return "struct<" + strings.Join(fields, ", ") + ">"
+	case "list":
+		return "list<" + icebergTypeString(nested.Element) + ">"
+	case "map":
+		return "map<" + icebergTypeString(nested.Key) + ", " + icebergTypeString(nested.Value) + ">"
+	}
+	return nested.Type
+}
+
+// Structure lists namespaces (nested namespaces as children) and their tables.
+func (c *icebergDriver) Structure() ([]*core.Structure, error) {
+	return c.namespaceStructure(context.Background(), nil)
+}
+
+func (c *icebergDriver) namespaceStructure(ctx context.Context, parent []string) ([]*core.Structure, error) {
+	namespaces, err := c.namespaces(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return strings.Join(namespaces[i], ".") < strings.Join(namespaces[j], ".")
+	})
+
+	var structure []*core.Structure
+	for _, ns := range namespaces {
+		// catalogs without support for nested namespaces ignore the parent
+		if len(ns) != len(parent)+1 || strings.Join(ns[:len(parent)], ".") != strings.Join(parent, ".") {
+			continue
+		}
+		schema := strings.Join(ns, ".")
+
+		children, err := c.namespaceStructure(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+
+		tables, err := c.tables(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			children = append(children, &core.Structure{
+				Name:   table,
+				Schema: schema,
+				Type:   core.StructureTypeTable,
+			})
+		}
+
+		structure = append(structure, &core.Structure{
+			Name:     ns[len(ns)-1],
+			Schema:   schema,
+			Type:     core.StructureTypeNone,
+			Children: children,
+		})
+	}
+
+	return structure, nil
+}
+
+func (c *icebergDriver) Close() {
+	c.c.CloseIdleConnections()
+	if c.engine != nil {
+		c.engine.Close()
+	}
+}
@@ -0,0 +1,159 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func newIcebergTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/catalog/v1/config", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "lake", r.URL.Query().Get("warehouse"))
+		fmt.Fprint(w, `{"defaults":{},"overrides":{"prefix":"lake"}}`)
+	})
+	mux.HandleFunc("/catalog/v1/lake/namespaces", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"message":"Not authorized","type":"NotAuthorizedException","code":401}}`)
+			return
+		}
+
+		switch r.URL.Query().Get("parent") {
+		case "":
+			// paginated
+			if r.URL.Query().Get("pageToken") == "" {
+				fmt.Fprint(w, `{"namespaces":[["sales"]],"next-page-token":"1"}`)
+				return
+			}
+			fmt.Fprint(w, `{"namespaces":[["analytics"]]}`)
+		case "sales":
+			fmt.Fprint(w, `{"namespaces":[["sales","eu"]]}`)
+		default:
+			fmt.Fprint(w, `{"namespaces":[]}`)
+		}
+	})
+	mux.HandleFunc("/catalog/v1/lake/namespaces/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/catalog/v1/lake/namespaces/sales/tables":
+			fmt.Fprint(w, `{"identifiers":[{"namespace":["sales"],"name":"orders"},{"namespace":["sales"],"name":"customers"}]}`)
+		case "/catalog/v1/lake/namespaces/sales\x1feu/tables":
+			fmt.Fprint(w, `{"identifiers":[{"namespace":["sales","eu"],"name":"orders"}]}`)
+		case "/catalog/v1/lake/namespaces/analytics/tables":
+			fmt.Fprint(w, `{"identifiers":[]}`)
+		case "/catalog/v1/lake/namespaces/sales/tables/orders":
+			fmt.Fprint(w, `{"metadata-location":"s3://lake/orders/metadata.json","metadata":{
+				"format-version":2,
+				"current-schema-id":1,
+				"schemas":[
+					{"schema-id":0,"type":"struct","fields":[{"id":1,"name":"id","required":true,"type":"int"}]},
+					{"schema-id":1,"type":"struct","fields":[
+						{"id":1,"name":"id","required":true,"type":"long"},
+						{"id":2,"name":"total","required":false,"type":"decimal(10, 2)"},
+						{"id":3,"name":"hash","required":false,"type":"fixed[16]"},
+						{"id":4,"name":"items","required":false,"type":{"type":"list","element-id":5,"element":{"type":"struct","fields":[{"id":6,"name":"sku","type":"string"}]}}},
+						{"id":7,"name":"tags","required":false,"type":{"type":"map","key-id":8,"key":"string","value-id":9,"value":"int"}}
+					]}
+				],
+				"current-snapshot-id":2,
+				"snapshots":[
+					{"snapshot-id":2,"parent-snapshot-id":1,"timestamp-ms":1700000060000,"manifest-list":"s3://lake/snap-2.avro","summary":{"operation":"overwrite"}},
+					{"snapshot-id":1,"timestamp-ms":1700000000000,"manifest-list":"s3://lake/snap-1.avro","summary":{"operation":"append"}}
+				]
+			}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"Table does not exist","type":"NoSuchTableException","code":404}}`)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestIceberg(t *testing.T) {
+	r := require.New(t)
+
+	server := newIcebergTestServer(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	driver, err := (&Iceberg{}).Connect(fmt.Sprintf("iceberg://%s/catalog?warehouse=lake&token=secret", host))
+	r.NoError(err)
+	defer driver.Close()
+
+	// structure
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Name: "analytics", Schema: "analytics", Type: core.StructureTypeNone},
+		{
+			Name:   "sales",
+			Schema: "sales",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{
+					Name:   "eu",
+					Schema: "sales.eu",
+					Type:   core.StructureTypeNone,
+					Children: []*core.Structure{
+						{Name: "orders", Schema: "sales.eu", Type: core.StructureTypeTable},
+					},
+				},
+				{Name: "customers", Schema: "sales", Type: core.StructureTypeTable},
+				{Name: "orders", Schema: "sales", Type: core.StructureTypeTable},
+			},
+		},
+	}, structure)
+
+	// columns of the current schema
+	columns, err := driver.Columns(&core.TableOptions{Schema: "sales", Table: "orders"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "long"},
+		{Name: "total", Type: "decimal(10, 2)", Precision: 10, Scale: 2},
+		{Name: "hash", Type: "fixed[16]", MaxLength: 16},
+		{Name: "items", Type: "list<struct<sku: string>>"},
+		{Name: "tags", Type: "map<string, int>"},
+	}, columns)
+
+	_, err = driver.Columns(&core.TableOptions{Schema: "sales", Table: "missing"})
+	r.ErrorContains(err, "NoSuchTableException")
+
+	// snapshots
+	result, err := driver.Query(context.Background(), (&Iceberg{}).GetHelpers(&core.TableOptions{Schema: "sales", Table: "orders"})["Snapshots"])
+	r.NoError(err)
+	r.Equal(core.Header{"snapshot_id", "parent_id", "committed_at", "operation", "current", "manifest_list"}, result.Header())
+	rows, err := drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{
+		{int64(1), nil, time.UnixMilli(1700000000000).UTC(), "append", false, "s3://lake/snap-1.avro"},
+		{int64(2), int64(1), time.UnixMilli(1700000060000).UTC(), "overwrite", true, "s3://lake/snap-2.avro"},
+	}, rows)
+
+	// no engine configured
+	_, err = driver.Query(context.Background(), "SELECT 1")
+	r.ErrorContains(err, "no query engine configured")
+
+	// invalid token
+	driver, err = (&Iceberg{}).Connect(fmt.Sprintf("iceberg://%s/catalog?warehouse=lake&token=wrong", host))
+	r.NoError(err)
+	_, err = driver.Structure()
+	r.ErrorContains(err, "NotAuthorizedException")
+
+	// unknown engine
+	_, err = (&Iceberg{}).Connect(fmt.Sprintf("iceberg://%s/catalog?engine=unknown", host))
+	r.ErrorIs(err, ErrUnsupportedTypeAlias)
+}
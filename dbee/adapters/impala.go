@@ -0,0 +1,45 @@
+//go:build windows || (odbc && cgo && (darwin || linux || freebsd))
+
+package adapters
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// Register client
+func init() {
+	_ = register(&Impala{}, "impala")
+}
+
+var _ core.Adapter = (*Impala)(nil)
+
+// Impala connects to Apache Impala through the Impala ODBC driver (the same build
+// requirements as [ODBC] apply). Tables stored in Apache Kudu are listed separately
+// from other tables, with additional helpers.
+type Impala struct{}
+
+// Connect opens a connection to Impala. The url has the same form as the one
+// of [ODBC] (see [parseODBCURL]), pointing to a DSN of the Impala ODBC driver.
+func (i *Impala) Connect(rawURL string) (core.Driver, error) {
+	connStr, err := parseODBCURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("odbc", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to impala: %w", err)
+	}
+
+	return &impalaDriver{
+		c: builders.NewClient(db),
+	}, nil
+}
+
+func (*Impala) GetHelpers(opts *core.TableOptions) map[string]string {
+	return impalaHelpers(opts)
+}
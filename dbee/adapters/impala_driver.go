@@ -0,0 +1,207 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver            = (*impalaDriver)(nil)
+	_ core.ContextStructurer = (*impalaDriver)(nil)
+	_ core.IdleCloser        = (*impalaDriver)(nil)
+	_ core.SafeScanner       = (*impalaDriver)(nil)
+	_ core.StatementLogger   = (*impalaDriver)(nil)
+)
+
+// impalaKuduPattern matches create statements of tables stored in Kudu: either
+// managed ("STORED AS KUDU") or external ones (with "kudu.table_name" property).
+var impalaKuduPattern = regexp.MustCompile(`(?i)\bSTORED\s+AS\s+KUDU\b|'kudu\.table_name'`)
+
+// impalaViewPattern matches create statements of views.
+var impalaViewPattern = regexp.MustCompile(`(?i)^\s*CREATE\s+VIEW\b`)
+
+// impalaSystemDatabases are not listed in the structure.
+var impalaSystemDatabases = map[string]bool{"_impala_builtins": true}
+
+type impalaDriver struct {
+	c *builders.Client
+}
+
+func (c *impalaDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	return c.c.QueryUntilNotEmpty(ctx, query)
+}
+
+func (c *impalaDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	return c.c.ColumnsFromQuery("DESCRIBE %s", impalaTable(opts))
+}
+
+func (c *impalaDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+// StructureCtx lists tables of all databases. Impala doesn't report the type of tables,
+// so the create statement of each table is inspected, which takes a while with many tables.
+func (c *impalaDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	databases, err := c.column(ctx, "SHOW DATABASES")
+	if err != nil {
+		return nil, err
+	}
+
+	var structure []*core.Structure
+	for _, db := range databases {
+		if impalaSystemDatabases[db] {
+			continue
+		}
+
+		tables, err := c.column(ctx, fmt.Sprintf("SHOW TABLES IN %s", impalaQuote(db)))
+		if err != nil {
+			return nil, err
+		}
+
+		children := make([]*core.Structure, 0, len(tables))
+		for _, table := range tables {
+			typ, err := c.tableType(ctx, db, table)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, &core.Structure{
+				Name:   table,
+				Schema: db,
+				Type:   typ,
+			})
+		}
+
+		structure = append(structure, &core.Structure{
+			Name:     db,
+			Schema:   db,
+			Type:     core.StructureTypeNone,
+			Children: children,
+		})
+	}
+
+	sort.Slice(structure, func(i, j int) bool {
+		return structure[i].Name < structure[j].Name
+	})
+
+	return structure, nil
+}
+
+// tableType classifies the table by its create statement.
+func (c *impalaDriver) tableType(ctx context.Context, db, table string) (core.StructureType, error) {
+	stmt, err := c.column(ctx, fmt.Sprintf("SHOW CREATE TABLE %s.%s", impalaQuote(db), impalaQuote(table)))
+	if err != nil {
+		return core.StructureTypeNone, err
+	}
+	if len(stmt) < 1 {
+		return core.StructureTypeTable, nil
+	}
+
+	return impalaTableType(stmt[0]), nil
+}
+
+// impalaTableType classifies a table by its create statement.
+func impalaTableType(createStmt string) core.StructureType {
+	switch {
+	case impalaViewPattern.MatchString(createStmt):
+		return core.StructureTypeView
+	case impalaKuduPattern.MatchString(createStmt):
+		return core.StructureTypeKuduTable
+	default:
+		return core.StructureTypeTable
+	}
+}
+
+// column returns values of the first column of the query result.
+func (c *impalaDriver) column(ctx context.Context, query string) ([]string, error) {
+	rows, err := c.c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 1 {
+			continue
+		}
+
+		if b, ok := row[0].([]byte); ok {
+			values = append(values, string(b))
+		} else {
+			values = append(values, fmt.Sprint(row[0]))
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (c *impalaDriver) Close() {
+	c.c.Close()
+}
+
+func (c *impalaDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *impalaDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+func (c *impalaDriver) SetIdleTimeout(timeout time.Duration) {
+	c.c.SetIdleTimeout(timeout)
+}
+
+// impalaHelpers returns helper queries of the table. Kudu tables get additional
+// ones with the primary key, encodings and partitioning, which are not
+// shown by the generic Impala output.
+func impalaHelpers(opts *core.TableOptions) map[string]string {
+	table := impalaTable(opts)
+
+	if opts.Materialization == core.StructureTypeView {
+		return map[string]string{
+			"List":             fmt.Sprintf("SELECT * FROM %s LIMIT 500", table),
+			"Columns":          fmt.Sprintf("DESCRIBE %s", table),
+			"Create Statement": fmt.Sprintf("SHOW CREATE VIEW %s", table),
+		}
+	}
+
+	helpers := map[string]string{
+		"List":             fmt.Sprintf("SELECT * FROM %s LIMIT 500", table),
+		"Columns":          fmt.Sprintf("DESCRIBE %s", table),
+		"Create Statement": fmt.Sprintf("SHOW CREATE TABLE %s", table),
+		"Table Stats":      fmt.Sprintf("SHOW TABLE STATS %s", table),
+		"Column Stats":     fmt.Sprintf("SHOW COLUMN STATS %s", table),
+	}
+
+	if opts.Materialization == core.StructureTypeKuduTable {
+		helpers["Kudu Schema"] = fmt.Sprintf("DESCRIBE EXTENDED %s", table)
+		helpers["Kudu Partitions"] = fmt.Sprintf("SHOW PARTITIONS %s", table)
+	}
+
+	return helpers
+}
+
+// impalaTable returns the quoted name of the table.
+func impalaTable(opts *core.TableOptions) string {
+	if opts.Schema == "" {
+		return impalaQuote(opts.Table)
+	}
+	return impalaQuote(opts.Schema) + "." + impalaQuote(opts.Table)
+}
+
+func impalaQuote(name string) string {
+	return "`" + name + "`"
+}
@@ -0,0 +1,118 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// impalaTestResponses are results (single column) of queries of the fake impala driver.
+var impalaTestResponses = map[string][]string{
+	"SHOW DATABASES":           {"_impala_builtins", "sales", "default"},
+	"SHOW TABLES IN `default`": {},
+	"SHOW TABLES IN `sales`":   {"events", "orders", "orders_v", "users"},
+	"SHOW CREATE TABLE `sales`.`events`": {
+		"CREATE EXTERNAL TABLE sales.events (id BIGINT)\nSTORED AS PARQUET\nLOCATION 'hdfs://events'",
+	},
+	"SHOW CREATE TABLE `sales`.`orders`": {
+		"CREATE TABLE sales.orders (\n  id BIGINT NOT NULL,\n  PRIMARY KEY (id)\n)\nPARTITION BY HASH (id) PARTITIONS 4\nSTORED AS KUDU\nTBLPROPERTIES ('kudu.master_addresses'='kudu:7051')",
+	},
+	"SHOW CREATE TABLE `sales`.`orders_v`": {
+		"CREATE VIEW sales.orders_v AS SELECT * FROM sales.orders",
+	},
+	"SHOW CREATE TABLE `sales`.`users`": {
+		"CREATE EXTERNAL TABLE sales.users\nSTORED AS KUDU\nTBLPROPERTIES ('kudu.table_name'='impala::sales.users')",
+	},
+}
+
+type impalaTestDriver struct{}
+
+func (impalaTestDriver) Open(string) (driver.Conn, error) { return impalaTestConn{}, nil }
+
+type impalaTestConn struct{}
+
+func (impalaTestConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (impalaTestConn) Close() error                        { return nil }
+func (impalaTestConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (impalaTestConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	values, ok := impalaTestResponses[query]
+	if !ok {
+		return nil, errors.New("unexpected query: " + query)
+	}
+	return &impalaTestRows{values: values}, nil
+}
+
+type impalaTestRows struct {
+	values []string
+}
+
+func (*impalaTestRows) Columns() []string { return []string{"name"} }
+func (*impalaTestRows) Close() error      { return nil }
+
+func (r *impalaTestRows) Next(dest []driver.Value) error {
+	if len(r.values) < 1 {
+		return io.EOF
+	}
+	dest[0] = []byte(r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("dbee-impala-test", impalaTestDriver{})
+}
+
+func TestImpalaDriver_Structure(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee-impala-test", "")
+	r.NoError(err)
+	d := &impalaDriver{c: builders.NewClient(db)}
+	defer d.Close()
+
+	structure, err := d.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Name: "default", Schema: "default", Type: core.StructureTypeNone, Children: []*core.Structure{}},
+		{
+			Name:   "sales",
+			Schema: "sales",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "events", Schema: "sales", Type: core.StructureTypeTable},
+				{Name: "orders", Schema: "sales", Type: core.StructureTypeKuduTable},
+				{Name: "orders_v", Schema: "sales", Type: core.StructureTypeView},
+				{Name: "users", Schema: "sales", Type: core.StructureTypeKuduTable},
+			},
+		},
+	}, structure)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = d.StructureCtx(ctx)
+	r.ErrorIs(err, context.Canceled)
+}
+
+func TestImpalaHelpers(t *testing.T) {
+	r := require.New(t)
+
+	helpers := impalaHelpers(&core.TableOptions{Schema: "sales", Table: "orders", Materialization: core.StructureTypeKuduTable})
+	r.Equal("DESCRIBE EXTENDED `sales`.`orders`", helpers["Kudu Schema"])
+	r.Equal("SHOW PARTITIONS `sales`.`orders`", helpers["Kudu Partitions"])
+
+	helpers = impalaHelpers(&core.TableOptions{Schema: "sales", Table: "events", Materialization: core.StructureTypeTable})
+	r.NotContains(helpers, "Kudu Schema")
+	r.Equal("SHOW TABLE STATS `sales`.`events`", helpers["Table Stats"])
+
+	helpers = impalaHelpers(&core.TableOptions{Schema: "sales", Table: "orders_v", Materialization: core.StructureTypeView})
+	r.Equal("SHOW CREATE VIEW `sales`.`orders_v`", helpers["Create Statement"])
+}
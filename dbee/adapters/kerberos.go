@@ -0,0 +1,192 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+// connection string parameters of gssapi (kerberos) authentication
+const (
+	kerberosParamAuth          = "auth"
+	kerberosParamConfigFile    = "krb5-configfile"
+	kerberosParamCredCacheFile = "krb5-credcachefile"
+
+	kerberosAuthGSSAPI = "gssapi"
+)
+
+var errKerberosNoTicket = errors.New("no valid kerberos ticket found (obtain one with kinit)")
+
+// loadKerberosCCache loads the credential cache from the path.
+// Tests replace it, so no KDC (or kinit) is needed.
+var loadKerberosCCache = credentials.LoadCCache
+
+// kerberosConfig holds locations of the kerberos config and the credential cache
+// (filled by kinit) used for gssapi authentication.
+type kerberosConfig struct {
+	configFile    string
+	credCacheFile string
+}
+
+// newKerberosConfig reads and removes gssapi related parameters from params.
+// It returns nil (and leaves params untouched) if params don't specify "auth=gssapi".
+//
+// Unless specified with "krb5-configfile" and "krb5-credcachefile", the locations
+// are taken from KRB5_CONFIG and KRB5CCNAME environment variables, or the platform
+// defaults (/etc/krb5.conf and /tmp/krb5cc_<uid>).
+func newKerberosConfig(params url.Values) (*kerberosConfig, error) {
+	auth := params.Get(kerberosParamAuth)
+	switch auth {
+	case "":
+		return nil, nil
+	case kerberosAuthGSSAPI:
+	default:
+		return nil, fmt.Errorf("unsupported auth method: %q", auth)
+	}
+
+	configFile := params.Get(kerberosParamConfigFile)
+	credCacheFile := params.Get(kerberosParamCredCacheFile)
+	params.Del(kerberosParamAuth)
+	params.Del(kerberosParamConfigFile)
+	params.Del(kerberosParamCredCacheFile)
+
+	cfg := defaultKerberosConfig()
+	if configFile != "" {
+		cfg.configFile = configFile
+	}
+	if credCacheFile != "" {
+		cfg.credCacheFile = credCacheFile
+	}
+
+	return cfg, nil
+}
+
+func defaultKerberosConfig() *kerberosConfig {
+	cfg := &kerberosConfig{
+		configFile:    "/etc/krb5.conf",
+		credCacheFile: filepath.Join("/tmp", fmt.Sprintf("krb5cc_%d", os.Getuid())),
+	}
+
+	// KRB5_CONFIG is a list of files, the first one is used
+	if env := strings.Split(os.Getenv("KRB5_CONFIG"), string(os.PathListSeparator))[0]; env != "" {
+		cfg.configFile = env
+	}
+	// only file based caches are supported
+	if env := strings.TrimPrefix(os.Getenv("KRB5CCNAME"), "FILE:"); env != "" {
+		cfg.credCacheFile = env
+	}
+
+	return cfg
+}
+
+// client returns a kerberos client with credentials of the cache.
+// An error wrapping errKerberosNoTicket is returned if the cache doesn't
+// hold a valid ticket granting ticket.
+func (k *kerberosConfig) client() (*client.Client, error) {
+	cfg, err := config.Load(k.configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading kerberos config %q: %w", k.configFile, err)
+	}
+
+	cache, err := loadKerberosCCache(k.credCacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed loading credential cache %q: %v", errKerberosNoTicket, k.credCacheFile, err)
+	}
+
+	if err := kerberosCheckTicket(cache, time.Now()); err != nil {
+		return nil, err
+	}
+
+	cl, err := client.NewFromCCache(cache, cfg, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, fmt.Errorf("client.NewFromCCache: %w", err)
+	}
+
+	return cl, nil
+}
+
+// kerberosCheckTicket checks that the cache holds a ticket granting ticket
+// of the default principal which hasn't expired yet.
+func kerberosCheckTicket(cache *credentials.CCache, now time.Time) error {
+	realm := cache.GetClientRealm()
+	principal := cache.GetClientPrincipalName().PrincipalNameString() + "@" + realm
+
+	for _, cred := range cache.GetEntries() {
+		name := cred.Server.PrincipalName.NameString
+		if len(name) != 2 || name[0] != "krbtgt" || name[1] != realm {
+			continue
+		}
+
+		if !now.Before(cred.EndTime) {
+			return fmt.Errorf("%w: ticket of %s expired at %s", errKerberosNoTicket, principal, cred.EndTime.Format(time.RFC3339))
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: no ticket granting ticket of %s in the credential cache", errKerberosNoTicket, principal)
+}
+
+// kerberosGSS implements the gssapi provider of lib/pq using SPNEGO.
+type kerberosGSS struct {
+	cl *client.Client
+}
+
+func newKerberosGSS(cfg *kerberosConfig) (*kerberosGSS, error) {
+	cl, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kerberosGSS{cl: cl}, nil
+}
+
+func (g *kerberosGSS) GetInitToken(host string, service string) ([]byte, error) {
+	// use the canonical host name for the service principal
+	if names, err := net.LookupAddr(host); err == nil && len(names) > 0 {
+		host = strings.TrimSuffix(names[0], ".")
+	} else if cname, err := net.LookupCNAME(host); err == nil {
+		host = strings.TrimSuffix(cname, ".")
+	}
+
+	return g.GetInitTokenFromSpn(service + "/" + host)
+}
+
+func (g *kerberosGSS) GetInitTokenFromSpn(spn string) ([]byte, error) {
+	token, err := spnego.SPNEGOClient(g.cl, spn).InitSecContext()
+	if err != nil {
+		return nil, fmt.Errorf("kerberos InitSecContext: %w", err)
+	}
+
+	b, err := token.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("token.Marshal: %w", err)
+	}
+
+	return b, nil
+}
+
+func (g *kerberosGSS) Continue(inToken []byte) (done bool, outToken []byte, err error) {
+	var token spnego.SPNEGOToken
+	if err := token.Unmarshal(inToken); err != nil {
+		return true, nil, fmt.Errorf("token.Unmarshal: %w", err)
+	}
+
+	if !token.Resp {
+		return true, nil, errors.New("kerberos: unexpected token from the server")
+	}
+	if ok, status := token.NegTokenResp.Verify(); !ok {
+		return true, nil, fmt.Errorf("kerberos: token verification failed: %s", status.Message)
+	}
+
+	return true, nil, nil
+}
@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newKerberosTestCache(t *testing.T, server string, end time.Time) *credentials.CCache {
+	t.Helper()
+
+	cache := &credentials.CCache{Path: "/tmp/krb5cc_test"}
+	cache.DefaultPrincipal.Realm = "EXAMPLE.COM"
+	cache.DefaultPrincipal.PrincipalName = types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "alice")
+
+	cred := &credentials.Credential{EndTime: end}
+	cred.Server.Realm = "EXAMPLE.COM"
+	cred.Server.PrincipalName = types.NewPrincipalName(nametype.KRB_NT_SRV_INST, server)
+	cache.Credentials = append(cache.Credentials, cred)
+
+	return cache
+}
+
+func TestNewKerberosConfig(t *testing.T) {
+	r := require.New(t)
+
+	t.Setenv("KRB5_CONFIG", "/etc/custom-krb5.conf"+string(os.PathListSeparator)+"/etc/krb5.conf")
+	t.Setenv("KRB5CCNAME", "FILE:/tmp/krb5cc_custom")
+
+	// no gssapi, params are left untouched
+	params := url.Values{kerberosParamConfigFile: {"/etc/krb5.conf"}, "database": {"db"}}
+	cfg, err := newKerberosConfig(params)
+	r.NoError(err)
+	r.Nil(cfg)
+	r.Len(params, 2)
+
+	// unknown method
+	_, err = newKerberosConfig(url.Values{kerberosParamAuth: {"ntlm"}})
+	r.Error(err)
+
+	// defaults from environment
+	params = url.Values{kerberosParamAuth: {"gssapi"}, "database": {"db"}}
+	cfg, err = newKerberosConfig(params)
+	r.NoError(err)
+	r.Equal(&kerberosConfig{configFile: "/etc/custom-krb5.conf", credCacheFile: "/tmp/krb5cc_custom"}, cfg)
+	r.Equal(url.Values{"database": {"db"}}, params)
+
+	// explicit locations
+	cfg, err = newKerberosConfig(url.Values{
+		kerberosParamAuth:          {"gssapi"},
+		kerberosParamCredCacheFile: {"/home/alice/krb5cc"},
+	})
+	r.NoError(err)
+	r.Equal(&kerberosConfig{configFile: "/etc/custom-krb5.conf", credCacheFile: "/home/alice/krb5cc"}, cfg)
+}
+
+func TestKerberosCheckTicket(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+
+	err := kerberosCheckTicket(newKerberosTestCache(t, "krbtgt/EXAMPLE.COM", now.Add(time.Hour)), now)
+	r.NoError(err)
+
+	err = kerberosCheckTicket(newKerberosTestCache(t, "krbtgt/EXAMPLE.COM", now.Add(-time.Hour)), now)
+	r.ErrorIs(err, errKerberosNoTicket)
+	r.ErrorContains(err, "alice@EXAMPLE.COM")
+
+	// only service tickets
+	err = kerberosCheckTicket(newKerberosTestCache(t, "postgres/db.example.com", now.Add(time.Hour)), now)
+	r.ErrorIs(err, errKerberosNoTicket)
+}
+
+func TestKerberosConfig_NoTicket(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "krb5.conf")
+	err := os.WriteFile(configFile, []byte("[libdefaults]\n  default_realm = EXAMPLE.COM\n"), 0o600)
+	r.NoError(err)
+
+	load := loadKerberosCCache
+	t.Cleanup(func() { loadKerberosCCache = load })
+
+	cfg := &kerberosConfig{configFile: configFile, credCacheFile: filepath.Join(dir, "krb5cc")}
+
+	// missing cache
+	loadKerberosCCache = func(string) (*credentials.CCache, error) {
+		return nil, errors.New("no such file or directory")
+	}
+	_, err = cfg.client()
+	r.ErrorIs(err, errKerberosNoTicket)
+
+	// expired ticket
+	loadKerberosCCache = func(string) (*credentials.CCache, error) {
+		return newKerberosTestCache(t, "krbtgt/EXAMPLE.COM", time.Now().Add(-time.Minute)), nil
+	}
+	_, err = cfg.client()
+	r.ErrorIs(err, errKerberosNoTicket)
+
+	// adapters fail early
+	t.Setenv("KRB5_CONFIG", configFile)
+	t.Setenv("KRB5CCNAME", filepath.Join(dir, "krb5cc"))
+
+	_, err = (&Postgres{}).Connect("postgres://db.example.com:5432/db?auth=gssapi")
+	r.ErrorIs(err, errKerberosNoTicket)
+
+	_, err = (&SQLServer{}).Connect("sqlserver://db.example.com:1433?database=db&auth=gssapi")
+	r.ErrorIs(err, errKerberosNoTicket)
+
+	// postgres uses a single provider, so locations can't be set per connection
+	_, err = (&Postgres{}).Connect("postgres://db.example.com:5432/db?auth=gssapi&krb5-credcachefile=/tmp/cache")
+	r.Error(err)
+	r.NotErrorIs(err, errKerberosNoTicket)
+}
@@ -0,0 +1,90 @@
+package adapters
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	nurl "net/url"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&KsqlDB{}, "ksqldb", "ksql")
+
+	// numbers are decoded as json.Number to keep precision
+	gob.Register(json.Number(""))
+}
+
+var _ core.Adapter = (*KsqlDB)(nil)
+
+type KsqlDB struct{}
+
+// Connect creates a [KsqlDB] client talking to the ksqlDB REST API.
+// The format of the url is as follows:
+//
+//	ksqldb://[user:password@]host:port[?properties]
+//
+// Where:
+//   - "ksqldbs" scheme can be used instead of "ksqldb" to connect over https.
+//   - "properties" is an ampersand-separated list of key=value streams properties
+//     passed with each query (e.g. auto.offset.reset=earliest).
+//
+// Instead of basic auth, OAuth2 can be used by passing the "oauth-token-url",
+// "oauth-client-id", "oauth-client-secret", "oauth-refresh-token" and "oauth-scopes"
+// properties (see newOAuthTokenSource).
+func (k *KsqlDB) Connect(rawURL string) (core.Driver, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	var scheme string
+	switch u.Scheme {
+	case "ksqldb", "ksql", "http":
+		scheme = "http"
+	case "ksqldbs", "https":
+		scheme = "https"
+	default:
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	params := u.Query()
+	oauth, err := newOAuthTokenSource(params)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make(map[string]string)
+	for key, values := range params {
+		if len(values) > 0 {
+			properties[key] = values[len(values)-1]
+		}
+	}
+
+	driver := &ksqlDBDriver{
+		c:          &http.Client{},
+		url:        &nurl.URL{Scheme: scheme, Host: u.Host},
+		properties: properties,
+	}
+
+	if oauth != nil {
+		driver.c = oauth.client()
+	} else if u.User != nil {
+		driver.user = u.User.Username()
+		driver.password, _ = u.User.Password()
+	}
+
+	return driver, nil
+}
+
+func (*KsqlDB) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List":     fmt.Sprintf("SELECT * FROM `%s` EMIT CHANGES LIMIT 100;", opts.Table),
+		"Describe": fmt.Sprintf("DESCRIBE `%s` EXTENDED;", opts.Table),
+		"Queries":  "SHOW QUERIES;",
+		"Topics":   "SHOW TOPICS;",
+	}
+}
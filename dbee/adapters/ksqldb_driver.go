@@ -0,0 +1,444 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"sort"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var _ core.Driver = (*ksqlDBDriver)(nil)
+
+type ksqlDBDriver struct {
+	c          *http.Client
+	url        *nurl.URL
+	user       string
+	password   string
+	properties map[string]string
+}
+
+// ksqlDBError is the error response of the rest api.
+type ksqlDBError struct {
+	Type      string `json:"@type"`
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func (e *ksqlDBError) Error() string {
+	return fmt.Sprintf("ksqldb error %d: %s", e.ErrorCode, e.Message)
+}
+
+// ksqlDBQueryHeader is the first line of the "/query-stream" response.
+type ksqlDBQueryHeader struct {
+	QueryID     string   `json:"queryId"`
+	ColumnNames []string `json:"columnNames"`
+	ColumnTypes []string `json:"columnTypes"`
+}
+
+func (c *ksqlDBDriver) post(ctx context.Context, path, contentType string, body any) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url.JoinPath(path).String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", contentType)
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("c.c.Do: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, ksqlDBErrorFromBody(resp)
+	}
+
+	return resp, nil
+}
+
+func ksqlDBErrorFromBody(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var kerr ksqlDBError
+	if err := json.Unmarshal(body, &kerr); err != nil || kerr.Message == "" {
+		return fmt.Errorf("ksqldb responded with %q: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return &kerr
+}
+
+// rawJSONValue converts a raw json value to a displayable value.
+// Structs, maps and arrays are kept as compact json strings.
+func rawJSONValue(raw json.RawMessage) any {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) < 1 {
+		return nil
+	}
+
+	switch raw[0] {
+	case 'n':
+		return nil
+	case 't', 'f':
+		return raw[0] == 't'
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return string(raw)
+		}
+		return s
+	case '{', '[':
+		var b bytes.Buffer
+		if err := json.Compact(&b, raw); err != nil {
+			return string(raw)
+		}
+		return b.String()
+	default:
+		return json.Number(raw)
+	}
+}
+
+func (c *ksqlDBDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	// only selects are streamed, everything else is a statement
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "SELECT") {
+		return c.queryStream(ctx, query)
+	}
+
+	entities, err := c.statement(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	header, rows := ksqlDBEntitiesToRows(entities)
+
+	index := 0
+	hasNext := func() bool {
+		return index < len(rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		index++
+		return rows[index-1], nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build(), nil
+}
+
+// queryStream runs a pull or push query and streams the rows as they arrive.
+// Push queries keep the stream open until they are canceled.
+func (c *ksqlDBDriver) queryStream(ctx context.Context, query string) (core.ResultStream, error) {
+	resp, err := c.post(ctx, "/query-stream", "application/vnd.ksqlapi.delimited.v1", map[string]any{
+		"sql":        query,
+		"properties": c.properties,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	// each line of the response is a separate json document,
+	// first one being the header
+	nextLine := func() ([]byte, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) > 0 {
+				return line, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	line, err := nextLine()
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed reading query header: %w", err)
+	}
+
+	var header ksqlDBQueryHeader
+	if err := json.Unmarshal(line, &header); err != nil || header.ColumnNames == nil {
+		resp.Body.Close()
+		var kerr ksqlDBError
+		if json.Unmarshal(line, &kerr) == nil && kerr.Message != "" {
+			return nil, &kerr
+		}
+		return nil, fmt.Errorf("unexpected query header: %s", line)
+	}
+
+	var pending []byte
+	var pendingErr error
+
+	hasNext := func() bool {
+		if pending != nil || pendingErr != nil {
+			return true
+		}
+
+		line, err := nextLine()
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+		if err != nil {
+			pendingErr = err
+			return true
+		}
+		pending = line
+		return true
+	}
+
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		if pendingErr != nil {
+			return nil, pendingErr
+		}
+
+		line := pending
+		pending = nil
+
+		// errors can be sent in the middle of the stream
+		if line[0] == '{' {
+			var kerr ksqlDBError
+			if err := json.Unmarshal(line, &kerr); err != nil {
+				return nil, fmt.Errorf("unexpected response: %s", line)
+			}
+			return nil, &kerr
+		}
+
+		var values []json.RawMessage
+		if err := json.Unmarshal(line, &values); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+
+		row := make(core.Row, len(values))
+		for i, v := range values {
+			row[i] = rawJSONValue(v)
+		}
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header.ColumnNames).
+		WithCloseFunc(func() {
+			_ = resp.Body.Close()
+		}).
+		Build(), nil
+}
+
+// statement runs a non-query statement and returns the response entities.
+func (c *ksqlDBDriver) statement(ctx context.Context, query string) ([]map[string]any, error) {
+	query = strings.TrimSpace(query)
+	if !strings.HasSuffix(query, ";") {
+		query += ";"
+	}
+
+	resp, err := c.post(ctx, "/ksql", "application/vnd.ksql.v1+json", map[string]any{
+		"ksql":              query,
+		"streamsProperties": c.properties,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var entities []map[string]any
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&entities); err != nil {
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
+	}
+
+	return entities, nil
+}
+
+// ksqlDBEntityList returns the list of objects of a response entity
+// (e.g. "streams" of a LIST STREAMS statement).
+func ksqlDBEntityList(entity map[string]any) ([]map[string]any, bool) {
+	// the list is usually stored under the entity's type, otherwise
+	// try any list field except warnings
+	typ, _ := entity["@type"].(string)
+	keys := []string{typ}
+	for k := range entity {
+		if k != typ && k != "warnings" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[1:])
+
+	for _, k := range keys {
+		list, ok := entity[k].([]any)
+		if !ok {
+			continue
+		}
+
+		var objects []map[string]any
+		for _, item := range list {
+			obj, ok := item.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			objects = append(objects, obj)
+		}
+		return objects, true
+	}
+
+	return nil, false
+}
+
+// ksqlDBEntitiesToRows converts the statement response to a table.
+// Listings are converted to a row per object, command statuses to
+// status and message and anything else to its type and json representation.
+func ksqlDBEntitiesToRows(entities []map[string]any) (core.Header, []core.Row) {
+	// single listing
+	if len(entities) == 1 {
+		if objects, ok := ksqlDBEntityList(entities[0]); ok {
+			if len(objects) < 1 {
+				return core.Header{"No Results"}, nil
+			}
+
+			var header core.Header
+			for k := range objects[0] {
+				if k != "@type" && k != "name" {
+					header = append(header, k)
+				}
+			}
+			sort.Strings(header)
+			if _, ok := objects[0]["name"]; ok {
+				header = append(core.Header{"name"}, header...)
+			}
+
+			var rows []core.Row
+			for _, obj := range objects {
+				row := make(core.Row, len(header))
+				for i, h := range header {
+					row[i] = ksqlDBDisplayValue(obj[h])
+				}
+				rows = append(rows, row)
+			}
+			return header, rows
+		}
+	}
+
+	var rows []core.Row
+	for _, e := range entities {
+		if status, ok := e["commandStatus"].(map[string]any); ok {
+			rows = append(rows, core.Row{status["status"], status["message"]})
+			continue
+		}
+
+		rows = append(rows, core.Row{e["@type"], ksqlDBDisplayValue(e)})
+	}
+
+	return core.Header{"Status", "Message"}, rows
+}
+
+func ksqlDBDisplayValue(v any) any {
+	switch v.(type) {
+	case map[string]any, []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	default:
+		return v
+	}
+}
+
+func (c *ksqlDBDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	entities, err := c.statement(context.Background(), fmt.Sprintf("DESCRIBE `%s`;", opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*core.Column
+	for _, e := range entities {
+		desc, ok := e["sourceDescription"].(map[string]any)
+		if !ok {
+			continue
+		}
+		fields, _ := desc["fields"].([]any)
+		for _, f := range fields {
+			field, ok := f.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			typ := ""
+			if schema, ok := field["schema"].(map[string]any); ok {
+				typ = fmt.Sprint(schema["type"])
+			}
+			columns = append(columns, &core.Column{
+				Name: fmt.Sprint(field["name"]),
+				Type: typ,
+			})
+		}
+	}
+
+	return columns, nil
+}
+
+func (c *ksqlDBDriver) Structure() ([]*core.Structure, error) {
+	var structure []*core.Structure
+
+	// streams don't have a dedicated type, they are displayed as views
+	for _, list := range []struct {
+		statement string
+		typ       core.StructureType
+	}{
+		{statement: "LIST STREAMS;", typ: core.StructureTypeView},
+		{statement: "LIST TABLES;", typ: core.StructureTypeTable},
+	} {
+		entities, err := c.statement(context.Background(), list.statement)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entities {
+			objects, _ := ksqlDBEntityList(e)
+			for _, obj := range objects {
+				name, ok := obj["name"].(string)
+				if !ok {
+					continue
+				}
+				structure = append(structure, &core.Structure{
+					Name:   name,
+					Schema: "",
+					Type:   list.typ,
+				})
+			}
+		}
+	}
+
+	return structure, nil
+}
+
+func (c *ksqlDBDriver) Close() {
+	c.c.CloseIdleConnections()
+}
@@ -0,0 +1,124 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func newKsqlDBTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/query-stream":
+			query := body["sql"].(string)
+			if strings.Contains(query, "missing") {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"@type":"generic_error","error_code":40001,"message":"stream not found"}`)
+				return
+			}
+
+			fmt.Fprintln(w, `{"queryId":"q1","columnNames":["ID","NAME","TAGS"],"columnTypes":["BIGINT","STRING","ARRAY<STRING>"]}`)
+			fmt.Fprintln(w, `[12345678901234567,"alice",["a","b"]]`)
+			fmt.Fprintln(w, `[2,null,[]]`)
+			if strings.Contains(query, "broken") {
+				fmt.Fprintln(w, `{"@type":"generic_error","error_code":50000,"message":"query terminated"}`)
+			}
+		case "/ksql":
+			switch body["ksql"] {
+			case "LIST STREAMS;":
+				fmt.Fprint(w, `[{"@type":"streams","streams":[{"name":"PAGEVIEWS","topic":"pageviews"}],"warnings":[]}]`)
+			case "LIST TABLES;":
+				fmt.Fprint(w, `[{"@type":"tables","tables":[{"name":"USERS","topic":"users"}],"warnings":[]}]`)
+			default:
+				fmt.Fprint(w, `[{"@type":"currentStatus","commandStatus":{"status":"SUCCESS","message":"Stream created"}}]`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func drainKsqlDBResult(t *testing.T, result core.ResultStream) ([]core.Row, error) {
+	t.Helper()
+
+	var rows []core.Row
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func TestKsqlDB(t *testing.T) {
+	r := require.New(t)
+
+	server := newKsqlDBTestServer(t)
+	defer server.Close()
+
+	driver, err := (&KsqlDB{}).Connect(strings.Replace(server.URL, "http://", "ksqldb://", 1))
+	r.NoError(err)
+	defer driver.Close()
+
+	// streamed query
+	result, err := driver.Query(context.Background(), "SELECT * FROM pageviews EMIT CHANGES;")
+	r.NoError(err)
+	r.Equal(core.Header{"ID", "NAME", "TAGS"}, result.Header())
+
+	rows, err := drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{
+		{json.Number("12345678901234567"), "alice", `["a","b"]`},
+		{json.Number("2"), nil, `[]`},
+	}, rows)
+
+	// error in the middle of the stream
+	result, err = driver.Query(context.Background(), "SELECT * FROM broken;")
+	r.NoError(err)
+	rows, err = drainKsqlDBResult(t, result)
+	r.ErrorContains(err, "query terminated")
+	r.Len(rows, 2)
+
+	// error response
+	_, err = driver.Query(context.Background(), "SELECT * FROM missing;")
+	r.ErrorContains(err, "stream not found")
+
+	// statement
+	result, err = driver.Query(context.Background(), "CREATE STREAM s (id INT) WITH (kafka_topic='s', value_format='json')")
+	r.NoError(err)
+	r.Equal(core.Header{"Status", "Message"}, result.Header())
+	rows, err = drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{{"SUCCESS", "Stream created"}}, rows)
+
+	// listing
+	result, err = driver.Query(context.Background(), "LIST STREAMS")
+	r.NoError(err)
+	r.Equal(core.Header{"name", "topic"}, result.Header())
+	rows, err = drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{{"PAGEVIEWS", "pageviews"}}, rows)
+
+	// structure
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Name: "PAGEVIEWS", Type: core.StructureTypeView},
+		{Name: "USERS", Type: core.StructureTypeTable},
+	}, structure)
+}
@@ -0,0 +1,164 @@
+package adapters
+
+import (
+	"fmt"
+	nurl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Kyuubi{}, "kyuubi")
+}
+
+var _ core.Adapter = (*Kyuubi)(nil)
+
+// defaultKyuubiLaunchTimeout is the default time to wait for the query engine to launch.
+const defaultKyuubiLaunchTimeout = 5 * time.Minute
+
+// Kyuubi connects to Apache Kyuubi or the Spark Thrift Server over the HiveServer2
+// thrift protocol. Kyuubi launches a query engine (e.g. a Spark application) for
+// the session, which takes a while, so the first query waits for it separately.
+type Kyuubi struct{}
+
+// Connect creates a [Kyuubi] client. The session is opened with the first query.
+// The format of the url is as follows:
+//
+//	kyuubi://[user[:password]@]host[:port][/database][?options]
+//
+// Where:
+//   - "port" defaults to 10009 (the port of Kyuubi, Spark Thrift Server uses 10000).
+//   - "user" and "password" default to "anonymous".
+//   - "options" is an ampersand-separated list of key=value arguments.
+//
+// The supported "options" are:
+//   - auth=plain|nosasl - authentication of the connection (default is plain, which works
+//     with servers without authentication too).
+//   - launch_timeout=duration - time to wait for the session to open and the engine to start
+//     running the first query (default is 5m).
+//   - timeout=duration - timeout of each query, the query is canceled when it's exceeded.
+//   - any other option is passed as a session configuration (e.g. kyuubi.engine.type=SPARK_SQL
+//     or spark.executor.memory=4g).
+func (*Kyuubi) Connect(rawURL string) (core.Driver, error) {
+	cfg, err := parseKyuubiURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kyuubiDriver{
+		cfg:      cfg,
+		database: cfg.database,
+	}, nil
+}
+
+func (*Kyuubi) GetHelpers(opts *core.TableOptions) map[string]string {
+	table := impalaTable(opts)
+
+	if opts.Materialization == core.StructureTypeView {
+		return map[string]string{
+			"List":             fmt.Sprintf("SELECT * FROM %s LIMIT 500", table),
+			"Columns":          fmt.Sprintf("DESCRIBE %s", table),
+			"Create Statement": fmt.Sprintf("SHOW CREATE TABLE %s", table),
+		}
+	}
+
+	return map[string]string{
+		"List":             fmt.Sprintf("SELECT * FROM %s LIMIT 500", table),
+		"Columns":          fmt.Sprintf("DESCRIBE %s", table),
+		"Create Statement": fmt.Sprintf("SHOW CREATE TABLE %s", table),
+		"Details":          fmt.Sprintf("DESCRIBE TABLE EXTENDED %s", table),
+		"Partitions":       fmt.Sprintf("SHOW PARTITIONS %s", table),
+		"Properties":       fmt.Sprintf("SHOW TBLPROPERTIES %s", table),
+	}
+}
+
+// kyuubiConfig is the parsed connection url.
+type kyuubiConfig struct {
+	host          string
+	port          int
+	sasl          bool
+	user          string
+	password      string
+	database      string
+	launchTimeout time.Duration
+	queryTimeout  time.Duration
+	conf          map[string]string
+}
+
+func parseKyuubiURL(rawURL string) (*kyuubiConfig, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	if u.Scheme != "kyuubi" {
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+
+	port := 10009
+	if p := u.Port(); p != "" {
+		port, err = strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port: %w", err)
+		}
+	}
+
+	cfg := &kyuubiConfig{
+		host:          u.Hostname(),
+		port:          port,
+		sasl:          true,
+		user:          "anonymous",
+		password:      "anonymous",
+		database:      strings.Trim(u.Path, "/"),
+		launchTimeout: defaultKyuubiLaunchTimeout,
+		conf:          make(map[string]string),
+	}
+	if u.User != nil && u.User.Username() != "" {
+		cfg.user = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			cfg.password = password
+		}
+	}
+
+	params := u.Query()
+
+	switch strings.ToLower(params.Get("auth")) {
+	case "", "plain", "none":
+	case "nosasl":
+		cfg.sasl = false
+	default:
+		return nil, fmt.Errorf("unsupported auth: %q", params.Get("auth"))
+	}
+	params.Del("auth")
+
+	if t := params.Get("launch_timeout"); t != "" {
+		cfg.launchTimeout, err = time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid launch_timeout: %w", err)
+		}
+	}
+	params.Del("launch_timeout")
+
+	if t := params.Get("timeout"); t != "" {
+		cfg.queryTimeout, err = time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	params.Del("timeout")
+
+	for key, values := range params {
+		if len(values) > 0 {
+			cfg.conf[key] = values[len(values)-1]
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,653 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/beltran/gohive"
+	"github.com/beltran/gohive/hiveserver"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver            = (*kyuubiDriver)(nil)
+	_ core.ContextStructurer = (*kyuubiDriver)(nil)
+	_ core.DatabaseSwitcher  = (*kyuubiDriver)(nil)
+	_ core.ProgressQuerier   = (*kyuubiDriver)(nil)
+)
+
+const (
+	// kyuubiFetchSize is the number of rows fetched at once.
+	kyuubiFetchSize = 1000
+	// kyuubiStageLaunching is the progress stage of queries waiting for the engine.
+	kyuubiStageLaunching = "engine launching"
+)
+
+// kyuubiPollInterval is the initial interval of polling the state of running queries.
+// It's doubled up to a second while the query runs.
+var kyuubiPollInterval = 50 * time.Millisecond
+
+type kyuubiDriver struct {
+	cfg *kyuubiConfig
+
+	mu      sync.Mutex
+	session *kyuubiSession
+	// database is the current database, it's selected again if the session is reopened
+	database string
+	// set once the engine ran a query
+	launched bool
+}
+
+// kyuubiSession is an open session. Calls of its cursors are serialized, because the
+// thrift client can't be used concurrently.
+type kyuubiSession struct {
+	mu   sync.Mutex
+	conn *gohive.Connection
+	// set once the connection failed, the session is reopened by the next query
+	broken bool
+}
+
+// do runs the call of the cursor and returns the error of the cursor. Failed connections
+// break the session.
+func (s *kyuubiSession) do(cursor *gohive.Cursor, call func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken {
+		return errors.New("connection is closed")
+	}
+
+	call()
+
+	err := cursor.Err
+	var transportErr thrift.TTransportException
+	if errors.As(err, &transportErr) {
+		s.broken = true
+		_ = s.conn.Close()
+	}
+
+	// errors of statements have the status of the response in their text
+	var hiveErr gohive.HiveError
+	if errors.As(err, &hiveErr) && hiveErr.Message != "" {
+		return errors.New(hiveErr.Message)
+	}
+	return err
+}
+
+// isBroken reports whether the connection of the session failed.
+func (s *kyuubiSession) isBroken() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.broken
+}
+
+// close closes the cursor, unless the connection failed.
+func (s *kyuubiSession) close(cursor *gohive.Cursor) {
+	if !s.isBroken() {
+		_ = s.do(cursor, cursor.Close)
+	}
+}
+
+func (d *kyuubiDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	return d.QueryProgress(ctx, query, nil, func(*core.Progress) {})
+}
+
+// QueryProgress runs the query after applying settings with SET statements, so they stay
+// set for the session. The first query waits for the engine to launch (up to the launch
+// timeout of the connection), which is reported as the "engine launching" stage.
+func (d *kyuubiDriver) QueryProgress(ctx context.Context, query string, settings map[string]string, onProgress func(*core.Progress)) (core.ResultStream, error) {
+	d.mu.Lock()
+	launching := !d.launched
+	d.mu.Unlock()
+
+	launchCtx, cancel := ctx, context.CancelFunc(func() {})
+	if launching {
+		onProgress(&core.Progress{Stage: kyuubiStageLaunching})
+		launchCtx, cancel = context.WithTimeout(ctx, d.cfg.launchTimeout)
+	}
+	defer cancel()
+
+	session, err := d.open(launchCtx)
+	if err != nil {
+		return nil, d.launchError(ctx, launchCtx, err)
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cursor, err := d.execute(ctx, launchCtx, session, fmt.Sprintf("SET %s=%s", name, settings[name]), func(*core.Progress) {})
+		if err != nil {
+			return nil, err
+		}
+		session.close(cursor)
+	}
+
+	cursor, err := d.execute(ctx, launchCtx, session, query, onProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns [][]string
+	descErr := session.do(cursor, func() { columns = cursor.Description() })
+	// e.g. "CREATE TABLE" (servers either fail or return no columns)
+	if descErr != nil || len(columns) < 1 {
+		session.close(cursor)
+		if session.isBroken() {
+			return nil, descErr
+		}
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			Build(), nil
+	}
+
+	return newKyuubiStream(ctx, session, cursor, columns), nil
+}
+
+// execute runs the statement and waits for it to finish. The query timeout of the
+// connection is enforced here, as the time of the statement isn't limited by the server.
+func (d *kyuubiDriver) execute(ctx, launchCtx context.Context, session *kyuubiSession, statement string, onProgress func(*core.Progress)) (*gohive.Cursor, error) {
+	queryCtx, queryLaunchCtx := ctx, launchCtx
+	if d.cfg.queryTimeout > 0 {
+		var cancel, cancelLaunch context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, d.cfg.queryTimeout)
+		defer cancel()
+		queryLaunchCtx, cancelLaunch = context.WithTimeout(launchCtx, d.cfg.queryTimeout)
+		defer cancelLaunch()
+	}
+	// queries of a launched engine aren't bounded by the launch timeout
+	if launchCtx == ctx {
+		queryLaunchCtx = queryCtx
+	}
+
+	cursor := session.conn.Cursor()
+	if err := session.do(cursor, func() { cursor.Execute(queryLaunchCtx, statement, true) }); err != nil {
+		return nil, d.launchError(ctx, launchCtx, err)
+	}
+
+	if err := d.wait(queryCtx, queryLaunchCtx, session, cursor, onProgress); err != nil {
+		// the operation can't be canceled over a failed connection
+		if !session.isBroken() {
+			_ = session.do(cursor, cursor.Cancel)
+			session.close(cursor)
+		}
+		if ctx.Err() == nil && errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("query timed out after %s", d.cfg.queryTimeout)
+		}
+		return nil, d.launchError(ctx, launchCtx, err)
+	}
+
+	return cursor, nil
+}
+
+// launchError explains errors of waiting for the engine to launch.
+func (d *kyuubiDriver) launchError(ctx, launchCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(launchCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("engine didn't launch within %s (see launch_timeout option)", d.cfg.launchTimeout)
+	}
+	return err
+}
+
+// wait polls the state of the statement of the cursor until it's finished. Statements of
+// a launching engine are pending (bounded by launchCtx) until the engine starts running them.
+func (d *kyuubiDriver) wait(ctx, launchCtx context.Context, session *kyuubiSession, cursor *gohive.Cursor, onProgress func(*core.Progress)) error {
+	interval := kyuubiPollInterval
+	// the launching stage is reported before the session is opened
+	stage := ""
+	if launchCtx != ctx {
+		stage = kyuubiStageLaunching
+	}
+	for {
+		pollCtx := ctx
+		d.mu.Lock()
+		if !d.launched {
+			pollCtx = launchCtx
+		}
+		d.mu.Unlock()
+
+		var status *hiveserver.TGetOperationStatusResp
+		if err := session.do(cursor, func() { status = cursor.Poll(false) }); err != nil {
+			return err
+		}
+
+		state := status.GetOperationState()
+		switch state {
+		case hiveserver.TOperationState_INITIALIZED_STATE, hiveserver.TOperationState_PENDING_STATE:
+		case hiveserver.TOperationState_RUNNING_STATE, hiveserver.TOperationState_FINISHED_STATE:
+			d.mu.Lock()
+			d.launched = true
+			d.mu.Unlock()
+		case hiveserver.TOperationState_CANCELED_STATE, hiveserver.TOperationState_CLOSED_STATE:
+			return errors.New("query was canceled")
+		case hiveserver.TOperationState_TIMEDOUT_STATE:
+			return errors.New("query timed out")
+		default:
+			msg := status.GetErrorMessage()
+			if msg == "" {
+				msg = "query failed"
+			}
+			return errors.New(msg)
+		}
+
+		// stages are reported on changes, except for queries finished before the first one
+		if s := kyuubiStage(state, pollCtx != ctx); s != stage && (stage != "" || state != hiveserver.TOperationState_FINISHED_STATE) {
+			stage = s
+			onProgress(&core.Progress{Stage: stage})
+		}
+
+		if state == hiveserver.TOperationState_FINISHED_STATE {
+			return nil
+		}
+
+		select {
+		case <-pollCtx.Done():
+			return pollCtx.Err()
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, time.Second)
+	}
+}
+
+// kyuubiStage returns the progress stage of the operation in the state.
+func kyuubiStage(state hiveserver.TOperationState, launching bool) string {
+	switch {
+	case state == hiveserver.TOperationState_RUNNING_STATE, state == hiveserver.TOperationState_FINISHED_STATE:
+		return "running"
+	case launching:
+		return kyuubiStageLaunching
+	default:
+		return "pending"
+	}
+}
+
+// open returns the session, opening it if it's not open yet (or the connection failed).
+func (d *kyuubiDriver) open(ctx context.Context) (*kyuubiSession, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.session != nil && !d.session.isBroken() {
+		return d.session, nil
+	}
+
+	conf := make(map[string]string, len(d.cfg.conf)+1)
+	for key, value := range d.cfg.conf {
+		conf[key] = value
+	}
+	// selected by the server, as USE would wait for the engine to launch
+	if d.database != "" {
+		conf["use:database"] = d.database
+	}
+
+	cfg := gohive.NewConnectConfiguration()
+	cfg.Username = d.cfg.user
+	cfg.Password = d.cfg.password
+	cfg.HiveConfiguration = conf
+	cfg.FetchSize = kyuubiFetchSize
+
+	auth := "NONE"
+	if !d.cfg.sasl {
+		auth = "NOSASL"
+	}
+
+	type result struct {
+		conn *gohive.Connection
+		err  error
+	}
+	opened := make(chan result, 1)
+	// the connection can't be canceled, so it's closed once it's opened after all
+	go func() {
+		conn, err := gohive.Connect(d.cfg.host, d.cfg.port, auth, cfg)
+		opened <- result{conn: conn, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		go func() {
+			if r := <-opened; r.err == nil {
+				_ = r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	case r := <-opened:
+		if r.err != nil {
+			return nil, fmt.Errorf("unable to open session: %w", r.err)
+		}
+		d.session = &kyuubiSession{conn: r.conn}
+		return d.session, nil
+	}
+}
+
+// newKyuubiStream returns a stream of rows of the cursor, which are fetched in batches.
+// The cursor is closed with the stream.
+func newKyuubiStream(ctx context.Context, session *kyuubiSession, cursor *gohive.Cursor, columns [][]string) core.ResultStream {
+	header := make(core.Header, len(columns))
+	for i, column := range columns {
+		header[i] = column[0]
+	}
+
+	var (
+		done bool
+		err  error
+	)
+
+	hasNext := func() bool {
+		if done {
+			return false
+		}
+		var more bool
+		fetchErr := session.do(cursor, func() { more = cursor.HasMore(ctx) })
+		// the end of the result is reported with an error of missing rows
+		if !more {
+			done = true
+			return false
+		}
+		if fetchErr != nil {
+			err = fetchErr
+			done = true
+			return false
+		}
+		return true
+	}
+
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		dests := kyuubiDests(columns)
+		if err := session.do(cursor, func() { cursor.FetchOne(ctx, dests...) }); err != nil {
+			return nil, err
+		}
+		return kyuubiRow(dests), nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		WithErrFunc(func() error { return err }).
+		WithCloseFunc(func() { session.close(cursor) }).
+		Build()
+}
+
+// kyuubiDests returns destinations of values of a row, which keep nulls.
+func kyuubiDests(columns [][]string) []any {
+	dests := make([]any, len(columns))
+	for i, column := range columns {
+		switch column[1] {
+		case "BOOLEAN_TYPE":
+			dests[i] = new(*bool)
+		case "TINYINT_TYPE":
+			dests[i] = new(*int8)
+		case "SMALLINT_TYPE":
+			dests[i] = new(*int16)
+		case "INT_TYPE":
+			dests[i] = new(*int32)
+		case "BIGINT_TYPE":
+			dests[i] = new(*int64)
+		case "FLOAT_TYPE", "DOUBLE_TYPE":
+			dests[i] = new(*float64)
+		case "BINARY_TYPE":
+			dests[i] = new([]byte)
+		default:
+			// other types (e.g. decimals, dates and complex types) are returned as strings
+			dests[i] = new(*string)
+		}
+	}
+	return dests
+}
+
+// kyuubiRow returns values of the destinations, where nulls are nil.
+func kyuubiRow(dests []any) core.Row {
+	row := make(core.Row, len(dests))
+	for i, dest := range dests {
+		switch v := dest.(type) {
+		case **bool:
+			row[i] = kyuubiValue(*v)
+		case **int8:
+			row[i] = kyuubiValue(*v)
+		case **int16:
+			row[i] = kyuubiValue(*v)
+		case **int32:
+			row[i] = kyuubiValue(*v)
+		case **int64:
+			row[i] = kyuubiValue(*v)
+		case **float64:
+			row[i] = kyuubiValue(*v)
+		case **string:
+			row[i] = kyuubiValue(*v)
+		case *[]byte:
+			if *v != nil {
+				row[i] = *v
+			}
+		}
+	}
+	return row
+}
+
+// kyuubiValue returns the value of the pointer, or nil.
+func kyuubiValue[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func (d *kyuubiDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	rows, err := d.rows(context.Background(), "DESCRIBE "+impalaTable(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*core.Column
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		// partitioning and other details follow after an empty line or a "#" heading
+		name := strings.TrimSpace(fmt.Sprint(row[0]))
+		if name == "" || strings.HasPrefix(name, "#") {
+			break
+		}
+		columns = append(columns, &core.Column{
+			Name: name,
+			Type: fmt.Sprint(row[1]),
+		})
+	}
+
+	return columns, nil
+}
+
+func (d *kyuubiDriver) Structure() ([]*core.Structure, error) {
+	return d.StructureCtx(context.Background())
+}
+
+// StructureCtx lists tables and views of all databases.
+func (d *kyuubiDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	databases, err := d.column(ctx, "SHOW DATABASES", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var structure []*core.Structure
+	for _, db := range databases {
+		tables, err := d.tables(ctx, "SHOW TABLES IN "+impalaQuote(db), db)
+		if err != nil {
+			return nil, err
+		}
+		// views are listed among tables, the query isn't supported by all engines
+		views, _ := d.tables(ctx, "SHOW VIEWS IN "+impalaQuote(db), db)
+		isView := make(map[string]bool, len(views))
+		for _, view := range views {
+			isView[view] = true
+		}
+
+		children := make([]*core.Structure, 0, len(tables))
+		for _, table := range tables {
+			typ := core.StructureTypeTable
+			if isView[table] {
+				typ = core.StructureTypeView
+			}
+			children = append(children, &core.Structure{
+				Name:   table,
+				Schema: db,
+				Type:   typ,
+			})
+		}
+
+		structure = append(structure, &core.Structure{
+			Name:     db,
+			Schema:   db,
+			Type:     core.StructureTypeNone,
+			Children: children,
+		})
+	}
+
+	sort.Slice(structure, func(i, j int) bool {
+		return structure[i].Name < structure[j].Name
+	})
+
+	return structure, nil
+}
+
+// tables returns names of tables (or views) listed by the query. Spark lists them with
+// the namespace first (database, name, isTemporary), where temporary views are listed
+// in all databases with an empty namespace, so they're skipped.
+func (d *kyuubiDriver) tables(ctx context.Context, query, db string) ([]string, error) {
+	rows, err := d.rows(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, row := range rows {
+		switch len(row) {
+		case 0:
+		case 1:
+			names = append(names, fmt.Sprint(row[0]))
+		default:
+			if ns, _ := row[0].(string); ns != db {
+				continue
+			}
+			names = append(names, fmt.Sprint(row[1]))
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// column returns values of the column of the query result.
+func (d *kyuubiDriver) column(ctx context.Context, query string, index int) ([]string, error) {
+	rows, err := d.rows(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for _, row := range rows {
+		if index < len(row) && row[index] != nil {
+			values = append(values, fmt.Sprint(row[index]))
+		}
+	}
+	return values, nil
+}
+
+// rows returns all rows of the query result.
+func (d *kyuubiDriver) rows(ctx context.Context, query string) ([]core.Row, error) {
+	result, err := d.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var rows []core.Row
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	if failable, ok := result.(core.FailableStream); ok {
+		if err := failable.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+func (d *kyuubiDriver) ListDatabases() (current string, available []string, err error) {
+	ctx := context.Background()
+
+	current, err = d.currentDatabase(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	databases, err := d.column(ctx, "SHOW DATABASES", 0)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, db := range databases {
+		if db != current {
+			available = append(available, db)
+		}
+	}
+
+	return current, available, nil
+}
+
+func (d *kyuubiDriver) currentDatabase(ctx context.Context) (string, error) {
+	values, err := d.column(ctx, "SELECT current_database()", 0)
+	if err != nil {
+		return "", err
+	}
+	if len(values) < 1 {
+		return "", errors.New("no current database")
+	}
+	return values[0], nil
+}
+
+// SelectDatabase switches the database of the session. The database is selected
+// again when the session is reopened.
+func (d *kyuubiDriver) SelectDatabase(name string) error {
+	result, err := d.Query(context.Background(), "USE "+impalaQuote(name))
+	if err != nil {
+		return err
+	}
+	result.Close()
+
+	d.mu.Lock()
+	d.database = name
+	d.mu.Unlock()
+
+	return nil
+}
+
+func (d *kyuubiDriver) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.session == nil {
+		return
+	}
+
+	d.session.mu.Lock()
+	if !d.session.broken {
+		d.session.broken = true
+		_ = d.session.conn.Close()
+	}
+	d.session.mu.Unlock()
+	d.session = nil
+}
@@ -0,0 +1,76 @@
+package adapters
+
+import (
+	"fmt"
+	nurl "net/url"
+	"strings"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Memcached{}, "memcached", "memcache")
+}
+
+var _ core.Adapter = (*Memcached)(nil)
+
+// Memcached allows inspecting keys and statistics of a memcached server.
+type Memcached struct{}
+
+// Connect creates a [Memcached] client.
+// The format of the url is as follows:
+//
+//	memcached://host:port[?timeout=duration]
+//
+// Where "timeout" is the network timeout of each command (e.g. 2s).
+func (*Memcached) Connect(rawURL string) (core.Driver, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "memcached://" + rawURL
+	}
+
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	switch u.Scheme {
+	case "memcached", "memcache":
+	default:
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr += ":11211"
+	}
+
+	timeout := memcache.DefaultTimeout
+	if t := u.Query().Get("timeout"); t != "" {
+		timeout, err = time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	c := memcache.New(addr)
+	c.Timeout = timeout
+
+	return &memcachedDriver{
+		c:       c,
+		addr:    addr,
+		timeout: timeout,
+	}, nil
+}
+
+func (*Memcached) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"Get":   fmt.Sprintf("get %s", opts.Table),
+		"Stats": "stats",
+		"Items": "stats items",
+		"Slabs": "stats slabs",
+	}
+}
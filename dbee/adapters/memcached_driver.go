@@ -0,0 +1,287 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var _ core.Driver = (*memcachedDriver)(nil)
+
+type memcachedDriver struct {
+	c       *memcache.Client
+	addr    string
+	timeout time.Duration
+}
+
+// Query supports the following (read only) commands:
+//   - "get <key> [<key>...]" and "gets <key> [<key>...]" return values of keys
+//   - "stats [<argument>...]" returns server statistics (e.g. "stats items", "stats slabs")
+//   - "stats cachedump <slab> <limit>" lists keys stored in a slab
+func (c *memcachedDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	fields := strings.Fields(query)
+	if len(fields) < 1 {
+		return nil, errors.New("empty command")
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "get", "gets":
+		return c.get(fields[1:])
+	case "stats":
+		if len(fields) > 1 && strings.EqualFold(fields[1], "cachedump") {
+			return c.cachedump(ctx, fields[1:])
+		}
+		return c.stats(ctx, fields[1:])
+	default:
+		return nil, fmt.Errorf("unsupported command: %q (supported commands are: get, gets, stats)", fields[0])
+	}
+}
+
+func (c *memcachedDriver) get(keys []string) (core.ResultStream, error) {
+	if len(keys) < 1 {
+		return nil, errors.New("no keys specified")
+	}
+
+	items, err := c.c.GetMulti(keys)
+	if err != nil {
+		return nil, fmt.Errorf("c.c.GetMulti: %w", err)
+	}
+
+	// keep the order of requested keys, missing keys are skipped
+	var rows []core.Row
+	for _, key := range keys {
+		item, ok := items[key]
+		if !ok {
+			continue
+		}
+		rows = append(rows, core.Row{item.Key, memcachedValue(item.Value), int64(item.Flags)})
+	}
+
+	return memcachedResult(core.Header{"Key", "Value", "Flags"}, rows), nil
+}
+
+// memcachedValue displays values as text, unless they are binary.
+func memcachedValue(value []byte) any {
+	if utf8.Valid(value) {
+		return string(value)
+	}
+	return value
+}
+
+func (c *memcachedDriver) stats(ctx context.Context, args []string) (core.ResultStream, error) {
+	lines, err := c.command(ctx, "stats "+strings.Join(args, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []core.Row
+	for _, line := range lines {
+		// STAT <name> <value>
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 3 || fields[0] != "STAT" {
+			continue
+		}
+		rows = append(rows, core.Row{fields[1], fields[2]})
+	}
+
+	return memcachedResult(core.Header{"Name", "Value"}, rows), nil
+}
+
+func (c *memcachedDriver) cachedump(ctx context.Context, args []string) (core.ResultStream, error) {
+	items, err := c.dumpKeys(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]core.Row, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, core.Row{item.key, item.size, item.expires})
+	}
+
+	return memcachedResult(core.Header{"Key", "Size", "Expires"}, rows), nil
+}
+
+// memcachedResult builds a result stream from already parsed rows.
+func memcachedResult(header core.Header, rows []core.Row) core.ResultStream {
+	i := 0
+	hasNext := func() bool {
+		return i < len(rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		i++
+		return rows[i-1], nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build()
+}
+
+// memcachedKey is a key listed by "stats cachedump".
+type memcachedKey struct {
+	key  string
+	size int64
+	// unix time of expiration (0 for keys that never expire)
+	expires int64
+}
+
+// dumpKeys runs "stats cachedump" with args and parses the listed keys.
+func (c *memcachedDriver) dumpKeys(ctx context.Context, args []string) ([]*memcachedKey, error) {
+	lines, err := c.command(ctx, "stats "+strings.Join(args, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*memcachedKey
+	for _, line := range lines {
+		// ITEM <key> [<size> b; <expiration> s]
+		fields := strings.Fields(strings.NewReplacer("[", "", "]", "", ";", "").Replace(line))
+		if len(fields) < 6 || fields[0] != "ITEM" {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[2], 10, 64)
+		expires, _ := strconv.ParseInt(fields[4], 10, 64)
+
+		keys = append(keys, &memcachedKey{
+			key:     fields[1],
+			size:    size,
+			expires: expires,
+		})
+	}
+
+	return keys, nil
+}
+
+// command sends a raw command, which isn't supported by the client library,
+// and returns the lines of response up to the terminating "END".
+func (c *memcachedDriver) command(ctx context.Context, cmd string) ([]string, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dialer.DialContext: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := fmt.Fprintf(conn, "%s\r\n", strings.TrimSpace(cmd)); err != nil {
+		return nil, fmt.Errorf("failed sending command: %w", err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case line == "END":
+			return lines, nil
+		case line == "ERROR":
+			return nil, fmt.Errorf("unknown command: %q", cmd)
+		case strings.HasPrefix(line, "CLIENT_ERROR "), strings.HasPrefix(line, "SERVER_ERROR "):
+			return nil, errors.New(line)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading response: %w", err)
+	}
+
+	return nil, errors.New("unexpected end of response")
+}
+
+func (c *memcachedDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	return []*core.Column{
+		{Name: "Key", Type: "string"},
+		{Name: "Value", Type: "bytes"},
+		{Name: "Flags", Type: "uint32"},
+	}, nil
+}
+
+// Structure lists keys grouped by slab class. Memcached can't enumerate keys,
+// so the list comes from "stats cachedump", which is best-effort: it is limited
+// in size by the server, may be disabled and it's not exhaustive.
+func (c *memcachedDriver) Structure() ([]*core.Structure, error) {
+	ctx := context.Background()
+
+	lines, err := c.command(ctx, "stats items")
+	if err != nil {
+		return nil, err
+	}
+
+	// STAT items:<slab>:<name> <value>
+	slabs := make(map[int]struct{})
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		parts := strings.Split(fields[1], ":")
+		if len(parts) < 3 || parts[0] != "items" {
+			continue
+		}
+		slab, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		slabs[slab] = struct{}{}
+	}
+
+	ids := make([]int, 0, len(slabs))
+	for slab := range slabs {
+		ids = append(ids, slab)
+	}
+	sort.Ints(ids)
+
+	structure := make([]*core.Structure, 0, len(ids))
+	for _, slab := range ids {
+		schema := fmt.Sprintf("slab %d", slab)
+
+		keys, err := c.dumpKeys(ctx, []string{"cachedump", strconv.Itoa(slab), "0"})
+		if err != nil {
+			return nil, err
+		}
+
+		children := make([]*core.Structure, 0, len(keys))
+		for _, k := range keys {
+			children = append(children, &core.Structure{
+				Name:   k.key,
+				Schema: schema,
+				Type:   core.StructureTypeTable,
+			})
+		}
+
+		structure = append(structure, &core.Structure{
+			Name:     schema,
+			Schema:   schema,
+			Type:     core.StructureTypeNone,
+			Children: children,
+		})
+	}
+
+	return structure, nil
+}
+
+func (c *memcachedDriver) Close() {
+	_ = c.c.Close()
+}
@@ -0,0 +1,195 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// newMemcachedTestServer starts a server which speaks enough of the memcached
+// text protocol for the driver and returns its address.
+func newMemcachedTestServer(t *testing.T, values map[string]string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	responses := map[string]string{
+		"stats":                "STAT pid 1\r\nSTAT version 1.6.21\r\nEND\r\n",
+		"stats items":          "STAT items:1:number 2\r\nSTAT items:1:age 10\r\nSTAT items:5:number 1\r\nEND\r\n",
+		"stats cachedump 1 0":  "ITEM greeting [5 b; 0 s]\r\nITEM user:1 [13 b; 1700000000 s]\r\nEND\r\n",
+		"stats cachedump 5 0":  "ITEM blob [2000 b; 0 s]\r\nEND\r\n",
+		"stats cachedump 9 10": "CLIENT_ERROR bad command line\r\n",
+	}
+
+	handle := func(conn net.Conn) {
+		defer conn.Close()
+		rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+		for {
+			line, err := rw.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimSpace(line)
+
+			switch {
+			case strings.HasPrefix(cmd, "gets "), strings.HasPrefix(cmd, "get "):
+				for _, key := range strings.Fields(cmd)[1:] {
+					if val, ok := values[key]; ok {
+						fmt.Fprintf(rw, "VALUE %s 7 %d 1\r\n%s\r\n", key, len(val), val)
+					}
+				}
+				fmt.Fprint(rw, "END\r\n")
+			default:
+				resp, ok := responses[cmd]
+				if !ok {
+					resp = "ERROR\r\n"
+				}
+				fmt.Fprint(rw, resp)
+			}
+			rw.Flush()
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestMemcached_Query(t *testing.T) {
+	addr := newMemcachedTestServer(t, map[string]string{
+		"greeting": "hello",
+		"user:1":   `{"name":"jo"}`,
+	})
+
+	driver, err := new(Memcached).Connect("memcached://" + addr)
+	require.NoError(t, err)
+	defer driver.Close()
+
+	type testCase struct {
+		name           string
+		query          string
+		expectedHeader core.Header
+		expectedRows   []core.Row
+		expectedError  string
+	}
+
+	testCases := []testCase{
+		{
+			name:           "get keeps order and skips missing keys",
+			query:          "get user:1 missing greeting",
+			expectedHeader: core.Header{"Key", "Value", "Flags"},
+			expectedRows: []core.Row{
+				{"user:1", `{"name":"jo"}`, int64(7)},
+				{"greeting", "hello", int64(7)},
+			},
+		},
+		{
+			name:           "stats",
+			query:          "stats",
+			expectedHeader: core.Header{"Name", "Value"},
+			expectedRows: []core.Row{
+				{"pid", "1"},
+				{"version", "1.6.21"},
+			},
+		},
+		{
+			name:           "cachedump",
+			query:          "STATS cachedump 1 0",
+			expectedHeader: core.Header{"Key", "Size", "Expires"},
+			expectedRows: []core.Row{
+				{"greeting", int64(5), int64(0)},
+				{"user:1", int64(13), int64(1700000000)},
+			},
+		},
+		{
+			name:          "server error",
+			query:         "stats cachedump 9 10",
+			expectedError: "CLIENT_ERROR bad command line",
+		},
+		{
+			name:          "unknown stats argument",
+			query:         "stats nonsense",
+			expectedError: "unknown command",
+		},
+		{
+			name:          "unsupported command",
+			query:         "set key 0 0 1",
+			expectedError: "unsupported command",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			result, err := driver.Query(context.Background(), tc.query)
+			if tc.expectedError != "" {
+				r.ErrorContains(err, tc.expectedError)
+				return
+			}
+			r.NoError(err)
+			defer result.Close()
+
+			r.Equal(tc.expectedHeader, result.Header())
+
+			var rows []core.Row
+			for result.HasNext() {
+				row, err := result.Next()
+				r.NoError(err)
+				rows = append(rows, row)
+			}
+			r.Equal(tc.expectedRows, rows)
+		})
+	}
+}
+
+func TestMemcached_Structure(t *testing.T) {
+	r := require.New(t)
+
+	addr := newMemcachedTestServer(t, nil)
+
+	driver, err := new(Memcached).Connect(addr)
+	r.NoError(err)
+	defer driver.Close()
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+
+	r.Equal([]*core.Structure{
+		{
+			Name:   "slab 1",
+			Schema: "slab 1",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "greeting", Schema: "slab 1", Type: core.StructureTypeTable},
+				{Name: "user:1", Schema: "slab 1", Type: core.StructureTypeTable},
+			},
+		},
+		{
+			Name:   "slab 5",
+			Schema: "slab 5",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "blob", Schema: "slab 5", Type: core.StructureTypeTable},
+			},
+		},
+	}, structure)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -20,7 +21,6 @@ func init() {
 
 	// register known types with gob
 	// full list available in go.mongodb.org/.../bson godoc
-	gob.Register(&mongoResponse{})
 	gob.Register(bson.A{})
 	gob.Register(bson.M{})
 	gob.Register(bson.D{})
@@ -58,12 +58,23 @@ func (m *Mongo) Connect(rawURL string) (core.Driver, error) {
 
 	return &mongoDriver{
 		c:      client,
-		dbName: u.Path[1:],
+		dbName: strings.TrimPrefix(u.Path, "/"),
 	}, nil
 }
 
+// GetHelpers returns database commands (extended JSON, as run by Query) for the
+// collection. Filters and the distinct field are placeholders meant to be edited.
+// Commands of collections in a schema (database) run on that database ("$db" field).
 func (*Mongo) GetHelpers(opts *core.TableOptions) map[string]string {
+	db := ""
+	if opts.Schema != "" {
+		db = fmt.Sprintf(`, "$db": %q`, opts.Schema)
+	}
+
 	return map[string]string{
-		"List": fmt.Sprintf(`{"find": %q}`, opts.Table),
+		"List":     fmt.Sprintf(`{"find": %q%s}`, opts.Table, db),
+		"Find":     fmt.Sprintf(`{"find": %q, "filter": {}, "sort": {"_id": 1}, "limit": 100%s}`, opts.Table, db),
+		"Count":    fmt.Sprintf(`{"count": %q, "query": {}%s}`, opts.Table, db),
+		"Distinct": fmt.Sprintf(`{"distinct": %q, "key": "_id", "query": {}%s}`, opts.Table, db),
 	}
 }
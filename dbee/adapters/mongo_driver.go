@@ -1,16 +1,17 @@
 package adapters
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -50,87 +51,151 @@ func (c *mongoDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	}, nil
 }
 
-func (c *mongoDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
-	dbName, err := c.getCurrentDatabase(ctx)
-	if err != nil {
-		return nil, err
+// mongoCursorCommands are commands which reply with a cursor. Their documents
+// are fetched until the cursor is exhausted.
+var mongoCursorCommands = map[string]bool{
+	"aggregate":       true,
+	"find":            true,
+	"listCollections": true,
+	"listIndexes":     true,
+}
+
+// mongoReturnsCursor reports if the command replies with a cursor. Explained
+// aggregations reply with the plan instead.
+func mongoReturnsCursor(command bson.D) bool {
+	if len(command) < 1 || !mongoCursorCommands[command[0].Key] {
+		return false
+	}
+	for _, e := range command {
+		if explain, ok := e.Value.(bool); ok && e.Key == "explain" && explain {
+			return false
+		}
 	}
-	db := c.c.Database(dbName)
+	return true
+}
 
-	var command any
-	err = bson.UnmarshalExtJSON([]byte(query), false, &command)
+// Query runs the database command (in extended JSON) on the current database or on the one
+// in the "$db" field of the command. Documents of the reply's cursor (or the reply itself)
+// are returned as rows.
+func (c *mongoDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	var command bson.D
+	err := bson.UnmarshalExtJSON([]byte(query), false, &command)
 	if err != nil {
 		return nil, fmt.Errorf("cannot marshal command: \"%v\" to bson: %v", query, err)
 	}
 
-	var resp bson.M
-	err = db.RunCommand(ctx, command).Decode(&resp)
+	dbName, command, err := c.commandDatabase(ctx, command)
 	if err != nil {
 		return nil, err
 	}
 
-	// check if "cursor" field exists and create an appropriate func
-	var next func() (core.Row, error)
-	var hasNext func() bool
-
-	cur, ok := resp["cursor"]
-	if ok {
-		next, hasNext = builders.NextYield(func(yield func(...any)) error {
-			cursor := cur.(bson.M)
-			if !ok {
-				return errors.New("type assertion for cursor object failed")
-			}
-
-			for _, b := range cursor {
-				batch, ok := b.(bson.A)
-				if !ok {
-					continue
-				}
-				for _, item := range batch {
-					yield(newMongoResponse(item))
-				}
-			}
-			return nil
-		})
+	var docs []bson.D
+	if mongoReturnsCursor(command) {
+		cursor, err := c.c.Database(dbName).RunCommandCursor(ctx, command)
+		if err != nil {
+			return nil, err
+		}
+		docs, err = mongoCursorDocuments(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		next, hasNext = builders.NextSingle(newMongoResponse(resp))
+		resp, err := c.c.Database(dbName).RunCommand(ctx, command).DecodeBytes()
+		if err != nil {
+			return nil, err
+		}
+		docs, err = mongoDocuments(resp)
+		if err != nil {
+			return nil, err
+		}
 	}
+	header, rows := mongoFlatten(docs)
 
 	// build result
 	result := builders.NewResultStreamBuilder().
-		WithNextFunc(next, hasNext).
-		WithHeader(core.Header{"Reply"}).
-		WithMeta(&core.Meta{
-			SchemaType: core.SchemaLess,
-		}).
+		WithNextFunc(builders.NextYield(func(yield func(...any)) error {
+			for _, row := range rows {
+				yield(row...)
+			}
+			return nil
+		})).
+		WithHeader(header).
 		Build()
 
 	return result, nil
 }
 
-func (c *mongoDriver) Structure() ([]*core.Structure, error) {
-	ctx := context.Background()
+// commandDatabase returns the database the command runs on and the command
+// without the "$db" field.
+func (c *mongoDriver) commandDatabase(ctx context.Context, command bson.D) (string, bson.D, error) {
+	for i, e := range command {
+		if e.Key != "$db" {
+			continue
+		}
+		name, ok := e.Value.(string)
+		if !ok || name == "" {
+			return "", nil, errors.New("\"$db\" field of the command is not a database name")
+		}
+		return name, append(command[:i:i], command[i+1:]...), nil
+	}
 
 	dbName, err := c.getCurrentDatabase(ctx)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
+	return dbName, command, nil
+}
 
-	collections, err := c.c.Database(dbName).ListCollectionNames(ctx, bson.D{})
+// Structure lists the collections of all databases, grouped by database.
+func (c *mongoDriver) Structure() ([]*core.Structure, error) {
+	ctx := context.Background()
+
+	dbs, err := c.c.ListDatabaseNames(ctx, bson.D{})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to retrieve database names: %w", err)
 	}
 
+	return mongoStructure(dbs, func(db string) ([]string, error) {
+		return c.c.Database(db).ListCollectionNames(ctx, bson.D{})
+	})
+}
+
+// mongoStructure groups collections by database. Databases whose collections can't be
+// listed (e.g. because of missing privileges) are skipped. An error is only returned
+// if none of the databases could be listed.
+func mongoStructure(dbs []string, listCollections func(db string) ([]string, error)) ([]*core.Structure, error) {
 	var structure []*core.Structure
+	var errs []error
+
+	for _, db := range dbs {
+		collections, err := listCollections(db)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list collections of %q: %w", db, err))
+			continue
+		}
+		sort.Strings(collections)
+
+		var children []*core.Structure
+		for _, coll := range collections {
+			children = append(children, &core.Structure{
+				Name:   coll,
+				Schema: db,
+				Type:   core.StructureTypeTable,
+			})
+		}
 
-	for _, coll := range collections {
 		structure = append(structure, &core.Structure{
-			Name:   coll,
-			Schema: "",
-			Type:   core.StructureTypeTable,
+			Name:     db,
+			Schema:   db,
+			Type:     core.StructureTypeNone,
+			Children: children,
 		})
 	}
 
+	if len(structure) < 1 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
 	return structure, nil
 }
 
@@ -168,48 +233,149 @@ func (c *mongoDriver) SelectDatabase(name string) error {
 	return nil
 }
 
-// mongoResponse serves as a wrapper around the mongo response
-// to stringify the return values
-type mongoResponse struct {
-	value any
+// mongoCursorDocuments decodes all documents of the cursor, fetching more batches
+// as needed, and closes it.
+func mongoCursorDocuments(ctx context.Context, cursor *mongo.Cursor) ([]bson.D, error) {
+	defer cursor.Close(ctx)
+
+	var docs []bson.D
+	for cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
 }
 
-func newMongoResponse(val any) *mongoResponse {
-	return &mongoResponse{
-		value: val,
+// mongoDocuments returns the documents of the first batch of the reply's cursor
+// or the reply itself if it has no cursor. Only used for commands which are not
+// known to reply with a cursor (see mongoCursorCommands).
+func mongoDocuments(resp bson.Raw) ([]bson.D, error) {
+	raws := []bson.Raw{resp}
+
+	if cursor, err := resp.LookupErr("cursor"); err == nil {
+		batch, err := cursor.Document().LookupErr("firstBatch")
+		if err != nil {
+			batch, err = cursor.Document().LookupErr("nextBatch")
+		}
+		if err != nil {
+			return nil, errors.New("cursor of the reply has no batch")
+		}
+		arr, ok := batch.ArrayOK()
+		if !ok {
+			return nil, errors.New("batch of the cursor is not an array")
+		}
+		values, err := arr.Values()
+		if err != nil {
+			return nil, err
+		}
+
+		raws = nil
+		for _, v := range values {
+			doc, ok := v.DocumentOK()
+			if !ok {
+				return nil, errors.New("batch of the cursor contains a non-document")
+			}
+			raws = append(raws, doc)
+		}
 	}
-}
 
-func (mr *mongoResponse) String() string {
-	parsed, err := json.MarshalIndent(mr.value, "", "  ")
-	if err != nil {
-		return fmt.Sprint(mr.value)
+	docs := make([]bson.D, 0, len(raws))
+	for _, raw := range raws {
+		var doc bson.D
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
 	}
-	return string(parsed)
+
+	return docs, nil
 }
 
-func (mr *mongoResponse) MarshalJSON() ([]byte, error) {
-	return json.Marshal(mr.value)
+// mongoFlatten converts documents to rows. The header is a superset of keys of all documents,
+// in order of appearance. Missing keys are nil and nested documents and arrays are
+// rendered as JSON strings.
+func mongoFlatten(docs []bson.D) (core.Header, [][]any) {
+	var header core.Header
+	columns := make(map[string]int)
+
+	for _, doc := range docs {
+		for _, e := range doc {
+			if _, ok := columns[e.Key]; !ok {
+				columns[e.Key] = len(header)
+				header = append(header, e.Key)
+			}
+		}
+	}
+
+	rows := make([][]any, 0, len(docs))
+	for _, doc := range docs {
+		row := make([]any, len(header))
+		for _, e := range doc {
+			row[columns[e.Key]] = mongoValue(e.Value)
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows
 }
 
-func (mr *mongoResponse) GobEncode() ([]byte, error) {
-	var err error
-	w := new(bytes.Buffer)
-	encoder := gob.NewEncoder(w)
-	err = encoder.Encode(mr.value)
-	if err != nil {
-		return nil, err
+// mongoValue converts a bson value to a value of a row.
+func mongoValue(val any) any {
+	switch v := val.(type) {
+	case primitive.D, primitive.M, primitive.A:
+		return mongoJSON(v)
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC()
+	case primitive.Decimal128:
+		return v.String()
+	case primitive.Null, primitive.Undefined:
+		return nil
+	default:
+		return v
 	}
-	return w.Bytes(), err
 }
 
-func (mr *mongoResponse) GobDecode(buf []byte) error {
-	var err error
-	r := bytes.NewBuffer(buf)
-	decoder := gob.NewDecoder(r)
-	err = decoder.Decode(&mr.value)
+// mongoJSON renders the bson value as JSON, keeping the order of document keys.
+func mongoJSON(val any) string {
+	switch v := val.(type) {
+	case primitive.D:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			key, _ := json.Marshal(e.Key)
+			parts = append(parts, string(key)+":"+mongoJSON(e.Value))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case primitive.M:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d := make(primitive.D, 0, len(v))
+		for _, k := range keys {
+			d = append(d, primitive.E{Key: k, Value: v[k]})
+		}
+		return mongoJSON(d)
+	case primitive.A:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			parts = append(parts, mongoJSON(e))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	}
+
+	out, err := json.Marshal(mongoValue(val))
 	if err != nil {
-		return err
+		out, _ = json.Marshal(fmt.Sprint(val))
 	}
-	return err
+	return string(out)
 }
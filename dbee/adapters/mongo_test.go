@@ -0,0 +1,146 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestMongoHelpers(t *testing.T) {
+	r := require.New(t)
+
+	helpers := (&Mongo{}).GetHelpers(&core.TableOptions{Schema: "shop", Table: `or"ders`})
+	r.Equal(`{"count": "or\"ders", "query": {}, "$db": "shop"}`, helpers["Count"])
+
+	// helpers are valid commands
+	for name, helper := range helpers {
+		var command bson.D
+		r.NoError(bson.UnmarshalExtJSON([]byte(helper), false, &command), name)
+		r.Equal(`or"ders`, command[0].Value, name)
+	}
+
+	var distinct bson.M
+	r.NoError(bson.UnmarshalExtJSON([]byte(helpers["Distinct"]), false, &distinct))
+	r.Equal("_id", distinct["key"])
+	r.Equal("shop", distinct["$db"])
+
+	helpers = (&Mongo{}).GetHelpers(&core.TableOptions{Table: "orders"})
+	r.Equal(`{"find": "orders"}`, helpers["List"])
+}
+
+func TestMongoDocuments(t *testing.T) {
+	r := require.New(t)
+
+	id := primitive.NewObjectID()
+	reply, err := bson.Marshal(bson.D{
+		{Key: "cursor", Value: bson.D{
+			{Key: "firstBatch", Value: bson.A{
+				bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "alice"}, {Key: "age", Value: int32(30)}},
+				bson.D{
+					{Key: "_id", Value: 2},
+					{Key: "address", Value: bson.D{{Key: "zip", Value: "1000"}, {Key: "city", Value: "Ljubljana"}}},
+					{Key: "tags", Value: bson.A{"a", int32(1), bson.D{{Key: "b", Value: nil}}}},
+					{Key: "name", Value: "bob"},
+				},
+			}},
+			{Key: "id", Value: int64(0)},
+			{Key: "ns", Value: "shop.users"},
+		}},
+		{Key: "ok", Value: 1.0},
+	})
+	r.NoError(err)
+
+	docs, err := mongoDocuments(reply)
+	r.NoError(err)
+
+	header, rows := mongoFlatten(docs)
+	r.Equal(core.Header{"_id", "name", "age", "address", "tags"}, header)
+	r.Equal([][]any{
+		{id.Hex(), "alice", int32(30), nil, nil},
+		{int32(2), "bob", nil, `{"zip":"1000","city":"Ljubljana"}`, `["a",1,{"b":null}]`},
+	}, rows)
+
+	// replies without a cursor are a single row
+	reply, err = bson.Marshal(bson.D{{Key: "n", Value: int32(2)}, {Key: "ok", Value: 1.0}})
+	r.NoError(err)
+
+	docs, err = mongoDocuments(reply)
+	r.NoError(err)
+
+	header, rows = mongoFlatten(docs)
+	r.Equal(core.Header{"n", "ok"}, header)
+	r.Equal([][]any{{int32(2), 1.0}}, rows)
+}
+
+func TestMongoCursorDocuments(t *testing.T) {
+	r := require.New(t)
+
+	r.True(mongoReturnsCursor(bson.D{{Key: "find", Value: "users"}}))
+	r.True(mongoReturnsCursor(bson.D{{Key: "aggregate", Value: "users"}, {Key: "cursor", Value: bson.D{}}}))
+	r.False(mongoReturnsCursor(bson.D{{Key: "aggregate", Value: "users"}, {Key: "explain", Value: true}}))
+	r.False(mongoReturnsCursor(bson.D{{Key: "count", Value: "users"}}))
+	r.False(mongoReturnsCursor(bson.D{}))
+
+	// all documents of the cursor are decoded
+	var documents []any
+	for i := 0; i < 250; i++ {
+		documents = append(documents, bson.D{{Key: "_id", Value: int32(i)}})
+	}
+	cursor, err := mongo.NewCursorFromDocuments(documents, nil, nil)
+	r.NoError(err)
+
+	docs, err := mongoCursorDocuments(context.Background(), cursor)
+	r.NoError(err)
+	r.Len(docs, 250)
+	r.Equal(bson.D{{Key: "_id", Value: int32(249)}}, docs[249])
+}
+
+func TestMongoStructure(t *testing.T) {
+	r := require.New(t)
+
+	collections := map[string][]string{
+		"shop":  {"users", "orders"},
+		"local": {"startup_log"},
+	}
+	list := func(db string) ([]string, error) {
+		colls, ok := collections[db]
+		if !ok {
+			return nil, errors.New("not authorized")
+		}
+		return colls, nil
+	}
+
+	// databases which can't be listed are skipped
+	structure, err := mongoStructure([]string{"admin", "shop", "local"}, list)
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name:   "shop",
+			Schema: "shop",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "orders", Schema: "shop", Type: core.StructureTypeTable},
+				{Name: "users", Schema: "shop", Type: core.StructureTypeTable},
+			},
+		},
+		{
+			Name:   "local",
+			Schema: "local",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "startup_log", Schema: "local", Type: core.StructureTypeTable},
+			},
+		},
+	}, structure)
+
+	_, err = mongoStructure([]string{"admin", "config"}, list)
+	r.ErrorContains(err, `failed to list collections of "admin": not authorized`)
+	r.ErrorContains(err, `failed to list collections of "config": not authorized`)
+}
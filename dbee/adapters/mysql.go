@@ -3,9 +3,8 @@ package adapters
 import (
 	"database/sql"
 	"fmt"
-	"regexp"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
@@ -13,40 +12,105 @@ import (
 
 // Register client
 func init() {
-	_ = register(&MySQL{}, "mysql")
+	_ = register(&MySQL{}, "mysql", "mariadb")
 }
 
 var _ core.Adapter = (*MySQL)(nil)
 
 type MySQL struct{}
 
+// Connect creates a [MySQL] client. The url is a mysql dsn, e.g.
+//
+//	user:password@tcp(host:port)/dbname?param=value
+//	user:password@unix(/var/run/mysqld/mysqld.sock)/dbname
 func (m *MySQL) Connect(url string) (core.Driver, error) {
-	// add multiple statements support parameter
-	match, err := regexp.MatchString(`[\?][\w]+=[\w-]+`, url)
+	db, cfg, err := openMySQL(url)
 	if err != nil {
 		return nil, err
 	}
-	sep := "?"
-	if match {
-		sep = "&"
+
+	return &mySQLDriver{
+		c:   builders.NewClient(db, builders.WithWarnings(mySQLWarnings)),
+		cfg: cfg,
+	}, nil
+}
+
+// openMySQL opens a pool of connections to the database of the dsn.
+func openMySQL(dsn string) (*sql.DB, *mysql.Config, error) {
+	// the dsn is parsed (instead of appending to it), so socket paths
+	// and parameter values can contain any characters
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mysql.ParseDSN: %w", err)
 	}
 
-	db, err := sql.Open("mysql", url+sep+"multiStatements=true")
+	// whole files (multiple statements) are run as a single query
+	cfg.MultiStatements = true
+
+	db, err := openMySQLConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, cfg, nil
+}
+
+// openMySQLConfig opens a pool of connections to the database of the config.
+func openMySQLConfig(cfg *mysql.Config) (*sql.DB, error) {
+	connector, err := mysql.NewConnector(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to mysql database: %v", err)
 	}
 
-	return &mySQLDriver{
-		c: builders.NewClient(db),
-	}, nil
+	return sql.OpenDB(connector), nil
 }
 
 func (*MySQL) GetHelpers(opts *core.TableOptions) map[string]string {
+	if opts.Materialization == core.StructureTypePartition {
+		partition := mySQLPartition(opts)
+		return map[string]string{
+			"List":      fmt.Sprintf("SELECT * FROM %s LIMIT 500", partition),
+			"Count":     fmt.Sprintf("SELECT COUNT(*) FROM %s", partition),
+			"Partition": fmt.Sprintf("%s AND PARTITION_NAME = '%s'", mySQLPartitionsHelper(opts.Schema, opts.Parent), opts.Table),
+		}
+	}
+
 	return map[string]string{
 		"List":         fmt.Sprintf("SELECT * FROM `%s` LIMIT 500", opts.Table),
 		"Columns":      fmt.Sprintf("DESCRIBE `%s`", opts.Table),
 		"Indexes":      fmt.Sprintf("SHOW INDEXES FROM `%s`", opts.Table),
 		"Foreign Keys": fmt.Sprintf("SELECT * FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' AND CONSTRAINT_TYPE = 'FOREIGN KEY'", opts.Schema, opts.Table),
 		"Primary Keys": fmt.Sprintf("SELECT * FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' AND CONSTRAINT_TYPE = 'PRIMARY KEY'", opts.Schema, opts.Table),
+		"Warnings":     "SHOW WARNINGS",
+		"Size": fmt.Sprintf(`SELECT CONCAT(ROUND((DATA_LENGTH + INDEX_LENGTH) / 1048576, 1), ' MiB') AS total,
+	CONCAT(ROUND(DATA_LENGTH / 1048576, 1), ' MiB') AS data, CONCAT(ROUND(INDEX_LENGTH / 1048576, 1), ' MiB') AS indexes,
+	CONCAT(ROUND(DATA_FREE / 1048576, 1), ' MiB') AS free,
+	DATA_LENGTH + INDEX_LENGTH AS total_bytes, DATA_LENGTH AS data_bytes, INDEX_LENGTH AS index_bytes, DATA_FREE AS free_bytes
+FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s'`, opts.Schema, opts.Table),
+		"Grants": mySQLGrantsQuery(opts, ""),
+		"Auto Increment": fmt.Sprintf(`SELECT column_name, current_value, increment_by, max_value,
+	FLOOR((max_value - COALESCE(current_value, 0)) / increment_by) AS remaining,
+	ROUND(100 * COALESCE(current_value, 0) / max_value, 2) AS used_percent
+FROM (%s) s
+WHERE table_schema = '%s' AND table_name = '%s'`, mySQLSequencesQuery, opts.Schema, opts.Table),
+		"Partitions": mySQLPartitionsHelper(opts.Schema, opts.Table) + " ORDER BY PARTITION_ORDINAL_POSITION, SUBPARTITION_ORDINAL_POSITION",
+	}
+}
+
+// mySQLPartitionsHelper returns a query listing bounds and row counts (estimates of the storage engine)
+// of partitions of the table.
+func mySQLPartitionsHelper(schema, table string) string {
+	return fmt.Sprintf(`SELECT PARTITION_NAME AS `+"`partition`"+`, SUBPARTITION_NAME AS subpartition, PARTITION_METHOD AS method,
+	PARTITION_EXPRESSION AS expression, PARTITION_DESCRIPTION AS bounds, TABLE_ROWS AS `+"`rows`"+`,
+	CONCAT(ROUND((DATA_LENGTH + INDEX_LENGTH) / 1048576, 1), ' MiB') AS size
+FROM INFORMATION_SCHEMA.PARTITIONS WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' AND PARTITION_NAME IS NOT NULL`, schema, table)
+}
+
+// mySQLPartition returns the selectable partition ("table PARTITION (name)") of the options.
+func mySQLPartition(opts *core.TableOptions) string {
+	table := builders.QuoteIdentifier(opts.Parent, builders.QuoteBacktick)
+	if opts.Schema != "" {
+		table = builders.QuoteIdentifier(opts.Schema, builders.QuoteBacktick) + "." + table
 	}
+	return fmt.Sprintf("%s PARTITION (%s)", table, builders.QuoteIdentifier(opts.Table, builders.QuoteBacktick))
 }
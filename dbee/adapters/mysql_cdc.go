@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// Register client
+func init() {
+	_ = register(&MySQLCDC{}, "mysql_cdc")
+}
+
+var (
+	_ core.Adapter   = (*MySQLCDC)(nil)
+	_ core.Validator = (*MySQLCDC)(nil)
+)
+
+// MySQLCDC is a companion adapter of [MySQL], which tails row changes from the binlog.
+type MySQLCDC struct{}
+
+// Connect creates a [MySQLCDC] client. The url is a mysql dsn with a mandatory "server_id" parameter:
+//
+//	user:password@tcp(host:port)/?server_id=<id>
+//	user:password@unix(/var/run/mysqld/mysqld.sock)/?server_id=<id>
+//
+// Where:
+//   - "server_id" is the id used when connecting as a replica. It has to be unique
+//     among the replicas of the server. Tailing is only possible if it's set explicitly.
+//   - "flavor" can be set to "mariadb" when connecting to a MariaDB server.
+//
+// The user needs the REPLICATION SLAVE and REPLICATION CLIENT privileges and the server
+// has to use row based logging (binlog_format=ROW). Column names are only known if
+// binlog_row_metadata=FULL, otherwise the columns are numbered.
+//
+// Queries starting with "TAIL" stream row changes until the call is canceled:
+//
+//	TAIL                     -- from the current position
+//	TAIL <binlog file> [pos] -- from the start (or position) of the binlog file
+//
+// Everything else is executed as a regular mysql query.
+func (m *MySQLCDC) Connect(url string) (core.Driver, error) {
+	cfg, syncerConfig, err := parseMySQLCDCDSN(url)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.MultiStatements = true
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to mysql database: %v", err)
+	}
+
+	return &mySQLCDCDriver{
+		sql: &mySQLDriver{
+			c: builders.NewClient(sql.OpenDB(connector), builders.WithWarnings(mySQLWarnings)),
+		},
+		syncerConfig: *syncerConfig,
+	}, nil
+}
+
+func (*MySQLCDC) Validate(url string) error {
+	_, _, err := parseMySQLCDCDSN(url)
+	return err
+}
+
+// parseMySQLCDCDSN parses the dsn into the mysql config (without the replica parameters)
+// and the binlog syncer config.
+func parseMySQLCDCDSN(url string) (*mysql.Config, *replication.BinlogSyncerConfig, error) {
+	cfg, err := mysql.ParseDSN(url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mysql.ParseDSN: %w", err)
+	}
+
+	// replica parameters must not be sent to the server as session variables
+	rawID, ok := cfg.Params["server_id"]
+	if !ok {
+		return nil, nil, fmt.Errorf("missing required parameter: %q", "server_id")
+	}
+	delete(cfg.Params, "server_id")
+
+	serverID, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil || serverID == 0 {
+		return nil, nil, fmt.Errorf("invalid value of parameter %q: %q", "server_id", rawID)
+	}
+
+	flavor := "mysql"
+	if f, ok := cfg.Params["flavor"]; ok {
+		flavor = f
+		delete(cfg.Params, "flavor")
+	}
+
+	// binlog syncer connects to the socket path if the port is 0
+	var host string
+	var port uint64
+	switch cfg.Net {
+	case "tcp":
+		var rawPort string
+		host, rawPort, err = net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("net.SplitHostPort: %w", err)
+		}
+		port, err = strconv.ParseUint(rawPort, 10, 16)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid port: %q", rawPort)
+		}
+	case "unix":
+		host = cfg.Addr
+	default:
+		return nil, nil, fmt.Errorf("binlog can only be tailed over tcp or unix sockets, got: %q", cfg.Net)
+	}
+
+	return cfg, &replication.BinlogSyncerConfig{
+		ServerID: uint32(serverID),
+		Flavor:   flavor,
+		Host:     host,
+		Port:     uint16(port),
+		User:     cfg.User,
+		Password: cfg.Passwd,
+		Logger:   discardLogger{},
+	}, nil
+}
+
+func (*MySQLCDC) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"Tail":   fmt.Sprintf("TAIL %s", opts.Table),
+		"Events": fmt.Sprintf("SHOW BINLOG EVENTS IN '%s' LIMIT 500", opts.Table),
+		"Status": "SHOW MASTER STATUS",
+	}
+}
@@ -0,0 +1,340 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver            = (*mySQLCDCDriver)(nil)
+	_ core.ContextStructurer = (*mySQLCDCDriver)(nil)
+	_ core.SafeScanner       = (*mySQLCDCDriver)(nil)
+)
+
+// mySQLCDCHeader is the header of tailed row changes.
+var mySQLCDCHeader = core.Header{"time", "position", "schema", "table", "action", "before", "after"}
+
+// mySQLCDCBinlogsSchema is the name of the node holding binlog files.
+const mySQLCDCBinlogsSchema = "binlogs"
+
+type mySQLCDCDriver struct {
+	sql          *mySQLDriver
+	syncerConfig replication.BinlogSyncerConfig
+}
+
+func (c *mySQLCDCDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	fields := strings.Fields(query)
+	if len(fields) < 1 || !strings.EqualFold(fields[0], "TAIL") {
+		return c.sql.Query(ctx, query)
+	}
+
+	pos, err := c.tailPosition(ctx, fields[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return c.tail(ctx, pos)
+}
+
+// tailPosition returns the position given by the arguments of the TAIL query:
+// none for the current position, a binlog file and an optional position in the file.
+func (c *mySQLCDCDriver) tailPosition(ctx context.Context, args []string) (gomysql.Position, error) {
+	switch len(args) {
+	case 0:
+		return c.currentPosition(ctx)
+	case 1:
+		// events start after the 4 byte magic number
+		return gomysql.Position{Name: args[0], Pos: 4}, nil
+	case 2:
+		pos, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return gomysql.Position{}, fmt.Errorf("invalid binlog position: %q", args[1])
+		}
+		return gomysql.Position{Name: args[0], Pos: uint32(pos)}, nil
+	default:
+		return gomysql.Position{}, errors.New("usage: TAIL [<binlog file> [<position>]]")
+	}
+}
+
+// currentPosition returns the position the server is currently writing to.
+func (c *mySQLCDCDriver) currentPosition(ctx context.Context) (gomysql.Position, error) {
+	// renamed in mysql 8.4
+	rows, err := c.sql.c.Query(ctx, "SHOW MASTER STATUS")
+	if err != nil {
+		rows, err = c.sql.c.Query(ctx, "SHOW BINARY LOG STATUS")
+		if err != nil {
+			return gomysql.Position{}, fmt.Errorf("failed getting binlog status: %w", err)
+		}
+	}
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return gomysql.Position{}, errors.New("binary logging is disabled")
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return gomysql.Position{}, fmt.Errorf("rows.Next: %w", err)
+	}
+	if len(row) < 2 {
+		return gomysql.Position{}, fmt.Errorf("unexpected binlog status: %v", row)
+	}
+
+	pos, err := strconv.ParseUint(fmt.Sprint(row[1]), 10, 32)
+	if err != nil {
+		return gomysql.Position{}, fmt.Errorf("invalid binlog position: %v", row[1])
+	}
+
+	return gomysql.Position{Name: fmt.Sprint(row[0]), Pos: uint32(pos)}, nil
+}
+
+// tail connects as a replica and streams row changes until ctx is canceled.
+func (c *mySQLCDCDriver) tail(ctx context.Context, pos gomysql.Position) (core.ResultStream, error) {
+	syncer := replication.NewBinlogSyncer(c.syncerConfig)
+
+	streamer, err := syncer.StartSync(pos)
+	if err != nil {
+		syncer.Close()
+		return nil, fmt.Errorf("syncer.StartSync: %w", err)
+	}
+
+	file := pos.Name
+	var pending []core.Row
+	var pendingErr error
+
+	// most events aren't row changes, so wait for the next one that is
+	hasNext := func() bool {
+		for len(pending) < 1 && pendingErr == nil {
+			ev, err := streamer.GetEvent(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return false
+				}
+				pendingErr = err
+				break
+			}
+
+			if rotate, ok := ev.Event.(*replication.RotateEvent); ok {
+				file = string(rotate.NextLogName)
+				continue
+			}
+			pending = mySQLCDCRows(ev, file)
+		}
+		return len(pending) > 0 || pendingErr != nil
+	}
+
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		if len(pending) < 1 {
+			return nil, pendingErr
+		}
+
+		row := pending[0]
+		pending = pending[1:]
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(mySQLCDCHeader).
+		WithCloseFunc(syncer.Close).
+		Build(), nil
+}
+
+// mySQLCDCRows converts a binlog event to rows of changes. Events that don't change
+// rows return nothing. Updates are logged as pairs of before and after images.
+func mySQLCDCRows(ev *replication.BinlogEvent, file string) []core.Row {
+	event, ok := ev.Event.(*replication.RowsEvent)
+	if !ok || event.Table == nil {
+		return nil
+	}
+
+	var action string
+	switch ev.Header.EventType {
+	case replication.WRITE_ROWS_EVENTv0, replication.WRITE_ROWS_EVENTv1, replication.WRITE_ROWS_EVENTv2,
+		replication.MARIADB_WRITE_ROWS_COMPRESSED_EVENT_V1:
+		action = "insert"
+	case replication.UPDATE_ROWS_EVENTv0, replication.UPDATE_ROWS_EVENTv1, replication.UPDATE_ROWS_EVENTv2,
+		replication.PARTIAL_UPDATE_ROWS_EVENT, replication.MARIADB_UPDATE_ROWS_COMPRESSED_EVENT_V1:
+		action = "update"
+	case replication.DELETE_ROWS_EVENTv0, replication.DELETE_ROWS_EVENTv1, replication.DELETE_ROWS_EVENTv2,
+		replication.MARIADB_DELETE_ROWS_COMPRESSED_EVENT_V1:
+		action = "delete"
+	default:
+		return nil
+	}
+
+	columns := event.Table.ColumnNameString()
+	received := time.Unix(int64(ev.Header.Timestamp), 0)
+	position := fmt.Sprintf("%s:%d", file, ev.Header.LogPos)
+
+	newRow := func(before, after []any) core.Row {
+		return core.Row{
+			received,
+			position,
+			string(event.Table.Schema),
+			string(event.Table.Table),
+			action,
+			mySQLCDCImage(columns, before),
+			mySQLCDCImage(columns, after),
+		}
+	}
+
+	var rows []core.Row
+	switch action {
+	case "insert":
+		for _, r := range event.Rows {
+			rows = append(rows, newRow(nil, r))
+		}
+	case "delete":
+		for _, r := range event.Rows {
+			rows = append(rows, newRow(r, nil))
+		}
+	case "update":
+		for i := 0; i+1 < len(event.Rows); i += 2 {
+			rows = append(rows, newRow(event.Rows[i], event.Rows[i+1]))
+		}
+	}
+
+	return rows
+}
+
+// mySQLCDCImage formats a row image as a json object, keeping the order of columns.
+// Columns without known names are numbered like in mysqlbinlog output (@1, @2, ...).
+func mySQLCDCImage(columns []string, values []any) any {
+	if values == nil {
+		return nil
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		name := "@" + strconv.Itoa(i+1)
+		if i < len(columns) && columns[i] != "" {
+			name = columns[i]
+		}
+		key, _ := json.Marshal(name)
+		b.Write(key)
+		b.WriteByte(':')
+
+		if bs, ok := v.([]byte); ok {
+			v = string(bs)
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(v))
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+func (c *mySQLCDCDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	// binlog files have no columns of their own, show the ones of tailed changes
+	if opts.Schema == mySQLCDCBinlogsSchema {
+		columns := make([]*core.Column, len(mySQLCDCHeader))
+		for i, name := range mySQLCDCHeader {
+			columns[i] = &core.Column{Name: name, Type: "text"}
+		}
+		columns[0].Type = "timestamp"
+		return columns, nil
+	}
+
+	return c.sql.Columns(opts)
+}
+
+func (c *mySQLCDCDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+// StructureCtx lists the binlog files.
+func (c *mySQLCDCDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	rows, err := c.sql.c.Query(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*core.Structure
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 1 {
+			continue
+		}
+
+		files = append(files, &core.Structure{
+			Name:   fmt.Sprint(row[0]),
+			Schema: mySQLCDCBinlogsSchema,
+			Type:   core.StructureTypeTable,
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return []*core.Structure{
+		{
+			Name:     mySQLCDCBinlogsSchema,
+			Schema:   mySQLCDCBinlogsSchema,
+			Type:     core.StructureTypeNone,
+			Children: files,
+		},
+	}, nil
+}
+
+func (c *mySQLCDCDriver) Close() {
+	c.sql.Close()
+}
+
+// SetSafeScan applies to regular queries, tailed row changes are always formatted the same way.
+func (c *mySQLCDCDriver) SetSafeScan(enabled bool) {
+	c.sql.SetSafeScan(enabled)
+}
+
+// discardLogger silences the replication logs, which are written to stdout
+// (used for rpc communication with neovim) by default.
+type discardLogger struct{}
+
+func (discardLogger) Fatal(...any)          {}
+func (discardLogger) Fatalf(string, ...any) {}
+func (discardLogger) Fatalln(...any)        {}
+func (discardLogger) Panic(...any)          {}
+func (discardLogger) Panicf(string, ...any) {}
+func (discardLogger) Panicln(...any)        {}
+func (discardLogger) Print(...any)          {}
+func (discardLogger) Printf(string, ...any) {}
+func (discardLogger) Println(...any)        {}
+func (discardLogger) Debug(...any)          {}
+func (discardLogger) Debugf(string, ...any) {}
+func (discardLogger) Debugln(...any)        {}
+func (discardLogger) Error(...any)          {}
+func (discardLogger) Errorf(string, ...any) {}
+func (discardLogger) Errorln(...any)        {}
+func (discardLogger) Info(...any)           {}
+func (discardLogger) Infof(string, ...any)  {}
+func (discardLogger) Infoln(...any)         {}
+func (discardLogger) Warn(...any)           {}
+func (discardLogger) Warnf(string, ...any)  {}
+func (discardLogger) Warnln(...any)         {}
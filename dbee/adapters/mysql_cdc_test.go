@@ -0,0 +1,108 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestMySQLCDC_Connect(t *testing.T) {
+	r := require.New(t)
+
+	_, err := (&MySQLCDC{}).Connect("user:pass@tcp(localhost:3306)/")
+	r.ErrorContains(err, "server_id")
+
+	_, err = (&MySQLCDC{}).Connect("user:pass@tcp(localhost:3306)/?server_id=0")
+	r.ErrorContains(err, "server_id")
+
+	driver, err := (&MySQLCDC{}).Connect("user:pass@tcp(localhost:3306)/?server_id=1001&flavor=mariadb")
+	r.NoError(err)
+	defer driver.Close()
+
+	cfg := driver.(*mySQLCDCDriver).syncerConfig
+	r.Equal(uint32(1001), cfg.ServerID)
+	r.Equal("mariadb", cfg.Flavor)
+	r.Equal("localhost", cfg.Host)
+	r.Equal(uint16(3306), cfg.Port)
+	r.Equal("user", cfg.User)
+	r.Equal("pass", cfg.Password)
+
+	driver, err = (&MySQLCDC{}).Connect("user:pass@unix(/tmp/my dir/mysql.sock)/?server_id=1002")
+	r.NoError(err)
+	defer driver.Close()
+
+	cfg = driver.(*mySQLCDCDriver).syncerConfig
+	r.Equal("/tmp/my dir/mysql.sock", cfg.Host)
+	r.Equal(uint16(0), cfg.Port)
+}
+
+func TestMySQLCDCDriver_TailPosition(t *testing.T) {
+	r := require.New(t)
+
+	c := &mySQLCDCDriver{}
+
+	pos, err := c.tailPosition(context.Background(), []string{"binlog.000002"})
+	r.NoError(err)
+	r.Equal(gomysql.Position{Name: "binlog.000002", Pos: 4}, pos)
+
+	pos, err = c.tailPosition(context.Background(), []string{"binlog.000002", "157"})
+	r.NoError(err)
+	r.Equal(gomysql.Position{Name: "binlog.000002", Pos: 157}, pos)
+
+	_, err = c.tailPosition(context.Background(), []string{"binlog.000002", "x"})
+	r.Error(err)
+
+	_, err = c.tailPosition(context.Background(), []string{"a", "b", "c"})
+	r.Error(err)
+}
+
+func TestMySQLCDCRows(t *testing.T) {
+	r := require.New(t)
+
+	table := &replication.TableMapEvent{
+		Schema:     []byte("shop"),
+		Table:      []byte("users"),
+		ColumnName: [][]byte{[]byte("id"), []byte("name")},
+	}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	event := func(typ replication.EventType, rows ...[]any) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{
+				Timestamp: uint32(ts.Unix()),
+				EventType: typ,
+				LogPos:    420,
+			},
+			Event: &replication.RowsEvent{Table: table, Rows: rows},
+		}
+	}
+
+	got := mySQLCDCRows(event(replication.UPDATE_ROWS_EVENTv2,
+		[]any{int32(1), []byte("alice")},
+		[]any{int32(1), nil},
+	), "binlog.000002")
+	r.Len(got, 1)
+	r.True(ts.Equal(got[0][0].(time.Time)))
+	r.Equal(core.Row{"binlog.000002:420", "shop", "users", "update", `{"id":1,"name":"alice"}`, `{"id":1,"name":null}`}, got[0][1:])
+
+	// unknown column names are numbered
+	got = mySQLCDCRows(event(replication.DELETE_ROWS_EVENTv2, []any{int32(1), "bob", 2.5}), "binlog.000002")
+	r.Len(got, 1)
+	r.Equal(core.Row{"delete", `{"id":1,"name":"bob","@3":2.5}`, nil}, got[0][4:])
+
+	got = mySQLCDCRows(event(replication.WRITE_ROWS_EVENTv2, []any{int32(1), "a"}, []any{int32(2), "b"}), "binlog.000002")
+	r.Len(got, 2)
+	r.Equal(core.Row{"insert", nil, `{"id":2,"name":"b"}`}, got[1][4:])
+
+	// other events are skipped
+	r.Nil(mySQLCDCRows(&replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+		Event:  &replication.QueryEvent{},
+	}, "binlog.000002"))
+}
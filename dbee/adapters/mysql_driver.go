@@ -2,34 +2,182 @@ package adapters
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*mySQLDriver)(nil)
+var (
+	_ core.Driver            = (*mySQLDriver)(nil)
+	_ core.ContextStructurer = (*mySQLDriver)(nil)
+	_ core.DDLProvider       = (*mySQLDriver)(nil)
+	_ core.DatabaseSwitcher  = (*mySQLDriver)(nil)
+	_ core.Describer         = (*mySQLDriver)(nil)
+	_ core.ForeignKeyLister  = (*mySQLDriver)(nil)
+	_ core.GrantLister       = (*mySQLDriver)(nil)
+	_ core.IdleCloser        = (*mySQLDriver)(nil)
+	_ core.Limiter           = (*mySQLDriver)(nil)
+	_ core.ParamQuerier      = (*mySQLDriver)(nil)
+	_ core.Peeker            = (*mySQLDriver)(nil)
+	_ core.PlanExplainer     = (*mySQLDriver)(nil)
+	_ core.ProcedureCaller   = (*mySQLDriver)(nil)
+	_ core.ReplicaRouter     = (*mySQLDriver)(nil)
+	_ core.SafeScanner       = (*mySQLDriver)(nil)
+	_ core.SequenceLister    = (*mySQLDriver)(nil)
+	_ core.StatementLogger   = (*mySQLDriver)(nil)
+	_ core.TableSizer        = (*mySQLDriver)(nil)
+	_ core.TopValuer         = (*mySQLDriver)(nil)
+	_ core.UniqueKeyLister   = (*mySQLDriver)(nil)
+)
+
+// mySQLSequencesQuery lists auto increment counters as sequences
+// (see core.SequenceFromRow).
+const mySQLSequencesQuery = `
+		SELECT t.TABLE_SCHEMA AS table_schema, t.TABLE_NAME AS sequence_name, t.TABLE_NAME AS table_name,
+			c.COLUMN_NAME AS column_name, NULLIF(LEAST(t.AUTO_INCREMENT - 1, 9223372036854775807), 0) AS current_value,
+			@@auto_increment_increment AS increment_by, 1 AS min_value,
+			CASE c.DATA_TYPE
+				WHEN 'tinyint' THEN IF(c.COLUMN_TYPE LIKE '%unsigned%', 255, 127)
+				WHEN 'smallint' THEN IF(c.COLUMN_TYPE LIKE '%unsigned%', 65535, 32767)
+				WHEN 'mediumint' THEN IF(c.COLUMN_TYPE LIKE '%unsigned%', 16777215, 8388607)
+				WHEN 'int' THEN IF(c.COLUMN_TYPE LIKE '%unsigned%', 4294967295, 2147483647)
+				ELSE 9223372036854775807
+			END AS max_value
+		FROM information_schema.TABLES t
+		JOIN information_schema.COLUMNS c ON c.TABLE_SCHEMA = t.TABLE_SCHEMA
+			AND c.TABLE_NAME = t.TABLE_NAME AND c.EXTRA LIKE '%auto_increment%'
+		WHERE t.AUTO_INCREMENT IS NOT NULL
+			AND t.TABLE_SCHEMA NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')`
 
 type mySQLDriver struct {
-	c *builders.Client
+	c   *builders.Client
+	cfg *mysql.Config
 }
 
 func (c *mySQLDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
-	// run query, fallback to affected rows
-	return c.c.QueryUntilNotEmpty(ctx, query, "select ROW_COUNT() as 'Rows Affected'")
+	// run query, fallback to affected rows and number of warnings
+	// (the warnings themselves are attached to the result, see mySQLWarnings)
+	return c.c.QueryUntilNotEmpty(ctx, query, "select ROW_COUNT() as 'Rows Affected', @@warning_count as 'Warnings'")
+}
+
+// mySQLWarnings lists warnings of the last statement if the server reports any
+// (see builders.WithWarnings). Warnings are kept until the next statement that
+// uses tables, so the fallback query of Query doesn't clear them.
+func mySQLWarnings(query func(statement string) (*sql.Rows, error)) ([]string, error) {
+	rows, err := query("SELECT @@warning_count")
+	if err != nil {
+		return nil, err
+	}
+	var count int
+	if rows.Next() {
+		err = rows.Scan(&count)
+	}
+	_ = rows.Close()
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	rows, err = query("SHOW WARNINGS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warnings []string
+	for rows.Next() {
+		var level, message string
+		var code int
+		if err := rows.Scan(&level, &code, &message); err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %d: %s", level, code, message))
+	}
+
+	return warnings, rows.Err()
 }
 
 func (c *mySQLDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
-	return c.c.ColumnsFromQuery("DESCRIBE `%s`", opts.Table)
+	opts = mySQLPartitionParent(opts)
+	return c.c.ColumnsFromQuery(`
+		SELECT
+			column_name,
+			column_type,
+			character_maximum_length,
+			numeric_precision,
+			numeric_scale,
+			-- "DEFAULT_GENERATED" only marks expression defaults
+			extra LIKE '%%VIRTUAL GENERATED%%' OR extra LIKE '%%STORED GENERATED%%',
+			NULLIF(generation_expression, '')
+		FROM information_schema.columns
+		WHERE
+			table_schema='%s' AND
+			table_name='%s'
+		ORDER BY ordinal_position
+		`, opts.Schema, opts.Table)
 }
 
 func (c *mySQLDriver) Structure() ([]*core.Structure, error) {
-	query := `SELECT table_schema, table_name FROM information_schema.tables`
+	return c.StructureCtx(context.Background())
+}
 
-	rows, err := c.Query(context.TODO(), query)
+func (c *mySQLDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	query := `
+		SELECT table_schema, table_name, table_type FROM information_schema.tables UNION ALL
+		SELECT routine_schema, routine_name, routine_type FROM information_schema.routines`
+
+	rows, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	structure, err := getMySQLStructure(ctx, rows)
 	if err != nil {
 		return nil, err
 	}
 
+	// compatible servers without partitioning (e.g. dolt) keep the flat structure
+	rows, err = c.Query(ctx, mySQLPartitionsQuery)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return structure, nil
+	}
+
+	return builders.NestPartitions(structure, rows)
+}
+
+// mySQLPartitionsQuery lists partitions with their tables. Sub-partitions are not listed,
+// as they can't be selected from on their own.
+const mySQLPartitionsQuery = `
+		SELECT table_schema, table_name, table_schema, partition_name
+		FROM information_schema.partitions
+		WHERE partition_name IS NOT NULL AND (subpartition_ordinal_position IS NULL OR subpartition_ordinal_position = 1)
+		ORDER BY table_schema, table_name, partition_ordinal_position`
+
+// mySQLPartitionParent returns options of the parent table if opts are of a partition,
+// because partitions aren't tables in mysql (they are selected with "table PARTITION (name)").
+func mySQLPartitionParent(opts *core.TableOptions) *core.TableOptions {
+	if opts.Materialization != core.StructureTypePartition || opts.Parent == "" {
+		return opts
+	}
+	return &core.TableOptions{
+		Table:           opts.Parent,
+		Schema:          opts.Schema,
+		Materialization: core.StructureTypeTable,
+	}
+}
+
+// getMySQLStructure groups the (schema, name, type) rows by schema.
+// It fails with ctx.Err() if ctx is cancelled while iterating.
+func getMySQLStructure(ctx context.Context, rows core.ResultStream) ([]*core.Structure, error) {
 	children := make(map[string][]*core.Structure)
 
 	for rows.HasNext() {
@@ -38,17 +186,20 @@ func (c *mySQLDriver) Structure() ([]*core.Structure, error) {
 			return nil, err
 		}
 
-		// We know for a fact there are 2 string fields (see query above)
+		// We know for a fact there are 3 string fields (see query above)
 		schema := row[0].(string)
 		table := row[1].(string)
 
 		children[schema] = append(children[schema], &core.Structure{
 			Name:   table,
 			Schema: schema,
-			Type:   core.StructureTypeTable,
+			Type:   getMySQLStructureType(row[2].(string)),
 		})
 
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	var structure []*core.Structure
 
@@ -64,6 +215,290 @@ func (c *mySQLDriver) Structure() ([]*core.Structure, error) {
 	return structure, nil
 }
 
+// getMySQLStructureType returns the structure type of the table_type (or routine_type).
+// Other types (e.g. "SEQUENCE" of mariadb) are shown as tables, as they can be selected from.
+func getMySQLStructureType(typ string) core.StructureType {
+	switch typ {
+	case "VIEW", "SYSTEM VIEW":
+		return core.StructureTypeView
+	case "PROCEDURE":
+		return core.StructureTypeProcedure
+	case "FUNCTION":
+		return core.StructureTypeFunction
+	default:
+		// "BASE TABLE", "SYSTEM VERSIONED", "TEMPORARY"
+		return core.StructureTypeTable
+	}
+}
+
+// ListDatabases lists databases (schemas in mysql terms) the user can see.
+func (c *mySQLDriver) ListDatabases() (current string, available []string, err error) {
+	query := `
+		SELECT COALESCE(DATABASE(), ''), schema_name FROM information_schema.schemata
+		WHERE schema_name <> COALESCE(DATABASE(), '')
+		ORDER BY schema_name`
+
+	rows, err := c.Query(context.TODO(), query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return "", nil, err
+		}
+
+		// We know for a fact there are 2 string fields (see query above)
+		current = row[0].(string)
+		available = append(available, row[1].(string))
+	}
+
+	return current, available, nil
+}
+
+// SelectDatabase reconnects with the database as the default one. A USE statement
+// would only apply to a single connection of the pool.
+func (c *mySQLDriver) SelectDatabase(name string) error {
+	cfg := c.cfg.Clone()
+	cfg.DBName = name
+
+	db, err := openMySQLConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("unable to switch databases: %w", err)
+	}
+
+	c.cfg = cfg
+	c.c.Swap(db)
+
+	return nil
+}
+
 func (c *mySQLDriver) Close() {
 	c.c.Close()
 }
+
+func (c *mySQLDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *mySQLDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *mySQLDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderQuestion
+}
+
+func (c *mySQLDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	if parent := mySQLPartitionParent(opts); parent != opts {
+		col := builders.QuoteIdentifier(column, builders.QuoteBacktick)
+		return fmt.Sprintf("SELECT %s, COUNT(*) AS cnt FROM %s GROUP BY %s ORDER BY cnt DESC LIMIT %d", col, mySQLPartition(opts), col, n)
+	}
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteBacktick, core.LimitDialectLimit)
+}
+
+func (c *mySQLDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return c.c.Peek(ctx, query)
+}
+
+func (c *mySQLDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *mySQLDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+// AddReplica routes read-only statements to the database of the dsn as well.
+func (c *mySQLDriver) AddReplica(dsn string) error {
+	db, cfg, err := openMySQL(dsn)
+	if err != nil {
+		return err
+	}
+
+	c.c.AddReplica(cfg.Addr, db)
+	return nil
+}
+
+func (c *mySQLDriver) SetIdleTimeout(timeout time.Duration) {
+	c.c.SetIdleTimeout(timeout)
+}
+
+func (c *mySQLDriver) Describe(ctx context.Context, opts *core.TableOptions) (core.ResultStream, error) {
+	opts = mySQLPartitionParent(opts)
+
+	var query string
+
+	switch opts.Materialization {
+	case core.StructureTypeTable:
+		query = fmt.Sprintf("DESCRIBE `%s`.`%s`", opts.Schema, opts.Table)
+	case core.StructureTypeView:
+		query = fmt.Sprintf("SHOW CREATE VIEW `%s`.`%s`", opts.Schema, opts.Table)
+	case core.StructureTypeProcedure:
+		query = fmt.Sprintf("SHOW CREATE PROCEDURE `%s`.`%s`", opts.Schema, opts.Table)
+	case core.StructureTypeFunction:
+		query = fmt.Sprintf("SHOW CREATE FUNCTION `%s`.`%s`", opts.Schema, opts.Table)
+	default:
+		return nil, fmt.Errorf("cannot describe object of type %q", opts.Materialization)
+	}
+
+	return c.c.Query(ctx, query)
+}
+
+func (c *mySQLDriver) DDL(opts *core.TableOptions) (string, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf("SHOW CREATE TABLE `%s`.`%s`", opts.Schema, opts.Table))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return "", fmt.Errorf("table %q.%q not found", opts.Schema, opts.Table)
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return "", err
+	}
+
+	// second column holds the create statement (for both tables and views)
+	if len(row) < 2 {
+		return "", errors.New("could not retrieve ddl: insufficient info")
+	}
+
+	return fmt.Sprint(row[1]), nil
+}
+
+// TableSize reports allocated but unused space (DATA_FREE) as overhead.
+// Sizes of InnoDB tables are estimates, which are refreshed by ANALYZE TABLE.
+// Sequences lists auto increment counters of tables (AUTO_INCREMENT is the next value).
+// The maximum is derived from the column type, unsigned bigint columns are capped to the
+// signed range. Note that mysql 8 caches the counters (see information_schema_stats_expiry).
+func (c *mySQLDriver) Sequences() ([]*core.Sequence, error) {
+	rows, err := c.c.Query(context.TODO(), mySQLSequencesQuery+" ORDER BY 1, 2")
+	if err != nil {
+		return nil, err
+	}
+
+	return sequencesFromResultStream(rows)
+}
+
+// mySQLGrantsQuery lists privileges on the table, including those granted on its schema
+// and globally (only privileges which apply to tables). If grantee is set, only its
+// privileges are listed. Note that information_schema shows only the current user's
+// privileges unless the user can read the mysql schema.
+func mySQLGrantsQuery(opts *core.TableOptions, grantee string) string {
+	privileges := `privilege_type IN ('SELECT', 'INSERT', 'UPDATE', 'DELETE', 'CREATE', 'DROP',
+		'REFERENCES', 'INDEX', 'ALTER', 'CREATE VIEW', 'SHOW VIEW', 'TRIGGER')`
+	filter := ""
+	if grantee != "" {
+		filter = " AND grantee = " + grantee
+	}
+
+	return fmt.Sprintf(`%s
+UNION ALL
+SELECT grantee, privilege_type, is_grantable, 'SCHEMA'
+FROM information_schema.schema_privileges
+WHERE table_schema = '%s' AND %s%s
+UNION ALL
+SELECT grantee, privilege_type, is_grantable, 'GLOBAL'
+FROM information_schema.user_privileges
+WHERE %s%s
+ORDER BY 1, 4, 2`, builders.GrantsQuery(opts, grantee), opts.Schema, privileges, filter, privileges, filter)
+}
+
+// mySQLCurrentGrantee is the current user in the form of grantees in information_schema ('user'@'host').
+const mySQLCurrentGrantee = `CONCAT('''', SUBSTRING_INDEX(CURRENT_USER(), '@', 1), '''@''', SUBSTRING_INDEX(CURRENT_USER(), '@', -1), '''')`
+
+func (c *mySQLDriver) Grants(opts *core.TableOptions) ([]*core.Grant, error) {
+	return c.c.GrantsFromQuery(mySQLGrantsQuery(opts, ""))
+}
+
+// EffectiveGrants lists privileges granted directly to the current user
+// (privileges of roles granted to the user are not expanded).
+func (c *mySQLDriver) EffectiveGrants(opts *core.TableOptions) ([]*core.Grant, error) {
+	return c.c.GrantsFromQuery(mySQLGrantsQuery(opts, mySQLCurrentGrantee))
+}
+
+// UniqueKeys lists columns of unique indexes (including the primary key) of the table.
+// Unqualified tables are looked up in the current database.
+func (c *mySQLDriver) UniqueKeys(opts *core.TableOptions) ([][]string, error) {
+	schema := "DATABASE()"
+	if opts.Schema != "" {
+		schema = fmt.Sprintf("'%s'", opts.Schema)
+	}
+
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT INDEX_NAME, COLUMN_NAME
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = %s AND TABLE_NAME = '%s' AND NON_UNIQUE = 0 AND COLUMN_NAME IS NOT NULL
+		ORDER BY INDEX_NAME <> 'PRIMARY', INDEX_NAME, SEQ_IN_INDEX`,
+		schema, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return uniqueKeysFromResultStream(rows)
+}
+
+func (c *mySQLDriver) TableSize(opts *core.TableOptions) (*core.TableSize, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT DATA_LENGTH + INDEX_LENGTH, DATA_LENGTH, INDEX_LENGTH, DATA_FREE
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s'`,
+		opts.Schema, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return tableSizeFromResultStream(rows)
+}
+
+func (c *mySQLDriver) ForeignKeys(opts *core.TableOptions) ([]*core.ForeignKey, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s' AND REFERENCED_TABLE_NAME IS NOT NULL`,
+		opts.Schema, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return foreignKeysFromResultStream(rows)
+}
+
+func (c *mySQLDriver) ListProcedures() ([]*core.Structure, error) {
+	rows, err := c.Query(context.TODO(), `
+		SELECT routine_schema, routine_name, routine_type FROM information_schema.routines
+		WHERE routine_schema NOT IN ('sys', 'mysql', 'information_schema', 'performance_schema')`)
+	if err != nil {
+		return nil, err
+	}
+
+	return getMySQLStructure(context.Background(), rows)
+}
+
+func (c *mySQLDriver) ProcedureParameters(opts *core.TableOptions) ([]*core.Column, error) {
+	// OUT and INOUT parameters of procedures are passed as NULL,
+	// function return value is stored with ordinal position 0
+	return c.c.ColumnsFromQuery(`
+		SELECT parameter_name, data_type FROM information_schema.parameters
+		WHERE specific_schema = '%s' AND specific_name = '%s' AND ordinal_position > 0
+		ORDER BY ordinal_position`, opts.Schema, opts.Table)
+}
+
+func (c *mySQLDriver) CallProcedure(ctx context.Context, opts *core.TableOptions, args []any) (core.ResultStream, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+
+	var query string
+	switch opts.Materialization {
+	case core.StructureTypeProcedure:
+		query = fmt.Sprintf("CALL `%s`.`%s`(%s)", opts.Schema, opts.Table, placeholders)
+	case core.StructureTypeFunction:
+		query = fmt.Sprintf("SELECT `%s`.`%s`(%s) AS result", opts.Schema, opts.Table, placeholders)
+	default:
+		return nil, fmt.Errorf("cannot call object of type %q", opts.Materialization)
+	}
+
+	return c.c.QueryArgs(ctx, query, args...)
+}
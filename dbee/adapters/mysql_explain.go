@@ -0,0 +1,149 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func (c *mySQLDriver) ExplainPlan(ctx context.Context, query string, analyze bool) (*core.PlanNode, error) {
+	// EXPLAIN ANALYZE only supports the tree format
+	if analyze {
+		return nil, errors.New("analyzed plan is not supported in json format")
+	}
+
+	rows, err := c.c.Query(ctx, "EXPLAIN FORMAT=JSON "+query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return nil, errors.New("no query plan returned")
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return nil, err
+	}
+	if len(row) < 1 {
+		return nil, errors.New("no query plan returned")
+	}
+
+	var plan []byte
+	switch v := row[0].(type) {
+	case []byte:
+		plan = v
+	default:
+		plan = []byte(fmt.Sprint(v))
+	}
+
+	return parseMySQLPlan(plan)
+}
+
+// parseMySQLPlan parses the output of "EXPLAIN FORMAT=JSON". Every operation
+// (e.g. "ordering_operation", "nested_loop", "table") becomes a node of the tree.
+func parseMySQLPlan(plan []byte) (*core.PlanNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(plan))
+	dec.UseNumber()
+
+	var parsed map[string]any
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("json.Decode: %w", err)
+	}
+
+	block, ok := parsed["query_block"].(map[string]any)
+	if !ok {
+		return nil, errors.New("empty query plan")
+	}
+
+	return mysqlPlanNode("query_block", block), nil
+}
+
+func mysqlPlanNode(name string, obj map[string]any) *core.PlanNode {
+	node := &core.PlanNode{
+		Type: name,
+	}
+
+	if name == "table" {
+		node.Relation, _ = obj["table_name"].(string)
+		node.Index, _ = obj["key"].(string)
+		if access, ok := obj["access_type"].(string); ok {
+			node.Type = fmt.Sprintf("table (%s)", access)
+		}
+	}
+
+	if costs, ok := obj["cost_info"].(map[string]any); ok {
+		for _, key := range []string{"query_cost", "prefix_cost", "sort_cost"} {
+			if cost, ok := mysqlPlanNumber(costs[key]); ok {
+				node.Cost = cost
+				break
+			}
+		}
+	}
+
+	for _, key := range []string{"rows_produced_per_join", "rows_examined_per_scan"} {
+		if rows, ok := mysqlPlanNumber(obj[key]); ok {
+			node.Rows = int64(rows)
+			break
+		}
+	}
+
+	node.Children = mysqlPlanChildren(obj)
+
+	return node
+}
+
+// mysqlPlanChildren returns nodes of nested operations of the object.
+// Keys are sorted, since the order of the json object isn't preserved.
+func mysqlPlanChildren(obj map[string]any) []*core.PlanNode {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var children []*core.PlanNode
+	for _, key := range keys {
+		switch val := obj[key].(type) {
+		case map[string]any:
+			if key == "cost_info" {
+				continue
+			}
+			children = append(children, mysqlPlanNode(key, val))
+
+		case []any:
+			// arrays of operations (e.g. nested_loop) hold wrapper objects
+			// like {"table": {...}}, which are flattened
+			node := &core.PlanNode{Type: key}
+			for _, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					node.Children = append(node.Children, mysqlPlanChildren(m)...)
+				}
+			}
+			if len(node.Children) > 0 {
+				children = append(children, node)
+			}
+		}
+	}
+
+	return children
+}
+
+// mysqlPlanNumber parses numbers, which are sometimes represented as strings.
+func mysqlPlanNumber(val any) (float64, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,111 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// plan of "EXPLAIN FORMAT=JSON" for a join with grouping and ordering
+const mysqlPlanFixture = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {
+      "query_cost": "1285.50"
+    },
+    "ordering_operation": {
+      "using_filesort": true,
+      "grouping_operation": {
+        "using_temporary_table": true,
+        "using_filesort": false,
+        "nested_loop": [
+          {
+            "table": {
+              "table_name": "c",
+              "access_type": "ALL",
+              "possible_keys": ["PRIMARY"],
+              "rows_examined_per_scan": 800,
+              "rows_produced_per_join": 800,
+              "filtered": "100.00",
+              "cost_info": {
+                "read_cost": "1.00",
+                "eval_cost": "80.00",
+                "prefix_cost": "81.00",
+                "data_read_per_join": "25K"
+              },
+              "used_columns": ["id", "name"]
+            }
+          },
+          {
+            "table": {
+              "table_name": "o",
+              "access_type": "ref",
+              "possible_keys": ["idx_customer"],
+              "key": "idx_customer",
+              "used_key_parts": ["customer_id"],
+              "key_length": "5",
+              "ref": ["shop.c.id"],
+              "rows_examined_per_scan": 12,
+              "rows_produced_per_join": 9950,
+              "filtered": "100.00",
+              "cost_info": {
+                "read_cost": "209.50",
+                "eval_cost": "995.00",
+                "prefix_cost": "1285.50",
+                "data_read_per_join": "310K"
+              },
+              "used_columns": ["id", "customer_id", "total"]
+            }
+          }
+        ]
+      }
+    }
+  }
+}`
+
+func TestParseMySQLPlan(t *testing.T) {
+	r := require.New(t)
+
+	root, err := parseMySQLPlan([]byte(mysqlPlanFixture))
+	r.NoError(err)
+
+	r.Equal(&core.PlanNode{
+		Type: "query_block",
+		Cost: 1285.5,
+		Children: []*core.PlanNode{
+			{
+				Type: "ordering_operation",
+				Children: []*core.PlanNode{
+					{
+						Type: "grouping_operation",
+						Children: []*core.PlanNode{
+							{
+								Type: "nested_loop",
+								Children: []*core.PlanNode{
+									{
+										Type:     "table (ALL)",
+										Relation: "c",
+										Cost:     81,
+										Rows:     800,
+									},
+									{
+										Type:     "table (ref)",
+										Relation: "o",
+										Index:    "idx_customer",
+										Cost:     1285.5,
+										Rows:     9950,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, root)
+
+	_, err = parseMySQLPlan([]byte(`{}`))
+	r.Error(err)
+}
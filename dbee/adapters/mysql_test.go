@@ -0,0 +1,189 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestMySQL_ConnectSocket(t *testing.T) {
+	r := require.New(t)
+
+	// socket path with characters that have a meaning in dsn
+	dir := filepath.Join(t.TempDir(), "my (1)")
+	r.NoError(os.Mkdir(dir, 0o700))
+	path := filepath.Join(dir, "mysql?.sock")
+
+	accepted := listenUnixSocket(t, path)
+
+	d, err := (&MySQL{}).Connect("user:pass@unix(" + path + ")/db?parseTime=true&loc=Europe%2FBerlin")
+	r.NoError(err)
+
+	// the server closes the connection right away, so the query fails after connecting
+	_, err = d.Query(context.Background(), "SELECT 1")
+	r.Error(err)
+	r.True(<-accepted)
+
+	d.Close()
+
+	_, err = (&MySQL{}).Connect("user:pass@unix(/tmp/mysql.sock")
+	r.Error(err)
+}
+
+func TestMySQLHelpers_Partition(t *testing.T) {
+	r := require.New(t)
+
+	helpers := (&MySQL{}).GetHelpers(&core.TableOptions{
+		Schema:          "shop",
+		Table:           "p2024",
+		Parent:          "orders",
+		Materialization: core.StructureTypePartition,
+	})
+	r.Equal("SELECT * FROM `shop`.`orders` PARTITION (`p2024`) LIMIT 500", helpers["List"])
+	r.Contains(helpers["Partition"], "TABLE_SCHEMA = 'shop' AND TABLE_NAME = 'orders' AND PARTITION_NAME IS NOT NULL AND PARTITION_NAME = 'p2024'")
+
+	helpers = (&MySQL{}).GetHelpers(&core.TableOptions{Schema: "shop", Table: "orders", Materialization: core.StructureTypeTable})
+	r.Equal("SELECT * FROM `orders` LIMIT 500", helpers["List"])
+	r.Contains(helpers["Partitions"], "TABLE_NAME = 'orders'")
+
+	d := &mySQLDriver{}
+	r.Equal("SELECT `kind`, COUNT(*) AS cnt FROM `shop`.`orders` PARTITION (`p2024`) GROUP BY `kind` ORDER BY cnt DESC LIMIT 5",
+		d.TopValues(&core.TableOptions{Schema: "shop", Table: "p2024", Parent: "orders", Materialization: core.StructureTypePartition}, "kind", 5))
+}
+
+func TestMySQLDriver_SelectDatabase(t *testing.T) {
+	r := require.New(t)
+
+	d, err := (&MySQL{}).Connect("user:pass@tcp(localhost:3306)/db?parseTime=true")
+	r.NoError(err)
+	defer d.Close()
+
+	driver := d.(*mySQLDriver)
+
+	r.NoError(driver.SelectDatabase("shop"))
+	r.Equal("shop", driver.cfg.DBName)
+	r.Equal("localhost:3306", driver.cfg.Addr)
+	r.True(driver.cfg.ParseTime)
+}
+
+func TestMySQL_OpenMultiStatements(t *testing.T) {
+	r := require.New(t)
+
+	// multiple statements are always enabled, so whole files can be run
+	for _, dsn := range []string{
+		"user:pass@tcp(localhost:3306)/db",
+		"user:pass@tcp(localhost:3306)/db?multiStatements=false",
+	} {
+		db, cfg, err := openMySQL(dsn)
+		r.NoError(err)
+		r.True(cfg.MultiStatements, dsn)
+		r.NoError(db.Close())
+	}
+
+	_, _, err := openMySQL("user:pass@tcp(localhost:3306/db")
+	r.Error(err)
+}
+
+func TestGetMySQLStructureType(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(core.StructureTypeTable, getMySQLStructureType("BASE TABLE"))
+	r.Equal(core.StructureTypeView, getMySQLStructureType("SYSTEM VIEW"))
+	r.Equal(core.StructureTypeProcedure, getMySQLStructureType("PROCEDURE"))
+	r.Equal(core.StructureTypeTable, getMySQLStructureType("SEQUENCE"))
+}
+
+func TestMySQLDriver_Warnings(t *testing.T) {
+	r := require.New(t)
+
+	connector := &sqlTestConnector{respond: func(query string) driver.Rows {
+		switch query {
+		case "select ROW_COUNT() as 'Rows Affected', @@warning_count as 'Warnings'":
+			return &sqlTestRows{columns: []string{"Rows Affected", "Warnings"}, rows: [][]driver.Value{{int64(1), int64(1)}}}
+		case "SELECT @@warning_count":
+			return &sqlTestRows{columns: []string{"@@warning_count"}, rows: [][]driver.Value{{int64(1)}}}
+		case "SHOW WARNINGS":
+			return &sqlTestRows{
+				columns: []string{"Level", "Code", "Message"},
+				rows:    [][]driver.Value{{"Warning", int64(1265), "Data truncated for column 'a' at row 1"}},
+			}
+		}
+		// statements without a result
+		return &sqlTestRows{}
+	}}
+	d := &mySQLDriver{c: builders.NewClient(sql.OpenDB(connector), builders.WithWarnings(mySQLWarnings))}
+
+	result, err := d.Query(context.Background(), "UPDATE t SET a = 'too long'")
+	r.NoError(err)
+	for result.HasNext() {
+		_, err := result.Next()
+		r.NoError(err)
+	}
+	result.Close()
+
+	r.Equal([]string{"Warning 1265: Data truncated for column 'a' at row 1"}, result.Meta().Warnings)
+	r.Equal([]string{
+		"UPDATE t SET a = 'too long'",
+		"select ROW_COUNT() as 'Rows Affected', @@warning_count as 'Warnings'",
+		"SELECT @@warning_count",
+		"SHOW WARNINGS",
+	}, connector.queries)
+
+	// no warnings are listed if the server doesn't report any
+	connector.respond = func(query string) driver.Rows {
+		return &sqlTestRows{columns: []string{"a"}, rows: [][]driver.Value{{int64(0)}}}
+	}
+	connector.queries = nil
+
+	result, err = d.Query(context.Background(), "SELECT a FROM t")
+	r.NoError(err)
+	result.Close()
+
+	r.Empty(result.Meta().Warnings)
+	r.Equal([]string{"SELECT a FROM t", "SELECT @@warning_count"}, connector.queries)
+}
+
+func TestMySQLDriver_Booleans(t *testing.T) {
+	r := require.New(t)
+
+	// BOOL columns are reported as TINYINT(1), values are sent as text
+	connector := &sqlTestConnector{respond: func(string) driver.Rows {
+		return &sqlTestRows{
+			columns: []string{"id", "active", "level"},
+			types:   []string{"BIGINT", "TINYINT", "TINYINT"},
+			lengths: []int64{20, 1, 4},
+			rows: [][]driver.Value{
+				{[]byte("1"), []byte("1"), []byte("5")},
+				{[]byte("2"), []byte("0"), []byte("0")},
+				{[]byte("3"), nil, nil},
+			},
+		}
+	}}
+	d := &mySQLDriver{c: builders.NewClient(sql.OpenDB(connector))}
+
+	rows, err := d.Query(context.Background(), "SELECT id, active, level FROM flags ORDER BY id")
+	r.NoError(err)
+	defer rows.Close()
+
+	var got []core.Row
+	for rows.HasNext() {
+		row, err := rows.Next()
+		r.NoError(err)
+		got = append(got, row)
+	}
+
+	// only TINYINT(1) is a boolean, wider TINYINT columns are numbers
+	r.Equal([]core.Row{
+		{"1", true, "5"},
+		{"2", false, "0"},
+		{"3", nil, nil},
+	}, got)
+}
@@ -0,0 +1,190 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// OAuth2 parameters of connection urls of http based adapters.
+// Dashes are used, so that the names don't collide with database settings.
+const (
+	oauthParamTokenURL     = "oauth-token-url"
+	oauthParamClientID     = "oauth-client-id"
+	oauthParamClientSecret = "oauth-client-secret"
+	oauthParamRefreshToken = "oauth-refresh-token"
+	oauthParamScopes       = "oauth-scopes"
+)
+
+// oauthTokenSource provides OAuth2 access tokens using either the refresh-token flow
+// (if a refresh token is configured) or the client-credentials flow.
+// Tokens are cached until they expire or are invalidated.
+type oauthTokenSource struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+
+	config       *oauth2.Config
+	refreshToken string
+	credentials  *clientcredentials.Config
+}
+
+var _ oauth2.TokenSource = (*oauthTokenSource)(nil)
+
+// newOAuthTokenSource creates a token source from the oauth parameters of the url
+// and removes them from params, so they aren't passed to the database.
+// If no token url is configured, nil is returned.
+func newOAuthTokenSource(params url.Values) (*oauthTokenSource, error) {
+	tokenURL := params.Get(oauthParamTokenURL)
+	clientID := params.Get(oauthParamClientID)
+	clientSecret := params.Get(oauthParamClientSecret)
+	refreshToken := params.Get(oauthParamRefreshToken)
+	scopes := strings.FieldsFunc(params.Get(oauthParamScopes), func(r rune) bool { return r == ',' || r == ' ' })
+
+	for _, p := range []string{oauthParamTokenURL, oauthParamClientID, oauthParamClientSecret, oauthParamRefreshToken, oauthParamScopes} {
+		params.Del(p)
+	}
+
+	if tokenURL == "" {
+		if clientID != "" || refreshToken != "" {
+			return nil, fmt.Errorf("%q is required for oauth authentication", oauthParamTokenURL)
+		}
+		return nil, nil
+	}
+
+	if refreshToken != "" {
+		return &oauthTokenSource{
+			config: &oauth2.Config{
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+				Scopes:       scopes,
+			},
+			refreshToken: refreshToken,
+		}, nil
+	}
+
+	if clientID == "" {
+		return nil, fmt.Errorf("either %q or %q is required for oauth authentication", oauthParamClientID, oauthParamRefreshToken)
+	}
+
+	return &oauthTokenSource{
+		credentials: &clientcredentials.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			TokenURL:     tokenURL,
+			Scopes:       scopes,
+		},
+	}, nil
+}
+
+// Token returns the cached token or fetches a new one if it's expired.
+func (s *oauthTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	token, err := s.fetch(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed obtaining oauth token: %w", err)
+	}
+	s.token = token
+
+	return token, nil
+}
+
+func (s *oauthTokenSource) fetch(ctx context.Context) (*oauth2.Token, error) {
+	if s.credentials != nil {
+		return s.credentials.Token(ctx)
+	}
+
+	token, err := s.config.TokenSource(ctx, &oauth2.Token{RefreshToken: s.refreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+	// some servers rotate refresh tokens
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	return token, nil
+}
+
+// invalidate drops the token if it's still the cached one (e.g. if it was rejected
+// by the server before its expiry), so that the next call to Token fetches a new one.
+func (s *oauthTokenSource) invalidate(token *oauth2.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+// client returns an http client which authenticates requests with the token.
+func (s *oauthTokenSource) client() *http.Client {
+	return &http.Client{
+		Transport: &oauthTransport{
+			source: s,
+			base:   http.DefaultTransport,
+		},
+	}
+}
+
+// oauthTransport adds the bearer token to requests. If a request is rejected
+// with "401 Unauthorized", the token is refreshed and the request is retried once.
+type oauthTransport struct {
+	source *oauthTokenSource
+	base   http.RoundTripper
+}
+
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.send(req, req.Body, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// request can't be retried if the body was already consumed
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	t.source.invalidate(token)
+	token, err = t.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	body := req.Body
+	if req.GetBody != nil {
+		body, err = req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("req.GetBody: %w", err)
+		}
+	}
+
+	return t.send(req, body, token)
+}
+
+func (t *oauthTransport) send(req *http.Request, body io.ReadCloser, token *oauth2.Token) (*http.Response, error) {
+	// requests must not be modified by round trippers
+	r := req.Clone(req.Context())
+	r.Body = body
+	token.SetAuthHeader(r)
+
+	return t.base.RoundTrip(r)
+}
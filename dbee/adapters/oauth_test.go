@@ -0,0 +1,150 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newOAuthTestServer starts a token endpoint which issues "token-1", "token-2", ...
+// and a resource endpoint which accepts only the latest issued token.
+func newOAuthTestServer(t *testing.T) (server *httptest.Server, issued *atomic.Int32) {
+	t.Helper()
+
+	issued = new(atomic.Int32)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		n := issued.Add(1)
+
+		resp := map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		}
+		if r.PostForm.Get("grant_type") == "refresh_token" {
+			resp["refresh_token"] = fmt.Sprintf("refresh-%d", n)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/query", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", issued.Load()) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(body)
+	})
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, issued
+}
+
+func TestOAuthTokenSource_Params(t *testing.T) {
+	r := require.New(t)
+
+	// no oauth
+	params := url.Values{"database": {"default"}}
+	source, err := newOAuthTokenSource(params)
+	r.NoError(err)
+	r.Nil(source)
+
+	// missing token url
+	_, err = newOAuthTokenSource(url.Values{oauthParamClientID: {"id"}})
+	r.Error(err)
+
+	// missing client id and refresh token
+	_, err = newOAuthTokenSource(url.Values{oauthParamTokenURL: {"http://localhost/token"}})
+	r.Error(err)
+
+	// oauth params are removed
+	params = url.Values{
+		"database":             {"default"},
+		oauthParamTokenURL:     {"http://localhost/token"},
+		oauthParamClientID:     {"id"},
+		oauthParamClientSecret: {"secret"},
+		oauthParamScopes:       {"read,write"},
+	}
+	source, err = newOAuthTokenSource(params)
+	r.NoError(err)
+	r.NotNil(source.credentials)
+	r.Equal([]string{"read", "write"}, source.credentials.Scopes)
+	r.Equal(url.Values{"database": {"default"}}, params)
+}
+
+func TestOAuthTransport_RetryOnUnauthorized(t *testing.T) {
+	type testCase struct {
+		name   string
+		params url.Values
+	}
+
+	testCases := []testCase{
+		{
+			name: "client credentials",
+			params: url.Values{
+				oauthParamClientID:     {"id"},
+				oauthParamClientSecret: {"secret"},
+			},
+		},
+		{
+			name: "refresh token",
+			params: url.Values{
+				oauthParamClientID:     {"id"},
+				oauthParamRefreshToken: {"refresh-0"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			server, issued := newOAuthTestServer(t)
+
+			tc.params.Set(oauthParamTokenURL, server.URL+"/token")
+			source, err := newOAuthTokenSource(tc.params)
+			r.NoError(err)
+
+			client := source.client()
+			post := func(body string) *http.Response {
+				resp, err := client.Post(server.URL+"/query", "text/plain", strings.NewReader(body))
+				r.NoError(err)
+				t.Cleanup(func() { resp.Body.Close() })
+				return resp
+			}
+
+			// first request fetches a token
+			resp := post("SELECT 1")
+			r.Equal(http.StatusOK, resp.StatusCode)
+			r.EqualValues(1, issued.Load())
+
+			// cached token is reused
+			resp = post("SELECT 2")
+			r.Equal(http.StatusOK, resp.StatusCode)
+			r.EqualValues(1, issued.Load())
+
+			// token is revoked on the server (a newer one is issued elsewhere),
+			// the request is retried once with a fresh token and the same body
+			issued.Add(1)
+			resp = post("SELECT 3")
+			r.Equal(http.StatusOK, resp.StatusCode)
+			r.EqualValues(3, issued.Load())
+			body, err := io.ReadAll(resp.Body)
+			r.NoError(err)
+			r.Equal("SELECT 3", string(body))
+		})
+	}
+}
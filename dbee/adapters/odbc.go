@@ -0,0 +1,57 @@
+//go:build windows || (odbc && cgo && (darwin || linux || freebsd))
+
+package adapters
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/alexbrainman/odbc"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// Register client
+func init() {
+	_ = register(&ODBC{}, "odbc")
+}
+
+var _ core.Adapter = (*ODBC)(nil)
+
+// ODBC is a generic adapter for data sources without a native go driver
+// (e.g. MS Access). It requires an ODBC driver manager: it is always
+// available on windows, other platforms need unixODBC installed and dbee
+// built with "odbc" build tag.
+type ODBC struct{}
+
+// Connect opens an ODBC connection. See [parseODBCURL] for the url format.
+func (o *ODBC) Connect(rawURL string) (core.Driver, error) {
+	connStr, err := parseODBCURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("odbc", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to odbc data source: %w", err)
+	}
+
+	return &odbcDriver{
+		c: builders.NewClient(db),
+		openCatalog: func() (odbcCatalog, error) {
+			return newODBCAPICatalog(connStr)
+		},
+	}, nil
+}
+
+func (*ODBC) GetHelpers(opts *core.TableOptions) map[string]string {
+	table := opts.Table
+	if opts.Schema != "" {
+		table = opts.Schema + "." + opts.Table
+	}
+
+	return map[string]string{
+		"List": fmt.Sprintf("SELECT * FROM %s", table),
+	}
+}
@@ -0,0 +1,185 @@
+//go:build windows || (odbc && cgo && (darwin || linux || freebsd))
+
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"unsafe"
+
+	"github.com/alexbrainman/odbc"
+	"github.com/alexbrainman/odbc/api"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var _ odbcCatalog = (*odbcAPICatalog)(nil)
+
+// odbcAPICatalog is a separate raw ODBC connection used for catalog functions.
+type odbcAPICatalog struct {
+	env api.SQLHENV
+	dbc api.SQLHDBC
+	// handles are not safe for concurrent use
+	lock sync.Mutex
+}
+
+func newODBCAPICatalog(connStr string) (*odbcAPICatalog, error) {
+	var env api.SQLHANDLE
+	ret := api.SQLAllocHandle(api.SQL_HANDLE_ENV, api.SQLHANDLE(api.SQL_NULL_HANDLE), &env)
+	if odbc.IsError(ret) {
+		return nil, errors.New("SQLAllocHandle: failed allocating environment handle")
+	}
+
+	ret = api.SQLSetEnvUIntPtrAttr(api.SQLHENV(env), api.SQL_ATTR_ODBC_VERSION, api.SQL_OV_ODBC3, 0)
+	if odbc.IsError(ret) {
+		err := odbc.NewError("SQLSetEnvUIntPtrAttr", api.SQLHENV(env))
+		api.SQLFreeHandle(api.SQL_HANDLE_ENV, env)
+		return nil, err
+	}
+
+	var dbc api.SQLHANDLE
+	ret = api.SQLAllocHandle(api.SQL_HANDLE_DBC, env, &dbc)
+	if odbc.IsError(ret) {
+		err := odbc.NewError("SQLAllocHandle", api.SQLHENV(env))
+		api.SQLFreeHandle(api.SQL_HANDLE_ENV, env)
+		return nil, err
+	}
+
+	b := api.StringToUTF16(connStr)
+	ret = api.SQLDriverConnect(api.SQLHDBC(dbc), 0,
+		(*api.SQLWCHAR)(unsafe.Pointer(&b[0])), api.SQL_NTS,
+		nil, 0, nil, api.SQL_DRIVER_NOPROMPT)
+	if odbc.IsError(ret) {
+		err := odbc.NewError("SQLDriverConnect", api.SQLHDBC(dbc))
+		api.SQLFreeHandle(api.SQL_HANDLE_DBC, dbc)
+		api.SQLFreeHandle(api.SQL_HANDLE_ENV, env)
+		return nil, err
+	}
+
+	return &odbcAPICatalog{
+		env: api.SQLHENV(env),
+		dbc: api.SQLHDBC(dbc),
+	}, nil
+}
+
+// query allocates a statement, calls the catalog function and
+// calls onRow for each fetched row.
+func (c *odbcAPICatalog) query(name string, call func(api.SQLHSTMT) api.SQLRETURN, onRow func(get func(col int) string)) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var h api.SQLHANDLE
+	ret := api.SQLAllocHandle(api.SQL_HANDLE_STMT, api.SQLHANDLE(c.dbc), &h)
+	if odbc.IsError(ret) {
+		return odbc.NewError("SQLAllocHandle", c.dbc)
+	}
+	stmt := api.SQLHSTMT(h)
+	defer api.SQLFreeHandle(api.SQL_HANDLE_STMT, h)
+
+	ret = call(stmt)
+	if odbc.IsError(ret) {
+		return odbc.NewError(name, stmt)
+	}
+
+	buf := make([]uint16, 1024)
+	get := func(col int) string {
+		var length api.SQLLEN
+		ret := api.SQLGetData(stmt, api.SQLUSMALLINT(col), api.SQL_C_WCHAR,
+			api.SQLPOINTER(unsafe.Pointer(&buf[0])), api.SQLLEN(len(buf)*2), &length)
+		if odbc.IsError(ret) || length == api.SQL_NULL_DATA {
+			return ""
+		}
+
+		// value might be truncated
+		n := int(length) / 2
+		if n > len(buf)-1 || length == api.SQL_NO_TOTAL {
+			n = len(buf) - 1
+		}
+		return api.UTF16ToString(buf[:n])
+	}
+
+	for {
+		ret := api.SQLFetch(stmt)
+		if ret == api.SQL_NO_DATA {
+			return nil
+		}
+		if odbc.IsError(ret) {
+			return odbc.NewError("SQLFetch", stmt)
+		}
+		onRow(get)
+	}
+}
+
+// Tables lists tables and views with SQLTables.
+func (c *odbcAPICatalog) Tables() ([]odbcTable, error) {
+	var tables []odbcTable
+
+	err := c.query("SQLTables", func(stmt api.SQLHSTMT) api.SQLRETURN {
+		return sqlTables(stmt, "", "", "", "TABLE,VIEW")
+	}, func(get func(int) string) {
+		// result columns: TABLE_CAT, TABLE_SCHEM, TABLE_NAME, TABLE_TYPE, REMARKS
+		tables = append(tables, odbcTable{
+			schema: get(2),
+			name:   get(3),
+			typ:    get(4),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// Columns lists columns of the table with SQLColumns.
+func (c *odbcAPICatalog) Columns(schema, table string) ([]*core.Column, error) {
+	var columns []*core.Column
+
+	err := c.query("SQLColumns", func(stmt api.SQLHSTMT) api.SQLRETURN {
+		return sqlColumns(stmt, "", schema, table, "")
+	}, func(get func(int) string) {
+		// result columns: TABLE_CAT, TABLE_SCHEM, TABLE_NAME, COLUMN_NAME, DATA_TYPE, TYPE_NAME, COLUMN_SIZE, ...
+		// names are patterns, so make sure the table matches exactly
+		if get(3) != table || (schema != "" && get(2) != schema) {
+			return
+		}
+
+		typ := get(6)
+		if size := get(7); size != "" {
+			if _, err := strconv.Atoi(size); err == nil {
+				typ = fmt.Sprintf("%s(%s)", typ, size)
+			}
+		}
+
+		columns = append(columns, &core.Column{
+			Name: get(4),
+			Type: typ,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+func (c *odbcAPICatalog) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	api.SQLDisconnect(c.dbc)
+	api.SQLFreeHandle(api.SQL_HANDLE_DBC, api.SQLHANDLE(c.dbc))
+	api.SQLFreeHandle(api.SQL_HANDLE_ENV, api.SQLHANDLE(c.env))
+}
+
+// odbcString converts the catalog function argument to a null terminated
+// UTF-16 string. Empty strings are converted to nil (all objects).
+func odbcString(s string) *api.SQLWCHAR {
+	if s == "" {
+		return nil
+	}
+	b := api.StringToUTF16(s)
+	return (*api.SQLWCHAR)(unsafe.Pointer(&b[0]))
+}
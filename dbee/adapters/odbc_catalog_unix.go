@@ -0,0 +1,48 @@
+//go:build odbc && cgo && (darwin || linux || freebsd)
+
+package adapters
+
+// #cgo darwin LDFLAGS: -L /usr/local/opt/unixodbc/lib -lodbc
+// #cgo darwin CFLAGS: -I /usr/local/opt/unixodbc/include
+// #cgo linux LDFLAGS: -lodbc
+// #cgo freebsd LDFLAGS: -L /usr/local/lib -lodbc
+// #cgo freebsd CFLAGS: -I/usr/local/include
+// #include <sql.h>
+// #include <sqlext.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/alexbrainman/odbc/api"
+)
+
+// catalog functions are not exposed by the odbc api package
+
+func sqlTables(stmt api.SQLHSTMT, catalog, schema, table, types string) api.SQLRETURN {
+	c, s, t, ty := odbcString(catalog), odbcString(schema), odbcString(table), odbcString(types)
+	r := C.SQLTablesW(C.SQLHSTMT(stmt),
+		(*C.SQLWCHAR)(unsafe.Pointer(c)), odbcStringLen(c),
+		(*C.SQLWCHAR)(unsafe.Pointer(s)), odbcStringLen(s),
+		(*C.SQLWCHAR)(unsafe.Pointer(t)), odbcStringLen(t),
+		(*C.SQLWCHAR)(unsafe.Pointer(ty)), odbcStringLen(ty))
+	return api.SQLRETURN(r)
+}
+
+func sqlColumns(stmt api.SQLHSTMT, catalog, schema, table, column string) api.SQLRETURN {
+	c, s, t, co := odbcString(catalog), odbcString(schema), odbcString(table), odbcString(column)
+	r := C.SQLColumnsW(C.SQLHSTMT(stmt),
+		(*C.SQLWCHAR)(unsafe.Pointer(c)), odbcStringLen(c),
+		(*C.SQLWCHAR)(unsafe.Pointer(s)), odbcStringLen(s),
+		(*C.SQLWCHAR)(unsafe.Pointer(t)), odbcStringLen(t),
+		(*C.SQLWCHAR)(unsafe.Pointer(co)), odbcStringLen(co))
+	return api.SQLRETURN(r)
+}
+
+// odbcStringLen returns the length argument for catalog function string.
+func odbcStringLen(s *api.SQLWCHAR) C.SQLSMALLINT {
+	if s == nil {
+		return 0
+	}
+	return C.SQL_NTS
+}
@@ -0,0 +1,47 @@
+//go:build windows
+
+package adapters
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/alexbrainman/odbc/api"
+	"golang.org/x/sys/windows"
+)
+
+// catalog functions are not exposed by the odbc api package
+var (
+	modODBC32       = windows.NewLazySystemDLL("odbc32.dll")
+	procSQLTablesW  = modODBC32.NewProc("SQLTablesW")
+	procSQLColumnsW = modODBC32.NewProc("SQLColumnsW")
+)
+
+func sqlTables(stmt api.SQLHSTMT, catalog, schema, table, types string) api.SQLRETURN {
+	c, s, t, ty := odbcString(catalog), odbcString(schema), odbcString(table), odbcString(types)
+	r0, _, _ := syscall.SyscallN(procSQLTablesW.Addr(), uintptr(stmt),
+		uintptr(unsafe.Pointer(c)), odbcStringLen(c),
+		uintptr(unsafe.Pointer(s)), odbcStringLen(s),
+		uintptr(unsafe.Pointer(t)), odbcStringLen(t),
+		uintptr(unsafe.Pointer(ty)), odbcStringLen(ty))
+	return api.SQLRETURN(r0)
+}
+
+func sqlColumns(stmt api.SQLHSTMT, catalog, schema, table, column string) api.SQLRETURN {
+	c, s, t, co := odbcString(catalog), odbcString(schema), odbcString(table), odbcString(column)
+	r0, _, _ := syscall.SyscallN(procSQLColumnsW.Addr(), uintptr(stmt),
+		uintptr(unsafe.Pointer(c)), odbcStringLen(c),
+		uintptr(unsafe.Pointer(s)), odbcStringLen(s),
+		uintptr(unsafe.Pointer(t)), odbcStringLen(t),
+		uintptr(unsafe.Pointer(co)), odbcStringLen(co))
+	return api.SQLRETURN(r0)
+}
+
+// odbcStringLen returns the length argument for catalog function string.
+func odbcStringLen(s *api.SQLWCHAR) uintptr {
+	if s == nil {
+		return 0
+	}
+	n := api.SQLSMALLINT(api.SQL_NTS)
+	return uintptr(n)
+}
@@ -0,0 +1,216 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	nurl "net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver          = (*odbcDriver)(nil)
+	_ core.IdleCloser      = (*odbcDriver)(nil)
+	_ core.SafeScanner     = (*odbcDriver)(nil)
+	_ core.StatementLogger = (*odbcDriver)(nil)
+)
+
+// odbcTable is a single row of SQLTables result.
+type odbcTable struct {
+	schema string
+	name   string
+	typ    string
+}
+
+// odbcCatalog lists database objects using ODBC catalog functions
+// (SQLTables and SQLColumns), which are not available through database/sql.
+type odbcCatalog interface {
+	Tables() ([]odbcTable, error)
+	Columns(schema, table string) ([]*core.Column, error)
+	Close()
+}
+
+type odbcDriver struct {
+	c *builders.Client
+
+	// catalog is opened on first use
+	openCatalog func() (odbcCatalog, error)
+	catalog     odbcCatalog
+	catalogLock sync.Mutex
+}
+
+// parseODBCURL returns the ODBC connection string from the url.
+// The url can either be a plain connection string (e.g. "DSN=MyDSN;UID=user")
+// or in the following form:
+//
+//	odbc://[user:password@]?dsn=MyDSN[&key=value...]
+//	odbc://?connection_string=<escaped connection string>
+//
+// Where additional query parameters are appended to the connection string as "key=value;".
+func parseODBCURL(rawURL string) (string, error) {
+	if !strings.HasPrefix(rawURL, "odbc:") {
+		return rawURL, nil
+	}
+
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	params := u.Query()
+	if cs := params.Get("connection_string"); cs != "" {
+		return cs, nil
+	}
+
+	dsn := params.Get("dsn")
+	if dsn == "" {
+		return "", fmt.Errorf("url must contain either \"dsn\" or \"connection_string\" parameter, got: %q", rawURL)
+	}
+
+	parts := []string{"DSN=" + odbcQuote(dsn)}
+	if u.User != nil {
+		parts = append(parts, "UID="+odbcQuote(u.User.Username()))
+		if pass, ok := u.User.Password(); ok {
+			parts = append(parts, "PWD="+odbcQuote(pass))
+		}
+	}
+
+	var keys []string
+	for k := range params {
+		if k != "dsn" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+odbcQuote(params.Get(k)))
+	}
+
+	return strings.Join(parts, ";") + ";", nil
+}
+
+// odbcQuote wraps the connection string value in braces if needed.
+func odbcQuote(value string) string {
+	if !strings.ContainsAny(value, ";{}= ") {
+		return value
+	}
+	return "{" + strings.ReplaceAll(value, "}", "}}") + "}"
+}
+
+func (c *odbcDriver) getCatalog() (odbcCatalog, error) {
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	if c.catalog != nil {
+		return c.catalog, nil
+	}
+
+	catalog, err := c.openCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed opening catalog connection: %w", err)
+	}
+	c.catalog = catalog
+
+	return catalog, nil
+}
+
+func (c *odbcDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	return c.c.QueryUntilNotEmpty(ctx, query)
+}
+
+func (c *odbcDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	catalog, err := c.getCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.Columns(opts.Schema, opts.Table)
+}
+
+func (c *odbcDriver) Structure() ([]*core.Structure, error) {
+	catalog, err := c.getCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := catalog.Tables()
+	if err != nil {
+		return nil, err
+	}
+
+	return odbcStructure(tables), nil
+}
+
+// odbcStructure converts tables to structure. Tables are grouped
+// by schema, unless the data source doesn't have schemas (e.g. MS Access).
+func odbcStructure(tables []odbcTable) []*core.Structure {
+	children := make(map[string][]*core.Structure)
+
+	for _, t := range tables {
+		var typ core.StructureType
+		switch t.typ {
+		case "TABLE":
+			typ = core.StructureTypeTable
+		case "VIEW":
+			typ = core.StructureTypeView
+		default:
+			continue
+		}
+
+		children[t.schema] = append(children[t.schema], &core.Structure{
+			Name:   t.name,
+			Schema: t.schema,
+			Type:   typ,
+		})
+	}
+
+	// no schemas
+	if len(children) == 1 {
+		if flat, ok := children[""]; ok {
+			return flat
+		}
+	}
+
+	var structure []*core.Structure
+	for schema, v := range children {
+		structure = append(structure, &core.Structure{
+			Name:     schema,
+			Schema:   schema,
+			Type:     core.StructureTypeNone,
+			Children: v,
+		})
+	}
+	sort.Slice(structure, func(i, j int) bool {
+		return structure[i].Name < structure[j].Name
+	})
+
+	return structure
+}
+
+func (c *odbcDriver) Close() {
+	c.catalogLock.Lock()
+	if c.catalog != nil {
+		c.catalog.Close()
+		c.catalog = nil
+	}
+	c.catalogLock.Unlock()
+
+	c.c.Close()
+}
+
+func (c *odbcDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *odbcDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+func (c *odbcDriver) SetIdleTimeout(timeout time.Duration) {
+	c.c.SetIdleTimeout(timeout)
+}
@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestParseODBCURL(t *testing.T) {
+	type testCase struct {
+		name     string
+		url      string
+		expected string
+		wantErr  bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "plain connection string",
+			url:      "Driver={Microsoft Access Driver (*.mdb, *.accdb)};DBQ=C:\\db.accdb;",
+			expected: "Driver={Microsoft Access Driver (*.mdb, *.accdb)};DBQ=C:\\db.accdb;",
+		},
+		{
+			name:     "dsn with credentials and params",
+			url:      "odbc://user:p%3Bss@?dsn=MyDSN&Timeout=10&Encoding=utf8",
+			expected: "DSN=MyDSN;UID=user;PWD={p;ss};Encoding=utf8;Timeout=10;",
+		},
+		{
+			name:     "escaped connection string",
+			url:      "odbc://?connection_string=DSN%3DMyDSN%3BUID%3Duser",
+			expected: "DSN=MyDSN;UID=user",
+		},
+		{
+			name:    "missing dsn",
+			url:     "odbc://user@?Timeout=10",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			connStr, err := parseODBCURL(tc.url)
+			if tc.wantErr {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+			r.Equal(tc.expected, connStr)
+		})
+	}
+}
+
+func TestODBCStructure(t *testing.T) {
+	r := require.New(t)
+
+	// no schemas (e.g. MS Access)
+	structure := odbcStructure([]odbcTable{
+		{name: "customers", typ: "TABLE"},
+		{name: "MSysObjects", typ: "SYSTEM TABLE"},
+		{name: "active_customers", typ: "VIEW"},
+	})
+	r.Equal([]*core.Structure{
+		{Name: "customers", Type: core.StructureTypeTable},
+		{Name: "active_customers", Type: core.StructureTypeView},
+	}, structure)
+
+	// grouped by schema
+	structure = odbcStructure([]odbcTable{
+		{schema: "dbo", name: "orders", typ: "TABLE"},
+	})
+	r.Equal([]*core.Structure{
+		{
+			Name:   "dbo",
+			Schema: "dbo",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "orders", Schema: "dbo", Type: core.StructureTypeTable},
+			},
+		},
+	}, structure)
+}
@@ -94,7 +94,7 @@ func (*Oracle) GetHelpers(opts *core.TableOptions) map[string]string {
 			qualifyAndOrderBy("N.index_name"),
 		),
 
-		"List": fmt.Sprintf("SELECT * FROM %q.%q", opts.Schema, opts.Table),
+		"List": fmt.Sprintf("SELECT * FROM %s.%s", core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table)),
 
 		"Primary Keys": keyCmd("P"),
 
@@ -3,12 +3,25 @@ package adapters
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*oracleDriver)(nil)
+var (
+	_ core.Driver            = (*oracleDriver)(nil)
+	_ core.ContextStructurer = (*oracleDriver)(nil)
+	_ core.IdentifierFolder  = (*oracleDriver)(nil)
+	_ core.IdleCloser        = (*oracleDriver)(nil)
+	_ core.Limiter           = (*oracleDriver)(nil)
+	_ core.ParamQuerier      = (*oracleDriver)(nil)
+	_ core.Peeker            = (*oracleDriver)(nil)
+	_ core.SafeScanner       = (*oracleDriver)(nil)
+	_ core.SequenceLister    = (*oracleDriver)(nil)
+	_ core.StatementLogger   = (*oracleDriver)(nil)
+	_ core.TopValuer         = (*oracleDriver)(nil)
+)
 
 type oracleDriver struct {
 	c *builders.Client
@@ -32,7 +45,10 @@ func (c *oracleDriver) Columns(opts *core.TableOptions) ([]*core.Column, error)
 	return c.c.ColumnsFromQuery(`
 		SELECT
 			col.column_name,
-			col.data_type
+			col.data_type,
+			CASE WHEN col.char_length > 0 THEN col.char_length END,
+			col.data_precision,
+			col.data_scale
 		FROM sys.all_tab_columns col
 		INNER JOIN sys.all_tables t
 			ON col.owner = t.owner
@@ -46,20 +62,26 @@ func (c *oracleDriver) Columns(opts *core.TableOptions) ([]*core.Column, error)
 }
 
 func (c *oracleDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+func (c *oracleDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
 	query := `
-		SELECT T.owner, T.table_name
+		SELECT T.owner, T.table_name, T.table_type
 		FROM (
-			SELECT owner, table_name
+			SELECT owner, table_name, 'TABLE' AS "table_type"
 			FROM all_tables
-			UNION SELECT owner, view_name AS "table_name"
+			UNION SELECT owner, view_name AS "table_name", 'TABLE' AS "table_type"
 			FROM all_views
+			UNION SELECT sequence_owner AS "owner", sequence_name AS "table_name", 'SEQUENCE' AS "table_type"
+			FROM all_sequences
 		) T
 		JOIN all_users U ON T.owner = U.username
 		WHERE U.common = 'NO'
 		ORDER BY T.table_name
 	`
 
-	rows, err := c.Query(context.TODO(), query)
+	rows, err := c.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -72,17 +94,25 @@ func (c *oracleDriver) Structure() ([]*core.Structure, error) {
 			return nil, err
 		}
 
-		// We know for a fact there are 2 string fields (see query above)
+		// We know for a fact there are 3 string fields (see query above)
 		schema := row[0].(string)
 		table := row[1].(string)
 
+		typ := core.StructureTypeTable
+		if row[2].(string) == "SEQUENCE" {
+			typ = core.StructureTypeSequence
+		}
+
 		children[schema] = append(children[schema], &core.Structure{
 			Name:   table,
 			Schema: schema,
-			Type:   core.StructureTypeTable,
+			Type:   typ,
 		})
 
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	var structure []*core.Structure
 
@@ -98,6 +128,63 @@ func (c *oracleDriver) Structure() ([]*core.Structure, error) {
 	return structure, nil
 }
 
+// Sequences lists sequences with the identity columns owning them. The current value
+// is the last number written to disk, which is ahead of the actual value if the sequence
+// is cached. Limits are capped to the int64 range (the default maximum is 10^28 - 1).
+func (c *oracleDriver) Sequences() ([]*core.Sequence, error) {
+	rows, err := c.Query(context.TODO(), `
+		SELECT S.sequence_owner, S.sequence_name, I.table_name, I.column_name,
+			TO_CHAR(S.last_number), TO_CHAR(S.increment_by),
+			TO_CHAR(GREATEST(S.min_value, -9223372036854775808)), TO_CHAR(LEAST(S.max_value, 9223372036854775807))
+		FROM all_sequences S
+		JOIN all_users U ON S.sequence_owner = U.username
+		LEFT JOIN all_tab_identity_cols I ON I.owner = S.sequence_owner AND I.sequence_name = S.sequence_name
+		WHERE U.common = 'NO'
+		ORDER BY S.sequence_owner, S.sequence_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return sequencesFromResultStream(rows)
+}
+
 func (c *oracleDriver) Close() {
 	c.c.Close()
 }
+
+func (c *oracleDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectFetchFirst
+}
+
+func (c *oracleDriver) IdentifierCase() core.IdentifierCase {
+	return core.IdentifierCaseUpper
+}
+
+func (c *oracleDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *oracleDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderColon
+}
+
+func (c *oracleDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteDouble, core.LimitDialectFetchFirst)
+}
+
+func (c *oracleDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return c.c.Peek(ctx, query)
+}
+
+func (c *oracleDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *oracleDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+func (c *oracleDriver) SetIdleTimeout(timeout time.Duration) {
+	c.c.SetIdleTimeout(timeout)
+}
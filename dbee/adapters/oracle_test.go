@@ -0,0 +1,25 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestOracleHelpers_MixedCase(t *testing.T) {
+	r := require.New(t)
+
+	helpers := (&Oracle{}).GetHelpers(&core.TableOptions{Schema: "HR", Table: "OrderItems", Materialization: core.StructureTypeTable})
+	r.Equal(`SELECT * FROM "HR"."OrderItems"`, helpers["List"])
+
+	// unquoted names are stored in upper case
+	ic := (&oracleDriver{}).IdentifierCase()
+	r.Equal("employees", ic.Display("EMPLOYEES"))
+	r.Equal("EMPLOYEES", ic.Catalog("employees"))
+	r.Equal(`"OrderItems"`, ic.Display("OrderItems"))
+	r.Equal("OrderItems", ic.Catalog(`"OrderItems"`))
+	r.Equal(`"employees"`, ic.Display("employees"))
+	r.Equal("employees", ic.Catalog(`"employees"`))
+}
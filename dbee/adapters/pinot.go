@@ -0,0 +1,100 @@
+package adapters
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	nurl "net/url"
+	"sort"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&Pinot{}, "pinot")
+}
+
+var _ core.Adapter = (*Pinot)(nil)
+
+// Pinot talks to the query endpoint of an Apache Pinot broker and
+// to the rest api of the controller (for structure and segment metadata).
+type Pinot struct{}
+
+// Connect creates a [Pinot] client.
+// The format of the url is as follows:
+//
+//	pinot://[user:password@]broker-host:port[?options]
+//
+// Where:
+//   - "pinots" (or "https") scheme can be used instead of "pinot" (or "http") to connect over https.
+//   - "options" is an ampersand-separated list of key=value arguments.
+//
+// The supported "options" are:
+//   - controller=host:port - address of the controller (default is the broker host with port 9000).
+//   - any other option is passed to the broker as a query option (e.g. timeoutMs=10000).
+//
+// Instead of basic auth, OAuth2 can be used by passing the "oauth-token-url",
+// "oauth-client-id", "oauth-client-secret", "oauth-refresh-token" and "oauth-scopes"
+// options (see newOAuthTokenSource).
+func (*Pinot) Connect(rawURL string) (core.Driver, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	var scheme string
+	switch u.Scheme {
+	case "pinot", "http":
+		scheme = "http"
+	case "pinots", "https":
+		scheme = "https"
+	default:
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	params := u.Query()
+	oauth, err := newOAuthTokenSource(params)
+	if err != nil {
+		return nil, err
+	}
+
+	controller := params.Get("controller")
+	params.Del("controller")
+	if controller == "" {
+		controller = net.JoinHostPort(u.Hostname(), "9000")
+	}
+
+	var options []string
+	for key, values := range params {
+		if len(values) > 0 {
+			options = append(options, key+"="+values[len(values)-1])
+		}
+	}
+	sort.Strings(options)
+
+	driver := &pinotDriver{
+		c:            &http.Client{},
+		broker:       &nurl.URL{Scheme: scheme, Host: u.Host},
+		controller:   &nurl.URL{Scheme: scheme, Host: controller},
+		queryOptions: strings.Join(options, ";"),
+	}
+
+	if oauth != nil {
+		driver.c = oauth.client()
+	} else if u.User != nil {
+		driver.user = u.User.Username()
+		driver.password, _ = u.User.Password()
+	}
+
+	return driver, nil
+}
+
+func (*Pinot) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List":     fmt.Sprintf("SELECT * FROM %q LIMIT 500", opts.Table),
+		"Count":    fmt.Sprintf("SELECT COUNT(*) FROM %q", opts.Table),
+		"Segments": fmt.Sprintf("SHOW SEGMENTS %s", opts.Table),
+	}
+}
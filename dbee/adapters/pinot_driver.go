@@ -0,0 +1,299 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver    = (*pinotDriver)(nil)
+	_ core.Limiter   = (*pinotDriver)(nil)
+	_ core.TopValuer = (*pinotDriver)(nil)
+)
+
+// pinotSegmentsQuery is a pseudo statement handled by the driver,
+// which lists segment metadata of a table from the controller.
+var pinotSegmentsQuery = regexp.MustCompile(`(?i)^\s*SHOW\s+SEGMENTS\s+"?([^\s";]+)"?\s*;?\s*$`)
+
+type pinotDriver struct {
+	c            *http.Client
+	broker       *nurl.URL
+	controller   *nurl.URL
+	user         string
+	password     string
+	queryOptions string
+}
+
+// pinotResponse is the response of the broker query endpoint.
+type pinotResponse struct {
+	ResultTable *struct {
+		DataSchema struct {
+			ColumnNames     []string `json:"columnNames"`
+			ColumnDataTypes []string `json:"columnDataTypes"`
+		} `json:"dataSchema"`
+		Rows [][]json.RawMessage `json:"rows"`
+	} `json:"resultTable"`
+	Exceptions []struct {
+		ErrorCode int    `json:"errorCode"`
+		Message   string `json:"message"`
+	} `json:"exceptions"`
+}
+
+func (c *pinotDriver) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("Accept", "application/json")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("c.c.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pinot responded with %q: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
+
+// query sends the sql query to the broker.
+func (c *pinotDriver) query(ctx context.Context, query string) (*pinotResponse, error) {
+	payload := map[string]string{
+		"sql": query,
+	}
+	if c.queryOptions != "" {
+		payload["queryOptions"] = c.queryOptions
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.broker.JoinPath("query", "sql").String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp pinotResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if len(resp.Exceptions) > 0 {
+		var msgs []string
+		for _, e := range resp.Exceptions {
+			msgs = append(msgs, fmt.Sprintf("pinot error %d: %s", e.ErrorCode, strings.TrimSpace(e.Message)))
+		}
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+
+	return &resp, nil
+}
+
+// get sends a get request to the controller and decodes the json response.
+func (c *pinotDriver) get(ctx context.Context, path []string, params nurl.Values, v any) error {
+	u := c.controller.JoinPath(path...)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return nil
+}
+
+func (c *pinotDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	if m := pinotSegmentsQuery.FindStringSubmatch(query); m != nil {
+		return c.segments(ctx, m[1])
+	}
+
+	resp, err := c.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ResultTable == nil {
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			Build(), nil
+	}
+
+	rows := resp.ResultTable.Rows
+	i := 0
+	hasNext := func() bool {
+		return i < len(rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+
+		row := make(core.Row, len(rows[i]))
+		for j, val := range rows[i] {
+			row[j] = rawJSONValue(val)
+		}
+		i++
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(resp.ResultTable.DataSchema.ColumnNames).
+		Build(), nil
+}
+
+// segments returns the metadata of table segments with a row per segment.
+func (c *pinotDriver) segments(ctx context.Context, table string) (core.ResultStream, error) {
+	var metadata map[string]map[string]json.RawMessage
+	if err := c.get(ctx, []string{"segments", table, "metadata"}, nil, &metadata); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(metadata))
+	fields := make(map[string]struct{})
+	for name, meta := range metadata {
+		names = append(names, name)
+		for field := range meta {
+			if field != "segmentName" {
+				fields[field] = struct{}{}
+			}
+		}
+	}
+	sort.Strings(names)
+
+	header := core.Header{"segmentName"}
+	for field := range fields {
+		header = append(header, field)
+	}
+	sort.Strings(header[1:])
+
+	i := 0
+	hasNext := func() bool {
+		return i < len(names)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+
+		meta := metadata[names[i]]
+		row := core.Row{names[i]}
+		for _, field := range header[1:] {
+			row = append(row, rawJSONValue(meta[field]))
+		}
+		i++
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build(), nil
+}
+
+func (c *pinotDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	resp, err := c.query(context.Background(), fmt.Sprintf("SELECT * FROM %q LIMIT 0", opts.Table))
+	if err != nil {
+		return nil, err
+	}
+	if resp.ResultTable == nil {
+		return nil, errors.New("no columns returned")
+	}
+
+	schema := resp.ResultTable.DataSchema
+
+	columns := make([]*core.Column, len(schema.ColumnNames))
+	for i, name := range schema.ColumnNames {
+		columns[i] = &core.Column{Name: name}
+		if i < len(schema.ColumnDataTypes) {
+			columns[i].Type = schema.ColumnDataTypes[i]
+		}
+	}
+
+	return columns, nil
+}
+
+// Structure lists offline and realtime tables (hybrid tables are listed under both).
+func (c *pinotDriver) Structure() ([]*core.Structure, error) {
+	var structure []*core.Structure
+
+	for _, typ := range []string{"offline", "realtime"} {
+		var tables struct {
+			Tables []string `json:"tables"`
+		}
+		err := c.get(context.Background(), []string{"tables"}, nurl.Values{"type": {typ}}, &tables)
+		if err != nil {
+			return nil, err
+		}
+		if len(tables.Tables) < 1 {
+			continue
+		}
+		sort.Strings(tables.Tables)
+
+		children := make([]*core.Structure, len(tables.Tables))
+		for i, table := range tables.Tables {
+			children[i] = &core.Structure{
+				Name:   table,
+				Schema: typ,
+				Type:   core.StructureTypeTable,
+			}
+		}
+
+		structure = append(structure, &core.Structure{
+			Name:     typ,
+			Schema:   typ,
+			Type:     core.StructureTypeNone,
+			Children: children,
+		})
+	}
+
+	return structure, nil
+}
+
+func (c *pinotDriver) Close() {
+	c.c.CloseIdleConnections()
+}
+
+func (c *pinotDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *pinotDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteDouble, core.LimitDialectLimit)
+}
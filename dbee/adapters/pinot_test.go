@@ -0,0 +1,142 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func newPinotTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	// broker
+	mux.HandleFunc("/query/sql", func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, "HTTP 401 Unauthorized")
+			return
+		}
+
+		var payload map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "timeoutMs=1000;useMultistageEngine=true", payload["queryOptions"])
+
+		query := payload["sql"]
+		switch {
+		case strings.Contains(query, "missing"):
+			fmt.Fprint(w, `{"exceptions":[{"errorCode":190,"message":"TableDoesNotExistError"}],"numRowsResultSet":0}`)
+		case strings.Contains(query, "LIMIT 0"):
+			fmt.Fprint(w, `{"resultTable":{"dataSchema":{"columnNames":["id","city"],"columnDataTypes":["LONG","STRING"]},"rows":[]},"exceptions":[]}`)
+		default:
+			fmt.Fprint(w, `{"resultTable":{"dataSchema":{"columnNames":["id","city","tags"],"columnDataTypes":["LONG","STRING","STRING_ARRAY"]},"rows":[[1,"Ljubljana",["a","b"]],[2,null,[]]]},"exceptions":[]}`)
+		}
+	})
+
+	// controller
+	mux.HandleFunc("/tables", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("type") {
+		case "offline":
+			fmt.Fprint(w, `{"tables":["trips","airports"]}`)
+		case "realtime":
+			fmt.Fprint(w, `{"tables":["trips"]}`)
+		}
+	})
+	mux.HandleFunc("/segments/trips/metadata", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"trips_1": {"segmentName":"trips_1","totalDocs":100,"crc":"123"},
+			"trips_0": {"segmentName":"trips_0","totalDocs":50,"startTime":10}
+		}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestPinot(t *testing.T) {
+	r := require.New(t)
+
+	server := newPinotTestServer(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	url := fmt.Sprintf("pinot://user:secret@%s?controller=%s&useMultistageEngine=true&timeoutMs=1000", host, host)
+	driver, err := (&Pinot{}).Connect(url)
+	r.NoError(err)
+	defer driver.Close()
+
+	// query
+	result, err := driver.Query(context.Background(), `SELECT * FROM "trips"`)
+	r.NoError(err)
+	r.Equal(core.Header{"id", "city", "tags"}, result.Header())
+
+	rows, err := drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{
+		{json.Number("1"), "Ljubljana", `["a","b"]`},
+		{json.Number("2"), nil, `[]`},
+	}, rows)
+
+	// exceptions
+	_, err = driver.Query(context.Background(), `SELECT * FROM "missing"`)
+	r.ErrorContains(err, "TableDoesNotExistError")
+
+	// columns
+	columns, err := driver.Columns(&core.TableOptions{Table: "trips"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "LONG"},
+		{Name: "city", Type: "STRING"},
+	}, columns)
+
+	// structure
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name:   "offline",
+			Schema: "offline",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "airports", Schema: "offline", Type: core.StructureTypeTable},
+				{Name: "trips", Schema: "offline", Type: core.StructureTypeTable},
+			},
+		},
+		{
+			Name:   "realtime",
+			Schema: "realtime",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "trips", Schema: "realtime", Type: core.StructureTypeTable},
+			},
+		},
+	}, structure)
+
+	// segment metadata
+	result, err = driver.Query(context.Background(), (&Pinot{}).GetHelpers(&core.TableOptions{Table: "trips"})["Segments"])
+	r.NoError(err)
+	r.Equal(core.Header{"segmentName", "crc", "startTime", "totalDocs"}, result.Header())
+	rows, err = drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{
+		{"trips_0", nil, json.Number("10"), json.Number("50")},
+		{"trips_1", "123", nil, json.Number("100")},
+	}, rows)
+
+	// invalid credentials
+	driver, err = (&Pinot{}).Connect("pinot://user:wrong@" + host)
+	r.NoError(err)
+	_, err = driver.Query(context.Background(), "SELECT 1")
+	r.ErrorContains(err, "401")
+}
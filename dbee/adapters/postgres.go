@@ -3,10 +3,13 @@ package adapters
 import (
 	"database/sql"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	nurl "net/url"
+	"strconv"
+	"strings"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
@@ -18,21 +21,30 @@ func init() {
 
 	// register special json response with gob
 	gob.Register(&postgresJSONResponse{})
+
+	// lib/pq supports a single global gssapi provider,
+	// so the kerberos config is taken from the environment
+	pq.RegisterGSSProvider(func() (pq.GSS, error) {
+		return newKerberosGSS(defaultKerberosConfig())
+	})
 }
 
 var _ core.Adapter = (*Postgres)(nil)
 
 type Postgres struct{}
 
+// Connect creates a [Postgres] client. Besides the options of lib/pq, the url accepts
+// "auth=gssapi", which authenticates with a kerberos ticket from the credential cache
+// (see KRB5_CONFIG and KRB5CCNAME environment variables). Service principal can be
+// changed with "krbsrvname" (default "postgres") or "krbspn" options.
+//
+// Unix sockets are used if the host is a directory of the socket, either as an option
+// ("postgres:///db?host=/var/run/postgresql") or percent-encoded like with libpq
+// ("postgres://%2Fvar%2Frun%2Fpostgresql/db").
 func (p *Postgres) Connect(url string) (core.Driver, error) {
-	u, err := nurl.Parse(url)
+	db, u, err := openPostgresURL(url)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
-	}
-
-	db, err := sql.Open("postgres", u.String())
-	if err != nil {
-		return nil, fmt.Errorf("unable to connect to postgres database: %w", err)
+		return nil, err
 	}
 
 	jsonProcessor := func(a any) any {
@@ -44,13 +56,120 @@ func (p *Postgres) Connect(url string) (core.Driver, error) {
 		return newPostgresJSONResponse(b)
 	}
 
-	return &postgresDriver{
-		c: builders.NewClient(db,
-			builders.WithCustomTypeProcessor("json", jsonProcessor),
-			builders.WithCustomTypeProcessor("jsonb", jsonProcessor),
-		),
-		url: u,
-	}, nil
+	driver := &postgresDriver{url: u}
+
+	// extension types (e.g. PostGIS geometry and geography) have dynamic oids,
+	// so they are reported without a type name
+	extensionProcessor := func(a any) any {
+		b, ok := a.([]byte)
+		if !ok {
+			return a
+		}
+
+		if driver.renderGeometry {
+			if wkt, err := ewkbHexToWKT(string(b)); err == nil {
+				return wkt
+			}
+		}
+
+		return string(b)
+	}
+
+	driver.c = builders.NewClient(db,
+		builders.WithCustomTypeProcessor("json", jsonProcessor),
+		builders.WithCustomTypeProcessor("jsonb", jsonProcessor),
+		builders.WithCustomTypeProcessor("", extensionProcessor),
+	)
+
+	return driver, nil
+}
+
+// openPostgresURL opens a pool of connections to the database of the url.
+func openPostgresURL(url string) (*sql.DB, *nurl.URL, error) {
+	url, err := postgresSocketURL(url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := nurl.Parse(url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	params := u.Query()
+	krb, err := newKerberosConfig(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if krb != nil {
+		if *krb != *defaultKerberosConfig() {
+			return nil, nil, errors.New("kerberos config and credential cache can't be set per connection, use KRB5_CONFIG and KRB5CCNAME environment variables instead")
+		}
+		// fail early if there is no valid ticket
+		if _, err := krb.client(); err != nil {
+			return nil, nil, err
+		}
+		u.RawQuery = params.Encode()
+	}
+
+	db, err := openPostgres(u)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to connect to postgres database: %w", err)
+	}
+
+	return db, u, nil
+}
+
+// postgresSocketURL moves the socket directory of a percent-encoded host (which can't
+// be parsed as a url host) to the "host" option, e.g. "postgres://user@%2Ftmp:5433/db"
+// becomes "postgres://user@/db?host=%2Ftmp&port=5433". Other urls are returned as they are.
+func postgresSocketURL(rawURL string) (string, error) {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return rawURL, nil
+	}
+
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority, tail := rest[:end], rest[end:]
+
+	userinfo, host := "", authority
+	if at := strings.LastIndexByte(authority, '@'); at >= 0 {
+		userinfo, host = authority[:at+1], authority[at+1:]
+	}
+	if !strings.HasPrefix(strings.ToUpper(host), "%2F") {
+		return rawURL, nil
+	}
+
+	port := ""
+	if colon := strings.LastIndexByte(host, ':'); colon >= 0 {
+		host, port = host[:colon], host[colon+1:]
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return "", fmt.Errorf("invalid port: %q", port)
+		}
+	}
+
+	dir, err := nurl.PathUnescape(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid socket directory: %w", err)
+	}
+
+	path, rawQuery, _ := strings.Cut(tail, "?")
+	if path == "" {
+		path = "/"
+	}
+	params, err := nurl.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("could not parse db connection string: %w", err)
+	}
+	params.Set("host", dir)
+	if port != "" {
+		params.Set("port", port)
+	}
+
+	return scheme + "://" + userinfo + path + "?" + params.Encode(), nil
 }
 
 func (*Postgres) GetHelpers(opts *core.TableOptions) map[string]string {
@@ -67,9 +186,14 @@ func (*Postgres) GetHelpers(opts *core.TableOptions) map[string]string {
 	`
 
 	return map[string]string{
-		"List":    fmt.Sprintf("SELECT * FROM %q.%q LIMIT 500", opts.Schema, opts.Table),
+		"List":    fmt.Sprintf("SELECT * FROM %s.%s LIMIT 500", core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table)),
 		"Columns": fmt.Sprintf("SELECT * FROM information_schema.columns WHERE table_name='%s' AND table_schema='%s'", opts.Table, opts.Schema),
 		"Indexes": fmt.Sprintf("SELECT * FROM pg_indexes WHERE tablename='%s' AND schemaname='%s'", opts.Table, opts.Schema),
+		"Size": fmt.Sprintf(`SELECT pg_size_pretty(pg_total_relation_size(r)) AS total, pg_size_pretty(pg_relation_size(r)) AS data,
+	pg_size_pretty(pg_indexes_size(r)) AS indexes, pg_size_pretty(pg_table_size(r) - pg_relation_size(r)) AS toast,
+	pg_total_relation_size(r) AS total_bytes, pg_relation_size(r) AS data_bytes,
+	pg_indexes_size(r) AS index_bytes, pg_table_size(r) - pg_relation_size(r) AS toast_bytes
+FROM (SELECT %s AS r) t`, pgRegclass(opts)),
 		"Foreign Keys": fmt.Sprintf("%s WHERE constraint_type = 'FOREIGN KEY' AND tc.table_name = '%s' AND tc.table_schema = '%s'",
 			basicConstraintQuery,
 			opts.Table,
@@ -85,5 +209,18 @@ func (*Postgres) GetHelpers(opts *core.TableOptions) map[string]string {
 			opts.Table,
 			opts.Schema,
 		),
+		"Grants": postgresGrantsQuery(opts),
+		"Sequences": fmt.Sprintf(`SELECT sequencename, column_name, last_value, increment_by, min_value, max_value,
+	floor(CASE WHEN increment_by > 0 THEN (max_value::numeric - COALESCE(last_value, min_value)) / increment_by
+		ELSE (COALESCE(last_value, max_value)::numeric - min_value) / -increment_by END)::bigint AS remaining,
+	round(100.0 * CASE WHEN increment_by > 0 THEN COALESCE(last_value, min_value)::numeric - min_value
+		ELSE max_value::numeric - COALESCE(last_value, max_value) END / NULLIF(max_value::numeric - min_value, 0), 2) AS used_percent
+FROM (%s) s WHERE table_name = '%s' AND schemaname = '%s'`, postgresSequencesQuery, opts.Table, opts.Schema),
+		// row counts are the planner estimates (exact only after ANALYZE)
+		"Partitions": fmt.Sprintf(`SELECT n.nspname AS schema, c.relname AS partition, pg_get_expr(c.relpartbound, c.oid) AS bounds,
+	GREATEST(c.reltuples, 0)::bigint AS rows, pg_size_pretty(pg_total_relation_size(c.oid)) AS size
+FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE i.inhparent = %s
+ORDER BY pg_get_expr(c.relpartbound, c.oid) = 'DEFAULT', c.relname`, pgRegclass(opts)),
 	}
 }
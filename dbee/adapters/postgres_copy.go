@@ -0,0 +1,35 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// CopyOut streams rows of the query as csv. The driver doesn't support "COPY ... TO STDOUT",
+// so rows are written in their text form as they're received, which is the same output.
+func (c *postgresDriver) CopyOut(ctx context.Context, query string, w io.Writer, opts *core.CopyOptions) (int64, error) {
+	return c.c.CopyOut(ctx, query, w, opts)
+}
+
+// CopyIn loads csv rows into the table with "COPY ... FROM STDIN".
+func (c *postgresDriver) CopyIn(ctx context.Context, table *core.TableOptions, r io.Reader, opts *core.CopyOptions) (int64, error) {
+	return c.c.CopyIn(ctx, postgresCopyInQuery(table, opts), r)
+}
+
+func postgresCopyInQuery(table *core.TableOptions, opts *core.CopyOptions) string {
+	name := core.QuoteIdentifier(table.Table)
+	if table.Schema != "" {
+		name = core.QuoteIdentifier(table.Schema) + "." + name
+	}
+
+	null, header := "", false
+	if opts != nil {
+		null, header = opts.Null, opts.Header
+	}
+
+	return fmt.Sprintf("COPY %s FROM STDIN WITH (FORMAT csv, DELIMITER %s, NULL %s, HEADER %t)",
+		name, core.QuoteLiteral(opts.DelimiterOrDefault()), core.QuoteLiteral(null), header)
+}
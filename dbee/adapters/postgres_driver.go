@@ -3,26 +3,57 @@ package adapters
 import (
 	"bytes"
 	"context"
-	"database/sql"
 	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
 	nurl "net/url"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
 var (
-	_ core.Driver           = (*postgresDriver)(nil)
-	_ core.DatabaseSwitcher = (*postgresDriver)(nil)
+	_ core.Driver            = (*postgresDriver)(nil)
+	_ core.ContextStructurer = (*postgresDriver)(nil)
+	_ core.Copier            = (*postgresDriver)(nil)
+	_ core.CostExplainer     = (*postgresDriver)(nil)
+	_ core.CursorQuerier     = (*postgresDriver)(nil)
+	_ core.DDLProvider       = (*postgresDriver)(nil)
+	_ core.DatabaseSwitcher  = (*postgresDriver)(nil)
+	_ core.Describer         = (*postgresDriver)(nil)
+	_ core.ForeignKeyLister  = (*postgresDriver)(nil)
+	_ core.GeometryRenderer  = (*postgresDriver)(nil)
+	_ core.GrantLister       = (*postgresDriver)(nil)
+	_ core.IdentifierFolder  = (*postgresDriver)(nil)
+	_ core.IdleCloser        = (*postgresDriver)(nil)
+	_ core.IndexAdvisor      = (*postgresDriver)(nil)
+	_ core.Limiter           = (*postgresDriver)(nil)
+	_ core.Notifier          = (*postgresDriver)(nil)
+	_ core.ParamQuerier      = (*postgresDriver)(nil)
+	_ core.Peeker            = (*postgresDriver)(nil)
+	_ core.PlanExplainer     = (*postgresDriver)(nil)
+	_ core.ProcedureCaller   = (*postgresDriver)(nil)
+	_ core.ReplicaRouter     = (*postgresDriver)(nil)
+	_ core.SafeScanner       = (*postgresDriver)(nil)
+	_ core.SchemaDDLProvider = (*postgresDriver)(nil)
+	_ core.SchemaSwitcher    = (*postgresDriver)(nil)
+	_ core.SequenceLister    = (*postgresDriver)(nil)
+	_ core.StatementLogger   = (*postgresDriver)(nil)
+	_ core.TableSizer        = (*postgresDriver)(nil)
+	_ core.TopValuer         = (*postgresDriver)(nil)
+	_ core.UniqueKeyLister   = (*postgresDriver)(nil)
 )
 
 type postgresDriver struct {
 	c   *builders.Client
 	url *nurl.URL
+
+	renderGeometry bool
 }
 
 func (c *postgresDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
@@ -36,34 +67,146 @@ func (c *postgresDriver) Query(ctx context.Context, query string) (core.ResultSt
 	return c.c.QueryUntilNotEmpty(ctx, query)
 }
 
+func (c *postgresDriver) QueryCursor(ctx context.Context, query string, fetchSize int) (core.ResultStream, error) {
+	return c.c.QueryCursor(ctx, query, fetchSize)
+}
+
 func (c *postgresDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return c.c.ColumnsFromQuery(`
-		SELECT column_name, data_type
+		SELECT
+			column_name,
+			data_type,
+			character_maximum_length,
+			numeric_precision,
+			numeric_scale,
+			is_generated,
+			generation_expression
 		FROM information_schema.columns
 		WHERE
 			table_schema='%s' AND
 			table_name='%s'
+		ORDER BY ordinal_position
 		`, opts.Schema, opts.Table)
 }
 
+// postgresSequencesQuery lists sequences with the table columns owning them
+// (serial and identity columns).
+const postgresSequencesQuery = `
+		SELECT s.schemaname, s.sequencename, t.relname AS table_name, a.attname AS column_name,
+			s.last_value, s.increment_by, s.min_value, s.max_value
+		FROM pg_sequences s
+		JOIN pg_namespace n ON n.nspname = s.schemaname
+		JOIN pg_class seq ON seq.relnamespace = n.oid AND seq.relname = s.sequencename
+		LEFT JOIN pg_depend d ON d.objid = seq.oid AND d.classid = 'pg_class'::regclass
+			AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+		LEFT JOIN pg_class t ON t.oid = d.refobjid
+		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid`
+
+// postgresRoutinesQuery lists user defined procedures and functions.
+const postgresRoutinesQuery = `
+		SELECT DISTINCT n.nspname, p.proname, CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END
+			FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE p.prokind IN ('f', 'p') AND n.nspname NOT IN ('pg_catalog', 'information_schema')`
+
 func (c *postgresDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+func (c *postgresDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
 	query := `
 		SELECT table_schema, table_name, table_type FROM information_schema.tables UNION ALL
-		SELECT schemaname, matviewname, 'VIEW' FROM pg_matviews;
+		SELECT schemaname, matviewname, 'VIEW' FROM pg_matviews UNION ALL` +
+		postgresRoutinesQuery + ` UNION ALL
+		SELECT sequence_schema, sequence_name, 'SEQUENCE' FROM information_schema.sequences;
 	`
 
-	rows, err := c.Query(context.TODO(), query)
+	rows, err := c.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return getPGStructure(rows)
+	structure, err := getPGStructure(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// servers without declarative partitioning (before postgres 10) keep the flat structure
+	rows, err = c.Query(ctx, postgresPartitionsQuery)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return structure, nil
+	}
+
+	return builders.NestPartitions(structure, rows)
 }
 
+// postgresPartitionsQuery lists partitions with their parent tables.
+const postgresPartitionsQuery = `
+		SELECT pn.nspname, p.relname, cn.nspname, c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_namespace cn ON cn.oid = c.relnamespace
+		JOIN pg_class p ON p.oid = i.inhparent
+		JOIN pg_namespace pn ON pn.oid = p.relnamespace
+		WHERE c.relispartition`
+
 func (c *postgresDriver) Close() {
 	c.c.Close()
 }
 
+func (c *postgresDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *postgresDriver) IdentifierCase() core.IdentifierCase {
+	return core.IdentifierCaseLower
+}
+
+func (c *postgresDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *postgresDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderDollar
+}
+
+func (c *postgresDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteDouble, core.LimitDialectLimit)
+}
+
+func (c *postgresDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return c.c.Peek(ctx, query)
+}
+
+func (c *postgresDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *postgresDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+// AddReplica routes read-only statements to the database of the url as well.
+func (c *postgresDriver) AddReplica(url string) error {
+	db, u, err := openPostgresURL(url)
+	if err != nil {
+		return err
+	}
+
+	c.c.AddReplica(u.Host, db)
+	return nil
+}
+
+func (c *postgresDriver) SetIdleTimeout(timeout time.Duration) {
+	c.c.SetIdleTimeout(timeout)
+}
+
+func (c *postgresDriver) SetRenderGeometry(enabled bool) {
+	c.renderGeometry = enabled
+}
+
 func (c *postgresDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT current_database(), datname FROM pg_database
@@ -92,7 +235,7 @@ func (c *postgresDriver) ListDatabases() (current string, available []string, er
 
 func (c *postgresDriver) SelectDatabase(name string) error {
 	c.url.Path = fmt.Sprintf("/%s", name)
-	db, err := sql.Open("postgres", c.url.String())
+	db, err := openPostgres(c.url)
 	if err != nil {
 		return fmt.Errorf("unable to switch databases: %w", err)
 	}
@@ -102,9 +245,65 @@ func (c *postgresDriver) SelectDatabase(name string) error {
 	return nil
 }
 
+// ListSchemas lists schemas of the current database, except the internal
+// toast and temporary ones.
+func (c *postgresDriver) ListSchemas() (current string, available []string, err error) {
+	query := `
+		SELECT COALESCE(current_schema(), ''), schema_name FROM information_schema.schemata
+		WHERE schema_name NOT LIKE 'pg\_toast%'
+		AND schema_name NOT LIKE 'pg\_temp\_%'
+		AND schema_name IS DISTINCT FROM current_schema()
+		ORDER BY schema_name;
+	`
+
+	rows, err := c.Query(context.TODO(), query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return "", nil, err
+		}
+
+		// We know for a fact there are 2 string fields (see query above)
+		current = row[0].(string)
+		available = append(available, row[1].(string))
+	}
+
+	return current, available, nil
+}
+
+// SelectSchema sets the search_path of new sessions to the schema (followed by public,
+// where extensions are usually installed). Like switching databases, it reconnects,
+// so the setting applies to all pooled connections.
+func (c *postgresDriver) SelectSchema(name string) error {
+	searchPath := pq.QuoteIdentifier(name)
+	if name != "public" {
+		searchPath += ", public"
+	}
+
+	u := *c.url
+	params := u.Query()
+	params.Set("search_path", searchPath)
+	u.RawQuery = params.Encode()
+
+	db, err := openPostgres(&u)
+	if err != nil {
+		return fmt.Errorf("unable to switch schemas: %w", err)
+	}
+
+	c.url.RawQuery = u.RawQuery
+	c.c.Swap(db)
+
+	return nil
+}
+
 // getPGStructure fetches the layout from the postgres database.
 // rows is at least 3 column wide result
-func getPGStructure(rows core.ResultStream) ([]*core.Structure, error) {
+// It fails with ctx.Err() if ctx is cancelled while iterating.
+func getPGStructure(ctx context.Context, rows core.ResultStream) ([]*core.Structure, error) {
 	children := make(map[string][]*core.Structure)
 
 	for rows.HasNext() {
@@ -124,6 +323,9 @@ func getPGStructure(rows core.ResultStream) ([]*core.Structure, error) {
 			Type:   getPGStructureType(tableType),
 		})
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	var structure []*core.Structure
 
@@ -146,6 +348,12 @@ func getPGStructureType(typ string) core.StructureType {
 		return core.StructureTypeTable
 	case "VIEW", "SYSTEM VIEW":
 		return core.StructureTypeView
+	case "PROCEDURE":
+		return core.StructureTypeProcedure
+	case "FUNCTION":
+		return core.StructureTypeFunction
+	case "SEQUENCE":
+		return core.StructureTypeSequence
 	default:
 		return core.StructureTypeNone
 	}
@@ -201,3 +409,390 @@ func (pj *postgresJSONResponse) GobDecode(buf []byte) error {
 	}
 	return err
 }
+
+func (c *postgresDriver) Describe(ctx context.Context, opts *core.TableOptions) (core.ResultStream, error) {
+	var query string
+
+	switch opts.Materialization {
+	case core.StructureTypeTable, core.StructureTypePartition:
+		query = fmt.Sprintf(`
+			SELECT column_name, data_type, is_nullable, column_default
+			FROM information_schema.columns
+			WHERE table_schema = '%s' AND table_name = '%s'
+			ORDER BY ordinal_position`, opts.Schema, opts.Table)
+	case core.StructureTypeView:
+		query = fmt.Sprintf(`SELECT pg_get_viewdef(%s, true) AS definition`, pgRegclass(opts))
+	case core.StructureTypeProcedure, core.StructureTypeFunction:
+		// returns all overloads
+		query = fmt.Sprintf(`
+			SELECT p.proname AS name, pg_get_function_arguments(p.oid) AS arguments,
+				pg_get_function_result(p.oid) AS returns, pg_get_functiondef(p.oid) AS definition
+			FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE p.prokind IN ('f', 'p') AND n.nspname = '%s' AND p.proname = '%s'`, opts.Schema, opts.Table)
+	case core.StructureTypeSequence:
+		query = fmt.Sprintf(`
+			SELECT * FROM pg_sequences
+			WHERE schemaname = '%s' AND sequencename = '%s'`, opts.Schema, opts.Table)
+	default:
+		return nil, fmt.Errorf("cannot describe object of type %q", opts.Materialization)
+	}
+
+	return c.Query(ctx, query)
+}
+
+func (c *postgresDriver) ListProcedures() ([]*core.Structure, error) {
+	rows, err := c.Query(context.TODO(), postgresRoutinesQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return getPGStructure(context.Background(), rows)
+}
+
+func (c *postgresDriver) ProcedureParameters(opts *core.TableOptions) ([]*core.Column, error) {
+	// only the first overload is considered,
+	// procedures also need arguments (NULL) for OUT parameters
+	return c.c.ColumnsFromQuery(`
+		SELECT COALESCE(NULLIF(p.parameter_name, ''), '$' || p.ordinal_position), p.data_type
+		FROM information_schema.parameters p
+		JOIN information_schema.routines r ON r.specific_schema = p.specific_schema AND r.specific_name = p.specific_name
+		WHERE r.specific_schema = '%s' AND r.specific_name = (
+				SELECT min(specific_name) FROM information_schema.routines
+				WHERE routine_schema = '%s' AND routine_name = '%s'
+			) AND (p.parameter_mode <> 'OUT' OR r.routine_type = 'PROCEDURE')
+		ORDER BY p.ordinal_position`, opts.Schema, opts.Schema, opts.Table)
+}
+
+func (c *postgresDriver) CallProcedure(ctx context.Context, opts *core.TableOptions, args []any) (core.ResultStream, error) {
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	var query string
+	switch opts.Materialization {
+	case core.StructureTypeProcedure:
+		query = fmt.Sprintf("CALL %s.%s(%s)", core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table), strings.Join(placeholders, ", "))
+	case core.StructureTypeFunction:
+		query = fmt.Sprintf("SELECT * FROM %s.%s(%s)", core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table), strings.Join(placeholders, ", "))
+	default:
+		return nil, fmt.Errorf("cannot call object of type %q", opts.Materialization)
+	}
+
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+// pgRegclass returns an sql expression that resolves the table to its oid.
+func pgRegclass(opts *core.TableOptions) string {
+	return fmt.Sprintf("format('%%I.%%I', '%s', '%s')::regclass", opts.Schema, opts.Table)
+}
+
+// UniqueKeys lists columns of unique indexes (including the primary key) of the table.
+// Partial and expression indexes are skipped. Unqualified tables are resolved with the search path.
+func (c *postgresDriver) UniqueKeys(opts *core.TableOptions) ([][]string, error) {
+	regclass := pgRegclass(opts)
+	if opts.Schema == "" {
+		regclass = fmt.Sprintf("quote_ident('%s')::regclass", opts.Table)
+	}
+
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT i.indexrelid::regclass::text, a.attname
+		FROM pg_index i
+		CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, n)
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		WHERE i.indrelid = %s AND i.indisunique AND i.indpred IS NULL AND i.indexprs IS NULL
+		ORDER BY NOT i.indisprimary, 1, k.n`, regclass))
+	if err != nil {
+		return nil, err
+	}
+
+	return uniqueKeysFromResultStream(rows)
+}
+
+func (c *postgresDriver) DDL(opts *core.TableOptions) (string, error) {
+	if opts.Materialization == core.StructureTypeView {
+		return c.viewDDL(opts)
+	}
+
+	def, err := c.TableDefinition(opts)
+	if err != nil {
+		return "", err
+	}
+
+	ddl := def.Create
+	for _, stmt := range append(def.Constraints, def.Indexes...) {
+		ddl += "\n" + stmt
+	}
+
+	return ddl, nil
+}
+
+// postgresUserSchema filters out system schemas of the namespace aliased as n.
+const postgresUserSchema = `n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg\_%'`
+
+// SchemaPrelude returns schemas, user defined types (enums, domains and composite types)
+// and sequences that are not backing identity columns. Types of extensions are skipped.
+func (c *postgresDriver) SchemaPrelude() ([]string, error) {
+	notExtension := `NOT EXISTS (SELECT 1 FROM pg_depend e WHERE e.objid = t.oid AND e.deptype = 'e')`
+
+	return c.statements(fmt.Sprintf(`
+		SELECT stmt FROM (
+			SELECT 0 AS kind, n.nspname AS name, format('CREATE SCHEMA IF NOT EXISTS %%I;', n.nspname) AS stmt
+			FROM pg_namespace n
+			WHERE %[1]s
+			UNION ALL
+			SELECT 1, n.nspname || '.' || t.typname, format('CREATE TYPE %%I.%%I AS ENUM (%%s);', n.nspname, t.typname,
+				string_agg(quote_literal(e.enumlabel), ', ' ORDER BY e.enumsortorder))
+			FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			JOIN pg_enum e ON e.enumtypid = t.oid
+			WHERE %[1]s AND %[2]s
+			GROUP BY n.nspname, t.typname
+			UNION ALL
+			SELECT 2, n.nspname || '.' || t.typname, format('CREATE DOMAIN %%I.%%I AS %%s', n.nspname, t.typname, format_type(t.typbasetype, t.typtypmod))
+				|| COALESCE(' DEFAULT ' || t.typdefault, '')
+				|| CASE WHEN t.typnotnull THEN ' NOT NULL' ELSE '' END
+				|| COALESCE((SELECT string_agg(' CONSTRAINT ' || quote_ident(con.conname) || ' ' || pg_get_constraintdef(con.oid), '' ORDER BY con.conname)
+					FROM pg_constraint con WHERE con.contypid = t.oid), '') || ';'
+			FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			WHERE t.typtype = 'd' AND %[1]s AND %[2]s
+			UNION ALL
+			SELECT 3, n.nspname || '.' || t.typname, format('CREATE TYPE %%I.%%I AS (%%s);', n.nspname, t.typname,
+				string_agg(format('%%I %%s', a.attname, format_type(a.atttypid, a.atttypmod)), ', ' ORDER BY a.attnum))
+			FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			JOIN pg_class cl ON cl.oid = t.typrelid AND cl.relkind = 'c'
+			JOIN pg_attribute a ON a.attrelid = cl.oid AND a.attnum > 0 AND NOT a.attisdropped
+			WHERE %[1]s AND %[2]s
+			GROUP BY n.nspname, t.typname
+			UNION ALL
+			SELECT 4, s.schemaname || '.' || s.sequencename,
+				format('CREATE SEQUENCE %%I.%%I AS %%s INCREMENT BY %%s MINVALUE %%s MAXVALUE %%s START WITH %%s CACHE %%s%%s;',
+					s.schemaname, s.sequencename, s.data_type, s.increment_by, s.min_value, s.max_value, s.start_value, s.cache_size,
+					CASE WHEN s.cycle THEN ' CYCLE' ELSE '' END)
+			FROM pg_sequences s
+			JOIN pg_namespace n ON n.nspname = s.schemaname
+			JOIN pg_class seq ON seq.relnamespace = n.oid AND seq.relname = s.sequencename
+			WHERE %[1]s AND NOT EXISTS (
+				SELECT 1 FROM pg_depend d WHERE d.objid = seq.oid AND d.classid = 'pg_class'::regclass AND d.deptype IN ('i', 'e'))
+		) p
+		ORDER BY kind, name`, postgresUserSchema, notExtension))
+}
+
+// TableDefinition returns the CREATE TABLE statement with all constraints except foreign keys,
+// which are returned as ALTER TABLE statements.
+func (c *postgresDriver) TableDefinition(opts *core.TableOptions) (*core.TableDefinition, error) {
+	table := fmt.Sprintf("%s.%s", core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table))
+
+	var lines []string
+
+	// columns
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT quote_ident(a.attname), format_type(a.atttypid, a.atttypmod), a.attnotnull, pg_get_expr(d.adbin, d.adrelid),
+			a.attidentity::text, a.attgenerated::text
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = %s AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, pgRegclass(opts)))
+	if err != nil {
+		return nil, err
+	}
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		line := fmt.Sprintf("  %s %s", row[0], row[1])
+		if def, ok := row[3].(string); ok && def != "" {
+			if generated, _ := row[5].(string); generated == "s" {
+				line += fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", def)
+			} else {
+				line += " DEFAULT " + def
+			}
+		}
+		switch identity, _ := row[4].(string); identity {
+		case "a":
+			line += " GENERATED ALWAYS AS IDENTITY"
+		case "d":
+			line += " GENERATED BY DEFAULT AS IDENTITY"
+		}
+		if notNull, ok := row[2].(bool); ok && notNull {
+			line += " NOT NULL"
+		}
+		lines = append(lines, line)
+	}
+
+	// constraints (primary keys first), foreign keys are added after all tables exist
+	rows, err = c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT quote_ident(conname), pg_get_constraintdef(oid), contype = 'f'
+		FROM pg_constraint
+		WHERE conrelid = %s
+		ORDER BY CASE contype WHEN 'p' THEN 0 WHEN 'u' THEN 1 WHEN 'c' THEN 2 ELSE 3 END, conname`, pgRegclass(opts)))
+	if err != nil {
+		return nil, err
+	}
+	def := &core.TableDefinition{}
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if foreign, _ := row[2].(bool); foreign {
+			def.Constraints = append(def.Constraints, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", table, row[0], row[1]))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  CONSTRAINT %s %s", row[0], row[1]))
+	}
+
+	def.Create = fmt.Sprintf("CREATE TABLE %s (\n%s\n);", table, strings.Join(lines, ",\n"))
+
+	// indexes that are not backing a constraint
+	def.Indexes, err = c.statements(fmt.Sprintf(`
+		SELECT indexdef || ';'
+		FROM pg_indexes
+		WHERE schemaname = '%s' AND tablename = '%s'
+			AND indexname NOT IN (SELECT conname FROM pg_constraint WHERE conrelid = %s)
+		ORDER BY indexname`, opts.Schema, opts.Table, pgRegclass(opts)))
+	if err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// ViewDependencies lists views and materialized views referenced by the rewrite rule of the view.
+func (c *postgresDriver) ViewDependencies(opts *core.TableOptions) ([]*core.TableOptions, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT DISTINCT n.nspname, cl.relname
+		FROM pg_rewrite r
+		JOIN pg_depend d ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid AND d.refclassid = 'pg_class'::regclass
+		JOIN pg_class cl ON cl.oid = d.refobjid
+		JOIN pg_namespace n ON n.oid = cl.relnamespace
+		WHERE r.ev_class = %s AND cl.oid <> r.ev_class AND cl.relkind IN ('v', 'm')
+		ORDER BY 1, 2`, pgRegclass(opts)))
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []*core.TableOptions
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		schema, _ := row[0].(string)
+		table, _ := row[1].(string)
+		deps = append(deps, &core.TableOptions{Schema: schema, Table: table, Materialization: core.StructureTypeView})
+	}
+
+	return deps, nil
+}
+
+// statements returns the first column of every row of the query.
+func (c *postgresDriver) statements(query string) ([]string, error) {
+	rows, err := c.c.Query(context.TODO(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if stmt, ok := row[0].(string); ok {
+			out = append(out, stmt)
+		}
+	}
+
+	return out, nil
+}
+
+func (c *postgresDriver) viewDDL(opts *core.TableOptions) (string, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT CASE relkind WHEN 'm' THEN 'MATERIALIZED VIEW' ELSE 'VIEW' END, pg_get_viewdef(oid, true)
+		FROM pg_class
+		WHERE oid = %s`, pgRegclass(opts)))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return "", fmt.Errorf("view %s.%s not found", core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table))
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("CREATE %s %s.%s AS\n%s", row[0], core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table), row[1]), nil
+}
+
+// TableSize reports TOAST storage (with free space and visibility maps) as overhead.
+func (c *postgresDriver) TableSize(opts *core.TableOptions) (*core.TableSize, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT pg_total_relation_size(r), pg_relation_size(r), pg_indexes_size(r), pg_table_size(r) - pg_relation_size(r)
+		FROM (SELECT %s AS r) t`, pgRegclass(opts)))
+	if err != nil {
+		return nil, err
+	}
+
+	return tableSizeFromResultStream(rows)
+}
+
+func (c *postgresDriver) Sequences() ([]*core.Sequence, error) {
+	rows, err := c.c.Query(context.TODO(), postgresSequencesQuery+" ORDER BY 1, 2")
+	if err != nil {
+		return nil, err
+	}
+
+	return sequencesFromResultStream(rows)
+}
+
+// postgresGrantsQuery lists privileges on the table from its access control list
+// (information_schema only shows grants related to the current user's roles).
+// A missing list means the default privileges of the owner.
+func postgresGrantsQuery(opts *core.TableOptions) string {
+	return fmt.Sprintf(`SELECT COALESCE(r.rolname, 'PUBLIC') AS grantee, a.privilege_type, a.is_grantable, 'TABLE' AS level
+FROM pg_class c
+CROSS JOIN LATERAL aclexplode(COALESCE(c.relacl, acldefault('r', c.relowner))) a
+LEFT JOIN pg_roles r ON r.oid = a.grantee
+WHERE c.oid = %s
+ORDER BY 1, 2`, pgRegclass(opts))
+}
+
+func (c *postgresDriver) Grants(opts *core.TableOptions) ([]*core.Grant, error) {
+	return c.c.GrantsFromQuery(postgresGrantsQuery(opts))
+}
+
+// EffectiveGrants checks every table privilege of the current user, so privileges
+// inherited from roles (or of superusers) are included as well.
+func (c *postgresDriver) EffectiveGrants(opts *core.TableOptions) ([]*core.Grant, error) {
+	return c.c.GrantsFromQuery(fmt.Sprintf(`
+		SELECT current_user AS grantee, p.privilege_type,
+			has_table_privilege(t.r, p.privilege_type || ' WITH GRANT OPTION') AS is_grantable, NULL AS level
+		FROM (SELECT %s AS r) t
+		CROSS JOIN unnest(ARRAY['SELECT', 'INSERT', 'UPDATE', 'DELETE', 'TRUNCATE', 'REFERENCES', 'TRIGGER']) AS p(privilege_type)
+		WHERE has_table_privilege(t.r, p.privilege_type)`, pgRegclass(opts)))
+}
+
+func (c *postgresDriver) ForeignKeys(opts *core.TableOptions) ([]*core.ForeignKey, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT tc.constraint_name, kcu.column_name, ccu.table_schema, ccu.table_name, ccu.column_name
+		FROM information_schema.table_constraints AS tc
+		JOIN information_schema.key_column_usage AS kcu
+			ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+		JOIN information_schema.constraint_column_usage AS ccu
+			ON ccu.constraint_name = tc.constraint_name
+		WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = '%s' AND tc.table_name = '%s'`,
+		opts.Schema, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return foreignKeysFromResultStream(rows)
+}
@@ -0,0 +1,176 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// postgresPlanNode is a single node of "EXPLAIN (FORMAT JSON)" output.
+type postgresPlanNode struct {
+	NodeType     string              `json:"Node Type"`
+	RelationName string              `json:"Relation Name"`
+	IndexName    string              `json:"Index Name"`
+	Filter       string              `json:"Filter"`
+	TotalCost    float64             `json:"Total Cost"`
+	PlanRows     int64               `json:"Plan Rows"`
+	ActualRows   *float64            `json:"Actual Rows"`
+	ActualLoops  *float64            `json:"Actual Loops"`
+	Plans        []*postgresPlanNode `json:"Plans"`
+}
+
+func (c *postgresDriver) ExplainCost(ctx context.Context, query string, analyze bool) (*core.ExplainSummary, error) {
+	plan, err := c.explainJSON(ctx, query, analyze)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizePostgresPlan(plan)
+}
+
+func (c *postgresDriver) ExplainPlan(ctx context.Context, query string, analyze bool) (*core.PlanNode, error) {
+	plan, err := c.explainJSON(ctx, query, analyze)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parsePostgresPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return root.toPlanNode(), nil
+}
+
+// explainJSON returns the output of "EXPLAIN (FORMAT JSON)" for the query.
+func (c *postgresDriver) explainJSON(ctx context.Context, query string, analyze bool) ([]byte, error) {
+	explain := "EXPLAIN (FORMAT JSON) "
+	if analyze {
+		explain = "EXPLAIN (ANALYZE, FORMAT JSON) "
+	}
+
+	rows, err := c.c.Query(ctx, explain+query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return nil, errors.New("no query plan returned")
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return nil, err
+	}
+	if len(row) < 1 {
+		return nil, errors.New("no query plan returned")
+	}
+
+	switch v := row[0].(type) {
+	case *postgresJSONResponse:
+		return v.value, nil
+	case []byte:
+		return v, nil
+	default:
+		return []byte(fmt.Sprint(v)), nil
+	}
+}
+
+// parsePostgresPlan parses the json plan and returns its root node.
+func parsePostgresPlan(plan []byte) (*postgresPlanNode, error) {
+	var parsed []struct {
+		Plan *postgresPlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &parsed); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if len(parsed) < 1 || parsed[0].Plan == nil {
+		return nil, errors.New("empty query plan")
+	}
+
+	return parsed[0].Plan, nil
+}
+
+// summarizePostgresPlan parses the json plan and extracts the top-line estimates.
+func summarizePostgresPlan(plan []byte) (*core.ExplainSummary, error) {
+	root, err := parsePostgresPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &core.ExplainSummary{
+		TotalCost: root.TotalCost,
+		PlanRows:  root.PlanRows,
+	}
+
+	summary.ActualRows = root.actualRows()
+
+	node, cost := root.mostExpensive()
+	summary.ExpensiveNode = node.description()
+	summary.ExpensiveNodeCost = cost
+
+	return summary, nil
+}
+
+// selfCost is the cost of the node without the cost of its children.
+func (n *postgresPlanNode) selfCost() float64 {
+	cost := n.TotalCost
+	for _, child := range n.Plans {
+		cost -= child.TotalCost
+	}
+	return max(cost, 0)
+}
+
+// mostExpensive returns the node in the subtree with the highest self cost.
+func (n *postgresPlanNode) mostExpensive() (*postgresPlanNode, float64) {
+	node, cost := n, n.selfCost()
+	for _, child := range n.Plans {
+		if cn, cc := child.mostExpensive(); cc > cost {
+			node, cost = cn, cc
+		}
+	}
+	return node, cost
+}
+
+func (n *postgresPlanNode) description() string {
+	desc := n.NodeType
+	if n.IndexName != "" {
+		desc += " using " + n.IndexName
+	}
+	if n.RelationName != "" {
+		desc += " on " + n.RelationName
+	}
+	return desc
+}
+
+// actualRows returns the total number of rows over all loops (nil if not analyzed).
+func (n *postgresPlanNode) actualRows() *int64 {
+	if n.ActualRows == nil {
+		return nil
+	}
+
+	actual := *n.ActualRows
+	if n.ActualLoops != nil {
+		actual *= *n.ActualLoops
+	}
+	rows := int64(actual)
+	return &rows
+}
+
+func (n *postgresPlanNode) toPlanNode() *core.PlanNode {
+	node := &core.PlanNode{
+		Type:       n.NodeType,
+		Relation:   n.RelationName,
+		Index:      n.IndexName,
+		Cost:       n.TotalCost,
+		Rows:       n.PlanRows,
+		ActualRows: n.actualRows(),
+	}
+	for _, child := range n.Plans {
+		node.Children = append(node.Children, child.toPlanNode())
+	}
+	return node
+}
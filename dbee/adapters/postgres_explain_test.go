@@ -0,0 +1,163 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// plan of "EXPLAIN (ANALYZE, FORMAT JSON)" for a join with aggregation
+const postgresAnalyzedPlanFixture = `[
+  {
+    "Plan": {
+      "Node Type": "Aggregate",
+      "Strategy": "Hashed",
+      "Startup Cost": 1250.5,
+      "Total Cost": 1275.5,
+      "Plan Rows": 100,
+      "Plan Width": 40,
+      "Actual Startup Time": 12.1,
+      "Actual Total Time": 12.9,
+      "Actual Rows": 87,
+      "Actual Loops": 1,
+      "Plans": [
+        {
+          "Node Type": "Hash Join",
+          "Parent Relationship": "Outer",
+          "Join Type": "Inner",
+          "Startup Cost": 30.5,
+          "Total Cost": 1200.25,
+          "Plan Rows": 10000,
+          "Plan Width": 36,
+          "Actual Rows": 9950,
+          "Actual Loops": 1,
+          "Plans": [
+            {
+              "Node Type": "Seq Scan",
+              "Parent Relationship": "Outer",
+              "Relation Name": "orders",
+              "Alias": "o",
+              "Startup Cost": 0.0,
+              "Total Cost": 1000.0,
+              "Plan Rows": 10000,
+              "Plan Width": 16,
+              "Actual Rows": 10000,
+              "Actual Loops": 1
+            },
+            {
+              "Node Type": "Hash",
+              "Parent Relationship": "Inner",
+              "Startup Cost": 20.0,
+              "Total Cost": 20.0,
+              "Plan Rows": 800,
+              "Plan Width": 24,
+              "Actual Rows": 800,
+              "Actual Loops": 1,
+              "Plans": [
+                {
+                  "Node Type": "Index Scan",
+                  "Parent Relationship": "Outer",
+                  "Index Name": "customers_pkey",
+                  "Relation Name": "customers",
+                  "Alias": "c",
+                  "Startup Cost": 0.28,
+                  "Total Cost": 20.0,
+                  "Plan Rows": 800,
+                  "Plan Width": 24,
+                  "Actual Rows": 800,
+                  "Actual Loops": 1
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    },
+    "Planning Time": 0.4,
+    "Triggers": [],
+    "Execution Time": 13.2
+  }
+]`
+
+func TestSummarizePostgresPlan(t *testing.T) {
+	r := require.New(t)
+
+	summary, err := summarizePostgresPlan([]byte(postgresAnalyzedPlanFixture))
+	r.NoError(err)
+
+	r.Equal(1275.5, summary.TotalCost)
+	r.Equal(int64(100), summary.PlanRows)
+	r.NotNil(summary.ActualRows)
+	r.Equal(int64(87), *summary.ActualRows)
+	r.Equal("Seq Scan on orders", summary.ExpensiveNode)
+	r.Equal(1000.0, summary.ExpensiveNodeCost)
+}
+
+func TestSummarizePostgresPlan_NotAnalyzed(t *testing.T) {
+	r := require.New(t)
+
+	plan := `[{"Plan": {"Node Type": "Index Only Scan", "Index Name": "users_pkey", "Relation Name": "users",
+		"Startup Cost": 0.29, "Total Cost": 8.3, "Plan Rows": 1, "Plan Width": 4}}]`
+
+	summary, err := summarizePostgresPlan([]byte(plan))
+	r.NoError(err)
+
+	r.Equal(8.3, summary.TotalCost)
+	r.Equal(int64(1), summary.PlanRows)
+	r.Nil(summary.ActualRows)
+	r.Equal("Index Only Scan using users_pkey on users", summary.ExpensiveNode)
+
+	_, err = summarizePostgresPlan([]byte(`[]`))
+	r.Error(err)
+}
+
+func TestParsePostgresPlan_Tree(t *testing.T) {
+	r := require.New(t)
+
+	root, err := parsePostgresPlan([]byte(postgresAnalyzedPlanFixture))
+	r.NoError(err)
+
+	rows := func(n int64) *int64 { return &n }
+
+	r.Equal(&core.PlanNode{
+		Type:       "Aggregate",
+		Cost:       1275.5,
+		Rows:       100,
+		ActualRows: rows(87),
+		Children: []*core.PlanNode{
+			{
+				Type:       "Hash Join",
+				Cost:       1200.25,
+				Rows:       10000,
+				ActualRows: rows(9950),
+				Children: []*core.PlanNode{
+					{
+						Type:       "Seq Scan",
+						Relation:   "orders",
+						Cost:       1000,
+						Rows:       10000,
+						ActualRows: rows(10000),
+					},
+					{
+						Type:       "Hash",
+						Cost:       20,
+						Rows:       800,
+						ActualRows: rows(800),
+						Children: []*core.PlanNode{
+							{
+								Type:       "Index Scan",
+								Relation:   "customers",
+								Index:      "customers_pkey",
+								Cost:       20,
+								Rows:       800,
+								ActualRows: rows(800),
+							},
+						},
+					},
+				},
+			},
+		},
+	}, root.toPlanNode())
+}
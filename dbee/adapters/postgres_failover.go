@@ -0,0 +1,177 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	nurl "net/url"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// postgresSessionAttrs maps supported values of "target_session_attrs" to queries
+// returning true if the session matches them.
+var postgresSessionAttrs = map[string]string{
+	"any":        "",
+	"read-write": "SELECT current_setting('transaction_read_only') = 'off'",
+	"read-only":  "SELECT current_setting('transaction_read_only') = 'on'",
+	"primary":    "SELECT NOT pg_is_in_recovery()",
+	"standby":    "SELECT pg_is_in_recovery()",
+}
+
+var _ driver.Connector = (*postgresFailoverConnector)(nil)
+
+// postgresFailoverConnector connects to the first of multiple hosts which accepts the
+// connection and matches "target_session_attrs", like libpq does. Hosts are tried in
+// order for every new connection, so broken connections are replaced by connections
+// to the next available host.
+type postgresFailoverConnector struct {
+	hosts        []string
+	sessionAttrs string
+	connect      func(ctx context.Context, host string) (driver.Conn, error)
+}
+
+// newPostgresFailoverConnector creates a connector for a url with comma separated
+// hosts (e.g. postgres://user@a:5432,b:5432/db?target_session_attrs=read-write).
+func newPostgresFailoverConnector(u *nurl.URL) (*postgresFailoverConnector, error) {
+	params := u.Query()
+	sessionAttrs := params.Get("target_session_attrs")
+	if sessionAttrs == "" {
+		sessionAttrs = "any"
+	}
+	if _, ok := postgresSessionAttrs[sessionAttrs]; !ok {
+		return nil, fmt.Errorf("unsupported target_session_attrs: %q", sessionAttrs)
+	}
+	// lib/pq would send it to the server as a runtime parameter
+	params.Del("target_session_attrs")
+
+	hosts := strings.Split(u.Host, ",")
+	connectors := make(map[string]*pq.Connector, len(hosts))
+	for _, host := range hosts {
+		if host == "" {
+			return nil, fmt.Errorf("empty host in host list: %q", u.Host)
+		}
+
+		hostURL := *u
+		hostURL.Host = host
+		hostURL.RawQuery = params.Encode()
+
+		connector, err := pq.NewConnector(hostURL.String())
+		if err != nil {
+			return nil, fmt.Errorf("pq.NewConnector: %w", err)
+		}
+		connectors[host] = connector
+	}
+
+	return &postgresFailoverConnector{
+		hosts:        hosts,
+		sessionAttrs: sessionAttrs,
+		connect: func(ctx context.Context, host string) (driver.Conn, error) {
+			return connectors[host].Connect(ctx)
+		},
+	}, nil
+}
+
+func (c *postgresFailoverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, _, err := c.connectHost(ctx)
+	return conn, err
+}
+
+// connectHost returns a connection to the first available host and the host itself.
+func (c *postgresFailoverConnector) connectHost(ctx context.Context) (driver.Conn, string, error) {
+	var errs []error
+	for _, host := range c.hosts {
+		conn, err := c.connect(ctx, host)
+		if err == nil {
+			err = c.checkSession(ctx, conn)
+			if err == nil {
+				return conn, host, nil
+			}
+			_ = conn.Close()
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", host, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, "", fmt.Errorf("no host accepted the connection: %w", errors.Join(errs...))
+}
+
+// checkSession fails if the session of conn doesn't match target_session_attrs.
+func (c *postgresFailoverConnector) checkSession(ctx context.Context, conn driver.Conn) error {
+	query := postgresSessionAttrs[c.sessionAttrs]
+	if query == "" {
+		return nil
+	}
+
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return errors.New("connection doesn't support queries")
+	}
+
+	rows, err := queryer.QueryContext(ctx, query, nil)
+	if err != nil {
+		return fmt.Errorf("failed checking session attributes: %w", err)
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		return fmt.Errorf("failed checking session attributes: %w", err)
+	}
+	if matches, _ := dest[0].(bool); !matches {
+		return fmt.Errorf("session is not %s", c.sessionAttrs)
+	}
+
+	return nil
+}
+
+func (c *postgresFailoverConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// openPostgres opens a postgres database handle. If the url lists multiple hosts or
+// sets "target_session_attrs", connections fail over between hosts.
+func openPostgres(u *nurl.URL) (*sql.DB, error) {
+	if !strings.Contains(u.Host, ",") && !u.Query().Has("target_session_attrs") {
+		return sql.Open("postgres", u.String())
+	}
+
+	connector, err := newPostgresFailoverConnector(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.OpenDB(connector), nil
+}
+
+// postgresSingleHostURL returns the url of the first available host, for connections
+// which can't fail over on their own (e.g. listeners).
+func postgresSingleHostURL(ctx context.Context, u *nurl.URL) (string, error) {
+	if !strings.Contains(u.Host, ",") && !u.Query().Has("target_session_attrs") {
+		return u.String(), nil
+	}
+
+	connector, err := newPostgresFailoverConnector(u)
+	if err != nil {
+		return "", err
+	}
+	conn, host, err := connector.connectHost(ctx)
+	if err != nil {
+		return "", err
+	}
+	_ = conn.Close()
+
+	hostURL := *u
+	hostURL.Host = host
+	params := u.Query()
+	params.Del("target_session_attrs")
+	hostURL.RawQuery = params.Encode()
+
+	return hostURL.String(), nil
+}
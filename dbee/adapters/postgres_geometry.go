@@ -0,0 +1,257 @@
+package adapters
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EWKB type flags (PostGIS extension of WKB)
+const (
+	ewkbFlagZ    = 0x80000000
+	ewkbFlagM    = 0x40000000
+	ewkbFlagSRID = 0x20000000
+)
+
+var errInvalidWKB = errors.New("invalid wkb")
+
+// ewkbHexToWKT converts a hex encoded (E)WKB value, which is how PostGIS sends
+// geometry and geography values, to WKT. If the value has an SRID, it is
+// prepended in EWKT style (e.g. "SRID=4326;POINT(1 2)").
+func ewkbHexToWKT(value string) (string, error) {
+	raw, err := hex.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	r := &wkbReader{buf: raw}
+	srid, wkt, err := r.readGeometry()
+	if err != nil {
+		return "", err
+	}
+	if len(r.buf) > 0 {
+		return "", fmt.Errorf("%w: %d trailing bytes", errInvalidWKB, len(r.buf))
+	}
+
+	if srid != 0 {
+		return fmt.Sprintf("SRID=%d;%s", srid, wkt), nil
+	}
+	return wkt, nil
+}
+
+// wkbReader consumes the wkb buffer.
+type wkbReader struct {
+	buf   []byte
+	order binary.ByteOrder
+}
+
+func (r *wkbReader) take(n int) ([]byte, error) {
+	if len(r.buf) < n {
+		return nil, fmt.Errorf("%w: unexpected end of input", errInvalidWKB)
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b, nil
+}
+
+func (r *wkbReader) readUint32() (uint32, error) {
+	b, err := r.take(4)
+	if err != nil {
+		return 0, err
+	}
+	return r.order.Uint32(b), nil
+}
+
+func (r *wkbReader) readFloat() (float64, error) {
+	b, err := r.take(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(r.order.Uint64(b)), nil
+}
+
+// readPoints reads a number of points with dims coordinates each.
+func (r *wkbReader) readPoints(n uint32, dims int) ([]string, error) {
+	// sanity check before allocating
+	if int(n)*dims*8 > len(r.buf) {
+		return nil, fmt.Errorf("%w: unexpected end of input", errInvalidWKB)
+	}
+
+	points := make([]string, n)
+	for i := range points {
+		coords := make([]string, dims)
+		for j := range coords {
+			f, err := r.readFloat()
+			if err != nil {
+				return nil, err
+			}
+			coords[j] = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		points[i] = strings.Join(coords, " ")
+	}
+	return points, nil
+}
+
+// readRings reads a counted list of point lists (e.g. polygon rings).
+func (r *wkbReader) readRings(dims int) ([]string, error) {
+	n, err := r.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n)*4 > len(r.buf) {
+		return nil, fmt.Errorf("%w: unexpected end of input", errInvalidWKB)
+	}
+
+	rings := make([]string, n)
+	for i := range rings {
+		count, err := r.readUint32()
+		if err != nil {
+			return nil, err
+		}
+		points, err := r.readPoints(count, dims)
+		if err != nil {
+			return nil, err
+		}
+		rings[i] = "(" + strings.Join(points, ",") + ")"
+	}
+	return rings, nil
+}
+
+// readGeometry reads a single geometry with its header and returns its srid and wkt.
+func (r *wkbReader) readGeometry() (uint32, string, error) {
+	order, err := r.take(1)
+	if err != nil {
+		return 0, "", err
+	}
+	switch order[0] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return 0, "", fmt.Errorf("%w: unknown byte order %d", errInvalidWKB, order[0])
+	}
+
+	typ, err := r.readUint32()
+	if err != nil {
+		return 0, "", err
+	}
+
+	var srid uint32
+	if typ&ewkbFlagSRID != 0 {
+		srid, err = r.readUint32()
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	hasZ := typ&ewkbFlagZ != 0
+	hasM := typ&ewkbFlagM != 0
+
+	// ISO WKB encodes dimensions in thousands (1001 is POINT Z)
+	base := typ &^ (ewkbFlagZ | ewkbFlagM | ewkbFlagSRID)
+	switch base / 1000 {
+	case 0:
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ, hasM = true, true
+	default:
+		return 0, "", fmt.Errorf("%w: unknown geometry type %d", errInvalidWKB, base)
+	}
+	base %= 1000
+
+	dims := 2
+	suffix := ""
+	switch {
+	case hasZ && hasM:
+		dims, suffix = 4, " ZM"
+	case hasZ:
+		dims, suffix = 3, " Z"
+	case hasM:
+		dims, suffix = 3, " M"
+	}
+
+	var name, body string
+
+	switch base {
+	case 1:
+		name = "POINT"
+		points, err := r.readPoints(1, dims)
+		if err != nil {
+			return 0, "", err
+		}
+		// empty points are encoded with NaN coordinates
+		if strings.Contains(points[0], "NaN") {
+			return srid, name + suffix + " EMPTY", nil
+		}
+		body = points[0]
+	case 2:
+		name = "LINESTRING"
+		n, err := r.readUint32()
+		if err != nil {
+			return 0, "", err
+		}
+		points, err := r.readPoints(n, dims)
+		if err != nil {
+			return 0, "", err
+		}
+		body = strings.Join(points, ",")
+	case 3:
+		name = "POLYGON"
+		rings, err := r.readRings(dims)
+		if err != nil {
+			return 0, "", err
+		}
+		body = strings.Join(rings, ",")
+	case 4, 5, 6, 7:
+		name = map[uint32]string{
+			4: "MULTIPOINT",
+			5: "MULTILINESTRING",
+			6: "MULTIPOLYGON",
+			7: "GEOMETRYCOLLECTION",
+		}[base]
+
+		n, err := r.readUint32()
+		if err != nil {
+			return 0, "", err
+		}
+		if int(n)*5 > len(r.buf) {
+			return 0, "", fmt.Errorf("%w: unexpected end of input", errInvalidWKB)
+		}
+
+		parts := make([]string, n)
+		for i := range parts {
+			_, part, err := r.readGeometry()
+			if err != nil {
+				return 0, "", err
+			}
+			// members of collections keep their type name
+			if base != 7 {
+				part = strings.TrimLeft(part, "ABCDEFGHIJKLMNOPQRSTUVWXYZ ")
+				if part == "" {
+					part = "EMPTY"
+				}
+			}
+			parts[i] = part
+		}
+		body = strings.Join(parts, ",")
+	default:
+		return 0, "", fmt.Errorf("%w: unknown geometry type %d", errInvalidWKB, base)
+	}
+
+	if body == "" {
+		return srid, name + suffix + " EMPTY", nil
+	}
+
+	if suffix != "" {
+		suffix += " "
+	}
+	return srid, name + suffix + "(" + body + ")", nil
+}
@@ -0,0 +1,104 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEWKBHexToWKT(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "point",
+			input: "0101000000000000000000F03F0000000000000040",
+			want:  "POINT(1 2)",
+		},
+		{
+			name:  "point with srid",
+			input: "0101000020E6100000000000000000F03F0000000000000040",
+			want:  "SRID=4326;POINT(1 2)",
+		},
+		{
+			name:  "big endian point z",
+			input: "00800000013FF000000000000040000000000000004008000000000000",
+			want:  "POINT Z (1 2 3)",
+		},
+		{
+			name:  "empty point",
+			input: "0101000000000000000000F87F000000000000F87F",
+			want:  "POINT EMPTY",
+		},
+		{
+			name:  "linestring",
+			input: "010200000002000000000000000000000000000000000000000000000000000040000000000000F03F",
+			want:  "LINESTRING(0 0,2 1)",
+		},
+		{
+			name: "polygon",
+			input: "01030000000100000004000000" +
+				"00000000000000000000000000000000" +
+				"000000000000F03F0000000000000000" +
+				"000000000000F03F000000000000F03F" +
+				"00000000000000000000000000000000",
+			want: "POLYGON((0 0,1 0,1 1,0 0))",
+		},
+		{
+			name: "multipoint",
+			input: "010400000002000000" +
+				"0101000000000000000000F03F0000000000000040" +
+				"010100000000000000000008400000000000001040",
+			want: "MULTIPOINT((1 2),(3 4))",
+		},
+		{
+			name: "geometry collection",
+			input: "010700000002000000" +
+				"0101000000000000000000F03F0000000000000040" +
+				"010200000002000000000000000000000000000000000000000000000000000040000000000000F03F",
+			want: "GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(0 0,2 1))",
+		},
+		{
+			name:  "empty collection",
+			input: "010700000000000000",
+			want:  "GEOMETRYCOLLECTION EMPTY",
+		},
+		{
+			name:    "not hex",
+			input:   "hello",
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			input:   "0101000000000000000000F03F",
+			wantErr: true,
+		},
+		{
+			name:    "trailing bytes",
+			input:   "0101000000000000000000F03F000000000000004000",
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			input:   "0109000000",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := ewkbHexToWKT(tt.input)
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+const (
+	// postgresIndexMinRows is the minimum number of table rows for suggesting an index,
+	// scans of small tables are cheap anyway.
+	postgresIndexMinRows = 10000
+	// postgresIndexMaxSelectivity is the maximum share of table rows returned by the
+	// filter for suggesting an index, an index doesn't help filters matching most rows.
+	postgresIndexMaxSelectivity = 0.2
+)
+
+// postgresFilterColumn matches a column compared in a plan filter, e.g. "(customer_id = 42)",
+// "((o.status)::text = 'paid'::text)" or "(deleted_at IS NULL)".
+var postgresFilterColumn = regexp.MustCompile(
+	`(?:^|[(\s])(?:"?[A-Za-z_][\w$]*"?\.)?"?([A-Za-z_][\w$]*)"?\)?(?:::[a-z ]+(?:\[\])?)?\s*(<>|!=|=|<=|>=|<|>|~~|IS NULL|IS NOT NULL)`,
+)
+
+// postgresIndexSuggestion is a candidate index for a filtered sequential scan.
+type postgresIndexSuggestion struct {
+	table     string
+	columns   []string
+	planRows  int64
+	tableRows int64
+}
+
+// postgresFilterColumns returns columns of the filter usable by a btree index: columns compared
+// for equality first, followed by range and pattern comparisons.
+func postgresFilterColumns(filter string) []string {
+	var equality, other []string
+	seen := make(map[string]bool)
+
+	for _, match := range postgresFilterColumn.FindAllStringSubmatch(filter, -1) {
+		column, op := match[1], match[2]
+		if op == "<>" || op == "!=" || op == "IS NOT NULL" || seen[column] {
+			continue
+		}
+		seen[column] = true
+
+		if op == "=" || op == "IS NULL" {
+			equality = append(equality, column)
+		} else {
+			other = append(other, column)
+		}
+	}
+
+	return append(equality, other...)
+}
+
+// postgresSeqScans returns filtered sequential scans of the plan tree.
+func postgresSeqScans(node *postgresPlanNode) []*postgresPlanNode {
+	var scans []*postgresPlanNode
+	if (node.NodeType == "Seq Scan" || node.NodeType == "Parallel Seq Scan") && node.RelationName != "" && node.Filter != "" {
+		scans = append(scans, node)
+	}
+	for _, child := range node.Plans {
+		scans = append(scans, postgresSeqScans(child)...)
+	}
+	return scans
+}
+
+// suggestPostgresIndexes returns suggestions for selective filters of sequential scans
+// on large tables. tableRows returns the estimated number of rows of the table.
+func suggestPostgresIndexes(root *postgresPlanNode, tableRows func(table string) (int64, error)) ([]*postgresIndexSuggestion, error) {
+	var suggestions []*postgresIndexSuggestion
+	seen := make(map[string]bool)
+
+	for _, scan := range postgresSeqScans(root) {
+		columns := postgresFilterColumns(scan.Filter)
+		if len(columns) < 1 {
+			continue
+		}
+
+		key := scan.RelationName + "(" + strings.Join(columns, ",") + ")"
+		if seen[key] {
+			continue
+		}
+
+		rows, err := tableRows(scan.RelationName)
+		if err != nil {
+			return nil, err
+		}
+		// tables without statistics report no rows
+		rows = max(rows, scan.PlanRows)
+		if rows < postgresIndexMinRows || float64(scan.PlanRows) > float64(rows)*postgresIndexMaxSelectivity {
+			continue
+		}
+
+		seen[key] = true
+		suggestions = append(suggestions, &postgresIndexSuggestion{
+			table:     scan.RelationName,
+			columns:   columns,
+			planRows:  scan.PlanRows,
+			tableRows: rows,
+		})
+	}
+
+	return suggestions, nil
+}
+
+func (s *postgresIndexSuggestion) reason() string {
+	return fmt.Sprintf("seq scan on %s filtering %s (estimated %d of %d rows)",
+		s.table, strings.Join(s.columns, ", "), s.planRows, s.tableRows)
+}
+
+func (s *postgresIndexSuggestion) statement() string {
+	columns := make([]string, len(s.columns))
+	for i, col := range s.columns {
+		columns[i] = postgresIdent(col)
+	}
+	return fmt.Sprintf("CREATE INDEX ON %s (%s);", postgresIdent(s.table), strings.Join(columns, ", "))
+}
+
+// postgresIdent quotes the identifier if it's not a lowercase name.
+func postgresIdent(name string) string {
+	for _, ch := range name {
+		if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_') {
+			return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+		}
+	}
+	return name
+}
+
+// SuggestIndexes plans the query (without executing it) and suggests indexes on
+// columns of selective filters of sequential scans on large tables.
+func (c *postgresDriver) SuggestIndexes(ctx context.Context, query string) (core.ResultStream, error) {
+	plan, err := c.explainJSON(ctx, query, false)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parsePostgresPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := suggestPostgresIndexes(root, func(table string) (int64, error) {
+		// names of the plan are resolved the same way as in the query (search_path)
+		rows, err := c.c.Query(ctx, fmt.Sprintf(
+			"SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass('%s')",
+			strings.ReplaceAll(postgresIdent(table), "'", "''")))
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+
+		if !rows.HasNext() {
+			return 0, nil
+		}
+		row, err := rows.Next()
+		if err != nil || len(row) < 1 {
+			return 0, err
+		}
+		n, _ := row[0].(int64)
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(suggestions) < 1 {
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextSingle("no filtered sequential scans of large tables found")).
+			WithHeader(core.Header{"Reason"}).
+			Build(), nil
+	}
+
+	next, hasNext := builders.NextYield(func(yield func(...any)) error {
+		for _, s := range suggestions {
+			yield(s.table, strings.Join(s.columns, ", "), s.reason(), s.statement())
+		}
+		return nil
+	})
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(core.Header{"Table", "Columns", "Reason", "Suggestion"}).
+		Build(), nil
+}
@@ -0,0 +1,81 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// plan of "EXPLAIN (FORMAT JSON)" for a join of two filtered tables
+const postgresSeqScanPlanFixture = `[
+  {
+    "Plan": {
+      "Node Type": "Hash Join",
+      "Total Cost": 2500.0,
+      "Plan Rows": 12,
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Relation Name": "orders",
+          "Alias": "o",
+          "Total Cost": 2000.0,
+          "Plan Rows": 12,
+          "Filter": "((customer_id = 42) AND (created_at > '2024-01-01'::date) AND ((status)::text <> 'void'::text))"
+        },
+        {
+          "Node Type": "Hash",
+          "Total Cost": 20.0,
+          "Plan Rows": 50,
+          "Plans": [
+            {
+              "Node Type": "Seq Scan",
+              "Relation Name": "Customers",
+              "Alias": "c",
+              "Total Cost": 20.0,
+              "Plan Rows": 50,
+              "Filter": "(c.deleted_at IS NULL)"
+            }
+          ]
+        }
+      ]
+    }
+  }
+]`
+
+func TestPostgresFilterColumns(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal([]string{"customer_id", "created_at"}, postgresFilterColumns(
+		"((customer_id = 42) AND (created_at > '2024-01-01'::date) AND ((status)::text <> 'void'::text))"))
+	r.Equal([]string{"status", "name"}, postgresFilterColumns(
+		"(((o.name)::text ~~ 'abc%'::text) AND ((o.status)::text = 'paid'::text))"))
+	r.Equal([]string{"deleted_at"}, postgresFilterColumns("(deleted_at IS NULL)"))
+	r.Empty(postgresFilterColumns("(archived IS NOT NULL)"))
+	r.Empty(postgresFilterColumns("(lower(email) <> 'x'::text)"))
+}
+
+func TestSuggestPostgresIndexes(t *testing.T) {
+	r := require.New(t)
+
+	root, err := parsePostgresPlan([]byte(postgresSeqScanPlanFixture))
+	r.NoError(err)
+
+	tables := map[string]int64{"orders": 100000, "Customers": 60000}
+	suggestions, err := suggestPostgresIndexes(root, func(table string) (int64, error) {
+		return tables[table], nil
+	})
+	r.NoError(err)
+	r.Len(suggestions, 2)
+
+	r.Equal("seq scan on orders filtering customer_id, created_at (estimated 12 of 100000 rows)", suggestions[0].reason())
+	r.Equal("CREATE INDEX ON orders (customer_id, created_at);", suggestions[0].statement())
+	r.Equal(`CREATE INDEX ON "Customers" (deleted_at);`, suggestions[1].statement())
+
+	// small tables and filters matching most rows are skipped
+	tables = map[string]int64{"orders": 5000, "Customers": 100}
+	suggestions, err = suggestPostgresIndexes(root, func(table string) (int64, error) {
+		return tables[table], nil
+	})
+	r.NoError(err)
+	r.Empty(suggestions)
+}
@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// postgresListenPingInterval is the interval of pinging an idle listener connection,
+// so that broken connections are detected (and reestablished).
+const postgresListenPingInterval = 90 * time.Second
+
+// Listen opens a dedicated LISTEN connection to the current database.
+// Lost connections are reestablished, but notifications sent in the meantime are lost.
+// With multiple hosts, the listener stays on the first host available when listening started.
+func (c *postgresDriver) Listen(ctx context.Context, channel string) (<-chan *core.Notification, error) {
+	url, err := postgresSingleHostURL(ctx, c.url)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open listener connection: %w", err)
+	}
+
+	// first connection attempt decides whether listening succeeds
+	connected := make(chan error, 1)
+	onEvent := func(ev pq.ListenerEventType, err error) {
+		var result error
+		switch ev {
+		case pq.ListenerEventConnected:
+		case pq.ListenerEventConnectionAttemptFailed:
+			result = err
+		default:
+			return
+		}
+
+		select {
+		case connected <- result:
+		default:
+		}
+	}
+
+	listener := pq.NewListener(url, time.Second, time.Minute, onEvent)
+
+	select {
+	case err := <-connected:
+		if err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("unable to open listener connection: %w", err)
+		}
+	case <-ctx.Done():
+		_ = listener.Close()
+		return nil, ctx.Err()
+	}
+
+	if err := listener.Listen(channel); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("listener.Listen: %w", err)
+	}
+
+	out := make(chan *core.Notification)
+
+	go func() {
+		defer close(out)
+		defer listener.Close()
+
+		ticker := time.NewTicker(postgresListenPingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = listener.Ping()
+			case n, ok := <-listener.Notify:
+				if !ok {
+					return
+				}
+				// nil notification is sent after reconnecting
+				if n == nil {
+					continue
+				}
+
+				select {
+				case out <- &core.Notification{
+					Channel:  n.Channel,
+					Payload:  n.Extra,
+					PID:      n.BePid,
+					Received: time.Now(),
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
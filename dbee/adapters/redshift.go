@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"database/sql"
 	"fmt"
 	"net/url"
 
@@ -26,7 +25,7 @@ func (r *Redshift) Connect(rawURL string) (core.Driver, error) {
 	}
 
 	// TODO: perhaps better to use something else than postgres driver..
-	db, err := sql.Open("postgres", connURL.String())
+	db, err := openPostgres(connURL)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to redshift: %w", err)
 	}
@@ -39,7 +38,7 @@ func (r *Redshift) Connect(rawURL string) (core.Driver, error) {
 
 func (r *Redshift) GetHelpers(opts *core.TableOptions) map[string]string {
 	out := make(map[string]string, 0)
-	list := fmt.Sprintf("SELECT * FROM %q.%q LIMIT 100;", opts.Schema, opts.Table)
+	list := fmt.Sprintf("SELECT * FROM %s.%s LIMIT 100;", core.QuoteIdentifier(opts.Schema), core.QuoteIdentifier(opts.Table))
 
 	switch opts.Materialization {
 	case core.StructureTypeTable:
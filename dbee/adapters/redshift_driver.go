@@ -2,9 +2,9 @@ package adapters
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"net/url"
+	"time"
 
 	_ "github.com/lib/pq"
 
@@ -13,8 +13,18 @@ import (
 )
 
 var (
-	_ core.Driver           = (*redshiftDriver)(nil)
-	_ core.DatabaseSwitcher = (*redshiftDriver)(nil)
+	_ core.Driver            = (*redshiftDriver)(nil)
+	_ core.ContextStructurer = (*redshiftDriver)(nil)
+	_ core.CursorQuerier     = (*redshiftDriver)(nil)
+	_ core.DatabaseSwitcher  = (*redshiftDriver)(nil)
+	_ core.IdentifierFolder  = (*redshiftDriver)(nil)
+	_ core.IdleCloser        = (*redshiftDriver)(nil)
+	_ core.Limiter           = (*redshiftDriver)(nil)
+	_ core.ParamQuerier      = (*redshiftDriver)(nil)
+	_ core.Peeker            = (*redshiftDriver)(nil)
+	_ core.SafeScanner       = (*redshiftDriver)(nil)
+	_ core.StatementLogger   = (*redshiftDriver)(nil)
+	_ core.TopValuer         = (*redshiftDriver)(nil)
 )
 
 // redshiftDriver is a sql client for redshiftDriver.
@@ -30,18 +40,64 @@ func (r *redshiftDriver) Query(ctx context.Context, query string) (core.ResultSt
 	return r.c.QueryUntilNotEmpty(ctx, query)
 }
 
+func (r *redshiftDriver) QueryCursor(ctx context.Context, query string, fetchSize int) (core.ResultStream, error) {
+	return r.c.QueryCursor(ctx, query, fetchSize)
+}
+
 // Close closes the underlying sql.DB connection.
 func (r *redshiftDriver) Close() {
 	r.c.Close()
 }
 
+func (r *redshiftDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (r *redshiftDriver) IdentifierCase() core.IdentifierCase {
+	return core.IdentifierCaseLower
+}
+
+func (r *redshiftDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return r.c.QueryArgs(ctx, query, args...)
+}
+
+func (r *redshiftDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderDollar
+}
+
+func (r *redshiftDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteDouble, core.LimitDialectLimit)
+}
+
+func (r *redshiftDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return r.c.Peek(ctx, query)
+}
+
+func (r *redshiftDriver) SetSafeScan(enabled bool) {
+	r.c.SetSafeScan(enabled)
+}
+
+func (r *redshiftDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	r.c.SetStatementLog(fn)
+}
+
+func (r *redshiftDriver) SetIdleTimeout(timeout time.Duration) {
+	r.c.SetIdleTimeout(timeout)
+}
+
 func (r *redshiftDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return r.c.ColumnsFromQuery(`
-		SELECT column_name, data_type
+		SELECT
+			column_name,
+			data_type,
+			character_maximum_length,
+			numeric_precision,
+			numeric_scale
 		FROM information_schema.columns
 		WHERE
 			table_schema='%s' AND
 			table_name='%s'
+		ORDER BY ordinal_position
 		`, opts.Schema, opts.Table)
 }
 
@@ -49,6 +105,10 @@ func (r *redshiftDriver) Columns(opts *core.TableOptions) ([]*core.Column, error
 // "schema" with all the tables and views. Note that ordering is not
 // done here. The ordering is done in the lua frontend.
 func (r *redshiftDriver) Structure() ([]*core.Structure, error) {
+	return r.StructureCtx(context.Background())
+}
+
+func (r *redshiftDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
 	query := `
 		SELECT
 		trim(n.nspname) AS schema_name
@@ -65,12 +125,12 @@ func (r *redshiftDriver) Structure() ([]*core.Structure, error) {
 					n.nspname NOT IN ('information_schema', 'pg_catalog');
 	`
 
-	rows, err := r.Query(context.Background(), query)
+	rows, err := r.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return getPGStructure(rows)
+	return getPGStructure(ctx, rows)
 }
 
 func (r *redshiftDriver) ListDatabases() (current string, available []string, err error) {
@@ -105,7 +165,7 @@ WHERE datistemplate = false
 
 func (r *redshiftDriver) SelectDatabase(name string) error {
 	r.connectionURL.Path = fmt.Sprintf("/%s", name)
-	db, err := sql.Open("postgres", r.connectionURL.String())
+	db, err := openPostgres(r.connectionURL)
 	if err != nil {
 		return fmt.Errorf("unable to switch databases: %w", err)
 	}
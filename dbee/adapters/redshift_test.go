@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
@@ -46,3 +47,14 @@ func TestNewRedshift(t *testing.T) {
 		})
 	}
 }
+
+func TestRedshiftHelpers_MixedCase(t *testing.T) {
+	r := require.New(t)
+
+	helpers := (&Redshift{}).GetHelpers(&core.TableOptions{Schema: "Sales", Table: "OrderItems", Materialization: core.StructureTypeTable})
+	r.Equal(`SELECT * FROM "Sales"."OrderItems" LIMIT 100;`, helpers["List"])
+
+	ic := (&redshiftDriver{}).IdentifierCase()
+	r.Equal(`"OrderItems"`, ic.Display("OrderItems"))
+	r.Equal("OrderItems", ic.Catalog(ic.Display("OrderItems")))
+}
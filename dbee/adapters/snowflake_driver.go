@@ -0,0 +1,258 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver             = (*snowflakeDriver)(nil)
+	_ core.ContextStructurer  = (*snowflakeDriver)(nil)
+	_ core.DatabaseSwitcher   = (*snowflakeDriver)(nil)
+	_ core.IdentifierFolder   = (*snowflakeDriver)(nil)
+	_ core.Limiter            = (*snowflakeDriver)(nil)
+	_ core.SafeScanner        = (*snowflakeDriver)(nil)
+	_ core.SessionParamSetter = (*snowflakeDriver)(nil)
+	_ core.TopValuer          = (*snowflakeDriver)(nil)
+)
+
+type snowflakeDriver struct {
+	c   *builders.Client
+	dsn *snowflakeDSN
+	// open opens a pool of connections to the DSN
+	open func(dsn *snowflakeDSN) (*sql.DB, error)
+}
+
+// snowflakeSessionConnector sets session parameters on every new connection of the pool,
+// as ALTER SESSION only applies to the session it is run in.
+type snowflakeSessionConnector struct {
+	driver.Connector
+	// ALTER SESSION statements
+	params []string
+}
+
+func (c *snowflakeSessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil || len(c.params) < 1 {
+		return conn, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, errors.New("connection can't execute statements")
+	}
+
+	for _, stmt := range c.params {
+		_, err = execer.ExecContext(ctx, stmt, nil)
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (c *snowflakeDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	return c.c.QueryUntilNotEmpty(ctx, query)
+}
+
+// Columns reads information_schema.columns. Names are compared as they are stored,
+// i.e. upper case for identifiers which were not quoted when created.
+func (c *snowflakeDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	return c.c.ColumnsFromQuery(`
+		SELECT
+			column_name,
+			data_type,
+			character_maximum_length,
+			numeric_precision,
+			numeric_scale
+		FROM information_schema.columns
+		WHERE table_schema = %s AND table_name = %s
+		ORDER BY ordinal_position`,
+		snowflakeString(opts.Schema), snowflakeString(opts.Table))
+}
+
+func (c *snowflakeDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+// StructureCtx reads information_schema.tables of the current database.
+func (c *snowflakeDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	query := `
+		SELECT table_schema, table_name, table_type
+		FROM information_schema.tables
+		WHERE table_schema != 'INFORMATION_SCHEMA'
+		ORDER BY table_schema, table_name`
+
+	rows, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var structure []*core.Structure
+	schemas := make(map[string]*core.Structure)
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve structure: insufficient info")
+		}
+
+		schema, table, tableType := row[0].(string), row[1].(string), row[2].(string)
+
+		parent, ok := schemas[schema]
+		if !ok {
+			parent = &core.Structure{
+				Name:   schema,
+				Schema: schema,
+				Type:   core.StructureTypeNone,
+			}
+			schemas[schema] = parent
+			structure = append(structure, parent)
+		}
+
+		parent.Children = append(parent.Children, &core.Structure{
+			Name:   table,
+			Schema: schema,
+			Type:   getSnowflakeStructureType(tableType),
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return structure, nil
+}
+
+// getSnowflakeStructureType returns the structure type of the table_type
+// column of information_schema.tables.
+func getSnowflakeStructureType(typ string) core.StructureType {
+	switch typ {
+	case "VIEW", "MATERIALIZED VIEW":
+		return core.StructureTypeView
+	case "EXTERNAL TABLE":
+		return core.StructureTypeExternalTable
+	default:
+		return core.StructureTypeTable
+	}
+}
+
+// ListDatabases runs SHOW DATABASES. Its columns differ between versions,
+// so they are looked up by name.
+func (c *snowflakeDriver) ListDatabases() (current string, available []string, err error) {
+	rows, err := c.Query(context.Background(), "SHOW DATABASES")
+	if err != nil {
+		return "", nil, err
+	}
+
+	nameIdx, currentIdx := -1, -1
+	for i, h := range rows.Header() {
+		switch h {
+		case "name":
+			nameIdx = i
+		case "is_current":
+			currentIdx = i
+		}
+	}
+	if nameIdx < 0 || currentIdx < 0 {
+		return "", nil, errors.New("could not retrieve databases: missing name or is_current column")
+	}
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return "", nil, err
+		}
+
+		name, ok := row[nameIdx].(string)
+		if !ok {
+			return "", nil, errors.New("could not retrieve databases: name not a string")
+		}
+		if isCurrent, _ := row[currentIdx].(string); isCurrent == "Y" {
+			current = name
+			continue
+		}
+		available = append(available, name)
+	}
+
+	return current, available, nil
+}
+
+// SelectDatabase reconnects with the database as the default one. A USE DATABASE
+// statement would only apply to a single connection of the pool.
+// The current connection is kept if the database can't be connected to.
+func (c *snowflakeDriver) SelectDatabase(name string) error {
+	dsn := c.dsn.withDatabase(name)
+
+	db, err := c.open(dsn)
+	if err != nil {
+		return fmt.Errorf("unable to switch databases: %w", err)
+	}
+
+	if err := db.PingContext(context.TODO()); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("unable to switch databases: %w", err)
+	}
+
+	c.dsn = dsn
+	c.c.Swap(db)
+
+	return nil
+}
+
+// SetSessionParam reconnects with the parameter added to the DSN, so that it's set on every
+// connection of the pool and kept when switching databases. Names are case insensitive.
+func (c *snowflakeDriver) SetSessionParam(ctx context.Context, name, value string) error {
+	dsn, err := c.dsn.withSessionParam(strings.ToUpper(name), value)
+	if err != nil {
+		return err
+	}
+
+	db, err := c.open(dsn)
+	if err != nil {
+		return fmt.Errorf("unable to set session parameter: %w", err)
+	}
+
+	// invalid parameters fail on connecting
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("unable to set session parameter: %w", err)
+	}
+
+	c.dsn = dsn
+	c.c.Swap(db)
+
+	return nil
+}
+
+func (c *snowflakeDriver) Close() {
+	c.c.Close()
+}
+
+func (c *snowflakeDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *snowflakeDriver) IdentifierCase() core.IdentifierCase {
+	return core.IdentifierCaseUpper
+}
+
+func (c *snowflakeDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *snowflakeDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteDouble, core.LimitDialectLimit)
+}
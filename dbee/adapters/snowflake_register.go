@@ -0,0 +1,12 @@
+//go:build snowflake
+
+package adapters
+
+import (
+	_ "github.com/snowflakedb/gosnowflake"
+)
+
+// Register client
+func init() {
+	_ = register(&Snowflake{}, "snowflake")
+}
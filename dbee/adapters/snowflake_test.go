@@ -0,0 +1,250 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// snowflakeTestResponse answers the catalog queries of snowflake with fixed rows.
+func snowflakeTestResponse(query string) driver.Rows {
+	switch {
+	case strings.Contains(query, "information_schema.tables"):
+		return &sqlTestRows{columns: []string{"table_schema", "table_name", "table_type"}, rows: [][]driver.Value{
+			{"PUBLIC", "ORDERS", "BASE TABLE"},
+			{"PUBLIC", "orders_v", "VIEW"},
+			{"RAW", "EVENTS", "EXTERNAL TABLE"},
+		}}
+	case strings.Contains(query, "SHOW DATABASES"):
+		return &sqlTestRows{columns: []string{"created_on", "name", "is_default", "is_current"}, rows: [][]driver.Value{
+			{"2024-01-01", "ANALYTICS", "N", "Y"},
+			{"2024-01-01", "SNOWFLAKE", "N", "N"},
+		}}
+	default:
+		return &sqlTestRows{columns: []string{"status"}}
+	}
+}
+
+// snowflakeTestConn records executed statements. Statements with "INVALID" fail.
+type snowflakeTestConn struct {
+	driver.Conn
+	executed *[]string
+	closed   bool
+}
+
+func (c *snowflakeTestConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	*c.executed = append(*c.executed, query)
+	if strings.Contains(query, "INVALID") {
+		return nil, errors.New("invalid parameter")
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *snowflakeTestConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type snowflakeTestConnector struct {
+	driver.Connector
+	executed []string
+	conns    []*snowflakeTestConn
+}
+
+func (c *snowflakeTestConnector) Connect(context.Context) (driver.Conn, error) {
+	conn := &snowflakeTestConn{executed: &c.executed}
+	c.conns = append(c.conns, conn)
+	return conn, nil
+}
+
+// snowflakeTestPingConnector is a connector of a database that can't be connected to.
+type snowflakeTestPingConnector struct {
+	driver.Connector
+}
+
+func (c *snowflakeTestPingConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("database does not exist")
+}
+
+func TestSnowflakeDriver_Structure(t *testing.T) {
+	r := require.New(t)
+
+	d := &snowflakeDriver{c: builders.NewClient(sql.OpenDB(&sqlTestConnector{respond: snowflakeTestResponse}))}
+	defer d.Close()
+
+	structure, err := d.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name:   "PUBLIC",
+			Schema: "PUBLIC",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "ORDERS", Schema: "PUBLIC", Type: core.StructureTypeTable},
+				{Name: "orders_v", Schema: "PUBLIC", Type: core.StructureTypeView},
+			},
+		},
+		{
+			Name:   "RAW",
+			Schema: "RAW",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "EVENTS", Schema: "RAW", Type: core.StructureTypeExternalTable},
+			},
+		},
+	}, structure)
+
+	current, available, err := d.ListDatabases()
+	r.NoError(err)
+	r.Equal("ANALYTICS", current)
+	r.Equal([]string{"SNOWFLAKE"}, available)
+}
+
+func TestSnowflake_ParseDSN(t *testing.T) {
+	r := require.New(t)
+
+	dsn, err := parseSnowflakeDSN("snowflake://jane:p@ss/w@xy12345.eu-central-1/SALES/PUBLIC?warehouse=wh&role=r")
+	r.NoError(err)
+	r.Equal(&snowflakeDSN{
+		userinfo: "jane:p@ss/w",
+		account:  "xy12345.eu-central-1",
+		database: "SALES",
+		schema:   "PUBLIC",
+		query:    "warehouse=wh&role=r",
+	}, dsn)
+	r.Equal("jane:p@ss/w@xy12345.eu-central-1/SALES/PUBLIC?warehouse=wh&role=r", dsn.String())
+
+	// switching databases leaves out the schema
+	r.Equal("jane:p@ss/w@xy12345.eu-central-1/ANALYTICS?warehouse=wh&role=r", dsn.withDatabase("ANALYTICS").String())
+	r.Equal("SALES", dsn.database)
+
+	dsn, err = parseSnowflakeDSN("jane@xy12345")
+	r.NoError(err)
+	r.Equal("jane@xy12345", dsn.String())
+
+	for _, invalid := range []string{"xy12345/SALES", "jane@", "jane@xy12345//PUBLIC"} {
+		_, err = parseSnowflakeDSN(invalid)
+		r.Error(err, invalid)
+	}
+}
+
+func TestSnowflakeDriver_SelectDatabase(t *testing.T) {
+	r := require.New(t)
+
+	dsn, err := parseSnowflakeDSN("jane@xy12345/SALES/PUBLIC?QUERY_TAG=dbee")
+	r.NoError(err)
+
+	var opened []string
+	d := &snowflakeDriver{
+		c:   builders.NewClient(sql.OpenDB(&sqlTestConnector{respond: snowflakeTestResponse})),
+		dsn: dsn,
+		open: func(dsn *snowflakeDSN) (*sql.DB, error) {
+			opened = append(opened, dsn.String())
+			if dsn.database == "MISSING" {
+				return sql.OpenDB(&snowflakeTestPingConnector{}), nil
+			}
+			return sql.OpenDB(&sqlTestConnector{respond: snowflakeTestResponse}), nil
+		},
+	}
+	defer d.Close()
+
+	// the current database is kept if the new one can't be connected to
+	r.ErrorContains(d.SelectDatabase("MISSING"), "database does not exist")
+	r.Equal("SALES", d.dsn.database)
+	_, err = d.Structure()
+	r.NoError(err)
+
+	r.NoError(d.SelectDatabase("ANALYTICS"))
+	r.Equal("ANALYTICS", d.dsn.database)
+	r.Equal([]string{
+		"jane@xy12345/MISSING?QUERY_TAG=dbee",
+		"jane@xy12345/ANALYTICS?QUERY_TAG=dbee",
+	}, opened)
+}
+
+func TestSnowflakeDriver_SetSessionParam(t *testing.T) {
+	r := require.New(t)
+
+	dsn, err := parseSnowflakeDSN("jane@xy12345/SALES?warehouse=wh")
+	r.NoError(err)
+
+	var opened []string
+	d := &snowflakeDriver{
+		c:   builders.NewClient(sql.OpenDB(&sqlTestConnector{respond: snowflakeTestResponse})),
+		dsn: dsn,
+		open: func(dsn *snowflakeDSN) (*sql.DB, error) {
+			opened = append(opened, dsn.String())
+			if strings.Contains(dsn.query, "INVALID") {
+				return sql.OpenDB(&snowflakeTestPingConnector{}), nil
+			}
+			return sql.OpenDB(&sqlTestConnector{respond: snowflakeTestResponse}), nil
+		},
+	}
+	defer d.Close()
+
+	r.NoError(d.SetSessionParam(context.Background(), "query_tag", "dbee export"))
+	r.Equal([]string{"ALTER SESSION SET QUERY_TAG = 'dbee export'"}, d.dsn.sessionParams())
+
+	// the parameter is kept when switching databases
+	r.NoError(d.SelectDatabase("ANALYTICS"))
+	r.Equal([]string{
+		"jane@xy12345/SALES?QUERY_TAG=dbee+export&warehouse=wh",
+		"jane@xy12345/ANALYTICS?QUERY_TAG=dbee+export&warehouse=wh",
+	}, opened)
+
+	// parameters which fail on connecting are not kept
+	r.Error(d.SetSessionParam(context.Background(), "INVALID", "1"))
+	r.Equal([]string{"ALTER SESSION SET QUERY_TAG = 'dbee export'"}, d.dsn.sessionParams())
+
+	r.ErrorContains(d.SetSessionParam(context.Background(), "tag; DROP TABLE x", "1"), "invalid session parameter name")
+}
+
+func TestSnowflakeSessionConnector(t *testing.T) {
+	r := require.New(t)
+
+	dsn, err := parseSnowflakeDSN("jane@xy12345?warehouse=wh&QUERY_TAG=it's&LOCK_TIMEOUT=10&QUOTED_IDENTIFIERS_IGNORE_CASE=true")
+	r.NoError(err)
+	params := dsn.sessionParams()
+	r.Equal([]string{
+		"ALTER SESSION SET LOCK_TIMEOUT = 10",
+		"ALTER SESSION SET QUERY_TAG = 'it\\'s'",
+		"ALTER SESSION SET QUOTED_IDENTIFIERS_IGNORE_CASE = true",
+	}, params)
+
+	// parameters are set on every connection
+	base := &snowflakeTestConnector{}
+	connector := &snowflakeSessionConnector{Connector: base, params: params}
+	for i := 0; i < 2; i++ {
+		_, err = connector.Connect(context.Background())
+		r.NoError(err)
+	}
+	r.Equal(append(params, params...), base.executed)
+
+	// connections with invalid parameters are closed
+	_, err = (&snowflakeSessionConnector{
+		Connector: base,
+		params:    []string{"ALTER SESSION SET INVALID = 1"},
+	}).Connect(context.Background())
+	r.ErrorContains(err, "ALTER SESSION SET INVALID = 1: invalid parameter")
+	r.True(base.conns[2].closed)
+}
+
+func TestSnowflake_GetHelpers(t *testing.T) {
+	r := require.New(t)
+
+	helpers := (&Snowflake{}).GetHelpers(&core.TableOptions{
+		Schema:          "PUBLIC",
+		Table:           "it's",
+		Materialization: core.StructureTypeView,
+	})
+	r.Equal(`SELECT * FROM "PUBLIC"."it's" LIMIT 500`, helpers["List"])
+	r.Equal(`SELECT GET_DDL('VIEW', '"PUBLIC"."it\'s"')`, helpers["Definition"])
+}
@@ -5,8 +5,10 @@ package adapters
 import (
 	"database/sql"
 	"fmt"
+	nurl "net/url"
 	"os/user"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	_ "modernc.org/sqlite"
@@ -20,7 +22,10 @@ func init() {
 	_ = register(&SQLite{}, "sqlite", "sqlite3")
 }
 
-var _ core.Adapter = (*SQLite)(nil)
+var (
+	_ core.Adapter   = (*SQLite)(nil)
+	_ core.Validator = (*SQLite)(nil)
+)
 
 type SQLite struct{}
 
@@ -39,22 +44,74 @@ func (s *SQLite) expandPath(path string) (string, error) {
 	return path, nil
 }
 
+// Connect creates a [SQLite] client. The url is the path of the database file, optionally
+// with parameters of sqlite uris (e.g. "~/app.db?mode=ro" or "app.db?cache=shared").
 func (s *SQLite) Connect(url string) (core.Driver, error) {
-	path, err := s.expandPath(url)
+	dsn, name, err := s.parseURL(url)
 	if err != nil {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite", path)
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to sqlite database: %v", err)
 	}
 
 	return &sqliteDriver{
-		c: builders.NewClient(db),
+		c:    builders.NewClient(db),
+		name: name,
 	}, nil
 }
 
+func (s *SQLite) Validate(url string) error {
+	_, _, err := s.parseURL(url)
+	return err
+}
+
+// parseURL returns the dsn of the url and the name of the database (the file name).
+// Urls with parameters are converted to "file:" uris, because the driver passes
+// parameters of plain paths (other than its own "_pragma" etc.) to nobody.
+func (s *SQLite) parseURL(url string) (dsn string, name string, err error) {
+	if strings.HasPrefix(url, "file:") {
+		path, _, _ := strings.Cut(strings.TrimPrefix(url, "file:"), "?")
+		return url, sqliteName(path), nil
+	}
+
+	path, rawQuery, _ := strings.Cut(url, "?")
+	path, err = s.expandPath(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	params, err := nurl.ParseQuery(rawQuery)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid url parameters: %w", err)
+	}
+	if mode := params.Get("mode"); mode != "" && !slices.Contains([]string{"ro", "rw", "rwc", "memory"}, mode) {
+		return "", "", fmt.Errorf("invalid value of parameter %q: %q (expected ro, rw, rwc or memory)", "mode", mode)
+	}
+	if cache := params.Get("cache"); cache != "" && cache != "shared" && cache != "private" {
+		return "", "", fmt.Errorf("invalid value of parameter %q: %q (expected shared or private)", "cache", cache)
+	}
+
+	if rawQuery == "" {
+		return path, sqliteName(path), nil
+	}
+
+	// escapes characters with a meaning in uris (e.g. "?" and "#") in the path
+	escaped := (&nurl.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+	return "file:" + escaped + "?" + rawQuery, sqliteName(path), nil
+}
+
+// sqliteName returns the name of the database file without directories
+// or "main" (the name sqlite uses) for in-memory databases.
+func sqliteName(path string) string {
+	if path == "" || path == ":memory:" {
+		return "main"
+	}
+	return filepath.Base(path)
+}
+
 func (*SQLite) GetHelpers(opts *core.TableOptions) map[string]string {
 	return map[string]string{
 		"List":         fmt.Sprintf("SELECT * FROM %q LIMIT 500", opts.Table),
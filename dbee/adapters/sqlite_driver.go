@@ -2,15 +2,35 @@ package adapters
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
-var _ core.Driver = (*sqliteDriver)(nil)
+var (
+	_ core.Driver            = (*sqliteDriver)(nil)
+	_ core.ContextStructurer = (*sqliteDriver)(nil)
+	_ core.DDLProvider       = (*sqliteDriver)(nil)
+	_ core.Describer         = (*sqliteDriver)(nil)
+	_ core.ForeignKeyLister  = (*sqliteDriver)(nil)
+	_ core.Limiter           = (*sqliteDriver)(nil)
+	_ core.ParamQuerier      = (*sqliteDriver)(nil)
+	_ core.Peeker            = (*sqliteDriver)(nil)
+	_ core.SafeScanner       = (*sqliteDriver)(nil)
+	_ core.StatementLogger   = (*sqliteDriver)(nil)
+	_ core.TableImporter     = (*sqliteDriver)(nil)
+	_ core.TopValuer         = (*sqliteDriver)(nil)
+	_ core.UniqueKeyLister   = (*sqliteDriver)(nil)
+)
 
 type sqliteDriver struct {
 	c *builders.Client
+	// name of the database file
+	name string
 }
 
 func (c *sqliteDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
@@ -23,32 +43,205 @@ func (c *sqliteDriver) Columns(opts *core.TableOptions) ([]*core.Column, error)
 }
 
 func (c *sqliteDriver) Structure() ([]*core.Structure, error) {
-	query := `SELECT name FROM sqlite_schema WHERE type ='table'`
+	return c.StructureCtx(context.Background())
+}
 
-	rows, err := c.Query(context.TODO(), query)
+// StructureCtx lists tables and views (with their indexes) under the name of the database file,
+// as sqlite has no schemas.
+func (c *sqliteDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	query := `
+		SELECT name, type, tbl_name FROM sqlite_schema
+		WHERE type IN ('table', 'view', 'index')
+		ORDER BY type = 'index', name`
+
+	rows, err := c.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	var schema []*core.Structure
+	var children []*core.Structure
+	tables := make(map[string]*core.Structure)
 	for rows.HasNext() {
 		row, err := rows.Next()
 		if err != nil {
 			return nil, err
 		}
 
-		// We know for a fact there is only one string field (see query above)
-		table := row[0].(string)
-		schema = append(schema, &core.Structure{
-			Name:   table,
+		// We know for a fact there are 3 string fields (see query above)
+		name, table := row[0].(string), row[2].(string)
+		s := &core.Structure{
+			Name:   name,
 			Schema: "",
-			Type:   core.StructureTypeTable,
-		})
+			Type:   getSqliteStructureType(row[1].(string)),
+		}
+
+		// indexes come last, under their table
+		if s.Type == core.StructureTypeIndex {
+			if t, ok := tables[table]; ok {
+				t.Children = append(t.Children, s)
+			}
+			continue
+		}
+
+		tables[name] = s
+		children = append(children, s)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return []*core.Structure{
+		{
+			Name:     c.name,
+			Schema:   "",
+			Type:     core.StructureTypeNone,
+			Children: children,
+		},
+	}, nil
+}
 
-	return schema, nil
+// getSqliteStructureType returns the structure type of the sqlite_schema type.
+func getSqliteStructureType(typ string) core.StructureType {
+	switch typ {
+	case "table":
+		return core.StructureTypeTable
+	case "view":
+		return core.StructureTypeView
+	case "index":
+		return core.StructureTypeIndex
+	default:
+		return core.StructureTypeNone
+	}
 }
 
 func (c *sqliteDriver) Close() {
 	c.c.Close()
 }
+
+func (c *sqliteDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *sqliteDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *sqliteDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderQuestion
+}
+
+func (c *sqliteDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteDouble, core.LimitDialectLimit)
+}
+
+func (c *sqliteDriver) ImportTable(ctx context.Context, table string, rows core.ResultStream) (int, error) {
+	return c.c.ImportTable(ctx, table, rows, builders.QuoteDouble, sqliteColumnType)
+}
+
+// sqliteColumnType returns the type of imported columns. Booleans are stored
+// as integers and times as text (sqlite has no dedicated types for them).
+func sqliteColumnType(kind builders.ColumnKind) string {
+	switch kind {
+	case builders.ColumnKindInteger, builders.ColumnKindBoolean:
+		return "INTEGER"
+	case builders.ColumnKindReal:
+		return "REAL"
+	case builders.ColumnKindBlob:
+		return "BLOB"
+	}
+	return "TEXT"
+}
+
+func (c *sqliteDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return c.c.Peek(ctx, query)
+}
+
+func (c *sqliteDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *sqliteDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+func (c *sqliteDriver) Describe(ctx context.Context, opts *core.TableOptions) (core.ResultStream, error) {
+	switch opts.Materialization {
+	case core.StructureTypeTable:
+		return c.c.Query(ctx, fmt.Sprintf(`
+			SELECT name, type, "notnull", dflt_value, pk
+			FROM pragma_table_info('%s')`, opts.Table))
+	case core.StructureTypeView:
+		return c.c.Query(ctx, fmt.Sprintf(`
+			SELECT sql AS definition FROM sqlite_schema
+			WHERE type = 'view' AND name = '%s'`, opts.Table))
+	case core.StructureTypeIndex:
+		// automatic indexes of constraints have no definition
+		return c.c.Query(ctx, fmt.Sprintf(`
+			SELECT x.seqno, x.name AS column, x."desc", x.coll, s.sql AS definition
+			FROM pragma_index_xinfo('%s') x
+			LEFT JOIN sqlite_schema s ON s.type = 'index' AND s.name = '%s'
+			WHERE x.key = 1
+			ORDER BY x.seqno`, opts.Table, opts.Table))
+	default:
+		return nil, fmt.Errorf("cannot describe object of type %q", opts.Materialization)
+	}
+}
+
+func (c *sqliteDriver) DDL(opts *core.TableOptions) (string, error) {
+	// table (or view) definition first, followed by its indexes and triggers
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT sql FROM sqlite_schema
+		WHERE tbl_name = '%s' AND sql IS NOT NULL
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'view' THEN 0 WHEN 'index' THEN 1 ELSE 2 END, name`,
+		opts.Table))
+	if err != nil {
+		return "", err
+	}
+
+	var statements []string
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, fmt.Sprint(row[0])+";")
+	}
+
+	if len(statements) < 1 {
+		return "", errors.New("could not retrieve ddl: table not found")
+	}
+
+	return strings.Join(statements, "\n"), nil
+}
+
+func (c *sqliteDriver) ForeignKeys(opts *core.TableOptions) ([]*core.ForeignKey, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT 'fk_' || id, "from", '', "table", "to"
+		FROM pragma_foreign_key_list('%s')`, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return foreignKeysFromResultStream(rows)
+}
+
+// UniqueKeys lists columns of the primary key (which has no index if it's a rowid alias)
+// and of unique indexes of the table.
+func (c *sqliteDriver) UniqueKeys(opts *core.TableOptions) ([][]string, error) {
+	rows, err := c.c.Query(context.TODO(), fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT '', name FROM pragma_table_info('%s') WHERE pk > 0 ORDER BY pk
+		)
+		UNION ALL
+		SELECT * FROM (
+			SELECT il.name, ii.name
+			FROM pragma_index_list('%s') il, pragma_index_info(il.name) ii
+			WHERE il."unique" AND ii.name IS NOT NULL
+			ORDER BY il.name, ii.seqno
+		)`, opts.Table, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return uniqueKeysFromResultStream(rows)
+}
@@ -0,0 +1,246 @@
+//go:build (darwin && (amd64 || arm64)) || (freebsd && (386 || amd64 || arm || arm64)) || (linux && (386 || amd64 || arm || arm64 || ppc64le || riscv64 || s390x)) || (netbsd && amd64) || (openbsd && (amd64 || arm64)) || (windows && (amd64 || arm64))
+
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestSQLiteDriver_TopValues(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&SQLite{}).Connect(filepath.Join(t.TempDir(), "test.db"))
+	r.NoError(err)
+	defer driver.Close()
+
+	_, err = driver.Query(context.Background(), `
+		CREATE TABLE users (id INTEGER, country TEXT);
+		INSERT INTO users VALUES (1, 'SI'), (2, 'DE'), (3, NULL), (4, 'SI'), (5, NULL), (6, 'SI'), (7, 'AT');
+	`)
+	r.NoError(err)
+
+	query := driver.(core.TopValuer).TopValues(&core.TableOptions{Table: "users"}, "country", 2)
+
+	rows, err := driver.Query(context.Background(), query)
+	r.NoError(err)
+	defer rows.Close()
+
+	r.Equal(core.Header{"country", "cnt"}, rows.Header())
+
+	var got []core.Row
+	for rows.HasNext() {
+		row, err := rows.Next()
+		r.NoError(err)
+		got = append(got, row)
+	}
+
+	// nulls are grouped as a value of their own
+	r.Equal([]core.Row{
+		{"SI", int64(3)},
+		{nil, int64(2)},
+	}, got)
+}
+
+func TestSQLiteDriver_Booleans(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&SQLite{}).Connect(filepath.Join(t.TempDir(), "test.db"))
+	r.NoError(err)
+	defer driver.Close()
+
+	_, err = driver.Query(context.Background(), `
+		CREATE TABLE flags (id INTEGER, active BOOLEAN, deleted BOOL);
+		INSERT INTO flags VALUES (1, 1, 0), (2, 0, NULL), (3, 'true', 'f');
+	`)
+	r.NoError(err)
+
+	rows, err := driver.Query(context.Background(), `SELECT id, active, deleted FROM flags ORDER BY id`)
+	r.NoError(err)
+	defer rows.Close()
+
+	var got []core.Row
+	for rows.HasNext() {
+		row, err := rows.Next()
+		r.NoError(err)
+		got = append(got, row)
+	}
+
+	// sqlite stores booleans as numbers, declared boolean columns are normalized
+	r.Equal([]core.Row{
+		{int64(1), true, false},
+		{int64(2), false, nil},
+		{int64(3), true, false},
+	}, got)
+}
+
+func TestSQLiteDriver_ImportTable(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&SQLite{}).Connect(filepath.Join(t.TempDir(), "scratch.db"))
+	r.NoError(err)
+	defer driver.Close()
+
+	importer := driver.(core.TableImporter)
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	source := mock.NewResultStream([]core.Row{
+		{1, "Alice", 1.5, true, created, map[string]any{"a": 1}},
+		{json.Number("2"), nil, 2, false, nil, nil},
+	}, mock.ResultStreamWithHeader(core.Header{"id", "name", "score", "active", "created", "id"}))
+
+	count, err := importer.ImportTable(context.Background(), "users", source)
+	r.NoError(err)
+	r.Equal(2, count)
+
+	columns, err := driver.Columns(&core.TableOptions{Table: "users"})
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "name", Type: "TEXT"},
+		{Name: "score", Type: "REAL"},
+		{Name: "active", Type: "INTEGER"},
+		{Name: "created", Type: "TEXT"},
+		{Name: "id_5", Type: "TEXT"},
+	}, columns)
+
+	query := func(query string) []core.Row {
+		rows, err := driver.Query(context.Background(), query)
+		r.NoError(err)
+		defer rows.Close()
+
+		var got []core.Row
+		for rows.HasNext() {
+			row, err := rows.Next()
+			r.NoError(err)
+			got = append(got, row)
+		}
+		return got
+	}
+
+	got := query(`SELECT id, name, score, active, id_5 FROM users ORDER BY id`)
+	r.Equal([]core.Row{
+		{int64(1), "Alice", 1.5, int64(1), `{"a":1}`},
+		{int64(2), nil, float64(2), int64(0), nil},
+	}, got)
+
+	// re-import replaces the table
+	source = mock.NewResultStream([]core.Row{{"x"}}, mock.ResultStreamWithHeader(core.Header{"value"}))
+	count, err = importer.ImportTable(context.Background(), "users", source)
+	r.NoError(err)
+	r.Equal(1, count)
+	r.Equal([]core.Row{{"x"}}, query(`SELECT * FROM users`))
+}
+
+func TestSQLiteDriver_UniqueKeys(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&SQLite{}).Connect(filepath.Join(t.TempDir(), "test.db"))
+	r.NoError(err)
+	defer driver.Close()
+
+	_, err = driver.Query(context.Background(), `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT UNIQUE, country TEXT, name TEXT);
+		CREATE UNIQUE INDEX users_country_name ON users (country, name);
+		CREATE INDEX users_name ON users (name);
+		CREATE TABLE events (day TEXT, seq INTEGER, PRIMARY KEY (day, seq));
+	`)
+	r.NoError(err)
+
+	lister := driver.(core.UniqueKeyLister)
+
+	// rowid alias has no index
+	keys, err := lister.UniqueKeys(&core.TableOptions{Table: "users"})
+	r.NoError(err)
+	r.Equal([][]string{{"id"}, {"email"}, {"country", "name"}}, keys)
+
+	keys, err = lister.UniqueKeys(&core.TableOptions{Table: "events"})
+	r.NoError(err)
+	r.Equal([][]string{{"day", "seq"}, {"day", "seq"}}, keys)
+}
+
+func TestSQLiteDriver_Structure(t *testing.T) {
+	r := require.New(t)
+
+	driver, err := (&SQLite{}).Connect(filepath.Join(t.TempDir(), "app.db"))
+	r.NoError(err)
+	defer driver.Close()
+
+	_, err = driver.Query(context.Background(), `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT UNIQUE, name TEXT);
+		CREATE INDEX users_name ON users (name DESC);
+		CREATE VIEW names AS SELECT name FROM users;
+	`)
+	r.NoError(err)
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name: "app.db",
+			Type: core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "names", Type: core.StructureTypeView},
+				{
+					Name: "users",
+					Type: core.StructureTypeTable,
+					Children: []*core.Structure{
+						{Name: "sqlite_autoindex_users_1", Type: core.StructureTypeIndex},
+						{Name: "users_name", Type: core.StructureTypeIndex},
+					},
+				},
+			},
+		},
+	}, structure)
+
+	rows, err := driver.(core.Describer).Describe(context.Background(), &core.TableOptions{
+		Table:           "users_name",
+		Materialization: core.StructureTypeIndex,
+	})
+	r.NoError(err)
+	defer rows.Close()
+
+	r.True(rows.HasNext())
+	row, err := rows.Next()
+	r.NoError(err)
+	r.Equal(core.Row{int64(0), "name", int64(1), "BINARY", "CREATE INDEX users_name ON users (name DESC)"}, row)
+}
+
+func TestSQLite_ParseURL(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "my #1 data.db")
+
+	driver, err := (&SQLite{}).Connect(path)
+	r.NoError(err)
+	_, err = driver.Query(context.Background(), `CREATE TABLE t (id INTEGER)`)
+	r.NoError(err)
+	driver.Close()
+
+	// the file is opened read-only
+	driver, err = (&SQLite{}).Connect(path + "?mode=ro&cache=shared")
+	r.NoError(err)
+	defer driver.Close()
+
+	_, err = driver.Query(context.Background(), `SELECT * FROM t`)
+	r.NoError(err)
+	_, err = driver.Query(context.Background(), `INSERT INTO t VALUES (1)`)
+	r.ErrorContains(err, "readonly")
+
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal("my #1 data.db", structure[0].Name)
+
+	r.ErrorContains((&SQLite{}).Validate(path+"?mode=readonly"), `invalid value of parameter "mode"`)
+	r.ErrorContains((&SQLite{}).Validate(path+"?cache=yes"), `invalid value of parameter "cache"`)
+	r.NoError((&SQLite{}).Validate("file::memory:?cache=shared"))
+}
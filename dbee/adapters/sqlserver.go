@@ -25,12 +25,31 @@ var _ core.Adapter = (*SQLServer)(nil)
 
 type SQLServer struct{}
 
+// Connect creates a [SQLServer] client. Besides the options of go-mssqldb, the url
+// accepts "auth=gssapi", which authenticates with a kerberos ticket from the credential
+// cache (a shorthand for the "krb5" authenticator with default config and cache locations).
 func (s *SQLServer) Connect(url string) (core.Driver, error) {
 	u, err := nurl.Parse(url)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
 	}
 
+	params := u.Query()
+	krb, err := newKerberosConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	if krb != nil {
+		// fail early if there is no valid ticket
+		if _, err := krb.client(); err != nil {
+			return nil, err
+		}
+		params.Set("authenticator", "krb5")
+		params.Set(kerberosParamConfigFile, krb.configFile)
+		params.Set(kerberosParamCredCacheFile, krb.credCacheFile)
+		u.RawQuery = params.Encode()
+	}
+
 	db, err := sql.Open("sqlserver", u.String())
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to sqlserver database: %v", err)
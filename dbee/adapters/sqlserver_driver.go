@@ -5,14 +5,25 @@ import (
 	"database/sql"
 	"fmt"
 	nurl "net/url"
+	"strings"
+	"time"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
 )
 
 var (
-	_ core.Driver           = (*sqlServerDriver)(nil)
-	_ core.DatabaseSwitcher = (*sqlServerDriver)(nil)
+	_ core.Driver            = (*sqlServerDriver)(nil)
+	_ core.ContextStructurer = (*sqlServerDriver)(nil)
+	_ core.DatabaseSwitcher  = (*sqlServerDriver)(nil)
+	_ core.IdleCloser        = (*sqlServerDriver)(nil)
+	_ core.Limiter           = (*sqlServerDriver)(nil)
+	_ core.ParamQuerier      = (*sqlServerDriver)(nil)
+	_ core.Peeker            = (*sqlServerDriver)(nil)
+	_ core.ProcedureCaller   = (*sqlServerDriver)(nil)
+	_ core.SafeScanner       = (*sqlServerDriver)(nil)
+	_ core.StatementLogger   = (*sqlServerDriver)(nil)
+	_ core.TopValuer         = (*sqlServerDriver)(nil)
 )
 
 type sqlServerDriver struct {
@@ -28,20 +39,40 @@ func (c *sqlServerDriver) Query(ctx context.Context, query string) (core.ResultS
 func (c *sqlServerDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
 	return c.c.ColumnsFromQuery(`
 		SELECT
-			column_name,
-			data_type
-		FROM information_schema.columns
-			WHERE table_name='%s' AND
-			table_schema = '%s'`,
+			c.column_name,
+			c.data_type,
+			-- "max" types report -1
+			NULLIF(c.character_maximum_length, -1),
+			c.numeric_precision,
+			c.numeric_scale,
+			CASE WHEN cc.name IS NULL THEN 0 ELSE 1 END,
+			cc.definition
+		FROM information_schema.columns c
+		LEFT JOIN sys.computed_columns cc ON
+			cc.object_id = OBJECT_ID(QUOTENAME(c.table_schema) + '.' + QUOTENAME(c.table_name)) AND
+			cc.name = c.column_name
+			WHERE c.table_name='%s' AND
+			c.table_schema = '%s'
+		ORDER BY c.ordinal_position`,
 		opts.Table,
 		opts.Schema,
 	)
 }
 
 func (c *sqlServerDriver) Structure() ([]*core.Structure, error) {
-	query := `SELECT table_schema, table_name FROM INFORMATION_SCHEMA.TABLES`
+	return c.StructureCtx(context.Background())
+}
 
-	rows, err := c.Query(context.TODO(), query)
+// StructureCtx lists tables and views of INFORMATION_SCHEMA.TABLES and procedures and
+// functions of sys.objects (INFORMATION_SCHEMA.ROUTINES leaves out CLR procedures).
+func (c *sqlServerDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	query := `
+		SELECT table_schema, table_name, table_type FROM INFORMATION_SCHEMA.TABLES
+		UNION ALL
+		SELECT SCHEMA_NAME(schema_id), name, RTRIM(type) FROM sys.objects
+		WHERE type IN ('P', 'PC', 'FN', 'IF', 'TF', 'FS', 'FT') AND is_ms_shipped = 0`
+
+	rows, err := c.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -54,17 +85,20 @@ func (c *sqlServerDriver) Structure() ([]*core.Structure, error) {
 			return nil, err
 		}
 
-		// We know for a fact there are 2 string fields (see query above)
+		// We know for a fact there are 3 string fields (see query above)
 		schema := row[0].(string)
 		table := row[1].(string)
 
 		children[schema] = append(children[schema], &core.Structure{
 			Name:   table,
 			Schema: schema,
-			Type:   core.StructureTypeTable,
+			Type:   getSQLServerStructureType(row[2].(string)),
 		})
 
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	var layout []*core.Structure
 
@@ -80,10 +114,59 @@ func (c *sqlServerDriver) Structure() ([]*core.Structure, error) {
 	return layout, nil
 }
 
+// getSQLServerStructureType returns the structure type of the table type
+// or the sys.objects type of routines.
+func getSQLServerStructureType(typ string) core.StructureType {
+	switch typ {
+	case "BASE TABLE":
+		return core.StructureTypeTable
+	case "VIEW":
+		return core.StructureTypeView
+	case "P", "PC":
+		return core.StructureTypeProcedure
+	case "FN", "IF", "TF", "FS", "FT":
+		return core.StructureTypeFunction
+	default:
+		return core.StructureTypeNone
+	}
+}
+
 func (c *sqlServerDriver) Close() {
 	c.c.Close()
 }
 
+func (c *sqlServerDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectTop
+}
+
+func (c *sqlServerDriver) QueryParams(ctx context.Context, query string, args []any) (core.ResultStream, error) {
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *sqlServerDriver) PlaceholderStyle() core.PlaceholderStyle {
+	return core.PlaceholderAtP
+}
+
+func (c *sqlServerDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteBracket, core.LimitDialectTop)
+}
+
+func (c *sqlServerDriver) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	return c.c.Peek(ctx, query)
+}
+
+func (c *sqlServerDriver) SetSafeScan(enabled bool) {
+	c.c.SetSafeScan(enabled)
+}
+
+func (c *sqlServerDriver) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.c.SetStatementLog(fn)
+}
+
+func (c *sqlServerDriver) SetIdleTimeout(timeout time.Duration) {
+	c.c.SetIdleTimeout(timeout)
+}
+
 func (c *sqlServerDriver) ListDatabases() (current string, available []string, err error) {
 	query := `
 		SELECT DB_NAME(), name
@@ -110,17 +193,120 @@ func (c *sqlServerDriver) ListDatabases() (current string, available []string, e
 	return current, available, nil
 }
 
+// SelectDatabase reconnects with the database in the "database" parameter of the url.
+// On error, the url of the current database is kept.
 func (c *sqlServerDriver) SelectDatabase(name string) error {
-	q := c.url.Query()
+	u := *c.url
+	q := u.Query()
 	q.Set("database", name)
-	c.url.RawQuery = q.Encode()
+	u.RawQuery = q.Encode()
 
-	db, err := sql.Open("sqlserver", c.url.String())
+	db, err := sql.Open("sqlserver", u.String())
 	if err != nil {
 		return fmt.Errorf("unable to switch databases: %w", err)
 	}
 
+	c.url.RawQuery = u.RawQuery
 	c.c.Swap(db)
 
 	return nil
 }
+
+func (c *sqlServerDriver) ListProcedures() ([]*core.Structure, error) {
+	query := `SELECT routine_schema, routine_name, routine_type FROM INFORMATION_SCHEMA.ROUTINES`
+
+	rows, err := c.Query(context.TODO(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]*core.Structure)
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		// We know for a fact there are 3 string fields (see query above)
+		schema := row[0].(string)
+		typ := core.StructureTypeFunction
+		if row[2].(string) == "PROCEDURE" {
+			typ = core.StructureTypeProcedure
+		}
+
+		children[schema] = append(children[schema], &core.Structure{
+			Name:   row[1].(string),
+			Schema: schema,
+			Type:   typ,
+		})
+	}
+
+	var layout []*core.Structure
+
+	for k, v := range children {
+		layout = append(layout, &core.Structure{
+			Name:     k,
+			Schema:   k,
+			Type:     core.StructureTypeNone,
+			Children: v,
+		})
+	}
+
+	return layout, nil
+}
+
+func (c *sqlServerDriver) ProcedureParameters(opts *core.TableOptions) ([]*core.Column, error) {
+	// function return value is stored with ordinal position 0
+	return c.c.ColumnsFromQuery(`
+		SELECT parameter_name, data_type FROM INFORMATION_SCHEMA.PARAMETERS
+		WHERE specific_schema = '%s' AND specific_name = '%s' AND ordinal_position > 0
+		ORDER BY ordinal_position`, opts.Schema, opts.Table)
+}
+
+func (c *sqlServerDriver) CallProcedure(ctx context.Context, opts *core.TableOptions, args []any) (core.ResultStream, error) {
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = fmt.Sprintf("@p%d", i+1)
+	}
+	name := fmt.Sprintf("[%s].[%s]", opts.Schema, opts.Table)
+
+	var query string
+	switch opts.Materialization {
+	case core.StructureTypeProcedure:
+		query = fmt.Sprintf("EXEC %s %s", name, strings.Join(placeholders, ", "))
+	case core.StructureTypeFunction:
+		// table valued functions are selected from, scalar ones are selected
+		tableFunc, err := c.isTableFunction(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if tableFunc {
+			query = fmt.Sprintf("SELECT * FROM %s(%s)", name, strings.Join(placeholders, ", "))
+		} else {
+			query = fmt.Sprintf("SELECT %s(%s) AS result", name, strings.Join(placeholders, ", "))
+		}
+	default:
+		return nil, fmt.Errorf("cannot call object of type %q", opts.Materialization)
+	}
+
+	return c.c.QueryArgs(ctx, query, args...)
+}
+
+func (c *sqlServerDriver) isTableFunction(ctx context.Context, name string) (bool, error) {
+	rows, err := c.c.Query(ctx, fmt.Sprintf("SELECT OBJECTPROPERTY(OBJECT_ID('%s'), 'IsTableFunction')", name))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return false, nil
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return false, err
+	}
+
+	return fmt.Sprint(row[0]) == "1", nil
+}
@@ -0,0 +1,60 @@
+package adapters
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+)
+
+// sqlTestConnector is a database/sql connector and connection in one, which records
+// queries and answers them with the rows returned by respond.
+type sqlTestConnector struct {
+	respond func(query string) driver.Rows
+	queries []string
+}
+
+func (c *sqlTestConnector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *sqlTestConnector) Driver() driver.Driver                        { return nil }
+func (c *sqlTestConnector) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (c *sqlTestConnector) Close() error                                 { return nil }
+func (c *sqlTestConnector) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+
+func (c *sqlTestConnector) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.queries = append(c.queries, query)
+	return c.respond(query), nil
+}
+
+// sqlTestRows are fixed rows of a sqlTestConnector. Database type names and
+// lengths of the columns are optional.
+type sqlTestRows struct {
+	columns []string
+	types   []string
+	lengths []int64
+	rows    [][]driver.Value
+}
+
+func (r *sqlTestRows) Columns() []string { return r.columns }
+func (r *sqlTestRows) Close() error      { return nil }
+
+func (r *sqlTestRows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.types) {
+		return r.types[i]
+	}
+	return ""
+}
+
+func (r *sqlTestRows) ColumnTypeLength(i int) (int64, bool) {
+	if i < len(r.lengths) {
+		return r.lengths[i], true
+	}
+	return 0, false
+}
+
+func (r *sqlTestRows) Next(dest []driver.Value) error {
+	if len(r.rows) < 1 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"fmt"
+	nurl "net/url"
+	"strings"
+
+	"github.com/surrealdb/surrealdb.go"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&SurrealDB{}, "surrealdb", "surreal")
+}
+
+var (
+	_ core.Adapter   = (*SurrealDB)(nil)
+	_ core.Validator = (*SurrealDB)(nil)
+)
+
+type SurrealDB struct{}
+
+// Connect creates a [SurrealDB] client connected over websockets.
+// The format of the url is as follows:
+//
+//	surrealdb://[user:password@]host:port/namespace/database[?options]
+//
+// Where:
+//   - "surrealdbs" scheme can be used instead of "surrealdb" to connect over wss.
+//
+// The supported "options" are:
+//   - auth=root|namespace|database - level of the user to sign in as (default: root).
+func (s *SurrealDB) Connect(rawURL string) (core.Driver, error) {
+	if err := s.Validate(rawURL); err != nil {
+		return nil, err
+	}
+
+	u, _ := nurl.Parse(rawURL)
+	scheme := "ws"
+	if u.Scheme == "surrealdbs" || u.Scheme == "wss" {
+		scheme = "wss"
+	}
+
+	ns, dbName, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+
+	auth := &surrealdb.Auth{}
+	switch u.Query().Get("auth") {
+	case "namespace", "ns":
+		auth.Namespace = ns
+	case "database", "db":
+		auth.Namespace = ns
+		auth.Database = dbName
+	}
+
+	db, err := surrealdb.New(fmt.Sprintf("%s://%s", scheme, u.Host))
+	if err != nil {
+		return nil, fmt.Errorf("surrealdb.New: %w", err)
+	}
+
+	if u.User != nil {
+		auth.Username = u.User.Username()
+		auth.Password, _ = u.User.Password()
+
+		if _, err := db.SignIn(auth); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("db.SignIn: %w", err)
+		}
+	}
+
+	driver := &surrealDBDriver{c: db}
+	if err := driver.use(ns, dbName); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return driver, nil
+}
+
+func (*SurrealDB) Validate(rawURL string) error {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	switch u.Scheme {
+	case "surrealdb", "surreal", "ws", "surrealdbs", "wss":
+	default:
+		return fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	ns, dbName, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	if ns == "" || dbName == "" {
+		return fmt.Errorf("url must be in form surrealdb://host:port/namespace/database, got: %q", rawURL)
+	}
+
+	switch level := u.Query().Get("auth"); level {
+	case "", "root", "namespace", "ns", "database", "db":
+	default:
+		return fmt.Errorf("unknown auth level: %q", level)
+	}
+	return nil
+}
+
+func (*SurrealDB) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"List":  fmt.Sprintf("SELECT * FROM `%s` LIMIT 100;", opts.Table),
+		"Info":  fmt.Sprintf("INFO FOR TABLE `%s`;", opts.Table),
+		"Count": fmt.Sprintf("SELECT count() FROM `%s` GROUP ALL;", opts.Table),
+	}
+}
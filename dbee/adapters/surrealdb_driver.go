@@ -0,0 +1,386 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go"
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver           = (*surrealDBDriver)(nil)
+	_ core.DatabaseSwitcher = (*surrealDBDriver)(nil)
+)
+
+// surrealFieldType matches the type of "DEFINE FIELD" statement.
+var surrealFieldType = regexp.MustCompile(`(?i)\sTYPE\s+(.+?)(?:\s+(?:DEFAULT|VALUE|ASSERT|READONLY|PERMISSIONS|COMMENT|FLEXIBLE)\b|$)`)
+
+type surrealDBDriver struct {
+	c      *surrealdb.DB
+	ns     string
+	dbName string
+}
+
+// use switches the namespace and database of the session
+// (same as "USE NS ns DB db").
+func (c *surrealDBDriver) use(ns, dbName string) error {
+	if err := c.c.Use(ns, dbName); err != nil {
+		return fmt.Errorf("c.c.Use: %w", err)
+	}
+	c.ns = ns
+	c.dbName = dbName
+	return nil
+}
+
+// query runs the query and returns results of all statements.
+func (c *surrealDBDriver) query(ctx context.Context, query string) ([]surrealdb.QueryResult[any], error) {
+	type response struct {
+		results *[]surrealdb.QueryResult[any]
+		err     error
+	}
+
+	// client doesn't support contexts, so at least stop waiting on cancel
+	ch := make(chan response, 1)
+	go func() {
+		results, err := surrealdb.Query[any](c.c, query, nil)
+		ch <- response{results: results, err: err}
+	}()
+
+	var resp response
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp = <-ch:
+	}
+
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	if resp.results == nil {
+		return nil, errors.New("no results returned")
+	}
+
+	// response contains a result per statement
+	for i, res := range *resp.results {
+		if res.Status != "OK" {
+			return nil, fmt.Errorf("statement %d: %v", i+1, res.Result)
+		}
+	}
+
+	return *resp.results, nil
+}
+
+// queryObject runs a single statement that returns an object (e.g. INFO FOR DB).
+func (c *surrealDBDriver) queryObject(ctx context.Context, query string) (map[string]any, error) {
+	results, err := c.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) < 1 {
+		return nil, errors.New("no results returned")
+	}
+
+	obj, ok := results[len(results)-1].Result.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %T", results[len(results)-1].Result)
+	}
+	return obj, nil
+}
+
+func (c *surrealDBDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	results, err := c.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) < 1 {
+		return nil, errors.New("no results returned")
+	}
+
+	// only the result of the last statement is displayed
+	header, rows := surrealResultToRows(results[len(results)-1])
+
+	i := 0
+	hasNext := func() bool {
+		return i < len(rows)
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		i++
+		return rows[i-1], nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		Build(), nil
+}
+
+// surrealResultToRows converts the statement result to a table.
+// Records (objects) are converted to a row each, with nested object
+// fields flattened to "parent.child" columns. Anything else is displayed
+// in a single "result" column.
+func surrealResultToRows(result surrealdb.QueryResult[any]) (core.Header, []core.Row) {
+	var items []any
+	switch res := result.Result.(type) {
+	case nil, models.CustomNil:
+		return core.Header{"Status", "Time"}, []core.Row{{result.Status, result.Time}}
+	case []any:
+		items = res
+	default:
+		items = []any{res}
+	}
+
+	var records []map[string]any
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			records = nil
+			break
+		}
+		flat := make(map[string]any)
+		surrealFlatten("", obj, flat)
+		records = append(records, flat)
+	}
+
+	// not a list of records
+	if records == nil {
+		rows := make([]core.Row, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, core.Row{surrealValue(item)})
+		}
+		return core.Header{"result"}, rows
+	}
+
+	columnSet := make(map[string]struct{})
+	for _, rec := range records {
+		for k := range rec {
+			columnSet[k] = struct{}{}
+		}
+	}
+
+	// "id" goes first, others are sorted
+	var header core.Header
+	for k := range columnSet {
+		if k != "id" {
+			header = append(header, k)
+		}
+	}
+	sort.Strings(header)
+	if _, ok := columnSet["id"]; ok {
+		header = append(core.Header{"id"}, header...)
+	}
+
+	rows := make([]core.Row, 0, len(records))
+	for _, rec := range records {
+		row := make(core.Row, len(header))
+		for i, h := range header {
+			row[i] = rec[h]
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows
+}
+
+// surrealFlatten flattens nested objects into dst.
+func surrealFlatten(prefix string, obj map[string]any, dst map[string]any) {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			surrealFlatten(key, nested, dst)
+			continue
+		}
+		dst[key] = surrealValue(v)
+	}
+}
+
+// surrealValue converts the decoded value into a displayable
+// (and gob encodable) value.
+func surrealValue(v any) any {
+	switch val := v.(type) {
+	case nil, models.CustomNil, *models.CustomNil:
+		return nil
+	case string, bool, int64, uint64, float32, float64:
+		return val
+	case models.RecordID:
+		return val.String()
+	case *models.RecordID:
+		return val.String()
+	case models.CustomDateTime:
+		return val.Time.Format(time.RFC3339Nano)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case []byte:
+		return fmt.Sprintf("0x%x", val)
+	case map[string]any, []any:
+		b, err := json.Marshal(surrealJSONValue(val))
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(b)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// surrealJSONValue prepares nested values for json encoding.
+func surrealJSONValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			m[k] = surrealJSONValue(item)
+		}
+		return m
+	case []any:
+		s := make([]any, 0, len(val))
+		for _, item := range val {
+			s = append(s, surrealJSONValue(item))
+		}
+		return s
+	default:
+		return surrealValue(val)
+	}
+}
+
+// surrealInfoNames returns sorted names of the INFO statement field
+// (e.g. "tables" of INFO FOR DB).
+func surrealInfoNames(info map[string]any, field string) []string {
+	defs, _ := info[field].(map[string]any)
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// surrealStructure converts the result of INFO FOR DB to structure.
+func surrealStructure(info map[string]any) []*core.Structure {
+	var structure []*core.Structure
+
+	for _, name := range surrealInfoNames(info, "tables") {
+		structure = append(structure, &core.Structure{
+			Name:   name,
+			Schema: "",
+			Type:   core.StructureTypeTable,
+		})
+	}
+
+	for _, name := range surrealInfoNames(info, "functions") {
+		structure = append(structure, &core.Structure{
+			Name:   "fn::" + strings.TrimPrefix(name, "fn::"),
+			Schema: "",
+			Type:   core.StructureTypeFunction,
+		})
+	}
+
+	return structure
+}
+
+// surrealColumns converts the result of INFO FOR TABLE to columns.
+func surrealColumns(info map[string]any) []*core.Column {
+	fields, _ := info["fields"].(map[string]any)
+
+	var columns []*core.Column
+	for _, name := range surrealInfoNames(info, "fields") {
+		typ := "any"
+		def, _ := fields[name].(string)
+		if m := surrealFieldType.FindStringSubmatch(def); m != nil {
+			typ = m[1]
+		}
+
+		columns = append(columns, &core.Column{
+			Name: name,
+			Type: typ,
+		})
+	}
+
+	return columns
+}
+
+func (c *surrealDBDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	info, err := c.queryObject(context.Background(), fmt.Sprintf("INFO FOR TABLE `%s`;", opts.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	return surrealColumns(info), nil
+}
+
+func (c *surrealDBDriver) Structure() ([]*core.Structure, error) {
+	info, err := c.queryObject(context.Background(), "INFO FOR DB;")
+	if err != nil {
+		return nil, err
+	}
+
+	return surrealStructure(info), nil
+}
+
+func (c *surrealDBDriver) Close() {
+	_ = c.c.Close()
+}
+
+// ListDatabases lists databases of all namespaces in "namespace/database" form.
+// If the user can't list namespaces, only the current one is used.
+func (c *surrealDBDriver) ListDatabases() (current string, available []string, err error) {
+	ctx := context.Background()
+	current = c.ns + "/" + c.dbName
+
+	namespaces := []string{c.ns}
+	if root, err := c.queryObject(ctx, "INFO FOR ROOT;"); err == nil {
+		if names := surrealInfoNames(root, "namespaces"); len(names) > 0 {
+			namespaces = names
+		}
+	}
+
+	for _, ns := range namespaces {
+		info, err := c.queryObject(ctx, fmt.Sprintf("USE NS `%s`; INFO FOR NS;", ns))
+		if err != nil {
+			continue
+		}
+		for _, db := range surrealInfoNames(info, "databases") {
+			if name := ns + "/" + db; name != current {
+				available = append(available, name)
+			}
+		}
+	}
+
+	// restore the session in case USE statements changed it
+	if err := c.use(c.ns, c.dbName); err != nil {
+		return "", nil, err
+	}
+
+	return current, available, nil
+}
+
+// SelectDatabase switches to "namespace/database" or to a database of
+// the current namespace.
+func (c *surrealDBDriver) SelectDatabase(name string) error {
+	ns, dbName, ok := strings.Cut(name, "/")
+	if !ok {
+		ns, dbName = c.ns, name
+	}
+
+	return c.use(ns, dbName)
+}
@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/surrealdb/surrealdb.go"
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestSurrealResultToRows(t *testing.T) {
+	type testCase struct {
+		name           string
+		result         any
+		expectedHeader core.Header
+		expectedRows   []core.Row
+	}
+
+	testCases := []testCase{
+		{
+			name: "records",
+			result: []any{
+				map[string]any{
+					"id":      models.RecordID{Table: "person", ID: "alice"},
+					"name":    "Alice",
+					"address": map[string]any{"city": "Ljubljana", "geo": map[string]any{"lat": 46.05}},
+					"tags":    []any{"a", "b"},
+				},
+				map[string]any{
+					"id":  models.RecordID{Table: "person", ID: "bob"},
+					"age": uint64(30),
+				},
+			},
+			expectedHeader: core.Header{"id", "address.city", "address.geo.lat", "age", "name", "tags"},
+			expectedRows: []core.Row{
+				{"person:alice", "Ljubljana", 46.05, nil, "Alice", `["a","b"]`},
+				{"person:bob", nil, nil, uint64(30), nil, nil},
+			},
+		},
+		{
+			name:           "single object",
+			result:         map[string]any{"count": uint64(2)},
+			expectedHeader: core.Header{"count"},
+			expectedRows:   []core.Row{{uint64(2)}},
+		},
+		{
+			name:           "scalars",
+			result:         []any{uint64(1), "two", nil},
+			expectedHeader: core.Header{"result"},
+			expectedRows:   []core.Row{{uint64(1)}, {"two"}, {nil}},
+		},
+		{
+			name:           "none",
+			result:         nil,
+			expectedHeader: core.Header{"Status", "Time"},
+			expectedRows:   []core.Row{{"OK", "1ms"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header, rows := surrealResultToRows(surrealdb.QueryResult[any]{
+				Status: "OK",
+				Time:   "1ms",
+				Result: tc.result,
+			})
+			require.Equal(t, tc.expectedHeader, header)
+			require.Equal(t, tc.expectedRows, rows)
+		})
+	}
+}
+
+func TestSurrealStructureAndColumns(t *testing.T) {
+	r := require.New(t)
+
+	structure := surrealStructure(map[string]any{
+		"analyzers": map[string]any{},
+		"functions": map[string]any{"greet": "DEFINE FUNCTION fn::greet($name: string) { ... }"},
+		"tables": map[string]any{
+			"person": "DEFINE TABLE person TYPE NORMAL SCHEMAFULL",
+			"event":  "DEFINE TABLE event TYPE ANY SCHEMALESS",
+		},
+	})
+	r.Equal([]*core.Structure{
+		{Name: "event", Type: core.StructureTypeTable},
+		{Name: "person", Type: core.StructureTypeTable},
+		{Name: "fn::greet", Type: core.StructureTypeFunction},
+	}, structure)
+
+	columns := surrealColumns(map[string]any{
+		"fields": map[string]any{
+			"name":     "DEFINE FIELD name ON person TYPE string PERMISSIONS FULL",
+			"tags":     "DEFINE FIELD tags ON person TYPE option<array<string>> DEFAULT [] PERMISSIONS FULL",
+			"age":      "DEFINE FIELD age ON person TYPE int",
+			"anything": "DEFINE FIELD anything ON person PERMISSIONS FULL",
+		},
+	})
+	r.Equal([]*core.Column{
+		{Name: "age", Type: "int"},
+		{Name: "anything", Type: "any"},
+		{Name: "name", Type: "string"},
+		{Name: "tags", Type: "option<array<string>>"},
+	}, columns)
+}
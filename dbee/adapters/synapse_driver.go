@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	_ core.Driver            = (*synapseDriver)(nil)
+	_ core.ContextStructurer = (*synapseDriver)(nil)
+	_ core.DatabaseSwitcher  = (*synapseDriver)(nil)
+)
+
+// synapseEditionDedicated is the engine edition of dedicated sql pools
+// (serverless pools are 11, fabric warehouses 12).
+const synapseEditionDedicated = "6"
+
+// synapseDriver is a sql server driver aware of external tables and dedicated pools.
+type synapseDriver struct {
+	*sqlServerDriver
+
+	mu      sync.Mutex
+	edition string
+}
+
+func (c *synapseDriver) Structure() ([]*core.Structure, error) {
+	return c.StructureCtx(context.Background())
+}
+
+// StructureCtx lists tables (including distributed tables of dedicated pools),
+// external tables and views. INFORMATION_SCHEMA.TABLES of synapse doesn't
+// tell external tables apart, so the catalog views are used instead.
+func (c *synapseDriver) StructureCtx(ctx context.Context) ([]*core.Structure, error) {
+	query := `
+		SELECT s.name, o.name,
+			CASE
+				WHEN et.object_id IS NOT NULL THEN 'external_table'
+				WHEN o.type = 'V' THEN 'view'
+				ELSE 'table'
+			END
+		FROM sys.objects o
+			JOIN sys.schemas s ON s.schema_id = o.schema_id
+			LEFT JOIN sys.external_tables et ON et.object_id = o.object_id
+		WHERE o.type IN ('U', 'V', 'ET') AND o.is_ms_shipped = 0
+		ORDER BY s.name, o.name`
+
+	rows, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]*core.Structure)
+	var schemas []string
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		// We know for a fact there are 3 string fields (see query above)
+		schema := row[0].(string)
+		if _, ok := children[schema]; !ok {
+			schemas = append(schemas, schema)
+		}
+
+		children[schema] = append(children[schema], &core.Structure{
+			Name:   row[1].(string),
+			Schema: schema,
+			Type:   core.StructureTypeFromString(row[2].(string)),
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	layout := make([]*core.Structure, 0, len(schemas))
+	for _, schema := range schemas {
+		layout = append(layout, &core.Structure{
+			Name:     schema,
+			Schema:   schema,
+			Type:     core.StructureTypeNone,
+			Children: children[schema],
+		})
+	}
+
+	return layout, nil
+}
+
+// dedicated reports whether the connection is to a dedicated sql pool.
+func (c *synapseDriver) dedicated() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.edition == "" {
+		rows, err := c.c.Query(context.TODO(), "SELECT CAST(SERVERPROPERTY('EngineEdition') AS INT)")
+		if err != nil {
+			return false, err
+		}
+		defer rows.Close()
+
+		if !rows.HasNext() {
+			return false, fmt.Errorf("unable to determine engine edition")
+		}
+		row, err := rows.Next()
+		if err != nil {
+			return false, err
+		}
+		c.edition = fmt.Sprint(row[0])
+	}
+
+	return c.edition == synapseEditionDedicated, nil
+}
+
+// ListDatabases lists databases of serverless pools and fabric warehouses.
+// Each dedicated pool is a database of its own, which doesn't support USE,
+// so switching is not supported there.
+func (c *synapseDriver) ListDatabases() (current string, available []string, err error) {
+	dedicated, err := c.dedicated()
+	if err != nil {
+		return "", nil, err
+	}
+	if dedicated {
+		return "", nil, core.ErrDatabaseSwitchingNotSupported
+	}
+
+	return c.sqlServerDriver.ListDatabases()
+}
+
+func (c *synapseDriver) SelectDatabase(name string) error {
+	dedicated, err := c.dedicated()
+	if err != nil {
+		return err
+	}
+	if dedicated {
+		return core.ErrDatabaseSwitchingNotSupported
+	}
+
+	return c.sqlServerDriver.SelectDatabase(name)
+}
@@ -0,0 +1,117 @@
+package adapters
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// synapseTestResponse answers the catalog queries of synapse with fixed rows.
+func synapseTestResponse(edition int64) func(string) driver.Rows {
+	return func(query string) driver.Rows {
+		switch {
+		case strings.Contains(query, "EngineEdition"):
+			return &sqlTestRows{columns: []string{"edition"}, rows: [][]driver.Value{{edition}}}
+		case strings.Contains(query, "sys.external_tables"):
+			return &sqlTestRows{columns: []string{"schema", "name", "type"}, rows: [][]driver.Value{
+				{"dbo", "orders", "table"},
+				{"dbo", "orders_v", "view"},
+				{"staging", "raw_orders", "external_table"},
+			}}
+		case strings.Contains(query, "sys.databases"):
+			return &sqlTestRows{columns: []string{"current", "name"}, rows: [][]driver.Value{{"lake", "master"}}}
+		default:
+			return &sqlTestRows{columns: []string{"Rows Affected"}}
+		}
+	}
+}
+
+func newTestSynapseDriver(edition int64) (*synapseDriver, *sqlTestConnector) {
+	connector := &sqlTestConnector{respond: synapseTestResponse(edition)}
+	return &synapseDriver{
+		sqlServerDriver: &sqlServerDriver{c: builders.NewClient(sql.OpenDB(connector))},
+	}, connector
+}
+
+func TestSynapseDriver_Structure(t *testing.T) {
+	r := require.New(t)
+
+	d, _ := newTestSynapseDriver(6)
+	defer d.Close()
+
+	structure, err := d.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name:   "dbo",
+			Schema: "dbo",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "orders", Schema: "dbo", Type: core.StructureTypeTable},
+				{Name: "orders_v", Schema: "dbo", Type: core.StructureTypeView},
+			},
+		},
+		{
+			Name:   "staging",
+			Schema: "staging",
+			Type:   core.StructureTypeNone,
+			Children: []*core.Structure{
+				{Name: "raw_orders", Schema: "staging", Type: core.StructureTypeExternalTable},
+			},
+		},
+	}, structure)
+}
+
+func TestSynapseDriver_ListDatabases(t *testing.T) {
+	r := require.New(t)
+
+	// dedicated pools don't support USE
+	d, connector := newTestSynapseDriver(6)
+	defer d.Close()
+
+	_, _, err := d.ListDatabases()
+	r.ErrorIs(err, core.ErrDatabaseSwitchingNotSupported)
+	r.ErrorIs(d.SelectDatabase("other"), core.ErrDatabaseSwitchingNotSupported)
+	// the edition is queried once
+	r.Len(connector.queries, 1)
+
+	// serverless pools do
+	d, _ = newTestSynapseDriver(11)
+	defer d.Close()
+
+	current, available, err := d.ListDatabases()
+	r.NoError(err)
+	r.Equal("lake", current)
+	r.Equal([]string{"master"}, available)
+}
+
+func TestSynapse_GetHelpers(t *testing.T) {
+	r := require.New(t)
+
+	helpers := (&Synapse{}).GetHelpers(&core.TableOptions{
+		Schema:          "dbo",
+		Table:           "o'rders",
+		Materialization: core.StructureTypeTable,
+	})
+	r.Contains(helpers["Distribution"], "sys.pdw_table_distribution_properties")
+	r.Contains(helpers["Distribution"], "WHERE t.name = 'o''rders' AND s.name = 'dbo'")
+	r.Contains(helpers, "Resource Classes")
+	r.Contains(helpers, "Primary Keys")
+	r.NotContains(helpers, "External Source")
+
+	helpers = (&Synapse{}).GetHelpers(&core.TableOptions{
+		Schema:          "staging",
+		Table:           "raw_orders",
+		Materialization: core.StructureTypeExternalTable,
+	})
+	r.Contains(helpers["External Source"], "WHERE et.name = 'raw_orders' AND s.name = 'staging'")
+	r.Contains(helpers, "List")
+	r.NotContains(helpers, "Primary Keys")
+	r.NotContains(helpers, "Distribution")
+}
@@ -0,0 +1,476 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	nurl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var (
+	_ core.Driver           = (*trinoDriver)(nil)
+	_ core.DatabaseSwitcher = (*trinoDriver)(nil)
+	_ core.Limiter          = (*trinoDriver)(nil)
+	_ core.TopValuer        = (*trinoDriver)(nil)
+)
+
+// trinoFlavor is the flavor of the server, which decides the names of protocol headers.
+type trinoFlavor int
+
+const (
+	// trinoFlavorUnknown means the flavor is detected on the first request
+	trinoFlavorUnknown trinoFlavor = iota
+	trinoFlavorTrino
+	trinoFlavorPresto
+)
+
+// header returns the name of the protocol header (e.g. "User" becomes "X-Trino-User").
+func (f trinoFlavor) header(name string) string {
+	if f == trinoFlavorPresto {
+		return "X-Presto-" + name
+	}
+	return "X-Trino-" + name
+}
+
+// trinoRenameVersion is the first version released as Trino (older PrestoSQL
+// releases only understand the presto headers).
+const trinoRenameVersion = 351
+
+// trinoDetectFlavor returns the flavor of the server by its version. Presto (prestodb)
+// versions look like "0.283", while PrestoSQL and Trino versions are plain numbers.
+func trinoDetectFlavor(version string) trinoFlavor {
+	version = strings.TrimSpace(version)
+	if strings.HasPrefix(version, "0.") {
+		return trinoFlavorPresto
+	}
+
+	// ignore suffixes like "-SNAPSHOT" or "-e.1"
+	major, _, _ := strings.Cut(version, "-")
+	major, _, _ = strings.Cut(major, ".")
+	if n, err := strconv.Atoi(major); err == nil && n < trinoRenameVersion {
+		return trinoFlavorPresto
+	}
+	return trinoFlavorTrino
+}
+
+type trinoDriver struct {
+	c        *http.Client
+	url      *nurl.URL
+	user     string
+	password string
+	source   string
+
+	// mu guards the session state, which is changed by responses (e.g. to USE statements)
+	mu      sync.Mutex
+	flavor  trinoFlavor
+	catalog string
+	schema  string
+	session map[string]string
+}
+
+// trinoResponse is a page of the statement response.
+type trinoResponse struct {
+	ID      string `json:"id"`
+	NextURI string `json:"nextUri"`
+	Columns []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"columns"`
+	Data        [][]json.RawMessage `json:"data"`
+	Error       *trinoError         `json:"error"`
+	UpdateType  string              `json:"updateType"`
+	UpdateCount *int64              `json:"updateCount"`
+}
+
+// trinoError is the error of a failed query. It's sent in the statement response by
+// both flavors, while some errors of the http layer are sent as the whole response body.
+type trinoError struct {
+	Message       string `json:"message"`
+	ErrorName     string `json:"errorName"`
+	ErrorType     string `json:"errorType"`
+	ErrorLocation *struct {
+		LineNumber   int `json:"lineNumber"`
+		ColumnNumber int `json:"columnNumber"`
+	} `json:"errorLocation"`
+}
+
+func (e *trinoError) Error() string {
+	msg := e.Message
+	// trino includes the location in the message, presto doesn't
+	if e.ErrorLocation != nil && !strings.HasPrefix(msg, "line ") {
+		msg = fmt.Sprintf("line %d:%d: %s", e.ErrorLocation.LineNumber, e.ErrorLocation.ColumnNumber, msg)
+	}
+	if e.ErrorName != "" {
+		return fmt.Sprintf("%s: %s", e.ErrorName, msg)
+	}
+	return msg
+}
+
+// trinoResponseError converts an unsuccessful http response to an error. The body is either
+// plain text, a json error or a statement response with an error, depending on the flavor
+// and the layer which refused the request (e.g. a proxy).
+func trinoResponseError(status string, body []byte) error {
+	var resp struct {
+		trinoError
+		Error *trinoError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil {
+		if resp.Error != nil {
+			return fmt.Errorf("server responded with %q: %w", status, resp.Error)
+		}
+		if resp.Message != "" {
+			return fmt.Errorf("server responded with %q: %w", status, &resp.trinoError)
+		}
+	}
+	return fmt.Errorf("server responded with %q: %s", status, strings.TrimSpace(string(body)))
+}
+
+// detectFlavor returns the flavor of the server, which is detected from the info
+// endpoint on the first request (unless it's set with the "flavor" option).
+func (c *trinoDriver) detectFlavor(ctx context.Context) (trinoFlavor, error) {
+	c.mu.Lock()
+	flavor := c.flavor
+	c.mu.Unlock()
+	if flavor != trinoFlavorUnknown {
+		return flavor, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url.JoinPath("v1", "info").String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	if c.password != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("c.c.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("could not detect server flavor (set the flavor option): %w", trinoResponseError(resp.Status, body))
+	}
+
+	var info struct {
+		NodeVersion struct {
+			Version string `json:"version"`
+		} `json:"nodeVersion"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return 0, fmt.Errorf("could not detect server flavor (set the flavor option): %w", err)
+	}
+
+	flavor = trinoDetectFlavor(info.NodeVersion.Version)
+
+	c.mu.Lock()
+	c.flavor = flavor
+	c.mu.Unlock()
+
+	return flavor, nil
+}
+
+// do sends the request with protocol headers of the server flavor and decodes the response.
+// Requests refused because the server is busy are retried.
+func (c *trinoDriver) do(ctx context.Context, method, url string, body string) (*trinoResponse, error) {
+	flavor, err := c.detectFlavor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+		}
+		c.setHeaders(req, flavor)
+
+		resp, err := c.c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("c.c.Do: %w", err)
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading response: %w", err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if attempt < 5 {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+				}
+				continue
+			}
+			return nil, trinoResponseError(resp.Status, b)
+		default:
+			return nil, trinoResponseError(resp.Status, b)
+		}
+
+		c.updateSession(resp.Header, flavor)
+
+		var page trinoResponse
+		if err := json.Unmarshal(b, &page); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		if page.Error != nil {
+			return nil, page.Error
+		}
+		return &page, nil
+	}
+}
+
+func (c *trinoDriver) setHeaders(req *http.Request, flavor trinoFlavor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	req.Header.Set(flavor.header("User"), c.user)
+	req.Header.Set(flavor.header("Source"), c.source)
+	if c.catalog != "" {
+		req.Header.Set(flavor.header("Catalog"), c.catalog)
+	}
+	if c.schema != "" {
+		req.Header.Set(flavor.header("Schema"), c.schema)
+	}
+
+	properties := make([]string, 0, len(c.session))
+	for key, value := range c.session {
+		properties = append(properties, key+"="+nurl.QueryEscape(value))
+	}
+	sort.Strings(properties)
+	for _, property := range properties {
+		req.Header.Add(flavor.header("Session"), property)
+	}
+
+	if c.password != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+}
+
+// updateSession applies changes of the session requested by the server
+// (e.g. "USE catalog.schema" or "SET SESSION" statements).
+func (c *trinoDriver) updateSession(header http.Header, flavor trinoFlavor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if catalog := header.Get(flavor.header("Set-Catalog")); catalog != "" {
+		c.catalog = catalog
+	}
+	if schema := header.Get(flavor.header("Set-Schema")); schema != "" {
+		c.schema = schema
+	}
+	for _, property := range header.Values(flavor.header("Set-Session")) {
+		key, value, _ := strings.Cut(property, "=")
+		if unescaped, err := nurl.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		c.session[strings.TrimSpace(key)] = value
+	}
+	for _, key := range header.Values(flavor.header("Clear-Session")) {
+		delete(c.session, strings.TrimSpace(key))
+	}
+}
+
+// cancel cancels the query which hasn't been fully retrieved.
+func (c *trinoDriver) cancel(nextURI string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, nextURI, nil)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	flavor := c.flavor
+	c.mu.Unlock()
+	c.setHeaders(req, flavor)
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+func (c *trinoDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	page, err := c.do(ctx, http.MethodPost, c.url.JoinPath("v1", "statement").String(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	// columns are sent once the query is planned
+	for page.Columns == nil && page.NextURI != "" {
+		page, err = c.do(ctx, http.MethodGet, page.NextURI, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if page.Columns == nil {
+		if page.UpdateCount != nil {
+			next, hasNext := builders.NextSingle(*page.UpdateCount)
+			return builders.NewResultStreamBuilder().
+				WithNextFunc(next, hasNext).
+				WithHeader(core.Header{"Rows Affected"}).
+				Build(), nil
+		}
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			Build(), nil
+	}
+
+	header := make(core.Header, len(page.Columns))
+	for i, column := range page.Columns {
+		header[i] = column.Name
+	}
+
+	// pages are fetched while the rows are retrieved
+	data := page.Data
+	nextURI := page.NextURI
+	var fetchErr error
+	i := 0
+
+	hasNext := func() bool {
+		for i >= len(data) && nextURI != "" && fetchErr == nil {
+			page, err := c.do(ctx, http.MethodGet, nextURI, "")
+			if err != nil {
+				fetchErr = err
+				break
+			}
+			data, nextURI, i = page.Data, page.NextURI, 0
+		}
+		return i < len(data) || fetchErr != nil
+	}
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		if i >= len(data) {
+			err := fetchErr
+			fetchErr = nil
+			nextURI = ""
+			return nil, err
+		}
+
+		row := make(core.Row, len(data[i]))
+		for j, val := range data[i] {
+			row[j] = rawJSONValue(val)
+		}
+		i++
+		return row, nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		WithCloseFunc(func() {
+			if nextURI != "" {
+				c.cancel(nextURI)
+			}
+		}).
+		Build(), nil
+}
+
+func (c *trinoDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	rows, err := c.Query(context.Background(), fmt.Sprintf(`
+		SELECT column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = '%s' AND table_name = '%s'
+		ORDER BY ordinal_position`, opts.Schema, opts.Table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return builders.ColumnsFromResultStream(rows)
+}
+
+// Structure lists tables and views of the current catalog.
+func (c *trinoDriver) Structure() ([]*core.Structure, error) {
+	c.mu.Lock()
+	catalog := c.catalog
+	c.mu.Unlock()
+	if catalog == "" {
+		return nil, errors.New("no catalog selected: add it to the url path or select it as a database")
+	}
+
+	rows, err := c.Query(context.Background(), `
+		SELECT table_schema, table_name, table_type
+		FROM information_schema.tables
+		WHERE table_schema != 'information_schema'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return getPGStructure(context.Background(), rows)
+}
+
+// ListDatabases lists catalogs.
+func (c *trinoDriver) ListDatabases() (current string, available []string, err error) {
+	rows, err := c.Query(context.TODO(), "SHOW CATALOGS")
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	c.mu.Lock()
+	current = c.catalog
+	c.mu.Unlock()
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return "", nil, err
+		}
+
+		if catalog := fmt.Sprint(row[0]); catalog != current {
+			available = append(available, catalog)
+		}
+	}
+
+	return current, available, nil
+}
+
+// SelectDatabase selects the catalog (the schema is reset).
+func (c *trinoDriver) SelectDatabase(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.catalog = name
+	c.schema = ""
+	return nil
+}
+
+func (c *trinoDriver) Close() {
+	c.c.CloseIdleConnections()
+}
+
+func (c *trinoDriver) LimitDialect() core.LimitDialect {
+	return core.LimitDialectLimit
+}
+
+func (c *trinoDriver) TopValues(opts *core.TableOptions, column string, n int) string {
+	return builders.TopValuesQuery(opts, column, n, builders.QuoteDouble, core.LimitDialectLimit)
+}
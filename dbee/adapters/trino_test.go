@@ -0,0 +1,177 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// newTrinoTestServer emulates a coordinator of the given version, which only understands
+// headers with the prefix of its flavor.
+func newTrinoTestServer(t *testing.T, version, prefix string, infoCalls *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v1/info", func(w http.ResponseWriter, r *http.Request) {
+		infoCalls.Add(1)
+		fmt.Fprintf(w, `{"nodeVersion":{"version":%q},"coordinator":true,"starting":false}`, version)
+	})
+
+	mux.HandleFunc("/v1/statement", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(prefix+"User") != "alice" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Error 400 Bad Request: %sUser must be set", prefix)
+			return
+		}
+		require.Equal(t, "nvim-dbee", r.Header.Get(prefix+"Source"))
+		require.Equal(t, "hive", r.Header.Get(prefix+"Catalog"))
+		require.Equal(t, []string{"query_max_run_time=10m"}, r.Header.Values(prefix+"Session"))
+
+		b, _ := io.ReadAll(r.Body)
+		query := string(b)
+		switch {
+		case strings.HasPrefix(query, "USE"):
+			w.Header().Set(prefix+"Set-Schema", "sales")
+			fmt.Fprint(w, `{"id":"q0","updateType":"USE","stats":{"state":"FINISHED"}}`)
+		case strings.Contains(query, "missing"):
+			fmt.Fprint(w, `{"id":"q1","stats":{"state":"FAILED"},"error":{"message":"Table 'hive.sales.missing' does not exist",`+
+				`"errorCode":46,"errorName":"TABLE_NOT_FOUND","errorType":"USER_ERROR","errorLocation":{"lineNumber":1,"columnNumber":15}}}`)
+		case strings.HasPrefix(query, "INSERT"):
+			require.Equal(t, "sales", r.Header.Get(prefix+"Schema"))
+			fmt.Fprintf(w, `{"id":"q2","nextUri":"%s/v1/statement/q2/1","stats":{"state":"QUEUED"}}`, server.URL)
+		default:
+			fmt.Fprintf(w, `{"id":"q3","nextUri":"%s/v1/statement/q3/1","stats":{"state":"QUEUED"}}`, server.URL)
+		}
+	})
+
+	mux.HandleFunc("/v1/statement/q2/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"q2","updateType":"INSERT","updateCount":3,"stats":{"state":"FINISHED"}}`)
+	})
+
+	mux.HandleFunc("/v1/statement/q3/1", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "alice", r.Header.Get(prefix+"User"))
+		fmt.Fprintf(w, `{"id":"q3","nextUri":"%s/v1/statement/q3/2",`+
+			`"columns":[{"name":"id","type":"bigint"},{"name":"city","type":"varchar"}],`+
+			`"data":[[1,"Ljubljana"]]}`, server.URL)
+	})
+	mux.HandleFunc("/v1/statement/q3/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"q3","columns":[{"name":"id","type":"bigint"},{"name":"city","type":"varchar"}],"data":[[2,null]]}`)
+	})
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestTrinoDetectFlavor(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(trinoFlavorPresto, trinoDetectFlavor("0.283"))
+	r.Equal(trinoFlavorPresto, trinoDetectFlavor("350"))
+	r.Equal(trinoFlavorTrino, trinoDetectFlavor("351"))
+	r.Equal(trinoFlavorTrino, trinoDetectFlavor("435-e.3"))
+	r.Equal(trinoFlavorTrino, trinoDetectFlavor("testversion"))
+}
+
+func TestTrino(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		version string
+		prefix  string
+	}{
+		{name: "trino", version: "435", prefix: "X-Trino-"},
+		{name: "prestosql", version: "348", prefix: "X-Presto-"},
+		{name: "presto", version: "0.283", prefix: "X-Presto-"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			var infoCalls atomic.Int32
+			server := newTrinoTestServer(t, tt.version, tt.prefix, &infoCalls)
+			host := strings.TrimPrefix(server.URL, "http://")
+
+			driver, err := (&Trino{}).Connect(fmt.Sprintf("trino://alice@%s/hive?query_max_run_time=10m", host))
+			r.NoError(err)
+			defer driver.Close()
+
+			// paged result
+			result, err := driver.Query(context.Background(), "SELECT id, city FROM trips")
+			r.NoError(err)
+			r.Equal(core.Header{"id", "city"}, result.Header())
+
+			rows, err := drainKsqlDBResult(t, result)
+			r.NoError(err)
+			r.Equal([]core.Row{
+				{json.Number("1"), "Ljubljana"},
+				{json.Number("2"), nil},
+			}, rows)
+
+			// session changes are sent with later requests
+			_, err = driver.Query(context.Background(), "USE sales")
+			r.NoError(err)
+
+			result, err = driver.Query(context.Background(), "INSERT INTO trips VALUES (3, 'Koper')")
+			r.NoError(err)
+			r.Equal(core.Header{"Rows Affected"}, result.Header())
+			rows, err = drainKsqlDBResult(t, result)
+			r.NoError(err)
+			r.Equal([]core.Row{{int64(3)}}, rows)
+
+			// query errors
+			_, err = driver.Query(context.Background(), "SELECT * FROM missing")
+			r.ErrorContains(err, "TABLE_NOT_FOUND: line 1:15: Table 'hive.sales.missing' does not exist")
+
+			// flavor is detected only once
+			r.Equal(int32(1), infoCalls.Load())
+		})
+	}
+}
+
+func TestTrino_FlavorOverride(t *testing.T) {
+	r := require.New(t)
+
+	var infoCalls atomic.Int32
+	server := newTrinoTestServer(t, "435", "X-Trino-", &infoCalls)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// overriding the flavor skips detection
+	driver, err := (&Trino{}).Connect(fmt.Sprintf("presto://alice@%s/hive?flavor=presto&query_max_run_time=10m", host))
+	r.NoError(err)
+	defer driver.Close()
+
+	_, err = driver.Query(context.Background(), "SELECT 1")
+	r.ErrorContains(err, "X-Trino-User must be set")
+	r.Zero(infoCalls.Load())
+
+	_, err = (&Trino{}).Connect("trino://alice@localhost?flavor=hive")
+	r.ErrorContains(err, "unexpected flavor")
+}
+
+func TestTrinoResponseError(t *testing.T) {
+	r := require.New(t)
+
+	// statement response
+	err := trinoResponseError("400 Bad Request", []byte(`{"error":{"message":"mismatched input","errorName":"SYNTAX_ERROR","errorLocation":{"lineNumber":1,"columnNumber":8}}}`))
+	r.EqualError(err, `server responded with "400 Bad Request": SYNTAX_ERROR: line 1:8: mismatched input`)
+
+	// bare error
+	err = trinoResponseError("401 Unauthorized", []byte(`{"message":"Access Denied"}`))
+	r.EqualError(err, `server responded with "401 Unauthorized": Access Denied`)
+
+	// plain text
+	err = trinoResponseError("503 Service Unavailable", []byte("no healthy upstream\n"))
+	r.EqualError(err, `server responded with "503 Service Unavailable": no healthy upstream`)
+}
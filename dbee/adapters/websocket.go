@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"fmt"
+	"net/http"
+	nurl "net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// Register client
+func init() {
+	_ = register(&WebSocket{}, "websocket", "ws")
+}
+
+var _ core.Adapter = (*WebSocket)(nil)
+
+// WebSocket streams messages of generic websocket feeds as rows.
+// The query is sent as the subscription message and every incoming json message
+// is a row, until the call is canceled or the server closes the connection.
+type WebSocket struct{}
+
+// Connect creates a [WebSocket] client.
+// The format of the url is as follows:
+//
+//	ws://[user:password@]host[:port]/path[?options]
+//
+// Where:
+//   - "wss" scheme can be used instead of "ws" to connect over tls.
+//   - user and password (if set) are sent with basic auth.
+//   - "options" is an ampersand-separated list of key=value arguments.
+//
+// The supported "options" are:
+//   - reconnect=n - number of attempts to reconnect a dropped stream (default: 5, 0 disables reconnecting).
+//
+// Other options are kept in the url.
+func (*WebSocket) Connect(rawURL string) (core.Driver, error) {
+	u, err := nurl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
+	}
+
+	switch u.Scheme {
+	case "ws", "websocket":
+		u.Scheme = "ws"
+	case "wss", "websockets":
+		u.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("unexpected scheme: %q", u.Scheme)
+	}
+
+	params := u.Query()
+
+	reconnects := 5
+	if r := params.Get("reconnect"); r != "" {
+		reconnects, err = strconv.Atoi(r)
+		if err != nil || reconnects < 0 {
+			return nil, fmt.Errorf("invalid reconnect option: %q", r)
+		}
+	}
+	params.Del("reconnect")
+	u.RawQuery = params.Encode()
+
+	header := make(http.Header)
+	if u.User != nil {
+		req := &http.Request{Header: header}
+		password, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), password)
+		u.User = nil
+	}
+
+	dialer := *websocket.DefaultDialer
+
+	return &webSocketDriver{
+		dialer:           &dialer,
+		url:              u.String(),
+		header:           header,
+		reconnects:       reconnects,
+		backoff:          time.Second,
+		handshakeTimeout: 2 * time.Second,
+	}, nil
+}
+
+func (*WebSocket) GetHelpers(opts *core.TableOptions) map[string]string {
+	return map[string]string{
+		"Subscribe": fmt.Sprintf(`{"type": "subscribe", "channel": %q}`, opts.Table),
+	}
+}
@@ -0,0 +1,375 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+var _ core.Driver = (*webSocketDriver)(nil)
+
+type webSocketDriver struct {
+	dialer *websocket.Dialer
+	url    string
+	header http.Header
+	// number of attempts to reconnect a dropped stream
+	reconnects int
+	// wait before the n-th attempt is n times the backoff
+	backoff time.Duration
+	// how long to wait for the handshake listing channels
+	handshakeTimeout time.Duration
+}
+
+// webSocketChannelKeys are keys of handshake messages which list the available channels,
+// e.g. {"channels": ["trades", {"name": "quotes"}]}.
+var webSocketChannelKeys = []string{"channels", "topics", "streams"}
+
+func (c *webSocketDriver) dial(ctx context.Context) (*websocket.Conn, error) {
+	conn, resp, err := c.dialer.DialContext(ctx, c.url, c.header)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			return nil, fmt.Errorf("server responded with %q: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+		return nil, fmt.Errorf("c.dialer.DialContext: %w", err)
+	}
+	return conn, nil
+}
+
+// webSocketStream reads messages of a subscription. Dropped connections are
+// reconnected and subscribed again.
+type webSocketStream struct {
+	driver *webSocketDriver
+	ctx    context.Context
+	query  string
+
+	conn *websocket.Conn
+	stop func() bool
+	// the first message of a new connection might be a handshake
+	fresh bool
+	// failed reconnect attempts since the last message
+	attempts int
+}
+
+func (s *webSocketStream) connect() error {
+	conn, err := s.driver.dial(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.query != "" {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(s.query)); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("conn.WriteMessage: %w", err)
+		}
+	}
+
+	// blocked reads are interrupted once the call is canceled
+	s.stop = context.AfterFunc(s.ctx, func() {
+		_ = conn.Close()
+	})
+	s.conn = conn
+	s.fresh = true
+
+	return nil
+}
+
+func (s *webSocketStream) close() {
+	if s.conn == nil {
+		return
+	}
+	s.stop()
+	_ = s.conn.Close()
+	s.conn = nil
+}
+
+// read returns the next data message. io.EOF is returned if the server closed
+// the stream or the call was canceled.
+func (s *webSocketStream) read() ([]byte, error) {
+	for {
+		if s.conn == nil {
+			return nil, io.EOF
+		}
+
+		_, msg, err := s.conn.ReadMessage()
+		if err == nil {
+			s.attempts = 0
+			fresh := s.fresh
+			s.fresh = false
+			if fresh && webSocketChannels(msg) != nil {
+				continue
+			}
+			return msg, nil
+		}
+
+		s.close()
+		if s.ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return nil, io.EOF
+		}
+		if err := s.reconnect(err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *webSocketStream) reconnect(cause error) error {
+	for s.attempts < s.driver.reconnects {
+		s.attempts++
+
+		select {
+		case <-s.ctx.Done():
+			return io.EOF
+		case <-time.After(s.driver.backoff * time.Duration(s.attempts)):
+		}
+
+		err := s.connect()
+		if err == nil {
+			return nil
+		}
+		cause = err
+	}
+
+	return fmt.Errorf("connection dropped: %w", cause)
+}
+
+// Query sends the query as the subscription message and streams incoming messages
+// as rows until the call is canceled or the server closes the connection.
+// The columns are the keys of the first message (keys that appear later are dropped),
+// array messages are split to numbered columns and other messages are single values.
+func (c *webSocketDriver) Query(ctx context.Context, query string) (core.ResultStream, error) {
+	s := &webSocketStream{
+		driver: c,
+		ctx:    ctx,
+		query:  strings.TrimSpace(query),
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	// the header is known once the first message arrives
+	first, err := s.read()
+	if errors.Is(err, io.EOF) {
+		s.close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return builders.NewResultStreamBuilder().
+			WithNextFunc(builders.NextNil()).
+			WithHeader(core.Header{"No Results"}).
+			Build(), nil
+	}
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+
+	header := webSocketHeader(first)
+	pending := first
+	var pendingErr error
+
+	hasNext := func() bool {
+		if pending != nil || pendingErr != nil {
+			return true
+		}
+
+		msg, err := s.read()
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+		if err != nil {
+			pendingErr = err
+			return true
+		}
+		pending = msg
+		return true
+	}
+
+	next := func() (core.Row, error) {
+		if !hasNext() {
+			return nil, errors.New("no next row")
+		}
+		if pendingErr != nil {
+			err := pendingErr
+			pendingErr = nil
+			return nil, err
+		}
+
+		msg := pending
+		pending = nil
+		return webSocketRow(header, msg), nil
+	}
+
+	return builders.NewResultStreamBuilder().
+		WithNextFunc(next, hasNext).
+		WithHeader(header).
+		WithCloseFunc(s.close).
+		Build(), nil
+}
+
+// webSocketHeader derives columns from the message.
+func webSocketHeader(msg []byte) core.Header {
+	if keys, _, ok := webSocketObject(msg); ok && len(keys) > 0 {
+		return keys
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(msg, &values); err == nil && len(values) > 0 {
+		header := make(core.Header, len(values))
+		for i := range values {
+			header[i] = strconv.Itoa(i + 1)
+		}
+		return header
+	}
+
+	return core.Header{"message"}
+}
+
+// webSocketRow converts the message to a row of the header.
+func webSocketRow(header core.Header, msg []byte) core.Row {
+	row := make(core.Row, len(header))
+
+	if _, fields, ok := webSocketObject(msg); ok {
+		for i, key := range header {
+			if raw, ok := fields[key]; ok {
+				row[i] = rawJSONValue(raw)
+			}
+		}
+		return row
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(msg, &values); err == nil {
+		for i := 0; i < len(values) && i < len(row); i++ {
+			row[i] = rawJSONValue(values[i])
+		}
+		return row
+	}
+
+	if json.Valid(msg) {
+		row[0] = rawJSONValue(msg)
+	} else {
+		row[0] = string(msg)
+	}
+	return row
+}
+
+// webSocketObject decodes a json object, keeping the order of its keys.
+func webSocketObject(msg []byte) (keys []string, fields map[string]json.RawMessage, ok bool) {
+	dec := json.NewDecoder(bytes.NewReader(msg))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, false
+	}
+
+	fields = make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, nil, false
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, false
+		}
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+		fields[key] = raw
+	}
+
+	return keys, fields, true
+}
+
+// webSocketChannels returns channels listed by a handshake message
+// (see webSocketChannelKeys) or nil if the message is not a handshake.
+func webSocketChannels(msg []byte) []string {
+	keys, fields, ok := webSocketObject(msg)
+	if !ok || len(keys) != 1 {
+		return nil
+	}
+
+	raw, ok := fields[keys[0]]
+	if !ok || !slices.Contains(webSocketChannelKeys, keys[0]) {
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil
+	}
+
+	channels := []string{}
+	for _, item := range items {
+		var name string
+		if err := json.Unmarshal(item, &name); err == nil {
+			channels = append(channels, name)
+			continue
+		}
+
+		var obj struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(item, &obj); err != nil || obj.Name == "" {
+			return nil
+		}
+		channels = append(channels, obj.Name)
+	}
+
+	return channels
+}
+
+func (c *webSocketDriver) Columns(opts *core.TableOptions) ([]*core.Column, error) {
+	// columns are only known once messages arrive
+	return []*core.Column{}, nil
+}
+
+// Structure lists channels if the server sends a handshake listing them after connecting.
+// Channels are displayed as views.
+func (c *webSocketDriver) Structure() ([]*core.Structure, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.handshakeTimeout)
+	defer cancel()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// not every server sends a handshake
+	_ = conn.SetReadDeadline(time.Now().Add(c.handshakeTimeout))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		return []*core.Structure{}, nil
+	}
+
+	structure := []*core.Structure{}
+	for _, channel := range webSocketChannels(msg) {
+		structure = append(structure, &core.Structure{
+			Name:   channel,
+			Schema: "",
+			Type:   core.StructureTypeView,
+		})
+	}
+
+	return structure, nil
+}
+
+func (c *webSocketDriver) Close() {}
@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// newWebSocketTestServer sends a handshake listing channels on every connection and
+// streams messages of the subscribed channel. The first "trades" stream is dropped
+// without a close frame.
+func newWebSocketTestServer(t *testing.T, connections *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	var trades atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed" || r.URL.Query().Get("token") != "abc" {
+			http.Error(w, "unknown feed", http.StatusNotFound)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		connections.Add(1)
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"channels":["trades",{"name":"quotes"}]}`))
+
+		_, sub, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		send := func(msg string) {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+
+		switch string(sub) {
+		case `{"subscribe":"trades"}`:
+			if trades.Add(1) == 1 {
+				send(`{"id":1,"price":1.5,"side":"buy"}`)
+				send(`{"id":2,"side":"sell","extra":{"a":1}}`)
+				// drop the connection
+				_ = conn.UnderlyingConn().Close()
+				return
+			}
+			send(`[3, 2.5]`)
+			send(`not json`)
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		case `{"subscribe":"quotes"}`:
+			send(`{"bid":1}`)
+			// wait for the client to go away
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestWebSocket(t *testing.T) {
+	r := require.New(t)
+
+	var connections atomic.Int32
+	server := newWebSocketTestServer(t, &connections)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/feed?token=abc&reconnect=2"
+
+	driver, err := (&WebSocket{}).Connect(url)
+	r.NoError(err)
+	defer driver.Close()
+	driver.(*webSocketDriver).backoff = time.Millisecond
+
+	// handshake
+	structure, err := driver.Structure()
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{Name: "trades", Type: core.StructureTypeView},
+		{Name: "quotes", Type: core.StructureTypeView},
+	}, structure)
+
+	// stream is reconnected after it's dropped
+	result, err := driver.Query(context.Background(), `{"subscribe":"trades"}`)
+	r.NoError(err)
+	r.Equal(core.Header{"id", "price", "side"}, result.Header())
+
+	rows, err := drainKsqlDBResult(t, result)
+	r.NoError(err)
+	r.Equal([]core.Row{
+		{json.Number("1"), json.Number("1.5"), "buy"},
+		{json.Number("2"), nil, "sell"},
+		{json.Number("3"), json.Number("2.5"), nil},
+		{"not json", nil, nil},
+	}, rows)
+	r.Equal(int32(3), connections.Load())
+
+	// canceling ends the stream
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err = driver.Query(ctx, `{"subscribe":"quotes"}`)
+	r.NoError(err)
+	r.Equal(core.Header{"bid"}, result.Header())
+
+	r.True(result.HasNext())
+	row, err := result.Next()
+	r.NoError(err)
+	r.Equal(core.Row{json.Number("1")}, row)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	r.False(result.HasNext())
+	result.Close()
+
+	// errors of the upgrade
+	driver, err = (&WebSocket{}).Connect("ws" + strings.TrimPrefix(server.URL, "http") + "/missing")
+	r.NoError(err)
+	_, err = driver.Query(context.Background(), "x")
+	r.ErrorContains(err, `server responded with "404 Not Found": unknown feed`)
+
+	_, err = (&WebSocket{}).Connect("http://localhost/feed")
+	r.ErrorContains(err, "unexpected scheme")
+}
@@ -0,0 +1,314 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// affectedModifiers are keywords between the command and the target table
+// (e.g. "DELETE LOW_PRIORITY FROM t", "UPDATE ONLY t").
+var affectedModifiers = map[string]bool{"LOW_PRIORITY": true, "QUICK": true, "IGNORE": true}
+
+// affectedCountQuery rewrites a DELETE or UPDATE statement to a query counting the rows
+// the statement would change. The WHERE clause is kept as is, while joined tables are
+// handled according to the syntax:
+//
+//   - Postgres "DELETE FROM t USING u WHERE c" and "UPDATE t SET ... FROM u WHERE c" count
+//     rows of t, for which a row of u exists ("SELECT COUNT(*) FROM t WHERE EXISTS (...)").
+//   - MySQL multi-table statements ("DELETE t FROM t JOIN u ON ...", "UPDATE t JOIN u ON ... SET")
+//     and SQL Server "UPDATE t SET ... FROM t JOIN u" count the rows of the join.
+//
+// Row limits (MySQL "LIMIT n", SQL Server "TOP (n)") are applied to the count.
+// RETURNING and OUTPUT clauses are dropped. Leading CTE is kept.
+func affectedCountQuery(query string) (string, error) {
+	tokens, end, ok := topLevelTokens(query)
+	if !ok {
+		return "", errors.New("unable to parse the statement")
+	}
+
+	// trailing semicolon is allowed, others mean multiple statements
+	for i, tok := range tokens {
+		if tok.value != ";" {
+			continue
+		}
+		if i != len(tokens)-1 {
+			return "", errors.New("only a single statement can be previewed")
+		}
+		tokens = tokens[:i]
+		end = tok.start
+	}
+	if len(tokens) < 1 {
+		return "", errors.New("empty statement")
+	}
+
+	p := &affectedParser{query: query, tokens: tokens, end: end}
+
+	// skip the CTE
+	cmd := 0
+	if tokens[0].value == "WITH" {
+		cmd = p.find(0, "DELETE", "UPDATE", "SELECT", "INSERT", "MERGE")
+		if cmd < 0 {
+			return "", errors.New("only DELETE and UPDATE statements can be previewed")
+		}
+	}
+	p.prefix = query[:tokens[cmd].start]
+
+	if w := p.find(cmd, "WHERE"); w >= 0 && w+2 < len(tokens) && tokens[w+1].value == "CURRENT" && tokens[w+2].value == "OF" {
+		return "", errors.New("statements with WHERE CURRENT OF can't be previewed")
+	}
+
+	switch tokens[cmd].value {
+	case "DELETE":
+		return p.delete(cmd + 1)
+	case "UPDATE":
+		return p.update(cmd + 1)
+	}
+
+	return "", errors.New("only DELETE and UPDATE statements can be previewed")
+}
+
+// affectedParser holds top-level tokens of the statement.
+type affectedParser struct {
+	query  string
+	tokens []sqlToken
+	// end of the statement
+	end int
+	// text before the command (CTE)
+	prefix string
+	// row limit of the statement (e.g. "LIMIT 10" or "TOP (10)")
+	limit string
+	top   bool
+}
+
+// find returns the index of the first token with one of the values, starting at from (or -1).
+func (p *affectedParser) find(from int, values ...string) int {
+	for i := from; i < len(p.tokens); i++ {
+		for _, v := range values {
+			if p.tokens[i].value == v {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// pos returns the start of the i-th token or the end of the statement.
+func (p *affectedParser) pos(i int) int {
+	if i < 0 || i >= len(p.tokens) {
+		return p.end
+	}
+	return p.tokens[i].start
+}
+
+// text returns the statement between the tokens, without trailing comments
+// (a line comment would otherwise comment out the rest of the count query).
+func (p *affectedParser) text(from, to int) string {
+	fragment := p.query[p.pos(from):p.pos(to)]
+	if _, codeEnd, ok := topLevelTokens(fragment); ok {
+		fragment = fragment[:codeEnd]
+	}
+	return strings.TrimSpace(fragment)
+}
+
+// skipModifiers skips the modifiers and the row limit ("TOP (n)") of the command.
+func (p *affectedParser) skipModifiers(i int) int {
+	for i < len(p.tokens) {
+		switch {
+		case affectedModifiers[p.tokens[i].value]:
+			i++
+		case p.tokens[i].value == "TOP":
+			p.limit = p.text(i, i+1)
+			p.top = true
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// condition returns the WHERE clause (without the keyword) and reads the row limit.
+func (p *affectedParser) condition(from int) string {
+	where := p.find(from, "WHERE")
+
+	if limit := p.find(from, "LIMIT"); limit >= 0 {
+		p.limit = p.text(limit, -1)
+	}
+
+	if where < 0 {
+		return ""
+	}
+	return p.text(where+1, p.find(where+1, "RETURNING", "ORDER", "LIMIT", "OUTPUT"))
+}
+
+func (p *affectedParser) delete(i int) (string, error) {
+	i = p.skipModifiers(i)
+
+	// "DELETE t1, t2 FROM t1 JOIN t2 ..." (MySQL) or "DELETE t FROM t JOIN u ..." (SQL Server)
+	if i < len(p.tokens) && p.tokens[i].value != "FROM" {
+		from := p.find(i, "FROM")
+		if from < 0 {
+			// "DELETE t WHERE c" (SQL Server)
+			target := p.text(i, p.find(i, "WHERE", "OUTPUT"))
+			return p.count(target, "", p.condition(i)), nil
+		}
+		refs := p.text(from+1, p.find(from+1, "WHERE", "ORDER", "LIMIT", "OUTPUT"))
+		return p.count(refs, "", p.condition(from)), nil
+	}
+
+	i++
+	clause := p.find(i, "USING", "FROM", "WHERE", "RETURNING", "ORDER", "LIMIT", "OUTPUT")
+	target := p.text(i, clause)
+	if target == "" {
+		return "", errors.New("missing table of the DELETE statement")
+	}
+
+	// skip the output clause (SQL Server)
+	if clause >= 0 && p.tokens[clause].value == "OUTPUT" {
+		clause = p.find(clause, "FROM", "WHERE")
+	}
+
+	if clause >= 0 && (p.tokens[clause].value == "USING" || p.tokens[clause].value == "FROM") {
+		refsEnd := p.find(clause+1, "WHERE", "RETURNING", "ORDER", "LIMIT", "OUTPUT")
+		return p.joined(i, clause, clause+1, refsEnd, p.condition(clause)), nil
+	}
+
+	return p.count(target, "", p.condition(i)), nil
+}
+
+func (p *affectedParser) update(i int) (string, error) {
+	i = p.skipModifiers(i)
+
+	set := p.find(i, "SET")
+	if set < 0 {
+		return "", errors.New("missing SET clause of the UPDATE statement")
+	}
+	target := p.text(i, set)
+	if target == "" {
+		return "", errors.New("missing table of the UPDATE statement")
+	}
+
+	from := p.find(set, "FROM")
+	if from >= 0 {
+		refsEnd := p.find(from+1, "WHERE", "RETURNING", "ORDER", "LIMIT", "OUTPUT")
+		return p.joined(i, set, from+1, refsEnd, p.condition(from)), nil
+	}
+
+	// multi-table update (MySQL) counts rows of the join
+	return p.count(target, "", p.condition(set)), nil
+}
+
+// joined builds the count query of a statement with a target and joined tables (refs).
+// If the refs mention the target (MySQL "DELETE FROM t USING t JOIN u", SQL Server
+// "UPDATE t SET ... FROM t JOIN u"), the rows of the join are counted. Otherwise the
+// target rows which have a matching row in the refs (Postgres) are counted.
+func (p *affectedParser) joined(targetStart, targetEnd, refsStart, refsEnd int, cond string) string {
+	target := p.text(targetStart, targetEnd)
+	refs := p.text(refsStart, refsEnd)
+
+	names := make(map[string]bool)
+	for i := targetStart; i < targetEnd && i < len(p.tokens); i++ {
+		if v := p.tokens[i].value; v != "AS" && v != "ONLY" {
+			names[v] = true
+		}
+	}
+	for i := refsStart; i < len(p.tokens) && (refsEnd < 0 || i < refsEnd); i++ {
+		if names[p.tokens[i].value] {
+			return p.count(refs, "", cond)
+		}
+	}
+
+	exists := fmt.Sprintf("EXISTS (SELECT 1 FROM %s", refs)
+	if cond != "" {
+		exists += " WHERE " + cond
+	}
+	return p.count(target, exists+")", "")
+}
+
+// count builds the count query of rows matching the conditions.
+func (p *affectedParser) count(from, exists, cond string) string {
+	where := exists
+	if cond != "" {
+		where = cond
+	}
+
+	rows := "FROM " + from
+	if where != "" {
+		rows += " WHERE " + where
+	}
+
+	switch {
+	case p.limit == "":
+		return p.prefix + "SELECT COUNT(*) " + rows
+	case p.top:
+		return p.prefix + fmt.Sprintf("SELECT COUNT(*) FROM (SELECT %s 1 AS n %s) AS affected", p.limit, rows)
+	default:
+		return p.prefix + fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 AS n %s %s) AS affected", rows, p.limit)
+	}
+}
+
+// PreviewAffected returns the number of rows the DELETE or UPDATE statement would change,
+// without executing it. The statement is rewritten to a count query (see affectedCountQuery
+// for supported syntax).
+func (c *Connection) PreviewAffected(ctx context.Context, query string) (int64, error) {
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	countQuery, err := affectedCountQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := c.driver.Query(ctx, countQuery)
+	if err != nil {
+		return 0, fmt.Errorf("c.driver.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.HasNext() {
+		return 0, errors.New("count query returned no rows")
+	}
+	row, err := rows.Next()
+	if err != nil {
+		return 0, fmt.Errorf("rows.Next: %w", err)
+	}
+	if len(row) < 1 {
+		return 0, errors.New("count query returned no columns")
+	}
+
+	return parseInt64(row[0])
+}
+
+// parseInt64 converts a numeric value returned by a driver (e.g. result of a count query) to int64.
+func parseInt64(val any) (int64, error) {
+	switch v := val.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+
+	n, err := strconv.ParseInt(fmt.Sprint(val), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected number: %v", val)
+	}
+	return n, nil
+}
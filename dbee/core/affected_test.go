@@ -0,0 +1,137 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInt64Query(t *testing.T) {
+	type testCase struct {
+		name     string
+		query    string
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "delete",
+			query:    "DELETE FROM orders WHERE status = 'cancelled';",
+			expected: "SELECT COUNT(*) FROM orders WHERE status = 'cancelled'",
+		},
+		{
+			name:     "delete without where",
+			query:    "delete from orders",
+			expected: "SELECT COUNT(*) FROM orders",
+		},
+		{
+			name:     "delete with alias and returning",
+			query:    "DELETE FROM ONLY orders o WHERE o.id IN (SELECT id FROM old) RETURNING o.id",
+			expected: "SELECT COUNT(*) FROM ONLY orders o WHERE o.id IN (SELECT id FROM old)",
+		},
+		{
+			name:     "delete with cte",
+			query:    "WITH old AS (SELECT id FROM archive) DELETE FROM orders WHERE id IN (SELECT id FROM old)",
+			expected: "WITH old AS (SELECT id FROM archive) SELECT COUNT(*) FROM orders WHERE id IN (SELECT id FROM old)",
+		},
+		{
+			name:     "keywords in strings and comments",
+			query:    "DELETE FROM orders -- WHERE nothing\nWHERE note = 'SET ; WHERE' /* LIMIT 1 */",
+			expected: "SELECT COUNT(*) FROM orders WHERE note = 'SET ; WHERE'",
+		},
+		{
+			name:     "postgres delete using",
+			query:    "DELETE FROM orders o USING customers c WHERE o.customer_id = c.id AND c.banned",
+			expected: "SELECT COUNT(*) FROM orders o WHERE EXISTS (SELECT 1 FROM customers c WHERE o.customer_id = c.id AND c.banned)",
+		},
+		{
+			name:     "mysql delete using",
+			query:    "DELETE FROM o USING orders o JOIN customers c ON o.customer_id = c.id WHERE c.banned",
+			expected: "SELECT COUNT(*) FROM orders o JOIN customers c ON o.customer_id = c.id WHERE c.banned",
+		},
+		{
+			name:     "mysql multi-table delete",
+			query:    "DELETE o, i FROM orders o JOIN items i ON i.order_id = o.id WHERE o.status = 'x'",
+			expected: "SELECT COUNT(*) FROM orders o JOIN items i ON i.order_id = o.id WHERE o.status = 'x'",
+		},
+		{
+			name:     "mysql delete with limit",
+			query:    "DELETE LOW_PRIORITY FROM orders WHERE status = 'x' ORDER BY id LIMIT 10",
+			expected: "SELECT COUNT(*) FROM (SELECT 1 AS n FROM orders WHERE status = 'x' LIMIT 10) AS affected",
+		},
+		{
+			name:     "sql server delete with top and output",
+			query:    "DELETE TOP (10) FROM orders OUTPUT deleted.id WHERE status = 'x'",
+			expected: "SELECT COUNT(*) FROM (SELECT TOP (10) 1 AS n FROM orders WHERE status = 'x') AS affected",
+		},
+		{
+			name:     "sql server delete without from",
+			query:    "DELETE orders WHERE status = 'x'",
+			expected: "SELECT COUNT(*) FROM orders WHERE status = 'x'",
+		},
+		{
+			name:     "update",
+			query:    "UPDATE orders SET status = 'x', note = (SELECT 'a' FROM dual WHERE 1 = 1) WHERE id > 10 RETURNING *",
+			expected: "SELECT COUNT(*) FROM orders WHERE id > 10",
+		},
+		{
+			name:     "postgres update from",
+			query:    "UPDATE orders o SET status = c.status FROM customers c WHERE o.customer_id = c.id",
+			expected: "SELECT COUNT(*) FROM orders o WHERE EXISTS (SELECT 1 FROM customers c WHERE o.customer_id = c.id)",
+		},
+		{
+			name:     "sql server update from",
+			query:    "UPDATE o SET status = c.status FROM orders o JOIN customers c ON o.customer_id = c.id WHERE c.banned = 1",
+			expected: "SELECT COUNT(*) FROM orders o JOIN customers c ON o.customer_id = c.id WHERE c.banned = 1",
+		},
+		{
+			name:     "mysql multi-table update",
+			query:    "UPDATE orders o JOIN customers c ON o.customer_id = c.id SET o.status = 'x' WHERE c.banned",
+			expected: "SELECT COUNT(*) FROM orders o JOIN customers c ON o.customer_id = c.id WHERE c.banned",
+		},
+		{
+			name:     "mysql update with limit",
+			query:    "UPDATE IGNORE orders SET status = 'x' WHERE status = 'y' LIMIT 5",
+			expected: "SELECT COUNT(*) FROM (SELECT 1 AS n FROM orders WHERE status = 'y' LIMIT 5) AS affected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := affectedCountQuery(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, query)
+		})
+	}
+}
+
+func TestParseInt64Query_Errors(t *testing.T) {
+	r := require.New(t)
+
+	for _, q := range []string{
+		"",
+		"SELECT * FROM orders",
+		"INSERT INTO orders VALUES (1)",
+		"WITH x AS (SELECT 1) SELECT * FROM x",
+		"DELETE FROM a; DELETE FROM b",
+		"DELETE FROM 'unterminated",
+		"UPDATE orders WHERE id = 1",
+		"DELETE FROM orders WHERE CURRENT OF cur",
+	} {
+		_, err := affectedCountQuery(q)
+		r.Error(err, q)
+	}
+}
+
+func TestParseInt64(t *testing.T) {
+	r := require.New(t)
+
+	for _, v := range []any{int64(7), 7, int32(7), uint64(7), float64(7), []byte("7"), "7"} {
+		n, err := parseInt64(v)
+		r.NoError(err)
+		r.Equal(int64(7), n)
+	}
+
+	_, err := parseInt64("seven")
+	r.Error(err)
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BoolFormat determines how boolean values are rendered in the output.
+type BoolFormat struct {
+	trueValue  string
+	falseValue string
+}
+
+// ParseBoolFormat creates a bool format from a pair of values separated by a slash
+// (e.g. "true/false", "t/f", "1/0" or "yes/no").
+// If the format is empty, nil is returned, which means boolean values are not touched.
+func ParseBoolFormat(format string) (*BoolFormat, error) {
+	if format == "" {
+		return nil, nil
+	}
+
+	trueValue, falseValue, ok := strings.Cut(format, "/")
+	if !ok || trueValue == "" || falseValue == "" || strings.Contains(falseValue, "/") {
+		return nil, fmt.Errorf("expected \"<true>/<false>\", got: %q", format)
+	}
+
+	return &BoolFormat{
+		trueValue:  trueValue,
+		falseValue: falseValue,
+	}, nil
+}
+
+// Apply formats the value if it's a boolean. Other values are returned unchanged.
+func (bf *BoolFormat) Apply(val any) any {
+	b, ok := val.(bool)
+	if !ok || bf == nil {
+		return val
+	}
+
+	if b {
+		return bf.trueValue
+	}
+	return bf.falseValue
+}
+
+var _ Formatter = (*boolFormatter)(nil)
+
+// boolFormatter applies the bool format to rows before passing them to the wrapped formatter.
+type boolFormatter struct {
+	formatter Formatter
+	format    *BoolFormat
+}
+
+// NewBoolFormatter wraps the formatter, so that boolean values are rendered using the bool format.
+// If the bool format is nil, the original formatter is returned.
+func NewBoolFormatter(formatter Formatter, format *BoolFormat) Formatter {
+	if format == nil {
+		return formatter
+	}
+
+	return &boolFormatter{
+		formatter: formatter,
+		format:    format,
+	}
+}
+
+func (bf *boolFormatter) Format(header Header, rows []Row, opts *FormatterOptions) ([]byte, error) {
+	// copy rows, as they are shared with the cached result
+	formatted := make([]Row, len(rows))
+	for i, row := range rows {
+		formatted[i] = make(Row, len(row))
+		for j, val := range row {
+			formatted[i][j] = bf.format.Apply(val)
+		}
+	}
+
+	return bf.formatter.Format(header, formatted, opts)
+}
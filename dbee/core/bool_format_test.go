@@ -0,0 +1,96 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestBoolFormat_Apply(t *testing.T) {
+	tests := []struct {
+		format    string
+		wantTrue  any
+		wantFalse any
+	}{
+		{format: "true/false", wantTrue: "true", wantFalse: "false"},
+		{format: "t/f", wantTrue: "t", wantFalse: "f"},
+		{format: "1/0", wantTrue: "1", wantFalse: "0"},
+		{format: "yes/no", wantTrue: "yes", wantFalse: "no"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := require.New(t)
+
+			bf, err := core.ParseBoolFormat(tt.format)
+			r.NoError(err)
+			r.Equal(tt.wantTrue, bf.Apply(true))
+			r.Equal(tt.wantFalse, bf.Apply(false))
+
+			// other values are not touched
+			r.Equal(1, bf.Apply(1))
+			r.Equal("true", bf.Apply("true"))
+			r.Nil(bf.Apply(nil))
+		})
+	}
+}
+
+func TestParseBoolFormat(t *testing.T) {
+	r := require.New(t)
+
+	bf, err := core.ParseBoolFormat("")
+	r.NoError(err)
+	r.Nil(bf)
+	r.Equal(true, bf.Apply(true))
+
+	for _, invalid := range []string{"yes", "yes/", "/no", "a/b/c"} {
+		_, err = core.ParseBoolFormat(invalid)
+		r.Error(err, invalid)
+	}
+}
+
+func TestNewBoolFormatter(t *testing.T) {
+	header := core.Header{"id", "active"}
+	rows := []core.Row{{1, true}, {2, false}, {3, nil}}
+	opts := &core.FormatterOptions{SchemaType: core.SchemaFul}
+
+	bf, err := core.ParseBoolFormat("yes/no")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		formatter core.Formatter
+		want      string
+	}{
+		{
+			name:      "csv",
+			formatter: format.NewCSV(),
+			want:      "id,active\n1,yes\n2,no\n3,<nil>\n",
+		},
+		{
+			name:      "yaml",
+			formatter: format.NewYAML(),
+			want:      "- id: 1\n  active: \"yes\"\n- id: 2\n  active: \"no\"\n- id: 3\n  active: null\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			out, err := core.NewBoolFormatter(tt.formatter, bf).Format(header, rows, opts)
+			r.NoError(err)
+			r.Equal(tt.want, string(out))
+
+			// original rows are not modified
+			r.Equal(true, rows[0][1])
+		})
+	}
+
+	// nil format returns the original formatter
+	csv := format.NewCSV()
+	require.Equal(t, core.Formatter(csv), core.NewBoolFormatter(csv, nil))
+}
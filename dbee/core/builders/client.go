@@ -5,15 +5,38 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
+// defaultMaxIdleConns is the number of idle connections kept in the pool
+// (same as the default of database/sql).
+const defaultMaxIdleConns = 2
+
 // default sql client used by other specific implementations
 type Client struct {
 	db             *sql.DB
 	typeProcessors map[string]func(any) any
+	safeScan       bool
+	warnings       WarningsFunc
+
+	// idle timeout closes pooled connections if no query is run for the duration
+	idleMu      sync.Mutex
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+	isIdle      bool
+
+	// replicas run read-only statements (see AddReplica), guarded by idleMu
+	replicas    []*replica
+	nextReplica int
+
+	// statementLog receives executed statements (see SetStatementLog)
+	statementLog func(statement string, elapsed time.Duration, err error)
 }
 
 func NewClient(db *sql.DB, opts ...ClientOption) *Client {
@@ -27,18 +50,110 @@ func NewClient(db *sql.DB, opts ...ClientOption) *Client {
 	return &Client{
 		db:             db,
 		typeProcessors: config.typeProcessors,
+		warnings:       config.warnings,
 	}
 }
 
 func (c *Client) Close() {
+	c.idleMu.Lock()
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+	}
+	c.closeReplicas()
+	c.idleMu.Unlock()
+
 	c.db.Close()
 }
 
+// SetIdleTimeout sets the duration after which all idle connections of the pool
+// are closed if no query was run in the meantime. Connections are opened again
+// on the next query. Zero disables the timeout.
+func (c *Client) SetIdleTimeout(timeout time.Duration) {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+
+	c.idleTimeout = timeout
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+		c.idleTimer = nil
+	}
+	c.resetIdleTimer()
+}
+
+// touch marks the client as active: the pool is restored if it was emptied
+// and the idle timer is restarted.
+func (c *Client) touch() {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+
+	if c.isIdle {
+		c.db.SetMaxIdleConns(defaultMaxIdleConns)
+		for _, r := range c.replicas {
+			r.db.SetMaxIdleConns(defaultMaxIdleConns)
+		}
+		c.isIdle = false
+	}
+	c.resetIdleTimer()
+}
+
+// resetIdleTimer restarts the idle timer. Must be called with idleMu locked.
+func (c *Client) resetIdleTimer() {
+	if c.idleTimeout <= 0 {
+		return
+	}
+
+	if c.idleTimer != nil {
+		c.idleTimer.Reset(c.idleTimeout)
+		return
+	}
+
+	c.idleTimer = time.AfterFunc(c.idleTimeout, func() {
+		c.idleMu.Lock()
+		defer c.idleMu.Unlock()
+
+		// closes idle connections, connections in use are closed when released
+		c.db.SetMaxIdleConns(0)
+		for _, r := range c.replicas {
+			r.db.SetMaxIdleConns(0)
+		}
+		c.isIdle = true
+	})
+}
+
+// SetSafeScan toggles the "safe scan" fallback mode. In this mode, every value is
+// scanned as a string (NULLs are preserved) and type processors are skipped.
+// This sacrifices native typing, but even types unknown to dbee don't break the output.
+func (c *Client) SetSafeScan(enabled bool) {
+	c.safeScan = enabled
+}
+
+// SetStatementLog sets the function which receives every statement executed by the client,
+// the time it took to execute it (not including reading the rows) and its error.
+// Secrets in statements are masked with core.RedactStatement. Nil disables logging.
+func (c *Client) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	c.statementLog = fn
+}
+
+// logStatement logs the statement started at start (if logging is enabled).
+func (c *Client) logStatement(statement string, start time.Time, err error) {
+	if c.statementLog == nil {
+		return
+	}
+	c.statementLog(core.RedactStatement(statement), time.Since(start), err)
+}
+
 // Swap swaps current database connection for another one
-// and closes the old one.
+// and closes the old one. Replicas are closed as well, since they
+// are connected to the old database.
 func (c *Client) Swap(db *sql.DB) {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+
+	c.closeReplicas()
 	c.db.Close()
 	c.db = db
+	c.isIdle = false
+	c.resetIdleTimer()
 }
 
 // ColumnsFromQuery executes a given query on a new connection and
@@ -58,9 +173,53 @@ func (c *Client) ColumnsFromQuery(query string, args ...any) ([]*core.Column, er
 	return ColumnsFromResultStream(result)
 }
 
+// Peek returns the columns of the select statement without fetching any rows.
+// The statement is wrapped in a subquery with a false condition, so most databases
+// only plan it and return an empty result.
+func (c *Client) Peek(ctx context.Context, query string) ([]*core.Column, error) {
+	c.touch()
+
+	peek := fmt.Sprintf("SELECT * FROM (\n%s\n) peek WHERE 1 = 0", query)
+	start := time.Now()
+	rows, err := c.db.QueryContext(ctx, peek)
+	c.logStatement(peek, start, err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("rows.ColumnTypes: %w", err)
+	}
+
+	columns := make([]*core.Column, len(types))
+	for i, typ := range types {
+		column := &core.Column{
+			Name: typ.Name(),
+			Type: typ.DatabaseTypeName(),
+		}
+		// unbounded types (e.g. text) report the maximum int
+		if length, ok := typ.Length(); ok && length != math.MaxInt64 {
+			column.MaxLength = length
+		}
+		if precision, scale, ok := typ.DecimalSize(); ok {
+			column.Precision = precision
+			column.Scale = scale
+		}
+		columns[i] = column
+	}
+
+	return columns, nil
+}
+
 // Exec executes a query and returns a stream with single row (number of affected results).
 func (c *Client) Exec(ctx context.Context, query string) (*ResultStream, error) {
+	c.touch()
+
+	start := time.Now()
 	res, err := c.db.ExecContext(ctx, query)
+	c.logStatement(query, start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -74,35 +233,72 @@ func (c *Client) Exec(ctx context.Context, query string) (*ResultStream, error)
 		WithNextFunc(NextSingle(affected)).
 		WithHeader(core.Header{"Rows Affected"}).
 		Build()
+	rows.meta.Endpoint = c.endpoint()
 
 	return rows, nil
 }
 
 // Query executes a query on a connection and returns a result stream.
+// Read-only queries run on a replica, if the client has any (see AddReplica).
 func (c *Client) Query(ctx context.Context, query string) (*ResultStream, error) {
-	rows, err := c.db.QueryContext(ctx, query)
+	rows, endpoint, err := c.queryRouted(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.parseRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	result.meta.Endpoint = endpoint
+
+	return result, nil
+}
+
+// QueryArgs executes a query with placeholder arguments and returns a result stream.
+// If the query doesn't return any columns (e.g. a procedure call), an empty result is returned.
+func (c *Client) QueryArgs(ctx context.Context, query string, args ...any) (*ResultStream, error) {
+	rows, endpoint, err := c.queryRouted(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.parseRows(rows)
 	if err != nil {
+		_ = rows.Close()
 		return nil, err
 	}
 
-	return c.parseRows(rows)
+	if len(result.Header()) > 0 {
+		result.meta.Endpoint = endpoint
+		return result, nil
+	}
+	result.Close()
+
+	return NewResultStreamBuilder().
+		WithNextFunc(NextNil()).
+		WithHeader(core.Header{"No Results"}).
+		Build(), nil
 }
 
 // QueryUntilNotEmpty executes given queries on a single connection and returns when one of them
 // has a nonempty result.
 // Useful for specifying "fallback" queries like "ROWCOUNT()" when there are no results in query.
+// The connection is chosen by the first query (see AddReplica).
 func (c *Client) QueryUntilNotEmpty(ctx context.Context, queries ...string) (*ResultStream, error) {
 	if len(queries) < 1 {
 		return nil, errors.New("no queries provided")
 	}
 
-	conn, err := c.db.Conn(ctx)
+	conn, endpoint, err := c.connRouted(ctx, queries[0])
 	if err != nil {
-		return nil, fmt.Errorf("c.db.Conn: %w", err)
+		return nil, fmt.Errorf("c.connRouted: %w", err)
 	}
 
 	for _, query := range queries {
+		start := time.Now()
 		rows, err := conn.QueryContext(ctx, query)
+		c.logStatement(query, start, err)
 		if err != nil {
 			_ = conn.Close()
 			return nil, fmt.Errorf("conn.QueryContext: %w", err)
@@ -116,7 +312,11 @@ func (c *Client) QueryUntilNotEmpty(ctx context.Context, queries ...string) (*Re
 
 		// has result
 		if len(result.Header()) > 0 {
-			result.AddCallback(func() { _ = conn.Close() })
+			result.AddCallback(func() {
+				c.readWarnings(ctx, conn, result.meta)
+				_ = conn.Close()
+			})
+			result.meta.Endpoint = endpoint
 			return result, nil
 		}
 
@@ -132,12 +332,135 @@ func (c *Client) QueryUntilNotEmpty(ctx context.Context, queries ...string) (*Re
 		Build(), nil
 }
 
-func (c *Client) getTypeProcessor(typ string) func(any) any {
-	proc, ok := c.typeProcessors[strings.ToLower(typ)]
+// readWarnings adds warnings of the last statement run on the connection to meta
+// (see WithWarnings). Warnings are informational, so errors reading them are ignored.
+func (c *Client) readWarnings(ctx context.Context, conn *sql.Conn, meta *core.Meta) {
+	if c.warnings == nil {
+		return
+	}
+
+	warnings, err := c.warnings(func(statement string) (*sql.Rows, error) {
+		start := time.Now()
+		rows, err := conn.QueryContext(ctx, statement)
+		c.logStatement(statement, start, err)
+		return rows, err
+	})
+	if err != nil {
+		return
+	}
+	meta.Warnings = append(meta.Warnings, warnings...)
+}
+
+// QueryCursor executes a select statement with a server-side cursor (DECLARE ... FETCH)
+// in a transaction of its own. Rows are fetched in batches of fetchSize, so only a single
+// batch of a large result is transferred and held by the client at once.
+func (c *Client) QueryCursor(ctx context.Context, query string, fetchSize int) (*ResultStream, error) {
+	if fetchSize <= 0 {
+		return nil, fmt.Errorf("invalid fetch size: %d", fetchSize)
+	}
+
+	tx, endpoint, err := c.beginRouted(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("c.beginRouted: %w", err)
+	}
+
+	declare := "DECLARE dbee_cursor CURSOR FOR " + query
+	start := time.Now()
+	_, err = tx.ExecContext(ctx, declare)
+	c.logStatement(declare, start, err)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM dbee_cursor", fetchSize)
+
+	start = time.Now()
+	rows, err := tx.QueryContext(ctx, fetch)
+	c.logStatement(fetch, start, err)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	header, err := rows.Columns()
+	if err != nil {
+		_ = rows.Close()
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	// number of rows read from the current batch
+	var fetched int
+	// error of the last fetch or scan, which ended the stream early
+	var streamErr error
+
+	hasNextFunc := func() bool {
+		if rows.Next() {
+			fetched++
+			return true
+		}
+		if err := rows.Err(); err != nil {
+			streamErr = err
+			return false
+		}
+		// incomplete batch means the cursor is exhausted
+		if fetched < fetchSize {
+			return false
+		}
+
+		_ = rows.Close()
+		start := time.Now()
+		next, err := tx.QueryContext(ctx, fetch)
+		c.logStatement(fetch, start, err)
+		if err != nil {
+			streamErr = err
+			return false
+		}
+		rows = next
+		fetched = 0
+
+		if rows.Next() {
+			fetched++
+			return true
+		}
+		streamErr = rows.Err()
+		return false
+	}
+
+	nextFunc := func() (core.Row, error) {
+		row, err := c.scanRow(rows)
+		if err != nil {
+			streamErr = err
+		}
+		return row, err
+	}
+
+	result := NewResultStreamBuilder().
+		WithNextFunc(nextFunc, hasNextFunc).
+		WithErrFunc(func() error { return streamErr }).
+		WithHeader(header).
+		WithCloseFunc(func() {
+			_ = rows.Close()
+			// the cursor is closed at the end of the transaction
+			_ = tx.Rollback()
+		}).
+		Build()
+	result.meta.Endpoint = endpoint
+
+	return result, nil
+}
+
+func (c *Client) getTypeProcessor(col *sql.ColumnType) func(any) any {
+	proc, ok := c.typeProcessors[strings.ToLower(col.DatabaseTypeName())]
 	if ok {
 		return proc
 	}
 
+	if isBoolColumn(col) {
+		return normalizeBool
+	}
+
 	return func(val any) any {
 		valb, ok := val.([]byte)
 		if ok {
@@ -147,6 +470,44 @@ func (c *Client) getTypeProcessor(typ string) func(any) any {
 	}
 }
 
+// isBoolColumn reports whether the column is of a boolean type. TINYINT columns with
+// a display width of 1 are booleans as well, since mysql stores BOOL columns as TINYINT(1).
+func isBoolColumn(col *sql.ColumnType) bool {
+	switch strings.ToLower(col.DatabaseTypeName()) {
+	case "bool", "boolean":
+		return true
+	case "tinyint":
+		length, ok := col.Length()
+		return ok && length == 1
+	}
+	return false
+}
+
+// normalizeBool converts the value of a boolean column to a go bool, as some
+// drivers return booleans as numbers (e.g. sqlite) or strings.
+// Values that don't look like booleans are returned unchanged.
+func normalizeBool(val any) any {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case int32:
+		return v != 0
+	case int:
+		return v != 0
+	case []byte:
+		return normalizeBool(string(v))
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return v
+		}
+		return b
+	}
+	return val
+}
+
 // parseRows transforms sql rows to result stream.
 func (c *Client) parseRows(rows *sql.Rows) (*ResultStream, error) {
 	// create new rows
@@ -168,35 +529,12 @@ func (c *Client) parseRows(rows *sql.Rows) (*ResultStream, error) {
 	}
 
 	nextFunc := func() (core.Row, error) {
-		dbCols, err := rows.ColumnTypes()
-		if err != nil {
-			return nil, err
-		}
-
-		columns := make([]any, len(dbCols))
-		columnPointers := make([]any, len(dbCols))
-		for i := range columns {
-			columnPointers[i] = &columns[i]
-		}
-
-		if err := rows.Scan(columnPointers...); err != nil {
-			return nil, err
-		}
-
-		row := make(core.Row, len(dbCols))
-		for i := range dbCols {
-			val := *columnPointers[i].(*any)
-
-			proc := c.getTypeProcessor(dbCols[i].DatabaseTypeName())
-
-			row[i] = proc(val)
-		}
-
-		return row, nil
+		return c.scanRow(rows)
 	}
 
 	result := NewResultStreamBuilder().
 		WithNextFunc(nextFunc, hasNextFunc).
+		WithErrFunc(rows.Err).
 		WithHeader(header).
 		WithCloseFunc(func() {
 			_ = rows.Close()
@@ -205,3 +543,77 @@ func (c *Client) parseRows(rows *sql.Rows) (*ResultStream, error) {
 
 	return result, nil
 }
+
+// scanRow scans the current row and applies type processors to its values.
+func (c *Client) scanRow(rows *sql.Rows) (core.Row, error) {
+	dbCols, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.safeScan {
+		return scanSafe(rows, len(dbCols))
+	}
+
+	columns := make([]any, len(dbCols))
+	columnPointers := make([]any, len(dbCols))
+	for i := range columns {
+		columnPointers[i] = &columns[i]
+	}
+
+	if err := rows.Scan(columnPointers...); err != nil {
+		return nil, err
+	}
+
+	row := make(core.Row, len(dbCols))
+	for i := range dbCols {
+		val := *columnPointers[i].(*any)
+
+		proc := c.getTypeProcessor(dbCols[i])
+
+		row[i] = proc(val)
+	}
+
+	return row, nil
+}
+
+// safeValue is a scan destination that accepts any value and stores it as a string.
+type safeValue struct {
+	value any
+}
+
+func (sv *safeValue) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		sv.value = nil
+	case []byte:
+		sv.value = string(v)
+	case string:
+		sv.value = v
+	case time.Time:
+		sv.value = v.Format(time.RFC3339Nano)
+	default:
+		sv.value = fmt.Sprint(v)
+	}
+	return nil
+}
+
+// scanSafe scans the current row with all values converted to strings.
+func scanSafe(rows *sql.Rows, numCols int) (core.Row, error) {
+	values := make([]safeValue, numCols)
+	pointers := make([]any, numCols)
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	row := make(core.Row, numCols)
+	for i := range values {
+		row[i] = values[i].value
+	}
+
+	return row, nil
+}
@@ -1,13 +1,21 @@
 package builders
 
-import "strings"
+import (
+	"database/sql"
+	"strings"
+)
 
 type clientConfig struct {
 	typeProcessors map[string]func(any) any
+	warnings       WarningsFunc
 }
 
 type ClientOption func(*clientConfig)
 
+// WarningsFunc lists warnings of the last statement run on a connection.
+// query runs a statement on that same connection, since warnings are kept per session.
+type WarningsFunc func(query func(statement string) (*sql.Rows, error)) ([]string, error)
+
 func WithCustomTypeProcessor(typ string, fn func(any) any) ClientOption {
 	return func(cc *clientConfig) {
 		t := strings.ToLower(typ)
@@ -20,3 +28,11 @@ func WithCustomTypeProcessor(typ string, fn func(any) any) ClientOption {
 		cc.typeProcessors[t] = fn
 	}
 }
+
+// WithWarnings sets the function which reads warnings of queries run with
+// Client.QueryUntilNotEmpty. They are added to the result meta when the result is closed.
+func WithWarnings(fn WarningsFunc) ClientOption {
+	return func(cc *clientConfig) {
+		cc.warnings = fn
+	}
+}
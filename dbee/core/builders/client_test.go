@@ -0,0 +1,435 @@
+package builders_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+	"github.com/stretchr/testify/require"
+)
+
+// exoticValue is a driver value type unknown to dbee.
+type exoticValue struct {
+	x, y int
+}
+
+func (e exoticValue) String() string {
+	return fmt.Sprintf("(%d,%d)", e.x, e.y)
+}
+
+// exoticDriver is a minimal sql driver which returns a single row:
+// exoticValue, nil, []byte("bytes")
+type exoticDriver struct{}
+
+func (exoticDriver) Open(string) (driver.Conn, error) { return exoticConn{}, nil }
+
+type exoticConn struct{}
+
+func (exoticConn) Prepare(string) (driver.Stmt, error) { return exoticStmt{}, nil }
+func (exoticConn) Close() error                        { return nil }
+func (exoticConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type exoticStmt struct{}
+
+func (exoticStmt) Close() error                               { return nil }
+func (exoticStmt) NumInput() int                              { return -1 }
+func (exoticStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (exoticStmt) Query([]driver.Value) (driver.Rows, error)  { return &exoticRows{}, nil }
+
+type exoticRows struct {
+	done bool
+}
+
+func (*exoticRows) Columns() []string { return []string{"point", "nothing", "bytes"} }
+func (*exoticRows) Close() error      { return nil }
+
+func (r *exoticRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = exoticValue{x: 1, y: 2}
+	dest[1] = nil
+	dest[2] = []byte("bytes")
+	return nil
+}
+
+func init() {
+	sql.Register("dbee-exotic", exoticDriver{})
+}
+
+func queryExotic(t *testing.T, safeScan bool) core.Row {
+	t.Helper()
+	r := require.New(t)
+
+	db, err := sql.Open("dbee-exotic", "")
+	r.NoError(err)
+
+	client := builders.NewClient(db)
+	defer client.Close()
+	client.SetSafeScan(safeScan)
+
+	result, err := client.Query(context.Background(), "select")
+	r.NoError(err)
+	defer result.Close()
+
+	r.True(result.HasNext())
+	row, err := result.Next()
+	r.NoError(err)
+
+	return row
+}
+
+func TestClient_SafeScan(t *testing.T) {
+	r := require.New(t)
+
+	// default scan keeps the unknown type, which can't be archived
+	row := queryExotic(t, false)
+	r.Equal(core.Row{exoticValue{x: 1, y: 2}, nil, "bytes"}, row)
+	err := gob.NewEncoder(new(bytes.Buffer)).Encode(row)
+	r.Error(err)
+
+	// safe scan converts everything to strings and preserves NULLs
+	row = queryExotic(t, true)
+	r.Equal(core.Row{"(1,2)", nil, "bytes"}, row)
+	err = gob.NewEncoder(new(bytes.Buffer)).Encode(row)
+	r.NoError(err)
+}
+
+func TestClient_IdleTimeout(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee-exotic", "")
+	r.NoError(err)
+
+	client := builders.NewClient(db)
+	defer client.Close()
+	client.SetIdleTimeout(100 * time.Millisecond)
+
+	query := func() {
+		result, err := client.Query(context.Background(), "select")
+		r.NoError(err)
+		for result.HasNext() {
+			_, err := result.Next()
+			r.NoError(err)
+		}
+		result.Close()
+	}
+
+	// connection is kept in the pool after the query
+	query()
+	r.Equal(1, db.Stats().Idle)
+
+	// and closed after the timeout
+	r.Eventually(func() bool {
+		return db.Stats().OpenConnections == 0
+	}, time.Second, 20*time.Millisecond)
+
+	// next query opens a new connection and restores the pool
+	query()
+	r.Equal(1, db.Stats().Idle)
+}
+
+func TestClient_Peek(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee-exotic", "")
+	r.NoError(err)
+
+	client := builders.NewClient(db)
+	defer client.Close()
+
+	columns, err := client.Peek(context.Background(), "select")
+	r.NoError(err)
+	r.Equal([]*core.Column{{Name: "point"}, {Name: "nothing"}, {Name: "bytes"}}, columns)
+}
+
+func TestClient_StatementLog(t *testing.T) {
+	r := require.New(t)
+
+	db, err := sql.Open("dbee-exotic", "")
+	r.NoError(err)
+
+	client := builders.NewClient(db)
+	defer client.Close()
+
+	type entry struct {
+		statement string
+		failed    bool
+	}
+	var logged []entry
+	client.SetStatementLog(func(statement string, elapsed time.Duration, err error) {
+		r.GreaterOrEqual(elapsed, time.Duration(0))
+		logged = append(logged, entry{statement: statement, failed: err != nil})
+	})
+
+	result, err := client.Query(context.Background(), "CREATE USER bob PASSWORD 'hunter2'")
+	r.NoError(err)
+	result.Close()
+
+	_, err = client.Exec(context.Background(), "ALTER USER bob IDENTIFIED BY hunter2")
+	r.Error(err)
+
+	r.Equal([]entry{
+		{statement: "CREATE USER bob PASSWORD '***'"},
+		{statement: "ALTER USER bob IDENTIFIED BY ***", failed: true},
+	}, logged)
+
+	// logging is disabled with nil
+	client.SetStatementLog(nil)
+	result, err = client.Query(context.Background(), "select")
+	r.NoError(err)
+	result.Close()
+	r.Len(logged, 2)
+}
+
+// cursorServer simulates a database with a large table, which can only be read
+// in batches with a server-side cursor ("DECLARE ..." and "FETCH FORWARD n ...").
+type cursorServer struct {
+	total int
+	// position of the declared cursor, -1 if there is none
+	cursor int
+	// largest number of rows materialized by a single fetch
+	maxBatch   int
+	rolledBack bool
+	// number of fetches so far, the failFetch-th one fails (if set)
+	fetches   int
+	failFetch int
+}
+
+func (s *cursorServer) Connect(context.Context) (driver.Conn, error) { return &cursorConn{s}, nil }
+func (s *cursorServer) Driver() driver.Driver                        { return nil }
+
+type cursorConn struct {
+	srv *cursorServer
+}
+
+func (c *cursorConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *cursorConn) Close() error                        { return nil }
+func (c *cursorConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *cursorConn) Commit() error                       { return nil }
+
+func (c *cursorConn) Rollback() error {
+	c.srv.cursor = -1
+	c.srv.rolledBack = true
+	return nil
+}
+
+func (c *cursorConn) Exec(query string, _ []driver.Value) (driver.Result, error) {
+	if !strings.HasPrefix(query, "DECLARE dbee_cursor CURSOR FOR ") {
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+	c.srv.cursor = 0
+	return driver.RowsAffected(0), nil
+}
+
+func (c *cursorConn) Query(query string, _ []driver.Value) (driver.Rows, error) {
+	var n int
+	if _, err := fmt.Sscanf(query, "FETCH FORWARD %d FROM dbee_cursor", &n); err != nil {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	if c.srv.cursor < 0 {
+		return nil, errors.New("cursor not declared")
+	}
+	c.srv.fetches++
+	if c.srv.fetches == c.srv.failFetch {
+		return nil, errors.New("connection reset by peer")
+	}
+
+	batch := make([]int64, 0, n)
+	for ; len(batch) < n && c.srv.cursor < c.srv.total; c.srv.cursor++ {
+		batch = append(batch, int64(c.srv.cursor))
+	}
+	c.srv.maxBatch = max(c.srv.maxBatch, len(batch))
+
+	return &cursorRows{batch: batch}, nil
+}
+
+type cursorRows struct {
+	batch []int64
+}
+
+func (*cursorRows) Columns() []string { return []string{"id"} }
+func (*cursorRows) Close() error      { return nil }
+
+func (r *cursorRows) Next(dest []driver.Value) error {
+	if len(r.batch) < 1 {
+		return io.EOF
+	}
+	dest[0] = r.batch[0]
+	r.batch = r.batch[1:]
+	return nil
+}
+
+func TestClient_QueryCursor(t *testing.T) {
+	r := require.New(t)
+
+	const (
+		total     = 100_000
+		fetchSize = 500
+	)
+
+	srv := &cursorServer{total: total, cursor: -1}
+	client := builders.NewClient(sql.OpenDB(srv))
+	defer client.Close()
+
+	result, err := client.QueryCursor(context.Background(), "SELECT id FROM big", fetchSize)
+	r.NoError(err)
+	r.Equal(core.Header{"id"}, result.Header())
+
+	var read int64
+	for result.HasNext() {
+		row, err := result.Next()
+		r.NoError(err)
+		r.Equal(core.Row{read}, row)
+		read++
+	}
+	result.Close()
+
+	// all rows are streamed, but never more than a single batch is held at once
+	r.EqualValues(total, read)
+	r.NoError(result.Err())
+	r.Equal(fetchSize, srv.maxBatch)
+	r.True(srv.rolledBack)
+
+	_, err = client.QueryCursor(context.Background(), "SELECT id FROM big", 0)
+	r.Error(err)
+}
+
+func TestClient_QueryCursor_FetchError(t *testing.T) {
+	r := require.New(t)
+
+	const fetchSize = 10
+
+	srv := &cursorServer{total: 100, cursor: -1, failFetch: 3}
+	client := builders.NewClient(sql.OpenDB(srv))
+	defer client.Close()
+
+	var logged []string
+	client.SetStatementLog(func(statement string, _ time.Duration, _ error) {
+		logged = append(logged, statement)
+	})
+
+	result, err := client.QueryCursor(context.Background(), "SELECT id FROM big", fetchSize)
+	r.NoError(err)
+
+	var read int
+	for result.HasNext() {
+		_, err := result.Next()
+		r.NoError(err)
+		read++
+	}
+	result.Close()
+
+	// the stream ends with the failed fetch, which is reported by Err
+	r.Equal(2*fetchSize, read)
+	r.ErrorContains(result.Err(), "connection reset by peer")
+
+	fetch := "FETCH FORWARD 10 FROM dbee_cursor"
+	r.Equal([]string{"DECLARE dbee_cursor CURSOR FOR SELECT id FROM big", fetch, fetch, fetch}, logged)
+}
+
+// endpointServer is a connector of a fake server, which returns its name
+// for every query. Connecting fails if the server is down.
+type endpointServer struct {
+	name    string
+	down    bool
+	queries []string
+}
+
+func (s *endpointServer) Connect(context.Context) (driver.Conn, error) {
+	if s.down {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return &endpointConn{s}, nil
+}
+func (s *endpointServer) Driver() driver.Driver { return nil }
+
+type endpointConn struct {
+	srv *endpointServer
+}
+
+func (c *endpointConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *endpointConn) Close() error                        { return nil }
+func (c *endpointConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c *endpointConn) Query(query string, _ []driver.Value) (driver.Rows, error) {
+	c.srv.queries = append(c.srv.queries, query)
+	return &endpointRows{name: c.srv.name}, nil
+}
+
+type endpointRows struct {
+	name string
+	done bool
+}
+
+func (*endpointRows) Columns() []string { return []string{"server"} }
+func (*endpointRows) Close() error      { return nil }
+
+func (r *endpointRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.name
+	return nil
+}
+
+func TestClient_Replicas(t *testing.T) {
+	r := require.New(t)
+
+	primary := &endpointServer{name: "primary"}
+	replicaA := &endpointServer{name: "a"}
+	replicaB := &endpointServer{name: "b", down: true}
+
+	client := builders.NewClient(sql.OpenDB(primary))
+	defer client.Close()
+
+	query := func(q string) (string, string) {
+		result, err := client.Query(context.Background(), q)
+		r.NoError(err)
+		defer result.Close()
+
+		r.True(result.HasNext())
+		row, err := result.Next()
+		r.NoError(err)
+		return row[0].(string), result.Meta().Endpoint
+	}
+
+	// no routing without replicas
+	server, endpoint := query("SELECT 1")
+	r.Equal("primary", server)
+	r.Empty(endpoint)
+
+	client.AddReplica("a:5432", sql.OpenDB(replicaA))
+	client.AddReplica("b:5432", sql.OpenDB(replicaB))
+
+	// reads are routed round-robin, unreachable replica falls back to the primary
+	server, endpoint = query("SELECT 1")
+	r.Equal("a", server)
+	r.Equal("a:5432", endpoint)
+	server, endpoint = query("SELECT 2")
+	r.Equal("primary", server)
+	r.Equal("primary", endpoint)
+	server, _ = query("SELECT 3")
+	r.Equal("a", server)
+
+	// writes go to the primary
+	server, endpoint = query("INSERT INTO t VALUES (1) RETURNING id")
+	r.Equal("primary", server)
+	r.Equal("primary", endpoint)
+
+	r.Equal([]string{"SELECT 1", "SELECT 3"}, replicaA.queries)
+	r.Equal([]string{"SELECT 1", "SELECT 2", "INSERT INTO t VALUES (1) RETURNING id"}, primary.queries)
+}
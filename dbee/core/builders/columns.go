@@ -3,6 +3,8 @@ package builders
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
@@ -13,6 +15,13 @@ import (
 //
 //	1st elem: name - string
 //	2nd elem: type - string
+//	3rd elem: max length - number (optional)
+//	4th elem: precision - number (optional)
+//	5th elem: scale - number (optional)
+//	6th elem: is generated - boolean, number or "YES"/"ALWAYS" string (optional)
+//	7th elem: generation expression - string (optional)
+//
+// Optional elements of other types (e.g. nulls) are left unset.
 func ColumnsFromResultStream(rows core.ResultStream) ([]*core.Column, error) {
 	var out []*core.Column
 
@@ -40,9 +49,76 @@ func ColumnsFromResultStream(rows core.ResultStream) ([]*core.Column, error) {
 			Name: name,
 			Type: typ,
 		}
+		if len(row) > 2 {
+			column.MaxLength = columnNumber(row[2])
+		}
+		if len(row) > 3 {
+			column.Precision = columnNumber(row[3])
+		}
+		if len(row) > 4 {
+			column.Scale = columnNumber(row[4])
+		}
+		if len(row) > 5 {
+			column.IsGenerated = columnBool(row[5])
+		}
+		if len(row) > 6 {
+			column.GenerationExpression, _ = row[6].(string)
+		}
 
 		out = append(out, column)
 	}
 
 	return out, nil
 }
+
+// columnNumber converts the column attribute to a number.
+// Zero is returned if the value isn't a number.
+func columnNumber(val any) int64 {
+	switch v := val.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0
+		}
+		return int64(n)
+	}
+	return 0
+}
+
+// columnBool converts the column attribute to a boolean.
+// Non-zero numbers and strings such as "YES" or "ALWAYS" are true.
+func columnBool(val any) bool {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToUpper(strings.TrimSpace(v)) {
+		case "YES", "ALWAYS", "TRUE", "1":
+			return true
+		}
+		return false
+	}
+	return columnNumber(val) != 0
+}
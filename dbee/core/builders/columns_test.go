@@ -0,0 +1,48 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestColumnsFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := [][]any{
+		{"id", "integer", nil, int64(32), int64(0)},
+		{"name", "character varying", int32(255), nil, nil},
+		{"price", "numeric", nil, "10", "2"},
+		{"created", "timestamp"},
+		{"active", "boolean", "YES"},
+		{"total", "numeric", nil, nil, nil, "ALWAYS", "(price * 2)"},
+		{"label", "text", nil, nil, nil, int64(1), nil},
+		{"updated", "timestamp", nil, nil, nil, "NEVER", ""},
+	}
+
+	result := builders.NewResultStreamBuilder().
+		WithNextFunc(builders.NextYield(func(yield func(...any)) error {
+			for _, row := range rows {
+				yield(row...)
+			}
+			return nil
+		})).
+		WithHeader(core.Header{"name", "type", "max_length", "precision", "scale", "is_generated", "generation_expression"}).
+		Build()
+
+	columns, err := builders.ColumnsFromResultStream(result)
+	r.NoError(err)
+	r.Equal([]*core.Column{
+		{Name: "id", Type: "integer", Precision: 32},
+		{Name: "name", Type: "character varying", MaxLength: 255},
+		{Name: "price", Type: "numeric", Precision: 10, Scale: 2},
+		{Name: "created", Type: "timestamp"},
+		{Name: "active", Type: "boolean"},
+		{Name: "total", Type: "numeric", IsGenerated: true, GenerationExpression: "(price * 2)"},
+		{Name: "label", Type: "text", IsGenerated: true},
+		{Name: "updated", Type: "timestamp"},
+	}, columns)
+}
@@ -0,0 +1,214 @@
+package builders
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// copyDataStmt is a prepared COPY ... FROM STDIN statement which accepts raw lines
+// of data (e.g. of lib/pq).
+type copyDataStmt interface {
+	driver.Stmt
+	CopyData(ctx context.Context, line string) (driver.Result, error)
+}
+
+// CopyOut runs the query and writes its rows as csv to w. Values are written in the
+// text form sent by the database, without being scanned to go values, which is what
+// "COPY ... TO STDOUT" would output (but works with drivers that don't support it).
+// Returns the number of written rows.
+func (c *Client) CopyOut(ctx context.Context, query string, w io.Writer, opts *core.CopyOptions) (int64, error) {
+	c.touch()
+
+	start := time.Now()
+	rows, err := c.db.QueryContext(ctx, query)
+	c.logStatement(query, start, err)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	header, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	cw := newCopyWriter(w, opts)
+	if opts != nil && opts.Header {
+		if err := cw.write(header, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	values := make([]sql.RawBytes, len(header))
+	dest := make([]any, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, err
+		}
+
+		fields := make([]string, len(values))
+		nulls := make([]bool, len(values))
+		for i, v := range values {
+			fields[i], nulls[i] = string(v), v == nil
+		}
+		if err := cw.write(fields, nulls); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, cw.flush()
+}
+
+// CopyIn runs the "COPY ... FROM STDIN" statement in a transaction and streams lines
+// read from r as its data. Returns the number of copied rows (as reported by the database).
+func (c *Client) CopyIn(ctx context.Context, statement string, r io.Reader) (int64, error) {
+	c.touch()
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var count int64
+	start := time.Now()
+	err = conn.Raw(func(driverConn any) error {
+		var err error
+		count, err = copyIn(ctx, driverConn, statement, r)
+		return err
+	})
+	c.logStatement(statement, start, err)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func copyIn(ctx context.Context, driverConn any, statement string, r io.Reader) (count int64, err error) {
+	beginner, ok := driverConn.(driver.ConnBeginTx)
+	if !ok {
+		return 0, errors.New("driver doesn't support transactions")
+	}
+	conn, ok := driverConn.(driver.Conn)
+	if !ok {
+		return 0, errors.New("driver doesn't support prepared statements")
+	}
+
+	tx, err := beginner.BeginTx(ctx, driver.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	prepared, err := conn.Prepare(statement)
+	if err != nil {
+		return 0, err
+	}
+	stmt, ok := prepared.(copyDataStmt)
+	if !ok {
+		_ = prepared.Close()
+		return 0, errors.New("driver doesn't support copying data")
+	}
+	defer stmt.Close()
+
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return 0, fmt.Errorf("unable to read data: %w", readErr)
+		}
+
+		// CopyData terminates every line with a newline
+		if line != "" {
+			if _, err := stmt.CopyData(ctx, strings.TrimSuffix(line, "\n")); err != nil {
+				return 0, err
+			}
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	// empty exec flushes the data and returns the result of the statement
+	res, err := stmt.Exec(nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	count, _ = res.RowsAffected()
+	return count, nil
+}
+
+// copyWriter writes csv rows like COPY does: NULL values are written as the null
+// string of the options and fields that could be mistaken for it are quoted.
+type copyWriter struct {
+	w         *bufio.Writer
+	delimiter string
+	null      string
+}
+
+func newCopyWriter(w io.Writer, opts *core.CopyOptions) *copyWriter {
+	cw := &copyWriter{
+		w:         bufio.NewWriter(w),
+		delimiter: opts.DelimiterOrDefault(),
+	}
+	if opts != nil {
+		cw.null = opts.Null
+	}
+	return cw
+}
+
+// write writes a row. nulls marks NULL fields (nil means none are).
+func (cw *copyWriter) write(fields []string, nulls []bool) error {
+	for i, field := range fields {
+		if i > 0 {
+			if _, err := cw.w.WriteString(cw.delimiter); err != nil {
+				return err
+			}
+		}
+
+		if nulls != nil && nulls[i] {
+			field = cw.null
+		} else if field == cw.null || strings.ContainsAny(field, cw.delimiter+"\"\r\n") {
+			field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+		}
+
+		if _, err := cw.w.WriteString(field); err != nil {
+			return err
+		}
+	}
+
+	_, err := cw.w.WriteString("\n")
+	return err
+}
+
+func (cw *copyWriter) flush() error {
+	return cw.w.Flush()
+}
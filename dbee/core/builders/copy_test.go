@@ -0,0 +1,140 @@
+package builders_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+// copyServer serves a fixed result and records data of COPY statements.
+type copyServer struct {
+	rows      [][]driver.Value
+	statement string
+	data      string
+	committed bool
+	failOn    string
+}
+
+func (s *copyServer) Connect(context.Context) (driver.Conn, error) { return &copyConn{s}, nil }
+func (s *copyServer) Driver() driver.Driver                        { return nil }
+
+type copyConn struct {
+	srv *copyServer
+}
+
+func (c *copyConn) Close() error              { return nil }
+func (c *copyConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *copyConn) Commit() error             { c.srv.committed = true; return nil }
+func (c *copyConn) Rollback() error           { return nil }
+
+func (c *copyConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return c, nil }
+
+func (c *copyConn) Prepare(query string) (driver.Stmt, error) {
+	c.srv.statement = query
+	return &copyStmt{c.srv}, nil
+}
+
+func (c *copyConn) Query(string, []driver.Value) (driver.Rows, error) {
+	return &copyRows{rows: c.srv.rows}, nil
+}
+
+type copyStmt struct {
+	srv *copyServer
+}
+
+func (s *copyStmt) Close() error                              { return nil }
+func (s *copyStmt) NumInput() int                             { return -1 }
+func (s *copyStmt) Query([]driver.Value) (driver.Rows, error) { return nil, driver.ErrSkip }
+
+func (s *copyStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(strings.Count(s.srv.data, "\n")), nil
+}
+
+func (s *copyStmt) CopyData(_ context.Context, line string) (driver.Result, error) {
+	if s.srv.failOn != "" && line == s.srv.failOn {
+		return nil, errors.New("invalid input syntax")
+	}
+	s.srv.data += line + "\n"
+	return driver.RowsAffected(0), nil
+}
+
+type copyRows struct {
+	rows [][]driver.Value
+}
+
+func (*copyRows) Columns() []string { return []string{"id", "name"} }
+func (*copyRows) Close() error      { return nil }
+
+func (r *copyRows) Next(dest []driver.Value) error {
+	if len(r.rows) < 1 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestClient_CopyOut(t *testing.T) {
+	r := require.New(t)
+
+	srv := &copyServer{rows: [][]driver.Value{
+		{[]byte("1"), []byte("plain")},
+		{[]byte("2"), []byte("")},
+		{[]byte("3"), nil},
+		{[]byte("4"), []byte("with \"quotes\";\nand lines")},
+	}}
+	client := builders.NewClient(sql.OpenDB(srv))
+	defer client.Close()
+
+	var out bytes.Buffer
+	count, err := client.CopyOut(context.Background(), "SELECT * FROM t", &out, nil)
+	r.NoError(err)
+	r.EqualValues(4, count)
+	// empty strings are quoted, so they're not mistaken for NULL
+	r.Equal("1,plain\n2,\"\"\n3,\n4,\"with \"\"quotes\"\";\nand lines\"\n", out.String())
+
+	srv.rows = [][]driver.Value{{[]byte("1"), nil}, {[]byte("2"), []byte("NULL")}}
+	out.Reset()
+	count, err = client.CopyOut(context.Background(), "SELECT * FROM t", &out, &core.CopyOptions{
+		Delimiter: ";",
+		Null:      "NULL",
+		Header:    true,
+	})
+	r.NoError(err)
+	r.EqualValues(2, count)
+	r.Equal("id;name\n1;NULL\n2;\"NULL\"\n", out.String())
+}
+
+func TestClient_CopyIn(t *testing.T) {
+	r := require.New(t)
+
+	srv := &copyServer{}
+	client := builders.NewClient(sql.OpenDB(srv))
+	defer client.Close()
+
+	data := "id,name\n1,\"multi\nline\"\r\n2,last"
+	count, err := client.CopyIn(context.Background(), "COPY t FROM STDIN", strings.NewReader(data))
+	r.NoError(err)
+	r.EqualValues(4, count)
+	r.Equal("COPY t FROM STDIN", srv.statement)
+	r.Equal(data+"\n", srv.data)
+	r.True(srv.committed)
+
+	srv = &copyServer{failOn: "2,last"}
+	client = builders.NewClient(sql.OpenDB(srv))
+	defer client.Close()
+
+	_, err = client.CopyIn(context.Background(), "COPY t FROM STDIN", strings.NewReader(data))
+	r.ErrorContains(err, "invalid input syntax")
+	r.False(srv.committed)
+}
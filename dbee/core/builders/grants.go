@@ -0,0 +1,96 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// GrantsQuery returns a query which lists privileges on the table from the standard
+// information_schema.table_privileges view, in the form expected by GrantsFromResultStream.
+// If grantee is set (an sql expression, e.g. "CURRENT_USER"), only its privileges are listed.
+// Adapters can union it with queries of privileges granted on other levels.
+func GrantsQuery(opts *core.TableOptions, grantee string) string {
+	query := fmt.Sprintf(`SELECT grantee, privilege_type, is_grantable, 'TABLE' AS level
+FROM information_schema.table_privileges
+WHERE table_schema = '%s' AND table_name = '%s'`, opts.Schema, opts.Table)
+	if grantee != "" {
+		query += " AND grantee = " + grantee
+	}
+	return query
+}
+
+// GrantsFromResultStream converts the result stream to grants.
+// A result stream should return rows that are at least 3 columns wide and
+// have the following structure:
+//
+//	1st elem: grantee - string
+//	2nd elem: privilege - string
+//	3rd elem: grantable - bool, number or "YES"/"NO"
+//	4th elem: level - string (optional)
+func GrantsFromResultStream(rows core.ResultStream) ([]*core.Grant, error) {
+	defer rows.Close()
+
+	var out []*core.Grant
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+
+		if len(row) < 3 {
+			return nil, errors.New("could not retrieve grant info: insufficient data")
+		}
+
+		grant := &core.Grant{
+			Grantee:   grantString(row[0]),
+			Privilege: grantString(row[1]),
+			Grantable: grantBool(row[2]),
+		}
+		if len(row) > 3 {
+			grant.Level = grantString(row[3])
+		}
+
+		out = append(out, grant)
+	}
+
+	return out, nil
+}
+
+// GrantsFromQuery executes the query and converts the results to grants
+// (see GrantsFromResultStream).
+func (c *Client) GrantsFromQuery(query string) ([]*core.Grant, error) {
+	result, err := c.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	return GrantsFromResultStream(result)
+}
+
+func grantString(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(val)
+}
+
+func grantBool(val any) bool {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case int:
+		return v != 0
+	}
+	s := strings.ToUpper(grantString(val))
+	return s == "YES" || s == "Y" || s == "TRUE" || s == "1"
+}
@@ -0,0 +1,54 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestGrantsFromResultStream(t *testing.T) {
+	r := require.New(t)
+
+	rows := [][]any{
+		{"alice", "SELECT", "YES", "TABLE"},
+		{[]byte("'bob'@'%'"), "INSERT", "NO", "SCHEMA"},
+		{"PUBLIC", "SELECT", false, nil},
+		{"carol", "UPDATE", true},
+		{"dave", "DELETE", int64(1)},
+	}
+
+	result := builders.NewResultStreamBuilder().
+		WithNextFunc(builders.NextYield(func(yield func(...any)) error {
+			for _, row := range rows {
+				yield(row...)
+			}
+			return nil
+		})).
+		WithHeader(core.Header{"grantee", "privilege_type", "is_grantable", "level"}).
+		Build()
+
+	grants, err := builders.GrantsFromResultStream(result)
+	r.NoError(err)
+	r.Equal([]*core.Grant{
+		{Grantee: "alice", Privilege: "SELECT", Grantable: true, Level: "TABLE"},
+		{Grantee: "'bob'@'%'", Privilege: "INSERT", Level: "SCHEMA"},
+		{Grantee: "PUBLIC", Privilege: "SELECT"},
+		{Grantee: "carol", Privilege: "UPDATE", Grantable: true},
+		{Grantee: "dave", Privilege: "DELETE", Grantable: true},
+	}, grants)
+}
+
+func TestGrantsQuery(t *testing.T) {
+	r := require.New(t)
+
+	opts := &core.TableOptions{Schema: "public", Table: "orders"}
+
+	r.Equal(`SELECT grantee, privilege_type, is_grantable, 'TABLE' AS level
+FROM information_schema.table_privileges
+WHERE table_schema = 'public' AND table_name = 'orders'`, builders.GrantsQuery(opts, ""))
+
+	r.Contains(builders.GrantsQuery(opts, "CURRENT_USER"), "AND grantee = CURRENT_USER")
+}
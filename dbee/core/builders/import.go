@@ -0,0 +1,201 @@
+package builders
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// importSampleRows is the number of rows used to infer column types of an imported table.
+const importSampleRows = 100
+
+// ColumnKind is the kind of values of an imported column.
+type ColumnKind int
+
+const (
+	ColumnKindText ColumnKind = iota
+	ColumnKindInteger
+	ColumnKindReal
+	ColumnKindBoolean
+	ColumnKindTime
+	ColumnKindBlob
+)
+
+// valueKind returns the kind of a single (non-null) value.
+func valueKind(v any) ColumnKind {
+	switch val := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return ColumnKindInteger
+	case float32, float64:
+		return ColumnKindReal
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return ColumnKindInteger
+		}
+		return ColumnKindReal
+	case bool:
+		return ColumnKindBoolean
+	case time.Time:
+		return ColumnKindTime
+	case []byte:
+		return ColumnKindBlob
+	}
+	return ColumnKindText
+}
+
+// InferColumnKind returns the kind that fits all non-null values: integers mixed
+// with reals are real and any other mix is text. Columns without values are text.
+func InferColumnKind(values []any) ColumnKind {
+	kind := ColumnKindText
+	seen := false
+
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		k := valueKind(v)
+		switch {
+		case !seen:
+			kind = k
+			seen = true
+		case k == kind:
+		case (k == ColumnKindInteger && kind == ColumnKindReal) || (k == ColumnKindReal && kind == ColumnKindInteger):
+			kind = ColumnKindReal
+		default:
+			return ColumnKindText
+		}
+	}
+
+	return kind
+}
+
+// importValue converts the value to one accepted by sql drivers.
+// Values of unknown types are converted to strings.
+func importValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case map[string]any, []any:
+		b, err := json.Marshal(val)
+		if err == nil {
+			return string(b)
+		}
+	}
+
+	converted, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return converted
+}
+
+// ImportTable creates the table (dropping the existing one) and inserts all rows of the result
+// in a single transaction. Column types are inferred from the first rows of the result and named
+// with typeName. Duplicate column names are handled as described in core.Header.Keys.
+// Returns the number of inserted rows.
+func (c *Client) ImportTable(ctx context.Context, table string, rows core.ResultStream, style QuoteStyle, typeName func(ColumnKind) string) (int, error) {
+	defer rows.Close()
+
+	keys := rows.Header().Keys()
+	if len(keys) < 1 {
+		return 0, fmt.Errorf("result has no columns")
+	}
+
+	// sample rows for type inference
+	var sample []core.Row
+	for len(sample) < importSampleRows && rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return 0, fmt.Errorf("rows.Next: %w", err)
+		}
+		sample = append(sample, row)
+	}
+
+	columns := make([]string, len(keys))
+	placeholders := make([]string, len(keys))
+	definitions := make([]string, len(keys))
+	for i, key := range keys {
+		values := make([]any, 0, len(sample))
+		for _, row := range sample {
+			if i < len(row) {
+				values = append(values, row[i])
+			}
+		}
+
+		columns[i] = QuoteIdentifier(key, style)
+		placeholders[i] = "?"
+		definitions[i] = columns[i] + " " + typeName(InferColumnKind(values))
+	}
+
+	quoted := QuoteIdentifier(table, style)
+
+	c.touch()
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("c.db.BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoted); err != nil {
+		return 0, fmt.Errorf("drop table: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoted, strings.Join(definitions, ", "))); err != nil {
+		return 0, fmt.Errorf("create table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		quoted, strings.Join(columns, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return 0, fmt.Errorf("tx.PrepareContext: %w", err)
+	}
+	defer stmt.Close()
+
+	count := 0
+	insert := func(row core.Row) error {
+		args := make([]any, len(keys))
+		for i := range args {
+			if i < len(row) {
+				args[i] = importValue(row[i])
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("insert row %d: %w", count+1, err)
+		}
+		count++
+		return nil
+	}
+
+	for _, row := range sample {
+		if err := insert(row); err != nil {
+			return 0, err
+		}
+	}
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return 0, fmt.Errorf("rows.Next: %w", err)
+		}
+		if err := insert(row); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,34 @@
+package builders_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestInferColumnKind(t *testing.T) {
+	tests := []struct {
+		values   []any
+		expected builders.ColumnKind
+	}{
+		{values: []any{1, int64(2), nil, json.Number("3")}, expected: builders.ColumnKindInteger},
+		{values: []any{1, 2.5, json.Number("3.5")}, expected: builders.ColumnKindReal},
+		{values: []any{true, nil, false}, expected: builders.ColumnKindBoolean},
+		{values: []any{time.Now()}, expected: builders.ColumnKindTime},
+		{values: []any{[]byte("x")}, expected: builders.ColumnKindBlob},
+		{values: []any{"a", nil}, expected: builders.ColumnKindText},
+		// mixed kinds and no values fall back to text
+		{values: []any{1, "a"}, expected: builders.ColumnKindText},
+		{values: []any{true, 1}, expected: builders.ColumnKindText},
+		{values: []any{nil, nil}, expected: builders.ColumnKindText},
+		{values: nil, expected: builders.ColumnKindText},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, builders.InferColumnKind(tt.values), "%v", tt.values)
+	}
+}
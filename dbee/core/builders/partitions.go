@@ -0,0 +1,112 @@
+package builders
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// NestPartitions moves partitions of the structure under their parent tables.
+// A result stream should return rows that are 4 columns wide and have the
+// following structure:
+//
+//	1st elem: schema of the parent table - string
+//	2nd elem: parent table - string
+//	3rd elem: schema of the partition - string
+//	4th elem: partition - string
+//
+// Partitions which are listed in the structure as tables are moved under their parent,
+// others are added to it. Partitions of tables missing in the structure are left as they are.
+// Parents can be partitions themselves (sub-partitioning).
+func NestPartitions(structure []*core.Structure, rows core.ResultStream) ([]*core.Structure, error) {
+	type partition struct {
+		parentKey string
+		key       string
+		name      string
+		schema    string
+		parent    string
+	}
+	var partitions []partition
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("result.Next: %w", err)
+		}
+		if len(row) < 4 {
+			return nil, errors.New("could not retrieve partitions: insufficient data")
+		}
+
+		parentSchema, ok1 := row[0].(string)
+		parent, ok2 := row[1].(string)
+		schema, ok3 := row[2].(string)
+		name, ok4 := row[3].(string)
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			return nil, errors.New("could not retrieve partitions: names not strings")
+		}
+
+		partitions = append(partitions, partition{
+			parentKey: parentSchema + "." + parent,
+			key:       schema + "." + name,
+			name:      name,
+			schema:    schema,
+			parent:    parent,
+		})
+	}
+
+	tables := make(map[string]*core.Structure)
+	indexTables(structure, tables)
+
+	// partitions of known parents which are already in the structure
+	moved := make(map[*core.Structure]bool)
+	for _, p := range partitions {
+		if _, ok := tables[p.parentKey]; !ok {
+			continue
+		}
+		if t, ok := tables[p.key]; ok {
+			moved[t] = true
+			continue
+		}
+		tables[p.key] = &core.Structure{Name: p.name, Schema: p.schema}
+	}
+
+	structure = removeStructures(structure, moved)
+
+	for _, p := range partitions {
+		parent, ok := tables[p.parentKey]
+		if !ok {
+			continue
+		}
+		t := tables[p.key]
+		t.Type = core.StructureTypePartition
+		t.Parent = p.parent
+		parent.Children = append(parent.Children, t)
+	}
+
+	return structure, nil
+}
+
+// indexTables adds tables of the structure (and its children) to the map,
+// keyed by "schema.name".
+func indexTables(structure []*core.Structure, tables map[string]*core.Structure) {
+	for _, s := range structure {
+		if s.Type == core.StructureTypeTable || s.Type == core.StructureTypePartition {
+			tables[s.Schema+"."+s.Name] = s
+		}
+		indexTables(s.Children, tables)
+	}
+}
+
+// removeStructures returns the structure without the given nodes.
+func removeStructures(structure []*core.Structure, remove map[*core.Structure]bool) []*core.Structure {
+	var out []*core.Structure
+	for _, s := range structure {
+		if remove[s] {
+			continue
+		}
+		s.Children = removeStructures(s.Children, remove)
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestNestPartitions(t *testing.T) {
+	r := require.New(t)
+
+	structure := []*core.Structure{
+		{
+			Name:   "public",
+			Schema: "public",
+			Children: []*core.Structure{
+				{Name: "events", Schema: "public", Type: core.StructureTypeTable},
+				{Name: "events_2024", Schema: "public", Type: core.StructureTypeTable},
+				{Name: "events_2024_01", Schema: "public", Type: core.StructureTypeTable},
+				{Name: "users", Schema: "public", Type: core.StructureTypeTable},
+			},
+		},
+		{
+			Name:   "archive",
+			Schema: "archive",
+			Children: []*core.Structure{
+				{Name: "events_2023", Schema: "archive", Type: core.StructureTypeTable},
+			},
+		},
+	}
+
+	rows := [][]any{
+		// sub-partition comes before its parent partition
+		{"public", "events_2024", "public", "events_2024_01"},
+		{"public", "events", "public", "events_2024"},
+		{"public", "events", "archive", "events_2023"},
+		// not in the structure, so added to the parent
+		{"public", "users", "public", "p0"},
+		// parent is unknown
+		{"public", "missing", "public", "p1"},
+	}
+
+	result := builders.NewResultStreamBuilder().
+		WithNextFunc(builders.NextYield(func(yield func(...any)) error {
+			for _, row := range rows {
+				yield(row...)
+			}
+			return nil
+		})).
+		WithHeader(core.Header{"parent_schema", "parent", "schema", "partition"}).
+		Build()
+
+	nested, err := builders.NestPartitions(structure, result)
+	r.NoError(err)
+	r.Equal([]*core.Structure{
+		{
+			Name:   "public",
+			Schema: "public",
+			Children: []*core.Structure{
+				{
+					Name:   "events",
+					Schema: "public",
+					Type:   core.StructureTypeTable,
+					Children: []*core.Structure{
+						{
+							Name:   "events_2024",
+							Schema: "public",
+							Type:   core.StructureTypePartition,
+							Parent: "events",
+							Children: []*core.Structure{
+								{Name: "events_2024_01", Schema: "public", Type: core.StructureTypePartition, Parent: "events_2024"},
+							},
+						},
+						{Name: "events_2023", Schema: "archive", Type: core.StructureTypePartition, Parent: "events"},
+					},
+				},
+				{
+					Name:   "users",
+					Schema: "public",
+					Type:   core.StructureTypeTable,
+					Children: []*core.Structure{
+						{Name: "p0", Schema: "public", Type: core.StructureTypePartition, Parent: "users"},
+					},
+				},
+			},
+		},
+		{
+			Name:   "archive",
+			Schema: "archive",
+		},
+	}, nested)
+}
@@ -0,0 +1,126 @@
+package builders
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// primaryEndpoint is the name of the primary in result metadata.
+const primaryEndpoint = "primary"
+
+// replica is a pool of connections to a read replica of the database.
+type replica struct {
+	name string
+	db   *sql.DB
+}
+
+// AddReplica adds a read replica. Read-only statements (see core.IsReadOnlyStatement)
+// are routed to replicas in turns, while other statements run on the primary.
+// The name (e.g. host of the replica) is reported in metadata of results.
+func (c *Client) AddReplica(name string, db *sql.DB) {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+
+	if c.isIdle {
+		db.SetMaxIdleConns(0)
+	}
+	c.replicas = append(c.replicas, &replica{name: name, db: db})
+}
+
+// pickReplica returns the next replica (round-robin) if the query is read only.
+// Nil means the query runs on the primary.
+func (c *Client) pickReplica(query string) *replica {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+
+	if len(c.replicas) < 1 || !core.IsReadOnlyStatement(query) {
+		return nil
+	}
+
+	r := c.replicas[c.nextReplica%len(c.replicas)]
+	c.nextReplica++
+	return r
+}
+
+// endpoint returns the name of the primary in metadata, if the client has replicas.
+func (c *Client) endpoint() string {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+
+	if len(c.replicas) < 1 {
+		return ""
+	}
+	return primaryEndpoint
+}
+
+// queryRouted runs the query on a replica if it's read only and on the primary otherwise.
+// If the replica is unreachable, the query runs on the primary instead.
+// It returns the name of the endpoint which ran the query.
+func (c *Client) queryRouted(ctx context.Context, query string, args ...any) (*sql.Rows, string, error) {
+	c.touch()
+
+	if r := c.pickReplica(query); r != nil {
+		start := time.Now()
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		c.logStatement(query, start, err)
+		if err == nil || !isConnectionError(err) {
+			return rows, r.name, err
+		}
+	}
+
+	start := time.Now()
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	c.logStatement(query, start, err)
+	return rows, c.endpoint(), err
+}
+
+// beginRouted is like queryRouted, but it starts a transaction for the query.
+func (c *Client) beginRouted(ctx context.Context, query string) (*sql.Tx, string, error) {
+	c.touch()
+
+	if r := c.pickReplica(query); r != nil {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err == nil || !isConnectionError(err) {
+			return tx, r.name, err
+		}
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	return tx, c.endpoint(), err
+}
+
+// connRouted is like queryRouted, but it returns a single connection for the query.
+func (c *Client) connRouted(ctx context.Context, query string) (*sql.Conn, string, error) {
+	c.touch()
+
+	if r := c.pickReplica(query); r != nil {
+		conn, err := r.db.Conn(ctx)
+		if err == nil || !isConnectionError(err) {
+			return conn, r.name, err
+		}
+	}
+
+	conn, err := c.db.Conn(ctx)
+	return conn, c.endpoint(), err
+}
+
+// isConnectionError reports whether the error means the server couldn't be reached
+// (as opposed to an error of the statement).
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr)
+}
+
+// closeReplicas closes pools of all replicas.
+func (c *Client) closeReplicas() {
+	for _, r := range c.replicas {
+		_ = r.db.Close()
+	}
+	c.replicas = nil
+}
@@ -7,11 +7,15 @@ import (
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
-var _ core.ResultStream = (*ResultStream)(nil)
+var (
+	_ core.ResultStream   = (*ResultStream)(nil)
+	_ core.FailableStream = (*ResultStream)(nil)
+)
 
 type ResultStream struct {
 	next    func() (core.Row, error)
 	hasNext func() bool
+	err     func() error
 	closes  []func()
 	meta    *core.Meta
 	header  core.Header
@@ -43,6 +47,14 @@ func (r *ResultStream) Next() (core.Row, error) {
 	return rows, nil
 }
 
+// Err returns the error that ended the stream early, if any.
+func (r *ResultStream) Err() error {
+	if r.err == nil {
+		return nil
+	}
+	return r.err()
+}
+
 func (r *ResultStream) Close() {
 	r.once.Do(func() {
 		for _, fn := range r.closes {
@@ -61,6 +73,7 @@ func (r *ResultStream) Close() {
 type ResultStreamBuilder struct {
 	next    func() (core.Row, error)
 	hasNext func() bool
+	err     func() error
 	header  core.Header
 	closes  []func()
 	meta    *core.Meta
@@ -81,6 +94,12 @@ func (b *ResultStreamBuilder) WithNextFunc(fn func() (core.Row, error), has func
 	return b
 }
 
+// WithErrFunc sets the function which reports the error that ended the stream early.
+func (b *ResultStreamBuilder) WithErrFunc(fn func() error) *ResultStreamBuilder {
+	b.err = fn
+	return b
+}
+
 func (b *ResultStreamBuilder) WithHeader(header core.Header) *ResultStreamBuilder {
 	b.header = header
 	return b
@@ -100,6 +119,7 @@ func (b *ResultStreamBuilder) Build() *ResultStream {
 	return &ResultStream{
 		next:    b.next,
 		hasNext: b.hasNext,
+		err:     b.err,
 		header:  b.header,
 		closes:  b.closes,
 		meta:    b.meta,
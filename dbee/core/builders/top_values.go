@@ -0,0 +1,62 @@
+package builders
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// QuoteStyle is the style of quoting sql identifiers.
+type QuoteStyle int
+
+const (
+	// QuoteDouble quotes identifiers with double quotes ("name")
+	QuoteDouble QuoteStyle = iota
+	// QuoteBacktick quotes identifiers with backticks (`name`)
+	QuoteBacktick
+	// QuoteBracket quotes identifiers with square brackets ([name])
+	QuoteBracket
+)
+
+// QuoteIdentifier quotes the identifier in the given style,
+// escaping the closing quote characters in the name.
+func QuoteIdentifier(name string, style QuoteStyle) string {
+	switch style {
+	case QuoteBacktick:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case QuoteBracket:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	case QuoteDouble:
+		fallthrough
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+}
+
+// TopValuesQuery returns a query which lists the n most common values of the column
+// with the number of their occurrences ("cnt"). NULLs are grouped as a value of their own.
+func TopValuesQuery(opts *core.TableOptions, column string, n int, style QuoteStyle, dialect core.LimitDialect) string {
+	table := QuoteIdentifier(opts.Table, style)
+	if opts.Schema != "" {
+		table = QuoteIdentifier(opts.Schema, style) + "." + table
+	}
+	return TopValuesQueryFrom(table, column, n, style, dialect)
+}
+
+// TopValuesQueryFrom is TopValuesQuery of a table reference which is already quoted
+// (e.g. with a database name).
+func TopValuesQueryFrom(table string, column string, n int, style QuoteStyle, dialect core.LimitDialect) string {
+	col := QuoteIdentifier(column, style)
+
+	switch dialect {
+	case core.LimitDialectTop:
+		return fmt.Sprintf("SELECT TOP %d %s, COUNT(*) AS cnt FROM %s GROUP BY %s ORDER BY cnt DESC", n, col, table, col)
+	case core.LimitDialectFetchFirst:
+		return fmt.Sprintf("SELECT %s, COUNT(*) AS cnt FROM %s GROUP BY %s ORDER BY cnt DESC FETCH FIRST %d ROWS ONLY", col, table, col, n)
+	case core.LimitDialectLimit:
+		fallthrough
+	default:
+		return fmt.Sprintf("SELECT %s, COUNT(*) AS cnt FROM %s GROUP BY %s ORDER BY cnt DESC LIMIT %d", col, table, col, n)
+	}
+}
@@ -0,0 +1,43 @@
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/builders"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(`"my ""col"""`, builders.QuoteIdentifier(`my "col"`, builders.QuoteDouble))
+	r.Equal("`my ``col```", builders.QuoteIdentifier("my `col`", builders.QuoteBacktick))
+	r.Equal("[my [col]]]", builders.QuoteIdentifier("my [col]", builders.QuoteBracket))
+}
+
+func TestTopValuesQuery(t *testing.T) {
+	r := require.New(t)
+
+	opts := &core.TableOptions{Schema: "public", Table: "users"}
+
+	r.Equal(
+		`SELECT "country", COUNT(*) AS cnt FROM "public"."users" GROUP BY "country" ORDER BY cnt DESC LIMIT 10`,
+		builders.TopValuesQuery(opts, "country", 10, builders.QuoteDouble, core.LimitDialectLimit),
+	)
+	r.Equal(
+		`SELECT TOP 5 [country], COUNT(*) AS cnt FROM [public].[users] GROUP BY [country] ORDER BY cnt DESC`,
+		builders.TopValuesQuery(opts, "country", 5, builders.QuoteBracket, core.LimitDialectTop),
+	)
+	r.Equal(
+		`SELECT "country", COUNT(*) AS cnt FROM "public"."users" GROUP BY "country" ORDER BY cnt DESC FETCH FIRST 3 ROWS ONLY`,
+		builders.TopValuesQuery(opts, "country", 3, builders.QuoteDouble, core.LimitDialectFetchFirst),
+	)
+
+	// no schema
+	r.Equal(
+		"SELECT `country`, COUNT(*) AS cnt FROM `users` GROUP BY `country` ORDER BY cnt DESC LIMIT 10",
+		builders.TopValuesQuery(&core.TableOptions{Table: "users"}, "country", 10, builders.QuoteBacktick, core.LimitDialectLimit),
+	)
+}
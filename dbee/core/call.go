@@ -23,6 +23,14 @@ type (
 		result     *Result
 		archive    *archive
 		cancelFunc func()
+		// result is kept only in memory
+		skipArchive bool
+		// non-fatal problems with the call (e.g. invalid directives)
+		warnings []string
+		// progress reported by the driver (nil if the driver doesn't report it)
+		progress *progressTracker
+		// paging state of paged queries (see Connection.ExecutePage)
+		page *callPage
 
 		// any error that might occur during execution
 		err  error
@@ -98,18 +106,21 @@ func (c *Call) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func newCallFromExecutor(executor func(context.Context) (ResultStream, error), query string, onEvent func(CallState, *Call)) *Call {
+func newCallFromExecutor(executor func(context.Context) (ResultStream, error), query string, result *Result, onEvent func(CallState, *Call), opts ...callOption) *Call {
 	id := CallID(uuid.New().String())
 	c := &Call{
 		id:    id,
 		query: query,
 		state: CallStateUnknown,
 
-		result:  new(Result),
+		result:  result,
 		archive: newArchive(id),
 
 		done: make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
 
 	eventsCh := make(chan CallState, 10)
 
@@ -163,13 +174,15 @@ func newCallFromExecutor(executor func(context.Context) (ResultStream, error), q
 		}
 
 		// archive the result
-		err = c.archive.setResult(c.result)
-		if err != nil {
-			c.timeTaken = time.Since(c.timestamp)
-			c.err = err
-			eventsCh <- CallStateArchiveFailed
-			close(c.done)
-			return
+		if !c.skipArchive {
+			err = c.archive.setResult(c.result)
+			if err != nil {
+				c.timeTaken = time.Since(c.timestamp)
+				c.err = err
+				eventsCh <- CallStateArchiveFailed
+				close(c.done)
+				return
+			}
 		}
 
 		c.timeTaken = time.Since(c.timestamp)
@@ -180,6 +193,38 @@ func newCallFromExecutor(executor func(context.Context) (ResultStream, error), q
 	return c
 }
 
+// callOption configures the call before it starts executing.
+type callOption func(*Call)
+
+// callWithoutArchive keeps the result only in memory, so it's not available
+// once it's discarded.
+func callWithoutArchive() callOption {
+	return func(c *Call) {
+		c.skipArchive = true
+	}
+}
+
+// callWithWarnings attaches the warnings to the call.
+func callWithWarnings(warnings []string) callOption {
+	return func(c *Call) {
+		c.warnings = warnings
+	}
+}
+
+// callWithProgress attaches the progress tracker (which is passed to the driver) to the call.
+func callWithProgress(progress *progressTracker) callOption {
+	return func(c *Call) {
+		c.progress = progress
+	}
+}
+
+// callWithPage attaches the paging state to the call.
+func callWithPage(page *callPage) callOption {
+	return func(c *Call) {
+		c.page = page
+	}
+}
+
 func (c *Call) GetID() CallID {
 	return c.id
 }
@@ -204,6 +249,42 @@ func (c *Call) Err() error {
 	return c.err
 }
 
+// GetWarnings returns non-fatal problems noticed when starting the call
+// (e.g. unknown directives) and, once the call is done, warnings reported
+// by the database (see Meta.Warnings).
+func (c *Call) GetWarnings() []string {
+	select {
+	case <-c.done:
+	default:
+		return c.warnings
+	}
+
+	meta := c.result.Meta()
+	if meta == nil || len(meta.Warnings) == 0 {
+		return c.warnings
+	}
+	return append(append([]string{}, c.warnings...), meta.Warnings...)
+}
+
+// GetProgress returns the progress of the query reported by the server so far.
+// It's safe to call while the result is retrieved. Returns nil if the driver
+// doesn't report progress or nothing was reported yet.
+func (c *Call) GetProgress() *Progress {
+	if c.progress == nil {
+		return nil
+	}
+	return c.progress.get()
+}
+
+// GetPage returns the page the call selects or nil if the call is not a page
+// of a paged query.
+func (c *Call) GetPage() *Page {
+	if c.page == nil {
+		return nil
+	}
+	return c.page.page
+}
+
 // Done returns a non-buffered channel that is closed when
 // call finishes.
 func (c *Call) Done() chan struct{} {
@@ -233,3 +314,29 @@ func (c *Call) GetResult() (*Result, error) {
 
 	return c.result, nil
 }
+
+// GetStats returns query statistics reported by the database (see Meta.Stats).
+// Nil is returned until the call is done.
+func (c *Call) GetStats() map[string]string {
+	select {
+	case <-c.done:
+	default:
+		return nil
+	}
+
+	if meta := c.result.Meta(); meta != nil {
+		return meta.Stats
+	}
+	return nil
+}
+
+// Close discards the cached result and removes its spill file (if any).
+// The archived result is kept, so it can still be loaded with GetResult.
+// Results of unfinished calls are left alone.
+func (c *Call) Close() {
+	select {
+	case <-c.done:
+		c.result.Wipe()
+	default:
+	}
+}
@@ -104,7 +104,7 @@ func (a *archive) setResult(result *Result) error {
 
 	// rows
 	chunkSize := 500
-	length := len(result.rows)
+	length := result.Len()
 
 	// write chunks concurrently
 	g := &errgroup.Group{}
@@ -194,3 +194,24 @@ func TestCall_Archive(t *testing.T) {
 	r.NoError(err)
 	r.Equal(rows, actualRows)
 }
+
+func TestCall_DatabaseWarnings(t *testing.T) {
+	r := require.New(t)
+
+	connection, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 1),
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithMeta(&core.Meta{
+			Warnings: []string{"Warning 1265: Data truncated for column 'a' at row 1"},
+		})),
+	))
+	r.NoError(err)
+
+	call := connection.Execute("-- dbee: unknown\nselect 1", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+
+	// directive warnings come first, database warnings are added once the call is done
+	r.Equal([]string{
+		`unknown directive: "unknown"`,
+		"Warning 1265: Data truncated for column 'a' at row 1",
+	}, call.GetWarnings())
+}
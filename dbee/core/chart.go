@@ -0,0 +1,122 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ChartData is the result reduced to series of numbers, ready to be plotted.
+type ChartData struct {
+	// X holds values of the x column
+	X []any
+	// Series holds values of each y column (by column name),
+	// every series has the same length as X
+	Series map[string][]float64
+}
+
+// ChartData extracts the x column and numeric y columns from the result.
+// Columns are matched the same way as in SelectColumns. Values of y columns are
+// coerced to numbers (numeric strings included) and rows where the x value is NULL
+// or any of the y values isn't a number are dropped. Numeric x values are converted
+// to numbers, times are formatted as RFC3339 and other values are kept as they are.
+func (cr *Result) ChartData(x string, y []string) (*ChartData, error) {
+	if len(y) < 1 {
+		return nil, errors.New("no y columns specified")
+	}
+
+	xIdx := cr.columnIndex(x)
+	if xIdx < 0 {
+		return nil, fmt.Errorf("unknown x column: %q", x)
+	}
+
+	yIdx := make([]int, len(y))
+	for i, name := range y {
+		yIdx[i] = cr.columnIndex(name)
+		if yIdx[i] < 0 {
+			return nil, fmt.Errorf("unknown y column: %q", name)
+		}
+	}
+
+	rows, err := cr.Rows(0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &ChartData{
+		X:      []any{},
+		Series: make(map[string][]float64, len(y)),
+	}
+	for _, idx := range yIdx {
+		data.Series[cr.header[idx]] = []float64{}
+	}
+
+	// number of numeric values of each y column, used for validation
+	numeric := make([]int, len(yIdx))
+
+	values := make([]float64, len(yIdx))
+	for _, row := range rows {
+		if xIdx >= len(row) || isNull(row[xIdx]) {
+			continue
+		}
+
+		plottable := true
+		for i, idx := range yIdx {
+			var ok bool
+			if idx < len(row) {
+				values[i], ok = chartNumber(row[idx])
+			}
+			if ok {
+				numeric[i]++
+			} else {
+				plottable = false
+			}
+		}
+		if !plottable {
+			continue
+		}
+
+		data.X = append(data.X, chartValue(row[xIdx]))
+		for i, idx := range yIdx {
+			name := cr.header[idx]
+			data.Series[name] = append(data.Series[name], values[i])
+		}
+	}
+
+	if len(rows) > 0 {
+		for i, count := range numeric {
+			if count == 0 {
+				return nil, fmt.Errorf("column %q has no numeric values", cr.header[yIdx[i]])
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// chartNumber converts the value to a float, if it's numeric.
+func chartNumber(v any) (float64, bool) {
+	n, ok := sortNumber(v)
+	if !ok {
+		return 0, false
+	}
+	f, _ := n.Float64()
+	return f, true
+}
+
+// chartValue converts the x value to a number, unless it's a string (or time).
+func chartValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	}
+
+	if f, ok := chartNumber(v); ok {
+		return f
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestResult_ChartData(t *testing.T) {
+	r := require.New(t)
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	result := new(core.Result)
+	err := result.SetIter(mock.NewResultStream([]core.Row{
+		{day, 1, "1.5", "a"},
+		{"2024-01-03", int64(2), json.Number("2.5"), "b"},
+		{nil, 3, 3.5, "c"},            // no x value
+		{"2024-01-05", 4, "n/a", "d"}, // y value not numeric
+		{[]byte("2024-01-06"), uint8(5), nil, "e"},
+		{"2024-01-07", 6.25, 7, "f"},
+	}), nil)
+	r.NoError(err)
+
+	data, err := result.ChartData("header_0", []string{"header_1", "HEADER_2"})
+	r.NoError(err)
+	r.Equal(&core.ChartData{
+		X: []any{"2024-01-02T00:00:00Z", "2024-01-03", "2024-01-07"},
+		Series: map[string][]float64{
+			"header_1": {1, 2, 6.25},
+			"header_2": {1.5, 2.5, 7},
+		},
+	}, data)
+
+	// numeric x
+	data, err = result.ChartData("header_1", []string{"header_2"})
+	r.NoError(err)
+	r.Equal([]any{float64(1), float64(2), float64(3), 6.25}, data.X)
+
+	// validation
+	_, err = result.ChartData("missing", []string{"header_1"})
+	r.ErrorContains(err, "unknown x column")
+	_, err = result.ChartData("header_0", []string{"header_1", "missing"})
+	r.ErrorContains(err, "unknown y column")
+	_, err = result.ChartData("header_0", nil)
+	r.Error(err)
+	_, err = result.ChartData("header_1", []string{"header_3"})
+	r.ErrorContains(err, `column "header_3" has no numeric values`)
+}
@@ -0,0 +1,152 @@
+package core
+
+// StatementKind is the kind of a statement, as returned by ClassifyStatement.
+type StatementKind int
+
+const (
+	// StatementKindUnknown is a statement that couldn't be classified
+	// (or multiple statements of different kinds).
+	StatementKindUnknown StatementKind = iota
+	// StatementKindSelect reads rows (SELECT, VALUES, TABLE).
+	StatementKindSelect
+	// StatementKindInsert adds rows (INSERT, REPLACE, UPSERT).
+	StatementKindInsert
+	// StatementKindUpdate changes rows (UPDATE, MERGE).
+	StatementKindUpdate
+	// StatementKindDelete removes rows (DELETE).
+	StatementKindDelete
+	// StatementKindDDL changes the schema or privileges (CREATE, ALTER, DROP, TRUNCATE, GRANT, ...).
+	StatementKindDDL
+	// StatementKindTransaction controls transactions (BEGIN, COMMIT, ROLLBACK, ...).
+	StatementKindTransaction
+	// StatementKindUtility is any other command (SHOW, EXPLAIN, SET, CALL, VACUUM, ...).
+	StatementKindUtility
+)
+
+func (k StatementKind) String() string {
+	switch k {
+	case StatementKindSelect:
+		return "select"
+	case StatementKindInsert:
+		return "insert"
+	case StatementKindUpdate:
+		return "update"
+	case StatementKindDelete:
+		return "delete"
+	case StatementKindDDL:
+		return "ddl"
+	case StatementKindTransaction:
+		return "transaction"
+	case StatementKindUtility:
+		return "utility"
+	default:
+		return "unknown"
+	}
+}
+
+// statementKinds maps the leading keywords of statements to their kind.
+var statementKinds = func() map[string]StatementKind {
+	kinds := make(map[string]StatementKind)
+	add := func(kind StatementKind, keywords ...string) {
+		for _, k := range keywords {
+			kinds[k] = kind
+		}
+	}
+
+	add(StatementKindSelect, "SELECT", "VALUES", "TABLE")
+	add(StatementKindInsert, "INSERT", "REPLACE", "UPSERT")
+	add(StatementKindUpdate, "UPDATE", "MERGE")
+	add(StatementKindDelete, "DELETE")
+	add(StatementKindDDL, "CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME", "COMMENT", "GRANT", "REVOKE")
+	add(StatementKindTransaction, "BEGIN", "START", "COMMIT", "ROLLBACK", "SAVEPOINT", "RELEASE", "END", "ABORT")
+	add(StatementKindUtility,
+		"SHOW", "EXPLAIN", "DESCRIBE", "DESC", "SET", "RESET", "USE", "CALL", "EXEC", "EXECUTE", "DO",
+		"ANALYZE", "VACUUM", "REINDEX", "CLUSTER", "CHECKPOINT", "COPY", "LOAD", "LOCK", "UNLOCK", "PRAGMA",
+		"PREPARE", "DEALLOCATE", "DECLARE", "FETCH", "CLOSE", "LISTEN", "UNLISTEN", "NOTIFY", "DISCARD",
+		"OPTIMIZE", "FLUSH", "KILL", "ATTACH", "DETACH",
+	)
+
+	return kinds
+}()
+
+// cteCommands are the commands which can follow the CTEs of a WITH clause.
+var cteCommands = toSet("SELECT", "VALUES", "TABLE", "INSERT", "UPDATE", "DELETE", "MERGE")
+
+// ClassifyStatement returns the kind of the statements of the query. Comments are
+// skipped and statements starting with a CTE are classified by the command after it
+// ("WITH ... DELETE FROM ..." is a delete).
+// If the query contains multiple statements of different kinds, it's unknown,
+// but transaction control around statements of a single kind is ignored
+// ("BEGIN; UPDATE ...; COMMIT;" is an update).
+// Queries that can't be tokenized (e.g. unterminated strings) are unknown.
+func ClassifyStatement(query string) StatementKind {
+	tokens, _, ok := topLevelTokens(query)
+	if !ok {
+		return StatementKindUnknown
+	}
+
+	kind := StatementKindUnknown
+	transaction := false
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && tokens[i].value != ";" {
+			continue
+		}
+		stmt := tokens[start:i]
+		start = i + 1
+		if len(stmt) < 1 {
+			continue
+		}
+
+		switch k := statementKind(stmt); {
+		case k == StatementKindTransaction:
+			transaction = true
+		case kind == StatementKindUnknown:
+			kind = k
+			if k == StatementKindUnknown {
+				return StatementKindUnknown
+			}
+		case kind != k:
+			return StatementKindUnknown
+		}
+	}
+
+	if kind == StatementKindUnknown && transaction {
+		return StatementKindTransaction
+	}
+	return kind
+}
+
+// statementKind classifies a single statement by its top-level tokens.
+func statementKind(tokens []sqlToken) StatementKind {
+	if len(tokens) < 1 {
+		return StatementKindUnknown
+	}
+
+	command := tokens[0].value
+	if command == "WITH" {
+		// CTE bodies are not top-level, so the first command after WITH is the main one
+		command = ""
+		for _, tok := range tokens[1:] {
+			if inSet(cteCommands, tok.value) {
+				command = tok.value
+				break
+			}
+		}
+	}
+
+	// "SET TRANSACTION ..." and "SET SESSION CHARACTERISTICS AS TRANSACTION ..."
+	if command == "SET" {
+		for _, tok := range tokens[1:] {
+			if tok.value == "TRANSACTION" {
+				return StatementKindTransaction
+			}
+		}
+	}
+
+	kind, ok := statementKinds[command]
+	if !ok {
+		return StatementKindUnknown
+	}
+	return kind
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected StatementKind
+	}{
+		{"SELECT * FROM users", StatementKindSelect},
+		{"  -- active users\n/* report */ select * from users where active;", StatementKindSelect},
+		{"WITH active AS (SELECT * FROM users WHERE active) SELECT count(*) FROM active", StatementKindSelect},
+		{"WITH RECURSIVE t(n) AS (VALUES (1) UNION ALL SELECT n+1 FROM t) SELECT * FROM t", StatementKindSelect},
+		{"VALUES (1), (2)", StatementKindSelect},
+		{"SELECT 1; SELECT 2", StatementKindSelect},
+		{"INSERT INTO users (name) VALUES ('DELETE')", StatementKindInsert},
+		{"WITH src AS (SELECT * FROM staging) INSERT INTO users SELECT * FROM src", StatementKindInsert},
+		{"UPDATE users SET name = (SELECT 'x') WHERE id = 1", StatementKindUpdate},
+		{"MERGE INTO users u USING staging s ON u.id = s.id WHEN MATCHED THEN DELETE", StatementKindUpdate},
+		{"WITH old AS (SELECT id FROM users) DELETE FROM users WHERE id IN (SELECT id FROM old)", StatementKindDelete},
+		{"BEGIN; DELETE FROM users WHERE id = 1; COMMIT;", StatementKindDelete},
+		{"CREATE TABLE users (id int)", StatementKindDDL},
+		{"drop index users_idx", StatementKindDDL},
+		{"TRUNCATE users", StatementKindDDL},
+		{"GRANT SELECT ON users TO reader", StatementKindDDL},
+		{"BEGIN", StatementKindTransaction},
+		{"START TRANSACTION; COMMIT", StatementKindTransaction},
+		{"SET TRANSACTION ISOLATION LEVEL SERIALIZABLE", StatementKindTransaction},
+		{"SHOW TABLES", StatementKindUtility},
+		{"EXPLAIN ANALYZE DELETE FROM users", StatementKindUtility},
+		{"SET search_path TO public", StatementKindUtility},
+		{"VACUUM users", StatementKindUtility},
+		{"", StatementKindUnknown},
+		{"-- nothing to see here", StatementKindUnknown},
+		{"SELECT 1; DELETE FROM users", StatementKindUnknown},
+		{"SELECT 'unterminated", StatementKindUnknown},
+		{"FROBNICATE users", StatementKindUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			require.Equal(t, tc.expected, ClassifyStatement(tc.query))
+		})
+	}
+}
+
+func TestStatementKind_String(t *testing.T) {
+	require.Equal(t, "ddl", StatementKindDDL.String())
+	require.Equal(t, "unknown", StatementKind(100).String())
+}
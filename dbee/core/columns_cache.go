@@ -0,0 +1,215 @@
+package core
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// columnsCache keeps columns of tables for a limited time (see OptionColumnsCacheTTL),
+// so repeated lookups (e.g. completion of every table) don't query the database.
+type columnsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[columnsCacheKey]columnsCacheEntry
+	now     func() time.Time
+}
+
+type columnsCacheKey struct {
+	schema string
+	table  string
+}
+
+type columnsCacheEntry struct {
+	columns []*Column
+	expires time.Time
+}
+
+func newColumnsCache(ttl time.Duration) *columnsCache {
+	return &columnsCache{
+		ttl:     ttl,
+		entries: make(map[columnsCacheKey]columnsCacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (c *columnsCache) get(opts *TableOptions) ([]*Column, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := columnsCacheKey{schema: opts.Schema, table: opts.Table}
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.columns, true
+}
+
+func (c *columnsCache) put(opts *TableOptions, columns []*Column) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[columnsCacheKey{schema: opts.Schema, table: opts.Table}] = columnsCacheEntry{
+		columns: columns,
+		expires: c.now().Add(c.ttl),
+	}
+}
+
+// invalidate drops the entries of tables changed by DDL statements of the query.
+// Names without a schema match the table in all schemas and names are compared
+// case insensitively, so an entry is rather dropped than kept stale.
+func (c *columnsCache) invalidate(query string) {
+	tables, all := ddlTables(query)
+	if len(tables) < 1 && !all {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if all {
+		c.entries = make(map[columnsCacheKey]columnsCacheEntry)
+		return
+	}
+
+	for key := range c.entries {
+		for _, t := range tables {
+			if strings.EqualFold(key.table, t.table) && (t.schema == "" || strings.EqualFold(key.schema, t.schema)) {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// ddlTables returns the tables and views created, altered, renamed or dropped by the
+// statements of the query. all is true if a name of such statement can't be determined.
+func ddlTables(query string) (tables []columnsCacheKey, all bool) {
+	tokens, _, ok := topLevelTokens(query)
+	if !ok {
+		return nil, false
+	}
+
+	command := ""
+	for i, tok := range tokens {
+		switch tok.value {
+		case ";":
+			command = ""
+			continue
+		case "ALTER", "DROP", "CREATE", "RENAME":
+			if command == "" {
+				command = tok.value
+			}
+			continue
+		case "TABLE", "VIEW":
+			// e.g. "CREATE OR REPLACE VIEW", "DROP TABLE IF EXISTS", but not "CREATE INDEX ... TABLE"
+			if command == "" || (i > 0 && tokens[i-1].value == "INDEX") {
+				continue
+			}
+		default:
+			if command == "" {
+				// other statements (e.g. "SELECT ... FOR UPDATE") are ignored until the next one
+				command = "-"
+			}
+			continue
+		}
+		if command == "-" {
+			continue
+		}
+
+		names, ok := ddlTableNames(query[tok.end:])
+		if !ok {
+			return nil, true
+		}
+		tables = append(tables, names...)
+		command = "-"
+	}
+
+	return tables, false
+}
+
+// ddlTableNames parses the comma separated list of names at the start of the statement
+// rest (e.g. "IF EXISTS a, s.b" or "a TO b, c TO d" of RENAME).
+func ddlTableNames(rest string) ([]columnsCacheKey, bool) {
+	var names []columnsCacheKey
+
+	rest = strings.TrimSpace(rest)
+	for _, prefix := range []string{"IF NOT EXISTS", "IF EXISTS", "ONLY"} {
+		if len(rest) > len(prefix) && strings.EqualFold(rest[:len(prefix)], prefix) && !isWordChar(rest[len(prefix)]) {
+			rest = strings.TrimSpace(rest[len(prefix):])
+		}
+	}
+
+	for {
+		parts, n := parseQualifiedName(rest)
+		if len(parts) < 1 {
+			return nil, false
+		}
+		key := columnsCacheKey{table: parts[len(parts)-1]}
+		if len(parts) > 1 {
+			key.schema = parts[len(parts)-2]
+		}
+		names = append(names, key)
+		rest = strings.TrimSpace(rest[n:])
+
+		// new name of "RENAME TABLE a TO b"
+		if len(rest) > 2 && strings.EqualFold(rest[:2], "TO") && !isWordChar(rest[2]) {
+			rest = strings.TrimSpace(rest[2:])
+			parts, n := parseQualifiedName(rest)
+			if len(parts) < 1 {
+				return nil, false
+			}
+			rest = strings.TrimSpace(rest[n:])
+		}
+
+		if !strings.HasPrefix(rest, ",") {
+			return names, true
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+}
+
+// parseQualifiedName parses a dot separated name (e.g. `s.t`, `"s"."t"`, "[s].[t]")
+// at the start of the string and returns its unquoted parts and length.
+func parseQualifiedName(s string) ([]string, int) {
+	var parts []string
+	i := 0
+	for {
+		if i >= len(s) {
+			return parts, i
+		}
+
+		switch ch := s[i]; {
+		case ch == '"' || ch == '`' || ch == '[':
+			closing := ch
+			if ch == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(s[i+1:], closing)
+			if end < 0 {
+				return nil, 0
+			}
+			parts = append(parts, s[i+1:i+1+end])
+			i += end + 2
+		case isWordChar(ch):
+			j := i
+			for j < len(s) && isWordChar(s[j]) {
+				j++
+			}
+			parts = append(parts, s[i:j])
+			i = j
+		default:
+			return nil, 0
+		}
+
+		if i >= len(s) || s[i] != '.' {
+			return parts, i
+		}
+		i++
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDLTables(t *testing.T) {
+	type testCase struct {
+		query    string
+		expected []columnsCacheKey
+		all      bool
+	}
+
+	testCases := []testCase{
+		{
+			query:    "ALTER TABLE users ADD COLUMN age int",
+			expected: []columnsCacheKey{{table: "users"}},
+		},
+		{
+			query:    `alter table if exists only public."Users" drop column age`,
+			expected: []columnsCacheKey{{schema: "public", table: "Users"}},
+		},
+		{
+			query:    "DROP TABLE IF EXISTS a, [dbo].[b]; SELECT * FROM c",
+			expected: []columnsCacheKey{{table: "a"}, {schema: "dbo", table: "b"}},
+		},
+		{
+			query:    "RENAME TABLE a TO b, `s`.`c` TO d",
+			expected: []columnsCacheKey{{table: "a"}, {schema: "s", table: "c"}},
+		},
+		{
+			query:    "-- new view\nCREATE OR REPLACE VIEW s.v AS SELECT 1",
+			expected: []columnsCacheKey{{schema: "s", table: "v"}},
+		},
+		{
+			query: "SELECT * FROM t FOR UPDATE; CREATE INDEX i ON t (a); TRUNCATE TABLE t; COMMENT ON TABLE t IS 'x'",
+		},
+		{
+			query: "ALTER TABLE (SELECT 1)",
+			all:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tables, all := ddlTables(tc.query)
+		require.Equal(t, tc.expected, tables, tc.query)
+		require.Equal(t, tc.all, all, tc.query)
+	}
+}
+
+func TestColumnsCache(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	cache := newColumnsCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	users := &TableOptions{Schema: "public", Table: "users"}
+	orders := &TableOptions{Schema: "shop", Table: "orders"}
+	columns := []*Column{{Name: "id", Type: "int"}}
+
+	cache.put(users, columns)
+	cache.put(orders, columns)
+
+	cols, ok := cache.get(users)
+	r.True(ok)
+	r.Equal(columns, cols)
+
+	_, ok = cache.get(&TableOptions{Schema: "other", Table: "users"})
+	r.False(ok)
+
+	// unrelated and non-ddl statements keep entries
+	cache.invalidate("ALTER TABLE other.users ADD x int; UPDATE users SET a = 1")
+	_, ok = cache.get(users)
+	r.True(ok)
+
+	cache.invalidate("alter table USERS add x int")
+	_, ok = cache.get(users)
+	r.False(ok)
+	_, ok = cache.get(orders)
+	r.True(ok)
+
+	// expired
+	now = now.Add(time.Minute)
+	_, ok = cache.get(orders)
+	r.False(ok)
+}
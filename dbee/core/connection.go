@@ -5,18 +5,48 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-var ErrDatabaseSwitchingNotSupported = errors.New("database switching not supported")
+var (
+	ErrDatabaseSwitchingNotSupported = errors.New("database switching not supported")
+	ErrSchemaSwitchingNotSupported   = errors.New("schema switching not supported")
+	ErrBranchSwitchingNotSupported   = errors.New("branch switching not supported")
+	ErrDDLNotSupported               = errors.New("ddl extraction not supported")
+	ErrForeignKeysNotSupported       = errors.New("foreign key listing not supported")
+	ErrDescribeNotSupported          = errors.New("describing objects not supported")
+	ErrProceduresNotSupported        = errors.New("calling procedures not supported")
+	ErrExplainNotSupported           = errors.New("explaining queries not supported")
+	ErrPlanNotSupported              = errors.New("query plan trees not supported")
+	ErrSessionParamsNotSupported     = errors.New("setting session parameters not supported")
+	ErrPeekNotSupported              = errors.New("peeking result columns not supported")
+	ErrListenNotSupported            = errors.New("listening for notifications not supported")
+	ErrTopValuesNotSupported         = errors.New("listing top values not supported")
+	ErrImportNotSupported            = errors.New("importing tables not supported")
+	ErrQueryParamsNotSupported       = errors.New("binding query parameters not supported")
+	ErrTableSizeNotSupported         = errors.New("table sizes not supported")
+	ErrIndexAdviceNotSupported       = errors.New("index suggestions not supported")
+	ErrSequencesNotSupported         = errors.New("listing sequences not supported")
+	ErrGrantsNotSupported            = errors.New("listing grants not supported")
+	ErrPagingNotSupported            = errors.New("paging queries not supported")
+	ErrCopyNotSupported              = errors.New("bulk copying not supported")
+	ErrSafeScanNotSupported          = errors.New("safe scan not supported")
+)
 
 // TableOptions contain options for gathering information about specific table.
 type TableOptions struct {
 	Table           string
 	Schema          string
 	Materialization StructureType
+	// Parent table of a partition (empty otherwise)
+	Parent string
 }
 
 type (
@@ -28,6 +58,12 @@ type (
 		GetHelpers(opts *TableOptions) map[string]string
 	}
 
+	// Validator is an optional interface for adapters that can check the url
+	// (format and required parameters) without opening a connection.
+	Validator interface {
+		Validate(url string) error
+	}
+
 	// Driver is an interface for a specific database driver.
 	Driver interface {
 		Query(ctx context.Context, query string) (ResultStream, error)
@@ -36,11 +72,294 @@ type (
 		Close()
 	}
 
+	// ContextStructurer is an optional interface for drivers that can stop loading
+	// the structure once the context is cancelled.
+	ContextStructurer interface {
+		StructureCtx(ctx context.Context) ([]*Structure, error)
+	}
+
 	// DatabaseSwitcher is an optional interface for drivers that have database switching capabilities.
 	DatabaseSwitcher interface {
 		SelectDatabase(string) error
 		ListDatabases() (current string, available []string, err error)
 	}
+
+	// SchemaSwitcher is an optional interface for drivers that can switch the default schema
+	// (the one unqualified names resolve to) within the current database.
+	SchemaSwitcher interface {
+		SelectSchema(string) error
+		ListSchemas() (current string, available []string, err error)
+	}
+
+	// BranchSwitcher is an optional interface for drivers of versioned databases (e.g. dolt),
+	// which can check out a branch of the current database.
+	BranchSwitcher interface {
+		SelectBranch(string) error
+		ListBranches() (current string, available []string, err error)
+	}
+
+	// DDLProvider is an optional interface for drivers that can return
+	// the definition (CREATE statement) of a table or view.
+	DDLProvider interface {
+		DDL(opts *TableOptions) (string, error)
+	}
+
+	// SchemaDDLProvider is an optional interface for drivers that can split
+	// definitions, so that a whole schema can be restored in order.
+	SchemaDDLProvider interface {
+		DDLProvider
+		// SchemaPrelude returns statements that tables depend on (schemas, types, sequences).
+		SchemaPrelude() ([]string, error)
+		TableDefinition(opts *TableOptions) (*TableDefinition, error)
+		// ViewDependencies returns views the provided view selects from.
+		ViewDependencies(opts *TableOptions) ([]*TableOptions, error)
+	}
+
+	// ForeignKeyLister is an optional interface for drivers that can list
+	// foreign keys of a table.
+	ForeignKeyLister interface {
+		ForeignKeys(opts *TableOptions) ([]*ForeignKey, error)
+	}
+
+	// TableSizer is an optional interface for drivers that can report
+	// the storage size of a table.
+	TableSizer interface {
+		TableSize(opts *TableOptions) (*TableSize, error)
+	}
+
+	// SequenceLister is an optional interface for drivers that can list sequences
+	// (or auto increment counters) with their current values and limits.
+	SequenceLister interface {
+		Sequences() ([]*Sequence, error)
+	}
+
+	// GrantLister is an optional interface for drivers that can list privileges on a table.
+	// Grants lists privileges granted to all users and roles, while EffectiveGrants lists
+	// privileges the current user actually has (including those inherited from roles or
+	// granted on the whole schema, where the database can tell).
+	GrantLister interface {
+		Grants(opts *TableOptions) ([]*Grant, error)
+		EffectiveGrants(opts *TableOptions) ([]*Grant, error)
+	}
+
+	// UniqueKeyLister is an optional interface for drivers that can list unique keys of a table
+	// (the primary key and unique constraints or indexes), each as a list of its columns.
+	// Paged queries ordered by a unique key are paged by the key (see ExecutePage).
+	UniqueKeyLister interface {
+		UniqueKeys(opts *TableOptions) ([][]string, error)
+	}
+
+	// Describer is an optional interface for drivers that can describe any object
+	// of the structure. The description depends on the type (opts.Materialization) of object,
+	// e.g. columns for tables, definition for views or parameters and body for procedures.
+	Describer interface {
+		Describe(ctx context.Context, opts *TableOptions) (ResultStream, error)
+	}
+
+	// ProcedureCaller is an optional interface for drivers of databases with stored procedures
+	// and functions. Procedures (or functions, see opts.Materialization) are called with
+	// positional arguments for their input parameters.
+	ProcedureCaller interface {
+		ListProcedures() ([]*Structure, error)
+		ProcedureParameters(opts *TableOptions) ([]*Column, error)
+		CallProcedure(ctx context.Context, opts *TableOptions, args []any) (ResultStream, error)
+	}
+
+	// CostExplainer is an optional interface for drivers that can summarize
+	// the query plan. If analyze is true, the query is actually executed.
+	CostExplainer interface {
+		ExplainCost(ctx context.Context, query string, analyze bool) (*ExplainSummary, error)
+	}
+
+	// PlanExplainer is an optional interface for drivers that can parse the query plan
+	// into a tree of nodes. If analyze is true, the query is actually executed.
+	PlanExplainer interface {
+		ExplainPlan(ctx context.Context, query string, analyze bool) (*PlanNode, error)
+	}
+
+	// IndexAdvisor is an optional interface for drivers that can suggest indexes for a query
+	// based on its plan (e.g. filtered sequential scans of large tables). The result lists
+	// the suggestions, each with a reason and a CREATE INDEX statement.
+	IndexAdvisor interface {
+		SuggestIndexes(ctx context.Context, query string) (ResultStream, error)
+	}
+
+	// Peeker is an optional interface for drivers that can return result columns
+	// of a select statement without fetching any rows.
+	Peeker interface {
+		Peek(ctx context.Context, query string) ([]*Column, error)
+	}
+
+	// TopValuer is an optional interface for drivers that can build a query listing
+	// the n most common values of a column with their counts (NULL counts as a value).
+	TopValuer interface {
+		TopValues(opts *TableOptions, column string, n int) string
+	}
+
+	// TableImporter is an optional interface for drivers that can load a result into a table
+	// (replacing the existing one), e.g. to join results of different connections locally.
+	// Returns the number of imported rows.
+	TableImporter interface {
+		ImportTable(ctx context.Context, table string, rows ResultStream) (int, error)
+	}
+
+	// Notifier is an optional interface for drivers of databases with publish/subscribe
+	// notifications (e.g. LISTEN/NOTIFY of postgres). Listen uses a dedicated connection,
+	// which is closed (along with the returned channel) once ctx is cancelled.
+	Notifier interface {
+		Listen(ctx context.Context, channel string) (<-chan *Notification, error)
+	}
+
+	// CursorQuerier is an optional interface for drivers that can run select statements
+	// with a server-side cursor, which is fetched in batches of fetchSize rows (see OptionFetchSize).
+	CursorQuerier interface {
+		QueryCursor(ctx context.Context, query string, fetchSize int) (ResultStream, error)
+	}
+
+	// Copier is an optional interface for drivers that can bulk copy csv data, bypassing
+	// the scanning of rows to values (e.g. COPY of postgres). CopyOut writes rows of the
+	// query to w and CopyIn loads rows read from r into the table. Both return the number
+	// of copied rows.
+	Copier interface {
+		CopyOut(ctx context.Context, query string, w io.Writer, opts *CopyOptions) (int64, error)
+		CopyIn(ctx context.Context, table *TableOptions, r io.Reader, opts *CopyOptions) (int64, error)
+	}
+
+	// ProgressQuerier is an optional interface for drivers that report progress of running
+	// queries (see Call.GetProgress) and accept per-query server settings (see Directives.Settings).
+	// onProgress is called with increments of progress, possibly while the result is retrieved.
+	ProgressQuerier interface {
+		QueryProgress(ctx context.Context, query string, settings map[string]string, onProgress func(*Progress)) (ResultStream, error)
+	}
+
+	// ParamQuerier is an optional interface for drivers that can bind arguments to positional
+	// placeholders of the query (in the style the driver returns, see ExecuteParams).
+	ParamQuerier interface {
+		QueryParams(ctx context.Context, query string, args []any) (ResultStream, error)
+		PlaceholderStyle() PlaceholderStyle
+	}
+
+	// SessionParamSetter is an optional interface for drivers that can set session parameters
+	// (e.g. a query tag or time zone). Drivers keep the parameters and apply them again
+	// whenever the session is recreated (e.g. after switching databases).
+	SessionParamSetter interface {
+		SetSessionParam(ctx context.Context, name, value string) error
+	}
+
+	// Limiter is an optional interface for drivers that understand sql select statements.
+	// It returns the dialect used for automatically limiting the number of returned rows.
+	Limiter interface {
+		LimitDialect() LimitDialect
+	}
+
+	// SafeScanner is an optional interface for drivers that can fall back
+	// to scanning all values as strings (see OptionSafeScan).
+	SafeScanner interface {
+		SetSafeScan(enabled bool)
+	}
+
+	// IdleCloser is an optional interface for drivers with connection pools, which can
+	// close the pooled connections after a period of inactivity (see OptionIdleTimeout).
+	IdleCloser interface {
+		SetIdleTimeout(timeout time.Duration)
+	}
+
+	// StatementLogger is an optional interface for drivers that can log every executed
+	// statement (see OptionLogStatements).
+	StatementLogger interface {
+		SetStatementLog(fn func(statement string, elapsed time.Duration, err error))
+	}
+
+	// ReplicaRouter is an optional interface for drivers that can route read-only statements
+	// (see IsReadOnlyStatement) to read replicas of the database (see OptionReplicas).
+	ReplicaRouter interface {
+		AddReplica(url string) error
+	}
+
+	// GeometryRenderer is an optional interface for drivers that can render
+	// binary spatial values as text (see OptionRenderGeometry).
+	GeometryRenderer interface {
+		SetRenderGeometry(enabled bool)
+	}
+
+	// IdentifierFolder is an optional interface for drivers of databases that fold
+	// unquoted identifiers to lower or upper case (see OptionFoldIdentifiers).
+	IdentifierFolder interface {
+		IdentifierCase() IdentifierCase
+	}
+)
+
+// Connection options (keys of ConnectionParams.Options).
+const (
+	// OptionDefaultLimit limits the number of rows returned by simple select statements
+	// that don't already specify a limit. Only applied if the driver implements Limiter.
+	OptionDefaultLimit = "default_limit"
+	// OptionSafeScan is a fallback mode which scans every value as a string.
+	// Use it if some column types can't be displayed or stored.
+	// Connecting fails if the driver doesn't implement SafeScanner.
+	OptionSafeScan = "safe_scan"
+	// OptionRenderGeometry renders spatial values (e.g. PostGIS geometry and geography)
+	// as WKT instead of hex encoded WKB. Only applied if the driver implements GeometryRenderer.
+	OptionRenderGeometry = "render_geometry"
+	// OptionTimeFormat is the format of time values in the output: a preset
+	// ("rfc3339", "epoch", "date-only", "datetime") or a go time layout.
+	OptionTimeFormat = "time_format"
+	// OptionTimeZone is the zone time values are converted to in the output:
+	// "utc", "local", "server" (default, as reported by the database) or an IANA zone name.
+	OptionTimeZone = "time_zone"
+	// OptionBoolFormat is the format of boolean values in the output: a pair of values
+	// separated by a slash (e.g. "true/false", "t/f", "1/0" or "yes/no").
+	OptionBoolFormat = "bool_format"
+	// OptionMaskColumns is a comma separated list of sensitive columns whose values are masked
+	// in the output: column names or regular expressions enclosed in slashes (see ParseMaskPolicy).
+	OptionMaskColumns = "mask_columns"
+	// OptionMaskStyle is the style of masked values: "length" (default, preserves the length)
+	// or "fixed".
+	OptionMaskStyle = "mask_style"
+	// OptionSecretCommand is the command used by the "secret" template function.
+	// The name of the secret is appended to the command (e.g. "pass show").
+	OptionSecretCommand = "secret_command"
+	// OptionMaxMemoryRows is the number of rows of each result kept in memory.
+	// Additional rows are spilled to a temporary file. Zero (default) means no limit.
+	OptionMaxMemoryRows = "max_memory_rows"
+	// OptionIdleTimeout is the duration (e.g. "15m") after which the connections to the database
+	// are closed if no query was run. They are opened again on the next query.
+	// Only applied if the driver implements IdleCloser.
+	OptionIdleTimeout = "idle_timeout"
+	// OptionRequireWhere refuses to run DELETE and UPDATE statements without a WHERE clause
+	// (see ErrMissingWhere), unless the execution is forced.
+	OptionRequireWhere = "require_where"
+	// OptionFetchSize is the number of rows fetched from the server at once. Select statements
+	// are run with a server-side cursor, so large results are streamed with bounded memory.
+	// Zero (default) fetches results the usual way. Only applied if the driver implements CursorQuerier.
+	OptionFetchSize = "fetch_size"
+	// OptionFormatHistory stores queries in the call history pretty-printed with FormatSQL.
+	// The query sent to the database is not changed.
+	OptionFormatHistory = "format_history"
+	// OptionColumnsCacheTTL is the duration (e.g. "5m") for which columns of tables are cached.
+	// Entries of tables altered or dropped by executed queries are discarded right away.
+	// Zero (default) disables the cache.
+	OptionColumnsCacheTTL = "columns_cache_ttl"
+	// OptionLogStatements logs every statement executed by the driver and the time it took.
+	// Secrets (e.g. passwords) are masked with RedactStatement.
+	// Only applied if the driver implements StatementLogger.
+	OptionLogStatements = "log_statements"
+	// OptionReplicas is a comma separated list of urls of read replicas. Read-only statements
+	// are run on the replicas (round-robin) and other statements on the primary (the connection url).
+	// If a replica is unreachable, the statement is run on the primary.
+	// Only applied if the driver implements ReplicaRouter.
+	OptionReplicas = "replicas"
+	// OptionFoldIdentifiers displays names in the structure consistently across databases:
+	// names that don't need quoting in lower case and the others quoted (see IdentifierCase.Display).
+	// Names sent back (e.g. for columns or helpers) are converted to names stored in the catalog.
+	// Only applied if the driver implements IdentifierFolder.
+	OptionFoldIdentifiers = "fold_identifiers"
+	// OptionDatabase is the database (or catalog) selected right after connecting.
+	// Connecting fails if the driver doesn't implement DatabaseSwitcher or the database doesn't exist.
+	OptionDatabase = "database"
+	// OptionSchema is the default schema selected right after connecting (after OptionDatabase).
+	// Connecting fails if the driver doesn't implement SchemaSwitcher or the schema doesn't exist.
+	OptionSchema = "schema"
 )
 
 type ConnectionID string
@@ -49,6 +368,18 @@ type Connection struct {
 	params           *ConnectionParams
 	unexpandedParams *ConnectionParams
 
+	defaultLimit  int
+	memoryRows    int
+	fetchSize     int
+	timeFormat    *TimeFormat
+	boolFormat    *BoolFormat
+	maskPolicy    atomic.Pointer[MaskPolicy]
+	identCase     IdentifierCase
+	requireWhere  bool
+	formatHistory bool
+	logStatements bool
+	columnsCache  *columnsCache
+
 	driver  Driver
 	adapter Adapter
 }
@@ -57,6 +388,23 @@ func (s *Connection) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.params)
 }
 
+// parseOption sets the field to the value of the option parsed with parse,
+// if the option is set.
+func parseOption[T any](field *T, name string, options map[string]string, parse func(string) (T, error)) error {
+	s, ok := options[name]
+	if !ok {
+		return nil
+	}
+
+	value, err := parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid value of option %q: %w", name, err)
+	}
+	*field = value
+
+	return nil
+}
+
 func NewConnection(params *ConnectionParams, adapter Adapter) (*Connection, error) {
 	expanded := params.Expand()
 
@@ -64,22 +412,198 @@ func NewConnection(params *ConnectionParams, adapter Adapter) (*Connection, erro
 		expanded.ID = ConnectionID(uuid.New().String())
 	}
 
-	driver, err := adapter.Connect(expanded.URL)
+	var (
+		defaultLimit    int
+		memoryRows      int
+		fetchSize       int
+		safeScan        bool
+		idleTimeout     time.Duration
+		renderGeometry  bool
+		requireWhere    bool
+		formatHistory   bool
+		logStatements   bool
+		foldIdentifiers bool
+		columnsCacheTTL time.Duration
+	)
+	err := errors.Join(
+		parseOption(&defaultLimit, OptionDefaultLimit, expanded.Options, strconv.Atoi),
+		parseOption(&memoryRows, OptionMaxMemoryRows, expanded.Options, strconv.Atoi),
+		parseOption(&fetchSize, OptionFetchSize, expanded.Options, strconv.Atoi),
+		parseOption(&safeScan, OptionSafeScan, expanded.Options, strconv.ParseBool),
+		parseOption(&idleTimeout, OptionIdleTimeout, expanded.Options, time.ParseDuration),
+		parseOption(&renderGeometry, OptionRenderGeometry, expanded.Options, strconv.ParseBool),
+		parseOption(&requireWhere, OptionRequireWhere, expanded.Options, strconv.ParseBool),
+		parseOption(&formatHistory, OptionFormatHistory, expanded.Options, strconv.ParseBool),
+		parseOption(&logStatements, OptionLogStatements, expanded.Options, strconv.ParseBool),
+		parseOption(&foldIdentifiers, OptionFoldIdentifiers, expanded.Options, strconv.ParseBool),
+		parseOption(&columnsCacheTTL, OptionColumnsCacheTTL, expanded.Options, time.ParseDuration),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	timeFormat, err := ParseTimeFormat(expanded.Options[OptionTimeFormat], expanded.Options[OptionTimeZone])
+	if err != nil {
+		option := OptionTimeFormat
+		if errors.Is(err, ErrInvalidTimeZone) {
+			option = OptionTimeZone
+		}
+		return nil, fmt.Errorf("invalid value of option %q: %w", option, err)
+	}
+
+	boolFormat, err := ParseBoolFormat(expanded.Options[OptionBoolFormat])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of option %q: %w", OptionBoolFormat, err)
+	}
+
+	var maskColumns []string
+	if columns := expanded.Options[OptionMaskColumns]; columns != "" {
+		maskColumns = strings.Split(columns, ",")
+	}
+	maskPolicy, err := ParseMaskPolicy(maskColumns, expanded.Options[OptionMaskStyle])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of option %q: %w", OptionMaskColumns, err)
+	}
+
+	// credentials are resolved only for connecting, so they don't end up in params
+	connectURL, err := resolveCredentials(expanded.URL)
+	if err != nil {
+		return nil, fmt.Errorf("resolveCredentials: %w", err)
+	}
+
+	driver, err := adapter.Connect(connectURL)
 	if err != nil {
 		return nil, fmt.Errorf("adapter.Connect: %w", err)
 	}
 
+	if safeScan {
+		scanner, ok := driver.(SafeScanner)
+		if !ok {
+			driver.Close()
+			return nil, fmt.Errorf("invalid value of option %q: %w", OptionSafeScan, ErrSafeScanNotSupported)
+		}
+		scanner.SetSafeScan(true)
+	}
+
+	if renderer, ok := driver.(GeometryRenderer); ok && renderGeometry {
+		renderer.SetRenderGeometry(true)
+	}
+
+	if router, ok := driver.(ReplicaRouter); ok {
+		for _, replica := range strings.Split(expanded.Options[OptionReplicas], ",") {
+			replica = strings.TrimSpace(replica)
+			if replica == "" {
+				continue
+			}
+
+			replicaURL, err := resolveCredentials(replica)
+			if err == nil {
+				err = router.AddReplica(replicaURL)
+			}
+			if err != nil {
+				driver.Close()
+				return nil, fmt.Errorf("invalid value of option %q: %w", OptionReplicas, err)
+			}
+		}
+	}
+
+	if closer, ok := driver.(IdleCloser); ok && idleTimeout > 0 {
+		closer.SetIdleTimeout(idleTimeout)
+	}
+
+	if err := selectInitial(driver, expanded.Options[OptionDatabase], expanded.Options[OptionSchema]); err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	identCase := IdentifierCaseSensitive
+	if folder, ok := driver.(IdentifierFolder); ok && foldIdentifiers {
+		identCase = folder.IdentifierCase()
+	}
+
 	c := &Connection{
 		params:           expanded,
 		unexpandedParams: params,
 
+		defaultLimit:  defaultLimit,
+		memoryRows:    memoryRows,
+		fetchSize:     fetchSize,
+		timeFormat:    timeFormat,
+		boolFormat:    boolFormat,
+		identCase:     identCase,
+		requireWhere:  requireWhere,
+		formatHistory: formatHistory,
+		logStatements: logStatements,
+
 		driver:  driver,
 		adapter: adapter,
 	}
+	c.maskPolicy.Store(maskPolicy)
+
+	if columnsCacheTTL > 0 {
+		c.columnsCache = newColumnsCache(columnsCacheTTL)
+	}
 
 	return c, nil
 }
 
+// selectInitial selects the database and schema of the connection options, if set.
+// Names are checked against the listed ones first, so a typo fails with a clear error
+// instead of e.g. an empty search path.
+func selectInitial(driver Driver, database, schema string) error {
+	if database != "" {
+		switcher, ok := driver.(DatabaseSwitcher)
+		if !ok {
+			return fmt.Errorf("invalid value of option %q: %w", OptionDatabase, ErrDatabaseSwitchingNotSupported)
+		}
+		err := selectExisting("database", database, switcher.ListDatabases, switcher.SelectDatabase)
+		if err != nil {
+			return fmt.Errorf("invalid value of option %q: %w", OptionDatabase, err)
+		}
+	}
+
+	if schema != "" {
+		switcher, ok := driver.(SchemaSwitcher)
+		if !ok {
+			return fmt.Errorf("invalid value of option %q: %w", OptionSchema, ErrSchemaSwitchingNotSupported)
+		}
+		err := selectExisting("schema", schema, switcher.ListSchemas, switcher.SelectSchema)
+		if err != nil {
+			return fmt.Errorf("invalid value of option %q: %w", OptionSchema, err)
+		}
+	}
+
+	return nil
+}
+
+// selectExisting selects the named object (e.g. database) unless it's already the current one.
+// It fails if the object isn't listed.
+func selectExisting(kind, name string, list func() (string, []string, error), sel func(string) error) error {
+	current, available, err := list()
+	if err != nil {
+		return fmt.Errorf("listing %ss: %w", kind, err)
+	}
+	if name == current {
+		return nil
+	}
+	if !slices.Contains(available, name) {
+		return fmt.Errorf("%s %q does not exist", kind, name)
+	}
+
+	if err := sel(name); err != nil {
+		return fmt.Errorf("selecting %s %q: %w", kind, name, err)
+	}
+	return nil
+}
+
+// SetStatementLog passes statements executed by the driver to fn if OptionLogStatements
+// is enabled and the driver implements StatementLogger.
+func (c *Connection) SetStatementLog(fn func(statement string, elapsed time.Duration, err error)) {
+	if logger, ok := c.driver.(StatementLogger); ok && c.logStatements {
+		logger.SetStatementLog(fn)
+	}
+}
+
 func (c *Connection) GetID() ConnectionID {
 	return c.params.ID
 }
@@ -101,15 +625,374 @@ func (c *Connection) GetParams() *ConnectionParams {
 	return c.unexpandedParams
 }
 
+// GetTimeFormat returns the format of time values in the output (nil if not configured).
+func (c *Connection) GetTimeFormat() *TimeFormat {
+	return c.timeFormat
+}
+
+// GetBoolFormat returns the format of boolean values in the output (nil if not configured).
+func (c *Connection) GetBoolFormat() *BoolFormat {
+	return c.boolFormat
+}
+
+// GetMaskPolicy returns the policy of masked columns in the output (nil if nothing is masked).
+func (c *Connection) GetMaskPolicy() *MaskPolicy {
+	return c.maskPolicy.Load()
+}
+
+// SetMask replaces the masked columns of the connection (see ParseMaskPolicy).
+// If style is empty, the style of OptionMaskStyle is used. No patterns turn masking off.
+func (c *Connection) SetMask(patterns []string, style string) error {
+	if style == "" && c.params != nil {
+		style = c.params.Options[OptionMaskStyle]
+	}
+
+	policy, err := ParseMaskPolicy(patterns, style)
+	if err != nil {
+		return err
+	}
+
+	c.maskPolicy.Store(policy)
+	return nil
+}
+
+// Execute starts executing the query. Instead of an inline query, a reference
+// to a file with the query can be passed ("file:///path" or "@/path").
 func (c *Connection) Execute(query string, onEvent func(CallState, *Call)) *Call {
-	exec := func(ctx context.Context) (ResultStream, error) {
+	return c.execute(query, onEvent, false)
+}
+
+// ExecuteForce is like Execute, but it runs statements refused by OptionRequireWhere.
+func (c *Connection) ExecuteForce(query string, onEvent func(CallState, *Call)) *Call {
+	return c.execute(query, onEvent, true)
+}
+
+func (c *Connection) execute(query string, onEvent func(CallState, *Call), force bool) *Call {
+	path, isFile := queryFilePath(query)
+
+	// directives are read from inline queries only
+	directives := &Directives{}
+	var opts []callOption
+	if !isFile {
+		var warnings []string
+		directives, warnings = ParseDirectives(query)
+		if _, ok := c.driver.(ProgressQuerier); !ok && len(directives.Settings) > 0 {
+			warnings = append(warnings, "query settings are not supported by the driver")
+		}
+		opts = append(opts, callWithWarnings(warnings))
+	}
+
+	progress := &progressTracker{}
+	if _, ok := c.driver.(ProgressQuerier); ok {
+		opts = append(opts, callWithProgress(progress))
+	}
+
+	limit := c.defaultLimit
+	if directives.Limit != nil {
+		limit = *directives.Limit
+	}
+	applied := 0
+	if !isFile {
+		query, applied = c.limitQuery(query, limit)
+	}
+
+	exec := func(ctx context.Context) (_ ResultStream, err error) {
+		query, applied := query, applied
+		// file is read when the call is executed, so the call keeps only the path
+		if isFile {
+			query, err = readQueryFile(path)
+			if err != nil {
+				return nil, err
+			}
+			query, applied = c.limitQuery(query, limit)
+		}
+
 		if strings.TrimSpace(query) == "" {
 			return nil, errors.New("empty query")
 		}
-		return c.driver.Query(ctx, query)
+		if c.requireWhere && !force {
+			if err := checkWhere(query); err != nil {
+				return nil, err
+			}
+		}
+
+		if c.columnsCache != nil {
+			defer c.columnsCache.invalidate(query)
+		}
+
+		run := func(ctx context.Context) (ResultStream, error) {
+			return c.query(ctx, query)
+		}
+		if querier, ok := c.driver.(ProgressQuerier); ok {
+			run = func(ctx context.Context) (ResultStream, error) {
+				return querier.QueryProgress(ctx, query, directives.Settings, progress.add)
+			}
+		}
+
+		var rows ResultStream
+		if directives.Timeout <= 0 {
+			rows, err = run(ctx)
+		} else {
+			rows, err = queryTimeout(ctx, directives.Timeout, run)
+		}
+		if err != nil || applied <= 0 {
+			return rows, err
+		}
+		return &limitStream{ResultStream: rows, limit: applied}, nil
+	}
+
+	logged := query
+	format := c.formatHistory
+	if directives.Format != nil {
+		format = *directives.Format
+	}
+	if format && !isFile {
+		logged = FormatSQL(query)
+	}
+
+	if directives.Cache != nil && !*directives.Cache {
+		opts = append(opts, callWithoutArchive())
+	}
+
+	return newCallFromExecutor(exec, logged, NewResult(c.memoryRows), onEvent, opts...)
+}
+
+// query runs the query with the driver. Select statements are run with a server-side
+// cursor if OptionFetchSize is set and the driver supports it.
+func (c *Connection) query(ctx context.Context, query string) (ResultStream, error) {
+	if querier, ok := c.driver.(CursorQuerier); ok && c.fetchSize > 0 {
+		if stmt, ok := selectStatement(query); ok {
+			return querier.QueryCursor(ctx, stmt, c.fetchSize)
+		}
+	}
+
+	return c.driver.Query(ctx, query)
+}
+
+// queryTimeout runs the query function, but it's canceled if executing and retrieving
+// the result takes longer than the timeout.
+func queryTimeout(ctx context.Context, timeout time.Duration, run func(context.Context) (ResultStream, error)) (ResultStream, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	rows, err := run(ctx)
+	if err != nil {
+		cancel()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("query timed out after %s: %w", timeout, err)
+		}
+		return nil, err
+	}
+
+	return &cancelStream{ResultStream: rows, cancel: cancel}, nil
+}
+
+// cancelStream cancels the context of the stream when it's closed.
+type cancelStream struct {
+	ResultStream
+	cancel context.CancelFunc
+}
+
+func (s *cancelStream) Close() {
+	s.ResultStream.Close()
+	s.cancel()
+}
+
+// limitStream reports the row limit added to the query in the metadata of the stream.
+type limitStream struct {
+	ResultStream
+	limit int
+}
+
+func (s *limitStream) Meta() *Meta {
+	// drivers fill stats of the same meta later, so it's not copied
+	meta := s.ResultStream.Meta()
+	if meta == nil {
+		meta = &Meta{}
+	}
+	meta.Limit = s.limit
+	return meta
+}
+
+// limitQuery is like applyLimit, but it also returns the limit if it was added
+// to the query (zero otherwise).
+func (c *Connection) limitQuery(query string, limit int) (string, int) {
+	limited := c.applyLimit(query, limit)
+	if limited == query {
+		return query, 0
+	}
+	return limited, limit
+}
+
+// applyLimit adds the limit to the query if it's positive and supported by the driver.
+func (c *Connection) applyLimit(query string, limit int) string {
+	limiter, ok := c.driver.(Limiter)
+	if !ok || limit <= 0 {
+		return query
+	}
+
+	return injectLimit(query, limit, limiter.LimitDialect())
+}
+
+// prepareSyncQuery reads the query from a file (if the query is a path)
+// and checks it before it's run synchronously.
+func (c *Connection) prepareSyncQuery(query string) (string, error) {
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return "", errors.New("empty query")
+	}
+
+	if c.requireWhere {
+		if err := checkWhere(query); err != nil {
+			return "", err
+		}
+	}
+
+	return query, nil
+}
+
+// ExecuteRecords runs the query synchronously and returns all rows as maps
+// of column names to values. Duplicate column names are handled as described in Header.Keys.
+func (c *Connection) ExecuteRecords(ctx context.Context, query string) ([]map[string]any, error) {
+	query, err := c.prepareSyncQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("c.query: %w", err)
+	}
+	defer rows.Close()
+
+	header := rows.Header()
+	keys := header.Keys()
+	mask := c.GetMaskPolicy()
+
+	records := []map[string]any{}
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
+
+		record := make(map[string]any, len(row))
+		for i, val := range row {
+			val = c.boolFormat.Apply(c.timeFormat.Apply(val))
+			if i < len(header) && mask.Matches(header[i]) {
+				val = mask.Mask(val)
+			}
+			if i < len(keys) {
+				record[keys[i]] = val
+			} else {
+				record[fmt.Sprintf("<unknown-field-%d>", i)] = val
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ExecutePage starts a call which selects the first page of pageSize rows of the select statement.
+// If the query orders the rows of a single table by a unique key (see UniqueKeyLister),
+// the following pages (see ExecuteNextPage) are selected by the key of the previous page's
+// last row (keyset pagination), otherwise they are selected by offset.
+func (c *Connection) ExecutePage(query string, pageSize int, onEvent func(CallState, *Call)) (*Call, error) {
+	limiter, ok := c.driver.(Limiter)
+	if !ok {
+		return nil, ErrPagingNotSupported
+	}
+
+	query, err := c.prepareSyncQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newPager(query, pageSize, limiter.LimitDialect())
+	if err != nil {
+		return nil, err
+	}
+
+	if keyset, ok := parseKeysetQuery(p.query); ok {
+		if lister, ok := c.driver.(UniqueKeyLister); ok {
+			// offset paging is used if the keys can't be listed
+			keys, err := lister.UniqueKeys(&TableOptions{Schema: keyset.schema, Table: keyset.table})
+			if err == nil && keyset.coversUniqueKey(keys) {
+				p.keyset = keyset
+			}
+		}
+	}
+
+	return c.executePage(p, 0, nil, onEvent), nil
+}
+
+// ExecuteNextPage starts a call which selects the page after the finished page of a paged query.
+// The page starts after the provided key values (one for each ordering column) or after the key
+// of the previous page's last row if after is nil. If the key is not known (e.g. the key
+// columns are not selected), the page is selected by offset.
+func (c *Connection) ExecuteNextPage(prev *Call, after []any, onEvent func(CallState, *Call)) (*Call, error) {
+	if prev == nil || prev.page == nil {
+		return nil, errors.New("call is not a page of a paged query")
+	}
+
+	select {
+	case <-prev.Done():
+	default:
+		return nil, errors.New("previous page is not done yet")
+	}
+	if err := prev.Err(); err != nil {
+		return nil, fmt.Errorf("previous page failed: %w", err)
+	}
+
+	p := prev.page.pager
+	if p.keyset == nil {
+		after = nil
+	}
+	if after == nil && p.keyset != nil {
+		result, err := prev.GetResult()
+		if err != nil {
+			return nil, fmt.Errorf("prev.GetResult: %w", err)
+		}
+		after = prev.page.lastKey(result)
+	}
+	if after != nil && len(after) != len(p.keyset.keys) {
+		return nil, fmt.Errorf("expected %d key values, got %d", len(p.keyset.keys), len(after))
+	}
+	for _, val := range after {
+		if val == nil {
+			after = nil
+			break
+		}
+	}
+
+	return c.executePage(p, prev.page.page.Number+1, after, onEvent), nil
+}
+
+func (c *Connection) executePage(p *pager, number int, after []any, onEvent func(CallState, *Call)) *Call {
+	page := &Page{
+		Number: number,
+		Size:   p.size,
+	}
+	if p.keyset != nil && (number == 0 || after != nil) {
+		page.Keyset = true
+		page.Keys = p.keyset.keys
+		page.After = after
+	}
+
+	query := p.pageQuery(number, after)
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		return c.query(ctx, query)
 	}
 
-	return newCallFromExecutor(exec, query, onEvent)
+	return newCallFromExecutor(exec, query, NewResult(c.memoryRows), onEvent, callWithPage(&callPage{pager: p, page: page}))
 }
 
 // SelectDatabase tries to switch to a given database with the used client.
@@ -128,6 +1011,91 @@ func (c *Connection) SelectDatabase(name string) error {
 	return nil
 }
 
+// SelectSchema switches the default schema of the current database.
+func (c *Connection) SelectSchema(name string) error {
+	switcher, ok := c.driver.(SchemaSwitcher)
+	if !ok {
+		return ErrSchemaSwitchingNotSupported
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return errors.New("empty schema name")
+	}
+
+	err := switcher.SelectSchema(name)
+	if err != nil {
+		return fmt.Errorf("switcher.SelectSchema: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connection) ListSchemas() (current string, available []string, err error) {
+	switcher, ok := c.driver.(SchemaSwitcher)
+	if !ok {
+		return "", nil, ErrSchemaSwitchingNotSupported
+	}
+
+	currentSchema, availableSchemas, err := switcher.ListSchemas()
+	if err != nil {
+		return "", nil, fmt.Errorf("switcher.ListSchemas: %w", err)
+	}
+
+	return currentSchema, availableSchemas, nil
+}
+
+// SelectBranch checks out the branch of the current database.
+func (c *Connection) SelectBranch(name string) error {
+	switcher, ok := c.driver.(BranchSwitcher)
+	if !ok {
+		return ErrBranchSwitchingNotSupported
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return errors.New("empty branch name")
+	}
+
+	err := switcher.SelectBranch(name)
+	if err != nil {
+		return fmt.Errorf("switcher.SelectBranch: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connection) ListBranches() (current string, available []string, err error) {
+	switcher, ok := c.driver.(BranchSwitcher)
+	if !ok {
+		return "", nil, ErrBranchSwitchingNotSupported
+	}
+
+	currentBranch, availableBranches, err := switcher.ListBranches()
+	if err != nil {
+		return "", nil, fmt.Errorf("switcher.ListBranches: %w", err)
+	}
+
+	return currentBranch, availableBranches, nil
+}
+
+// SetSessionParam sets the session parameter for the rest of the session.
+func (c *Connection) SetSessionParam(name, value string) error {
+	setter, ok := c.driver.(SessionParamSetter)
+	if !ok {
+		return ErrSessionParamsNotSupported
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return errors.New("empty session parameter name")
+	}
+
+	err := setter.SetSessionParam(context.Background(), name, value)
+	if err != nil {
+		return fmt.Errorf("setter.SetSessionParam: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Connection) ListDatabases() (current string, available []string, err error) {
 	switcher, ok := c.driver.(DatabaseSwitcher)
 	if !ok {
@@ -146,6 +1114,13 @@ func (c *Connection) GetColumns(opts *TableOptions) ([]*Column, error) {
 	if opts == nil {
 		return nil, fmt.Errorf("opts cannot be nil")
 	}
+	opts = c.identCase.catalogOptions(opts)
+
+	if c.columnsCache != nil {
+		if cols, ok := c.columnsCache.get(opts); ok {
+			return cols, nil
+		}
+	}
 
 	cols, err := c.driver.Columns(opts)
 	if err != nil {
@@ -155,15 +1130,333 @@ func (c *Connection) GetColumns(opts *TableOptions) ([]*Column, error) {
 		return nil, errors.New("no column names found for specified opts")
 	}
 
+	if c.columnsCache != nil {
+		c.columnsCache.put(opts, cols)
+	}
+
 	return cols, nil
 }
 
+// Describe returns a call with a description of the provided object.
+func (c *Connection) Describe(opts *TableOptions, onEvent func(CallState, *Call)) (*Call, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	describer, ok := c.driver.(Describer)
+	if !ok {
+		return nil, ErrDescribeNotSupported
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		return describer.Describe(ctx, opts)
+	}
+
+	query := fmt.Sprintf("-- describe %s %s", opts.Materialization, objectKey(opts.Schema, opts.Table))
+
+	return newCallFromExecutor(exec, query, NewResult(c.memoryRows), onEvent), nil
+}
+
+// SuggestIndexes returns a call with index suggestions for the query.
+// The query is only planned, not executed.
+func (c *Connection) SuggestIndexes(query string, onEvent func(CallState, *Call)) (*Call, error) {
+	advisor, ok := c.driver.(IndexAdvisor)
+	if !ok {
+		return nil, ErrIndexAdviceNotSupported
+	}
+
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty query")
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		return advisor.SuggestIndexes(ctx, query)
+	}
+
+	return newCallFromExecutor(exec, "-- suggest indexes\n"+query, NewResult(c.memoryRows), onEvent), nil
+}
+
+// TopValues starts a call which lists the n most common values of the column.
+func (c *Connection) TopValues(opts *TableOptions, column string, n int, onEvent func(CallState, *Call)) (*Call, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	valuer, ok := c.driver.(TopValuer)
+	if !ok {
+		return nil, ErrTopValuesNotSupported
+	}
+
+	if strings.TrimSpace(column) == "" {
+		return nil, errors.New("empty column name")
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of values: %d", n)
+	}
+
+	query := valuer.TopValues(opts, column, n)
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		return c.driver.Query(ctx, query)
+	}
+
+	return newCallFromExecutor(exec, query, NewResult(c.memoryRows), onEvent), nil
+}
+
+// ListProcedures returns the procedures and functions of the database.
+func (c *Connection) ListProcedures() ([]*Structure, error) {
+	caller, ok := c.driver.(ProcedureCaller)
+	if !ok {
+		return nil, ErrProceduresNotSupported
+	}
+
+	procs, err := caller.ListProcedures()
+	if err != nil {
+		return nil, fmt.Errorf("caller.ListProcedures: %w", err)
+	}
+
+	return c.identCase.displayStructure(procs), nil
+}
+
+// GetProcedureParameters returns the input parameters of the procedure in the order of arguments.
+func (c *Connection) GetProcedureParameters(opts *TableOptions) ([]*Column, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	caller, ok := c.driver.(ProcedureCaller)
+	if !ok {
+		return nil, ErrProceduresNotSupported
+	}
+
+	params, err := caller.ProcedureParameters(opts)
+	if err != nil {
+		return nil, fmt.Errorf("caller.ProcedureParameters: %w", err)
+	}
+
+	return params, nil
+}
+
+// CallProcedure returns a call of the procedure with provided arguments.
+func (c *Connection) CallProcedure(opts *TableOptions, args []any, onEvent func(CallState, *Call)) (*Call, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	caller, ok := c.driver.(ProcedureCaller)
+	if !ok {
+		return nil, ErrProceduresNotSupported
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		return caller.CallProcedure(ctx, opts, args)
+	}
+
+	strArgs := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			strArgs[i] = "NULL"
+			continue
+		}
+		strArgs[i] = fmt.Sprintf("%q", fmt.Sprint(arg))
+	}
+	query := fmt.Sprintf("-- call %s %s(%s)", opts.Materialization, objectKey(opts.Schema, opts.Table), strings.Join(strArgs, ", "))
+
+	return newCallFromExecutor(exec, query, NewResult(c.memoryRows), onEvent), nil
+}
+
+// ExplainCost returns the summary of the query plan (cost and row estimates).
+func (c *Connection) ExplainCost(ctx context.Context, query string, analyze bool) (*ExplainSummary, error) {
+	explainer, ok := c.driver.(CostExplainer)
+	if !ok {
+		return nil, ErrExplainNotSupported
+	}
+
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty query")
+	}
+
+	summary, err := explainer.ExplainCost(ctx, query, analyze)
+	if err != nil {
+		return nil, fmt.Errorf("explainer.ExplainCost: %w", err)
+	}
+
+	return summary, nil
+}
+
+// ExplainPlan returns the query plan as a tree of nodes.
+func (c *Connection) ExplainPlan(ctx context.Context, query string, analyze bool) (*PlanNode, error) {
+	explainer, ok := c.driver.(PlanExplainer)
+	if !ok {
+		return nil, ErrPlanNotSupported
+	}
+
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty query")
+	}
+
+	plan, err := explainer.ExplainPlan(ctx, query, analyze)
+	if err != nil {
+		return nil, fmt.Errorf("explainer.ExplainPlan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// Listen streams notifications sent to the channel until ctx is cancelled.
+func (c *Connection) Listen(ctx context.Context, channel string) (<-chan *Notification, error) {
+	notifier, ok := c.driver.(Notifier)
+	if !ok {
+		return nil, ErrListenNotSupported
+	}
+
+	if strings.TrimSpace(channel) == "" {
+		return nil, errors.New("empty channel name")
+	}
+
+	notifications, err := notifier.Listen(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("notifier.Listen: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// Peek returns the columns a select statement would return, without fetching any rows.
+func (c *Connection) Peek(ctx context.Context, query string) ([]*Column, error) {
+	peeker, ok := c.driver.(Peeker)
+	if !ok {
+		return nil, ErrPeekNotSupported
+	}
+
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query, ok = selectStatement(query)
+	if !ok {
+		return nil, errors.New("only single select statements can be peeked")
+	}
+
+	columns, err := peeker.Peek(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("peeker.Peek: %w", err)
+	}
+
+	return columns, nil
+}
+
+func (c *Connection) GetDDL(opts *TableOptions) (string, error) {
+	if opts == nil {
+		return "", fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	provider, ok := c.driver.(DDLProvider)
+	if !ok {
+		return "", ErrDDLNotSupported
+	}
+
+	ddl, err := provider.DDL(opts)
+	if err != nil {
+		return "", fmt.Errorf("provider.DDL: %w", err)
+	}
+
+	return ddl, nil
+}
+
+// GetGrants returns privileges on the table. If effective is set, only privileges
+// of the current user are returned.
+func (c *Connection) GetGrants(opts *TableOptions, effective bool) ([]*Grant, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	lister, ok := c.driver.(GrantLister)
+	if !ok {
+		return nil, ErrGrantsNotSupported
+	}
+
+	if effective {
+		grants, err := lister.EffectiveGrants(opts)
+		if err != nil {
+			return nil, fmt.Errorf("lister.EffectiveGrants: %w", err)
+		}
+		return grants, nil
+	}
+
+	grants, err := lister.Grants(opts)
+	if err != nil {
+		return nil, fmt.Errorf("lister.Grants: %w", err)
+	}
+	return grants, nil
+}
+
+func (c *Connection) GetForeignKeys(opts *TableOptions) ([]*ForeignKey, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	lister, ok := c.driver.(ForeignKeyLister)
+	if !ok {
+		return nil, ErrForeignKeysNotSupported
+	}
+
+	fks, err := lister.ForeignKeys(opts)
+	if err != nil {
+		return nil, fmt.Errorf("lister.ForeignKeys: %w", err)
+	}
+
+	return fks, nil
+}
+
 func (c *Connection) GetStructure() ([]*Structure, error) {
-	// structure
-	structure, err := c.driver.Structure()
+	return c.GetStructureCtx(context.Background())
+}
+
+// GetStructureCtx is like GetStructure, but returns ctx.Err() once ctx is cancelled.
+// Drivers that don't implement ContextStructurer keep loading in the background
+// and their result is discarded.
+func (c *Connection) GetStructureCtx(ctx context.Context) ([]*Structure, error) {
+	structure, err := c.structure(ctx)
 	if err != nil {
 		return nil, err
 	}
+	structure = c.identCase.displayStructure(structure)
 
 	// fallback to not confuse users
 	if len(structure) < 1 {
@@ -177,10 +1470,46 @@ func (c *Connection) GetStructure() ([]*Structure, error) {
 	return structure, nil
 }
 
+func (c *Connection) structure(ctx context.Context) ([]*Structure, error) {
+	if structurer, ok := c.driver.(ContextStructurer); ok {
+		structure, err := structurer.StructureCtx(ctx)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, err
+		}
+		return structure, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		structure []*Structure
+		err       error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		structure, err := c.driver.Structure()
+		done <- result{structure: structure, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		return res.structure, res.err
+	}
+}
+
 func (c *Connection) GetHelpers(opts *TableOptions) map[string]string {
 	if opts == nil {
 		opts = &TableOptions{}
 	}
+	opts = c.identCase.catalogOptions(opts)
 
 	helpers := c.adapter.GetHelpers(opts)
 	if helpers == nil {
@@ -7,28 +7,62 @@ type ConnectionParams struct {
 	Name string
 	Type string
 	URL  string
+	// Options holds optional per-connection settings (see Option* constants).
+	Options map[string]string
+	// Values are template values available in other fields as {{ .key }}.
+	Values map[string]string
 }
 
-// Expand returns a copy of the original parameters with expanded fields
+// Expand returns a copy of the original parameters with expanded fields.
+// Options are expanded first, then values (which can use the secret command
+// from options) and lastly the rest of the fields, which can reference values.
 func (p *ConnectionParams) Expand() *ConnectionParams {
+	var options map[string]string
+	if p.Options != nil {
+		options = make(map[string]string, len(p.Options))
+		for k, v := range p.Options {
+			options[k] = (&expander{}).expandOrDefault(v)
+		}
+	}
+
+	var values map[string]string
+	if p.Values != nil {
+		ex := &expander{secretCommand: options[OptionSecretCommand]}
+		values = make(map[string]string, len(p.Values))
+		for k, v := range p.Values {
+			values[k] = ex.expandOrDefault(v)
+		}
+	}
+
+	ex := &expander{
+		values:        values,
+		secretCommand: options[OptionSecretCommand],
+	}
+
 	return &ConnectionParams{
-		ID:   ConnectionID(expandOrDefault(string(p.ID))),
-		Name: expandOrDefault(p.Name),
-		Type: expandOrDefault(p.Type),
-		URL:  expandOrDefault(p.URL),
+		ID:      ConnectionID(ex.expandOrDefault(string(p.ID))),
+		Name:    ex.expandOrDefault(p.Name),
+		Type:    ex.expandOrDefault(p.Type),
+		URL:     ex.expandOrDefault(p.URL),
+		Options: options,
+		Values:  values,
 	}
 }
 
 func (cp *ConnectionParams) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-		Type string `json:"type"`
-		URL  string `json:"url"`
+		ID      string            `json:"id"`
+		Name    string            `json:"name"`
+		Type    string            `json:"type"`
+		URL     string            `json:"url"`
+		Options map[string]string `json:"options,omitempty"`
+		Values  map[string]string `json:"values,omitempty"`
 	}{
-		ID:   string(cp.ID),
-		Name: cp.Name,
-		Type: cp.Type,
-		URL:  cp.URL,
+		ID:      string(cp.ID),
+		Name:    cp.Name,
+		Type:    cp.Type,
+		URL:     cp.URL,
+		Options: cp.Options,
+		Values:  cp.Values,
 	})
 }
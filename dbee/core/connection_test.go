@@ -0,0 +1,263 @@
+package core_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_ExecuteRecords(t *testing.T) {
+	r := require.New(t)
+
+	rows := []core.Row{
+		{1, "alice", 10},
+		{2, "bob", 20},
+	}
+
+	adapter := mock.NewAdapter(rows,
+		mock.AdapterWithResultStreamOpts(
+			mock.ResultStreamWithHeader(core.Header{"id", "name", "id"}),
+		),
+	)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	records, err := c.ExecuteRecords(context.Background(), "select 1")
+	r.NoError(err)
+	r.Equal([]map[string]any{
+		{"id": 1, "name": "alice", "id_2": 10},
+		{"id": 2, "name": "bob", "id_2": 20},
+	}, records)
+
+	_, err = c.ExecuteRecords(context.Background(), "  ")
+	r.Error(err)
+}
+
+func TestConnection_GetStructureCtx(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter([]core.Row{{"a"}}))
+	r.NoError(err)
+
+	structure, err := c.GetStructureCtx(context.Background())
+	r.NoError(err)
+	r.NotEmpty(structure)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = c.GetStructureCtx(ctx)
+	r.ErrorIs(err, context.Canceled)
+}
+
+func TestConnection_DescribeNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	_, err = c.Describe(&core.TableOptions{Table: "t", Materialization: core.StructureTypeTable}, nil)
+	r.ErrorIs(err, core.ErrDescribeNotSupported)
+}
+
+func TestConnection_ProceduresNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	_, err = c.ListProcedures()
+	r.ErrorIs(err, core.ErrProceduresNotSupported)
+
+	_, err = c.CallProcedure(&core.TableOptions{Table: "p", Materialization: core.StructureTypeProcedure}, []any{1}, nil)
+	r.ErrorIs(err, core.ErrProceduresNotSupported)
+}
+
+func TestConnection_SessionParamsNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	err = c.SetSessionParam("QUERY_TAG", "dbee")
+	r.ErrorIs(err, core.ErrSessionParamsNotSupported)
+}
+
+func TestConnection_CopyNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	_, err = c.CopyOut(context.Background(), "SELECT 1", io.Discard, nil)
+	r.ErrorIs(err, core.ErrCopyNotSupported)
+
+	_, err = c.CopyIn(context.Background(), &core.TableOptions{Table: "t"}, strings.NewReader("1\n"), nil)
+	r.ErrorIs(err, core.ErrCopyNotSupported)
+
+	_, err = c.CopyIn(context.Background(), &core.TableOptions{}, strings.NewReader("1\n"), nil)
+	r.ErrorContains(err, "empty table name")
+}
+
+func TestConnection_ListenNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	_, err = c.Listen(context.Background(), "events")
+	r.ErrorIs(err, core.ErrListenNotSupported)
+}
+
+func TestConnection_GrantsNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	_, err = c.GetGrants(&core.TableOptions{Schema: "public", Table: "orders"}, true)
+	r.ErrorIs(err, core.ErrGrantsNotSupported)
+}
+
+func TestConnection_SchemaSwitchingNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(nil))
+	r.NoError(err)
+
+	err = c.SelectSchema("sales")
+	r.ErrorIs(err, core.ErrSchemaSwitchingNotSupported)
+
+	_, _, err = c.ListSchemas()
+	r.ErrorIs(err, core.ErrSchemaSwitchingNotSupported)
+}
+
+func TestNewConnection_SafeScanNotSupported(t *testing.T) {
+	r := require.New(t)
+
+	_, err := core.NewConnection(&core.ConnectionParams{
+		Options: map[string]string{core.OptionSafeScan: "true"},
+	}, mock.NewAdapter(nil))
+	r.ErrorIs(err, core.ErrSafeScanNotSupported)
+	r.ErrorContains(err, `invalid value of option "safe_scan"`)
+
+	_, err = core.NewConnection(&core.ConnectionParams{
+		Options: map[string]string{core.OptionSafeScan: "false"},
+	}, mock.NewAdapter(nil))
+	r.NoError(err)
+}
+
+func TestNewConnection_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]string
+		want    string
+	}{
+		{name: "int", options: map[string]string{core.OptionDefaultLimit: "many"}, want: `invalid value of option "default_limit"`},
+		{name: "bool", options: map[string]string{core.OptionRequireWhere: "maybe"}, want: `invalid value of option "require_where"`},
+		{name: "duration", options: map[string]string{core.OptionIdleTimeout: "soon"}, want: `invalid value of option "idle_timeout"`},
+		{name: "time format", options: map[string]string{core.OptionTimeFormat: "iso"}, want: `invalid value of option "time_format"`},
+		{name: "time zone", options: map[string]string{core.OptionTimeZone: "Not/AZone"}, want: `invalid value of option "time_zone"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := core.NewConnection(&core.ConnectionParams{Options: tt.options}, mock.NewAdapter(nil))
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestConnection_RequireWhere(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{
+		Options: map[string]string{core.OptionRequireWhere: "true"},
+	}, mock.NewAdapter([]core.Row{{1}}))
+	r.NoError(err)
+
+	call := c.Execute("DELETE FROM users", nil)
+	<-call.Done()
+	r.ErrorIs(call.Err(), core.ErrMissingWhere)
+
+	_, err = c.ExecuteRecords(context.Background(), "UPDATE users SET a = 1")
+	r.ErrorIs(err, core.ErrMissingWhere)
+
+	call = c.Execute("DELETE FROM users WHERE id = 1", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+
+	call = c.ExecuteForce("DELETE FROM users", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+}
+
+func TestConnection_FormatHistory(t *testing.T) {
+	r := require.New(t)
+
+	var executed string
+	adapter := mock.NewAdapter([]core.Row{{1}},
+		mock.AdapterWithQuerySideEffect("select a from t where b = 1", func(context.Context) error {
+			executed = "original"
+			return nil
+		}),
+	)
+
+	c, err := core.NewConnection(&core.ConnectionParams{
+		Options: map[string]string{core.OptionFormatHistory: "true"},
+	}, adapter)
+	r.NoError(err)
+
+	call := c.Execute("select a from t where b = 1", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+
+	// the database gets the original query, the history the formatted one
+	r.Equal("original", executed)
+	r.Equal("SELECT a\nFROM t\nWHERE b = 1", call.GetQuery())
+}
+
+func TestConnection_Directives(t *testing.T) {
+	r := require.New(t)
+
+	slow := "-- dbee: timeout=50ms\nselect pg_sleep(10)"
+	uncached := "-- dbee: cache=false format unknown\nselect a from t"
+	adapter := mock.NewAdapter([]core.Row{{1}},
+		mock.AdapterWithQuerySideEffect(slow, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+
+	call := c.Execute(slow, nil)
+	select {
+	case <-call.Done():
+	case <-time.After(5 * time.Second):
+		r.FailNow("call didn't time out")
+	}
+	r.ErrorContains(call.Err(), "query timed out after 50ms")
+
+	call = c.Execute(uncached, nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Equal([]string{`unknown directive: "unknown"`}, call.GetWarnings())
+	r.Equal("-- dbee: cache=false format unknown\nSELECT a\nFROM t", call.GetQuery())
+
+	// result is not archived, so it's gone once discarded
+	_, err = call.GetResult()
+	r.NoError(err)
+	call.Close()
+	_, err = call.GetResult()
+	r.Error(err)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CopyOptions describe the csv format of bulk copies (see Copier).
+type CopyOptions struct {
+	// Delimiter separates the fields of a row, defaults to ",".
+	Delimiter string
+	// Null is the representation of NULL values, defaults to an empty (unquoted) field.
+	Null string
+	// Header is set if the first line is the header.
+	Header bool
+}
+
+// DelimiterOrDefault returns the delimiter of the options or the default one.
+func (o *CopyOptions) DelimiterOrDefault() string {
+	if o == nil || o.Delimiter == "" {
+		return ","
+	}
+	return o.Delimiter
+}
+
+func (o *CopyOptions) validate() error {
+	if o == nil {
+		return nil
+	}
+
+	delimiter := o.DelimiterOrDefault()
+	if len(delimiter) != 1 || strings.ContainsAny(delimiter, "\"\r\n") {
+		return fmt.Errorf("invalid delimiter: %q (has to be a single character other than a quote or a newline)", o.Delimiter)
+	}
+	if strings.ContainsAny(o.Null, "\"\r\n"+delimiter) {
+		return fmt.Errorf("invalid null representation: %q (can't contain quotes, newlines or the delimiter)", o.Null)
+	}
+
+	return nil
+}
+
+// CopyOut runs the query and writes its rows as csv to w, skipping the formatting of
+// results, so huge results are exported quickly. Returns the number of copied rows.
+func (c *Connection) CopyOut(ctx context.Context, query string, w io.Writer, opts *CopyOptions) (int64, error) {
+	copier, ok := c.driver.(Copier)
+	if !ok {
+		return 0, ErrCopyNotSupported
+	}
+
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return 0, errors.New("empty query")
+	}
+
+	if c.requireWhere {
+		if err := checkWhere(query); err != nil {
+			return 0, err
+		}
+	}
+
+	count, err := copier.CopyOut(ctx, query, w, opts)
+	if err != nil {
+		return count, fmt.Errorf("copier.CopyOut: %w", err)
+	}
+
+	return count, nil
+}
+
+// CopyIn loads csv rows read from r into the existing table. Columns of the rows
+// have to be in the order of columns of the table. Returns the number of copied rows.
+func (c *Connection) CopyIn(ctx context.Context, table *TableOptions, r io.Reader, opts *CopyOptions) (int64, error) {
+	if table == nil || strings.TrimSpace(table.Table) == "" {
+		return 0, errors.New("empty table name")
+	}
+	table = c.identCase.catalogOptions(table)
+
+	copier, ok := c.driver.(Copier)
+	if !ok {
+		return 0, ErrCopyNotSupported
+	}
+
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	count, err := copier.CopyIn(ctx, table, r, opts)
+	if err != nil {
+		return 0, fmt.Errorf("copier.CopyIn: %w", err)
+	}
+
+	return count, nil
+}
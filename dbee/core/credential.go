@@ -0,0 +1,108 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// CredentialProvider resolves credential references in connection urls
+// (e.g. "vault://secret/db#password") to their values.
+type CredentialProvider interface {
+	// Resolve returns the value of the reference, which includes the scheme.
+	Resolve(ref string) (string, error)
+}
+
+var ErrNoValidCredentialScheme = errors.New("no valid credential provider scheme provided")
+
+// credentialProviders holds registered credential providers by their scheme.
+var credentialProviders = map[string]CredentialProvider{
+	"env":  envCredentialProvider{},
+	"exec": execCredentialProvider{},
+}
+
+// RegisterCredentialProvider registers a provider for references with the scheme
+// (e.g. "vault" for "vault://secret/db#password"). Existing providers are replaced.
+func RegisterCredentialProvider(scheme string, provider CredentialProvider) error {
+	if scheme == "" || strings.ContainsAny(scheme, ":/@&;= ") || provider == nil {
+		return ErrNoValidCredentialScheme
+	}
+
+	credentialProviders[scheme] = provider
+	return nil
+}
+
+// envCredentialProvider resolves "env://VAR" to the value of the environment variable.
+type envCredentialProvider struct{}
+
+func (envCredentialProvider) Resolve(ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "env://")
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set", name)
+	}
+	return value, nil
+}
+
+// execCredentialProvider resolves "exec://command" to the output of the command.
+type execCredentialProvider struct{}
+
+func (execCredentialProvider) Resolve(ref string) (string, error) {
+	return execCommand(strings.TrimPrefix(ref, "exec://"))
+}
+
+// resolveCredentials replaces credential references in the connection url with values
+// of registered providers. A reference is a password ("user:<ref>@host") or a value of
+// an option ("key=<ref>") and ends before the next "@", "&" or ";". Values are escaped
+// for their position in urls with a scheme and left as they are in other dsn formats.
+func resolveCredentials(rawURL string) (string, error) {
+	isURL := false
+	if scheme, _, ok := strings.Cut(rawURL, "://"); ok {
+		isURL = scheme != "" && !strings.ContainsAny(scheme, ":/@?=&;")
+	}
+	query := strings.IndexByte(rawURL, '?')
+
+	var out strings.Builder
+	last := 0
+	for i := 1; i < len(rawURL); i++ {
+		prev := rawURL[i-1]
+		if prev != ':' && prev != '=' {
+			continue
+		}
+
+		scheme, _, ok := strings.Cut(rawURL[i:], "://")
+		provider, registered := credentialProviders[scheme]
+		if !ok || !registered {
+			continue
+		}
+
+		end := strings.IndexAny(rawURL[i:], "@&;")
+		if end < 0 {
+			end = len(rawURL) - i
+		}
+		ref := rawURL[i : i+end]
+
+		value, err := provider.Resolve(ref)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", ref, err)
+		}
+
+		switch {
+		case isURL && prev == '=' && query >= 0 && i > query:
+			value = url.QueryEscape(value)
+		case isURL && prev == ':':
+			// escape as the password part of user info
+			value = strings.TrimPrefix(url.UserPassword("", value).String(), ":")
+		}
+
+		out.WriteString(rawURL[last:i])
+		out.WriteString(value)
+		last = i + end
+		i = last
+	}
+	out.WriteString(rawURL[last:])
+
+	return out.String(), nil
+}
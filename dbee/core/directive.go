@@ -0,0 +1,157 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// directivePrefix starts comments with directives.
+	directivePrefix = "dbee:"
+	// settingPrefix starts keys of server settings.
+	settingPrefix = "setting."
+)
+
+// settingNameRegex matches names of server settings, which are dot separated
+// identifiers (e.g. "async_insert" or "spark.sql.shuffle.partitions").
+var settingNameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*(?:\.[a-z_][a-z0-9_]*)*$`)
+
+// Directives override connection options for a single execution of a query.
+// They are given in leading comments of the query:
+//
+//	-- dbee: timeout=10s limit=1000 format cache=false
+//	SELECT * FROM orders
+//
+// Server settings of the query are given with a "setting." prefix:
+//
+//	-- dbee: setting.async_insert=1 setting.wait_for_async_insert=0
+//	INSERT INTO events VALUES (1, 'click')
+type Directives struct {
+	// Timeout cancels the call if it takes longer. Zero means no timeout.
+	Timeout time.Duration
+	// Limit overrides OptionDefaultLimit (zero disables the limit).
+	Limit *int
+	// Format overrides OptionFormatHistory.
+	Format *bool
+	// Cache stores the result in the call archive, so it can be loaded after the
+	// cached result is discarded (e.g. when reopening the call from history). Default is true.
+	Cache *bool
+	// Settings are server settings applied only to the query.
+	// Only applied if the driver implements ProgressQuerier.
+	Settings map[string]string
+}
+
+// ParseDirectives extracts directives from the leading comments of the query (comments
+// after the first statement are not considered). Directives are whitespace separated
+// "key=value" pairs, boolean keys can also be given without a value ("format" is the same
+// as "format=true"). Unknown keys and invalid values are skipped and reported as warnings.
+func ParseDirectives(query string) (*Directives, []string) {
+	d := &Directives{}
+	var warnings []string
+
+	for _, comment := range leadingComments(query) {
+		text := strings.TrimSpace(comment)
+		if len(text) < len(directivePrefix) || !strings.EqualFold(text[:len(directivePrefix)], directivePrefix) {
+			continue
+		}
+
+		for _, field := range strings.Fields(text[len(directivePrefix):]) {
+			key, value, hasValue := strings.Cut(field, "=")
+			if err := d.set(strings.ToLower(key), value, hasValue); err != nil {
+				warnings = append(warnings, err.Error())
+			}
+		}
+	}
+
+	return d, warnings
+}
+
+func (d *Directives) set(key, value string, hasValue bool) error {
+	parseBool := func() (*bool, error) {
+		if !hasValue {
+			b := true
+			return &b, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of directive %q: %q", key, value)
+		}
+		return &b, nil
+	}
+
+	if name, ok := strings.CutPrefix(key, settingPrefix); ok {
+		// names are passed to the server as they are, so they have to be plain identifiers
+		if !settingNameRegex.MatchString(name) || !hasValue {
+			return fmt.Errorf("invalid setting directive: %q", key)
+		}
+		if d.Settings == nil {
+			d.Settings = make(map[string]string)
+		}
+		d.Settings[name] = value
+		return nil
+	}
+
+	switch key {
+	case "timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("invalid value of directive %q: %q", key, value)
+		}
+		d.Timeout = timeout
+	case "limit":
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("invalid value of directive %q: %q", key, value)
+		}
+		d.Limit = &limit
+	case "format":
+		b, err := parseBool()
+		if err != nil {
+			return err
+		}
+		d.Format = b
+	case "cache":
+		b, err := parseBool()
+		if err != nil {
+			return err
+		}
+		d.Cache = b
+	default:
+		return fmt.Errorf("unknown directive: %q", key)
+	}
+
+	return nil
+}
+
+// leadingComments returns the text of comments (without comment markers)
+// before the first statement of the query.
+func leadingComments(query string) []string {
+	var comments []string
+
+	rest := query
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			comments = append(comments, rest[2:end])
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return comments
+			}
+			comments = append(comments, rest[2:end+2])
+			rest = rest[end+4:]
+		default:
+			return comments
+		}
+	}
+}
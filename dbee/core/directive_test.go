@@ -0,0 +1,111 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestParseDirectives(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	boolPtr := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name       string
+		query      string
+		directives *core.Directives
+		warnings   []string
+	}{
+		{
+			name:       "no directives",
+			query:      "-- list users\nSELECT * FROM users",
+			directives: &core.Directives{},
+		},
+		{
+			name:  "all keys",
+			query: "-- dbee: timeout=10s limit=1000 format cache=false\nSELECT * FROM users",
+			directives: &core.Directives{
+				Timeout: 10 * time.Second,
+				Limit:   intPtr(1000),
+				Format:  boolPtr(true),
+				Cache:   boolPtr(false),
+			},
+		},
+		{
+			name:  "multiple comments, case and spacing",
+			query: "  \n--DBEE:LIMIT=5\n-- some note\n/* dbee:  timeout=1m30s\n  format=false */\nSELECT 1",
+			directives: &core.Directives{
+				Timeout: 90 * time.Second,
+				Limit:   intPtr(5),
+				Format:  boolPtr(false),
+			},
+		},
+		{
+			name:       "later directives override earlier ones",
+			query:      "-- dbee: limit=5\n-- dbee: limit=0\nSELECT 1",
+			directives: &core.Directives{Limit: intPtr(0)},
+		},
+		{
+			name:       "comments after the statement start are ignored",
+			query:      "SELECT 1 -- dbee: limit=5\n/* dbee: timeout=1s */",
+			directives: &core.Directives{},
+		},
+		{
+			name:       "directives in strings are ignored",
+			query:      "SELECT '-- dbee: limit=5'",
+			directives: &core.Directives{},
+		},
+		{
+			name:       "unknown keys and invalid values warn",
+			query:      "-- dbee: readonly limit=-1 timeout=soon cache=maybe limit=3 =x\nSELECT 1",
+			directives: &core.Directives{Limit: intPtr(3)},
+			warnings: []string{
+				`unknown directive: "readonly"`,
+				`invalid value of directive "limit": "-1"`,
+				`invalid value of directive "timeout": "soon"`,
+				`invalid value of directive "cache": "maybe"`,
+				`unknown directive: ""`,
+			},
+		},
+		{
+			name:  "server settings",
+			query: "-- dbee: setting.async_insert=1 limit=0 setting.wait_for_async_insert=0 setting.x setting.=1 setting.a;b=1 setting.a..b=1\nINSERT INTO t VALUES (1)",
+			directives: &core.Directives{
+				Limit: intPtr(0),
+				Settings: map[string]string{
+					"async_insert":          "1",
+					"wait_for_async_insert": "0",
+				},
+			},
+			warnings: []string{
+				`invalid setting directive: "setting.x"`,
+				`invalid setting directive: "setting."`,
+				`invalid setting directive: "setting.a;b"`,
+				`invalid setting directive: "setting.a..b"`,
+			},
+		},
+		{
+			name:       "unterminated block comment",
+			query:      "-- dbee: limit=1\n/* dbee: limit=2",
+			directives: &core.Directives{Limit: intPtr(1)},
+		},
+		{
+			name:       "prefix only",
+			query:      "-- dbee:\n-- dbeex: limit=1\nSELECT 1",
+			directives: &core.Directives{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			directives, warnings := core.ParseDirectives(tt.query)
+			r.Equal(tt.directives, directives)
+			r.Equal(tt.warnings, warnings)
+		})
+	}
+}
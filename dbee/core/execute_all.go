@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// AllDatabasesColumn is the column of ExecuteAllDatabases results with the database of the row.
+	AllDatabasesColumn = "__database"
+	// AllDatabasesErrorColumn is the column of ExecuteAllDatabases results with the error
+	// of the database the query failed on.
+	AllDatabasesErrorColumn = "__error"
+)
+
+// ExecuteAllDatabases starts a call which runs the query in every available database
+// of the connection and unions the results. Rows are prefixed with the name of their
+// database and columns are matched by name (missing ones are NULL). If the query fails
+// in a database, a single row with the error is added instead. The current database is
+// selected again once the call finishes, meanwhile other calls of the connection also
+// run in the database being queried.
+func (c *Connection) ExecuteAllDatabases(query string, onEvent func(CallState, *Call)) (*Call, error) {
+	switcher, ok := c.driver.(DatabaseSwitcher)
+	if !ok {
+		return nil, ErrDatabaseSwitchingNotSupported
+	}
+
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty query")
+	}
+	if c.requireWhere {
+		if err := checkWhere(query); err != nil {
+			return nil, err
+		}
+	}
+
+	limited := c.applyLimit(query, c.defaultLimit)
+
+	exec := func(ctx context.Context) (_ ResultStream, err error) {
+		current, available, err := switcher.ListDatabases()
+		if err != nil {
+			return nil, fmt.Errorf("switcher.ListDatabases: %w", err)
+		}
+
+		defer func() {
+			if serr := switcher.SelectDatabase(current); serr != nil {
+				err = errors.Join(err, fmt.Errorf("switching back to %q: %w", current, serr))
+			}
+		}()
+
+		union := &unionResult{columns: make(map[string]int)}
+		for _, db := range available {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			if err := switcher.SelectDatabase(db); err != nil {
+				union.addError(db, err)
+				continue
+			}
+			if err := union.add(ctx, db, func(ctx context.Context) (ResultStream, error) {
+				return c.query(ctx, limited)
+			}); err != nil {
+				union.addError(db, err)
+			}
+		}
+
+		return union.stream(), nil
+	}
+
+	return newCallFromExecutor(exec, "-- all databases\n"+query, NewResult(c.memoryRows), onEvent), nil
+}
+
+// unionResult collects results of the same query from different databases.
+type unionResult struct {
+	header  Header
+	columns map[string]int // positions of columns in header
+	rows    []Row
+	failed  bool
+}
+
+// add reads the whole result, so the database can be switched afterwards.
+func (u *unionResult) add(ctx context.Context, db string, query func(context.Context) (ResultStream, error)) error {
+	result, err := query(ctx)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	positions := make([]int, len(result.Header()))
+	for i, key := range result.Header().Keys() {
+		pos, ok := u.columns[key]
+		if !ok {
+			pos = len(u.header)
+			u.columns[key] = pos
+			u.header = append(u.header, key)
+		}
+		positions[i] = pos
+	}
+
+	var rows []Row
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return err
+		}
+		rows = append(rows, u.row(db, nil, positions, row))
+	}
+
+	u.rows = append(u.rows, rows...)
+	return nil
+}
+
+func (u *unionResult) addError(db string, err error) {
+	u.failed = true
+	u.rows = append(u.rows, u.row(db, err, nil, nil))
+}
+
+// row stores database, error and values at their positions in the header. Rows are
+// padded to the full header once all results are collected.
+func (u *unionResult) row(db string, err error, positions []int, values Row) Row {
+	var errValue any
+	if err != nil {
+		errValue = err.Error()
+	}
+
+	row := Row{db, errValue}
+	for i, pos := range positions {
+		if i >= len(values) {
+			break
+		}
+		for len(row) < pos+3 {
+			row = append(row, nil)
+		}
+		row[pos+2] = values[i]
+	}
+	return row
+}
+
+func (u *unionResult) stream() ResultStream {
+	header := append(Header{AllDatabasesColumn, AllDatabasesErrorColumn}, u.header...)
+
+	rows := make([]Row, len(u.rows))
+	for i, row := range u.rows {
+		for len(row) < len(header) {
+			row = append(row, nil)
+		}
+		// error column is left out if all databases succeeded
+		if !u.failed {
+			row = append(Row{row[0]}, row[2:]...)
+		}
+		rows[i] = row
+	}
+	if !u.failed {
+		header = append(Header{AllDatabasesColumn}, u.header...)
+	}
+
+	return &rowsStream{header: header, rows: rows}
+}
+
+// rowsStream is a result stream of rows kept in memory.
+type rowsStream struct {
+	header Header
+	rows   []Row
+	next   int
+}
+
+func (s *rowsStream) Meta() *Meta {
+	return &Meta{SchemaType: SchemaFul}
+}
+
+func (s *rowsStream) Header() Header {
+	return s.header
+}
+
+func (s *rowsStream) Next() (Row, error) {
+	if s.next >= len(s.rows) {
+		return nil, errors.New("no next row")
+	}
+	row := s.rows[s.next]
+	s.next++
+	return row, nil
+}
+
+func (s *rowsStream) HasNext() bool {
+	return s.next < len(s.rows)
+}
+
+func (s *rowsStream) Close() {}
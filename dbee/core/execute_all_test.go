@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// switchingDriver returns results of the selected database.
+type switchingDriver struct {
+	current  string
+	results  map[string]*rowsStream
+	selected []string
+}
+
+func (d *switchingDriver) Query(_ context.Context, _ string) (ResultStream, error) {
+	result, ok := d.results[d.current]
+	if !ok {
+		return nil, errors.New("relation does not exist")
+	}
+	return &rowsStream{header: result.header, rows: result.rows}, nil
+}
+
+func (d *switchingDriver) Structure() ([]*Structure, error)           { return nil, nil }
+func (d *switchingDriver) Columns(_ *TableOptions) ([]*Column, error) { return nil, nil }
+func (d *switchingDriver) Close()                                     {}
+
+func (d *switchingDriver) SelectDatabase(name string) error {
+	d.selected = append(d.selected, name)
+	d.current = name
+	return nil
+}
+
+func (d *switchingDriver) ListDatabases() (string, []string, error) {
+	return d.current, []string{"a", "b", "c"}, nil
+}
+
+func TestConnection_ExecuteAllDatabases(t *testing.T) {
+	r := require.New(t)
+
+	driver := &switchingDriver{
+		current: "b",
+		results: map[string]*rowsStream{
+			"a": {header: Header{"id", "name"}, rows: []Row{{1, "x"}, {2, "y"}}},
+			"b": {header: Header{"name", "extra"}, rows: []Row{{"z", true}}},
+		},
+	}
+	c := &Connection{driver: driver}
+
+	call, err := c.ExecuteAllDatabases("SELECT * FROM users", nil)
+	r.NoError(err)
+	<-call.Done()
+	r.NoError(call.Err())
+
+	result, err := call.GetResult()
+	r.NoError(err)
+	r.Equal(Header{AllDatabasesColumn, AllDatabasesErrorColumn, "id", "name", "extra"}, result.Header())
+
+	rows, err := result.Rows(0, result.Len())
+	r.NoError(err)
+	r.Equal([]Row{
+		{"a", nil, 1, "x", nil},
+		{"a", nil, 2, "y", nil},
+		{"b", nil, nil, "z", true},
+		{"c", "relation does not exist", nil, nil, nil},
+	}, rows)
+
+	// original database is selected again
+	r.Equal([]string{"a", "b", "c", "b"}, driver.selected)
+
+	// error column is left out if the query succeeds everywhere
+	driver.results["c"] = &rowsStream{header: Header{"id"}}
+	call, err = c.ExecuteAllDatabases("SELECT * FROM users", nil)
+	r.NoError(err)
+	<-call.Done()
+	result, err = call.GetResult()
+	r.NoError(err)
+	r.Equal(Header{AllDatabasesColumn, "id", "name", "extra"}, result.Header())
+}
@@ -3,25 +3,46 @@ package core
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 	"text/template"
 )
 
+// expander expands the templates in connection parameters.
+type expander struct {
+	// values are available in templates as {{ .key }}
+	values map[string]string
+	// secretCommand is the command used by the "secret" function,
+	// the secret name is appended as the last argument (e.g. "pass show")
+	secretCommand string
+}
+
 func expand(value string) (string, error) {
-	tmpl, err := template.New("expand_variables").
+	return (&expander{}).expand(value)
+}
+
+func (e *expander) expand(value string) (string, error) {
+	tmpl := template.New("expand_variables").
 		Funcs(template.FuncMap{
-			"env":  os.Getenv,
-			"exec": execCommand,
-		}).
-		Parse(value)
+			"env":    os.Getenv,
+			"exec":   execCommand,
+			"secret": e.secret,
+		})
+
+	// missing values are errors only if values are used
+	if e.values != nil {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
+	tmpl, err := tmpl.Parse(value)
 	if err != nil {
 		return "", err
 	}
 
 	var out bytes.Buffer
-	err = tmpl.Execute(&out, nil)
+	err = tmpl.Execute(&out, e.values)
 	if err != nil {
 		return "", err
 	}
@@ -29,6 +50,22 @@ func expand(value string) (string, error) {
 	return out.String(), nil
 }
 
+// secret retrieves the secret with the configured secret command.
+func (e *expander) secret(name string) (string, error) {
+	if e.secretCommand == "" {
+		return "", fmt.Errorf("secret %q: no secret command configured (see %q option)", name, OptionSecretCommand)
+	}
+
+	out, err := execCommand(e.secretCommand + " " + name)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %w", name, err)
+	}
+
+	// only the first line is used (e.g. "pass" stores metadata on other lines)
+	secret, _, _ := strings.Cut(out, "\n")
+	return secret, nil
+}
+
 func execCommand(line string) (string, error) {
 	if strings.Contains(line, " | ") {
 		out, err := exec.Command("sh", "-c", line).Output()
@@ -47,8 +84,8 @@ func execCommand(line string) (string, error) {
 }
 
 // expandOrDefault silently suppresses errors.
-func expandOrDefault(value string) string {
-	ex, err := expand(value)
+func (e *expander) expandOrDefault(value string) string {
+	ex, err := e.expand(value)
 	if err != nil {
 		return value
 	}
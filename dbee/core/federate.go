@@ -0,0 +1,210 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FederatedTable is a table of another connection referenced by a federated query
+// as "<connection>.<schema>.<table>".
+type FederatedTable struct {
+	// Connection is the name of the connection (one of the names passed to ParseFederatedQuery).
+	Connection string
+	// Query selects all rows of the table on its connection.
+	Query string
+	// Alias is the name of the table the rows are loaded into.
+	Alias string
+}
+
+// ParseFederatedQuery finds references to tables of the connections in the query
+// ("<connection>.<schema>.<table>") and replaces them with aliases of the tables
+// (e.g. "pg.public.users" with "pg_public_users"). Each referenced table is returned once.
+// Unquoted connection names are matched case-insensitively (exact matches first), quoted ones
+// (e.g. "My Db".public.users) exactly. Schema and table parts are used in the queries
+// of the source connections as written (including quotes).
+// Strings and comments are skipped, so they are never rewritten.
+func ParseFederatedQuery(query string, connections []string) (string, []*FederatedTable, error) {
+	var (
+		out     strings.Builder
+		tables  []*FederatedTable
+		byRef   = make(map[string]*FederatedTable)
+		aliases = make(map[string]bool)
+	)
+
+	i := 0
+	for i < len(query) {
+		ch := query[i]
+
+		switch {
+		// line comment
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			out.WriteString(query[i : i+end])
+			i += end
+			continue
+		// block comment
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return "", nil, errors.New("unterminated comment")
+			}
+			out.WriteString(query[i : i+end+4])
+			i += end + 4
+			continue
+		// string literal
+		case ch == '\'':
+			end, ok := quotedEnd(query, i)
+			if !ok {
+				return "", nil, errors.New("unterminated string")
+			}
+			out.WriteString(query[i:end])
+			i = end
+			continue
+		case ch == '"' || ch == '`' || ch == '[' || isWordChar(ch):
+			parts, end, ok := identifierChain(query, i)
+			if !ok {
+				return "", nil, errors.New("unterminated quoted identifier")
+			}
+
+			// a chain of identifiers is only a reference if it's not a part of a longer one
+			conn, isRef := "", false
+			if len(parts) == 3 && (i == 0 || query[i-1] != '.') {
+				conn, isRef = matchConnection(parts[0], connections)
+			}
+			if !isRef {
+				out.WriteString(query[i:end])
+				i = end
+				continue
+			}
+
+			ref := conn + "." + parts[1] + "." + parts[2]
+			table, seen := byRef[ref]
+			if !seen {
+				table = &FederatedTable{
+					Connection: conn,
+					Query:      fmt.Sprintf("SELECT * FROM %s.%s", parts[1], parts[2]),
+					Alias:      federatedAlias(aliases, parts),
+				}
+				byRef[ref] = table
+				tables = append(tables, table)
+			}
+
+			out.WriteString(table.Alias)
+			i = end
+			continue
+		}
+
+		out.WriteByte(ch)
+		i++
+	}
+
+	return out.String(), tables, nil
+}
+
+// quotedEnd returns the position after the quoted string (or identifier) starting at i.
+func quotedEnd(query string, i int) (int, bool) {
+	closing := query[i]
+	if closing == '[' {
+		closing = ']'
+	}
+
+	j := i + 1
+	for {
+		end := strings.IndexByte(query[j:], closing)
+		if end < 0 {
+			return 0, false
+		}
+		j += end + 1
+		// doubled quote is an escaped quote
+		if closing != ']' && j < len(query) && query[j] == closing {
+			j++
+			continue
+		}
+		return j, true
+	}
+}
+
+// identifierChain reads identifiers separated by dots (e.g. a."b".c) starting at i.
+// Returns the parts as written and the position after the chain.
+func identifierChain(query string, i int) ([]string, int, bool) {
+	var parts []string
+	for {
+		start := i
+		if query[i] == '"' || query[i] == '`' || query[i] == '[' {
+			end, ok := quotedEnd(query, i)
+			if !ok {
+				return nil, 0, false
+			}
+			i = end
+		} else {
+			for i < len(query) && isWordChar(query[i]) {
+				i++
+			}
+		}
+		parts = append(parts, query[start:i])
+
+		if i+1 < len(query) && query[i] == '.' &&
+			(isWordChar(query[i+1]) || query[i+1] == '"' || query[i+1] == '`' || query[i+1] == '[') {
+			i++
+			continue
+		}
+		return parts, i, true
+	}
+}
+
+// matchConnection returns the name of the connection the identifier refers to.
+func matchConnection(ident string, connections []string) (string, bool) {
+	quoted := strings.HasPrefix(ident, `"`)
+	if quoted {
+		ident = strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+
+	for _, name := range connections {
+		if name == ident {
+			return name, true
+		}
+	}
+	if quoted {
+		return "", false
+	}
+	for _, name := range connections {
+		if strings.EqualFold(name, ident) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// federatedAlias returns a unique plain identifier made of the reference parts.
+func federatedAlias(used map[string]bool, parts []string) string {
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteByte('_')
+		}
+		for _, ch := range strings.ToLower(strings.Trim(part, "\"`[]")) {
+			if ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_' {
+				b.WriteRune(ch)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+	}
+
+	alias := b.String()
+	if alias[0] >= '0' && alias[0] <= '9' {
+		alias = "t_" + alias
+	}
+
+	unique := alias
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", alias, n)
+	}
+	used[unique] = true
+
+	return unique
+}
@@ -0,0 +1,54 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestParseFederatedQuery(t *testing.T) {
+	r := require.New(t)
+
+	connections := []string{"pg", "My Db", "Shop"}
+
+	query, tables, err := core.ParseFederatedQuery(`
+		-- pg.public.ignored
+		SELECT u.name, o.total, 'pg.public.users' AS source
+		FROM PG.public.users u
+		JOIN "My Db".sales."Order Items" o ON o.user_id = u.id
+		JOIN shop.main.users s ON s.id = u.id
+		WHERE u.id IN (SELECT id FROM pg.public.users)
+		AND x.pg.public.users IS NULL
+		AND other.public.users IS NULL`, connections)
+	r.NoError(err)
+	r.Equal(`
+		-- pg.public.ignored
+		SELECT u.name, o.total, 'pg.public.users' AS source
+		FROM pg_public_users u
+		JOIN my_db_sales_order_items o ON o.user_id = u.id
+		JOIN shop_main_users s ON s.id = u.id
+		WHERE u.id IN (SELECT id FROM pg_public_users)
+		AND x.pg.public.users IS NULL
+		AND other.public.users IS NULL`, query)
+
+	r.Equal([]*core.FederatedTable{
+		{Connection: "pg", Query: "SELECT * FROM public.users", Alias: "pg_public_users"},
+		{Connection: "My Db", Query: `SELECT * FROM sales."Order Items"`, Alias: "my_db_sales_order_items"},
+		{Connection: "Shop", Query: "SELECT * FROM main.users", Alias: "shop_main_users"},
+	}, tables)
+
+	// quoted names match exactly and aliases are unique
+	query, tables, err = core.ParseFederatedQuery(`SELECT * FROM "pg".a.b_c, "PG".x.y, pg.a_b.c`, connections)
+	r.NoError(err)
+	r.Equal(`SELECT * FROM pg_a_b_c, "PG".x.y, pg_a_b_c_2`, query)
+	r.Len(tables, 2)
+
+	_, tables, err = core.ParseFederatedQuery("SELECT 1", connections)
+	r.NoError(err)
+	r.Empty(tables)
+
+	_, _, err = core.ParseFederatedQuery("SELECT 'unterminated FROM pg.public.users", connections)
+	r.Error(err)
+}
@@ -4,16 +4,72 @@ import (
 	"bytes"
 	"encoding/csv"
 	"fmt"
+	"strings"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
 )
 
 var _ core.Formatter = (*CSV)(nil)
 
-type CSV struct{}
+type CSV struct {
+	comma rune
+	// header of the file the rows are appended to (see NewCSVAppend)
+	appendTo []string
+	// only rows are written (see WithoutHeader)
+	noHeader bool
+}
 
 func NewCSV() *CSV {
-	return &CSV{}
+	return &CSV{comma: ','}
+}
+
+// NewTSV returns a [CSV] formatter which separates fields with tabs.
+func NewTSV() *CSV {
+	return &CSV{comma: '\t'}
+}
+
+// NewCSVAppend returns a [CSV] formatter for appending rows to a file with the given header.
+// The header is not written and columns are reordered to match it. If the columns
+// of the result are not the same as the header, formatting fails.
+func NewCSVAppend(header []string) *CSV {
+	return &CSV{comma: ',', appendTo: header}
+}
+
+// WithoutHeader returns a copy of the formatter which writes only the rows
+// (e.g. to continue output written in batches).
+func (cf *CSV) WithoutHeader() *CSV {
+	c := *cf
+	c.noHeader = true
+	return &c
+}
+
+// appendOrder returns indexes of result columns in the order of the appended header.
+func (cf *CSV) appendOrder(header core.Header) ([]int, error) {
+	mismatch := fmt.Errorf("columns of the result (%s) don't match the header (%s)",
+		strings.Join(header, ", "), strings.Join(cf.appendTo, ", "))
+
+	if len(header) != len(cf.appendTo) {
+		return nil, mismatch
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, col := range header {
+		if _, ok := positions[col]; ok {
+			return nil, fmt.Errorf("column %q of the result is not unique", col)
+		}
+		positions[col] = i
+	}
+
+	order := make([]int, len(cf.appendTo))
+	for i, col := range cf.appendTo {
+		pos, ok := positions[col]
+		if !ok {
+			return nil, mismatch
+		}
+		order[i] = pos
+	}
+
+	return order, nil
 }
 
 func (cf *CSV) parseSchemaFul(header core.Header, rows []core.Row) [][]string {
@@ -35,8 +91,30 @@ func (cf *CSV) Format(header core.Header, rows []core.Row, _ *core.FormatterOpti
 	// parse as if schema is defined regardles of schema presence in the result
 	data := cf.parseSchemaFul(header, rows)
 
+	if cf.appendTo != nil {
+		order, err := cf.appendOrder(header)
+		if err != nil {
+			return nil, err
+		}
+
+		// drop the header and reorder the rows
+		data = data[1:]
+		for i, row := range data {
+			reordered := make([]string, len(order))
+			for j, pos := range order {
+				if pos < len(row) {
+					reordered[j] = row[pos]
+				}
+			}
+			data[i] = reordered
+		}
+	} else if cf.noHeader {
+		data = data[1:]
+	}
+
 	b := new(bytes.Buffer)
 	w := csv.NewWriter(b)
+	w.Comma = cf.comma
 
 	err := w.WriteAll(data)
 	if err != nil {
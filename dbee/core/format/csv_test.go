@@ -0,0 +1,40 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestCSVAppend_Format(t *testing.T) {
+	r := require.New(t)
+
+	out, err := format.NewCSVAppend([]string{"id", "name"}).Format(
+		core.Header{"name", "id"},
+		[]core.Row{{"a", 1}, {"b, c", 2}},
+		&core.FormatterOptions{},
+	)
+	r.NoError(err)
+	r.Equal("1,a\n2,\"b, c\"\n", string(out))
+
+	_, err = format.NewCSVAppend([]string{"id", "name"}).Format(core.Header{"id"}, nil, &core.FormatterOptions{})
+	r.ErrorContains(err, "columns of the result (id) don't match the header (id, name)")
+
+	_, err = format.NewCSVAppend([]string{"id", "name"}).Format(core.Header{"id", "id"}, nil, &core.FormatterOptions{})
+	r.ErrorContains(err, `column "id" of the result is not unique`)
+}
+
+func TestCSV_WithoutHeader(t *testing.T) {
+	r := require.New(t)
+
+	out, err := format.NewTSV().WithoutHeader().Format(
+		core.Header{"id", "name"},
+		[]core.Row{{1, "a"}, {2, "b"}},
+		&core.FormatterOptions{},
+	)
+	r.NoError(err)
+	r.Equal("1\ta\n2\tb\n", string(out))
+}
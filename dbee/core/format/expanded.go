@@ -0,0 +1,55 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// formatExpanded renders every row as a record of column name / value pairs
+// (like expanded display in psql). This greatly helps with reading wide results.
+func formatExpanded(header core.Header, rows []core.Row, opts *core.FormatterOptions) []byte {
+	nameWidth := 0
+	for _, h := range header {
+		nameWidth = max(nameWidth, text.RuneWidthWithoutEscSequences(h))
+	}
+
+	// values are split into lines, so multiline values stay aligned
+	values := make([][][]string, len(rows))
+	valueWidth := 0
+	for i, row := range rows {
+		values[i] = make([][]string, len(header))
+		for j := range header {
+			var v any
+			if j < len(row) {
+				v = row[j]
+			}
+			lines := strings.Split(fmt.Sprint(v), "\n")
+			for _, l := range lines {
+				valueWidth = max(valueWidth, text.RuneWidthWithoutEscSequences(l))
+			}
+			values[i][j] = lines
+		}
+	}
+
+	var sb strings.Builder
+	for i := range rows {
+		title := fmt.Sprintf("─[ RECORD %d ]", opts.ChunkStart+i+1)
+		fill := nameWidth + 3 + valueWidth - text.RuneWidthWithoutEscSequences(title)
+		sb.WriteString(title + strings.Repeat("─", max(fill, 1)) + "\n")
+
+		for j, name := range header {
+			for k, line := range values[i][j] {
+				if k > 0 {
+					name = ""
+				}
+				sb.WriteString(strings.TrimRight(text.Pad(name, nameWidth, ' ')+" │ "+line, " ") + "\n")
+			}
+		}
+	}
+
+	return []byte(strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var _ core.Formatter = (*Markdown)(nil)
+
+// Markdown formats the result as a markdown (GFM) table.
+type Markdown struct{}
+
+func NewMarkdown() *Markdown {
+	return &Markdown{}
+}
+
+// cell escapes the value so it doesn't break the table.
+func (mf *Markdown) cell(val any) string {
+	if val == nil {
+		return ""
+	}
+
+	s := fmt.Sprint(val)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	s = strings.ReplaceAll(s, "\n", "<br>")
+	return s
+}
+
+func (mf *Markdown) writeRow(b *bytes.Buffer, cells []string) {
+	b.WriteString("| ")
+	b.WriteString(strings.Join(cells, " | "))
+	b.WriteString(" |\n")
+}
+
+func (mf *Markdown) Format(header core.Header, rows []core.Row, _ *core.FormatterOptions) ([]byte, error) {
+	b := new(bytes.Buffer)
+
+	cells := make([]string, len(header))
+	for i, h := range header {
+		cells[i] = mf.cell(h)
+	}
+	mf.writeRow(b, cells)
+
+	for i := range cells {
+		cells[i] = "---"
+	}
+	mf.writeRow(b, cells)
+
+	for _, row := range rows {
+		cells := make([]string, len(header))
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = mf.cell(row[i])
+			}
+		}
+		mf.writeRow(b, cells)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestMarkdown_Format(t *testing.T) {
+	r := require.New(t)
+
+	out, err := format.NewMarkdown().Format(
+		core.Header{"id", "note"},
+		[]core.Row{
+			{1, "a|b"},
+			{2, "line\nbreak"},
+			{3, nil},
+		},
+		&core.FormatterOptions{},
+	)
+	r.NoError(err)
+
+	expected := "| id | note |\n" +
+		"| --- | --- |\n" +
+		"| 1 | a\\|b |\n" +
+		"| 2 | line<br>break |\n" +
+		"| 3 |  |\n"
+	r.Equal(expected, string(out))
+}
+
+func TestTSV_Format(t *testing.T) {
+	r := require.New(t)
+
+	out, err := format.NewTSV().Format(
+		core.Header{"id", "name"},
+		[]core.Row{{1, "one, two"}},
+		&core.FormatterOptions{},
+	)
+	r.NoError(err)
+	r.Equal("id\tname\n1\tone, two\n", string(out))
+}
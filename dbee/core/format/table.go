@@ -0,0 +1,156 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var _ core.Formatter = (*TextTable)(nil)
+
+// TableStyle determines how the table is drawn.
+type TableStyle int
+
+const (
+	// TableStyleBorderless draws only the column and header separators.
+	TableStyleBorderless TableStyle = iota
+	// TableStyleBox draws full box borders around the table (like psql or mysql cli).
+	TableStyleBox
+	// TableStyleExpanded draws every row as a record of column name / value pairs.
+	TableStyleExpanded
+)
+
+func TableStyleFromString(s string) TableStyle {
+	switch s {
+	case "box":
+		return TableStyleBox
+	case "expanded":
+		return TableStyleExpanded
+	default:
+		return TableStyleBorderless
+	}
+}
+
+// TextTable renders rows as an aligned plain text table with numbered rows.
+// It doesn't depend on the editor, so the same output can be shown in a buffer
+// or written to any file.
+type TextTable struct {
+	style TableStyle
+}
+
+func NewTextTable(style TableStyle) *TextTable {
+	return &TextTable{
+		style: style,
+	}
+}
+
+// Write renders the rows and writes the table to w.
+func (tf *TextTable) Write(w io.Writer, header core.Header, rows []core.Row, opts *core.FormatterOptions) error {
+	out, err := tf.Format(header, rows, opts)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("w.Write: %w", err)
+	}
+	return nil
+}
+
+func (tf *TextTable) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	if tf.style == TableStyleExpanded {
+		return formatExpanded(header, rows, opts), nil
+	}
+
+	tableHeaders := []any{""}
+	for _, k := range header {
+		tableHeaders = append(tableHeaders, k)
+	}
+	index := opts.ChunkStart
+
+	var tableRows []table.Row
+	for _, row := range rows {
+		indexedRow := append([]any{index + 1}, row...)
+		tableRows = append(tableRows, table.Row(indexedRow))
+		index += 1
+	}
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row(tableHeaders))
+	t.AppendRows(tableRows)
+	t.AppendSeparator()
+	t.SetStyle(table.StyleLight)
+	t.Style().Format = table.FormatOptions{
+		Footer: text.FormatDefault,
+		Header: text.FormatDefault,
+		Row:    text.FormatDefault,
+	}
+	// column widths are computed from the display width of runes,
+	// so wide (CJK) characters are aligned correctly in both styles
+	t.Style().Options.DrawBorder = tf.style == TableStyleBox
+	t.SuppressTrailingSpaces()
+	render := t.Render()
+
+	return []byte(render), nil
+}
+
+// tableJunctions are characters of the header separator line where columns meet
+// (left border and inner column separators).
+var tableJunctions = map[rune]bool{'├': true, '┼': true}
+
+// TableColumnOffsets returns byte offsets of the starts of cells (after padding) on every
+// line of a table rendered by TextTable, so columns can be located even if lines contain
+// wide or multi-byte characters. The first offset of each line is the row index column.
+// Expanded tables don't have columns, so nil is returned.
+func TableColumnOffsets(table []byte) [][]int {
+	lines := strings.Split(string(table), "\n")
+
+	// display columns of cell starts are taken from the header separator line,
+	// which consists only of drawing characters
+	var starts []int
+	for _, line := range lines {
+		if !strings.ContainsRune(line, '┼') {
+			continue
+		}
+		width := 0
+		for _, r := range line {
+			if tableJunctions[r] {
+				starts = append(starts, width+2)
+			}
+			width += text.RuneWidth(r)
+		}
+		// borderless table doesn't have the left border
+		if !strings.HasPrefix(line, "├") {
+			starts = append([]int{1}, starts...)
+		}
+		break
+	}
+	if len(starts) < 1 {
+		return nil
+	}
+
+	offsets := make([][]int, len(lines))
+	for i, line := range lines {
+		lineOffsets := make([]int, 0, len(starts))
+		width, next := 0, 0
+		for pos, r := range line {
+			for next < len(starts) && width >= starts[next] {
+				lineOffsets = append(lineOffsets, pos)
+				next++
+			}
+			width += text.RuneWidth(r)
+		}
+		// trailing spaces of empty cells are suppressed
+		for ; next < len(starts); next++ {
+			lineOffsets = append(lineOffsets, len(line))
+		}
+		offsets[i] = lineOffsets
+	}
+
+	return offsets
+}
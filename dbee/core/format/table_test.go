@@ -0,0 +1,178 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestTable_TimeFormat(t *testing.T) {
+	r := require.New(t)
+
+	ts := time.Date(2024, 3, 5, 14, 30, 15, 0, time.UTC)
+	tf, err := core.ParseTimeFormat("datetime", "")
+	r.NoError(err)
+
+	out, err := core.NewTimeFormatter(NewTextTable(TableStyleBorderless), tf).
+		Format(core.Header{"created"}, []core.Row{{ts}}, &core.FormatterOptions{})
+	r.NoError(err)
+
+	r.Equal(`   │ created
+───┼─────────────────────
+ 1 │ 2024-03-05 14:30:15`, string(out))
+}
+
+func TestTextTable_Write(t *testing.T) {
+	header := core.Header{"id", "name"}
+	rows := []core.Row{
+		{1, "alice"},
+		{2, "日本語"},
+		{3, nil},
+	}
+
+	type testCase struct {
+		style    TableStyle
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			style: TableStyleBorderless,
+			expected: `    │ id │ name
+────┼────┼────────
+ 11 │  1 │ alice
+ 12 │  2 │ 日本語
+ 13 │  3 │ <nil>`,
+		},
+		{
+			style: TableStyleBox,
+			expected: `┌────┬────┬────────┐
+│    │ id │ name   │
+├────┼────┼────────┤
+│ 11 │  1 │ alice  │
+│ 12 │  2 │ 日本語 │
+│ 13 │  3 │ <nil>  │
+└────┴────┴────────┘`,
+		},
+		{
+			style: TableStyleExpanded,
+			expected: `─[ RECORD 11 ]─
+id   │ 1
+name │ alice
+─[ RECORD 12 ]─
+id   │ 2
+name │ 日本語
+─[ RECORD 13 ]─
+id   │ 3
+name │ <nil>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		err := NewTextTable(tc.style).Write(&buf, header, rows, &core.FormatterOptions{ChunkStart: 10})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, buf.String())
+	}
+}
+
+func TestTableStyleFromString(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected TableStyle
+	}
+
+	testCases := []testCase{
+		{input: "box", expected: TableStyleBox},
+		{input: "expanded", expected: TableStyleExpanded},
+		{input: "borderless", expected: TableStyleBorderless},
+		// invalid styles fall back to the borderless style
+		{input: "", expected: TableStyleBorderless},
+		{input: "Box", expected: TableStyleBorderless},
+		{input: " box", expected: TableStyleBorderless},
+		{input: "grid", expected: TableStyleBorderless},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			require.Equal(t, tc.expected, TableStyleFromString(tc.input))
+		})
+	}
+}
+
+func TestTextTable_WideCharacters(t *testing.T) {
+	// hangul, kanji and full-width latin take two columns each
+	header := core.Header{"名前", "city"}
+	rows := []core.Row{
+		{"김철수", "서울"},
+		{"ab", "東京都"},
+		{"ｆｕｌｌ", "x"},
+	}
+
+	type testCase struct {
+		style    TableStyle
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			style: TableStyleBox,
+			expected: `┌───┬──────────┬────────┐
+│   │ 名前     │ city   │
+├───┼──────────┼────────┤
+│ 1 │ 김철수   │ 서울   │
+│ 2 │ ab       │ 東京都 │
+│ 3 │ ｆｕｌｌ │ x      │
+└───┴──────────┴────────┘`,
+		},
+		{
+			style: TableStyleBorderless,
+			expected: `   │ 名前     │ city
+───┼──────────┼────────
+ 1 │ 김철수   │ 서울
+ 2 │ ab       │ 東京都
+ 3 │ ｆｕｌｌ │ x`,
+		},
+	}
+
+	for _, tc := range testCases {
+		out, err := NewTextTable(tc.style).Format(header, rows, &core.FormatterOptions{})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, string(out))
+	}
+}
+
+func TestTableColumnOffsets(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"id", "name", "note"}
+	rows := []core.Row{
+		{1, "日本語", "x"},
+		{2, "bob", nil},
+	}
+
+	render := func(style TableStyle) []byte {
+		out, err := NewTextTable(style).Format(header, rows, &core.FormatterOptions{})
+		r.NoError(err)
+		return out
+	}
+
+	// drawing characters and "日本語" take multiple bytes
+	offsets := TableColumnOffsets(render(TableStyleBorderless))
+	r.Len(offsets, 4)
+	r.Equal([]int{1, 7, 14, 25}, offsets[0])
+	r.Equal([]int{3, 15, 30, 57}, offsets[1])
+	r.Equal([]int{1, 7, 14, 28}, offsets[2])
+	r.Equal([]int{1, 7, 14, 25}, offsets[3])
+
+	offsets = TableColumnOffsets(render(TableStyleBox))
+	r.Len(offsets, 6)
+	r.Equal([]int{4, 10, 17, 28}, offsets[1])
+	r.Equal([]int{4, 10, 17, 31}, offsets[3])
+
+	r.Nil(TableColumnOffsets(render(TableStyleExpanded)))
+}
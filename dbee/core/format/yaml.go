@@ -0,0 +1,145 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var _ core.Formatter = (*YAML)(nil)
+
+type YAML struct{}
+
+func NewYAML() *YAML {
+	return &YAML{}
+}
+
+// valueNode converts a single value to a yaml node.
+// Multiline strings are rendered as literal block scalars and nil values as null.
+func (yf *YAML) valueNode(val any) (*yaml.Node, error) {
+	if val == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+
+	// types without a yaml representation are stringified
+	if _, ok := val.(yaml.Marshaler); !ok {
+		if s, ok := val.(fmt.Stringer); ok {
+			val = s.String()
+		}
+	}
+
+	node := new(yaml.Node)
+	err := node.Encode(val)
+	if err != nil {
+		return nil, fmt.Errorf("node.Encode: %w", err)
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+
+	return node, nil
+}
+
+// recordNode converts a row to a mapping of the header to values.
+func (yf *YAML) recordNode(header core.Header, row core.Row) (*yaml.Node, error) {
+	record := &yaml.Node{Kind: yaml.MappingNode}
+	for i, val := range row {
+		var h string
+		if i < len(header) {
+			h = header[i]
+		} else {
+			h = fmt.Sprintf("<unknown-field-%d>", i)
+		}
+
+		valNode, err := yf.valueNode(val)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Content = append(record.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: h},
+			valNode,
+		)
+	}
+
+	return record, nil
+}
+
+// schemaLessNode converts a row to its only value or a sequence of values.
+// Empty rows are skipped (nil node).
+func (yf *YAML) schemaLessNode(row core.Row) (*yaml.Node, error) {
+	switch {
+	case len(row) == 1:
+		return yf.valueNode(row[0])
+	case len(row) > 1:
+		nested := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, val := range row {
+			valNode, err := yf.valueNode(val)
+			if err != nil {
+				return nil, err
+			}
+			nested.Content = append(nested.Content, valNode)
+		}
+		return nested, nil
+	default:
+		return nil, nil
+	}
+}
+
+// encodeItem writes the node as an item of the top level sequence.
+func (yf *YAML) encodeItem(w io.Writer, node *yaml.Node) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	err := enc.Encode(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{node}})
+	if err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+	err = enc.Close()
+	if err != nil {
+		return fmt.Errorf("enc.Close: %w", err)
+	}
+
+	return nil
+}
+
+// Format encodes rows one at a time, so only a single row is converted to yaml nodes at once.
+func (yf *YAML) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	b := new(bytes.Buffer)
+
+	for _, row := range rows {
+		var node *yaml.Node
+		var err error
+		switch opts.SchemaType {
+		case core.SchemaLess:
+			node, err = yf.schemaLessNode(row)
+		case core.SchemaFul:
+			fallthrough
+		default:
+			node, err = yf.recordNode(header, row)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			continue
+		}
+
+		err = yf.encodeItem(b, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if b.Len() == 0 {
+		return []byte("[]\n"), nil
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,89 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestYAML_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   core.Header
+		rows     []core.Row
+		schema   core.SchemaType
+		expected string
+	}{
+		{
+			name:     "empty",
+			header:   core.Header{"id"},
+			expected: "[]\n",
+		},
+		{
+			name:   "nulls",
+			header: core.Header{"id", "name"},
+			rows:   []core.Row{{1, nil}, {nil, "b"}},
+			expected: `- id: 1
+  name: null
+- id: null
+  name: b
+`,
+		},
+		{
+			name:   "multiline strings",
+			header: core.Header{"id", "body"},
+			rows:   []core.Row{{1, "first\nsecond\n"}, {2, "single"}},
+			expected: `- id: 1
+  body: |
+    first
+    second
+- id: 2
+  body: single
+`,
+		},
+		{
+			name:   "special keys",
+			header: core.Header{"a: b", "true", "", "- item", "#"},
+			rows:   []core.Row{{1, 2, 3, 4, 5}},
+			expected: `- 'a: b': 1
+  "true": 2
+  "": 3
+  '- item': 4
+  '#': 5
+`,
+		},
+		{
+			name:   "unknown fields",
+			header: core.Header{"id"},
+			rows:   []core.Row{{1, "a"}},
+			expected: `- id: 1
+  <unknown-field-1>: a
+`,
+		},
+		{
+			name:   "schemaless",
+			header: core.Header{"id", "name"},
+			rows:   []core.Row{{1}, {}, {2, "multi\nline"}},
+			schema: core.SchemaLess,
+			expected: `- 1
+- - 2
+  - |-
+    multi
+    line
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			out, err := format.NewYAML().Format(tt.header, tt.rows, &core.FormatterOptions{SchemaType: tt.schema})
+			r.NoError(err)
+			r.Equal(tt.expected, string(out))
+		})
+	}
+}
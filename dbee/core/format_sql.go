@@ -0,0 +1,332 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+)
+
+// sqlKeywords are uppercased by FormatSQL.
+var sqlKeywords = toSet(
+	"ADD", "ALL", "ALTER", "AND", "ANY", "AS", "ASC", "BEGIN", "BETWEEN", "BY", "CASCADE", "CASE",
+	"CAST", "CHECK", "COLUMN", "COMMIT", "CONFLICT", "CONSTRAINT", "CREATE", "CROSS", "DEFAULT",
+	"DELETE", "DESC", "DISTINCT", "DO", "DROP", "ELSE", "END", "EXCEPT", "EXISTS", "FALSE", "FETCH",
+	"FIRST", "FOR", "FOREIGN", "FROM", "FULL", "GRANT", "GROUP", "HAVING", "IF", "ILIKE", "IN", "INDEX",
+	"INNER", "INSERT", "INTERSECT", "INTERVAL", "INTO", "IS", "JOIN", "KEY", "LATERAL", "LEFT", "LIKE",
+	"LIMIT", "NATURAL", "NEXT", "NOT", "NOTHING", "NULL", "OFFSET", "ON", "ONLY", "OR", "ORDER",
+	"OUTER", "OVER", "PARTITION", "PRIMARY", "RECURSIVE", "REFERENCES", "REPLACE", "RETURNING",
+	"REVOKE", "RIGHT", "ROLLBACK", "ROW", "ROWS", "SELECT", "SET", "SOME", "TABLE", "THEN", "TOP",
+	"TRUE", "TRUNCATE", "UNION", "UNIQUE", "UPDATE", "USING", "VALUES", "VIEW", "WHEN", "WHERE",
+	"WINDOW", "WITH",
+)
+
+// sqlClauseKeywords start a new line when they appear at the query level.
+var sqlClauseKeywords = toSet(
+	"DELETE", "EXCEPT", "FROM", "GROUP", "HAVING", "INSERT", "INTERSECT", "JOIN", "LIMIT", "OFFSET",
+	"ORDER", "RETURNING", "SELECT", "SET", "UNION", "UPDATE", "VALUES", "WHERE", "WINDOW",
+)
+
+// sqlJoinModifiers start a new line if they are followed by a join.
+var sqlJoinModifiers = toSet("CROSS", "FULL", "INNER", "LEFT", "NATURAL", "OUTER", "RIGHT")
+
+// sqlNoBreakAfter are keywords after which a clause keyword is part of the same
+// expression (e.g. "ON DELETE CASCADE", "FOR UPDATE", "DO UPDATE SET").
+var sqlNoBreakAfter = toSet("AFTER", "BEFORE", "DELETE", "DO", "FOR", "INSTEAD", "OF", "ON", "UPDATE")
+
+// sqlSpacedParenAfter are keywords after which the next word is followed by a spaced
+// parenthesis (e.g. "INSERT INTO t (a, b)" as opposed to a function call "f(a, b)").
+var sqlSpacedParenAfter = toSet("INTO", "TABLE")
+
+// sqlOperators are multi-character operators, longest first.
+var sqlOperators = []string{"->>", "#>>", "<=", ">=", "<>", "!=", "||", "::", "->", "#>", "@>", "<@", "&&", "<<", ">>", "~*", "!~"}
+
+// sqlFunctionKeywords are keywords that are also used as functions,
+// so no space is put before their parentheses.
+var sqlFunctionKeywords = toSet("ANY", "CAST", "IF", "LEFT", "REPLACE", "RIGHT", "SOME")
+
+func toSet(values ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+func inSet(set map[string]struct{}, value string) bool {
+	_, ok := set[value]
+	return ok
+}
+
+type sqlFormatTokenKind int
+
+const (
+	sqlFormatWord sqlFormatTokenKind = iota
+	sqlFormatQuoted
+	sqlFormatLineComment
+	sqlFormatBlockComment
+	sqlFormatPunct
+)
+
+type sqlFormatToken struct {
+	kind  sqlFormatTokenKind
+	value string
+	// no whitespace between the token and the previous one in the query
+	adjacent bool
+}
+
+// isFormatWordChar also accepts non-ascii bytes, so multi-byte characters are never split.
+func isFormatWordChar(ch byte) bool {
+	return isWordChar(ch) || ch >= 0x80
+}
+
+// tokenizeSQL splits the query into tokens, dropping whitespace.
+// ok is false if the query contains an unterminated string or comment.
+func tokenizeSQL(query string) (tokens []sqlFormatToken, ok bool) {
+	adjacent := false
+	add := func(kind sqlFormatTokenKind, value string) {
+		tokens = append(tokens, sqlFormatToken{kind: kind, value: value, adjacent: adjacent})
+		adjacent = true
+	}
+
+	i := 0
+	for i < len(query) {
+		ch := query[i]
+		start := i
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+			adjacent = false
+			continue
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+			add(sqlFormatLineComment, strings.TrimRight(query[start:i], " \t\r"))
+			adjacent = false
+			continue
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, false
+			}
+			i += end + 4
+			add(sqlFormatBlockComment, query[start:i])
+			adjacent = false
+			continue
+		case ch == '\'' || ch == '"' || ch == '`' || ch == '[':
+			closing := ch
+			if ch == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			for {
+				end := strings.IndexByte(query[j:], closing)
+				if end < 0 {
+					return nil, false
+				}
+				j += end + 1
+				// doubled quote is an escaped quote
+				if closing != ']' && j < len(query) && query[j] == closing {
+					j++
+					continue
+				}
+				break
+			}
+			i = j
+			add(sqlFormatQuoted, query[start:i])
+			continue
+		// named parameters (:name), variables (@var, @@global) and temporary tables (#tmp)
+		// are kept together with their prefix
+		case (ch == ':' || ch == '@' || ch == '#') && i+1 < len(query) &&
+			(isFormatWordChar(query[i+1]) || ch == '@' && query[i+1] == '@'):
+			i++
+			for i < len(query) && (isFormatWordChar(query[i]) || query[i] == '@') {
+				i++
+			}
+			add(sqlFormatWord, query[start:i])
+			continue
+		case isFormatWordChar(ch):
+			for i < len(query) && isFormatWordChar(query[i]) {
+				i++
+			}
+			add(sqlFormatWord, query[start:i])
+			continue
+		}
+
+		i++
+		for _, op := range sqlOperators {
+			if strings.HasPrefix(query[start:], op) {
+				i = start + len(op)
+				break
+			}
+		}
+		add(sqlFormatPunct, query[start:i])
+	}
+
+	return tokens, true
+}
+
+// sqlFormatter writes tokens with normalized whitespace.
+type sqlFormatter struct {
+	b bytes.Buffer
+	// position of the current line if nothing was written to it yet, -1 otherwise
+	lineStart int
+	prev      *sqlFormatToken
+	prev2     *sqlFormatToken
+}
+
+// newline starts a new indented line. If the current line is still empty,
+// only its indentation is changed.
+func (f *sqlFormatter) newline(indent int) {
+	if f.b.Len() < 1 {
+		return
+	}
+	if f.lineStart >= 0 {
+		f.b.Truncate(f.lineStart)
+	} else {
+		f.b.WriteByte('\n')
+	}
+	f.lineStart = f.b.Len()
+	f.b.WriteString(strings.Repeat("  ", indent))
+}
+
+func (f *sqlFormatter) write(tok *sqlFormatToken, space bool) {
+	if space && f.lineStart < 0 && f.b.Len() > 0 {
+		f.b.WriteByte(' ')
+	}
+	f.b.WriteString(tok.value)
+	f.lineStart = -1
+	f.prev2 = f.prev
+	f.prev = tok
+}
+
+// FormatSQL pretty-prints the query: keywords are uppercased and major clauses
+// (SELECT, FROM, WHERE, JOIN, ...) start on a new line, with subqueries indented.
+// Contents of string literals, quoted identifiers and comments are kept as they are.
+// It's not a full parser, so queries that can't be tokenized (e.g. unterminated
+// strings) are returned unchanged.
+func FormatSQL(query string) string {
+	tokens, ok := tokenizeSQL(query)
+	if !ok {
+		return query
+	}
+
+	for i := range tokens {
+		if tokens[i].kind == sqlFormatWord && inSet(sqlKeywords, strings.ToUpper(tokens[i].value)) {
+			tokens[i].value = strings.ToUpper(tokens[i].value)
+		}
+	}
+
+	// nextWord returns the first word or punctuation after the i-th token, skipping comments
+	nextWord := func(i int) string {
+		for j := i + 1; j < len(tokens); j++ {
+			if tokens[j].kind != sqlFormatLineComment && tokens[j].kind != sqlFormatBlockComment {
+				return tokens[j].value
+			}
+		}
+		return ""
+	}
+
+	f := &sqlFormatter{lineStart: -1}
+	indent := 0
+	// whether each open parenthesis holds a subquery
+	var parens []bool
+	// whether the current query level has no tokens yet
+	levelStart := true
+	// whether the previous token is a unary sign
+	unary := false
+
+	atQueryLevel := func() bool {
+		return len(parens) == 0 || parens[len(parens)-1]
+	}
+
+	for i := range tokens {
+		tok := &tokens[i]
+		prev := f.prev
+
+		switch tok.kind {
+		case sqlFormatLineComment:
+			f.write(tok, true)
+			f.newline(indent)
+			continue
+		case sqlFormatBlockComment:
+			f.write(tok, true)
+			continue
+		}
+
+		switch tok.value {
+		case "(":
+			subquery := nextWord(i) == "SELECT" || nextWord(i) == "WITH"
+			space := prev == nil || prev.kind != sqlFormatWord && prev.kind != sqlFormatQuoted ||
+				inSet(sqlKeywords, prev.value) && !inSet(sqlFunctionKeywords, prev.value) ||
+				f.prev2 != nil && inSet(sqlSpacedParenAfter, f.prev2.value)
+			if prev != nil && (prev.value == "(" || prev.value == "." || unary) {
+				space = false
+			}
+			unary = false
+			f.write(tok, space)
+			parens = append(parens, subquery)
+			if subquery {
+				indent++
+				f.newline(indent)
+				levelStart = true
+			}
+			continue
+		case ")":
+			if len(parens) > 0 {
+				if parens[len(parens)-1] {
+					indent--
+					f.newline(indent)
+				}
+				parens = parens[:len(parens)-1]
+			}
+			f.write(tok, false)
+			levelStart = false
+			continue
+		case ",", ".", "::":
+			f.write(tok, false)
+			continue
+		case ";":
+			f.write(tok, false)
+			parens = nil
+			indent = 0
+			// statements are separated by an empty line
+			if i < len(tokens)-1 {
+				f.b.WriteByte('\n')
+				f.newline(0)
+			}
+			levelStart = true
+			continue
+		}
+
+		if tok.kind == sqlFormatWord && atQueryLevel() && !levelStart {
+			breaks := inSet(sqlClauseKeywords, tok.value) ||
+				inSet(sqlJoinModifiers, tok.value) && (nextWord(i) == "JOIN" || nextWord(i) == "OUTER")
+			if tok.value == "JOIN" || tok.value == "OUTER" {
+				breaks = breaks && !(prev != nil && inSet(sqlJoinModifiers, prev.value))
+			}
+			if prev != nil && inSet(sqlNoBreakAfter, prev.value) {
+				breaks = false
+			}
+			if breaks {
+				f.newline(indent)
+			}
+		}
+
+		space := prev == nil || (prev.value != "(" && prev.value != "." && prev.value != "::" && !unary)
+		// keep prefixed literals together (e.g. E'...' or N'...')
+		if tok.kind == sqlFormatQuoted && tok.adjacent && prev != nil && prev.kind == sqlFormatWord {
+			space = false
+		}
+		f.write(tok, space)
+		levelStart = false
+
+		// sign of a number (e.g. "= -1") is not an operator
+		unary = (tok.value == "-" || tok.value == "+") &&
+			(prev == nil || prev.kind == sqlFormatPunct && prev.value != ")" || inSet(sqlKeywords, prev.value))
+	}
+
+	return strings.TrimSpace(f.b.String())
+}
@@ -0,0 +1,77 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestFormatSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "clauses",
+			query: "select a, count(*) as n from users u left outer join orders o on o.user_id = u.id where o.total >= 10 group by a having count(*) > 1 order by n desc limit 10",
+			want: `SELECT a, count(*) AS n
+FROM users u
+LEFT OUTER JOIN orders o ON o.user_id = u.id
+WHERE o.total >= 10
+GROUP BY a
+HAVING count(*) > 1
+ORDER BY n DESC
+LIMIT 10`,
+		},
+		{
+			name:  "strings and quoted identifiers are kept",
+			query: `select 'select  from', "Mixed Case", e'it''s', N'x' from [my table]`,
+			want:  `SELECT 'select  from', "Mixed Case", e'it''s', N'x'` + "\nFROM [my table]",
+		},
+		{
+			name:  "comments are kept",
+			query: "-- list   users\nselect /* all  columns */ * -- select\nfrom users",
+			want:  "-- list   users\nSELECT /* all  columns */ * -- select\nFROM users",
+		},
+		{
+			name:  "subqueries are indented",
+			query: "with x as (select id from t where id in (select id from s)) select * from x union all select 1",
+			want: `WITH x AS (
+  SELECT id
+  FROM t
+  WHERE id IN (
+    SELECT id
+    FROM s
+  )
+)
+SELECT *
+FROM x
+UNION ALL
+SELECT 1`,
+		},
+		{
+			name:  "expressions stay inline",
+			query: "select row_number() over (partition by a order by b), cast(c as text), x::int, -1 from t where y = :param and z = @var for update",
+			want:  "SELECT row_number() OVER (PARTITION BY a ORDER BY b), CAST(c AS text), x::int, -1\nFROM t\nWHERE y = :param AND z = @var FOR UPDATE",
+		},
+		{
+			name:  "multiple statements",
+			query: "insert into t (a, b) values (1, 'x'); update t set a = 2 where b = 'x';",
+			want:  "INSERT INTO t (a, b)\nVALUES (1, 'x');\n\nUPDATE t\nSET a = 2\nWHERE b = 'x';",
+		},
+		{
+			name:  "unterminated string is returned unchanged",
+			query: "select 'oops from t",
+			want:  "select 'oops from t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, core.FormatSQL(tt.query))
+		})
+	}
+}
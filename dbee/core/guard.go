@@ -0,0 +1,156 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingWhere is returned for DELETE and UPDATE statements without a WHERE clause,
+// if the connection requires one (see OptionRequireWhere).
+var ErrMissingWhere = errors.New("statement without a WHERE clause refused")
+
+// unguardedStatement returns the command (DELETE or UPDATE) of the first statement
+// of the query that modifies rows without a top-level WHERE clause.
+// WHERE clauses of subqueries (e.g. in the SET clause) don't count. Statements are
+// also found after a leading CTE ("WITH ... DELETE FROM ...") and in the bodies of
+// data modifying CTEs ("WITH d AS (DELETE FROM ...) SELECT ...").
+// Queries that can't be tokenized are treated as guarded, the database reports the error.
+func unguardedStatement(query string) (string, bool) {
+	tokens, _, ok := topLevelTokens(query)
+	if !ok {
+		return "", false
+	}
+
+	// split to statements
+	var statements [][]sqlToken
+	start := 0
+	for i, tok := range tokens {
+		if tok.value == ";" {
+			statements = append(statements, tokens[start:i])
+			start = i + 1
+		}
+	}
+	statements = append(statements, tokens[start:])
+
+	for _, stmt := range statements {
+		command := ""
+		commandIndex := len(stmt)
+		hasWhere := false
+		for i, tok := range stmt {
+			switch tok.value {
+			case "DELETE", "UPDATE", "SELECT", "INSERT", "MERGE", "CREATE", "ALTER":
+				// first command keyword is the one of the statement,
+				// others (e.g. "ON DELETE", "FOR UPDATE") are clauses
+				if command == "" {
+					command = tok.value
+					commandIndex = i
+				}
+			case "WHERE":
+				hasWhere = true
+			}
+		}
+
+		if (command == "DELETE" || command == "UPDATE") && !hasWhere {
+			return command, true
+		}
+
+		if len(stmt) > 0 && stmt[0].value == "WITH" {
+			for _, body := range cteBodies(query, stmt[:commandIndex]) {
+				if command, ok := unguardedStatement(body); ok {
+					return command, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// cteBodies returns the parenthesized bodies of CTEs from the top-level tokens
+// of a "WITH" clause, without the parentheses. The body of a CTE is between its
+// "AS [NOT] [MATERIALIZED]" and the next top-level token.
+func cteBodies(query string, tokens []sqlToken) []string {
+	var bodies []string
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].value != "AS" {
+			continue
+		}
+
+		start := tokens[i].end
+		for i+1 < len(tokens) && (tokens[i+1].value == "NOT" || tokens[i+1].value == "MATERIALIZED") {
+			i++
+			start = tokens[i].end
+		}
+		end := len(query)
+		if i+1 < len(tokens) {
+			end = tokens[i+1].start
+		}
+
+		segment := query[start:end]
+		open, closing := strings.IndexByte(segment, '('), strings.LastIndexByte(segment, ')')
+		if open < 0 || closing < open {
+			continue
+		}
+		bodies = append(bodies, segment[open+1:closing])
+	}
+
+	return bodies
+}
+
+// checkWhere returns an error wrapping ErrMissingWhere if the query
+// contains a DELETE or UPDATE statement without a WHERE clause.
+func checkWhere(query string) error {
+	command, ok := unguardedStatement(query)
+	if !ok {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s affects all rows of the table (option %q is set, force the execution to run it anyway)", ErrMissingWhere, command, OptionRequireWhere)
+}
+
+// readOnlyCommands start statements which only read data.
+var readOnlyCommands = toSet("SELECT", "WITH", "VALUES", "TABLE", "SHOW", "EXPLAIN", "DESCRIBE", "DESC")
+
+// writeKeywords mark statements (or parts of them, e.g. data modifying CTEs,
+// "SELECT ... INTO" or "FOR UPDATE") which write data or take locks.
+var writeKeywords = toSet(
+	"INSERT", "UPDATE", "DELETE", "MERGE", "UPSERT", "INTO", "LOCK", "SHARE",
+	"CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME", "GRANT", "REVOKE", "CALL", "EXEC", "EXECUTE", "DO",
+)
+
+// IsReadOnlyStatement reports whether all statements of the query only read data,
+// so they can be run on a read replica. Queries that can't be tokenized are not read only.
+// Side effects of functions called by the query (e.g. nextval) can't be detected.
+func IsReadOnlyStatement(query string) bool {
+	top, _, ok := topLevelTokens(query)
+	if !ok || len(top) < 1 {
+		return false
+	}
+
+	// every statement has to start with a reading command
+	first := true
+	for _, tok := range top {
+		if tok.value == ";" {
+			first = true
+			continue
+		}
+		if first && !inSet(readOnlyCommands, tok.value) {
+			return false
+		}
+		first = false
+	}
+
+	// writes can also hide in subqueries
+	tokens, ok := tokenizeSQL(query)
+	if !ok {
+		return false
+	}
+	for _, tok := range tokens {
+		if tok.kind == sqlFormatWord && inSet(writeKeywords, strings.ToUpper(tok.value)) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnguardedStatement(t *testing.T) {
+	type testCase struct {
+		name     string
+		query    string
+		expected string
+	}
+
+	testCases := []testCase{
+		// refused
+		{
+			name:     "delete without where",
+			query:    "DELETE FROM users",
+			expected: "DELETE",
+		},
+		{
+			name:     "update without where",
+			query:    "update users set active = false;",
+			expected: "UPDATE",
+		},
+		{
+			name:     "alias",
+			query:    "UPDATE users AS u SET name = 'x'",
+			expected: "UPDATE",
+		},
+		{
+			name:     "where in a comment",
+			query:    "DELETE FROM users -- WHERE id = 1\n",
+			expected: "DELETE",
+		},
+		{
+			name:     "where in a block comment",
+			query:    "DELETE /* WHERE id = 1 */ FROM users",
+			expected: "DELETE",
+		},
+		{
+			name:     "where in a string",
+			query:    "UPDATE users SET note = 'where id = 1'",
+			expected: "UPDATE",
+		},
+		{
+			name:     "where in a quoted identifier",
+			query:    `UPDATE users SET "where" = 1`,
+			expected: "UPDATE",
+		},
+		{
+			name:     "where in a subquery of set",
+			query:    "UPDATE users SET score = (SELECT max(score) FROM scores WHERE scores.user_id = users.id)",
+			expected: "UPDATE",
+		},
+		{
+			name:     "where in a subquery of from",
+			query:    "DELETE FROM users USING (SELECT id FROM banned WHERE reason = 'spam') b",
+			expected: "DELETE",
+		},
+		{
+			name:     "cte",
+			query:    "WITH old AS (SELECT id FROM users WHERE age > 100) DELETE FROM users",
+			expected: "DELETE",
+		},
+		{
+			name:     "delete in a cte",
+			query:    "WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d",
+			expected: "DELETE",
+		},
+		{
+			name:     "update in a materialized cte",
+			query:    "WITH a AS (SELECT 1), u AS MATERIALIZED (UPDATE t SET x = 1 RETURNING *) SELECT * FROM u",
+			expected: "UPDATE",
+		},
+		{
+			name:     "delete in a nested cte",
+			query:    "WITH d AS (WITH n AS (DELETE FROM t RETURNING id) SELECT id FROM n) SELECT * FROM d",
+			expected: "DELETE",
+		},
+		{
+			name:     "second statement",
+			query:    "DELETE FROM users WHERE id = 1; DELETE FROM orders;",
+			expected: "DELETE",
+		},
+
+		// allowed
+		{
+			name:  "delete with where",
+			query: "DELETE FROM users WHERE id = 1",
+		},
+		{
+			name:  "delete with alias and where",
+			query: "delete from users u where u.id = 1",
+		},
+		{
+			name:  "update with subquery in set and where",
+			query: "UPDATE users SET score = (SELECT max(score) FROM scores) WHERE id IN (SELECT user_id FROM scores)",
+		},
+		{
+			name:  "update with comment",
+			query: "UPDATE users -- all of them?\nSET active = false\nWHERE id = 2",
+		},
+		{
+			name:  "delete with where in a cte",
+			query: "WITH d AS (DELETE FROM t WHERE id = 1 RETURNING *) SELECT * FROM d",
+		},
+		{
+			name:  "cte with column list and select alias",
+			query: "WITH d (id) AS (SELECT id FROM t) SELECT id AS x FROM d",
+		},
+		{
+			name:  "truncate",
+			query: "TRUNCATE users",
+		},
+		{
+			name:  "select",
+			query: "SELECT * FROM users",
+		},
+		{
+			name:  "select for update",
+			query: "SELECT * FROM users FOR UPDATE",
+		},
+		{
+			name:  "insert on conflict do update",
+			query: "INSERT INTO users (id) VALUES (1) ON CONFLICT (id) DO UPDATE SET id = excluded.id",
+		},
+		{
+			name:  "create table with on delete",
+			query: "CREATE TABLE orders (user_id int REFERENCES users ON DELETE CASCADE)",
+		},
+		{
+			name:  "unterminated string",
+			query: "DELETE FROM users WHERE name = 'x",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, ok := unguardedStatement(tc.query)
+			require.Equal(t, tc.expected != "", ok)
+			require.Equal(t, tc.expected, command)
+		})
+	}
+}
+
+func TestIsReadOnlyStatement(t *testing.T) {
+	r := require.New(t)
+
+	for _, query := range []string{
+		"SELECT * FROM users",
+		"select 1; select 2;",
+		"WITH active AS (SELECT * FROM users WHERE active) SELECT count(*) FROM active",
+		"SELECT replace(name, 'a', 'b') FROM users -- insert later",
+		"SELECT 'DELETE FROM users'",
+		"SHOW TABLES",
+		"EXPLAIN SELECT * FROM users",
+	} {
+		r.True(IsReadOnlyStatement(query), query)
+	}
+
+	for _, query := range []string{
+		"",
+		"INSERT INTO users VALUES (1)",
+		"SELECT 1; DELETE FROM users",
+		"WITH gone AS (DELETE FROM users RETURNING *) SELECT * FROM gone",
+		"SELECT * INTO backup FROM users",
+		"SELECT * FROM users FOR UPDATE",
+		"EXPLAIN ANALYZE UPDATE users SET active = false",
+		"SELECT 'unterminated",
+	} {
+		r.False(IsReadOnlyStatement(query), query)
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchRows returns indexes (zero based, in the displayed order) of the rows whose value
+// of the column satisfies the predicate "<column> <operator> <value>". The column is matched
+// the same way as in SelectColumns. Supported operators are "=", "!=", "<", "<=", ">", ">="
+// and "~" (contains, case insensitive). Values are compared the same way as in Sort: numbers
+// numerically, dates chronologically and everything else as strings. NULLs never match.
+func (cr *Result) MatchRows(column, operator, value string) ([]int, error) {
+	idx := cr.columnIndex(column)
+	if idx < 0 {
+		return nil, fmt.Errorf("unknown column: %q", column)
+	}
+
+	match, err := rowPredicate(operator, value)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := cr.Rows(0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := []int{}
+	for i, row := range rows {
+		if idx >= len(row) || isNull(row[idx]) {
+			continue
+		}
+		if match(row[idx]) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes, nil
+}
+
+// rowPredicate returns a function that compares a value to the operand with the operator.
+func rowPredicate(operator, operand string) (func(v any) bool, error) {
+	compare := func(check func(int) bool) func(any) bool {
+		return func(v any) bool {
+			return check(compareValues(v, operand))
+		}
+	}
+
+	switch operator {
+	case "=", "==":
+		return compare(func(c int) bool { return c == 0 }), nil
+	case "!=", "<>":
+		return compare(func(c int) bool { return c != 0 }), nil
+	case "<":
+		return compare(func(c int) bool { return c < 0 }), nil
+	case "<=":
+		return compare(func(c int) bool { return c <= 0 }), nil
+	case ">":
+		return compare(func(c int) bool { return c > 0 }), nil
+	case ">=":
+		return compare(func(c int) bool { return c >= 0 }), nil
+	case "~":
+		operand = strings.ToLower(operand)
+		return func(v any) bool {
+			return strings.Contains(strings.ToLower(sortString(v)), operand)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator: %q", operator)
+}
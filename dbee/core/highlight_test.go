@@ -0,0 +1,58 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestResult_MatchRows(t *testing.T) {
+	r := require.New(t)
+
+	result := new(core.Result)
+	err := result.SetIter(mock.NewResultStream([]core.Row{
+		{1, "Alice", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"10", "bob", "2024-03-01"},
+		{nil, "Carol", nil},
+		{2.5, "alicia", "2023-12-31"},
+	}), nil)
+	r.NoError(err)
+
+	tests := []struct {
+		column   string
+		operator string
+		value    string
+		expected []int
+	}{
+		// numbers are compared numerically, numeric strings included
+		{column: "header_0", operator: ">", value: "2", expected: []int{1, 3}},
+		{column: "header_0", operator: "<=", value: "2.5", expected: []int{0, 3}},
+		{column: "header_0", operator: "=", value: "10", expected: []int{1}},
+		// nulls never match
+		{column: "header_0", operator: "!=", value: "1", expected: []int{1, 3}},
+		// strings
+		{column: "HEADER_1", operator: "=", value: "bob", expected: []int{1}},
+		{column: "header_1", operator: "<", value: "B", expected: []int{0}},
+		{column: "header_1", operator: "~", value: "ALI", expected: []int{0, 3}},
+		// dates are compared chronologically
+		{column: "header_2", operator: ">=", value: "2024-01-01", expected: []int{0, 1}},
+		// no matches
+		{column: "header_1", operator: "=", value: "dave", expected: []int{}},
+	}
+
+	for _, tt := range tests {
+		indexes, err := result.MatchRows(tt.column, tt.operator, tt.value)
+		r.NoError(err)
+		r.Equal(tt.expected, indexes, "%s %s %s", tt.column, tt.operator, tt.value)
+	}
+
+	// validation
+	_, err = result.MatchRows("missing", "=", "1")
+	r.ErrorContains(err, "unknown column")
+	_, err = result.MatchRows("header_0", "like", "1")
+	r.ErrorContains(err, "unknown operator")
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"strings"
+)
+
+// IdentifierCase is the case unquoted identifiers are folded to by the database
+// (e.g. oracle stores unquoted "employees" as "EMPLOYEES" and postgres as "employees").
+type IdentifierCase int
+
+const (
+	// IdentifierCaseSensitive means unquoted identifiers are not folded.
+	IdentifierCaseSensitive IdentifierCase = iota
+	// IdentifierCaseLower means unquoted identifiers are folded to lower case.
+	IdentifierCaseLower
+	// IdentifierCaseUpper means unquoted identifiers are folded to upper case.
+	IdentifierCaseUpper
+)
+
+// fold returns the name as the database stores it when it's not quoted.
+func (ic IdentifierCase) fold(name string) string {
+	switch ic {
+	case IdentifierCaseLower:
+		return strings.ToLower(name)
+	case IdentifierCaseUpper:
+		return strings.ToUpper(name)
+	default:
+		return name
+	}
+}
+
+// isPlain checks if the name (as stored in the catalog) can be written without quotes,
+// i.e. it consists of letters, digits and underscores in the folded case and doesn't start
+// with a digit.
+func (ic IdentifierCase) isPlain(name string) bool {
+	if name == "" || ic.fold(name) != name {
+		return false
+	}
+
+	for i, ch := range name {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch == '_':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// QuoteIdentifier encloses the name in double quotes, doubling the quotes inside of it.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// unquoteIdentifier reverses QuoteIdentifier. ok is false if the name is not quoted.
+func unquoteIdentifier(name string) (unquoted string, ok bool) {
+	if len(name) < 2 || !strings.HasPrefix(name, `"`) || !strings.HasSuffix(name, `"`) {
+		return name, false
+	}
+	return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`), true
+}
+
+// Quote returns the name (as stored in the catalog) as it has to be written in a query:
+// quoted only if it would be folded to a different name otherwise.
+func (ic IdentifierCase) Quote(name string) string {
+	if ic.isPlain(name) {
+		return name
+	}
+	return QuoteIdentifier(name)
+}
+
+// Display returns the name (as stored in the catalog) in the form shown in the structure,
+// which is consistent across databases: names that don't need quoting are shown in lower case
+// and the others are shown quoted (e.g. "EMPLOYEES" of oracle is shown as employees
+// and "OrderItems" as "OrderItems"). Names of case sensitive databases are not changed.
+func (ic IdentifierCase) Display(name string) string {
+	if ic == IdentifierCaseSensitive || name == "" {
+		return name
+	}
+	if ic.isPlain(name) {
+		return strings.ToLower(name)
+	}
+	return QuoteIdentifier(name)
+}
+
+// Catalog reverses Display: quoted names are unquoted and the others are folded.
+func (ic IdentifierCase) Catalog(name string) string {
+	if ic == IdentifierCaseSensitive {
+		return name
+	}
+	if unquoted, ok := unquoteIdentifier(name); ok {
+		return unquoted
+	}
+	return ic.fold(name)
+}
+
+// displayStructure replaces names of the structure (and its children) with their displayed form.
+func (ic IdentifierCase) displayStructure(structure []*Structure) []*Structure {
+	if structure == nil || ic == IdentifierCaseSensitive {
+		return structure
+	}
+
+	displayed := make([]*Structure, len(structure))
+	for i, s := range structure {
+		displayed[i] = &Structure{
+			Name:     ic.Display(s.Name),
+			Schema:   ic.Display(s.Schema),
+			Type:     s.Type,
+			Parent:   ic.Display(s.Parent),
+			Children: ic.displayStructure(s.Children),
+		}
+	}
+	return displayed
+}
+
+// catalogOptions returns options with names of the displayed structure converted back
+// to names stored in the catalog.
+func (ic IdentifierCase) catalogOptions(opts *TableOptions) *TableOptions {
+	if opts == nil || ic == IdentifierCaseSensitive {
+		return opts
+	}
+	return &TableOptions{
+		Table:           ic.Catalog(opts.Table),
+		Schema:          ic.Catalog(opts.Schema),
+		Materialization: opts.Materialization,
+		Parent:          ic.Catalog(opts.Parent),
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifierCase(t *testing.T) {
+	type testCase struct {
+		ic      IdentifierCase
+		catalog string
+		quoted  string
+		display string
+	}
+
+	testCases := []testCase{
+		{ic: IdentifierCaseLower, catalog: "order_items", quoted: "order_items", display: "order_items"},
+		{ic: IdentifierCaseLower, catalog: "OrderItems", quoted: `"OrderItems"`, display: `"OrderItems"`},
+		{ic: IdentifierCaseLower, catalog: "order items", quoted: `"order items"`, display: `"order items"`},
+		{ic: IdentifierCaseLower, catalog: `say "hi"`, quoted: `"say ""hi"""`, display: `"say ""hi"""`},
+		{ic: IdentifierCaseLower, catalog: "2024_sales", quoted: `"2024_sales"`, display: `"2024_sales"`},
+		{ic: IdentifierCaseUpper, catalog: "ORDER_ITEMS", quoted: "ORDER_ITEMS", display: "order_items"},
+		{ic: IdentifierCaseUpper, catalog: "OrderItems", quoted: `"OrderItems"`, display: `"OrderItems"`},
+		{ic: IdentifierCaseUpper, catalog: "order_items", quoted: `"order_items"`, display: `"order_items"`},
+		{ic: IdentifierCaseSensitive, catalog: "OrderItems", quoted: "OrderItems", display: "OrderItems"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.catalog, func(t *testing.T) {
+			r := require.New(t)
+
+			r.Equal(tc.quoted, tc.ic.Quote(tc.catalog))
+			r.Equal(tc.display, tc.ic.Display(tc.catalog))
+			// displayed names round-trip
+			r.Equal(tc.catalog, tc.ic.Catalog(tc.display))
+		})
+	}
+
+	// unquoted names typed by users are folded
+	r := require.New(t)
+	r.Equal("ORDERITEMS", IdentifierCaseUpper.Catalog("OrderItems"))
+	r.Equal("orderitems", IdentifierCaseLower.Catalog("OrderItems"))
+}
+
+// foldingDriver is a driver of a database that folds identifiers to upper case.
+type foldingDriver struct {
+	sequenceDriver
+	columns []*TableOptions
+}
+
+func (d *foldingDriver) Structure() ([]*Structure, error) {
+	return []*Structure{
+		{
+			Name: "HR",
+			Type: StructureTypeNone,
+			Children: []*Structure{
+				{Name: "EMPLOYEES", Schema: "HR", Type: StructureTypeTable},
+				{Name: "OrderItems", Schema: "HR", Type: StructureTypeTable},
+			},
+		},
+	}, nil
+}
+
+func (d *foldingDriver) Columns(opts *TableOptions) ([]*Column, error) {
+	d.columns = append(d.columns, opts)
+	return []*Column{{Name: "ID", Type: "NUMBER"}}, nil
+}
+
+func (d *foldingDriver) IdentifierCase() IdentifierCase {
+	return IdentifierCaseUpper
+}
+
+type foldingAdapter struct {
+	driver *foldingDriver
+}
+
+func (a *foldingAdapter) Connect(_ string) (Driver, error) {
+	return a.driver, nil
+}
+
+func (a *foldingAdapter) GetHelpers(opts *TableOptions) map[string]string {
+	return map[string]string{
+		"List": "SELECT * FROM " + IdentifierCaseUpper.Quote(opts.Schema) + "." + IdentifierCaseUpper.Quote(opts.Table),
+	}
+}
+
+func TestConnection_FoldIdentifiers(t *testing.T) {
+	r := require.New(t)
+
+	driver := &foldingDriver{}
+	c, err := NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionFoldIdentifiers: "true"},
+	}, &foldingAdapter{driver: driver})
+	r.NoError(err)
+
+	structure, err := c.GetStructureCtx(context.Background())
+	r.NoError(err)
+	r.Equal([]*Structure{
+		{
+			Name: "hr",
+			Type: StructureTypeNone,
+			Children: []*Structure{
+				{Name: "employees", Schema: "hr", Type: StructureTypeTable},
+				{Name: `"OrderItems"`, Schema: "hr", Type: StructureTypeTable},
+			},
+		},
+	}, structure)
+
+	// displayed names are sent back
+	_, err = c.GetColumns(&TableOptions{Schema: "hr", Table: "employees", Materialization: StructureTypeTable})
+	r.NoError(err)
+	_, err = c.GetColumns(&TableOptions{Schema: "hr", Table: `"OrderItems"`, Materialization: StructureTypeTable})
+	r.NoError(err)
+	r.Equal([]*TableOptions{
+		{Schema: "HR", Table: "EMPLOYEES", Materialization: StructureTypeTable},
+		{Schema: "HR", Table: "OrderItems", Materialization: StructureTypeTable},
+	}, driver.columns)
+
+	r.Equal(`SELECT * FROM HR."OrderItems"`, c.GetHelpers(&TableOptions{Schema: "hr", Table: `"OrderItems"`})["List"])
+
+	// names are kept without the option
+	c, err = NewConnection(&ConnectionParams{}, &foldingAdapter{driver: driver})
+	r.NoError(err)
+
+	structure, err = c.GetStructureCtx(context.Background())
+	r.NoError(err)
+	r.Equal("OrderItems", structure[0].Children[1].Name)
+	r.Equal(`SELECT * FROM HR."OrderItems"`, c.GetHelpers(&TableOptions{Schema: "HR", Table: "OrderItems"})["List"])
+
+	_, err = NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionFoldIdentifiers: "maybe"},
+	}, &foldingAdapter{driver: driver})
+	r.ErrorContains(err, `invalid value of option "fold_identifiers"`)
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Import runs the query on the source connection and loads its result into the table
+// of this connection, replacing the table if it already exists. This way results of
+// different connections can be joined locally (e.g. in a scratch database).
+// Returns the number of imported rows.
+func (c *Connection) Import(ctx context.Context, src *Connection, query, table string) (int, error) {
+	importer, ok := c.driver.(TableImporter)
+	if !ok {
+		return 0, ErrImportNotSupported
+	}
+
+	if strings.TrimSpace(table) == "" {
+		return 0, errors.New("empty table name")
+	}
+
+	if path, ok := queryFilePath(query); ok {
+		var err error
+		query, err = readQueryFile(path)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return 0, errors.New("empty query")
+	}
+
+	if src.requireWhere {
+		if err := checkWhere(query); err != nil {
+			return 0, err
+		}
+	}
+
+	rows, err := src.query(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("src.query: %w", err)
+	}
+
+	count, err := importer.ImportTable(ctx, table, rows)
+	if err != nil {
+		return 0, fmt.Errorf("importer.ImportTable: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// initialDriver is a driver with databases and schemas of the current database.
+type initialDriver struct {
+	sequenceDriver
+	database  string
+	databases []string
+	schema    string
+	schemas   []string
+	closed    bool
+}
+
+func (d *initialDriver) Close() { d.closed = true }
+
+func (d *initialDriver) ListDatabases() (string, []string, error) {
+	return d.database, d.databases, nil
+}
+
+func (d *initialDriver) SelectDatabase(name string) error {
+	d.database = name
+	// every database has its own schemas
+	d.schema = "public"
+	return nil
+}
+
+func (d *initialDriver) ListSchemas() (string, []string, error) { return d.schema, d.schemas, nil }
+
+func (d *initialDriver) SelectSchema(name string) error {
+	d.schema = name
+	return nil
+}
+
+type initialAdapter struct {
+	driver Driver
+}
+
+func (a *initialAdapter) Connect(_ string) (Driver, error) {
+	return a.driver, nil
+}
+
+func (a *initialAdapter) GetHelpers(_ *TableOptions) map[string]string {
+	return nil
+}
+
+func TestConnection_InitialDatabaseAndSchema(t *testing.T) {
+	r := require.New(t)
+
+	newDriver := func() *initialDriver {
+		return &initialDriver{
+			database:  "postgres",
+			databases: []string{"postgres", "shop"},
+			schema:    "public",
+			schemas:   []string{"public", "sales"},
+		}
+	}
+
+	driver := newDriver()
+	_, err := NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionDatabase: "shop", OptionSchema: "sales"},
+	}, &initialAdapter{driver: driver})
+	r.NoError(err)
+	r.Equal("shop", driver.database)
+	r.Equal("sales", driver.schema)
+
+	// the current database is kept
+	driver = newDriver()
+	driver.database = "unlisted"
+	_, err = NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionDatabase: "unlisted"},
+	}, &initialAdapter{driver: driver})
+	r.NoError(err)
+	r.Equal("unlisted", driver.database)
+
+	driver = newDriver()
+	_, err = NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionDatabase: "shop", OptionSchema: "missing"},
+	}, &initialAdapter{driver: driver})
+	r.ErrorContains(err, `invalid value of option "schema": schema "missing" does not exist`)
+	r.True(driver.closed)
+
+	_, err = NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionDatabase: "missing"},
+	}, &initialAdapter{driver: newDriver()})
+	r.ErrorContains(err, `invalid value of option "database": database "missing" does not exist`)
+
+	_, err = NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionSchema: "sales"},
+	}, &initialAdapter{driver: &sequenceDriver{}})
+	r.ErrorIs(err, ErrSchemaSwitchingNotSupported)
+}
@@ -0,0 +1,350 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Page describes a page of a paged query (see Connection.ExecutePage).
+type Page struct {
+	// Number is the zero-based number of the page.
+	Number int
+	Size   int
+	// Keyset is true if the page was selected by the key of the previous page
+	// and false if it was selected by offset.
+	Keyset bool
+	// Keys are the ordering columns (as written in the query) used for keyset pagination.
+	Keys []string
+	// After are the key values of the last row of the previous page.
+	After []any
+}
+
+// callPage is the paging state of a call, which is needed to fetch the next page.
+type callPage struct {
+	pager *pager
+	page  *Page
+}
+
+// pager builds queries for pages of a select statement. Pages are selected by the
+// ordering key (keyset pagination) if the query orders the rows of a single table
+// by a unique key, otherwise they are selected by offset.
+type pager struct {
+	query   string
+	size    int
+	dialect LimitDialect
+	// nil if pages are selected by offset
+	keyset *keysetQuery
+}
+
+// keysetQuery is a select statement which can be extended with a condition
+// on its ordering columns.
+type keysetQuery struct {
+	schema string
+	table  string
+	// key expressions as written in ORDER BY and their unquoted column names
+	keys  []string
+	names []string
+	desc  bool
+	// span of the WHERE condition (whereStart is -1 if there is none)
+	whereStart int
+	whereEnd   int
+	// position of the ORDER BY clause
+	orderStart int
+}
+
+var (
+	identPart  = `(?:[A-Za-z_][\w$]*|"(?:[^"]|"")+"|` + "`[^`]+`" + `|\[[^\]]+\])`
+	identRegex = regexp.MustCompile(`^` + identPart + `(?:\.` + identPart + `)*$`)
+	identParts = regexp.MustCompile(identPart)
+)
+
+// splitIdent splits a (possibly qualified) identifier into unquoted parts.
+func splitIdent(ident string) []string {
+	parts := identParts.FindAllString(ident, -1)
+	for i, part := range parts {
+		switch part[0] {
+		case '"':
+			parts[i] = strings.ReplaceAll(part[1:len(part)-1], `""`, `"`)
+		case '`', '[':
+			parts[i] = part[1 : len(part)-1]
+		}
+	}
+	return parts
+}
+
+// newPager checks that the query is a select statement without a row limit of its own.
+func newPager(query string, size int, dialect LimitDialect) (*pager, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid page size: %d", size)
+	}
+
+	stmt, ok := selectStatement(query)
+	if !ok {
+		return nil, errors.New("only single select statements can be paged")
+	}
+
+	tokens, _, _ := topLevelTokens(stmt)
+	for _, tok := range tokens {
+		switch tok.value {
+		case "LIMIT", "TOP", "FETCH", "OFFSET", "ROWNUM":
+			return nil, errors.New("query already limits its rows")
+		case "INTO", "FOR":
+			return nil, errors.New("only single select statements can be paged")
+		}
+	}
+
+	return &pager{
+		query:   stmt,
+		size:    size,
+		dialect: dialect,
+	}, nil
+}
+
+// parseKeysetQuery checks if the query selects from a single table and orders the rows
+// by plain columns in the same direction, so that keyset pagination can be used if the
+// columns form a unique key.
+func parseKeysetQuery(query string) (*keysetQuery, bool) {
+	tokens, end, ok := topLevelTokens(query)
+	if !ok || len(tokens) < 1 || tokens[0].value != "SELECT" {
+		return nil, false
+	}
+
+	from, where, order := -1, -1, -1
+	for i, tok := range tokens {
+		switch tok.value {
+		case "FROM":
+			if from >= 0 {
+				return nil, false
+			}
+			from = i
+		case "WHERE":
+			if from < 0 || where >= 0 || order >= 0 {
+				return nil, false
+			}
+			where = i
+		case "ORDER":
+			if from < 0 || order >= 0 || i+1 >= len(tokens) || tokens[i+1].value != "BY" {
+				return nil, false
+			}
+			order = i
+		case "JOIN", "GROUP", "HAVING", "WINDOW", "QUALIFY", "UNION", "INTERSECT", "EXCEPT", "MINUS",
+			"NULLS", "COLLATE", ";":
+			return nil, false
+		}
+	}
+	if from < 0 || order < 0 {
+		return nil, false
+	}
+
+	q := &keysetQuery{
+		whereStart: -1,
+		orderStart: tokens[order].start,
+	}
+
+	// the table (with an optional alias)
+	tableEnd := tokens[order].start
+	if where >= 0 {
+		tableEnd = tokens[where].start
+		q.whereStart = tokens[where].start
+		q.whereEnd = tokens[order].start
+	}
+	fields := strings.Fields(query[tokens[from].end:tableEnd])
+	if len(fields) < 1 || len(fields) > 3 || strings.Contains(query[tokens[from].end:tableEnd], ",") ||
+		!identRegex.MatchString(fields[0]) {
+		return nil, false
+	}
+	parts := splitIdent(fields[0])
+	q.table = parts[len(parts)-1]
+	if len(parts) > 1 {
+		q.schema = parts[len(parts)-2]
+	}
+
+	// ordering columns
+	for i, item := range strings.Split(query[tokens[order+1].end:end], ",") {
+		fields := strings.Fields(item)
+		if len(fields) < 1 || len(fields) > 2 || !identRegex.MatchString(fields[0]) {
+			return nil, false
+		}
+
+		desc := false
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+			case "DESC":
+				desc = true
+			default:
+				return nil, false
+			}
+		}
+		if i > 0 && desc != q.desc {
+			return nil, false
+		}
+		q.desc = desc
+
+		parts := splitIdent(fields[0])
+		q.keys = append(q.keys, fields[0])
+		q.names = append(q.names, parts[len(parts)-1])
+	}
+
+	return q, true
+}
+
+// coversUniqueKey checks if the ordering columns include all columns of any of the keys.
+func (q *keysetQuery) coversUniqueKey(keys [][]string) bool {
+	ordered := make(map[string]bool, len(q.names))
+	for _, name := range q.names {
+		ordered[strings.ToLower(name)] = true
+	}
+
+	for _, key := range keys {
+		covered := len(key) > 0
+		for _, col := range key {
+			if !ordered[strings.ToLower(col)] {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+
+	return false
+}
+
+// seek adds the condition that selects rows after the key values to the query.
+func (q *keysetQuery) seek(query string, after []any, dialect LimitDialect) string {
+	op := ">"
+	if q.desc {
+		op = "<"
+	}
+
+	literals := make([]string, len(after))
+	for i, val := range after {
+		literals[i] = keysetLiteral(val)
+	}
+
+	var cond string
+	switch {
+	case len(q.keys) == 1:
+		cond = fmt.Sprintf("%s %s %s", q.keys[0], op, literals[0])
+	case dialect == LimitDialectLimit:
+		cond = fmt.Sprintf("(%s) %s (%s)", strings.Join(q.keys, ", "), op, strings.Join(literals, ", "))
+	default:
+		// row value comparison is not supported, so it's expanded:
+		// a > 1 OR (a = 1 AND b > 2) ...
+		terms := make([]string, len(q.keys))
+		for i := range q.keys {
+			conds := make([]string, 0, i+1)
+			for j := 0; j < i; j++ {
+				conds = append(conds, fmt.Sprintf("%s = %s", q.keys[j], literals[j]))
+			}
+			conds = append(conds, fmt.Sprintf("%s %s %s", q.keys[i], op, literals[i]))
+			terms[i] = strings.Join(conds, " AND ")
+			if i > 0 {
+				terms[i] = "(" + terms[i] + ")"
+			}
+		}
+		cond = "(" + strings.Join(terms, " OR ") + ")"
+	}
+
+	if q.whereStart < 0 {
+		return query[:q.orderStart] + "WHERE " + cond + " " + query[q.orderStart:]
+	}
+
+	original := strings.TrimSpace(query[q.whereStart+len("WHERE") : q.whereEnd])
+	return query[:q.whereStart] + "WHERE (" + original + ") AND " + cond + " " + query[q.orderStart:]
+}
+
+// keysetLiteral formats the key value of a result as an sql literal.
+func keysetLiteral(val any) string {
+	if t, ok := val.(time.Time); ok {
+		return QuoteLiteral(t.Format("2006-01-02 15:04:05.999999999Z07:00"))
+	}
+	return QuoteLiteral(val)
+}
+
+// pageQuery returns the query of the page. Pages are selected by the key values
+// of the previous page's last row if they are known, otherwise by offset.
+func (p *pager) pageQuery(number int, after []any) string {
+	if p.keyset != nil && after != nil {
+		return p.keyset.seek(p.query, after, p.dialect) + p.limitClause(0)
+	}
+	return p.query + p.limitClause(number*p.size)
+}
+
+func (p *pager) limitClause(offset int) string {
+	switch p.dialect {
+	case LimitDialectTop:
+		// OFFSET ... FETCH requires ORDER BY
+		clause := ""
+		if !p.ordered() {
+			clause = " ORDER BY (SELECT NULL)"
+		}
+		return clause + fmt.Sprintf(" OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, p.size)
+	case LimitDialectFetchFirst:
+		if offset == 0 {
+			return fmt.Sprintf(" FETCH FIRST %d ROWS ONLY", p.size)
+		}
+		return fmt.Sprintf(" OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, p.size)
+	case LimitDialectLimit:
+		fallthrough
+	default:
+		if offset == 0 {
+			return fmt.Sprintf(" LIMIT %d", p.size)
+		}
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", p.size, offset)
+	}
+}
+
+func (p *pager) ordered() bool {
+	tokens, _, _ := topLevelTokens(p.query)
+	for i, tok := range tokens {
+		if tok.value == "ORDER" && i+1 < len(tokens) && tokens[i+1].value == "BY" {
+			return true
+		}
+	}
+	return false
+}
+
+// lastKey returns the key values of the last row of the finished page. Nil is returned if
+// the page is empty or the key columns are not part of the result (or any of the values
+// is NULL, which can't be compared).
+func (cp *callPage) lastKey(result *Result) []any {
+	if cp.pager.keyset == nil || result.Len() < 1 {
+		return nil
+	}
+
+	header := result.Header()
+	indexes := make([]int, len(cp.pager.keyset.names))
+	for i, name := range cp.pager.keyset.names {
+		indexes[i] = -1
+		for j, col := range header {
+			if strings.EqualFold(col, name) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil
+		}
+	}
+
+	rows, err := result.Rows(result.Len()-1, result.Len())
+	if err != nil || len(rows) < 1 {
+		return nil
+	}
+
+	after := make([]any, len(indexes))
+	for i, idx := range indexes {
+		if idx >= len(rows[0]) || rows[0][idx] == nil {
+			return nil
+		}
+		after[i] = rows[0][idx]
+	}
+
+	return after
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPager_PageQuery(t *testing.T) {
+	type testCase struct {
+		name     string
+		query    string
+		dialect  LimitDialect
+		keyset   bool
+		number   int
+		after    []any
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "first keyset page",
+			query:    "SELECT * FROM users ORDER BY id;",
+			keyset:   true,
+			expected: "SELECT * FROM users ORDER BY id LIMIT 10",
+		},
+		{
+			name:     "keyset page",
+			query:    "SELECT * FROM users ORDER BY id",
+			keyset:   true,
+			number:   1,
+			after:    []any{20},
+			expected: "SELECT * FROM users WHERE id > 20 ORDER BY id LIMIT 10",
+		},
+		{
+			name:     "keyset page with condition",
+			query:    "SELECT * FROM public.users u WHERE u.active OR u.admin ORDER BY u.name DESC, u.id DESC",
+			keyset:   true,
+			number:   1,
+			after:    []any{"O'Neil", 5},
+			expected: "SELECT * FROM public.users u WHERE (u.active OR u.admin) AND (u.name, u.id) < ('O''Neil', 5) ORDER BY u.name DESC, u.id DESC LIMIT 10",
+		},
+		{
+			name:     "keyset page without row values",
+			query:    "SELECT * FROM users ORDER BY name, id",
+			dialect:  LimitDialectTop,
+			keyset:   true,
+			number:   1,
+			after:    []any{"Bob", 5},
+			expected: "SELECT * FROM users WHERE (name > 'Bob' OR (name = 'Bob' AND id > 5)) ORDER BY name, id OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:     "offset page",
+			query:    "SELECT * FROM users ORDER BY id",
+			number:   2,
+			expected: "SELECT * FROM users ORDER BY id LIMIT 10 OFFSET 20",
+		},
+		{
+			name:     "offset fallback of keyset page",
+			query:    "SELECT * FROM users ORDER BY id",
+			keyset:   true,
+			number:   2,
+			expected: "SELECT * FROM users ORDER BY id LIMIT 10 OFFSET 20",
+		},
+		{
+			name:     "offset page with fetch first",
+			query:    "SELECT * FROM users ORDER BY id",
+			dialect:  LimitDialectFetchFirst,
+			number:   1,
+			expected: "SELECT * FROM users ORDER BY id OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			name:     "offset page of unordered query",
+			query:    "SELECT * FROM users",
+			dialect:  LimitDialectTop,
+			number:   1,
+			expected: "SELECT * FROM users ORDER BY (SELECT NULL) OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			p, err := newPager(tc.query, 10, tc.dialect)
+			r.NoError(err)
+			if tc.keyset {
+				keyset, ok := parseKeysetQuery(p.query)
+				r.True(ok)
+				p.keyset = keyset
+			}
+
+			r.Equal(tc.expected, p.pageQuery(tc.number, tc.after))
+		})
+	}
+}
+
+func TestParseKeysetQuery(t *testing.T) {
+	r := require.New(t)
+
+	q, ok := parseKeysetQuery(`SELECT * FROM "sales"."Orders" AS o ORDER BY o."Id"`)
+	r.True(ok)
+	r.Equal("sales", q.schema)
+	r.Equal("Orders", q.table)
+	r.Equal([]string{`o."Id"`}, q.keys)
+	r.True(q.coversUniqueKey([][]string{{"email"}, {"id"}}))
+	r.False(q.coversUniqueKey([][]string{{"id", "region"}}))
+
+	for _, query := range []string{
+		"SELECT * FROM users",
+		"SELECT * FROM users, roles ORDER BY id",
+		"SELECT * FROM users u JOIN roles r ON r.id = u.role ORDER BY u.id",
+		"SELECT country, count(*) FROM users GROUP BY country ORDER BY country",
+		"SELECT * FROM users ORDER BY lower(name)",
+		"SELECT * FROM users ORDER BY name ASC, id DESC",
+		"SELECT * FROM users ORDER BY id NULLS LAST",
+		"SELECT * FROM (SELECT * FROM users) u ORDER BY id",
+		"WITH u AS (SELECT * FROM users) SELECT * FROM u ORDER BY id",
+	} {
+		_, ok := parseKeysetQuery(query)
+		r.False(ok, query)
+	}
+
+	// queries with their own limits are not paged
+	_, err := newPager("SELECT * FROM users LIMIT 5", 10, LimitDialectLimit)
+	r.Error(err)
+	_, err = newPager("DELETE FROM users", 10, LimitDialectLimit)
+	r.Error(err)
+}
+
+// keyedDriver is a limitingDriver with a primary key on the "id" column.
+type keyedDriver struct {
+	limitingDriver
+}
+
+func (d *keyedDriver) UniqueKeys(_ *TableOptions) ([][]string, error) {
+	return [][]string{{"id"}}, nil
+}
+
+func TestConnection_ExecutePage(t *testing.T) {
+	r := require.New(t)
+
+	driver := &keyedDriver{}
+	c := &Connection{driver: driver}
+
+	call, err := c.ExecutePage("SELECT id FROM t ORDER BY id", 2, nil)
+	r.NoError(err)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Equal(&Page{Number: 0, Size: 2, Keyset: true, Keys: []string{"id"}}, call.GetPage())
+
+	// key of the last row
+	call, err = c.ExecuteNextPage(call, nil, nil)
+	r.NoError(err)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Equal(&Page{Number: 1, Size: 2, Keyset: true, Keys: []string{"id"}, After: []any{2}}, call.GetPage())
+	r.Equal("SELECT id FROM t WHERE id > 2 ORDER BY id LIMIT 2", driver.executed[1])
+
+	// provided key
+	call, err = c.ExecuteNextPage(call, []any{"x"}, nil)
+	r.NoError(err)
+	<-call.Done()
+	r.Equal("SELECT id FROM t WHERE id > 'x' ORDER BY id LIMIT 2", driver.executed[2])
+
+	_, err = c.ExecuteNextPage(call, []any{1, 2}, nil)
+	r.ErrorContains(err, "expected 1 key values")
+
+	// ordering by a column that is not unique
+	call, err = c.ExecutePage("SELECT id FROM t ORDER BY name", 2, nil)
+	r.NoError(err)
+	<-call.Done()
+	call, err = c.ExecuteNextPage(call, nil, nil)
+	r.NoError(err)
+	<-call.Done()
+	r.False(call.GetPage().Keyset)
+	r.Equal("SELECT id FROM t ORDER BY name LIMIT 2 OFFSET 2", driver.executed[4])
+
+	_, err = (&Connection{driver: &sequenceDriver{}}).ExecutePage("SELECT 1", 2, nil)
+	r.ErrorIs(err, ErrPagingNotSupported)
+}
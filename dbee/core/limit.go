@@ -0,0 +1,185 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// LimitDialect describes how a row limit is applied to a select statement.
+type LimitDialect int
+
+const (
+	// LimitDialectLimit appends "LIMIT n" (postgres, mysql, sqlite, ...)
+	LimitDialectLimit LimitDialect = iota
+	// LimitDialectTop inserts "TOP n" after the "SELECT" keyword (sql server)
+	LimitDialectTop
+	// LimitDialectFetchFirst appends "FETCH FIRST n ROWS ONLY" (oracle)
+	LimitDialectFetchFirst
+)
+
+// sqlToken is a single top-level keyword or punctuation of a statement.
+type sqlToken struct {
+	value string
+	start int
+	end   int
+}
+
+// topLevelTokens splits the query into tokens that are not part of string literals,
+// quoted identifiers, comments or parenthesized expressions (subqueries).
+// Word tokens are uppercased. codeEnd is the position after the last character
+// that is not a comment or whitespace. ok is false if the query could not be
+// tokenized (e.g. unterminated string).
+func topLevelTokens(query string) (tokens []sqlToken, codeEnd int, ok bool) {
+	depth := 0
+	i := 0
+	for i < len(query) {
+		ch := query[i]
+
+		switch {
+		// line comment
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens, codeEnd, true
+			}
+			i += end + 1
+			continue
+		// block comment
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, 0, false
+			}
+			i += end + 4
+			continue
+		// quoted strings and identifiers
+		case ch == '\'' || ch == '"' || ch == '`' || ch == '[':
+			closing := ch
+			if ch == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			for {
+				end := strings.IndexByte(query[j:], closing)
+				if end < 0 {
+					return nil, 0, false
+				}
+				j += end + 1
+				// doubled quote is an escaped quote
+				if closing != ']' && j < len(query) && query[j] == closing {
+					j++
+					continue
+				}
+				break
+			}
+			i = j
+			codeEnd = i
+			continue
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ';' && depth == 0:
+			tokens = append(tokens, sqlToken{value: ";", start: i, end: i + 1})
+		case isWordChar(ch):
+			j := i
+			for j < len(query) && isWordChar(query[j]) {
+				j++
+			}
+			if depth == 0 {
+				tokens = append(tokens, sqlToken{value: strings.ToUpper(query[i:j]), start: i, end: j})
+			}
+			i = j
+			codeEnd = i
+			continue
+		}
+
+		i++
+		if !unicode.IsSpace(rune(ch)) {
+			codeEnd = i
+		}
+	}
+
+	return tokens, codeEnd, true
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' || unicode.IsLetter(rune(ch)) || unicode.IsDigit(rune(ch))
+}
+
+// selectStatement checks if the query is a single select statement
+// (optionally with a CTE) and returns it without the trailing semicolon.
+func selectStatement(query string) (string, bool) {
+	tokens, end, ok := topLevelTokens(query)
+	if !ok || len(tokens) < 1 || (tokens[0].value != "SELECT" && tokens[0].value != "WITH") {
+		return "", false
+	}
+
+	for i, tok := range tokens {
+		if tok.value != ";" {
+			continue
+		}
+		if i != len(tokens)-1 {
+			return "", false
+		}
+		end = tok.start
+		tokens = tokens[:i]
+	}
+
+	// data modifying statements can start with a CTE too
+	if statementKind(tokens) != StatementKindSelect {
+		return "", false
+	}
+
+	return strings.TrimSpace(query[:end]), true
+}
+
+// injectLimit adds a row limit to simple top-level select statements.
+// Queries that are not plain selects (e.g. CTEs, set operations, multiple statements)
+// or that already limit their rows in any way are returned unchanged.
+func injectLimit(query string, limit int, dialect LimitDialect) string {
+	if limit <= 0 {
+		return query
+	}
+
+	tokens, end, ok := topLevelTokens(query)
+	if !ok || len(tokens) < 1 || tokens[0].value != "SELECT" {
+		return query
+	}
+
+	// trailing semicolon is allowed, others mean multiple statements
+	for i, tok := range tokens {
+		if tok.value != ";" {
+			continue
+		}
+		if i != len(tokens)-1 {
+			return query
+		}
+		end = tok.start
+	}
+
+	for _, tok := range tokens {
+		switch tok.value {
+		case "LIMIT", "TOP", "FETCH", "OFFSET", "ROWNUM", "INTO", "FOR",
+			"UNION", "INTERSECT", "EXCEPT", "MINUS":
+			return query
+		}
+	}
+
+	switch dialect {
+	case LimitDialectTop:
+		// insert after "SELECT [ALL | DISTINCT]"
+		pos := tokens[0].end
+		if len(tokens) > 1 && (tokens[1].value == "DISTINCT" || tokens[1].value == "ALL") {
+			pos = tokens[1].end
+		}
+		return query[:pos] + fmt.Sprintf(" TOP %d", limit) + query[pos:]
+	case LimitDialectFetchFirst:
+		return query[:end] + fmt.Sprintf(" FETCH FIRST %d ROWS ONLY", limit) + query[end:]
+	case LimitDialectLimit:
+		fallthrough
+	default:
+		return query[:end] + fmt.Sprintf(" LIMIT %d", limit) + query[end:]
+	}
+}
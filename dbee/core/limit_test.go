@@ -0,0 +1,190 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectLimit(t *testing.T) {
+	type testCase struct {
+		name     string
+		query    string
+		dialect  LimitDialect
+		expected string
+	}
+
+	testCases := []testCase{
+		// dialects
+		{
+			name:     "limit",
+			query:    "SELECT * FROM users",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT * FROM users LIMIT 100",
+		},
+		{
+			name:     "top",
+			query:    "select * from users",
+			dialect:  LimitDialectTop,
+			expected: "select TOP 100 * from users",
+		},
+		{
+			name:     "top after distinct",
+			query:    "SELECT DISTINCT name FROM users",
+			dialect:  LimitDialectTop,
+			expected: "SELECT DISTINCT TOP 100 name FROM users",
+		},
+		{
+			name:     "fetch first",
+			query:    "SELECT * FROM users ORDER BY id",
+			dialect:  LimitDialectFetchFirst,
+			expected: "SELECT * FROM users ORDER BY id FETCH FIRST 100 ROWS ONLY",
+		},
+		// placement
+		{
+			name:     "before trailing semicolon",
+			query:    "SELECT * FROM users;\n",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT * FROM users LIMIT 100;\n",
+		},
+		{
+			name:     "before trailing comment",
+			query:    "SELECT * FROM users -- all users",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT * FROM users LIMIT 100 -- all users",
+		},
+		{
+			name:     "limit in subquery is ignored",
+			query:    "SELECT * FROM (SELECT * FROM users LIMIT 5) u",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT * FROM (SELECT * FROM users LIMIT 5) u LIMIT 100",
+		},
+		{
+			name:     "keywords in strings are ignored",
+			query:    "SELECT 'limit 5' AS \"top\" FROM users",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT 'limit 5' AS \"top\" FROM users LIMIT 100",
+		},
+		// skipped
+		{
+			name:     "already has limit",
+			query:    "SELECT * FROM users LIMIT 5",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT * FROM users LIMIT 5",
+		},
+		{
+			name:     "already has top",
+			query:    "SELECT TOP 5 * FROM users",
+			dialect:  LimitDialectTop,
+			expected: "SELECT TOP 5 * FROM users",
+		},
+		{
+			name:     "already has fetch first",
+			query:    "SELECT * FROM users FETCH FIRST 5 ROWS ONLY",
+			dialect:  LimitDialectFetchFirst,
+			expected: "SELECT * FROM users FETCH FIRST 5 ROWS ONLY",
+		},
+		{
+			name:     "cte",
+			query:    "WITH u AS (SELECT * FROM users) SELECT * FROM u",
+			dialect:  LimitDialectLimit,
+			expected: "WITH u AS (SELECT * FROM users) SELECT * FROM u",
+		},
+		{
+			name:     "not a select",
+			query:    "DELETE FROM users",
+			dialect:  LimitDialectLimit,
+			expected: "DELETE FROM users",
+		},
+		{
+			name:     "set operation",
+			query:    "SELECT id FROM a UNION SELECT id FROM b",
+			dialect:  LimitDialectTop,
+			expected: "SELECT id FROM a UNION SELECT id FROM b",
+		},
+		{
+			name:     "multiple statements",
+			query:    "SELECT * FROM a; SELECT * FROM b",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT * FROM a; SELECT * FROM b",
+		},
+		{
+			name:     "unterminated string",
+			query:    "SELECT 'abc FROM users",
+			dialect:  LimitDialectLimit,
+			expected: "SELECT 'abc FROM users",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, injectLimit(tc.query, 100, tc.dialect))
+		})
+	}
+}
+
+func TestSelectStatement(t *testing.T) {
+	r := require.New(t)
+
+	query, ok := selectStatement("  select * from users; -- all users\n")
+	r.True(ok)
+	r.Equal("select * from users", query)
+
+	query, ok = selectStatement("WITH x AS (SELECT 1) SELECT * FROM x")
+	r.True(ok)
+	r.Equal("WITH x AS (SELECT 1) SELECT * FROM x", query)
+
+	for _, q := range []string{
+		"",
+		"DELETE FROM users",
+		"WITH old AS (SELECT id FROM users) DELETE FROM users WHERE id IN (SELECT id FROM old)",
+		"SELECT 1; SELECT 2",
+		"SELECT 'unterminated",
+	} {
+		_, ok := selectStatement(q)
+		r.False(ok, q)
+	}
+}
+
+// limitingDriver records executed queries and limits select statements.
+type limitingDriver struct {
+	executed []string
+}
+
+func (d *limitingDriver) Query(_ context.Context, query string) (ResultStream, error) {
+	d.executed = append(d.executed, query)
+	return &rowsStream{header: Header{"id"}, rows: []Row{{1}, {2}}}, nil
+}
+
+func (d *limitingDriver) Structure() ([]*Structure, error)           { return nil, nil }
+func (d *limitingDriver) Columns(_ *TableOptions) ([]*Column, error) { return nil, nil }
+func (d *limitingDriver) Close()                                     {}
+func (d *limitingDriver) LimitDialect() LimitDialect                 { return LimitDialectLimit }
+
+func TestConnection_LimitMeta(t *testing.T) {
+	r := require.New(t)
+
+	driver := &limitingDriver{}
+	c := &Connection{driver: driver, defaultLimit: 2}
+
+	call := c.Execute("SELECT id FROM t", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Equal("SELECT id FROM t LIMIT 2", driver.executed[0])
+
+	result, err := call.GetResult()
+	r.NoError(err)
+	r.Equal(2, result.Meta().Limit)
+
+	// limit is not reported if the query was run as written
+	for _, query := range []string{"SELECT id FROM t LIMIT 5", "-- dbee: limit=0\nSELECT id FROM t"} {
+		call = c.Execute(query, nil)
+		<-call.Done()
+		r.NoError(call.Err())
+
+		result, err = call.GetResult()
+		r.NoError(err)
+		r.Zero(result.Meta().Limit, query)
+	}
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskStyle determines how masked values are rendered.
+type MaskStyle int
+
+const (
+	// MaskStyleLength replaces every character with an asterisk, so the length is preserved.
+	MaskStyleLength MaskStyle = iota
+	// MaskStyleFixed replaces the value with a fixed number of asterisks,
+	// so the length is not revealed.
+	MaskStyleFixed
+)
+
+// maskFixed is the mask of MaskStyleFixed.
+const maskFixed = "****"
+
+var maskEmailRegex = regexp.MustCompile(`^([^@\s]+)@([^@\s]+\.[^@\s]+)$`)
+
+// MaskPolicy selects sensitive columns whose values are masked in the output
+// (e.g. when sharing the screen).
+type MaskPolicy struct {
+	names    map[string]struct{}
+	patterns []*regexp.Regexp
+	style    MaskStyle
+}
+
+// ParseMaskPolicy creates a mask policy from column patterns. A pattern is either
+// a column name (matched case insensitively) or a regular expression enclosed in slashes
+// (e.g. "/^(ssn|phone)$/"). Style is "length" (default) or "fixed".
+// If there are no patterns, nil is returned, which means values are not masked.
+func ParseMaskPolicy(patterns []string, style string) (*MaskPolicy, error) {
+	mp := &MaskPolicy{
+		names: make(map[string]struct{}),
+	}
+
+	switch strings.ToLower(style) {
+	case "", "length":
+		mp.style = MaskStyleLength
+	case "fixed":
+		mp.style = MaskStyleFixed
+	default:
+		return nil, fmt.Errorf("unknown mask style: %q", style)
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			mp.patterns = append(mp.patterns, re)
+			continue
+		}
+
+		mp.names[strings.ToLower(pattern)] = struct{}{}
+	}
+
+	if len(mp.names) == 0 && len(mp.patterns) == 0 {
+		return nil, nil
+	}
+
+	return mp, nil
+}
+
+// Matches checks if the column is masked.
+func (mp *MaskPolicy) Matches(column string) bool {
+	if mp == nil {
+		return false
+	}
+
+	if _, ok := mp.names[strings.ToLower(column)]; ok {
+		return true
+	}
+	for _, re := range mp.patterns {
+		if re.MatchString(column) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mask masks the value according to the style. Email addresses keep the first character
+// and the domain (e.g. "j***@x.com"), other values are masked fully. NULL values are kept.
+func (mp *MaskPolicy) Mask(val any) any {
+	if val == nil || mp == nil {
+		return val
+	}
+
+	var s string
+	switch v := val.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprint(val)
+	}
+
+	if m := maskEmailRegex.FindStringSubmatch(s); m != nil {
+		return mp.maskEmail(m[1], m[2])
+	}
+
+	return mp.mask(s)
+}
+
+func (mp *MaskPolicy) mask(s string) string {
+	if mp.style == MaskStyleFixed {
+		return maskFixed
+	}
+	return strings.Repeat("*", utf8.RuneCountInString(s))
+}
+
+func (mp *MaskPolicy) maskEmail(local, domain string) string {
+	first, size := utf8.DecodeRuneInString(local)
+	if size == len(local) {
+		// nothing would be masked
+		return mp.mask(local) + "@" + domain
+	}
+
+	rest := "***"
+	if mp.style == MaskStyleLength {
+		rest = strings.Repeat("*", utf8.RuneCountInString(local[size:]))
+	}
+	return string(first) + rest + "@" + domain
+}
+
+var _ Formatter = (*maskFormatter)(nil)
+
+// maskFormatter masks values of sensitive columns before passing them to the wrapped formatter.
+type maskFormatter struct {
+	formatter Formatter
+	policy    *MaskPolicy
+}
+
+// NewMaskFormatter wraps the formatter, so that values of columns matched by the policy are masked.
+// If the policy is nil, the original formatter is returned.
+func NewMaskFormatter(formatter Formatter, policy *MaskPolicy) Formatter {
+	if policy == nil {
+		return formatter
+	}
+
+	return &maskFormatter{
+		formatter: formatter,
+		policy:    policy,
+	}
+}
+
+func (mf *maskFormatter) Format(header Header, rows []Row, opts *FormatterOptions) ([]byte, error) {
+	masked := make([]bool, len(header))
+	anyMasked := false
+	for i, col := range header {
+		masked[i] = mf.policy.Matches(col)
+		anyMasked = anyMasked || masked[i]
+	}
+	if !anyMasked {
+		return mf.formatter.Format(header, rows, opts)
+	}
+
+	// copy rows, as they are shared with the cached result
+	formatted := make([]Row, len(rows))
+	for i, row := range rows {
+		formatted[i] = make(Row, len(row))
+		for j, val := range row {
+			if j < len(masked) && masked[j] {
+				val = mf.policy.Mask(val)
+			}
+			formatted[i][j] = val
+		}
+	}
+
+	return mf.formatter.Format(header, formatted, opts)
+}
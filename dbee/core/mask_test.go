@@ -0,0 +1,83 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestMaskPolicy_Mask(t *testing.T) {
+	tests := []struct {
+		style string
+		value any
+		want  any
+	}{
+		{style: "length", value: "john@example.com", want: "j***@example.com"},
+		{style: "length", value: "žana.novak@x.si", want: "ž*********@x.si"},
+		{style: "length", value: "j@x.com", want: "*@x.com"},
+		{style: "length", value: "secret", want: "******"},
+		{style: "length", value: []byte("šifra"), want: "*****"},
+		{style: "length", value: 123456789, want: "*********"},
+		{style: "length", value: "", want: ""},
+		{style: "length", value: "not an @ email", want: "**************"},
+		{style: "fixed", value: "john.doe@example.com", want: "j***@example.com"},
+		{style: "fixed", value: "secret", want: "****"},
+		{style: "fixed", value: "", want: "****"},
+		{style: "fixed", value: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		mp, err := core.ParseMaskPolicy([]string{"col"}, tt.style)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, mp.Mask(tt.value), "%s: %v", tt.style, tt.value)
+	}
+}
+
+func TestParseMaskPolicy(t *testing.T) {
+	r := require.New(t)
+
+	mp, err := core.ParseMaskPolicy([]string{" Email ", "/^(ssn|phone)/", ""}, "")
+	r.NoError(err)
+	r.True(mp.Matches("email"))
+	r.True(mp.Matches("EMAIL"))
+	r.True(mp.Matches("phone_number"))
+	r.False(mp.Matches("user_email"))
+	r.False(mp.Matches("id"))
+
+	// no patterns
+	mp, err = core.ParseMaskPolicy([]string{" "}, "fixed")
+	r.NoError(err)
+	r.Nil(mp)
+	r.False(mp.Matches("email"))
+	r.Equal("x", mp.Mask("x"))
+
+	_, err = core.ParseMaskPolicy([]string{"email"}, "stars")
+	r.Error(err)
+	_, err = core.ParseMaskPolicy([]string{"/(/"}, "")
+	r.Error(err)
+}
+
+func TestNewMaskFormatter(t *testing.T) {
+	r := require.New(t)
+
+	header := core.Header{"id", "email", "ssn"}
+	rows := []core.Row{{1, "ana@x.com", "123-45-6789"}, {2, nil, "987"}}
+	opts := &core.FormatterOptions{SchemaType: core.SchemaFul}
+
+	mp, err := core.ParseMaskPolicy([]string{"email", "/^ssn$/"}, "length")
+	r.NoError(err)
+
+	out, err := core.NewMaskFormatter(format.NewCSV(), mp).Format(header, rows, opts)
+	r.NoError(err)
+	r.Equal("id,email,ssn\n1,a**@x.com,***********\n2,<nil>,***\n", string(out))
+
+	// original rows are not modified
+	r.Equal("ana@x.com", rows[0][1])
+
+	// nil policy returns the original formatter
+	csv := format.NewCSV()
+	r.Equal(core.Formatter(csv), core.NewMaskFormatter(csv, nil))
+}
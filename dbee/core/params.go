@@ -0,0 +1,354 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PlaceholderStyle describes how a driver expects positional placeholders
+// of parameterized queries.
+type PlaceholderStyle int
+
+const (
+	// PlaceholderQuestion is "?" (mysql, sqlite, ...)
+	PlaceholderQuestion PlaceholderStyle = iota
+	// PlaceholderDollar is "$1" (postgres)
+	PlaceholderDollar
+	// PlaceholderAtP is "@p1" (sql server)
+	PlaceholderAtP
+	// PlaceholderColon is ":1" (oracle)
+	PlaceholderColon
+)
+
+func (s PlaceholderStyle) placeholder(n int) string {
+	switch s {
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(n)
+	case PlaceholderAtP:
+		return "@p" + strconv.Itoa(n)
+	case PlaceholderColon:
+		return ":" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// QueryParams are values of query placeholders. Positional values are referenced
+// by "$1" or "?" (the n-th "?" is the n-th value) and named ones by ":name" or "@name".
+type QueryParams struct {
+	Positional []any
+	Named      map[string]any
+}
+
+// ParseQueryParams parses json encoded parameters. An array holds positional values,
+// while keys of an object are either names or (1-based) positions, so both styles can be
+// mixed (e.g. {"1": 10, "name": "x"}). Values can be numbers, strings, booleans or null.
+func ParseQueryParams(data string) (*QueryParams, error) {
+	params := &QueryParams{Named: make(map[string]any)}
+	if strings.TrimSpace(data) == "" {
+		return params, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	switch v := raw.(type) {
+	case nil:
+	case []any:
+		for i, val := range v {
+			arg, err := paramValue(val)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %d: %w", i+1, err)
+			}
+			params.Positional = append(params.Positional, arg)
+		}
+	case map[string]any:
+		for key, val := range v {
+			arg, err := paramValue(val)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %q: %w", key, err)
+			}
+
+			name := strings.TrimLeft(key, ":@$")
+			n, err := strconv.Atoi(name)
+			if err != nil {
+				params.Named[name] = arg
+				continue
+			}
+			if n < 1 {
+				return nil, fmt.Errorf("invalid parameter position: %q", key)
+			}
+			for len(params.Positional) < n {
+				params.Positional = append(params.Positional, missingParam{})
+			}
+			params.Positional[n-1] = arg
+		}
+	default:
+		return nil, errors.New("parameters must be a json array or object")
+	}
+
+	return params, nil
+}
+
+// missingParam marks positions without a value in sparse positional parameters.
+type missingParam struct{}
+
+// paramValue converts a decoded json value to a query argument.
+func paramValue(val any) (any, error) {
+	switch v := val.(type) {
+	case nil, string, bool:
+		return v, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", v)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value type: %T", val)
+}
+
+// bindParams rewrites all placeholders of the query to positional placeholders of the
+// style and returns arguments in the order of placeholders. Named parameters used more
+// than once are passed once per occurrence. Placeholders in string literals, quoted
+// identifiers and comments are ignored. All provided parameters must be used.
+func bindParams(query string, params *QueryParams, style PlaceholderStyle) (string, []any, error) {
+	var (
+		out   strings.Builder
+		args  []any
+		next  int // position of the next "?"
+		used  = make(map[string]bool)
+		usedN = make(map[int]bool)
+	)
+
+	add := func(arg any) {
+		args = append(args, arg)
+		out.WriteString(style.placeholder(len(args)))
+	}
+	positional := func(n int) error {
+		if n < 1 || n > len(params.Positional) {
+			return fmt.Errorf("missing value for parameter %d", n)
+		}
+		arg := params.Positional[n-1]
+		if _, ok := arg.(missingParam); ok {
+			return fmt.Errorf("missing value for parameter %d", n)
+		}
+		usedN[n] = true
+		add(arg)
+		return nil
+	}
+
+	i := 0
+	for i < len(query) {
+		ch := query[i]
+
+		switch {
+		// comments, strings and quoted identifiers are copied as they are
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			out.WriteString(query[i : i+end])
+			i += end
+			continue
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return "", nil, errors.New("unterminated comment")
+			}
+			out.WriteString(query[i : i+end+4])
+			i += end + 4
+			continue
+		case ch == '\'' || ch == '"' || ch == '`':
+			j := i + 1
+			for {
+				end := strings.IndexByte(query[j:], ch)
+				if end < 0 {
+					return "", nil, errors.New("unterminated quoted string")
+				}
+				j += end + 1
+				// doubled quote is an escaped quote
+				if j < len(query) && query[j] == ch {
+					j++
+					continue
+				}
+				break
+			}
+			out.WriteString(query[i:j])
+			i = j
+			continue
+
+		case ch == '?':
+			next++
+			if err := positional(next); err != nil {
+				return "", nil, err
+			}
+			i++
+			continue
+		case ch == '$':
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			if j > i+1 {
+				n, _ := strconv.Atoi(query[i+1 : j])
+				if err := positional(n); err != nil {
+					return "", nil, err
+				}
+				i = j
+				continue
+			}
+			// dollar quoted string ($$...$$ or $tag$...$tag$)
+			for j < len(query) && query[j] != '$' && isWordChar(query[j]) {
+				j++
+			}
+			if j < len(query) && query[j] == '$' {
+				tag := query[i : j+1]
+				end := strings.Index(query[j+1:], tag)
+				if end < 0 {
+					return "", nil, errors.New("unterminated dollar quoted string")
+				}
+				out.WriteString(query[i : j+1+end+len(tag)])
+				i = j + 1 + end + len(tag)
+				continue
+			}
+		case ch == ':' || ch == '@':
+			// casts ("::") and system variables ("@@")
+			if i+1 < len(query) && query[i+1] == ch {
+				out.WriteString(query[i : i+2])
+				i += 2
+				continue
+			}
+			j := i + 1
+			for j < len(query) && isWordChar(query[j]) {
+				j++
+			}
+			// names can't start with a digit or follow a word (e.g. "a:b")
+			if j > i+1 && !isDigit(query[i+1]) && (i == 0 || !isWordChar(query[i-1])) {
+				name := query[i+1 : j]
+				arg, ok := params.Named[name]
+				if !ok {
+					return "", nil, fmt.Errorf("missing value for parameter %q", name)
+				}
+				used[name] = true
+				add(arg)
+				i = j
+				continue
+			}
+		}
+
+		out.WriteByte(ch)
+		i++
+	}
+
+	for name := range params.Named {
+		if !used[name] {
+			return "", nil, fmt.Errorf("unused parameter: %q", name)
+		}
+	}
+	for n, arg := range params.Positional {
+		if _, ok := arg.(missingParam); !ok && !usedN[n+1] {
+			return "", nil, fmt.Errorf("unused parameter: %d", n+1)
+		}
+	}
+
+	return out.String(), args, nil
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// ExecuteParams starts executing the query with values bound to its placeholders
+// (see ParseQueryParams for the format of params). Values are sent separately from
+// the query, so they can't alter the statement (sql injection).
+func (c *Connection) ExecuteParams(query, params string, onEvent func(CallState, *Call)) (*Call, error) {
+	querier, ok := c.driver.(ParamQuerier)
+	if !ok {
+		return nil, ErrQueryParamsNotSupported
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty query")
+	}
+	if c.requireWhere {
+		if err := checkWhere(query); err != nil {
+			return nil, err
+		}
+	}
+
+	parsed, err := ParseQueryParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	limited, applied := c.limitQuery(query, c.defaultLimit)
+	bound, args, err := bindParams(limited, parsed, querier.PlaceholderStyle())
+	if err != nil {
+		return nil, err
+	}
+
+	exec := func(ctx context.Context) (ResultStream, error) {
+		rows, err := querier.QueryParams(ctx, bound, args)
+		if err != nil || applied <= 0 {
+			return rows, err
+		}
+		return &limitStream{ResultStream: rows, limit: applied}, nil
+	}
+
+	logged := query
+	if c.formatHistory {
+		logged = FormatSQL(query)
+	}
+	if compact := new(bytes.Buffer); json.Compact(compact, []byte(params)) == nil && compact.Len() > 0 {
+		logged += "\n-- params: " + compact.String()
+	}
+
+	return newCallFromExecutor(exec, logged, NewResult(c.memoryRows), onEvent), nil
+}
+
+// QuoteLiteral formats the value as a SQL literal, for values which have to be pasted
+// into the query text instead of being bound. Strings are single quoted with quotes
+// doubled, nil is NULL and lists become comma separated literals (e.g. for "IN (...)").
+func QuoteLiteral(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return QuoteLiteral(string(v))
+	case []any:
+		literals := make([]string, len(v))
+		for i, item := range v {
+			literals[i] = QuoteLiteral(item)
+		}
+		return strings.Join(literals, ", ")
+	}
+	return QuoteLiteral(fmt.Sprint(val))
+}
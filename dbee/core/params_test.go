@@ -0,0 +1,136 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryParams(t *testing.T) {
+	r := require.New(t)
+
+	params, err := ParseQueryParams(`[1, 2.5, "a", true, null]`)
+	r.NoError(err)
+	r.Equal([]any{int64(1), 2.5, "a", true, nil}, params.Positional)
+	r.Empty(params.Named)
+
+	params, err = ParseQueryParams(`{"2": "b", ":name": 9007199254740993, "flag": false}`)
+	r.NoError(err)
+	r.Equal([]any{missingParam{}, "b"}, params.Positional)
+	r.Equal(map[string]any{"name": int64(9007199254740993), "flag": false}, params.Named)
+
+	params, err = ParseQueryParams("")
+	r.NoError(err)
+	r.Empty(params.Positional)
+
+	for _, data := range []string{`{"a": [1]}`, `[{"a": 1}]`, `"abc"`, `{"0": 1}`, `[1`} {
+		_, err := ParseQueryParams(data)
+		r.Error(err, data)
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	type testCase struct {
+		name          string
+		query         string
+		params        string
+		style         PlaceholderStyle
+		expectedQuery string
+		expectedArgs  []any
+	}
+
+	testCases := []testCase{
+		{
+			name:          "positional dollar",
+			query:         "SELECT * FROM t WHERE a = $2 AND b = $1",
+			params:        `["x", 10]`,
+			style:         PlaceholderDollar,
+			expectedQuery: "SELECT * FROM t WHERE a = $1 AND b = $2",
+			expectedArgs:  []any{int64(10), "x"},
+		},
+		{
+			name:          "positional question",
+			query:         "SELECT * FROM t WHERE a = ? AND b = ?",
+			params:        `[1, null]`,
+			style:         PlaceholderQuestion,
+			expectedQuery: "SELECT * FROM t WHERE a = ? AND b = ?",
+			expectedArgs:  []any{int64(1), nil},
+		},
+		{
+			name:          "named to positional",
+			query:         "SELECT * FROM t WHERE a = :id OR b = @id OR c = :name",
+			params:        `{"id": 1, "name": "x"}`,
+			style:         PlaceholderAtP,
+			expectedQuery: "SELECT * FROM t WHERE a = @p1 OR b = @p2 OR c = @p3",
+			expectedArgs:  []any{int64(1), int64(1), "x"},
+		},
+		{
+			name:          "mixed named and positional",
+			query:         "SELECT * FROM t WHERE a = $1 AND b = :name AND c = ?",
+			params:        `{"1": 5, "name": "x"}`,
+			style:         PlaceholderColon,
+			expectedQuery: "SELECT * FROM t WHERE a = :1 AND b = :2 AND c = :3",
+			expectedArgs:  []any{int64(5), "x", int64(5)},
+		},
+		{
+			name:          "ignored placeholders",
+			query:         "SELECT a::text, @@version, '?:x $1', \"@y\", $$ $1 $$ FROM t -- :z\nWHERE b = $1 /* ? */",
+			params:        `[true]`,
+			style:         PlaceholderDollar,
+			expectedQuery: "SELECT a::text, @@version, '?:x $1', \"@y\", $$ $1 $$ FROM t -- :z\nWHERE b = $1 /* ? */",
+			expectedArgs:  []any{true},
+		},
+		{
+			name:          "no params",
+			query:         "SELECT 1",
+			style:         PlaceholderQuestion,
+			expectedQuery: "SELECT 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			params, err := ParseQueryParams(tc.params)
+			r.NoError(err)
+
+			query, args, err := bindParams(tc.query, params, tc.style)
+			r.NoError(err)
+			r.Equal(tc.expectedQuery, query)
+			r.Equal(tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestBindParams_Errors(t *testing.T) {
+	r := require.New(t)
+
+	for _, tc := range []struct{ query, params string }{
+		{"SELECT $2", `[1]`},
+		{"SELECT ?, ?", `[1]`},
+		{"SELECT :missing", `{}`},
+		{"SELECT $1", `[1, 2]`},
+		{"SELECT :a", `{"a": 1, "b": 2}`},
+		{"SELECT $2", `{"2": 1, "3": 2}`},
+		{"SELECT 'unterminated", `[]`},
+	} {
+		params, err := ParseQueryParams(tc.params)
+		r.NoError(err)
+
+		_, _, err = bindParams(tc.query, params, PlaceholderDollar)
+		r.Error(err, tc.query)
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("NULL", QuoteLiteral(nil))
+	r.Equal("TRUE", QuoteLiteral(true))
+	r.Equal("42", QuoteLiteral(int64(42)))
+	r.Equal("1.5", QuoteLiteral(1.5))
+	r.Equal("'it''s'", QuoteLiteral("it's"))
+	r.Equal("'x'", QuoteLiteral([]byte("x")))
+	r.Equal("1, 'a', NULL", QuoteLiteral([]any{int64(1), "a", nil}))
+}
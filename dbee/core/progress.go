@@ -0,0 +1,57 @@
+package core
+
+import "sync"
+
+// Progress of a running query as reported by the server (totals since the query started).
+type Progress struct {
+	RowsRead  uint64
+	BytesRead uint64
+	// TotalRows is an estimate of rows the query has to read (zero if unknown).
+	TotalRows    uint64
+	RowsWritten  uint64
+	BytesWritten uint64
+	// Stage is what the query is waiting for (e.g. "engine launching"), empty if unknown.
+	Stage string
+}
+
+// progressTracker accumulates progress increments reported by the driver.
+// Drivers report progress while the result is streamed, so it's guarded
+// by a mutex and polled with get.
+type progressTracker struct {
+	mu       sync.Mutex
+	progress Progress
+	reported bool
+}
+
+// add adds the increment to the totals.
+func (t *progressTracker) add(p *Progress) {
+	if p == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.RowsRead += p.RowsRead
+	t.progress.BytesRead += p.BytesRead
+	t.progress.TotalRows += p.TotalRows
+	t.progress.RowsWritten += p.RowsWritten
+	t.progress.BytesWritten += p.BytesWritten
+	// stages replace each other
+	if p.Stage != "" {
+		t.progress.Stage = p.Stage
+	}
+	t.reported = true
+}
+
+// get returns a copy of the totals or nil if no progress was reported yet.
+func (t *progressTracker) get() *Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.reported {
+		return nil
+	}
+	p := t.progress
+	return &p
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// progressDriver reports a progress increment for every retrieved row.
+type progressDriver struct {
+	limitingDriver
+	settings map[string]string
+}
+
+func (d *progressDriver) QueryProgress(_ context.Context, query string, settings map[string]string, onProgress func(*Progress)) (ResultStream, error) {
+	d.executed = append(d.executed, query)
+	d.settings = settings
+	onProgress(&Progress{TotalRows: 3})
+	return &progressStream{
+		rowsStream: rowsStream{header: Header{"id"}, rows: []Row{{1}, {2}, {3}}},
+		onProgress: onProgress,
+	}, nil
+}
+
+type progressStream struct {
+	rowsStream
+	onProgress func(*Progress)
+}
+
+func (s *progressStream) Next() (Row, error) {
+	s.onProgress(&Progress{RowsRead: 1, BytesRead: 8})
+	return s.rowsStream.Next()
+}
+
+func TestProgressTracker(t *testing.T) {
+	r := require.New(t)
+
+	tracker := &progressTracker{}
+	r.Nil(tracker.get())
+
+	// progress is polled while it's reported
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tracker.add(&Progress{RowsRead: 1, BytesRead: 10, RowsWritten: 2})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if p := tracker.get(); p != nil && p.BytesRead != p.RowsRead*10 {
+					t.Errorf("inconsistent progress: %+v", p)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	r.Equal(&Progress{RowsRead: 400, BytesRead: 4000, RowsWritten: 800}, tracker.get())
+
+	// the last reported stage is kept
+	tracker.add(&Progress{Stage: "engine launching"})
+	tracker.add(&Progress{RowsRead: 1})
+	r.Equal("engine launching", tracker.get().Stage)
+	tracker.add(&Progress{Stage: "running"})
+	r.Equal("running", tracker.get().Stage)
+}
+
+func TestConnection_ExecuteProgress(t *testing.T) {
+	r := require.New(t)
+
+	driver := &progressDriver{}
+	c := &Connection{driver: driver}
+
+	call := c.Execute("-- dbee: setting.async_insert=1\nSELECT id FROM t", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Empty(call.GetWarnings())
+	r.Equal(map[string]string{"async_insert": "1"}, driver.settings)
+	r.Equal(&Progress{RowsRead: 3, BytesRead: 24, TotalRows: 3}, call.GetProgress())
+
+	// queries without settings still report progress, but don't pass any settings
+	driver = &progressDriver{}
+	c = &Connection{driver: driver}
+	call = c.Execute("SELECT id FROM t", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Nil(driver.settings)
+	r.Equal(&Progress{RowsRead: 3, BytesRead: 24, TotalRows: 3}, call.GetProgress())
+
+	// invalid names are not passed to the driver
+	call = c.Execute("-- dbee: setting.max_threads=2 setting.x;DROP=1\nSELECT id FROM t", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Equal(map[string]string{"max_threads": "2"}, driver.settings)
+	r.Equal([]string{`invalid setting directive: "setting.x;drop"`}, call.GetWarnings())
+
+	// drivers without progress ignore settings
+	call = (&Connection{driver: &limitingDriver{}}).Execute("-- dbee: setting.async_insert=1\nSELECT id FROM t", nil)
+	<-call.Done()
+	r.NoError(call.Err())
+	r.Nil(call.GetProgress())
+	r.Equal([]string{"query settings are not supported by the driver"}, call.GetWarnings())
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// queryFilePath returns the path if the query references a file
+// instead of being an inline query. Supported forms are
+// "file:///path/to/query.sql" and "@/path/to/query.sql".
+func queryFilePath(query string) (string, bool) {
+	query = strings.TrimSpace(query)
+
+	// inline queries can span multiple lines, paths can't
+	if strings.ContainsAny(query, "\r\n") {
+		return "", false
+	}
+
+	var path string
+	switch {
+	case strings.HasPrefix(query, "file://"):
+		path = strings.TrimPrefix(query, "file://")
+	case strings.HasPrefix(query, "@"):
+		path = strings.TrimPrefix(query, "@")
+	default:
+		return "", false
+	}
+
+	if path == "" {
+		return "", false
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+
+	return path, true
+}
+
+// readQueryFile reads the query from file. The whole content is executed
+// as a single query, the same way as running the whole file from the editor.
+func readQueryFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("query file does not exist: %q", path)
+		}
+		return "", fmt.Errorf("failed reading query file: %w", err)
+	}
+
+	// byte order mark is allowed, other encodings are not
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(content) {
+		return "", fmt.Errorf("query file is not valid UTF-8: %q", path)
+	}
+
+	return string(content), nil
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFilePath(t *testing.T) {
+	r := require.New(t)
+
+	home, err := os.UserHomeDir()
+	r.NoError(err)
+
+	testCases := []struct {
+		input    string
+		expected string
+		isFile   bool
+	}{
+		{"file:///tmp/query.sql", "/tmp/query.sql", true},
+		{"  @/tmp/query.sql\n", "/tmp/query.sql", true},
+		{"@~/query.sql", filepath.Join(home, "query.sql"), true},
+		{"@relative/query.sql", "relative/query.sql", true},
+		{"SELECT * FROM t", "", false},
+		{"@", "", false},
+		{"@x := 1;\nSELECT @x", "", false},
+	}
+
+	for _, tc := range testCases {
+		path, isFile := queryFilePath(tc.input)
+		r.Equal(tc.isFile, isFile, tc.input)
+		r.Equal(tc.expected, path, tc.input)
+	}
+}
+
+func TestReadQueryFile(t *testing.T) {
+	r := require.New(t)
+	dir := t.TempDir()
+
+	// valid file with byte order mark
+	valid := filepath.Join(dir, "valid.sql")
+	err := os.WriteFile(valid, []byte("\xef\xbb\xbfSELECT 'ž';\nSELECT 2;"), 0o644)
+	r.NoError(err)
+
+	query, err := readQueryFile(valid)
+	r.NoError(err)
+	r.Equal("SELECT 'ž';\nSELECT 2;", query)
+
+	// not utf-8
+	invalid := filepath.Join(dir, "invalid.sql")
+	err = os.WriteFile(invalid, []byte("SELECT '\xe8'"), 0o644)
+	r.NoError(err)
+
+	_, err = readQueryFile(invalid)
+	r.ErrorContains(err, "not valid UTF-8")
+
+	// missing
+	_, err = readQueryFile(filepath.Join(dir, "missing.sql"))
+	r.ErrorContains(err, "does not exist")
+}
@@ -0,0 +1,190 @@
+package core
+
+import "strings"
+
+// redactedValue replaces redacted values in statements.
+const redactedValue = "***"
+
+type redactTokenKind int
+
+const (
+	redactTokenSpace   redactTokenKind = iota // whitespace and comments
+	redactTokenWord                           // keywords and bare identifiers
+	redactTokenLiteral                        // string literals ('...', E'...', $$...$$)
+	redactTokenQuoted                         // quoted identifiers ("..." and `...`)
+	redactTokenPunct
+)
+
+type redactToken struct {
+	kind       redactTokenKind
+	start, end int
+	// uppercased word
+	word string
+}
+
+// redactTokens splits the statement into tokens. Unterminated literals, identifiers
+// and comments reach to the end of the statement.
+func redactTokens(query string) []redactToken {
+	var tokens []redactToken
+
+	for i := 0; i < len(query); {
+		start := i
+		ch := query[i]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			for i < len(query) && strings.IndexByte(" \t\n\r", query[i]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, redactToken{kind: redactTokenSpace, start: start, end: i})
+
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end
+			}
+			tokens = append(tokens, redactToken{kind: redactTokenSpace, start: start, end: i})
+
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 4
+			}
+			tokens = append(tokens, redactToken{kind: redactTokenSpace, start: start, end: i})
+
+		case ch == '\'':
+			i = redactQuotedEnd(query, i, '\'')
+			tokens = append(tokens, redactToken{kind: redactTokenLiteral, start: start, end: i})
+
+		case ch == '"' || ch == '`':
+			i = redactQuotedEnd(query, i, ch)
+			tokens = append(tokens, redactToken{kind: redactTokenQuoted, start: start, end: i})
+
+		case ch == '$':
+			// dollar quoted string ($$...$$ or $tag$...$tag$)
+			tagEnd := i + 1
+			for tagEnd < len(query) && isRedactWordChar(rune(query[tagEnd])) {
+				tagEnd++
+			}
+			if tagEnd >= len(query) || query[tagEnd] != '$' {
+				i++
+				tokens = append(tokens, redactToken{kind: redactTokenPunct, start: start, end: i})
+				break
+			}
+			tag := query[i : tagEnd+1]
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += len(tag) + end + len(tag)
+			}
+			tokens = append(tokens, redactToken{kind: redactTokenLiteral, start: start, end: i})
+
+		case isRedactWordChar(rune(ch)):
+			for i < len(query) && isRedactWordChar(rune(query[i])) {
+				i++
+			}
+			// string prefixes (e.g. E'...' or N'...')
+			if i-start == 1 && i < len(query) && query[i] == '\'' {
+				i = redactQuotedEnd(query, i, '\'')
+				tokens = append(tokens, redactToken{kind: redactTokenLiteral, start: start, end: i})
+				break
+			}
+			tokens = append(tokens, redactToken{kind: redactTokenWord, start: start, end: i, word: strings.ToUpper(query[start:i])})
+
+		default:
+			i++
+			tokens = append(tokens, redactToken{kind: redactTokenPunct, start: start, end: i})
+		}
+	}
+
+	return tokens
+}
+
+// redactQuotedEnd returns the end of the quoted string starting at i. Doubled quotes
+// and backslash escapes are skipped, so a literal is rather redacted too far than too short.
+func redactQuotedEnd(query string, i int, quote byte) int {
+	for i++; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+func isRedactWordChar(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r > 127
+}
+
+// RedactStatement masks secrets in the statement, so it can be logged: literals following
+// PASSWORD or SECRET (e.g. "PASSWORD 'hunter2'", "SECRET = 'abc'" or
+// "SET PASSWORD FOR bob = 'hunter2'") and values of IDENTIFIED ... BY (quoted or not).
+func RedactStatement(query string) string {
+	tokens := redactTokens(query)
+
+	var b strings.Builder
+	last := 0
+	redact := func(tok redactToken, value string) {
+		b.WriteString(query[last:tok.start])
+		b.WriteString(value)
+		last = tok.end
+	}
+
+	// expecting a secret literal, or also an unquoted value (after IDENTIFIED BY)
+	var pending, pendingBare bool
+	// seen IDENTIFIED (waiting for BY) and SET PASSWORD FOR (waiting for "=")
+	var identified, passwordFor bool
+	var prev redactToken
+	for _, tok := range tokens {
+		if tok.kind == redactTokenSpace {
+			continue
+		}
+
+		switch {
+		case pending && tok.kind == redactTokenLiteral:
+			redact(tok, "'"+redactedValue+"'")
+		case pendingBare && tok.kind == redactTokenQuoted:
+			redact(tok, query[tok.start:tok.start+1]+redactedValue+query[tok.start:tok.start+1])
+		case pendingBare && tok.kind == redactTokenWord && tok.word != "PASSWORD":
+			redact(tok, redactedValue)
+		case pending && tok.kind == redactTokenPunct && query[tok.start] == '=':
+			// "PASSWORD = '...'"
+			continue
+		}
+
+		wasPassword := prev.kind == redactTokenWord && prev.word == "PASSWORD"
+		prev = tok
+		pending, pendingBare = false, false
+		switch {
+		case tok.kind == redactTokenWord && (tok.word == "PASSWORD" || tok.word == "SECRET"):
+			pending = true
+		case tok.kind == redactTokenWord && tok.word == "IDENTIFIED":
+			identified = true
+		case tok.kind == redactTokenWord && tok.word == "BY" && identified:
+			pending, pendingBare, identified = true, true, false
+		case tok.kind == redactTokenWord && tok.word == "FOR" && wasPassword:
+			passwordFor = true
+		case tok.kind == redactTokenPunct && query[tok.start] == '=' && passwordFor:
+			pending, passwordFor = true, false
+		case tok.kind == redactTokenPunct && query[tok.start] == ';':
+			identified, passwordFor = false, false
+		}
+	}
+
+	if last == 0 {
+		return query
+	}
+	b.WriteString(query[last:])
+	return b.String()
+}
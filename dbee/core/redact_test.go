@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactStatement(t *testing.T) {
+	type testCase struct {
+		query    string
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			query:    "CREATE USER bob WITH PASSWORD 'hunter2'",
+			expected: "CREATE USER bob WITH PASSWORD '***'",
+		},
+		{
+			query:    "alter role bob encrypted password E'hun\\'ter''2' valid until 'infinity'",
+			expected: "alter role bob encrypted password '***' valid until 'infinity'",
+		},
+		{
+			query:    "CREATE USER 'bob'@'%' IDENTIFIED WITH caching_sha2_password BY 'hunter2'; SELECT 'by'",
+			expected: "CREATE USER 'bob'@'%' IDENTIFIED WITH caching_sha2_password BY '***'; SELECT 'by'",
+		},
+		{
+			query:    "SET PASSWORD FOR 'bob'@'%' = 'hunter2'",
+			expected: "SET PASSWORD FOR 'bob'@'%' = '***'",
+		},
+		{
+			query:    "ALTER USER bob IDENTIFIED BY hunter2 ACCOUNT UNLOCK",
+			expected: "ALTER USER bob IDENTIFIED BY *** ACCOUNT UNLOCK",
+		},
+		{
+			query:    `CREATE USER bob IDENTIFIED BY "hunter2"`,
+			expected: `CREATE USER bob IDENTIFIED BY "***"`,
+		},
+		{
+			query:    "GRANT USAGE ON *.* TO 'bob'@'%' IDENTIFIED BY PASSWORD '*6BB4837EB74329105EE4568DDA7DC67ED2CA2AD9'",
+			expected: "GRANT USAGE ON *.* TO 'bob'@'%' IDENTIFIED BY PASSWORD '***'",
+		},
+		{
+			query:    "ALTER USER bob SET PASSWORD = 'hunter2' MUST_CHANGE_PASSWORD = TRUE",
+			expected: "ALTER USER bob SET PASSWORD = '***' MUST_CHANGE_PASSWORD = TRUE",
+		},
+		{
+			query:    "CREATE SECRET s3_secret (TYPE s3, KEY_ID 'AKIA', SECRET 'abc')",
+			expected: "CREATE SECRET s3_secret (TYPE s3, KEY_ID 'AKIA', SECRET '***')",
+		},
+		{
+			query:    "CREATE ROLE bob PASSWORD $pw$hunter2$pw$ LOGIN",
+			expected: "CREATE ROLE bob PASSWORD '***' LOGIN",
+		},
+		{
+			query:    "SELECT password, 'x' FROM users -- password 'x'\nWHERE name = 'password'",
+			expected: "SELECT password, 'x' FROM users -- password 'x'\nWHERE name = 'password'",
+		},
+		{
+			query:    "CREATE USER bob PASSWORD 'unterminated",
+			expected: "CREATE USER bob PASSWORD '***'",
+		},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, RedactStatement(tc.query), tc.query)
+	}
+}
@@ -15,12 +15,27 @@ type Result struct {
 	meta   *Meta
 	rows   []Row
 
+	// rows past memoryRows are stored in the spill file (0 means no limit)
+	memoryRows int
+	spill      *resultSpill
+
+	// indexes of displayed columns (nil displays all, see SelectColumns)
+	columns []int
+
 	isDrained  bool
 	isFilled   bool
 	writeMutex sync.Mutex
 	readMutex  sync.RWMutex
 }
 
+// NewResult creates a result which keeps at most memoryRows rows in memory,
+// additional rows are spilled to a temporary file. Zero means no limit.
+func NewResult(memoryRows int) *Result {
+	return &Result{
+		memoryRows: memoryRows,
+	}
+}
+
 // SetIter sets the ResultStream iterator to result.
 // This can be done only once!
 func (cr *Result) SetIter(iter ResultStream, onFillStart func()) error {
@@ -34,6 +49,8 @@ func (cr *Result) SetIter(iter ResultStream, onFillStart func()) error {
 	cr.header = iter.Header()
 	cr.meta = iter.Meta()
 	cr.rows = []Row{}
+	cr.closeSpill()
+	cr.columns = nil
 
 	cr.isDrained = false
 	cr.isFilled = true
@@ -53,12 +70,49 @@ func (cr *Result) SetIter(iter ResultStream, onFillStart func()) error {
 			return err
 		}
 
-		cr.rows = append(cr.rows, row)
+		if err := cr.addRow(row); err != nil {
+			cr.isFilled = false
+			return err
+		}
 	}
 
 	return nil
 }
 
+// addRow adds the row to memory or to the spill file if memory is full.
+func (cr *Result) addRow(row Row) error {
+	if cr.memoryRows <= 0 || len(cr.rows) < cr.memoryRows {
+		cr.rows = append(cr.rows, row)
+		return nil
+	}
+
+	if cr.spill == nil {
+		spill, err := newResultSpill()
+		if err != nil {
+			return err
+		}
+		cr.spill = spill
+	}
+
+	return cr.spill.add(row)
+}
+
+// closeSpill removes the spill file if it exists.
+func (cr *Result) closeSpill() {
+	if cr.spill != nil {
+		cr.spill.close()
+		cr.spill = nil
+	}
+}
+
+// length returns the number of rows in memory and in the spill file.
+func (cr *Result) length() int {
+	if cr.spill == nil {
+		return len(cr.rows)
+	}
+	return len(cr.rows) + cr.spill.len()
+}
+
 func (cr *Result) Wipe() {
 	// lock write and read mutexes
 	cr.writeMutex.Lock()
@@ -70,6 +124,8 @@ func (cr *Result) Wipe() {
 	cr.header = Header{}
 	cr.meta = &Meta{}
 	cr.rows = []Row{}
+	cr.closeSpill()
+	cr.columns = nil
 	cr.isDrained = false
 	cr.isFilled = false
 }
@@ -85,7 +141,11 @@ func (cr *Result) Format(formatter Formatter, from, to int) ([]byte, error) {
 		ChunkStart: fromAdjusted,
 	}
 
-	f, err := formatter.Format(cr.header, rows, opts)
+	cr.readMutex.RLock()
+	header, rows := cr.project(cr.header, rows)
+	cr.readMutex.RUnlock()
+
+	f, err := formatter.Format(header, rows, opts)
 	if err != nil {
 		return nil, fmt.Errorf("formatter.Format: %w", err)
 	}
@@ -94,7 +154,12 @@ func (cr *Result) Format(formatter Formatter, from, to int) ([]byte, error) {
 }
 
 func (cr *Result) Len() int {
-	return len(cr.rows)
+	return cr.length()
+}
+
+// IsDrained reports whether all rows of the stream were retrieved.
+func (cr *Result) IsDrained() bool {
+	return cr.isDrained
 }
 
 func (cr *Result) IsEmpty() bool {
@@ -137,7 +202,7 @@ func (cr *Result) getRows(from, to int) (rows []Row, rangeFrom int, rangeTo int,
 
 	// Wait for drain, available index or timeout
 	for {
-		if cr.isDrained || (to >= 0 && to <= len(cr.rows)) {
+		if cr.isDrained || (to >= 0 && to <= cr.length()) {
 			break
 		}
 
@@ -148,7 +213,7 @@ func (cr *Result) getRows(from, to int) (rows []Row, rangeFrom int, rangeTo int,
 	}
 
 	// calculate range
-	length := len(cr.rows)
+	length := cr.length()
 	if from < 0 {
 		from += length + 1
 		if from < 0 {
@@ -169,5 +234,23 @@ func (cr *Result) getRows(from, to int) (rows []Row, rangeFrom int, rangeTo int,
 		to = length
 	}
 
-	return cr.rows[from:to], from, to, nil
+	// rows past the memory buffer are read from the spill file
+	inMemory := len(cr.rows)
+	if to <= inMemory {
+		return cr.rows[from:to], from, to, nil
+	}
+
+	spilled, err := cr.spill.rows(max(from-inMemory, 0), to-inMemory)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("cr.spill.rows: %w", err)
+	}
+	if from >= inMemory {
+		return spilled, from, to, nil
+	}
+
+	rows = make([]Row, 0, to-from)
+	rows = append(rows, cr.rows[from:inMemory]...)
+	rows = append(rows, spilled...)
+
+	return rows, from, to, nil
 }
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// spillChunkSize is the number of rows encoded together in the spill file.
+const spillChunkSize = 500
+
+// resultSpill stores rows that don't fit into the memory buffer of the result
+// in a temporary file. Rows are written in gob encoded chunks, so any range
+// can be read back by decoding only the chunks it spans.
+type resultSpill struct {
+	mu sync.RWMutex
+
+	file *os.File
+	// offsets are start positions of chunks in the file, the last one is the end of the file
+	offsets []int64
+	// pending rows of the current chunk, which isn't written yet
+	pending []Row
+	length  int
+}
+
+func newResultSpill() (*resultSpill, error) {
+	file, err := os.CreateTemp("", "dbee-result-*.gob")
+	if err != nil {
+		return nil, fmt.Errorf("os.CreateTemp: %w", err)
+	}
+
+	return &resultSpill{
+		file:    file,
+		offsets: []int64{0},
+	}, nil
+}
+
+// add appends the row to the spill.
+func (s *resultSpill) add(row Row) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, row)
+	s.length++
+
+	if len(s.pending) < spillChunkSize {
+		return nil
+	}
+	return s.flush()
+}
+
+// flush writes the pending rows as a new chunk.
+func (s *resultSpill) flush() error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s.pending); err != nil {
+		return fmt.Errorf("encoder.Encode: %w", err)
+	}
+
+	end := s.offsets[len(s.offsets)-1]
+	n, err := s.file.WriteAt(buf.Bytes(), end)
+	if err != nil {
+		return fmt.Errorf("file.WriteAt: %w", err)
+	}
+
+	s.offsets = append(s.offsets, end+int64(n))
+	s.pending = nil
+
+	return nil
+}
+
+func (s *resultSpill) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.length
+}
+
+// readChunk decodes the i-th written chunk.
+func (s *resultSpill) readChunk(i int) ([]Row, error) {
+	section := io.NewSectionReader(s.file, s.offsets[i], s.offsets[i+1]-s.offsets[i])
+
+	var rows []Row
+	if err := gob.NewDecoder(section).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("decoder.Decode: %w", err)
+	}
+	return rows, nil
+}
+
+// rows returns the rows in range [from, to) of the spill.
+func (s *resultSpill) rows(from, to int) ([]Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if from < 0 || to > s.length || from > to {
+		return nil, ErrInvalidRange(from, to)
+	}
+
+	rows := make([]Row, 0, to-from)
+	written := len(s.offsets) - 1
+
+	for chunk := from / spillChunkSize; chunk*spillChunkSize < to; chunk++ {
+		var chunkRows []Row
+		if chunk < written {
+			var err error
+			chunkRows, err = s.readChunk(chunk)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			chunkRows = s.pending
+		}
+
+		start := chunk * spillChunkSize
+		lo := max(from-start, 0)
+		hi := min(to-start, len(chunkRows))
+		rows = append(rows, chunkRows[lo:hi]...)
+	}
+
+	return rows, nil
+}
+
+// close closes and removes the spill file.
+func (s *resultSpill) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.file.Close()
+	_ = os.Remove(s.file.Name())
+}
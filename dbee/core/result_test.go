@@ -109,3 +109,52 @@ func TestResult(t *testing.T) {
 		})
 	}
 }
+
+func TestResult_Spill(t *testing.T) {
+	r := require.New(t)
+
+	// more rows than a single spill chunk holds, so ranges span
+	// memory, written chunks and pending rows
+	input := mock.NewRows(0, 1200)
+
+	result := core.NewResult(10)
+	defer result.Wipe()
+
+	err := result.SetIter(mock.NewResultStream(input), nil)
+	r.NoError(err)
+	r.Equal(1200, result.Len())
+
+	testCases := []struct {
+		from int
+		to   int
+	}{
+		{from: 0, to: 10},
+		{from: 5, to: 15},
+		{from: 10, to: 20},
+		{from: 495, to: 1005},
+		{from: 1100, to: 1200},
+		{from: 0, to: 1200},
+	}
+
+	for _, tc := range testCases {
+		rows, err := result.Rows(tc.from, tc.to)
+		r.NoError(err)
+		r.Equal(input[tc.from:tc.to], rows)
+	}
+
+	rows, err := result.Rows(-3, -1)
+	r.NoError(err)
+	r.Equal(mock.NewRows(1198, 1200), rows)
+
+	// sorting includes spilled rows
+	err = result.Sort(0, false)
+	r.NoError(err)
+
+	rows, err = result.Rows(0, 2)
+	r.NoError(err)
+	r.Equal([]core.Row{input[1199], input[1198]}, rows)
+
+	rows, err = result.Rows(-3, -1)
+	r.NoError(err)
+	r.Equal([]core.Row{input[1], input[0]}, rows)
+}
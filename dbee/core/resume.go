@@ -0,0 +1,257 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultCheckpointRows = 1000
+	defaultResumeRetries  = 3
+)
+
+// resumeRetryDelay is the delay before the first resume. It's doubled on every next one.
+var resumeRetryDelay = time.Second
+
+// ResumeOptions are options of resumable exports (see Connection.ExportResumable).
+type ResumeOptions struct {
+	// Key are the columns the query orders its rows by. They have to form a unique key.
+	Key []string
+	// CheckpointRows is the number of rows written at once, defaults to 1000.
+	CheckpointRows int
+	// MaxRetries is the number of resumes after consecutive failures (without any rows
+	// written in between), defaults to 3.
+	MaxRetries int
+}
+
+// ExportResumable runs the select statement and passes its rows to write in batches of
+// CheckpointRows rows. The key of the last row of each written batch is the checkpoint:
+// if retrieving rows fails with a transient error (e.g. a dropped connection), the query
+// is run again for the rows after it (like pages are selected by keyset pagination,
+// see ExecutePage) and the export continues, so write is never passed a row twice.
+// The query has to order rows of a single table by the key (with the key columns selected).
+// Returns the number of written rows.
+func (c *Connection) ExportResumable(ctx context.Context, query string, opts *ResumeOptions, write func(header Header, rows []Row) error) (int, error) {
+	if opts == nil || len(opts.Key) < 1 {
+		return 0, errors.New("resumable export requires an ordering key")
+	}
+
+	query, err := c.prepareSyncQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, ok := selectStatement(query)
+	if !ok {
+		return 0, errors.New("only single select statements can be exported with resume")
+	}
+	keyset, ok := parseKeysetQuery(stmt)
+	if !ok || !keyset.orderedBy(opts.Key) {
+		return 0, fmt.Errorf("query has to order rows of a single table by the key (%s)", strings.Join(opts.Key, ", "))
+	}
+
+	if lister, ok := c.driver.(UniqueKeyLister); ok {
+		keys, err := lister.UniqueKeys(&TableOptions{Schema: keyset.schema, Table: keyset.table})
+		if err == nil && !keyset.coversUniqueKey(keys) {
+			return 0, fmt.Errorf("key (%s) is not a unique key of %q", strings.Join(opts.Key, ", "), keyset.table)
+		}
+	}
+
+	// row value comparisons are only used with drivers known to support them
+	dialect := LimitDialectFetchFirst
+	if limiter, ok := c.driver.(Limiter); ok {
+		dialect = limiter.LimitDialect()
+	}
+
+	exp := &resumableExport{
+		query:   stmt,
+		keyset:  keyset,
+		dialect: dialect,
+		size:    opts.CheckpointRows,
+		write:   write,
+	}
+	if exp.size <= 0 {
+		exp.size = defaultCheckpointRows
+	}
+	retries := opts.MaxRetries
+	if retries <= 0 {
+		retries = defaultResumeRetries
+	}
+
+	delay := resumeRetryDelay
+	failures := 0
+	for {
+		written := exp.written
+		err := exp.run(ctx, c.query)
+		if err == nil {
+			return exp.written, nil
+		}
+
+		if exp.written > written {
+			failures, delay = 0, resumeRetryDelay
+		}
+		failures++
+		if exp.fatal || failures > retries || !isTransientError(err) {
+			return exp.written, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return exp.written, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// resumableExport is the state of a resumable export.
+type resumableExport struct {
+	query   string
+	keyset  *keysetQuery
+	dialect LimitDialect
+	size    int
+	write   func(Header, []Row) error
+
+	header Header
+	// positions of the key columns in the header
+	indexes []int
+	// key of the last written row (the checkpoint)
+	after   []any
+	written int
+	// set if the export failed for a reason other than retrieving rows
+	fatal bool
+}
+
+// run selects the rows after the checkpoint and writes them.
+func (e *resumableExport) run(ctx context.Context, query func(context.Context, string) (ResultStream, error)) error {
+	q := e.query
+	if e.after != nil {
+		q = e.keyset.seek(e.query, e.after, e.dialect)
+	}
+
+	rows, err := query(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if err := e.setHeader(rows.Header()); err != nil {
+		e.fatal = true
+		return err
+	}
+
+	batch := make([]Row, 0, e.size)
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, row)
+		if len(batch) >= e.size {
+			if err := e.checkpoint(batch); err != nil {
+				return err
+			}
+			batch = make([]Row, 0, e.size)
+		}
+	}
+	if failable, ok := rows.(FailableStream); ok {
+		if err := failable.Err(); err != nil {
+			return err
+		}
+	}
+
+	// an empty result is still written (e.g. csv header)
+	if len(batch) > 0 || e.written == 0 {
+		return e.checkpoint(batch)
+	}
+	return nil
+}
+
+// setHeader sets the header of the first run and checks that resumed runs have the same one.
+func (e *resumableExport) setHeader(header Header) error {
+	if e.header != nil {
+		if !slices.Equal(e.header, header) {
+			return errors.New("columns of the query changed while resuming")
+		}
+		return nil
+	}
+
+	indexes := make([]int, len(e.keyset.names))
+	for i, name := range e.keyset.names {
+		indexes[i] = slices.IndexFunc(header, func(col string) bool { return strings.EqualFold(col, name) })
+		if indexes[i] < 0 {
+			return fmt.Errorf("key column %q is not selected", name)
+		}
+	}
+
+	e.header, e.indexes = header, indexes
+	return nil
+}
+
+// checkpoint writes the batch and remembers the key of its last row.
+func (e *resumableExport) checkpoint(batch []Row) error {
+	var after []any
+	if len(batch) > 0 {
+		last := batch[len(batch)-1]
+		after = make([]any, len(e.indexes))
+		for i, idx := range e.indexes {
+			if idx >= len(last) || last[idx] == nil {
+				e.fatal = true
+				return fmt.Errorf("key column %q is NULL", e.keyset.names[i])
+			}
+			after[i] = last[idx]
+		}
+	}
+
+	if err := e.write(e.header, batch); err != nil {
+		e.fatal = true
+		return err
+	}
+
+	if after != nil {
+		e.after = after
+	}
+	e.written += len(batch)
+	return nil
+}
+
+// orderedBy checks if the query orders rows by exactly the key columns (qualified or not).
+func (q *keysetQuery) orderedBy(key []string) bool {
+	if len(key) != len(q.names) {
+		return false
+	}
+	for i, name := range q.names {
+		parts := splitIdent(key[i])
+		if len(parts) < 1 || !strings.EqualFold(name, parts[len(parts)-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTransientError reports whether the error is caused by the connection to the
+// database (e.g. a network failure), so the query is likely to succeed if run again.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr)
+}
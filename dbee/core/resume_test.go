@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingStream is a stream which ends with an error after its rows.
+type failingStream struct {
+	rowsStream
+	err error
+}
+
+func (s *failingStream) Err() error {
+	return s.err
+}
+
+// flakyDriver selects ids from 1 to total (after the id of a "WHERE id > n" condition).
+// Streams of the queries with failures in fails end with the error after that many rows.
+type flakyDriver struct {
+	keyedDriver
+	total int
+	fails map[int]error
+}
+
+func (d *flakyDriver) Query(_ context.Context, query string) (ResultStream, error) {
+	d.executed = append(d.executed, query)
+	run := len(d.executed) - 1
+
+	from := 1
+	if _, after, ok := strings.Cut(query, "WHERE id > "); ok {
+		n, _ := strconv.Atoi(strings.Fields(after)[0])
+		from = n + 1
+	}
+
+	stream := &failingStream{rowsStream: rowsStream{header: Header{"id", "name"}}}
+	for id := from; id <= d.total; id++ {
+		stream.rows = append(stream.rows, Row{id, "row_" + strconv.Itoa(id)})
+	}
+
+	if err, ok := d.fails[run]; ok {
+		// fails after 3 rows
+		stream.rows = stream.rows[:min(3, len(stream.rows))]
+		stream.err = err
+	}
+
+	return stream, nil
+}
+
+func TestConnection_ExportResumable(t *testing.T) {
+	r := require.New(t)
+
+	defer func(delay time.Duration) { resumeRetryDelay = delay }(resumeRetryDelay)
+	resumeRetryDelay = 0
+
+	d := &flakyDriver{total: 7, fails: map[int]error{
+		0: io.ErrUnexpectedEOF,
+		1: driver.ErrBadConn,
+	}}
+	c := &Connection{driver: d}
+
+	var written []Row
+	write := func(header Header, rows []Row) error {
+		r.Equal(Header{"id", "name"}, header)
+		r.LessOrEqual(len(rows), 2)
+		written = append(written, rows...)
+		return nil
+	}
+
+	count, err := c.ExportResumable(context.Background(), "SELECT id, name FROM t ORDER BY id", &ResumeOptions{
+		Key:            []string{"id"},
+		CheckpointRows: 2,
+	}, write)
+	r.NoError(err)
+	r.Equal(7, count)
+	r.Equal([]string{
+		"SELECT id, name FROM t ORDER BY id",
+		// the third row of the failed run wasn't checkpointed, so it's selected again
+		"SELECT id, name FROM t WHERE id > 2 ORDER BY id",
+		"SELECT id, name FROM t WHERE id > 4 ORDER BY id",
+	}, d.executed)
+	r.Len(written, 7)
+	for i, row := range written {
+		r.Equal(i+1, row[0])
+	}
+
+	// consecutive failures without progress
+	d = &flakyDriver{total: 1, fails: map[int]error{0: io.EOF, 1: io.EOF, 2: io.EOF}}
+	c = &Connection{driver: d}
+	_, err = c.ExportResumable(context.Background(), "SELECT id, name FROM t ORDER BY id", &ResumeOptions{
+		Key:        []string{"id"},
+		MaxRetries: 2,
+	}, func(Header, []Row) error { return nil })
+	r.ErrorIs(err, io.EOF)
+	r.Len(d.executed, 3)
+
+	// errors other than transient ones are not retried
+	d = &flakyDriver{total: 5, fails: map[int]error{0: errors.New("division by zero")}}
+	c = &Connection{driver: d}
+	_, err = c.ExportResumable(context.Background(), "SELECT id, name FROM t ORDER BY id", &ResumeOptions{
+		Key: []string{"id"},
+	}, func(Header, []Row) error { return nil })
+	r.EqualError(err, "division by zero")
+	r.Len(d.executed, 1)
+
+	// neither are errors of writing
+	d = &flakyDriver{total: 5}
+	c = &Connection{driver: d}
+	_, err = c.ExportResumable(context.Background(), "SELECT id, name FROM t ORDER BY id", &ResumeOptions{
+		Key: []string{"id"},
+	}, func(Header, []Row) error { return io.ErrUnexpectedEOF })
+	r.ErrorIs(err, io.ErrUnexpectedEOF)
+	r.Len(d.executed, 1)
+}
+
+func TestConnection_ExportResumable_Key(t *testing.T) {
+	r := require.New(t)
+
+	c := &Connection{driver: &flakyDriver{total: 1}}
+	write := func(Header, []Row) error { return nil }
+
+	_, err := c.ExportResumable(context.Background(), "SELECT id, name FROM t ORDER BY id", nil, write)
+	r.EqualError(err, "resumable export requires an ordering key")
+
+	_, err = c.ExportResumable(context.Background(), "SELECT id, name FROM t", &ResumeOptions{Key: []string{"id"}}, write)
+	r.EqualError(err, "query has to order rows of a single table by the key (id)")
+
+	_, err = c.ExportResumable(context.Background(), "SELECT id, name FROM t ORDER BY name", &ResumeOptions{Key: []string{"id"}}, write)
+	r.EqualError(err, "query has to order rows of a single table by the key (id)")
+
+	_, err = c.ExportResumable(context.Background(), "SELECT id, name FROM t ORDER BY name", &ResumeOptions{Key: []string{"name"}}, write)
+	r.EqualError(err, `key (name) is not a unique key of "t"`)
+
+	count, err := c.ExportResumable(context.Background(), "SELECT t.id, t.name FROM t ORDER BY t.id", &ResumeOptions{Key: []string{"t.id"}}, write)
+	r.NoError(err)
+	r.Equal(1, count)
+
+	keyset, _ := parseKeysetQuery("SELECT name FROM t ORDER BY id")
+	err = (&resumableExport{keyset: keyset}).setHeader(Header{"name"})
+	r.EqualError(err, `key column "id" is not selected`)
+}
+
+func TestIsTransientError(t *testing.T) {
+	r := require.New(t)
+
+	r.True(isTransientError(driver.ErrBadConn))
+	r.True(isTransientError(fmt.Errorf("read: %w", syscall.ECONNRESET)))
+	r.True(isTransientError(&net.OpError{Op: "read", Err: errors.New("i/o timeout")}))
+	r.False(isTransientError(errors.New("syntax error")))
+	r.False(isTransientError(fmt.Errorf("canceled: %w", context.Canceled)))
+}
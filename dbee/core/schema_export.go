@@ -0,0 +1,301 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// schemaObject is a single table or view collected for export.
+type schemaObject struct {
+	opts *TableOptions
+	// keys of objects this object depends on
+	dependsOn []string
+}
+
+func (o *schemaObject) key() string {
+	return objectKey(o.opts.Schema, o.opts.Table)
+}
+
+func objectKey(schema, table string) string {
+	if schema == "" {
+		return table
+	}
+	return schema + "." + table
+}
+
+// collectSchemaObjects flattens the structure tree into a list of tables and views.
+func collectSchemaObjects(structure []*Structure) (tables []*schemaObject, views []*schemaObject) {
+	for _, s := range structure {
+		switch s.Type {
+		case StructureTypeTable, StructureTypeKuduTable:
+			tables = append(tables, &schemaObject{
+				opts: &TableOptions{Table: s.Name, Schema: s.Schema, Materialization: s.Type},
+			})
+		case StructureTypeView:
+			views = append(views, &schemaObject{
+				opts: &TableOptions{Table: s.Name, Schema: s.Schema, Materialization: s.Type},
+			})
+		}
+
+		t, v := collectSchemaObjects(s.Children)
+		tables = append(tables, t...)
+		views = append(views, v...)
+	}
+
+	return tables, views
+}
+
+// sortByDependencies sorts objects topologically, so that every object comes after
+// the objects it depends on. Objects without mutual dependencies are sorted by name.
+// Objects that are part of a dependency cycle are appended at the end in name order.
+func sortByDependencies(objects []*schemaObject) (sorted []*schemaObject, cyclic []*schemaObject) {
+	lookup := make(map[string]*schemaObject, len(objects))
+	for _, o := range objects {
+		lookup[o.key()] = o
+	}
+
+	// number of unresolved dependencies per object and reverse edges
+	pending := make(map[string]int, len(objects))
+	dependents := make(map[string][]string)
+	for _, o := range objects {
+		seen := make(map[string]bool)
+		for _, dep := range o.dependsOn {
+			// ignore self references and references to unknown objects
+			if dep == o.key() || seen[dep] {
+				continue
+			}
+			if _, ok := lookup[dep]; !ok {
+				continue
+			}
+			seen[dep] = true
+			pending[o.key()]++
+			dependents[dep] = append(dependents[dep], o.key())
+		}
+	}
+
+	var ready []string
+	for _, o := range objects {
+		if pending[o.key()] == 0 {
+			ready = append(ready, o.key())
+		}
+	}
+	sort.Strings(ready)
+
+	done := make(map[string]bool, len(objects))
+	for len(ready) > 0 {
+		key := ready[0]
+		ready = ready[1:]
+
+		sorted = append(sorted, lookup[key])
+		done[key] = true
+
+		var next []string
+		for _, d := range dependents[key] {
+			pending[d]--
+			if pending[d] == 0 {
+				next = append(next, d)
+			}
+		}
+		ready = append(ready, next...)
+		sort.Strings(ready)
+	}
+
+	for _, o := range objects {
+		if !done[o.key()] {
+			cyclic = append(cyclic, o)
+		}
+	}
+	sort.Slice(cyclic, func(i, j int) bool { return cyclic[i].key() < cyclic[j].key() })
+
+	return sorted, cyclic
+}
+
+// ExportSchema writes definitions of all tables and views of the connection to the provided writer.
+//
+// If the driver implements SchemaDDLProvider, the output can be restored as is: schemas, types and
+// sequences come first, followed by tables without foreign keys, views ordered by their dependencies
+// and finally foreign keys and indexes.
+// Otherwise tables are ordered by their foreign key dependencies (referenced tables come first,
+// tables with circular references last) and are followed by views.
+// Driver needs to implement DDLProvider and optionally ForeignKeyLister.
+//
+// Names are used as returned by the driver (i.e. as stored in the catalog), so the driver
+// is called directly instead of through GetDDL and GetForeignKeys, which expect displayed names.
+func (c *Connection) ExportSchema(w io.Writer) error {
+	ddlProvider, ok := c.driver.(DDLProvider)
+	if !ok {
+		return ErrDDLNotSupported
+	}
+
+	structure, err := c.driver.Structure()
+	if err != nil {
+		return fmt.Errorf("c.driver.Structure: %w", err)
+	}
+
+	tables, views := collectSchemaObjects(structure)
+
+	if provider, ok := c.driver.(SchemaDDLProvider); ok {
+		return exportSchemaDefinitions(w, provider, tables, views)
+	}
+
+	if lister, ok := c.driver.(ForeignKeyLister); ok {
+		for _, t := range tables {
+			fks, err := lister.ForeignKeys(t.opts)
+			if err != nil {
+				if errors.Is(err, ErrForeignKeysNotSupported) {
+					break
+				}
+				return fmt.Errorf("lister.ForeignKeys: %w", err)
+			}
+
+			for _, fk := range fks {
+				schema := fk.ReferencedSchema
+				if schema == "" {
+					schema = t.opts.Schema
+				}
+				t.dependsOn = append(t.dependsOn, objectKey(schema, fk.ReferencedTable))
+			}
+		}
+	}
+
+	sortedTables, cyclicTables := sortByDependencies(tables)
+	sortedViews, _ := sortByDependencies(views)
+
+	write := func(header string, objects []*schemaObject) error {
+		if len(objects) < 1 {
+			return nil
+		}
+
+		_, err := fmt.Fprintf(w, "-- %s\n\n", header)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range objects {
+			ddl, err := ddlProvider.DDL(o.opts)
+			if err != nil {
+				return fmt.Errorf("ddlProvider.DDL: %w", err)
+			}
+
+			err = writeStatements(w, fmt.Sprintf("%s: %s", o.opts.Materialization, o.key()), ddl)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := write("Tables", append(sortedTables, cyclicTables...)); err != nil {
+		return err
+	}
+	if err := write("Views", sortedViews); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// exportSchemaDefinitions writes the schema in an order that can be restored:
+// prelude, tables, views, constraints and indexes.
+func exportSchemaDefinitions(w io.Writer, provider SchemaDDLProvider, tables, views []*schemaObject) error {
+	prelude, err := provider.SchemaPrelude()
+	if err != nil {
+		return fmt.Errorf("provider.SchemaPrelude: %w", err)
+	}
+	if len(prelude) > 0 {
+		err = writeStatements(w, "Schemas, types and sequences", prelude...)
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(tables, func(i, j int) bool { return tables[i].key() < tables[j].key() })
+
+	var constraints, indexes []string
+	for i, t := range tables {
+		def, err := provider.TableDefinition(t.opts)
+		if err != nil {
+			return fmt.Errorf("provider.TableDefinition: %w", err)
+		}
+
+		if i == 0 {
+			if _, err := fmt.Fprint(w, "-- Tables\n\n"); err != nil {
+				return err
+			}
+		}
+		err = writeStatements(w, fmt.Sprintf("%s: %s", t.opts.Materialization, t.key()), def.Create)
+		if err != nil {
+			return err
+		}
+
+		constraints = append(constraints, def.Constraints...)
+		indexes = append(indexes, def.Indexes...)
+	}
+
+	for _, v := range views {
+		deps, err := provider.ViewDependencies(v.opts)
+		if err != nil {
+			return fmt.Errorf("provider.ViewDependencies: %w", err)
+		}
+		for _, d := range deps {
+			v.dependsOn = append(v.dependsOn, objectKey(d.Schema, d.Table))
+		}
+	}
+
+	// views can't reference each other in a cycle, so cyclic ones are only
+	// a result of an incomplete dependency list
+	sortedViews, cyclicViews := sortByDependencies(views)
+	for i, v := range append(sortedViews, cyclicViews...) {
+		ddl, err := provider.DDL(v.opts)
+		if err != nil {
+			return fmt.Errorf("provider.DDL: %w", err)
+		}
+
+		if i == 0 {
+			if _, err := fmt.Fprint(w, "-- Views\n\n"); err != nil {
+				return err
+			}
+		}
+		err = writeStatements(w, fmt.Sprintf("%s: %s", v.opts.Materialization, v.key()), ddl)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(constraints) > 0 {
+		if err := writeStatements(w, "Foreign keys", constraints...); err != nil {
+			return err
+		}
+	}
+	if len(indexes) > 0 {
+		if err := writeStatements(w, "Indexes", indexes...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeStatements writes a comment followed by the statements, each terminated with a semicolon.
+func writeStatements(w io.Writer, comment string, statements ...string) error {
+	_, err := fmt.Fprintf(w, "-- %s\n", comment)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range statements {
+		stmt = strings.TrimSpace(stmt)
+		if !strings.HasSuffix(stmt, ";") {
+			stmt += ";"
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", stmt); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprint(w, "\n")
+	return err
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	newObject := func(schema, table string, dependsOn ...string) *schemaObject {
+		return &schemaObject{
+			opts:      &TableOptions{Schema: schema, Table: table},
+			dependsOn: dependsOn,
+		}
+	}
+
+	keys := func(objects []*schemaObject) []string {
+		var out []string
+		for _, o := range objects {
+			out = append(out, o.key())
+		}
+		return out
+	}
+
+	type testCase struct {
+		name           string
+		input          []*schemaObject
+		expectedSorted []string
+		expectedCyclic []string
+	}
+
+	testCases := []testCase{
+		{
+			name: "no dependencies are sorted by name",
+			input: []*schemaObject{
+				newObject("public", "c"),
+				newObject("public", "a"),
+				newObject("public", "b"),
+			},
+			expectedSorted: []string{"public.a", "public.b", "public.c"},
+		},
+		{
+			name: "referenced tables come first",
+			input: []*schemaObject{
+				newObject("public", "order_items", "public.orders", "public.products"),
+				newObject("public", "orders", "public.users"),
+				newObject("public", "users"),
+				newObject("public", "products"),
+			},
+			expectedSorted: []string{"public.products", "public.users", "public.orders", "public.order_items"},
+		},
+		{
+			name: "self references and unknown tables are ignored",
+			input: []*schemaObject{
+				newObject("", "employees", "employees", "external.departments"),
+			},
+			expectedSorted: []string{"employees"},
+		},
+		{
+			name: "cycles are reported separately",
+			input: []*schemaObject{
+				newObject("s", "a", "s.b"),
+				newObject("s", "b", "s.a"),
+				newObject("s", "c", "s.a"),
+				newObject("s", "d"),
+			},
+			expectedSorted: []string{"s.d"},
+			expectedCyclic: []string{"s.a", "s.b", "s.c"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted, cyclic := sortByDependencies(tc.input)
+			require.Equal(t, tc.expectedSorted, keys(sorted))
+			require.Equal(t, tc.expectedCyclic, keys(cyclic))
+		})
+	}
+}
+
+// schemaDDLDriver is a driver that returns split definitions of its tables and views.
+type schemaDDLDriver struct {
+	sequenceDriver
+	structure []*Structure
+	// foreign keys per table
+	references map[string]string
+	// view dependencies per view
+	viewDependencies map[string][]string
+}
+
+func (d *schemaDDLDriver) Structure() ([]*Structure, error) { return d.structure, nil }
+
+func (d *schemaDDLDriver) DDL(opts *TableOptions) (string, error) {
+	return "CREATE VIEW " + opts.Table + " AS SELECT 1", nil
+}
+
+func (d *schemaDDLDriver) SchemaPrelude() ([]string, error) {
+	return []string{"CREATE SCHEMA s", "CREATE SEQUENCE s.ids"}, nil
+}
+
+func (d *schemaDDLDriver) TableDefinition(opts *TableOptions) (*TableDefinition, error) {
+	def := &TableDefinition{
+		Create:  "CREATE TABLE " + opts.Table + " (id int)",
+		Indexes: []string{"CREATE INDEX " + opts.Table + "_idx ON " + opts.Table + " (id)"},
+	}
+	if ref, ok := d.references[opts.Table]; ok {
+		def.Constraints = append(def.Constraints, "ALTER TABLE "+opts.Table+" ADD CONSTRAINT fk FOREIGN KEY (id) REFERENCES "+ref+" (id)")
+	}
+	return def, nil
+}
+
+func (d *schemaDDLDriver) ViewDependencies(opts *TableOptions) ([]*TableOptions, error) {
+	var deps []*TableOptions
+	for _, v := range d.viewDependencies[opts.Table] {
+		deps = append(deps, &TableOptions{Schema: opts.Schema, Table: v, Materialization: StructureTypeView})
+	}
+	return deps, nil
+}
+
+func TestConnection_ExportSchema(t *testing.T) {
+	r := require.New(t)
+
+	driver := &schemaDDLDriver{
+		structure: []*Structure{{
+			Name: "s",
+			Type: StructureTypeNone,
+			Children: []*Structure{
+				{Name: "v_orders", Schema: "s", Type: StructureTypeView},
+				{Name: "orders", Schema: "s", Type: StructureTypeTable},
+				{Name: "users", Schema: "s", Type: StructureTypeTable},
+				{Name: "v_all", Schema: "s", Type: StructureTypeView},
+			},
+		}},
+		// circular references
+		references:       map[string]string{"orders": "users", "users": "orders"},
+		viewDependencies: map[string][]string{"v_all": {"v_orders"}},
+	}
+
+	conn, err := NewConnection(&ConnectionParams{}, &initialAdapter{driver: driver})
+	r.NoError(err)
+
+	var out strings.Builder
+	r.NoError(conn.ExportSchema(&out))
+
+	expected := `-- Schemas, types and sequences
+CREATE SCHEMA s;
+CREATE SEQUENCE s.ids;
+
+-- Tables
+
+-- table: s.orders
+CREATE TABLE orders (id int);
+
+-- table: s.users
+CREATE TABLE users (id int);
+
+-- Views
+
+-- view: s.v_orders
+CREATE VIEW v_orders AS SELECT 1;
+
+-- view: s.v_all
+CREATE VIEW v_all AS SELECT 1;
+
+-- Foreign keys
+ALTER TABLE orders ADD CONSTRAINT fk FOREIGN KEY (id) REFERENCES users (id);
+ALTER TABLE users ADD CONSTRAINT fk FOREIGN KEY (id) REFERENCES orders (id);
+
+-- Indexes
+CREATE INDEX orders_idx ON orders (id);
+CREATE INDEX users_idx ON users (id);
+
+`
+	r.Equal(expected, out.String())
+}
+
+// foldingDDLDriver is a driver of a database that folds unquoted names to upper case
+// and only knows tables by their names in the catalog.
+type foldingDDLDriver struct {
+	sequenceDriver
+	structure []*Structure
+	// foreign keys per table
+	references map[string]string
+}
+
+func (d *foldingDDLDriver) Structure() ([]*Structure, error) { return d.structure, nil }
+func (d *foldingDDLDriver) IdentifierCase() IdentifierCase   { return IdentifierCaseUpper }
+
+func (d *foldingDDLDriver) DDL(opts *TableOptions) (string, error) {
+	for _, s := range d.structure[0].Children {
+		if s.Schema == opts.Schema && s.Name == opts.Table {
+			return `CREATE TABLE "` + opts.Table + `" (ID int)`, nil
+		}
+	}
+	return "", fmt.Errorf("table %s.%s does not exist", opts.Schema, opts.Table)
+}
+
+func (d *foldingDDLDriver) ForeignKeys(opts *TableOptions) ([]*ForeignKey, error) {
+	ref, ok := d.references[opts.Table]
+	if !ok {
+		return nil, nil
+	}
+	return []*ForeignKey{{Name: "FK", Column: "ID", ReferencedTable: ref, ReferencedColumn: "ID"}}, nil
+}
+
+func TestConnection_ExportSchema_FoldedIdentifiers(t *testing.T) {
+	r := require.New(t)
+
+	driver := &foldingDDLDriver{
+		structure: []*Structure{{
+			Name: "APP",
+			Type: StructureTypeNone,
+			Children: []*Structure{
+				{Name: "OrderItems", Schema: "APP", Type: StructureTypeTable},
+				{Name: "ORDERS", Schema: "APP", Type: StructureTypeTable},
+			},
+		}},
+		references: map[string]string{"OrderItems": "ORDERS"},
+	}
+
+	conn, err := NewConnection(&ConnectionParams{
+		Options: map[string]string{OptionFoldIdentifiers: "true"},
+	}, &initialAdapter{driver: driver})
+	r.NoError(err)
+
+	// names of the driver are already stored in the catalog, so they are not folded again
+	var out strings.Builder
+	r.NoError(conn.ExportSchema(&out))
+
+	expected := `-- Tables
+
+-- table: APP.ORDERS
+CREATE TABLE "ORDERS" (ID int);
+
+-- table: APP.OrderItems
+CREATE TABLE "OrderItems" (ID int);
+
+`
+	r.Equal(expected, out.String())
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"strings"
+)
+
+// SelectColumns sets which columns (and in which order) are displayed when formatting
+// the result. Names are matched exactly first and case-insensitively second.
+// Unknown names are ignored and returned. Passing no names resets the selection.
+// This only affects the output, rows are kept as they are.
+func (cr *Result) SelectColumns(names []string) (ignored []string) {
+	cr.readMutex.Lock()
+	defer cr.readMutex.Unlock()
+
+	if len(names) < 1 {
+		cr.columns = nil
+		return nil
+	}
+
+	columns := []int{}
+	for _, name := range names {
+		idx := cr.columnIndex(name)
+		if idx < 0 {
+			ignored = append(ignored, name)
+			continue
+		}
+		columns = append(columns, idx)
+	}
+
+	cr.columns = columns
+	return ignored
+}
+
+// columnIndex returns the index of the column in the header or -1 if not found.
+func (cr *Result) columnIndex(name string) int {
+	for i, h := range cr.header {
+		if h == name {
+			return i
+		}
+	}
+	for i, h := range cr.header {
+		if strings.EqualFold(h, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectedColumn returns the index in the header of the i-th displayed column.
+func (cr *Result) selectedColumn(i int) int {
+	if cr.columns == nil {
+		return i
+	}
+	if i < 0 || i >= len(cr.columns) {
+		return -1
+	}
+	return cr.columns[i]
+}
+
+// project applies the column selection to header and rows.
+func (cr *Result) project(header Header, rows []Row) (Header, []Row) {
+	if cr.columns == nil {
+		return header, rows
+	}
+
+	projectedHeader := make(Header, len(cr.columns))
+	for i, idx := range cr.columns {
+		projectedHeader[i] = header[idx]
+	}
+
+	projectedRows := make([]Row, len(rows))
+	for i, row := range rows {
+		projected := make(Row, len(cr.columns))
+		for j, idx := range cr.columns {
+			if idx < len(row) {
+				projected[j] = row[idx]
+			}
+		}
+		projectedRows[i] = projected
+	}
+
+	return projectedHeader, projectedRows
+}
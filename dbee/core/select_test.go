@@ -0,0 +1,49 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestResult_SelectColumns(t *testing.T) {
+	r := require.New(t)
+
+	result := new(core.Result)
+	err := result.SetIter(mock.NewResultStream([]core.Row{
+		{1, "a", true},
+		{2, "b", false},
+	}), nil)
+	r.NoError(err)
+
+	// reorder, case-insensitive match and unknown name
+	ignored := result.SelectColumns([]string{"header_2", "HEADER_0", "missing"})
+	r.Equal([]string{"missing"}, ignored)
+
+	out, err := result.Format(format.NewCSV(), 0, -1)
+	r.NoError(err)
+	r.Equal("header_2,header_0\ntrue,1\nfalse,2\n", string(out))
+
+	// rows are not modified
+	rows, err := result.Rows(0, -1)
+	r.NoError(err)
+	r.Equal([]core.Row{{1, "a", true}, {2, "b", false}}, rows)
+
+	// sort index refers to the displayed column
+	err = result.Sort(1, false)
+	r.NoError(err)
+	out, err = result.Format(format.NewCSV(), 0, -1)
+	r.NoError(err)
+	r.Equal("header_2,header_0\nfalse,2\ntrue,1\n", string(out))
+
+	// reset
+	ignored = result.SelectColumns(nil)
+	r.Empty(ignored)
+	out, err = result.Format(format.NewCSV(), 0, 1)
+	r.NoError(err)
+	r.Equal("header_0,header_1,header_2\n2,b,false\n", string(out))
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+// GetSequences returns sequences of the database. If opts are set, only sequences
+// owned by columns of the table are returned.
+func (c *Connection) GetSequences(opts *TableOptions) ([]*Sequence, error) {
+	lister, ok := c.driver.(SequenceLister)
+	if !ok {
+		return nil, ErrSequencesNotSupported
+	}
+
+	sequences, err := lister.Sequences()
+	if err != nil {
+		return nil, fmt.Errorf("lister.Sequences: %w", err)
+	}
+
+	if opts == nil {
+		return sequences, nil
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	var owned []*Sequence
+	for _, s := range sequences {
+		if s.Table == opts.Table && (opts.Schema == "" || s.Schema == opts.Schema) {
+			owned = append(owned, s)
+		}
+	}
+	return owned, nil
+}
+
+// Remaining returns the number of values the sequence can still return
+// before it's exhausted.
+func (s *Sequence) Remaining() uint64 {
+	if s.Increment == 0 || s.Max < s.Min {
+		return 0
+	}
+
+	// differences are computed on unsigned integers, so they don't overflow
+	step := uint64(s.Increment)
+	if s.Increment < 0 {
+		step = uint64(-s.Increment)
+	}
+
+	if s.Current == nil {
+		n := (uint64(s.Max) - uint64(s.Min)) / step
+		if n == math.MaxUint64 {
+			return n
+		}
+		return n + 1
+	}
+
+	current := *s.Current
+	if s.Increment > 0 {
+		if current >= s.Max {
+			return 0
+		}
+		return (uint64(s.Max) - uint64(current)) / step
+	}
+	if current <= s.Min {
+		return 0
+	}
+	return (uint64(current) - uint64(s.Min)) / step
+}
+
+// UsedPercent returns the used part of the sequence range in percent.
+func (s *Sequence) UsedPercent() float64 {
+	span := float64(s.Max) - float64(s.Min)
+	if s.Current == nil || span <= 0 {
+		return 0
+	}
+
+	used := float64(*s.Current) - float64(s.Min)
+	if s.Increment < 0 {
+		used = float64(s.Max) - float64(*s.Current)
+	}
+	return math.Max(0, math.Min(100, 100*used/span))
+}
+
+// SequenceFromRow converts a row of schema, name, owning table, owning column,
+// current value, increment, min and max values returned by a sequence query.
+// Numbers can also be in their text form and a NULL current value means
+// the sequence wasn't used yet.
+func SequenceFromRow(row Row) (*Sequence, error) {
+	if len(row) < 8 {
+		return nil, fmt.Errorf("expected 8 sequence columns, got %d", len(row))
+	}
+
+	str := func(val any) string {
+		switch v := val.(type) {
+		case nil:
+			return ""
+		case []byte:
+			return string(v)
+		}
+		return fmt.Sprint(val)
+	}
+
+	s := &Sequence{
+		Schema: str(row[0]),
+		Name:   str(row[1]),
+		Table:  str(row[2]),
+		Column: str(row[3]),
+	}
+
+	if row[4] != nil {
+		current, err := parseInt64(row[4])
+		if err != nil {
+			return nil, err
+		}
+		s.Current = &current
+	}
+
+	for i, dst := range []*int64{&s.Increment, &s.Min, &s.Max} {
+		n, err := parseInt64(row[5+i])
+		if err != nil {
+			return nil, err
+		}
+		*dst = n
+	}
+
+	return s, nil
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceDriver lists static sequences.
+type sequenceDriver struct {
+	sequences []*Sequence
+}
+
+func (d *sequenceDriver) Query(_ context.Context, _ string) (ResultStream, error) { return nil, nil }
+func (d *sequenceDriver) Structure() ([]*Structure, error)                        { return nil, nil }
+func (d *sequenceDriver) Columns(_ *TableOptions) ([]*Column, error)              { return nil, nil }
+func (d *sequenceDriver) Close()                                                  {}
+func (d *sequenceDriver) Sequences() ([]*Sequence, error)                         { return d.sequences, nil }
+
+func TestSequence_Remaining(t *testing.T) {
+	r := require.New(t)
+
+	current := func(n int64) *int64 { return &n }
+
+	// int4 serial
+	s := &Sequence{Current: current(2147483000), Increment: 1, Min: 1, Max: math.MaxInt32}
+	r.Equal(uint64(647), s.Remaining())
+	r.InDelta(99.99997, s.UsedPercent(), 0.00001)
+
+	// unused sequence
+	s = &Sequence{Increment: 10, Min: 1, Max: 100}
+	r.Equal(uint64(10), s.Remaining())
+	r.Zero(s.UsedPercent())
+
+	// descending sequence
+	s = &Sequence{Current: current(-50), Increment: -5, Min: -100, Max: -1}
+	r.Equal(uint64(10), s.Remaining())
+
+	// exhausted sequence
+	s = &Sequence{Current: current(100), Increment: 1, Min: 1, Max: 100}
+	r.Zero(s.Remaining())
+	r.Equal(100.0, s.UsedPercent())
+
+	// full int64 range doesn't overflow
+	s = &Sequence{Current: current(math.MinInt64), Increment: 1, Min: math.MinInt64, Max: math.MaxInt64}
+	r.Equal(uint64(math.MaxUint64), s.Remaining())
+}
+
+func TestSequenceFromRow(t *testing.T) {
+	r := require.New(t)
+
+	s, err := SequenceFromRow(Row{"public", "users_id_seq", "users", "id", int64(42), "1", []byte("1"), uint64(math.MaxInt64)})
+	r.NoError(err)
+	current := int64(42)
+	r.Equal(&Sequence{
+		Schema:    "public",
+		Name:      "users_id_seq",
+		Table:     "users",
+		Column:    "id",
+		Current:   &current,
+		Increment: 1,
+		Min:       1,
+		Max:       math.MaxInt64,
+	}, s)
+
+	s, err = SequenceFromRow(Row{"public", "free_seq", nil, nil, nil, int64(1), int64(1), int64(10)})
+	r.NoError(err)
+	r.Nil(s.Current)
+	r.Empty(s.Table)
+
+	_, err = SequenceFromRow(Row{"public", "users_id_seq"})
+	r.Error(err)
+}
+
+func TestConnection_GetSequences(t *testing.T) {
+	r := require.New(t)
+
+	driver := &sequenceDriver{
+		sequences: []*Sequence{
+			{Schema: "public", Name: "users_id_seq", Table: "users"},
+			{Schema: "other", Name: "users_id_seq", Table: "users"},
+			{Schema: "public", Name: "free_seq"},
+		},
+	}
+	c := &Connection{driver: driver}
+
+	sequences, err := c.GetSequences(nil)
+	r.NoError(err)
+	r.Len(sequences, 3)
+
+	sequences, err = c.GetSequences(&TableOptions{Schema: "public", Table: "users"})
+	r.NoError(err)
+	r.Equal([]*Sequence{driver.sequences[0]}, sequences)
+
+	sequences, err = c.GetSequences(&TableOptions{Table: "users"})
+	r.NoError(err)
+	r.Len(sequences, 2)
+
+	c = &Connection{driver: &switchingDriver{}}
+	_, err = c.GetSequences(nil)
+	r.ErrorIs(err, ErrSequencesNotSupported)
+}
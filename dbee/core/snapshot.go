@@ -0,0 +1,290 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot is a deterministic serialization of a query result, which can be stored
+// and compared with results of later runs (e.g. in data tests).
+type Snapshot struct {
+	Columns []string
+	// Rows hold json encodings of rows (see snapshotValue)
+	Rows []string
+}
+
+// SnapshotOptions control how the result is compared with the stored snapshot.
+type SnapshotOptions struct {
+	// Unordered compares rows regardless of their order.
+	// Rows of the stored snapshot are sorted.
+	Unordered bool
+	// Update overwrites the stored snapshot with the result instead of comparing.
+	Update bool
+}
+
+// SnapshotRow is a row of a snapshot diff.
+type SnapshotRow struct {
+	// Row number (1 based) in the snapshot (missing rows) or in the result (unexpected rows)
+	Row int
+	// Json encoded values
+	Values string
+}
+
+// SnapshotDiff is the result of comparing a query result with a stored snapshot.
+type SnapshotDiff struct {
+	// Match is true if the result matches the snapshot
+	Match bool
+	// Updated is true if the snapshot was written instead of compared
+	Updated bool
+	// Columns are only set if they differ (rows aren't compared in that case)
+	ExpectedColumns []string
+	ActualColumns   []string
+	// Missing rows are in the snapshot, but not in the result
+	Missing []SnapshotRow
+	// Unexpected rows are in the result, but not in the snapshot
+	Unexpected []SnapshotRow
+}
+
+// snapshotValue converts the value to a json value with a stable encoding.
+// Numbers stay numbers (so 1 and "1" differ), times are formatted as RFC3339
+// and binary values are hex encoded.
+func snapshotValue(val any) any {
+	switch v := val.(type) {
+	case nil, bool, string, json.Number:
+		return v
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return json.Number(fmt.Sprint(v))
+	case float32:
+		return snapshotFloat(float64(v))
+	case float64:
+		return snapshotFloat(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return `\x` + hex.EncodeToString(v)
+	}
+
+	// maps and slices are decoded back, so keys are sorted and numbers kept
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	var out any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return fmt.Sprint(val)
+	}
+	return out
+}
+
+func snapshotFloat(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// NewSnapshot drains the result stream into a snapshot.
+func NewSnapshot(rows ResultStream) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Columns: rows.Header(),
+		Rows:    []string{},
+	}
+	if snapshot.Columns == nil {
+		snapshot.Columns = []string{}
+	}
+
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("rows.Next: %w", err)
+		}
+
+		values := make([]any, len(row))
+		for i, val := range row {
+			values[i] = snapshotValue(val)
+		}
+
+		b, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		snapshot.Rows = append(snapshot.Rows, string(b))
+	}
+
+	return snapshot, nil
+}
+
+// ReadSnapshot reads the snapshot from a json file.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Columns []string          `json:"columns"`
+		Rows    []json.RawMessage `json:"rows"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %q: %w", path, err)
+	}
+
+	snapshot := &Snapshot{
+		Columns: file.Columns,
+		Rows:    make([]string, len(file.Rows)),
+	}
+	if snapshot.Columns == nil {
+		snapshot.Columns = []string{}
+	}
+
+	// re-encode the rows, so formatting of the file doesn't matter
+	for i, raw := range file.Rows {
+		var values []any
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("invalid snapshot %q: row %d: %w", path, i+1, err)
+		}
+
+		row, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		snapshot.Rows[i] = string(row)
+	}
+
+	return snapshot, nil
+}
+
+// Write writes the snapshot to a json file with a row per line, so changes
+// of the snapshot are easy to review.
+func (s *Snapshot) Write(path string) error {
+	columns, err := json.Marshal(s.Columns)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n  \"columns\": " + string(columns) + ",\n  \"rows\": [")
+	for i, row := range s.Rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n    " + row)
+	}
+	if len(s.Rows) > 0 {
+		b.WriteString("\n  ")
+	}
+	b.WriteString("]\n}\n")
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// CompareSnapshots compares the actual snapshot with the expected one. Ordered rows are
+// compared by position, unordered rows are matched regardless of their position.
+func CompareSnapshots(expected, actual *Snapshot, unordered bool) *SnapshotDiff {
+	if !slices.Equal(expected.Columns, actual.Columns) {
+		return &SnapshotDiff{
+			ExpectedColumns: expected.Columns,
+			ActualColumns:   actual.Columns,
+		}
+	}
+
+	diff := &SnapshotDiff{}
+
+	if unordered {
+		// number of unmatched expected rows
+		remaining := make(map[string]int, len(expected.Rows))
+		for _, row := range expected.Rows {
+			remaining[row]++
+		}
+		for i, row := range actual.Rows {
+			if remaining[row] > 0 {
+				remaining[row]--
+				continue
+			}
+			diff.Unexpected = append(diff.Unexpected, SnapshotRow{Row: i + 1, Values: row})
+		}
+		// report the last duplicates as missing
+		for i := len(expected.Rows) - 1; i >= 0; i-- {
+			row := expected.Rows[i]
+			if remaining[row] > 0 {
+				remaining[row]--
+				diff.Missing = append(diff.Missing, SnapshotRow{Row: i + 1, Values: row})
+			}
+		}
+		slices.Reverse(diff.Missing)
+	} else {
+		for i := 0; i < max(len(expected.Rows), len(actual.Rows)); i++ {
+			if i < len(expected.Rows) && i < len(actual.Rows) && expected.Rows[i] == actual.Rows[i] {
+				continue
+			}
+			if i < len(expected.Rows) {
+				diff.Missing = append(diff.Missing, SnapshotRow{Row: i + 1, Values: expected.Rows[i]})
+			}
+			if i < len(actual.Rows) {
+				diff.Unexpected = append(diff.Unexpected, SnapshotRow{Row: i + 1, Values: actual.Rows[i]})
+			}
+		}
+	}
+
+	diff.Match = len(diff.Missing) == 0 && len(diff.Unexpected) == 0
+	return diff
+}
+
+// AssertSnapshot runs the query synchronously and compares the result with the snapshot
+// stored in the file. If opts.Update is set, the snapshot is written instead.
+func (c *Connection) AssertSnapshot(ctx context.Context, query, path string, opts *SnapshotOptions) (*SnapshotDiff, error) {
+	if opts == nil {
+		opts = &SnapshotOptions{}
+	}
+
+	query, err := c.prepareSyncQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("c.query: %w", err)
+	}
+	defer rows.Close()
+
+	actual, err := NewSnapshot(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Update {
+		if opts.Unordered {
+			sort.Strings(actual.Rows)
+		}
+		if err := actual.Write(path); err != nil {
+			return nil, fmt.Errorf("actual.Write: %w", err)
+		}
+		return &SnapshotDiff{Match: true, Updated: true}, nil
+	}
+
+	expected, err := ReadSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("snapshot %q doesn't exist: run with update to create it", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareSnapshots(expected, actual, opts.Unordered), nil
+}
@@ -0,0 +1,106 @@
+package core_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestConnection_AssertSnapshot(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "expected.json")
+
+	newConnection := func(rows []core.Row) *core.Connection {
+		adapter := mock.NewAdapter(rows,
+			mock.AdapterWithResultStreamOpts(
+				mock.ResultStreamWithHeader(core.Header{"id", "name", "created"}),
+			),
+		)
+		c, err := core.NewConnection(&core.ConnectionParams{}, adapter)
+		r.NoError(err)
+		return c
+	}
+
+	ts := time.Date(2024, 3, 5, 14, 30, 15, 0, time.UTC)
+	c := newConnection([]core.Row{
+		{2, "bob", nil},
+		{1, "alice", ts},
+	})
+
+	// missing snapshot
+	_, err := c.AssertSnapshot(context.Background(), "select 1", path, nil)
+	r.ErrorContains(err, "doesn't exist")
+
+	// update writes sorted rows of unordered snapshots
+	diff, err := c.AssertSnapshot(context.Background(), "select 1", path, &core.SnapshotOptions{Update: true, Unordered: true})
+	r.NoError(err)
+	r.Equal(&core.SnapshotDiff{Match: true, Updated: true}, diff)
+
+	b, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Equal(`{
+  "columns": ["id","name","created"],
+  "rows": [
+    [1,"alice","2024-03-05T14:30:15Z"],
+    [2,"bob",null]
+  ]
+}
+`, string(b))
+
+	// order matters, unless unordered
+	diff, err = c.AssertSnapshot(context.Background(), "select 1", path, &core.SnapshotOptions{Unordered: true})
+	r.NoError(err)
+	r.True(diff.Match)
+
+	diff, err = c.AssertSnapshot(context.Background(), "select 1", path, nil)
+	r.NoError(err)
+	r.Equal(&core.SnapshotDiff{
+		Missing: []core.SnapshotRow{
+			{Row: 1, Values: `[1,"alice","2024-03-05T14:30:15Z"]`},
+			{Row: 2, Values: `[2,"bob",null]`},
+		},
+		Unexpected: []core.SnapshotRow{
+			{Row: 1, Values: `[2,"bob",null]`},
+			{Row: 2, Values: `[1,"alice","2024-03-05T14:30:15Z"]`},
+		},
+	}, diff)
+
+	// values are typed
+	c = newConnection([]core.Row{
+		{"1", "alice", ts},
+		{2, "bob", nil},
+		{3, "carol", nil},
+	})
+	diff, err = c.AssertSnapshot(context.Background(), "select 1", path, &core.SnapshotOptions{Unordered: true})
+	r.NoError(err)
+	r.Equal(&core.SnapshotDiff{
+		Missing: []core.SnapshotRow{
+			{Row: 1, Values: `[1,"alice","2024-03-05T14:30:15Z"]`},
+		},
+		Unexpected: []core.SnapshotRow{
+			{Row: 1, Values: `["1","alice","2024-03-05T14:30:15Z"]`},
+			{Row: 3, Values: `[3,"carol",null]`},
+		},
+	}, diff)
+
+	// different columns
+	adapter := mock.NewAdapter([]core.Row{{1}}, mock.AdapterWithResultStreamOpts(
+		mock.ResultStreamWithHeader(core.Header{"id"}),
+	))
+	c, err = core.NewConnection(&core.ConnectionParams{}, adapter)
+	r.NoError(err)
+	diff, err = c.AssertSnapshot(context.Background(), "select 1", path, nil)
+	r.NoError(err)
+	r.Equal(&core.SnapshotDiff{
+		ExpectedColumns: []string{"id", "name", "created"},
+		ActualColumns:   []string{"id"},
+	}, diff)
+}
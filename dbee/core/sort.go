@@ -0,0 +1,210 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrResultNotDrained = errors.New("result is still being streamed, add ORDER BY to the query instead")
+
+// sortTimeLayouts are layouts tried when comparing string values as dates.
+var sortTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Sort sorts the already fetched rows by the column index (zero based).
+// If columns are selected (see SelectColumns), the index is of the displayed column.
+// The sort is stable and compares values by type: numbers numerically,
+// dates chronologically and everything else as strings. NULLs go last.
+// If part of the result is spilled to disk, all rows are loaded into memory
+// for sorting and the rows past the memory limit are spilled again.
+func (cr *Result) Sort(column int, ascending bool) error {
+	// result that is still being filled can't be sorted
+	if !cr.writeMutex.TryLock() {
+		return ErrResultNotDrained
+	}
+	defer cr.writeMutex.Unlock()
+	cr.readMutex.Lock()
+	defer cr.readMutex.Unlock()
+
+	if !cr.isDrained {
+		return ErrResultNotDrained
+	}
+	displayed := column
+	column = cr.selectedColumn(column)
+	if column < 0 || column >= len(cr.header) {
+		return fmt.Errorf("invalid column index: %d", displayed)
+	}
+
+	value := func(row Row) any {
+		if column < len(row) {
+			return row[column]
+		}
+		return nil
+	}
+
+	rows := cr.rows
+	if cr.spill != nil {
+		spilled, err := cr.spill.rows(0, cr.spill.len())
+		if err != nil {
+			return fmt.Errorf("cr.spill.rows: %w", err)
+		}
+		rows = append(rows[:len(rows):len(rows)], spilled...)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := value(rows[i]), value(rows[j])
+
+		// nulls are always last
+		if isNull(a) || isNull(b) {
+			return !isNull(a) && isNull(b)
+		}
+
+		if ascending {
+			return compareValues(a, b) < 0
+		}
+		return compareValues(a, b) > 0
+	})
+
+	if cr.spill == nil {
+		return nil
+	}
+
+	cr.closeSpill()
+	cr.rows = []Row{}
+	for _, row := range rows {
+		if err := cr.addRow(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isNull(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case []byte:
+		return val == nil
+	}
+	return false
+}
+
+// compareValues compares a and b and returns -1, 0 or 1.
+func compareValues(a, b any) int {
+	if na, ok := sortNumber(a); ok {
+		if nb, ok := sortNumber(b); ok {
+			return na.Cmp(nb)
+		}
+	}
+
+	if ta, ok := sortTime(a); ok {
+		if tb, ok := sortTime(b); ok {
+			return ta.Compare(tb)
+		}
+	}
+
+	return strings.Compare(sortString(a), sortString(b))
+}
+
+// sortNumber returns the numeric value of v.
+// Strings are considered numbers if they can be parsed as such.
+func sortNumber(v any) (*big.Float, bool) {
+	switch val := v.(type) {
+	case int:
+		return big.NewFloat(0).SetInt64(int64(val)), true
+	case int8:
+		return big.NewFloat(0).SetInt64(int64(val)), true
+	case int16:
+		return big.NewFloat(0).SetInt64(int64(val)), true
+	case int32:
+		return big.NewFloat(0).SetInt64(int64(val)), true
+	case int64:
+		return big.NewFloat(0).SetInt64(val), true
+	case uint:
+		return big.NewFloat(0).SetUint64(uint64(val)), true
+	case uint8:
+		return big.NewFloat(0).SetUint64(uint64(val)), true
+	case uint16:
+		return big.NewFloat(0).SetUint64(uint64(val)), true
+	case uint32:
+		return big.NewFloat(0).SetUint64(uint64(val)), true
+	case uint64:
+		return big.NewFloat(0).SetUint64(val), true
+	case float32:
+		return sortFloat(float64(val))
+	case float64:
+		return sortFloat(val)
+	case json.Number:
+		return sortNumberString(string(val))
+	case string:
+		return sortNumberString(val)
+	case []byte:
+		return sortNumberString(string(val))
+	}
+	return nil, false
+}
+
+func sortFloat(f float64) (*big.Float, bool) {
+	// NaN can't be represented
+	if math.IsNaN(f) {
+		return nil, false
+	}
+	return big.NewFloat(f), true
+}
+
+func sortNumberString(s string) (*big.Float, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+	f, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil, false
+	}
+	return f, true
+}
+
+func sortTime(v any) (time.Time, bool) {
+	var s string
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		s = val
+	case []byte:
+		s = string(val)
+	default:
+		return time.Time{}, false
+	}
+
+	s = strings.TrimSpace(s)
+	for _, layout := range sortTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func sortString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,112 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestResult_Sort(t *testing.T) {
+	type testCase struct {
+		name      string
+		input     []core.Row
+		column    int
+		ascending bool
+		expected  []core.Row
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []testCase{
+		{
+			name:      "numbers",
+			input:     []core.Row{{0, 10}, {1, 2.5}, {2, int64(-1)}, {3, uint8(2)}},
+			column:    1,
+			ascending: true,
+			expected:  []core.Row{{2, int64(-1)}, {3, uint8(2)}, {1, 2.5}, {0, 10}},
+		},
+		{
+			name:      "numeric strings are compared as numbers",
+			input:     []core.Row{{0, "10"}, {1, "9"}, {2, "100"}},
+			column:    1,
+			ascending: true,
+			expected:  []core.Row{{1, "9"}, {0, "10"}, {2, "100"}},
+		},
+		{
+			name:      "dates",
+			input:     []core.Row{{0, day(3)}, {1, day(1)}, {2, day(2)}},
+			column:    1,
+			ascending: false,
+			expected:  []core.Row{{0, day(3)}, {2, day(2)}, {1, day(1)}},
+		},
+		{
+			name:      "date strings",
+			input:     []core.Row{{0, "2024-01-10 12:00:00"}, {1, "2024-01-09 13:00:00"}, {2, "2024-01-10"}},
+			column:    1,
+			ascending: true,
+			expected:  []core.Row{{1, "2024-01-09 13:00:00"}, {2, "2024-01-10"}, {0, "2024-01-10 12:00:00"}},
+		},
+		{
+			name:      "strings",
+			input:     []core.Row{{0, "banana"}, {1, "apple"}, {2, "cherry"}},
+			column:    1,
+			ascending: false,
+			expected:  []core.Row{{2, "cherry"}, {0, "banana"}, {1, "apple"}},
+		},
+		{
+			name:      "stable with nulls last",
+			input:     []core.Row{{0, nil}, {1, "b"}, {2, "a"}, {3, "b"}, {4, nil}},
+			column:    1,
+			ascending: false,
+			expected:  []core.Row{{1, "b"}, {3, "b"}, {2, "a"}, {0, nil}, {4, nil}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			result := new(core.Result)
+			err := result.SetIter(mock.NewResultStream(tc.input), nil)
+			r.NoError(err)
+
+			err = result.Sort(tc.column, tc.ascending)
+			r.NoError(err)
+
+			rows, err := result.Rows(0, -1)
+			r.NoError(err)
+			r.Equal(tc.expected, rows)
+		})
+	}
+}
+
+func TestResult_SortErrors(t *testing.T) {
+	r := require.New(t)
+
+	result := new(core.Result)
+
+	// not filled yet
+	err := result.Sort(0, true)
+	r.ErrorIs(err, core.ErrResultNotDrained)
+
+	// still streaming
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = result.SetIter(mock.NewResultStream(mock.NewRows(0, 5), mock.ResultStreamWithNextSleep(100*time.Millisecond)), nil)
+	}()
+	err = result.Sort(0, true)
+	r.ErrorIs(err, core.ErrResultNotDrained)
+	<-done
+
+	// invalid column
+	err = result.Sort(20, true)
+	r.Error(err)
+	r.NotErrorIs(err, core.ErrResultNotDrained)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"fmt"
+)
+
+// GetTableSize returns the storage size of the table.
+func (c *Connection) GetTableSize(opts *TableOptions) (*TableSize, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("opts cannot be nil")
+	}
+	opts = c.identCase.catalogOptions(opts)
+
+	sizer, ok := c.driver.(TableSizer)
+	if !ok {
+		return nil, ErrTableSizeNotSupported
+	}
+
+	size, err := sizer.TableSize(opts)
+	if err != nil {
+		return nil, fmt.Errorf("sizer.TableSize: %w", err)
+	}
+
+	return size, nil
+}
+
+// FormatBytes formats the number of bytes in binary units (e.g. "1.5 MiB").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	i := -1
+	for (value >= unit || value <= -unit) && i < 5 {
+		value /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTPE"[i])
+}
+
+// TableSizeFromRow converts a row of total, data, index and overhead sizes (numbers
+// or their text form, NULL is 0) returned by a size query.
+func TableSizeFromRow(row Row) (*TableSize, error) {
+	if len(row) < 4 {
+		return nil, fmt.Errorf("expected 4 size columns, got %d", len(row))
+	}
+
+	sizes := make([]int64, 4)
+	for i, val := range row[:4] {
+		if val == nil {
+			continue
+		}
+		n, err := parseInt64(val)
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = n
+	}
+
+	return &TableSize{
+		Total:    sizes[0],
+		Data:     sizes[1],
+		Index:    sizes[2],
+		Overhead: sizes[3],
+	}, nil
+}
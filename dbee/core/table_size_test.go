@@ -0,0 +1,31 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatBytes(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("0 B", FormatBytes(0))
+	r.Equal("1023 B", FormatBytes(1023))
+	r.Equal("1.0 KiB", FormatBytes(1024))
+	r.Equal("1.5 MiB", FormatBytes(3<<19))
+	r.Equal("8.0 EiB", FormatBytes(1<<63-1))
+	r.Equal("-2.0 GiB", FormatBytes(-2<<30))
+}
+
+func TestTableSizeFromRow(t *testing.T) {
+	r := require.New(t)
+
+	size, err := TableSizeFromRow(Row{int64(3072), uint64(2048), []byte("1024"), nil})
+	r.NoError(err)
+	r.Equal(&TableSize{Total: 3072, Data: 2048, Index: 1024}, size)
+
+	_, err = TableSizeFromRow(Row{int64(1), int64(1)})
+	r.Error(err)
+	_, err = TableSizeFromRow(Row{"a", "b", "c", "d"})
+	r.Error(err)
+}
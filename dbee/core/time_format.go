@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidTimeFormat is returned by ParseTimeFormat for layouts without any time elements.
+	ErrInvalidTimeFormat = errors.New("invalid time format")
+	// ErrInvalidTimeZone is returned by ParseTimeFormat for unknown zones.
+	ErrInvalidTimeZone = errors.New("invalid time zone")
+)
+
+// TimeFormat determines how time values are rendered in the output.
+type TimeFormat struct {
+	// go time layout (empty keeps the value as is)
+	layout string
+	// render as unix seconds instead of using layout
+	epoch bool
+	// location to convert the value to (nil keeps the zone reported by the server)
+	location *time.Location
+}
+
+// ParseTimeFormat creates a time format from a preset ("rfc3339", "epoch",
+// "date-only", "datetime") or a go time layout and a zone ("utc", "local",
+// "server" or an IANA zone name like "Europe/Ljubljana").
+// If both are empty, nil is returned, which means time values are not touched.
+func ParseTimeFormat(format, zone string) (*TimeFormat, error) {
+	if format == "" && zone == "" {
+		return nil, nil
+	}
+
+	tf := new(TimeFormat)
+
+	switch strings.ToLower(format) {
+	case "":
+	case "rfc3339":
+		tf.layout = time.RFC3339
+	case "epoch":
+		tf.epoch = true
+	case "date-only":
+		tf.layout = time.DateOnly
+	case "datetime":
+		tf.layout = time.DateTime
+	default:
+		// a layout without elements renders every value as itself
+		if time.Unix(0, 0).UTC().Format(format) == format {
+			return nil, fmt.Errorf("%w: layout %q has no time elements", ErrInvalidTimeFormat, format)
+		}
+		tf.layout = format
+	}
+
+	switch strings.ToLower(zone) {
+	case "", "server":
+	case "utc":
+		tf.location = time.UTC
+	case "local":
+		tf.location = time.Local
+	default:
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time.LoadLocation: %w", ErrInvalidTimeZone, err)
+		}
+		tf.location = loc
+	}
+
+	return tf, nil
+}
+
+// Apply formats the value if it's a time value. Other values are returned unchanged.
+func (tf *TimeFormat) Apply(val any) any {
+	t, ok := val.(time.Time)
+	if !ok || tf == nil {
+		return val
+	}
+
+	if tf.location != nil {
+		t = t.In(tf.location)
+	}
+
+	if tf.epoch {
+		return t.Unix()
+	}
+	if tf.layout == "" {
+		return t
+	}
+
+	return t.Format(tf.layout)
+}
+
+var _ Formatter = (*timeFormatter)(nil)
+
+// timeFormatter applies the time format to rows before passing them to the wrapped formatter.
+type timeFormatter struct {
+	formatter Formatter
+	format    *TimeFormat
+}
+
+// NewTimeFormatter wraps the formatter, so that time values are rendered using the time format.
+// If the time format is nil, the original formatter is returned.
+func NewTimeFormatter(formatter Formatter, format *TimeFormat) Formatter {
+	if format == nil {
+		return formatter
+	}
+
+	return &timeFormatter{
+		formatter: formatter,
+		format:    format,
+	}
+}
+
+func (tf *timeFormatter) Format(header Header, rows []Row, opts *FormatterOptions) ([]byte, error) {
+	// copy rows, as they are shared with the cached result
+	formatted := make([]Row, len(rows))
+	for i, row := range rows {
+		formatted[i] = make(Row, len(row))
+		for j, val := range row {
+			formatted[i][j] = tf.format.Apply(val)
+		}
+	}
+
+	return tf.formatter.Format(header, formatted, opts)
+}
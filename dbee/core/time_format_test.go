@@ -0,0 +1,118 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+func TestTimeFormat_Apply(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 14, 30, 15, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name   string
+		format string
+		zone   string
+		want   any
+	}{
+		{name: "rfc3339 server zone", format: "rfc3339", want: "2024-03-05T14:30:15+01:00"},
+		{name: "rfc3339 utc", format: "RFC3339", zone: "utc", want: "2024-03-05T13:30:15Z"},
+		{name: "epoch", format: "epoch", want: int64(1709645415)},
+		{name: "date only", format: "date-only", want: "2024-03-05"},
+		{name: "datetime", format: "datetime", zone: "utc", want: "2024-03-05 13:30:15"},
+		{name: "go layout", format: "02.01.2006 15:04", want: "05.03.2024 14:30"},
+		{name: "zone only", zone: "UTC", want: ts.UTC()},
+		{name: "iana zone", format: "15:04 MST", zone: "America/New_York", want: "08:30 EST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tf, err := core.ParseTimeFormat(tt.format, tt.zone)
+			r.NoError(err)
+			r.Equal(tt.want, tf.Apply(ts))
+
+			// other values are not touched
+			r.Equal("text", tf.Apply("text"))
+			r.Nil(tf.Apply(nil))
+		})
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	r := require.New(t)
+
+	tf, err := core.ParseTimeFormat("", "")
+	r.NoError(err)
+	r.Nil(tf)
+
+	_, err = core.ParseTimeFormat("", "Not/AZone")
+	r.ErrorIs(err, core.ErrInvalidTimeZone)
+
+	_, err = core.ParseTimeFormat("iso", "")
+	r.ErrorIs(err, core.ErrInvalidTimeFormat)
+}
+
+func TestNewTimeFormatter(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 14, 30, 15, 0, time.UTC)
+
+	header := core.Header{"id", "created"}
+	rows := []core.Row{{1, ts}, {2, nil}}
+	opts := &core.FormatterOptions{SchemaType: core.SchemaFul}
+
+	tf, err := core.ParseTimeFormat("date-only", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		formatter core.Formatter
+		want      string
+	}{
+		{
+			name:      "csv",
+			formatter: format.NewCSV(),
+			want:      "id,created\n1,2024-03-05\n2,<nil>\n",
+		},
+		{
+			name:      "json",
+			formatter: format.NewJSON(),
+			want: `[
+  {
+    "created": "2024-03-05",
+    "id": 1
+  },
+  {
+    "created": null,
+    "id": 2
+  }
+]`,
+		},
+		{
+			name:      "yaml",
+			formatter: format.NewYAML(),
+			want:      "- id: 1\n  created: \"2024-03-05\"\n- id: 2\n  created: null\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			out, err := core.NewTimeFormatter(tt.formatter, tf).Format(header, rows, opts)
+			r.NoError(err)
+			r.Equal(tt.want, string(out))
+
+			// original rows are not modified
+			r.Equal(ts, rows[0][1])
+		})
+	}
+
+	// nil format returns the original formatter
+	csv := format.NewCSV()
+	require.Equal(t, core.Formatter(csv), core.NewTimeFormatter(csv, nil))
+}
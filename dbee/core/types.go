@@ -1,6 +1,10 @@
 package core
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type SchemaType int
 
@@ -31,6 +35,18 @@ type (
 	Meta struct {
 		// type of schema (schemaful or schemaless)
 		SchemaType SchemaType
+		// Stats are query statistics reported by the database (e.g. execution time).
+		// Drivers fill them once the stream is drained.
+		Stats map[string]string
+		// Limit is the row limit dbee added to the query (see OptionDefaultLimit),
+		// zero if the query was run as written.
+		Limit int
+		// Endpoint is the server which ran the query ("primary" or the host of a replica)
+		// if the connection has read replicas (see OptionReplicas), empty otherwise.
+		Endpoint string
+		// Warnings are non-fatal problems reported by the database for the query
+		// (e.g. truncated values). Drivers fill them once the stream is drained.
+		Warnings []string
 	}
 
 	// ResultStream is a result from executed query and has a form of an iterator
@@ -41,14 +57,49 @@ type (
 		HasNext() bool
 		Close()
 	}
+
+	// FailableStream is an optional interface for result streams which can report the
+	// error that ended them early (HasNext returns false both at the end and on errors).
+	FailableStream interface {
+		Err() error
+	}
 )
 
+// Keys returns header names that can be used as unique keys of a record.
+// Duplicate column names are suffixed with their column index
+// (e.g. "id", "name", "id" becomes "id", "name", "id_2"). If the suffixed name
+// is taken as well, the suffix is increased until the key is unique.
+func (h Header) Keys() []string {
+	keys := make([]string, len(h))
+	seen := make(map[string]bool, len(h))
+	for i, name := range h {
+		key := name
+		for n := i; seen[key]; n++ {
+			key = fmt.Sprintf("%s_%d", name, n)
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
 type StructureType int
 
 const (
 	StructureTypeNone StructureType = iota
 	StructureTypeTable
 	StructureTypeView
+	StructureTypeProcedure
+	StructureTypeFunction
+	StructureTypeSequence
+	// StructureTypeKuduTable is an Impala table stored in Apache Kudu.
+	StructureTypeKuduTable
+	// StructureTypeExternalTable is a table reading files of an external data source (e.g. of Synapse).
+	StructureTypeExternalTable
+	// StructureTypePartition is a partition of a partitioned table, listed under its parent table.
+	StructureTypePartition
+	// StructureTypeIndex is an index, listed under its table.
+	StructureTypeIndex
 )
 
 func (s StructureType) String() string {
@@ -59,6 +110,20 @@ func (s StructureType) String() string {
 		return "table"
 	case StructureTypeView:
 		return "view"
+	case StructureTypeProcedure:
+		return "procedure"
+	case StructureTypeFunction:
+		return "function"
+	case StructureTypeSequence:
+		return "sequence"
+	case StructureTypeKuduTable:
+		return "kudu_table"
+	case StructureTypeExternalTable:
+		return "external_table"
+	case StructureTypePartition:
+		return "partition"
+	case StructureTypeIndex:
+		return "index"
 	default:
 		return ""
 	}
@@ -70,6 +135,20 @@ func StructureTypeFromString(s string) StructureType {
 		return StructureTypeTable
 	case "view":
 		return StructureTypeView
+	case "procedure":
+		return StructureTypeProcedure
+	case "function":
+		return StructureTypeFunction
+	case "sequence":
+		return StructureTypeSequence
+	case "kudu_table":
+		return StructureTypeKuduTable
+	case "external_table":
+		return StructureTypeExternalTable
+	case "partition":
+		return StructureTypePartition
+	case "index":
+		return StructureTypeIndex
 	default:
 		return StructureTypeNone
 	}
@@ -82,6 +161,8 @@ type Structure struct {
 	Schema string
 	// Type of layout
 	Type StructureType
+	// Parent table of a partition (empty otherwise)
+	Parent string
 	// Children layout nodes
 	Children []*Structure
 }
@@ -91,4 +172,118 @@ type Column struct {
 	Name string
 	// Database data type
 	Type string
+	// Declared maximum length of character and binary types (0 if unknown)
+	MaxLength int64
+	// Declared precision of numeric types (0 if unknown)
+	Precision int64
+	// Declared scale of numeric types (0 if unknown)
+	Scale int64
+	// Whether the value is computed by the database (e.g. GENERATED ALWAYS AS)
+	// and can't be written directly
+	IsGenerated bool
+	// Expression the value of a generated column is computed from (empty if unknown)
+	GenerationExpression string
+}
+
+// ForeignKey represents a reference from a column of one table to a column of another.
+type ForeignKey struct {
+	// Name of the constraint
+	Name string
+	// Column in the referencing table
+	Column string
+	// Referenced table
+	ReferencedSchema string
+	ReferencedTable  string
+	ReferencedColumn string
+}
+
+// TableDefinition is the definition of a table split into statements,
+// which can be restored separately.
+type TableDefinition struct {
+	// CREATE TABLE statement without foreign keys
+	Create string
+	// statements that reference other tables (e.g. ALTER TABLE ... ADD CONSTRAINT ... FOREIGN KEY)
+	Constraints []string
+	// CREATE INDEX statements of indexes that are not backing a constraint
+	Indexes []string
+}
+
+// TableSize is the storage size of a table in bytes.
+type TableSize struct {
+	// Total size on disk (data, indexes and overhead)
+	Total int64
+	// Size of the table data
+	Data int64
+	// Size of all indexes of the table
+	Index int64
+	// Size of other storage of the table (e.g. TOAST of postgres),
+	// 0 if not applicable
+	Overhead int64
+}
+
+// Sequence is a sequence or an auto increment counter of a table.
+type Sequence struct {
+	Schema string
+	Name   string
+	// Table and column owning the sequence (e.g. serial or identity columns),
+	// empty if the sequence isn't owned by a column
+	Table  string
+	Column string
+	// Last returned value (nil if the sequence wasn't used yet)
+	Current   *int64
+	Increment int64
+	Min       int64
+	Max       int64
+}
+
+// Grant is a privilege on a table held by a grantee (user or role).
+type Grant struct {
+	Grantee   string
+	Privilege string
+	// Grantable is true if the grantee can grant the privilege to others
+	Grantable bool
+	// Level at which the privilege was granted (e.g. TABLE, SCHEMA or GLOBAL),
+	// empty if unknown (e.g. effective privileges inherited from roles)
+	Level string
+}
+
+// ExplainSummary is a compact summary of a query plan.
+type ExplainSummary struct {
+	// Estimated total cost of the plan
+	TotalCost float64
+	// Estimated number of returned rows
+	PlanRows int64
+	// Actual number of returned rows (nil if the query wasn't analyzed)
+	ActualRows *int64
+	// Node with the highest cost of its own (excluding its children)
+	ExpensiveNode     string
+	ExpensiveNodeCost float64
+}
+
+// PlanNode is a node of a query plan tree.
+type PlanNode struct {
+	// Type of operation (e.g. "Seq Scan", "Hash Join")
+	Type string
+	// Relation (table) the node operates on
+	Relation string
+	// Index used by the node
+	Index string
+	// Estimated cost (including children)
+	Cost float64
+	// Estimated number of rows
+	Rows int64
+	// Actual number of rows (nil if the query wasn't analyzed)
+	ActualRows *int64
+	// Child nodes
+	Children []*PlanNode
+}
+
+// Notification is a message received on a channel the connection listens to.
+type Notification struct {
+	Channel string
+	Payload string
+	// Process ID of the sending session (0 if unknown)
+	PID int
+	// Time of arrival
+	Received time.Time
 }
@@ -0,0 +1,44 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestHeader_Keys(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   core.Header
+		expected []string
+	}{
+		{
+			name:     "unique names",
+			header:   core.Header{"id", "name"},
+			expected: []string{"id", "name"},
+		},
+		{
+			name:     "duplicates are suffixed with their index",
+			header:   core.Header{"id", "name", "id"},
+			expected: []string{"id", "name", "id_2"},
+		},
+		{
+			name:     "suffixed name is taken",
+			header:   core.Header{"id", "id_2", "id"},
+			expected: []string{"id", "id_2", "id_3"},
+		},
+		{
+			name:     "suffixed name is taken by a later column",
+			header:   core.Header{"id", "id", "id_1"},
+			expected: []string{"id", "id_1", "id_1_2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.header.Keys())
+		})
+	}
+}
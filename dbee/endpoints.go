@@ -1,9 +1,12 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/neovim/go-client/nvim"
 
 	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
 	"github.com/kndndrj/nvim-dbee/dbee/handler"
 	"github.com/kndndrj/nvim-dbee/dbee/plugin"
 )
@@ -17,14 +20,38 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 				URL  string `msgpack:"url"`
 				Type string `msgpack:"type"`
 				Name string `msgpack:"name"`
+				// options and values are stringified, so that users can specify numbers and booleans
+				Options map[string]any `msgpack:"options"`
+				Values  map[string]any `msgpack:"values"`
 			} `msgpack:",array"`
 		},
 		) (core.ConnectionID, error) {
 			return h.CreateConnection(&core.ConnectionParams{
-				ID:   core.ConnectionID(args.Opts.ID),
-				Name: args.Opts.Name,
-				Type: args.Opts.Type,
-				URL:  args.Opts.URL,
+				ID:      core.ConnectionID(args.Opts.ID),
+				Name:    args.Opts.Name,
+				Type:    args.Opts.Type,
+				URL:     args.Opts.URL,
+				Options: stringifyValues(args.Opts.Options),
+				Values:  stringifyValues(args.Opts.Values),
+			})
+		})
+
+	p.RegisterEndpoint(
+		"DbeeValidateConnection",
+		func(args *struct {
+			Opts *struct {
+				URL     string         `msgpack:"url"`
+				Type    string         `msgpack:"type"`
+				Options map[string]any `msgpack:"options"`
+				Values  map[string]any `msgpack:"values"`
+			} `msgpack:",array"`
+		},
+		) error {
+			return h.ValidateConnection(&core.ConnectionParams{
+				Type:    args.Opts.Type,
+				URL:     args.Opts.URL,
+				Options: stringifyValues(args.Opts.Options),
+				Values:  stringifyValues(args.Opts.Values),
 			})
 		})
 
@@ -56,6 +83,43 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return h.AddHelpers(args.Type, args.Helpers)
 		})
 
+	p.RegisterEndpoint(
+		"DbeeSetInterpolationTokens",
+		func(args *struct {
+			Tokens map[string]string `msgpack:",array"`
+		},
+		) error {
+			h.SetInterpolationTokens(args.Tokens)
+			return nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeFormatSQL",
+		func(args *struct {
+			Query string `msgpack:",array"`
+		},
+		) (string, error) {
+			return h.FormatSQL(args.Query), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeClassifyStatement",
+		func(args *struct {
+			Query string `msgpack:",array"`
+		},
+		) (string, error) {
+			return h.ClassifyStatement(args.Query), nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeAnalyzeFileQuery",
+		func(args *struct {
+			Path string `msgpack:",array"`
+		},
+		) (string, error) {
+			return h.AnalyzeFileQuery(args.Path)
+		})
+
 	p.RegisterEndpoint(
 		"DbeeConnectionGetHelpers",
 		func(args *struct {
@@ -64,6 +128,7 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 				Table           string `msgpack:"table"`
 				Schema          string `msgpack:"schema"`
 				Materialization string `msgpack:"materialization"`
+				Parent          string `msgpack:"parent"`
 			}
 		},
 		) (any, error) {
@@ -71,6 +136,7 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 				Table:           args.Opts.Table,
 				Schema:          args.Opts.Schema,
 				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+				Parent:          args.Opts.Parent,
 			})
 		})
 
@@ -95,9 +161,343 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 		func(args *struct {
 			ID    core.ConnectionID `msgpack:",array"`
 			Query string
+			Opts  *struct {
+				Force bool `msgpack:"force"`
+			}
+		},
+		) (any, error) {
+			force := args.Opts != nil && args.Opts.Force
+			call, err := h.ConnectionExecute(args.ID, args.Query, force)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExecuteParams",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Query  string
+			Params string
+		},
+		) (any, error) {
+			call, err := h.ConnectionExecuteParams(args.ID, args.Query, args.Params)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExecutePage",
+		func(args *struct {
+			ID       core.ConnectionID `msgpack:",array"`
+			Query    string
+			PageSize int
+		},
+		) (any, error) {
+			call, err := h.ConnectionExecutePage(args.ID, args.Query, args.PageSize)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExecuteAllDatabases",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+		},
+		) (any, error) {
+			call, err := h.ConnectionExecuteAllDatabases(args.ID, args.Query)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExecuteJSON",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+		},
+		) (any, error) {
+			return h.ConnectionExecuteJSON(args.ID, args.Query)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionImport",
+		func(args *struct {
+			ID          core.ConnectionID `msgpack:",array"`
+			Query       string
+			Destination core.ConnectionID
+			Table       string
+		},
+		) (any, error) {
+			return h.ConnectionImport(args.ID, args.Query, args.Destination, args.Table)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeFederate",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+		},
+		) (any, error) {
+			call, err := h.Federate(args.ID, args.Query)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionCopyExport",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+			Path  string
+			Opts  *copyOptions
+		},
+		) (any, error) {
+			return h.ConnectionCopyExport(args.ID, args.Query, args.Path, args.Opts.toCore())
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExportResumable",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+			Out   string
+			Opts  *resumeOptions
+		},
+		) (any, error) {
+			return h.ConnectionExportResumable(args.ID, args.Query, args.Out, args.Opts.toCore())
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionCopyImport",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Table *struct {
+				Table  string `msgpack:"table"`
+				Schema string `msgpack:"schema"`
+			}
+			Path string
+			Opts *copyOptions
+		},
+		) (any, error) {
+			if args.Table == nil {
+				return nil, fmt.Errorf("table cannot be nil")
+			}
+			return h.ConnectionCopyImport(args.ID, &core.TableOptions{
+				Table:  args.Table.Table,
+				Schema: args.Table.Schema,
+			}, args.Path, args.Opts.toCore())
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionAssertResult",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+			Path  string
+			Opts  *struct {
+				Unordered bool `msgpack:"unordered"`
+				Update    bool `msgpack:"update"`
+			}
+		},
+		) (any, error) {
+			opts := &core.SnapshotOptions{}
+			if args.Opts != nil {
+				opts.Unordered = args.Opts.Unordered
+				opts.Update = args.Opts.Update
+			}
+			diff, err := h.ConnectionAssertResult(args.ID, args.Query, args.Path, opts)
+			return handler.WrapSnapshotDiff(diff), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExplainCost",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Query   string
+			Analyze bool
+		},
+		) (any, error) {
+			summary, err := h.ConnectionExplainCost(args.ID, args.Query, args.Analyze)
+			return handler.WrapExplainSummary(summary), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExplainPlan",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Query   string
+			Analyze bool
+		},
+		) (any, error) {
+			plan, err := h.ConnectionExplainPlan(args.ID, args.Query, args.Analyze)
+			return handler.WrapPlanNode(plan), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSuggestIndexes",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+		},
+		) (any, error) {
+			call, err := h.ConnectionSuggestIndexes(args.ID, args.Query)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionPeek",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+		},
+		) (any, error) {
+			columns, err := h.ConnectionPeek(args.ID, args.Query)
+			return handler.WrapColumns(columns), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionPreviewAffected",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Query string
+		},
+		) (any, error) {
+			return h.ConnectionPreviewAffected(args.ID, args.Query)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionListen",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Channel string
+			Buffer  int
+		},
+		) (any, error) {
+			return nil, h.ConnectionListen(args.ID, args.Channel, nvim.Buffer(args.Buffer))
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionUnlisten",
+		func(args *struct {
+			ID      core.ConnectionID `msgpack:",array"`
+			Channel string
+		},
+		) (any, error) {
+			return nil, h.ConnectionUnlisten(args.ID, args.Channel)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSetMask",
+		func(args *struct {
+			ID       core.ConnectionID `msgpack:",array"`
+			Patterns []string
+			Style    string
+		},
+		) (any, error) {
+			return nil, h.ConnectionSetMask(args.ID, args.Patterns, args.Style)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSetSessionParam",
+		func(args *struct {
+			ID    core.ConnectionID `msgpack:",array"`
+			Name  string
+			Value string
+		},
+		) (any, error) {
+			return nil, h.ConnectionSetSessionParam(args.ID, args.Name, args.Value)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionDescribe",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+				Parent          string `msgpack:"parent"`
+			}
+		},
+		) (any, error) {
+			call, err := h.ConnectionDescribe(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+				Parent:          args.Opts.Parent,
+			})
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionTopValues",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+				Parent          string `msgpack:"parent"`
+			}
+			Column string
+			N      int
+		},
+		) (any, error) {
+			call, err := h.ConnectionTopValues(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+				Parent:          args.Opts.Parent,
+			}, args.Column, args.N)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionListProcedures",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			procs, err := h.ConnectionListProcedures(args.ID)
+			return handler.WrapStructures(procs), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionGetProcedureParameters",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+				Parent          string `msgpack:"parent"`
+			}
+		},
+		) (any, error) {
+			params, err := h.ConnectionGetProcedureParameters(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+				Parent:          args.Opts.Parent,
+			})
+			return handler.WrapColumns(params), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionCallProcedure",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Opts *struct {
+				Table           string `msgpack:"table"`
+				Schema          string `msgpack:"schema"`
+				Materialization string `msgpack:"materialization"`
+				Parent          string `msgpack:"parent"`
+			}
+			Args []any
 		},
 		) (any, error) {
-			call, err := h.ConnectionExecute(args.ID, args.Query)
+			call, err := h.ConnectionCallProcedure(args.ID, &core.TableOptions{
+				Table:           args.Opts.Table,
+				Schema:          args.Opts.Schema,
+				Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+				Parent:          args.Opts.Parent,
+			}, args.Args)
 			return handler.WrapCall(call), err
 		})
 
@@ -111,6 +511,16 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return handler.WrapCalls(calls), err
 		})
 
+	p.RegisterEndpoint(
+		"DbeeConnectionGetActiveQueries",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			calls, err := h.ConnectionGetActiveQueries(args.ID)
+			return handler.WrapCalls(calls), err
+		})
+
 	p.RegisterEndpoint(
 		"DbeeConnectionGetParams",
 		func(args *struct {
@@ -131,12 +541,31 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return handler.WrapStructures(str), err
 		})
 
+	p.RegisterEndpoint(
+		"DbeeConnectionLoadStructure",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionLoadStructure(args.ID)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionCancelStructure",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			return nil, h.ConnectionCancelStructure(args.ID)
+		})
+
 	p.RegisterEndpoint("DbeeConnectionGetColumns", func(args *struct {
 		ID   core.ConnectionID `msgpack:",array"`
 		Opts *struct {
 			Table           string `msgpack:"table"`
 			Schema          string `msgpack:"schema"`
 			Materialization string `msgpack:"materialization"`
+			Parent          string `msgpack:"parent"`
 		}
 	},
 	) (any, error) {
@@ -144,10 +573,68 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			Table:           args.Opts.Table,
 			Schema:          args.Opts.Schema,
 			Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			Parent:          args.Opts.Parent,
 		})
 		return handler.WrapColumns(cols), err
 	})
 
+	p.RegisterEndpoint("DbeeConnectionGetTableSize", func(args *struct {
+		ID   core.ConnectionID `msgpack:",array"`
+		Opts *struct {
+			Table           string `msgpack:"table"`
+			Schema          string `msgpack:"schema"`
+			Materialization string `msgpack:"materialization"`
+			Parent          string `msgpack:"parent"`
+		}
+	},
+	) (any, error) {
+		size, err := h.ConnectionGetTableSize(args.ID, &core.TableOptions{
+			Table:           args.Opts.Table,
+			Schema:          args.Opts.Schema,
+			Materialization: core.StructureTypeFromString(args.Opts.Materialization),
+			Parent:          args.Opts.Parent,
+		})
+		return handler.WrapTableSize(size), err
+	})
+
+	p.RegisterEndpoint("DbeeConnectionGetSequences", func(args *struct {
+		ID   core.ConnectionID `msgpack:",array"`
+		Opts *struct {
+			Table  string `msgpack:"table"`
+			Schema string `msgpack:"schema"`
+		}
+	},
+	) (any, error) {
+		var opts *core.TableOptions
+		if args.Opts != nil && args.Opts.Table != "" {
+			opts = &core.TableOptions{
+				Table:  args.Opts.Table,
+				Schema: args.Opts.Schema,
+			}
+		}
+		sequences, err := h.ConnectionGetSequences(args.ID, opts)
+		return handler.WrapSequences(sequences), err
+	})
+
+	p.RegisterEndpoint("DbeeConnectionGetGrants", func(args *struct {
+		ID   core.ConnectionID `msgpack:",array"`
+		Opts *struct {
+			Table     string `msgpack:"table"`
+			Schema    string `msgpack:"schema"`
+			Effective bool   `msgpack:"effective"`
+		}
+	},
+	) (any, error) {
+		if args.Opts == nil {
+			return nil, fmt.Errorf("opts cannot be nil")
+		}
+		grants, err := h.ConnectionGetGrants(args.ID, &core.TableOptions{
+			Table:  args.Opts.Table,
+			Schema: args.Opts.Schema,
+		}, args.Opts.Effective)
+		return handler.WrapGrants(grants), err
+	})
+
 	p.RegisterEndpoint(
 		"DbeeConnectionListDatabases",
 		func(args *struct {
@@ -171,6 +658,62 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return nil, h.ConnectionSelectDatabase(args.ID, args.Database)
 		})
 
+	p.RegisterEndpoint(
+		"DbeeConnectionListSchemas",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			current, available, err := h.ConnectionListSchemas(args.ID)
+			if err != nil {
+				return nil, err
+			}
+			return []any{current, available}, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSelectSchema",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Schema string
+		},
+		) (any, error) {
+			return nil, h.ConnectionSelectSchema(args.ID, args.Schema)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionListBranches",
+		func(args *struct {
+			ID core.ConnectionID `msgpack:",array"`
+		},
+		) (any, error) {
+			current, available, err := h.ConnectionListBranches(args.ID)
+			if err != nil {
+				return nil, err
+			}
+			return []any{current, available}, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionSelectBranch",
+		func(args *struct {
+			ID     core.ConnectionID `msgpack:",array"`
+			Branch string
+		},
+		) (any, error) {
+			return nil, h.ConnectionSelectBranch(args.ID, args.Branch)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeConnectionExportSchema",
+		func(args *struct {
+			ID   core.ConnectionID `msgpack:",array"`
+			Path string
+		},
+		) (any, error) {
+			return nil, h.ConnectionExportSchema(args.ID, args.Path)
+		})
+
 	p.RegisterEndpoint(
 		"DbeeCallCancel",
 		func(args *struct {
@@ -180,18 +723,105 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 			return nil, h.CallCancel(args.ID)
 		})
 
+	p.RegisterEndpoint(
+		"DbeeCallPageNext",
+		func(args *struct {
+			ID    core.CallID `msgpack:",array"`
+			After []any
+		},
+		) (any, error) {
+			call, err := h.CallPageNext(args.ID, args.After)
+			return handler.WrapCall(call), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallProgress",
+		func(args *struct {
+			ID core.CallID `msgpack:",array"`
+		},
+		) (any, error) {
+			progress, err := h.CallProgress(args.ID)
+			if err != nil {
+				return nil, err
+			}
+			return handler.WrapProgress(progress), nil
+		})
+
 	p.RegisterEndpoint(
 		"DbeeCallDisplayResult",
 		func(args *struct {
 			ID   core.CallID `msgpack:",array"`
 			Opts *struct {
-				Buffer int `msgpack:"buffer"`
-				From   int `msgpack:"from"`
-				To     int `msgpack:"to"`
+				Buffer  int    `msgpack:"buffer"`
+				From    int    `msgpack:"from"`
+				To      int    `msgpack:"to"`
+				Style   string `msgpack:"style"`
+				Columns bool   `msgpack:"columns"`
 			}
 		},
 		) (any, error) {
-			return h.CallDisplayResult(args.ID, nvim.Buffer(args.Opts.Buffer), args.Opts.From, args.Opts.To)
+			return h.CallDisplayResult(args.ID, nvim.Buffer(args.Opts.Buffer), args.Opts.From, args.Opts.To,
+				format.TableStyleFromString(args.Opts.Style), args.Opts.Columns)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallResultFooter",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				From int `msgpack:"from"`
+				To   int `msgpack:"to"`
+			}
+		},
+		) (any, error) {
+			return h.CallResultFooter(args.ID, args.Opts.From, args.Opts.To)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallSortResult",
+		func(args *struct {
+			ID   core.CallID `msgpack:",array"`
+			Opts *struct {
+				Column    int  `msgpack:"column"`
+				Ascending bool `msgpack:"ascending"`
+			}
+		},
+		) (any, error) {
+			return nil, h.CallSortResult(args.ID, args.Opts.Column, args.Opts.Ascending)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallSelectColumns",
+		func(args *struct {
+			ID    core.CallID `msgpack:",array"`
+			Names []string
+		},
+		) (any, error) {
+			return h.CallSelectColumns(args.ID, args.Names)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallChartData",
+		func(args *struct {
+			ID core.CallID `msgpack:",array"`
+			X  string
+			Y  []string
+		},
+		) (any, error) {
+			data, err := h.CallChartData(args.ID, args.X, args.Y)
+			return handler.WrapChartData(data), err
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallHighlightRows",
+		func(args *struct {
+			ID       core.CallID `msgpack:",array"`
+			Column   string
+			Operator string
+			Value    string
+		},
+		) (any, error) {
+			return h.CallHighlightRows(args.ID, args.Column, args.Operator, args.Value)
 		})
 
 	p.RegisterEndpoint(
@@ -209,4 +839,103 @@ func mountEndpoints(p *plugin.Plugin, h *handler.Handler) {
 		) (any, error) {
 			return nil, h.CallStoreResult(args.ID, args.Format, args.Output, args.Opts.From, args.Opts.To, args.Opts.ExtraArg)
 		})
+
+	p.RegisterEndpoint(
+		"DbeeCallYankResult",
+		func(args *struct {
+			ID       core.CallID `msgpack:",array"`
+			Format   string
+			Register string
+			Opts     *struct {
+				MaxRows int `msgpack:"max_rows"`
+			}
+		},
+		) (any, error) {
+			maxRows := 0
+			if args.Opts != nil {
+				maxRows = args.Opts.MaxRows
+			}
+			rows, truncated, err := h.CallYankResult(args.ID, args.Format, args.Register, maxRows)
+			if err != nil {
+				return nil, err
+			}
+			return []any{rows, truncated}, nil
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallExportSheet",
+		func(args *struct {
+			ID            core.CallID `msgpack:",array"`
+			SpreadsheetID string
+			Sheet         string
+			Opts          *struct {
+				Credentials string `msgpack:"credentials"`
+			}
+		},
+		) (any, error) {
+			credentials := ""
+			if args.Opts != nil {
+				credentials = args.Opts.Credentials
+			}
+			return h.CallExportSheet(args.ID, args.SpreadsheetID, args.Sheet, credentials)
+		})
+
+	p.RegisterEndpoint(
+		"DbeeCallExportS3",
+		func(args *struct {
+			ID  core.CallID `msgpack:",array"`
+			URL string
+		},
+		) (any, error) {
+			return h.CallExportS3(args.ID, args.URL)
+		})
+}
+
+// copyOptions are csv options of bulk copies (see core.CopyOptions).
+type copyOptions struct {
+	Delimiter string `msgpack:"delimiter"`
+	Null      string `msgpack:"null"`
+	Header    bool   `msgpack:"header"`
+}
+
+func (o *copyOptions) toCore() *core.CopyOptions {
+	if o == nil {
+		return nil
+	}
+	return &core.CopyOptions{
+		Delimiter: o.Delimiter,
+		Null:      o.Null,
+		Header:    o.Header,
+	}
+}
+
+// stringifyValues converts values of a lua table to strings.
+func stringifyValues(m map[string]any) map[string]string {
+	if len(m) < 1 {
+		return nil
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+// resumeOptions are options of resumable exports (see core.ResumeOptions).
+type resumeOptions struct {
+	Key            []string `msgpack:"key"`
+	CheckpointRows int      `msgpack:"checkpoint_rows"`
+	MaxRetries     int      `msgpack:"max_retries"`
+}
+
+func (o *resumeOptions) toCore() *core.ResumeOptions {
+	if o == nil {
+		return nil
+	}
+	return &core.ResumeOptions{
+		Key:            o.Key,
+		CheckpointRows: o.CheckpointRows,
+		MaxRetries:     o.MaxRetries,
+	}
 }
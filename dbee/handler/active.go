@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"sort"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// ConnectionGetActiveQueries returns calls of the connection which are still executing
+// or retrieving their result (oldest first). Any of them can be canceled with CallCancel.
+func (h *Handler) ConnectionGetActiveQueries(connID core.ConnectionID) ([]*core.Call, error) {
+	calls, err := h.ConnectionGetCalls(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	return activeCalls(calls), nil
+}
+
+// activeCalls filters calls which haven't finished. Canceled calls finish as soon as
+// the driver returns. The state of a call is written by its own goroutine, so only
+// the done channel is checked.
+func activeCalls(calls []*core.Call) []*core.Call {
+	active := []*core.Call{}
+	for _, c := range calls {
+		select {
+		case <-c.Done():
+			continue
+		default:
+		}
+		active = append(active, c)
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].GetTimestamp().Before(active[j].GetTimestamp())
+	})
+
+	return active
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestActiveCalls(t *testing.T) {
+	r := require.New(t)
+
+	// signals that the call started executing and blocks until it's released
+	block := func(started, release chan struct{}) func(context.Context) error {
+		return func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}
+	}
+	started1, release1 := make(chan struct{}), make(chan struct{})
+	started2, release2 := make(chan struct{}), make(chan struct{})
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 3),
+		mock.AdapterWithQuerySideEffect("slow 1", block(started1, release1)),
+		mock.AdapterWithQuerySideEffect("slow 2", block(started2, release2)),
+	))
+	r.NoError(err)
+
+	// compared by ids, as the other fields are written by the calls' goroutines
+	ids := func(calls []*core.Call) []core.CallID {
+		out := []core.CallID{}
+		for _, c := range calls {
+			out = append(out, c.GetID())
+		}
+		return out
+	}
+
+	slow1 := c.Execute("slow 1", nil)
+	<-started1
+	slow2 := c.Execute("slow 2", nil)
+	<-started2
+	fast := c.Execute("fast", nil)
+	<-fast.Done()
+
+	r.Equal(ids([]*core.Call{slow1, slow2}), ids(activeCalls([]*core.Call{slow2, fast, slow1})))
+
+	close(release1)
+	<-slow1.Done()
+	r.Equal(ids([]*core.Call{slow2}), ids(activeCalls([]*core.Call{slow2, fast, slow1})))
+
+	close(release2)
+	<-slow2.Done()
+	r.Empty(activeCalls([]*core.Call{slow2, fast, slow1}))
+}
@@ -64,3 +64,40 @@ func (eb *eventBus) DatabaseSelected(id core.ConnectionID, dbname string) {
 
 	eb.callLua("database_selected", data)
 }
+
+// SchemaSelected is called when the default schema of a connection is changed.
+func (eb *eventBus) SchemaSelected(id core.ConnectionID, schema string) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		schema_name = %q,
+	}`, id, schema)
+
+	eb.callLua("schema_selected", data)
+}
+
+// BranchSelected is called when the checked out branch of a connection is changed.
+func (eb *eventBus) BranchSelected(id core.ConnectionID, branch string) {
+	data := fmt.Sprintf(`{
+		conn_id = %q,
+		branch_name = %q,
+	}`, id, branch)
+
+	eb.callLua("branch_selected", data)
+}
+
+// StructureLoaded is called when a structure load started with ConnectionLoadStructure finishes.
+// The structure is passed as an argument, as it doesn't fit into a lua literal.
+func (eb *eventBus) StructureLoaded(id core.ConnectionID, structure []*core.Structure, err error) {
+	data := map[string]any{
+		"conn_id":   id,
+		"structure": WrapStructures(structure),
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+
+	err = eb.vim.ExecLua(`require("dbee.handler.__events").trigger("structure_loaded", ...)`, nil, data)
+	if err != nil {
+		eb.log.Infof("eb.vim.ExecLua: %s", err)
+	}
+}
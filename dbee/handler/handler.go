@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/neovim/go-client/nvim"
@@ -28,7 +31,13 @@ type Handler struct {
 	lookupCall           map[core.CallID]*core.Call
 	lookupConnectionCall map[core.ConnectionID][]core.CallID
 
+	listeners      *listeners
+	structureLoads *structureLoads
+
 	currentConnectionID core.ConnectionID
+
+	// tokens interpolated in executed queries (name to vim expression)
+	interpolationTokens map[string]string
 }
 
 func New(vim *nvim.Nvim, logger *plugin.Logger) *Handler {
@@ -43,6 +52,9 @@ func New(vim *nvim.Nvim, logger *plugin.Logger) *Handler {
 		lookupConnection:     make(map[core.ConnectionID]*core.Connection),
 		lookupCall:           make(map[core.CallID]*core.Call),
 		lookupConnectionCall: make(map[core.ConnectionID][]core.CallID),
+
+		listeners:      &listeners{lookup: make(map[listenerKey]*listener)},
+		structureLoads: &structureLoads{lookup: make(map[core.ConnectionID]*structureLoad)},
 	}
 
 	// restore the call log concurrently
@@ -71,6 +83,17 @@ func (h *Handler) Close() {
 		h.log.Infof("h.storeCallLog: %s", err)
 	}
 
+	// discard cached results
+	for _, c := range h.lookupCall {
+		c.Close()
+	}
+
+	// stop listening for notifications
+	h.listeners.stop(func(listenerKey) bool { return true })
+
+	// cancel structure loads
+	h.structureLoads.stop(func(core.ConnectionID) bool { return true })
+
 	// close connections
 	for _, c := range h.lookupConnection {
 		c.Close()
@@ -89,17 +112,37 @@ func (h *Handler) CreateConnection(params *core.ConnectionParams) (core.Connecti
 		return "", fmt.Errorf("connection with id already exists. id: %s", params.ID)
 	}
 
+	name := c.GetName()
+	c.SetStatementLog(func(statement string, elapsed time.Duration, err error) {
+		if err != nil {
+			h.log.Errorf("statement on %q failed after %s: %s: %s", name, elapsed, statement, err)
+			return
+		}
+		h.log.Infof("statement on %q took %s: %s", name, elapsed, statement)
+	})
+
 	h.lookupConnection[c.GetID()] = c
 	_ = h.SetCurrentConnection(c.GetID())
 
 	return c.GetID(), nil
 }
 
+// ValidateConnection checks the connection params (e.g. required url parameters)
+// without connecting to the database.
+func (h *Handler) ValidateConnection(params *core.ConnectionParams) error {
+	if err := adapters.ValidateConnection(params); err != nil {
+		return fmt.Errorf("adapters.ValidateConnection: %w", err)
+	}
+	return nil
+}
+
 func (h *Handler) DeleteConnection(id core.ConnectionID) error {
 	c, ok := h.lookupConnection[id]
 	if !ok {
 		return fmt.Errorf("connection with id does not exist. id: %s", id)
 	}
+	h.listeners.stop(func(key listenerKey) bool { return key.connID == id })
+	h.structureLoads.stop(func(connID core.ConnectionID) bool { return connID == id })
 	c.Close()
 	delete(h.lookupConnection, id)
 	return nil
@@ -122,6 +165,26 @@ func (h *Handler) AddHelpers(typ string, helpers map[string]string) error {
 	return new(adapters.Mux).AddHelpers(typ, helpers)
 }
 
+// FormatSQL pretty-prints the query (see core.FormatSQL).
+func (h *Handler) FormatSQL(query string) string {
+	return core.FormatSQL(query)
+}
+
+// ClassifyStatement returns the kind of the statements of the query (see core.ClassifyStatement).
+func (h *Handler) ClassifyStatement(query string) string {
+	return core.ClassifyStatement(query).String()
+}
+
+// AnalyzeFileQuery returns a DuckDB query selecting the rows of a local Parquet, CSV
+// or JSON file (see adapters.DuckFileQuery).
+func (h *Handler) AnalyzeFileQuery(path string) (string, error) {
+	query, err := adapters.DuckFileQuery(path)
+	if err != nil {
+		return "", fmt.Errorf("adapters.DuckFileQuery: %w", err)
+	}
+	return query, nil
+}
+
 func (h *Handler) ConnectionGetHelpers(connID core.ConnectionID, opts *core.TableOptions) (map[string]string, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
@@ -156,197 +219,1099 @@ func (h *Handler) SetCurrentConnection(connID core.ConnectionID) error {
 	return nil
 }
 
-func (h *Handler) ConnectionExecute(connID core.ConnectionID, query string) (*core.Call, error) {
+// ConnectionExecute starts executing the query. If force is true, statements refused
+// by the "require_where" option are executed too.
+func (h *Handler) ConnectionExecute(connID core.ConnectionID, query string, force bool) (*core.Call, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	call := c.Execute(query, func(state core.CallState, c *core.Call) {
-		if err := c.Err(); err != nil {
-			h.log.Errorf("cl.Err: %s", err)
-		}
+	query, err := h.interpolateQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("h.interpolateQuery: %w", err)
+	}
 
-		h.events.CallStateChanged(c)
-	})
+	var call *core.Call
+	if force {
+		call = c.ExecuteForce(query, h.onCallStateChanged)
+	} else {
+		call = c.Execute(query, h.onCallStateChanged)
+	}
 
-	id := call.GetID()
+	h.addCall(connID, call)
 
-	// add to lookup
-	h.lookupCall[id] = call
-	h.lookupConnectionCall[connID] = append(h.lookupConnectionCall[connID], id)
+	return call, nil
+}
 
-	// update current call and conn
-	_ = h.SetCurrentConnection(connID)
+// ConnectionExecuteParams starts executing the query with json encoded values
+// bound to its placeholders.
+func (h *Handler) ConnectionExecuteParams(connID core.ConnectionID, query, params string) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	call, err := c.ExecuteParams(query, params, h.onCallStateChanged)
+	if err != nil {
+		return nil, fmt.Errorf("c.ExecuteParams: %w", err)
+	}
+
+	h.addCall(connID, call)
 
 	return call, nil
 }
 
-func (h *Handler) ConnectionGetCalls(connID core.ConnectionID) ([]*core.Call, error) {
-	_, ok := h.lookupConnection[connID]
+// ConnectionExecuteAllDatabases starts a call which runs the query in all databases of the connection.
+func (h *Handler) ConnectionExecuteAllDatabases(connID core.ConnectionID, query string) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	var calls []*core.Call
-	callIDs, ok := h.lookupConnectionCall[connID]
+	call, err := c.ExecuteAllDatabases(query, h.onCallStateChanged)
+	if err != nil {
+		return nil, fmt.Errorf("c.ExecuteAllDatabases: %w", err)
+	}
+
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+// ConnectionExecutePage starts a call which selects the first page of the query's rows
+// (see CallPageNext for the following pages).
+func (h *Handler) ConnectionExecutePage(connID core.ConnectionID, query string, pageSize int) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return calls, nil
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
-	for _, cID := range callIDs {
-		c, ok := h.lookupCall[cID]
-		if !ok {
-			continue
-		}
-		calls = append(calls, c)
+
+	call, err := c.ExecutePage(query, pageSize, h.onCallStateChanged)
+	if err != nil {
+		return nil, fmt.Errorf("c.ExecutePage: %w", err)
 	}
 
-	return calls, nil
+	h.addCall(connID, call)
+
+	return call, nil
 }
 
-func (h *Handler) ConnectionGetParams(connID core.ConnectionID) (*core.ConnectionParams, error) {
+// ConnectionDescribe starts a call which describes the provided object of the connection.
+func (h *Handler) ConnectionDescribe(connID core.ConnectionID, opts *core.TableOptions) (*core.Call, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	return c.GetParams(), nil
+	call, err := c.Describe(opts, h.onCallStateChanged)
+	if err != nil {
+		return nil, fmt.Errorf("c.Describe: %w", err)
+	}
+
+	h.addCall(connID, call)
+
+	return call, nil
 }
 
-func (h *Handler) ConnectionGetStructure(connID core.ConnectionID) ([]*core.Structure, error) {
+// ConnectionTopValues starts a call which lists the n most common values of the column.
+func (h *Handler) ConnectionTopValues(connID core.ConnectionID, opts *core.TableOptions, column string, n int) (*core.Call, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	layout, err := c.GetStructure()
+	call, err := c.TopValues(opts, column, n, h.onCallStateChanged)
 	if err != nil {
-		return nil, fmt.Errorf("c.GetStructure: %w", err)
+		return nil, fmt.Errorf("c.TopValues: %w", err)
 	}
 
-	return layout, nil
+	h.addCall(connID, call)
+
+	return call, nil
 }
 
-func (h *Handler) ConnectionGetColumns(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Column, error) {
+// ConnectionListProcedures returns the procedures and functions of the connection.
+func (h *Handler) ConnectionListProcedures(connID core.ConnectionID) ([]*core.Structure, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	columns, err := c.GetColumns(opts)
+	procs, err := c.ListProcedures()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("c.ListProcedures: %w", err)
 	}
 
-	return columns, nil
+	return procs, nil
 }
 
-func (h *Handler) ConnectionListDatabases(connID core.ConnectionID) (current string, available []string, err error) {
+// ConnectionGetProcedureParameters returns the input parameters of the procedure.
+func (h *Handler) ConnectionGetProcedureParameters(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Column, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return "", nil, fmt.Errorf("unknown connection with id: %q", connID)
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	currentDB, availableDBs, err := c.ListDatabases()
+	params, err := c.GetProcedureParameters(opts)
 	if err != nil {
-		if errors.Is(err, core.ErrDatabaseSwitchingNotSupported) {
-			return "", []string{}, nil
+		return nil, fmt.Errorf("c.GetProcedureParameters: %w", err)
+	}
+
+	return params, nil
+}
+
+// ConnectionCallProcedure starts a call of the procedure with provided arguments.
+func (h *Handler) ConnectionCallProcedure(connID core.ConnectionID, opts *core.TableOptions, args []any) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	call, err := c.CallProcedure(opts, args, h.onCallStateChanged)
+	if err != nil {
+		return nil, fmt.Errorf("c.CallProcedure: %w", err)
+	}
+
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+func (h *Handler) onCallStateChanged(state core.CallState, c *core.Call) {
+	if err := c.Err(); err != nil {
+		h.log.Errorf("cl.Err: %s", err)
+	}
+
+	h.events.CallStateChanged(c)
+}
+
+// addCall adds the call to lookups and sets its connection as current.
+func (h *Handler) addCall(connID core.ConnectionID, call *core.Call) {
+	id := call.GetID()
+
+	// add to lookup
+	h.lookupCall[id] = call
+	h.lookupConnectionCall[connID] = append(h.lookupConnectionCall[connID], id)
+
+	// update current call and conn
+	_ = h.SetCurrentConnection(connID)
+}
+
+// ConnectionImport runs the query on the source connection and loads the result into
+// the table of the destination connection (e.g. the scratch database), replacing the table.
+// Returns the number of imported rows.
+func (h *Handler) ConnectionImport(srcID core.ConnectionID, query string, dstID core.ConnectionID, table string) (int, error) {
+	src, ok := h.lookupConnection[srcID]
+	if !ok {
+		return 0, fmt.Errorf("unknown connection with id: %q", srcID)
+	}
+	dst, ok := h.lookupConnection[dstID]
+	if !ok {
+		return 0, fmt.Errorf("unknown connection with id: %q", dstID)
+	}
+
+	count, err := dst.Import(context.Background(), src, query, table)
+	if err != nil {
+		return 0, fmt.Errorf("dst.Import: %w", err)
+	}
+
+	return count, nil
+}
+
+// Federate runs a query joining tables of other connections on the engine connection
+// (e.g. the analytics DuckDB database). Tables referenced as "<connection>.<schema>.<table>"
+// (by connection name) are loaded into the engine as a whole and the query is rewritten
+// to use the loaded tables (see core.ParseFederatedQuery).
+func (h *Handler) Federate(engineID core.ConnectionID, query string) (*core.Call, error) {
+	engine, ok := h.lookupConnection[engineID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", engineID)
+	}
+
+	// connections by name, names of multiple connections are ambiguous
+	sources := make(map[string]*core.Connection)
+	ambiguous := make(map[string]bool)
+	var names []string
+	for id, c := range h.lookupConnection {
+		if id == engineID {
+			continue
 		}
-		return "", nil, fmt.Errorf("c.ListDatabases: %w", err)
+		name := c.GetParams().Name
+		if _, ok := sources[name]; ok {
+			ambiguous[name] = true
+			continue
+		}
+		sources[name] = c
+		names = append(names, name)
 	}
+	slices.Sort(names)
 
-	return currentDB, availableDBs, nil
+	rewritten, tables, err := core.ParseFederatedQuery(query, names)
+	if err != nil {
+		return nil, fmt.Errorf("core.ParseFederatedQuery: %w", err)
+	}
+	if len(tables) < 1 {
+		return nil, errors.New("query doesn't reference any tables of other connections (<connection>.<schema>.<table>)")
+	}
+
+	for _, table := range tables {
+		if ambiguous[table.Connection] {
+			return nil, fmt.Errorf("connection name %q is ambiguous", table.Connection)
+		}
+
+		if _, err := engine.Import(context.Background(), sources[table.Connection], table.Query, table.Alias); err != nil {
+			return nil, fmt.Errorf("loading table %q of connection %q: %w", table.Alias, table.Connection, err)
+		}
+	}
+
+	return h.ConnectionExecute(engineID, rewritten, false)
 }
 
-func (h *Handler) ConnectionSelectDatabase(connID core.ConnectionID, database string) error {
+// ConnectionCopyExport writes rows of the query to a csv file at path, bypassing
+// the formatting of results (see core.Connection.CopyOut). Returns the number of exported rows.
+func (h *Handler) ConnectionCopyExport(connID core.ConnectionID, query, path string, opts *core.CopyOptions) (int64, error) {
 	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return fmt.Errorf("unknown connection with id: %q", connID)
+		return 0, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	err := c.SelectDatabase(database)
+	file, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("c.SelectDatabase: %w", err)
+		return 0, err
 	}
-	h.events.DatabaseSelected(connID, database)
+	defer file.Close()
 
-	return nil
+	count, err := c.CopyOut(context.Background(), query, file, opts)
+	if err != nil {
+		return count, fmt.Errorf("c.CopyOut: %w", err)
+	}
+
+	return count, file.Close()
 }
 
-func (h *Handler) CallCancel(callID core.CallID) error {
-	call, ok := h.lookupCall[callID]
+// ConnectionCopyImport loads rows of the csv file at path into the table
+// (see core.Connection.CopyIn). Returns the number of imported rows.
+func (h *Handler) ConnectionCopyImport(connID core.ConnectionID, table *core.TableOptions, path string, opts *core.CopyOptions) (int64, error) {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return fmt.Errorf("unknown call with id: %q", callID)
+		return 0, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	call.Cancel()
-	return nil
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count, err := c.CopyIn(context.Background(), table, file, opts)
+	if err != nil {
+		return 0, fmt.Errorf("c.CopyIn: %w", err)
+	}
+
+	return count, nil
 }
 
-func (h *Handler) CallDisplayResult(callID core.CallID, buffer nvim.Buffer, from, to int) (int, error) {
-	call, ok := h.lookupCall[callID]
+// ConnectionExportResumable exports rows of the query to a file or to an s3 object (if out
+// is an "s3://" url, see CallExportS3) as csv, tsv or json (by the extension), resuming
+// after transient failures of the connection (see core.Connection.ExportResumable).
+// Returns the number of exported rows.
+func (h *Handler) ConnectionExportResumable(connID core.ConnectionID, query, out string, opts *core.ResumeOptions) (int, error) {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return 0, fmt.Errorf("unknown call with id: %q", callID)
+		return 0, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	res, err := call.GetResult()
+	ctx := context.Background()
+	wrap := func(f core.Formatter) core.Formatter {
+		return connectionFormatter(c, f)
+	}
+
+	var rows int
+	var err error
+	if strings.HasPrefix(out, "s3://") {
+		rows, err = uploadS3(ctx, out, func(w io.Writer, stream *s3Stream) (int, error) {
+			return exportResumable(ctx, c, query, opts, w, stream, wrap)
+		})
+	} else {
+		rows, err = exportResumableFile(ctx, c, query, out, opts, wrap)
+	}
 	if err != nil {
-		return 0, fmt.Errorf("call.GetResult: %w", err)
+		return rows, err
+	}
+
+	h.log.Infof("exported %d rows of connection %q to %q", rows, connID, out)
+
+	return rows, nil
+}
+
+// ConnectionExecuteJSON runs the query synchronously and returns the rows
+// as a json array of objects (column name -> value).
+func (h *Handler) ConnectionExecuteJSON(connID core.ConnectionID, query string) (string, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	text, err := res.Format(newTable(), from, to)
+	records, err := c.ExecuteRecords(context.Background(), query)
 	if err != nil {
-		return 0, fmt.Errorf("res.Format: %w", err)
+		return "", fmt.Errorf("c.ExecuteRecords: %w", err)
 	}
 
-	_, err = newBuffer(h.vim, buffer).Write(text)
+	out, err := json.Marshal(records)
 	if err != nil {
-		return 0, fmt.Errorf("buffer.Write: %w", err)
+		return "", fmt.Errorf("json.Marshal: %w", err)
 	}
 
-	return res.Len(), nil
+	return string(out), nil
 }
 
-func (h *Handler) CallStoreResult(callID core.CallID, fmat, out string, from, to int, arg ...any) error {
-	stat, ok := h.lookupCall[callID]
+// ConnectionAssertResult runs the query synchronously and compares the result
+// with the snapshot stored at path (or updates the snapshot).
+func (h *Handler) ConnectionAssertResult(connID core.ConnectionID, query, path string, opts *core.SnapshotOptions) (*core.SnapshotDiff, error) {
+	c, ok := h.lookupConnection[connID]
 	if !ok {
-		return fmt.Errorf("unknown call with id: %q", callID)
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	var formatter core.Formatter
-	switch fmat {
-	case "json":
-		formatter = format.NewJSON()
-	case "csv":
-		formatter = format.NewCSV()
-	case "table":
-		formatter = newTable()
-	default:
-		return fmt.Errorf("store output: %q is not supported", fmat)
+	diff, err := c.AssertSnapshot(context.Background(), query, path, opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.AssertSnapshot: %w", err)
 	}
 
-	writer, cleanup, err := h.getStoreWriter(out, arg...)
+	return diff, nil
+}
+
+// ConnectionExplainCost returns the summary of the query plan.
+func (h *Handler) ConnectionExplainCost(connID core.ConnectionID, query string, analyze bool) (*core.ExplainSummary, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	summary, err := c.ExplainCost(context.Background(), query, analyze)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("c.ExplainCost: %w", err)
 	}
-	defer cleanup()
 
-	res, err := stat.GetResult()
+	return summary, nil
+}
+
+// ConnectionExplainPlan returns the query plan as a tree of nodes.
+func (h *Handler) ConnectionExplainPlan(connID core.ConnectionID, query string, analyze bool) (*core.PlanNode, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	plan, err := c.ExplainPlan(context.Background(), query, analyze)
 	if err != nil {
-		return fmt.Errorf("stat.GetResult: %w", err)
+		return nil, fmt.Errorf("c.ExplainPlan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// ConnectionSuggestIndexes starts a call which lists index suggestions for the query.
+func (h *Handler) ConnectionSuggestIndexes(connID core.ConnectionID, query string) (*core.Call, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
 	}
 
-	text, err := res.Format(formatter, from, to)
+	call, err := c.SuggestIndexes(query, h.onCallStateChanged)
 	if err != nil {
-		return fmt.Errorf("res.Format: %w", err)
+		return nil, fmt.Errorf("c.SuggestIndexes: %w", err)
 	}
 
-	_, err = writer.Write(text)
+	h.addCall(connID, call)
+
+	return call, nil
+}
+
+// ConnectionPeek returns the columns of the select statement without fetching any rows.
+func (h *Handler) ConnectionPeek(connID core.ConnectionID, query string) ([]*core.Column, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	columns, err := c.Peek(context.Background(), query)
 	if err != nil {
-		return fmt.Errorf("buffer.Write: %w", err)
+		return nil, fmt.Errorf("c.Peek: %w", err)
 	}
 
-	return nil
+	return columns, nil
+}
+
+// ConnectionPreviewAffected returns the number of rows the DELETE or UPDATE statement
+// would change, without executing it.
+func (h *Handler) ConnectionPreviewAffected(connID core.ConnectionID, query string) (int64, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return 0, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	count, err := c.PreviewAffected(context.Background(), query)
+	if err != nil {
+		return 0, fmt.Errorf("c.PreviewAffected: %w", err)
+	}
+
+	return count, nil
+}
+
+// ConnectionSetSessionParam sets the session parameter of the connection.
+func (h *Handler) ConnectionSetSessionParam(connID core.ConnectionID, name, value string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.SetSessionParam(name, value)
+	if err != nil {
+		return fmt.Errorf("c.SetSessionParam: %w", err)
+	}
+
+	return nil
+}
+
+// ConnectionSetMask replaces the masked columns of the connection (see core.ParseMaskPolicy).
+// Masking applies to all outputs of the connection's results. No patterns turn masking off.
+func (h *Handler) ConnectionSetMask(connID core.ConnectionID, patterns []string, style string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.SetMask(patterns, style)
+	if err != nil {
+		return fmt.Errorf("c.SetMask: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) ConnectionGetCalls(connID core.ConnectionID) ([]*core.Call, error) {
+	_, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	var calls []*core.Call
+	callIDs, ok := h.lookupConnectionCall[connID]
+	if !ok {
+		return calls, nil
+	}
+	for _, cID := range callIDs {
+		c, ok := h.lookupCall[cID]
+		if !ok {
+			continue
+		}
+		calls = append(calls, c)
+	}
+
+	return calls, nil
+}
+
+func (h *Handler) ConnectionGetParams(connID core.ConnectionID) (*core.ConnectionParams, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	return c.GetParams(), nil
+}
+
+func (h *Handler) ConnectionGetStructure(connID core.ConnectionID) ([]*core.Structure, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	layout, err := c.GetStructure()
+	if err != nil {
+		return nil, fmt.Errorf("c.GetStructure: %w", err)
+	}
+
+	return layout, nil
+}
+
+func (h *Handler) ConnectionGetColumns(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Column, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	columns, err := c.GetColumns(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// ConnectionGetTableSize returns the storage size of the table.
+func (h *Handler) ConnectionGetTableSize(connID core.ConnectionID, opts *core.TableOptions) (*core.TableSize, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	size, err := c.GetTableSize(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetTableSize: %w", err)
+	}
+
+	return size, nil
+}
+
+// ConnectionGetSequences returns sequences (and auto increment counters) of the database.
+// If opts are set, only sequences owned by the table are returned.
+func (h *Handler) ConnectionGetSequences(connID core.ConnectionID, opts *core.TableOptions) ([]*core.Sequence, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	sequences, err := c.GetSequences(opts)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetSequences: %w", err)
+	}
+
+	return sequences, nil
+}
+
+// ConnectionGetGrants returns privileges on the table. If effective is set,
+// only privileges of the current user are returned.
+func (h *Handler) ConnectionGetGrants(connID core.ConnectionID, opts *core.TableOptions, effective bool) ([]*core.Grant, error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	grants, err := c.GetGrants(opts, effective)
+	if err != nil {
+		return nil, fmt.Errorf("c.GetGrants: %w", err)
+	}
+
+	return grants, nil
+}
+
+func (h *Handler) ConnectionListDatabases(connID core.ConnectionID) (current string, available []string, err error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	currentDB, availableDBs, err := c.ListDatabases()
+	if err != nil {
+		if errors.Is(err, core.ErrDatabaseSwitchingNotSupported) {
+			return "", []string{}, nil
+		}
+		return "", nil, fmt.Errorf("c.ListDatabases: %w", err)
+	}
+
+	return currentDB, availableDBs, nil
+}
+
+func (h *Handler) ConnectionSelectDatabase(connID core.ConnectionID, database string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.SelectDatabase(database)
+	if err != nil {
+		return fmt.Errorf("c.SelectDatabase: %w", err)
+	}
+	h.events.DatabaseSelected(connID, database)
+
+	return nil
+}
+
+func (h *Handler) ConnectionListSchemas(connID core.ConnectionID) (current string, available []string, err error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	currentSchema, availableSchemas, err := c.ListSchemas()
+	if err != nil {
+		if errors.Is(err, core.ErrSchemaSwitchingNotSupported) {
+			return "", []string{}, nil
+		}
+		return "", nil, fmt.Errorf("c.ListSchemas: %w", err)
+	}
+
+	return currentSchema, availableSchemas, nil
+}
+
+func (h *Handler) ConnectionSelectSchema(connID core.ConnectionID, schema string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.SelectSchema(schema)
+	if err != nil {
+		return fmt.Errorf("c.SelectSchema: %w", err)
+	}
+	h.events.SchemaSelected(connID, schema)
+
+	return nil
+}
+
+func (h *Handler) ConnectionListBranches(connID core.ConnectionID) (current string, available []string, err error) {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	currentBranch, availableBranches, err := c.ListBranches()
+	if err != nil {
+		if errors.Is(err, core.ErrBranchSwitchingNotSupported) {
+			return "", []string{}, nil
+		}
+		return "", nil, fmt.Errorf("c.ListBranches: %w", err)
+	}
+
+	return currentBranch, availableBranches, nil
+}
+
+func (h *Handler) ConnectionSelectBranch(connID core.ConnectionID, branch string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	err := c.SelectBranch(branch)
+	if err != nil {
+		return fmt.Errorf("c.SelectBranch: %w", err)
+	}
+	h.events.BranchSelected(connID, branch)
+
+	return nil
+}
+
+// ConnectionExportSchema writes DDL of all tables and views of the connection to a file.
+func (h *Handler) ConnectionExportSchema(connID core.ConnectionID, path string) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+	defer file.Close()
+
+	err = c.ExportSchema(file)
+	if err != nil {
+		return fmt.Errorf("c.ExportSchema: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Handler) CallCancel(callID core.CallID) error {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	call.Cancel()
+	return nil
+}
+
+// CallProgress returns the progress of the call reported by the server so far
+// (nil if the driver doesn't report progress).
+func (h *Handler) CallProgress(callID core.CallID) (*core.Progress, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	return call.GetProgress(), nil
+}
+
+// CallPageNext starts a call which selects the page after the call's page. The page starts
+// after the provided key values or after the key of the call's last row if after is empty.
+func (h *Handler) CallPageNext(callID core.CallID, after []any) (*core.Call, error) {
+	prev, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	for connID, calls := range h.lookupConnectionCall {
+		if !slices.Contains(calls, callID) {
+			continue
+		}
+		c, ok := h.lookupConnection[connID]
+		if !ok {
+			break
+		}
+
+		if len(after) == 0 {
+			after = nil
+		}
+		call, err := c.ExecuteNextPage(prev, after, h.onCallStateChanged)
+		if err != nil {
+			return nil, fmt.Errorf("c.ExecuteNextPage: %w", err)
+		}
+
+		h.addCall(connID, call)
+
+		return call, nil
+	}
+
+	return nil, fmt.Errorf("no connection of call with id: %q", callID)
+}
+
+// CallDisplayResult displays rows (from-to) of the call's result in the buffer and returns
+// the number of all rows. If columnOffsets is set, byte offsets of column starts on every
+// line (see format.TableColumnOffsets) are stored in the "dbee_columns" buffer variable.
+func (h *Handler) CallDisplayResult(callID core.CallID, buffer nvim.Buffer, from, to int, style format.TableStyle, columnOffsets bool) (int, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return 0, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return 0, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	text, err := res.Format(h.callFormatter(callID, format.NewTextTable(style)), from, to)
+	if err != nil {
+		return 0, fmt.Errorf("res.Format: %w", err)
+	}
+
+	_, err = newBuffer(h.vim, buffer).Write(text)
+	if err != nil {
+		return 0, fmt.Errorf("buffer.Write: %w", err)
+	}
+
+	if columnOffsets {
+		offsets := format.TableColumnOffsets(text)
+		if offsets == nil {
+			offsets = [][]int{}
+		}
+		if err := h.vim.SetBufferVar(buffer, "dbee_columns", offsets); err != nil {
+			return 0, fmt.Errorf("h.vim.SetBufferVar: %w", err)
+		}
+	}
+
+	return res.Len(), nil
+}
+
+// CallResultFooter returns the footer line of the displayed rows of the call's result
+// (from-to as in CallDisplayResult). It tells whether the result was cut by the row
+// limit or is still streaming, so it can be redrawn while rows arrive.
+func (h *Handler) CallResultFooter(callID core.CallID, from, to int) (string, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return "", fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return "", fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	footer := &resultFooter{
+		from:      from,
+		to:        to,
+		total:     res.Len(),
+		streaming: !res.IsDrained(),
+		elapsed:   call.GetTimeTaken(),
+	}
+	if meta := res.Meta(); meta != nil {
+		footer.limit = meta.Limit
+		footer.endpoint = meta.Endpoint
+	}
+	// time taken is known once the call is done
+	if footer.streaming || footer.elapsed <= 0 {
+		footer.elapsed = time.Since(call.GetTimestamp())
+	}
+
+	return footer.String(), nil
+}
+
+// CallSortResult sorts the already fetched result of the call by column
+// without re-running the query.
+func (h *Handler) CallSortResult(callID core.CallID, column int, ascending bool) error {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	if err := res.Sort(column, ascending); err != nil {
+		return fmt.Errorf("res.Sort: %w", err)
+	}
+
+	return nil
+}
+
+// CallSelectColumns sets which columns of the call's result are displayed and stored
+// (and in which order) without re-running the query. Unknown column names are
+// ignored and returned. No names reset the selection.
+func (h *Handler) CallSelectColumns(callID core.CallID, names []string) ([]string, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	return res.SelectColumns(names), nil
+}
+
+// CallChartData reduces the call's result to the x column and numeric y columns for plotting.
+func (h *Handler) CallChartData(callID core.CallID, x string, y []string) (*core.ChartData, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	data, err := res.ChartData(x, y)
+	if err != nil {
+		return nil, fmt.Errorf("res.ChartData: %w", err)
+	}
+
+	return data, nil
+}
+
+// CallHighlightRows returns indexes of the call's result rows which match
+// the predicate "<column> <operator> <value>" (see core.Result.MatchRows).
+func (h *Handler) CallHighlightRows(callID core.CallID, column, operator, value string) ([]int, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return nil, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	indexes, err := res.MatchRows(column, operator, value)
+	if err != nil {
+		return nil, fmt.Errorf("res.MatchRows: %w", err)
+	}
+
+	return indexes, nil
+}
+
+func (h *Handler) CallStoreResult(callID core.CallID, fmat, out string, from, to int, arg ...any) error {
+	stat, ok := h.lookupCall[callID]
+	if !ok {
+		return fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	// "table:<path>" writes the aligned text table (as shown in the result buffer) to a file
+	if path, ok := strings.CutPrefix(out, "table:"); ok {
+		fmat, out, arg = "table", "file", []any{path}
+	}
+
+	// "csv-append:<path>" appends rows to a csv file, matching its header
+	if path, ok := strings.CutPrefix(out, "csv-append:"); ok {
+		res, err := stat.GetResult()
+		if err != nil {
+			return fmt.Errorf("stat.GetResult: %w", err)
+		}
+
+		return appendCSV(res, path, from, to, func(f core.Formatter) core.Formatter {
+			return h.callFormatter(callID, f)
+		})
+	}
+
+	formatter, err := storeFormatter(fmat)
+	if err != nil {
+		return err
+	}
+
+	writer, cleanup, err := h.getStoreWriter(out, arg...)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	res, err := stat.GetResult()
+	if err != nil {
+		return fmt.Errorf("stat.GetResult: %w", err)
+	}
+
+	text, err := res.Format(h.callFormatter(callID, formatter), from, to)
+	if err != nil {
+		return fmt.Errorf("res.Format: %w", err)
+	}
+
+	_, err = writer.Write(text)
+	if err != nil {
+		return fmt.Errorf("buffer.Write: %w", err)
+	}
+
+	return nil
+}
+
+// CallYankResult stores the formatted result of the call to the register.
+// If maxRows is positive, only the first maxRows rows are yanked, so large
+// results don't flood the clipboard. It returns the number of yanked rows and
+// whether the result was truncated.
+func (h *Handler) CallYankResult(callID core.CallID, fmat, register string, maxRows int) (rows int, truncated bool, err error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return 0, false, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	formatter, err := storeFormatter(fmat)
+	if err != nil {
+		return 0, false, err
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return 0, false, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	to := -1
+	if maxRows > 0 {
+		to = maxRows
+	}
+
+	text, err := res.Format(h.callFormatter(callID, formatter), 0, to)
+	if err != nil {
+		return 0, false, fmt.Errorf("res.Format: %w", err)
+	}
+
+	if _, err := newYankRegister(h.vim, register).Write(text); err != nil {
+		return 0, false, err
+	}
+
+	rows = res.Len()
+	if maxRows > 0 && rows > maxRows {
+		return maxRows, true, nil
+	}
+	return rows, false, nil
+}
+
+// CallExportSheet writes the result of the call to a tab of a Google Sheets spreadsheet,
+// replacing its contents (the tab is created if it doesn't exist). Credentials are a path
+// to a credentials file, its contents or empty for application default credentials.
+// Returns the number of exported rows.
+func (h *Handler) CallExportSheet(callID core.CallID, spreadsheetID, sheet, credentials string) (int, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return 0, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return 0, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	ctx := context.Background()
+
+	svc, err := newSheetsService(ctx, credentials)
+	if err != nil {
+		return 0, err
+	}
+
+	w := &sheetWriter{svc: svc, spreadsheetID: spreadsheetID, sheet: sheet}
+	rows, err := w.write(ctx, res, func(f core.Formatter) core.Formatter {
+		return h.callFormatter(callID, f)
+	})
+	if err != nil {
+		return rows, fmt.Errorf("w.write: %w", maskCredentials(err, credentials))
+	}
+
+	h.log.Infof("exported %d rows of call %q to sheet %q of spreadsheet %q", rows, callID, sheet, spreadsheetID)
+
+	return rows, nil
+}
+
+// CallExportS3 uploads the result of the call to an S3 object. The url is in form of
+// "s3://bucket/key?region=...&format=csv" (see s3Target). The result is formatted and
+// uploaded in parts, so it's never held in memory as a whole. Credentials are looked
+// up in the environment and the shared AWS credentials file. Returns the number of
+// exported rows.
+func (h *Handler) CallExportS3(callID core.CallID, url string) (int, error) {
+	call, ok := h.lookupCall[callID]
+	if !ok {
+		return 0, fmt.Errorf("unknown call with id: %q", callID)
+	}
+
+	res, err := call.GetResult()
+	if err != nil {
+		return 0, fmt.Errorf("call.GetResult: %w", err)
+	}
+
+	rows, err := exportS3(context.Background(), res, url, func(f core.Formatter) core.Formatter {
+		return h.callFormatter(callID, f)
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	h.log.Infof("exported %d rows of call %q to %q", rows, callID, url)
+
+	return rows, nil
+}
+
+// storeFormatter returns the formatter of stored (or yanked) results.
+func storeFormatter(fmat string) (core.Formatter, error) {
+	switch fmat {
+	case "json":
+		return format.NewJSON(), nil
+	case "csv":
+		return format.NewCSV(), nil
+	case "tsv":
+		return format.NewTSV(), nil
+	case "markdown":
+		return format.NewMarkdown(), nil
+	case "yaml":
+		return format.NewYAML(), nil
+	case "table":
+		return format.NewTextTable(format.TableStyleBorderless), nil
+	}
+
+	return nil, fmt.Errorf("store output: %q is not supported", fmat)
+}
+
+// callFormatter wraps the formatter with time and bool formats and the mask policy
+// of the connection the call belongs to.
+func (h *Handler) callFormatter(callID core.CallID, formatter core.Formatter) core.Formatter {
+	for connID, calls := range h.lookupConnectionCall {
+		if !slices.Contains(calls, callID) {
+			continue
+		}
+		if c, ok := h.lookupConnection[connID]; ok {
+			return connectionFormatter(c, formatter)
+		}
+	}
+	return formatter
+}
+
+// connectionFormatter wraps the formatter with the formats of the connection.
+func connectionFormatter(c *core.Connection, formatter core.Formatter) core.Formatter {
+	// values are masked after they are formatted, so lengths match the output
+	formatter = core.NewMaskFormatter(formatter, c.GetMaskPolicy())
+	formatter = core.NewTimeFormatter(formatter, c.GetTimeFormat())
+	return core.NewBoolFormatter(formatter, c.GetBoolFormat())
 }
 
 func (h *Handler) getStoreWriter(output string, arg ...any) (writer io.Writer, cleanup func(), err error) {
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+var (
+	// interpolationToken matches tokens like "{{word}}" or "{{ g:my_var }}"
+	// (go templates of helpers start with a dot, so they don't match).
+	interpolationToken = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w:#]*)\s*\}\}`)
+	// scopedVariable matches variables which are evaluated as they are (e.g. "g:my_var").
+	scopedVariable = regexp.MustCompile(`^[gbwtv]:[A-Za-z_][\w#]*$`)
+)
+
+// interpolate replaces tokens of the query with SQL literals of their values.
+// Tokens are either configured (name to vim expression) or scoped vim variables,
+// other tokens are left untouched. Every token is evaluated only once.
+func interpolate(query string, tokens map[string]string, eval func(expr string) (any, error)) (string, error) {
+	values := make(map[string]string)
+
+	var evalErr error
+	out := interpolationToken.ReplaceAllStringFunc(query, func(match string) string {
+		name := interpolationToken.FindStringSubmatch(match)[1]
+		if literal, ok := values[name]; ok {
+			return literal
+		}
+
+		expr, ok := tokens[name]
+		if !ok && scopedVariable.MatchString(name) {
+			expr = name
+		}
+		if expr == "" || evalErr != nil {
+			return match
+		}
+
+		val, err := eval(expr)
+		if err != nil {
+			evalErr = fmt.Errorf("token %q: %w", name, err)
+			return match
+		}
+
+		values[name] = core.QuoteLiteral(val)
+		return values[name]
+	})
+	if evalErr != nil {
+		return "", evalErr
+	}
+
+	return out, nil
+}
+
+// SetInterpolationTokens sets tokens replaced in executed queries (name to vim expression).
+// An empty expression disables the token.
+func (h *Handler) SetInterpolationTokens(tokens map[string]string) {
+	h.interpolationTokens = tokens
+}
+
+// interpolateQuery evaluates tokens of the query in neovim.
+func (h *Handler) interpolateQuery(query string) (string, error) {
+	return interpolate(query, h.interpolationTokens, func(expr string) (any, error) {
+		var val any
+		if err := h.vim.Eval(expr, &val); err != nil {
+			return nil, err
+		}
+		return val, nil
+	})
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	r := require.New(t)
+
+	vars := map[string]any{
+		"expand('<cword>')": "O'Brien",
+		"g:ids":             []any{int64(1), int64(2)},
+		"b:limit":           int64(10),
+	}
+	var evaluated []string
+	eval := func(expr string) (any, error) {
+		evaluated = append(evaluated, expr)
+		val, ok := vars[expr]
+		if !ok {
+			return nil, errors.New("undefined variable")
+		}
+		return val, nil
+	}
+	tokens := map[string]string{
+		"word":      "expand('<cword>')",
+		"selection": "",
+	}
+
+	out, err := interpolate(
+		"SELECT * FROM users WHERE name = {{word}} OR id IN ({{ g:ids }}) OR alias = {{word}} LIMIT {{b:limit}}",
+		tokens, eval)
+	r.NoError(err)
+	r.Equal("SELECT * FROM users WHERE name = 'O''Brien' OR id IN (1, 2) OR alias = 'O''Brien' LIMIT 10", out)
+	r.Equal([]string{"expand('<cword>')", "g:ids", "b:limit"}, evaluated)
+
+	// unknown, disabled and template tokens are kept
+	out, err = interpolate("SELECT '{{unknown}}', {{selection}}, {{ .Table }}", tokens, eval)
+	r.NoError(err)
+	r.Equal("SELECT '{{unknown}}', {{selection}}, {{ .Table }}", out)
+
+	_, err = interpolate("SELECT {{g:missing}}", tokens, eval)
+	r.ErrorContains(err, "g:missing")
+}
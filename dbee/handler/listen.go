@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+type listenerKey struct {
+	connID  core.ConnectionID
+	channel string
+}
+
+// listener is an active subscription of a connection to a notification channel.
+type listener struct {
+	cancel context.CancelFunc
+}
+
+// listeners holds active subscriptions. Notifications are written from their
+// own goroutines, so access is guarded by a mutex.
+type listeners struct {
+	mu     sync.Mutex
+	lookup map[listenerKey]*listener
+}
+
+func (ls *listeners) add(key listenerKey, l *listener) bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if _, ok := ls.lookup[key]; ok {
+		return false
+	}
+	ls.lookup[key] = l
+	return true
+}
+
+// remove removes the listener if it's still registered under the key.
+func (ls *listeners) remove(key listenerKey, l *listener) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ls.lookup[key] == l {
+		delete(ls.lookup, key)
+	}
+}
+
+// stop cancels listeners matching the filter. It doesn't wait for them to finish,
+// as their goroutines might be blocked on writing to neovim.
+func (ls *listeners) stop(match func(listenerKey) bool) int {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	stopped := 0
+	for key, l := range ls.lookup {
+		if match(key) {
+			l.cancel()
+			delete(ls.lookup, key)
+			stopped++
+		}
+	}
+
+	return stopped
+}
+
+// formatNotification formats the notification as a single buffer line.
+func formatNotification(n *core.Notification) string {
+	payload := strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(n.Payload)
+
+	line := fmt.Sprintf("%s  %s", n.Received.Format(time.RFC3339), n.Channel)
+	if n.PID != 0 {
+		line += fmt.Sprintf(" (pid %d)", n.PID)
+	}
+	return line + ": " + payload
+}
+
+// ConnectionListen starts listening for notifications on the channel and appends
+// them to the buffer as they arrive, until ConnectionUnlisten is called.
+func (h *Handler) ConnectionListen(connID core.ConnectionID, channel string, buffer nvim.Buffer) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	key := listenerKey{connID: connID, channel: channel}
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &listener{cancel: cancel}
+
+	if !h.listeners.add(key, l) {
+		cancel()
+		return fmt.Errorf("already listening on channel: %q", channel)
+	}
+
+	notifications, err := c.Listen(ctx, channel)
+	if err != nil {
+		h.listeners.remove(key, l)
+		cancel()
+		return fmt.Errorf("c.Listen: %w", err)
+	}
+
+	go func() {
+		defer h.listeners.remove(key, l)
+		defer cancel()
+
+		buf := newBuffer(h.vim, buffer)
+		for n := range notifications {
+			if err := buf.Append(formatNotification(n)); err != nil {
+				// the buffer is probably gone
+				h.log.Infof("stopped listening on %q: buf.Append: %s", channel, err)
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ConnectionUnlisten stops listening on the channel.
+func (h *Handler) ConnectionUnlisten(connID core.ConnectionID, channel string) error {
+	n := h.listeners.stop(func(key listenerKey) bool {
+		return key.connID == connID && key.channel == channel
+	})
+	if n < 1 {
+		return fmt.Errorf("not listening on channel: %q", channel)
+	}
+
+	return nil
+}
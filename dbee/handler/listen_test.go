@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+func TestFormatNotification(t *testing.T) {
+	r := require.New(t)
+
+	received := time.Date(2024, 3, 5, 14, 30, 15, 0, time.UTC)
+
+	r.Equal("2024-03-05T14:30:15Z  orders (pid 42): {\"id\":1}\\nnext", formatNotification(&core.Notification{
+		Channel:  "orders",
+		Payload:  "{\"id\":1}\nnext",
+		PID:      42,
+		Received: received,
+	}))
+
+	r.Equal("2024-03-05T14:30:15Z  orders: ", formatNotification(&core.Notification{
+		Channel:  "orders",
+		Received: received,
+	}))
+}
+
+func TestListeners(t *testing.T) {
+	r := require.New(t)
+
+	ls := &listeners{lookup: make(map[listenerKey]*listener)}
+
+	cancelled := 0
+	newListener := func() *listener {
+		return &listener{cancel: func() { cancelled++ }}
+	}
+
+	a := listenerKey{connID: "c1", channel: "a"}
+	b := listenerKey{connID: "c1", channel: "b"}
+	c := listenerKey{connID: "c2", channel: "a"}
+
+	la := newListener()
+	r.True(ls.add(a, la))
+	r.False(ls.add(a, newListener()))
+	r.True(ls.add(b, newListener()))
+	r.True(ls.add(c, newListener()))
+
+	// removing a stale listener keeps the registered one
+	ls.remove(a, newListener())
+	r.Len(ls.lookup, 3)
+
+	r.Equal(2, ls.stop(func(key listenerKey) bool { return key.connID == "c1" }))
+	r.Equal(2, cancelled)
+	r.Len(ls.lookup, 1)
+
+	// finished listener removes itself
+	ls.remove(c, ls.lookup[c])
+	r.Empty(ls.lookup)
+	r.Equal(0, ls.stop(func(listenerKey) bool { return true }))
+}
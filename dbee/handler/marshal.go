@@ -39,13 +39,32 @@ func (cw *callWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		errMsg = err.Error()
 	}
 
+	type pageInfo struct {
+		Number int      `msgpack:"number"`
+		Size   int      `msgpack:"size"`
+		Keyset bool     `msgpack:"keyset"`
+		Keys   []string `msgpack:"keys,omitempty"`
+	}
+	var page *pageInfo
+	if p := cw.call.GetPage(); p != nil {
+		page = &pageInfo{
+			Number: p.Number,
+			Size:   p.Size,
+			Keyset: p.Keyset,
+			Keys:   p.Keys,
+		}
+	}
+
 	return enc.Encode(&struct {
-		ID        string `msgpack:"id"`
-		Query     string `msgpack:"query"`
-		State     string `msgpack:"state"`
-		TimeTaken int64  `msgpack:"time_taken_us"`
-		Timestamp int64  `msgpack:"timestamp_us"`
-		Error     string `msgpack:"error,omitempty"`
+		ID        string            `msgpack:"id"`
+		Query     string            `msgpack:"query"`
+		State     string            `msgpack:"state"`
+		TimeTaken int64             `msgpack:"time_taken_us"`
+		Timestamp int64             `msgpack:"timestamp_us"`
+		Error     string            `msgpack:"error,omitempty"`
+		Stats     map[string]string `msgpack:"stats,omitempty"`
+		Warnings  []string          `msgpack:"warnings,omitempty"`
+		Page      *pageInfo         `msgpack:"page,omitempty"`
 	}{
 		ID:        string(cw.call.GetID()),
 		Query:     cw.call.GetQuery(),
@@ -53,6 +72,9 @@ func (cw *callWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		TimeTaken: cw.call.GetTimeTaken().Microseconds(),
 		Timestamp: cw.call.GetTimestamp().UnixMicro(),
 		Error:     errMsg,
+		Stats:     cw.call.GetStats(),
+		Warnings:  cw.call.GetWarnings(),
+		Page:      page,
 	})
 }
 
@@ -112,15 +134,19 @@ func (cw *connectionParamsWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		return enc.Encode(nil)
 	}
 	return enc.Encode(&struct {
-		ID   string `msgpack:"id"`
-		Name string `msgpack:"name"`
-		Type string `msgpack:"type"`
-		URL  string `msgpack:"url"`
+		ID      string            `msgpack:"id"`
+		Name    string            `msgpack:"name"`
+		Type    string            `msgpack:"type"`
+		URL     string            `msgpack:"url"`
+		Options map[string]string `msgpack:"options,omitempty"`
+		Values  map[string]string `msgpack:"values,omitempty"`
 	}{
-		ID:   string(cw.params.ID),
-		Name: cw.params.Name,
-		Type: cw.params.Type,
-		URL:  cw.params.URL,
+		ID:      string(cw.params.ID),
+		Name:    cw.params.Name,
+		Type:    cw.params.Type,
+		URL:     cw.params.URL,
+		Options: cw.params.Options,
+		Values:  cw.params.Values,
 	})
 }
 
@@ -155,11 +181,13 @@ func (cw *structureWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		Name     string           `msgpack:"name"`
 		Schema   string           `msgpack:"schema"`
 		Type     string           `msgpack:"type"`
+		Parent   string           `msgpack:"parent,omitempty"`
 		Children []*structureWrap `msgpack:"children"`
 	}{
 		Name:     cw.structure.Name,
 		Schema:   cw.structure.Schema,
 		Type:     cw.structure.Type.String(),
+		Parent:   cw.structure.Parent,
 		Children: WrapStructures(cw.structure.Children),
 	})
 }
@@ -192,10 +220,306 @@ func (cw *columnWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
 		return enc.Encode(nil)
 	}
 	return enc.Encode(&struct {
-		Name string `msgpack:"name"`
-		Type string `msgpack:"type"`
+		Name      string `msgpack:"name"`
+		Type      string `msgpack:"type"`
+		MaxLength int64  `msgpack:"max_length,omitempty"`
+		Precision int64  `msgpack:"precision,omitempty"`
+		Scale     int64  `msgpack:"scale,omitempty"`
+
+		IsGenerated          bool   `msgpack:"is_generated,omitempty"`
+		GenerationExpression string `msgpack:"generation_expression,omitempty"`
+	}{
+		Name:      cw.column.Name,
+		Type:      cw.column.Type,
+		MaxLength: cw.column.MaxLength,
+		Precision: cw.column.Precision,
+		Scale:     cw.column.Scale,
+
+		IsGenerated:          cw.column.IsGenerated,
+		GenerationExpression: cw.column.GenerationExpression,
+	})
+}
+
+// explainSummaryWrap is a wrapper around core.ExplainSummary with msgpack marshaling capabilities
+type explainSummaryWrap struct {
+	summary *core.ExplainSummary
+}
+
+func WrapExplainSummary(summary *core.ExplainSummary) *explainSummaryWrap {
+	return &explainSummaryWrap{
+		summary: summary,
+	}
+}
+
+func (ew *explainSummaryWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if ew.summary == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		TotalCost         float64 `msgpack:"total_cost"`
+		PlanRows          int64   `msgpack:"plan_rows"`
+		ActualRows        *int64  `msgpack:"actual_rows"`
+		ExpensiveNode     string  `msgpack:"expensive_node"`
+		ExpensiveNodeCost float64 `msgpack:"expensive_node_cost"`
+	}{
+		TotalCost:         ew.summary.TotalCost,
+		PlanRows:          ew.summary.PlanRows,
+		ActualRows:        ew.summary.ActualRows,
+		ExpensiveNode:     ew.summary.ExpensiveNode,
+		ExpensiveNodeCost: ew.summary.ExpensiveNodeCost,
+	})
+}
+
+// tableSizeWrap is a wrapper around core.TableSize with msgpack marshaling capabilities.
+// Sizes are sent both in bytes and human readable.
+type tableSizeWrap struct {
+	size *core.TableSize
+}
+
+func WrapTableSize(size *core.TableSize) *tableSizeWrap {
+	return &tableSizeWrap{
+		size: size,
+	}
+}
+
+func (tw *tableSizeWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if tw.size == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		Total          int64  `msgpack:"total"`
+		Data           int64  `msgpack:"data"`
+		Index          int64  `msgpack:"index"`
+		Overhead       int64  `msgpack:"overhead"`
+		TotalPretty    string `msgpack:"total_pretty"`
+		DataPretty     string `msgpack:"data_pretty"`
+		IndexPretty    string `msgpack:"index_pretty"`
+		OverheadPretty string `msgpack:"overhead_pretty"`
+	}{
+		Total:          tw.size.Total,
+		Data:           tw.size.Data,
+		Index:          tw.size.Index,
+		Overhead:       tw.size.Overhead,
+		TotalPretty:    core.FormatBytes(tw.size.Total),
+		DataPretty:     core.FormatBytes(tw.size.Data),
+		IndexPretty:    core.FormatBytes(tw.size.Index),
+		OverheadPretty: core.FormatBytes(tw.size.Overhead),
+	})
+}
+
+// progressWrap is a wrapper around core.Progress with msgpack marshaling capabilities.
+// Byte counts are sent both in bytes and human readable.
+type progressWrap struct {
+	progress *core.Progress
+}
+
+func WrapProgress(progress *core.Progress) *progressWrap {
+	return &progressWrap{
+		progress: progress,
+	}
+}
+
+func (pw *progressWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if pw.progress == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		RowsRead           uint64 `msgpack:"rows_read"`
+		BytesRead          uint64 `msgpack:"bytes_read"`
+		TotalRows          uint64 `msgpack:"total_rows"`
+		RowsWritten        uint64 `msgpack:"rows_written"`
+		BytesWritten       uint64 `msgpack:"bytes_written"`
+		BytesReadPretty    string `msgpack:"bytes_read_pretty"`
+		BytesWrittenPretty string `msgpack:"bytes_written_pretty"`
+		Stage              string `msgpack:"stage"`
+	}{
+		RowsRead:           pw.progress.RowsRead,
+		BytesRead:          pw.progress.BytesRead,
+		TotalRows:          pw.progress.TotalRows,
+		RowsWritten:        pw.progress.RowsWritten,
+		BytesWritten:       pw.progress.BytesWritten,
+		BytesReadPretty:    core.FormatBytes(int64(pw.progress.BytesRead)),
+		BytesWrittenPretty: core.FormatBytes(int64(pw.progress.BytesWritten)),
+		Stage:              pw.progress.Stage,
+	})
+}
+
+// sequenceWrap is a wrapper around core.Sequence with msgpack marshaling capabilities.
+// Remaining headroom is computed, so it doesn't have to be done in lua.
+type sequenceWrap struct {
+	sequence *core.Sequence
+}
+
+func WrapSequences(sequences []*core.Sequence) []*sequenceWrap {
+	wraps := make([]*sequenceWrap, len(sequences))
+	for i, s := range sequences {
+		wraps[i] = &sequenceWrap{
+			sequence: s,
+		}
+	}
+	return wraps
+}
+
+func (sw *sequenceWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	return enc.Encode(&struct {
+		Schema      string  `msgpack:"schema"`
+		Name        string  `msgpack:"name"`
+		Table       string  `msgpack:"table"`
+		Column      string  `msgpack:"column"`
+		Current     *int64  `msgpack:"current"`
+		Increment   int64   `msgpack:"increment"`
+		Min         int64   `msgpack:"min"`
+		Max         int64   `msgpack:"max"`
+		Remaining   uint64  `msgpack:"remaining"`
+		UsedPercent float64 `msgpack:"used_percent"`
+	}{
+		Schema:      sw.sequence.Schema,
+		Name:        sw.sequence.Name,
+		Table:       sw.sequence.Table,
+		Column:      sw.sequence.Column,
+		Current:     sw.sequence.Current,
+		Increment:   sw.sequence.Increment,
+		Min:         sw.sequence.Min,
+		Max:         sw.sequence.Max,
+		Remaining:   sw.sequence.Remaining(),
+		UsedPercent: sw.sequence.UsedPercent(),
+	})
+}
+
+// grantWrap is a wrapper around core.Grant with msgpack marshaling capabilities.
+type grantWrap struct {
+	grant *core.Grant
+}
+
+func WrapGrants(grants []*core.Grant) []*grantWrap {
+	wraps := make([]*grantWrap, len(grants))
+	for i, g := range grants {
+		wraps[i] = &grantWrap{
+			grant: g,
+		}
+	}
+	return wraps
+}
+
+func (gw *grantWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	return enc.Encode(&struct {
+		Grantee   string `msgpack:"grantee"`
+		Privilege string `msgpack:"privilege"`
+		Grantable bool   `msgpack:"grantable"`
+		Level     string `msgpack:"level"`
+	}{
+		Grantee:   gw.grant.Grantee,
+		Privilege: gw.grant.Privilege,
+		Grantable: gw.grant.Grantable,
+		Level:     gw.grant.Level,
+	})
+}
+
+// planNodeWrap is a wrapper around core.PlanNode with msgpack marshaling capabilities
+type planNodeWrap struct {
+	node *core.PlanNode
+}
+
+func WrapPlanNode(node *core.PlanNode) *planNodeWrap {
+	return &planNodeWrap{
+		node: node,
+	}
+}
+
+func (pw *planNodeWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if pw.node == nil {
+		return enc.Encode(nil)
+	}
+
+	children := make([]*planNodeWrap, len(pw.node.Children))
+	for i, child := range pw.node.Children {
+		children[i] = WrapPlanNode(child)
+	}
+
+	return enc.Encode(&struct {
+		Type       string          `msgpack:"type"`
+		Relation   string          `msgpack:"relation"`
+		Index      string          `msgpack:"index"`
+		Cost       float64         `msgpack:"cost"`
+		Rows       int64           `msgpack:"rows"`
+		ActualRows *int64          `msgpack:"actual_rows"`
+		Children   []*planNodeWrap `msgpack:"children"`
+	}{
+		Type:       pw.node.Type,
+		Relation:   pw.node.Relation,
+		Index:      pw.node.Index,
+		Cost:       pw.node.Cost,
+		Rows:       pw.node.Rows,
+		ActualRows: pw.node.ActualRows,
+		Children:   children,
+	})
+}
+
+// chartDataWrap is a wrapper around core.ChartData with msgpack marshaling capabilities
+type chartDataWrap struct {
+	data *core.ChartData
+}
+
+func WrapChartData(data *core.ChartData) *chartDataWrap {
+	return &chartDataWrap{
+		data: data,
+	}
+}
+
+func (cw *chartDataWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if cw.data == nil {
+		return enc.Encode(nil)
+	}
+	return enc.Encode(&struct {
+		X      []any                `msgpack:"x"`
+		Series map[string][]float64 `msgpack:"series"`
+	}{
+		X:      cw.data.X,
+		Series: cw.data.Series,
+	})
+}
+
+// snapshotDiffWrap is a wrapper around core.SnapshotDiff with msgpack marshaling capabilities
+type snapshotDiffWrap struct {
+	diff *core.SnapshotDiff
+}
+
+func WrapSnapshotDiff(diff *core.SnapshotDiff) *snapshotDiffWrap {
+	return &snapshotDiffWrap{
+		diff: diff,
+	}
+}
+
+func (sw *snapshotDiffWrap) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if sw.diff == nil {
+		return enc.Encode(nil)
+	}
+
+	type row struct {
+		Row    int    `msgpack:"row"`
+		Values string `msgpack:"values"`
+	}
+	rows := func(in []core.SnapshotRow) []row {
+		out := make([]row, len(in))
+		for i, r := range in {
+			out[i] = row{Row: r.Row, Values: r.Values}
+		}
+		return out
+	}
+
+	return enc.Encode(&struct {
+		Match           bool     `msgpack:"match"`
+		Updated         bool     `msgpack:"updated"`
+		ExpectedColumns []string `msgpack:"expected_columns,omitempty"`
+		ActualColumns   []string `msgpack:"actual_columns,omitempty"`
+		Missing         []row    `msgpack:"missing"`
+		Unexpected      []row    `msgpack:"unexpected"`
 	}{
-		Name: cw.column.Name,
-		Type: cw.column.Type,
+		Match:           sw.diff.Match,
+		Updated:         sw.diff.Updated,
+		ExpectedColumns: sw.diff.ExpectedColumns,
+		ActualColumns:   sw.diff.ActualColumns,
+		Missing:         rows(sw.diff.Missing),
+		Unexpected:      rows(sw.diff.Unexpected),
 	})
 }
@@ -26,33 +26,57 @@ func (b *Buffer) Write(p []byte) (int, error) {
 		lines = append(lines, []byte(scanner.Text()))
 	}
 
+	err := b.modify(func() error {
+		return b.vim.SetBufferLines(b.buffer, 0, -1, true, lines)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Append adds lines to the end of the buffer.
+func (b *Buffer) Append(lines ...string) error {
+	bl := make([][]byte, len(lines))
+	for i, l := range lines {
+		bl[i] = []byte(l)
+	}
+
+	return b.modify(func() error {
+		return b.vim.SetBufferLines(b.buffer, -1, -1, true, bl)
+	})
+}
+
+// modify calls fn while the buffer is temporarily modifiable.
+func (b *Buffer) modify(fn func() error) error {
 	const modifiableOptionName = "modifiable"
 
 	// is the buffer modifiable
 	isModifiable := false
 	err := b.vim.BufferOption(b.buffer, modifiableOptionName, &isModifiable)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	if !isModifiable {
 		err = b.vim.SetBufferOption(b.buffer, modifiableOptionName, true)
 		if err != nil {
-			return 0, err
+			return err
 		}
 	}
 
-	err = b.vim.SetBufferLines(b.buffer, 0, -1, true, lines)
+	err = fn()
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	if !isModifiable {
 		err = b.vim.SetBufferOption(b.buffer, modifiableOptionName, false)
 		if err != nil {
-			return 0, err
+			return err
 		}
 	}
 
-	return len(p), nil
+	return nil
 }
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+// openCSVAppend opens the csv file for appending and reads its header. If the file
+// doesn't exist or is empty, it's created and the returned header is nil.
+// A missing newline at the end of the file is added, so appended rows start on a new line.
+func openCSVAppend(path string) (file *os.File, header []string, err error) {
+	file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	size := stat.Size()
+	if size == 0 {
+		return file, nil, nil
+	}
+
+	r := csv.NewReader(io.NewSectionReader(file, 0, size))
+	r.FieldsPerRecord = -1
+	header, err = r.Read()
+	if errors.Is(err, io.EOF) {
+		return file, nil, nil
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("invalid header of %q: %w", path, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, size-1); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if last[0] != '\n' {
+		if _, err := file.Write([]byte("\n")); err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+	}
+
+	return file, header, nil
+}
+
+// appendCSV appends rows of the result to the csv file. The result is written with
+// its header if the file is new (or empty), otherwise only its rows are written
+// in the order of columns of the file. wrap wraps the formatter with the formats
+// of the connection.
+func appendCSV(res *core.Result, path string, from, to int, wrap func(core.Formatter) core.Formatter) error {
+	file, header, err := openCSVAppend(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	formatter := format.NewCSV()
+	if header != nil {
+		formatter = format.NewCSVAppend(header)
+	}
+
+	text, err := res.Format(wrap(formatter), from, to)
+	if err != nil {
+		return fmt.Errorf("res.Format: %w", err)
+	}
+
+	_, err = file.Write(text)
+	if err != nil {
+		return fmt.Errorf("file.Write: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestAppendCSV(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "out.csv")
+	read := func() string {
+		b, err := os.ReadFile(path)
+		r.NoError(err)
+		return string(b)
+	}
+
+	// new file gets the header
+	r.NoError(appendCSV(newTestResult(t, []core.Row{{1, "a"}}), path, 0, -1, noWrap))
+	r.Equal("id,name\n1,a\n", read())
+
+	// only rows are appended
+	r.NoError(appendCSV(newTestResult(t, []core.Row{{2, "b, c"}}), path, 0, -1, noWrap))
+	r.Equal("id,name\n1,a\n2,\"b, c\"\n", read())
+
+	// columns are reordered to match the header
+	res := core.NewResult(0)
+	r.NoError(res.SetIter(mock.NewResultStream([]core.Row{{"d", 3}}, mock.ResultStreamWithHeader(core.Header{"name", "id"})), nil))
+	r.NoError(appendCSV(res, path, 0, -1, noWrap))
+	r.Equal("id,name\n1,a\n2,\"b, c\"\n3,d\n", read())
+
+	// different columns
+	res = core.NewResult(0)
+	r.NoError(res.SetIter(mock.NewResultStream([]core.Row{{4, "e"}}, mock.ResultStreamWithHeader(core.Header{"id", "title"})), nil))
+	r.ErrorContains(appendCSV(res, path, 0, -1, noWrap), "columns of the result (id, title) don't match the header (id, name)")
+	r.Equal("id,name\n1,a\n2,\"b, c\"\n3,d\n", read())
+
+	// missing newline at the end and a byte order mark
+	r.NoError(os.WriteFile(path, []byte("\ufeffid,name\n1,a"), 0o644))
+	r.NoError(appendCSV(newTestResult(t, []core.Row{{2, "b"}}), path, 0, -1, noWrap))
+	r.Equal("\ufeffid,name\n1,a\n2,b\n", read())
+
+	// empty file gets the header
+	r.NoError(os.WriteFile(path, nil, 0o644))
+	r.NoError(appendCSV(newTestResult(t, []core.Row{{1, "a"}}), path, 0, -1, noWrap))
+	r.Equal("id,name\n1,a\n", read())
+}
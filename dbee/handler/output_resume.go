@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// exportResumable writes rows of the query to w in the format of the stream, resuming
+// after transient failures (see core.Connection.ExportResumable). wrap is applied
+// to the formatter of each batch. Returns the number of exported rows.
+func exportResumable(ctx context.Context, c *core.Connection, query string, opts *core.ResumeOptions, w io.Writer, stream *s3Stream, wrap func(core.Formatter) core.Formatter) (int, error) {
+	written := 0
+	rows, err := c.ExportResumable(ctx, query, opts, func(header core.Header, rows []core.Row) error {
+		text, err := wrap(stream.formatter()).Format(header, rows, &core.FormatterOptions{
+			SchemaType: core.SchemaFul,
+			ChunkStart: written,
+		})
+		if err != nil {
+			return fmt.Errorf("formatter.Format: %w", err)
+		}
+		written += len(rows)
+
+		_, err = w.Write(stream.chunk(text))
+		return err
+	})
+	if err != nil {
+		return rows, fmt.Errorf("c.ExportResumable: %w", err)
+	}
+
+	if _, err := w.Write(stream.end()); err != nil {
+		return rows, err
+	}
+
+	return rows, nil
+}
+
+// exportResumableFile exports rows of the query to the file at path (overwritten if
+// it exists). The format is csv, tsv or json by the extension of the file (csv by default).
+func exportResumableFile(ctx context.Context, c *core.Connection, query, path string, opts *core.ResumeOptions, wrap func(core.Formatter) core.Formatter) (int, error) {
+	stream, err := newS3Stream(strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		stream, _ = newS3Stream("csv")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	rows, err := exportResumable(ctx, c, query, opts, file, stream, wrap)
+	if err != nil {
+		return rows, err
+	}
+
+	return rows, file.Close()
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+func TestExportResumable(t *testing.T) {
+	r := require.New(t)
+
+	c, err := core.NewConnection(&core.ConnectionParams{}, mock.NewAdapter(mock.NewRows(0, 5),
+		mock.AdapterWithResultStreamOpts(mock.ResultStreamWithHeader(core.Header{"id", "name"})),
+	))
+	r.NoError(err)
+	defer c.Close()
+
+	opts := &core.ResumeOptions{Key: []string{"id"}, CheckpointRows: 2}
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "rows.csv")
+	rows, err := exportResumableFile(context.Background(), c, "SELECT id, name FROM t ORDER BY id", path, opts, noWrap)
+	r.NoError(err)
+	r.Equal(5, rows)
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Equal("id,name\n0,row_0\n1,row_1\n2,row_2\n3,row_3\n4,row_4\n", string(content))
+
+	// batches are joined into a single json array
+	path = filepath.Join(dir, "rows.json")
+	rows, err = exportResumableFile(context.Background(), c, "SELECT id, name FROM t ORDER BY id", path, opts, noWrap)
+	r.NoError(err)
+	r.Equal(5, rows)
+	content, err = os.ReadFile(path)
+	r.NoError(err)
+	var records []map[string]any
+	r.NoError(json.Unmarshal(content, &records))
+	r.Len(records, 5)
+	r.Equal(map[string]any{"id": float64(4), "name": "row_4"}, records[4])
+
+	// s3 objects
+	server, endpoint := newTestS3Server(t)
+	rows, err = uploadS3(context.Background(), "s3://reports/rows.tsv?endpoint="+endpoint, func(w io.Writer, stream *s3Stream) (int, error) {
+		return exportResumable(context.Background(), c, "SELECT id, name FROM t ORDER BY id", opts, w, stream, noWrap)
+	})
+	r.NoError(err)
+	r.Equal(5, rows)
+	r.Equal("id\tname\n0\trow_0\n1\trow_1\n2\trow_2\n3\trow_3\n4\trow_4\n", string(server.objects["/reports/rows.tsv"]))
+
+	_, err = exportResumableFile(context.Background(), c, "SELECT id, name FROM t", filepath.Join(dir, "unordered.csv"), opts, noWrap)
+	r.ErrorContains(err, "query has to order rows of a single table by the key (id)")
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/format"
+)
+
+// s3BatchRows is the number of rows formatted at once while streaming the result.
+const s3BatchRows = 10000
+
+// s3PartSize is the size of uploaded parts. Only the last part of a multipart upload
+// may be smaller than 5 MiB.
+var s3PartSize int64 = 8 << 20
+
+// s3Target is the object results are exported to, parsed from an url like:
+//
+//	s3://bucket/key?region=eu-west-1&format=csv&endpoint=http://localhost:9000
+//
+// Where "region" defaults to the region of the AWS configuration, "format"
+// defaults to the extension of the key (or csv) and "endpoint" is the url of
+// an S3 compatible service.
+type s3Target struct {
+	bucket   string
+	key      string
+	region   string
+	format   string
+	endpoint string
+}
+
+func parseS3URL(raw string) (*s3Target, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 url: %w", err)
+	}
+	if u.Scheme != "s3" {
+		return nil, fmt.Errorf("invalid s3 url: unsupported scheme %q", u.Scheme)
+	}
+
+	t := &s3Target{
+		bucket: u.Host,
+		key:    strings.TrimPrefix(u.Path, "/"),
+	}
+	if t.bucket == "" {
+		return nil, errors.New("invalid s3 url: no bucket provided")
+	}
+	if t.key == "" || strings.HasSuffix(t.key, "/") {
+		return nil, errors.New("invalid s3 url: no object key provided")
+	}
+
+	for param, values := range u.Query() {
+		value := values[len(values)-1]
+		switch param {
+		case "region":
+			t.region = value
+		case "format":
+			t.format = value
+		case "endpoint":
+			t.endpoint = value
+		default:
+			return nil, fmt.Errorf("invalid s3 url: unknown parameter %q", param)
+		}
+	}
+
+	if t.format == "" {
+		t.format = strings.TrimPrefix(filepath.Ext(t.key), ".")
+		if _, err := newS3Stream(t.format); err != nil {
+			t.format = "csv"
+		}
+	}
+
+	return t, nil
+}
+
+// s3Stream formats the result in batches whose outputs are joined into a single document.
+type s3Stream struct {
+	fmat    string
+	batches int
+	// a json array was opened by the first batch
+	open bool
+}
+
+func newS3Stream(fmat string) (*s3Stream, error) {
+	switch fmat {
+	case "csv", "tsv", "json":
+		return &s3Stream{fmat: fmat}, nil
+	}
+	return nil, fmt.Errorf("s3 export: format %q is not supported", fmat)
+}
+
+// formatter returns the formatter of the next batch.
+func (s *s3Stream) formatter() core.Formatter {
+	switch s.fmat {
+	case "csv":
+		if s.batches > 0 {
+			return format.NewCSV().WithoutHeader()
+		}
+		return format.NewCSV()
+	case "tsv":
+		if s.batches > 0 {
+			return format.NewTSV().WithoutHeader()
+		}
+		return format.NewTSV()
+	}
+	return format.NewJSON()
+}
+
+// chunk returns the part of the output of the batch which is written.
+func (s *s3Stream) chunk(text []byte) []byte {
+	defer func() { s.batches++ }()
+
+	if s.fmat != "json" || !bytes.HasPrefix(text, []byte("[")) {
+		return text
+	}
+	s.open = true
+
+	// arrays are joined by dropping their brackets: "[\n  a\n]" + "[\n  b\n]" -> "[\n  a,\n  b\n]"
+	text = bytes.TrimSuffix(text, []byte("\n]"))
+	if s.batches > 0 {
+		return append([]byte(","), text[1:]...)
+	}
+	return text
+}
+
+// end returns the remainder of the output after the last batch.
+func (s *s3Stream) end() []byte {
+	if s.open {
+		return []byte("\n]")
+	}
+	return nil
+}
+
+// rowCounter counts the rows formatted by the wrapped formatter.
+type rowCounter struct {
+	core.Formatter
+	rows int
+}
+
+func (c *rowCounter) Format(header core.Header, rows []core.Row, opts *core.FormatterOptions) ([]byte, error) {
+	c.rows = len(rows)
+	return c.Formatter.Format(header, rows, opts)
+}
+
+// writeStream writes the result to w batch by batch, so the whole output is never
+// held in memory. wrap is applied to the formatter of each batch. Returns the
+// number of written rows.
+func writeStream(w io.Writer, res *core.Result, stream *s3Stream, wrap func(core.Formatter) core.Formatter) (int, error) {
+	written := 0
+	for {
+		counter := &rowCounter{Formatter: stream.formatter()}
+		text, err := res.Format(wrap(counter), written, written+s3BatchRows)
+		if err != nil {
+			return written, fmt.Errorf("res.Format: %w", err)
+		}
+		// an empty result is still written (e.g. csv header)
+		if counter.rows < 1 && written > 0 {
+			break
+		}
+
+		if _, err := w.Write(stream.chunk(text)); err != nil {
+			return written, err
+		}
+
+		written += counter.rows
+		if counter.rows < s3BatchRows {
+			break
+		}
+	}
+
+	if _, err := w.Write(stream.end()); err != nil {
+		return written, err
+	}
+
+	return written, nil
+}
+
+// newS3Uploader creates an uploader with the default AWS configuration (environment,
+// shared config and credentials files with profiles, SSO, assume role and instance
+// metadata credentials). Region and endpoint of the target override the configuration.
+func newS3Uploader(ctx context.Context, t *s3Target) (*manager.Uploader, error) {
+	var opts []func(*config.LoadOptions) error
+	if t.region != "" {
+		opts = append(opts, config.WithRegion(t.region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if t.endpoint != "" {
+			o.BaseEndpoint = aws.String(t.endpoint)
+			// custom endpoints rarely support virtual hosts
+			o.UsePathStyle = true
+		}
+	})
+
+	return manager.NewUploader(client, func(u *manager.Uploader) {
+		u.PartSize = s3PartSize
+	}), nil
+}
+
+// exportS3 streams the formatted result to the object of the s3 url. wrap is applied
+// to the formatter of each batch. Returns the number of exported rows.
+func exportS3(ctx context.Context, res *core.Result, rawURL string, wrap func(core.Formatter) core.Formatter) (int, error) {
+	return uploadS3(ctx, rawURL, func(w io.Writer, stream *s3Stream) (int, error) {
+		return writeStream(w, res, stream, wrap)
+	})
+}
+
+// uploadS3 uploads the output of write (in the format of the url) to the object
+// of the s3 url. Output is sent in parts of a multipart upload as soon as a part fills
+// up, objects smaller than a single part are uploaded with a single request. The upload
+// is aborted if write fails. Returns the number of rows reported by write.
+func uploadS3(ctx context.Context, rawURL string, write func(w io.Writer, stream *s3Stream) (int, error)) (int, error) {
+	target, err := parseS3URL(rawURL)
+	if err != nil {
+		return 0, err
+	}
+	stream, err := newS3Stream(target.format)
+	if err != nil {
+		return 0, err
+	}
+	uploader, err := newS3Uploader(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+
+	pr, pw := io.Pipe()
+
+	type written struct {
+		rows int
+		err  error
+	}
+	done := make(chan written, 1)
+	go func() {
+		rows, err := write(pw, stream)
+		// a failed write fails the upload, which aborts it
+		pw.CloseWithError(err)
+		done <- written{rows: rows, err: err}
+	}()
+
+	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(target.bucket),
+		Key:    aws.String(target.key),
+		Body:   pr,
+	})
+	// unblocks write if the upload failed before reading everything
+	pr.CloseWithError(err)
+
+	w := <-done
+	if w.err != nil {
+		err = w.err
+	}
+	if err != nil {
+		return w.rows, fmt.Errorf("upload to s3://%s/%s: %w", target.bucket, target.key, err)
+	}
+
+	return w.rows, nil
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+// fakeS3Server emulates object uploads of S3 (path style).
+type fakeS3Server struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	parts    map[int][]byte
+	requests []string
+	aborted  bool
+	failPart int
+}
+
+func (s *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// requests are signed with the credentials of the environment
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key-id/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.requests = append(s.requests, "create")
+		s.parts = make(map[int][]byte)
+		_, _ = w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		s.requests = append(s.requests, "part")
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		if number == s.failPart {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+			return
+		}
+		s.parts[number] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.requests = append(s.requests, "complete")
+		var complete struct {
+			Parts []struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+			} `xml:"Part"`
+		}
+		_ = xml.Unmarshal(body, &complete)
+		var object []byte
+		for _, p := range complete.Parts {
+			object = append(object, s.parts[p.PartNumber]...)
+		}
+		s.objects[r.URL.Path] = object
+		_, _ = w.Write([]byte(`<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		s.requests = append(s.requests, "abort")
+		s.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.requests = append(s.requests, "put")
+		if strings.HasPrefix(r.URL.Path, "/missing/") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`))
+			return
+		}
+		s.objects[r.URL.Path] = body
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestS3Server(t *testing.T) (*fakeS3Server, string) {
+	// keep the configuration of the machine out of the tests
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "key-id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	server := &fakeS3Server{objects: make(map[string][]byte)}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	return server, url.QueryEscape(srv.URL)
+}
+
+func TestExportS3(t *testing.T) {
+	r := require.New(t)
+
+	server, endpoint := newTestS3Server(t)
+
+	// small results are uploaded with a single request
+	rows, err := exportS3(context.Background(), newTestResult(t, mock.NewRows(0, 2)), "s3://reports/daily/orders.csv?endpoint="+endpoint, noWrap)
+	r.NoError(err)
+	r.Equal(2, rows)
+	r.Equal([]string{"put"}, server.requests)
+	r.Equal("id,name\n0,row_0\n1,row_1\n", string(server.objects["/reports/daily/orders.csv"]))
+
+	// large results are formatted in batches and uploaded in parts
+	defer func(size int64) { s3PartSize = size }(s3PartSize)
+	s3PartSize = manager.MinUploadPartSize
+	server.requests = nil
+
+	rows, err = exportS3(context.Background(), newTestResult(t, mock.NewRows(0, 200000)), "s3://reports/orders?format=json&region=eu-west-1&endpoint="+endpoint, noWrap)
+	r.NoError(err)
+	r.Equal(200000, rows)
+	r.Equal("create", server.requests[0])
+	r.Equal("complete", server.requests[len(server.requests)-1])
+	r.Greater(len(server.parts), 1)
+
+	var records []map[string]any
+	r.NoError(json.Unmarshal(server.objects["/reports/orders"], &records))
+	r.Len(records, 200000)
+	r.Equal(map[string]any{"id": float64(199999), "name": "row_199999"}, records[199999])
+
+	server.requests = nil
+	rows, err = exportS3(context.Background(), newTestResult(t, mock.NewRows(0, 25000)), "s3://reports/orders.tsv?endpoint="+endpoint, noWrap)
+	r.NoError(err)
+	r.Equal(25000, rows)
+	lines := strings.Split(strings.TrimSuffix(string(server.objects["/reports/orders.tsv"]), "\n"), "\n")
+	r.Len(lines, 25001)
+	r.Equal("id\tname", lines[0])
+	r.Equal("10000\trow_10000", lines[10001])
+}
+
+func TestExportS3_Errors(t *testing.T) {
+	r := require.New(t)
+
+	server, endpoint := newTestS3Server(t)
+
+	_, err := exportS3(context.Background(), newTestResult(t, mock.NewRows(0, 2)), "s3://missing/orders.csv?endpoint="+endpoint, noWrap)
+	r.ErrorContains(err, "upload to s3://missing/orders.csv: ")
+	r.ErrorContains(err, "NoSuchBucket")
+
+	// failed multipart uploads are aborted
+	defer func(size int64) { s3PartSize = size }(s3PartSize)
+	s3PartSize = manager.MinUploadPartSize
+	server.failPart = 2
+
+	_, err = exportS3(context.Background(), newTestResult(t, mock.NewRows(0, 200000)), "s3://reports/orders.json?endpoint="+endpoint, noWrap)
+	r.ErrorContains(err, "AccessDenied")
+	r.True(server.aborted)
+
+	// so are uploads of failed writes
+	server.aborted = false
+	server.failPart = 0
+	_, err = uploadS3(context.Background(), "s3://reports/broken.csv?endpoint="+endpoint, func(w io.Writer, _ *s3Stream) (int, error) {
+		if _, err := w.Write(bytes.Repeat([]byte("x"), int(s3PartSize)+1)); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("query failed")
+	})
+	r.EqualError(err, "upload to s3://reports/broken.csv: query failed")
+	r.True(server.aborted)
+	r.NotContains(server.objects, "/reports/broken.csv")
+
+	_, err = exportS3(context.Background(), newTestResult(t, nil), "s3://reports/orders.parquet?format=parquet", noWrap)
+	r.EqualError(err, `s3 export: format "parquet" is not supported`)
+
+	_, err = exportS3(context.Background(), newTestResult(t, nil), "s3://reports/", noWrap)
+	r.EqualError(err, "invalid s3 url: no object key provided")
+
+	_, err = exportS3(context.Background(), newTestResult(t, nil), "s3://reports/orders.csv?acl=public", noWrap)
+	r.EqualError(err, `invalid s3 url: unknown parameter "acl"`)
+}
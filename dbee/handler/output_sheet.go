@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// sheetBatchRows is the number of rows written by a single values.append request,
+// which keeps request payloads well below the recommended 2 MB.
+const sheetBatchRows = 1000
+
+// sheetMaxRetries is the number of retries of rate limited (or otherwise failed
+// with a server error) requests.
+const sheetMaxRetries = 5
+
+// sheetRetryDelay is the delay before the first retry. It's doubled on every next one.
+var sheetRetryDelay = 2 * time.Second
+
+// newSheetsService creates a Sheets API client. Credentials are a path to a (service
+// account) credentials file, contents of one, or empty for application default credentials.
+func newSheetsService(ctx context.Context, credentials string) (*sheets.Service, error) {
+	var (
+		creds *google.Credentials
+		err   error
+	)
+
+	switch {
+	case credentials == "":
+		creds, err = google.FindDefaultCredentials(ctx, sheets.SpreadsheetsScope)
+	case strings.HasPrefix(strings.TrimSpace(credentials), "{"):
+		creds, err = google.CredentialsFromJSON(ctx, []byte(credentials), sheets.SpreadsheetsScope)
+	default:
+		var data []byte
+		data, err = os.ReadFile(credentials)
+		if err != nil {
+			return nil, errors.New("unable to read credentials file")
+		}
+		creds, err = google.CredentialsFromJSON(ctx, data, sheets.SpreadsheetsScope)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials: %w", maskCredentials(err, credentials))
+	}
+
+	svc, err := sheets.NewService(ctx, option.WithCredentials(creds), option.WithTelemetryDisabled())
+	if err != nil {
+		return nil, fmt.Errorf("sheets.NewService: %w", maskCredentials(err, credentials))
+	}
+
+	return svc, nil
+}
+
+// maskCredentials hides credentials which could be a part of the error message,
+// so they don't end up in logs or notifications.
+func maskCredentials(err error, credentials string) error {
+	if err == nil || credentials == "" || !strings.Contains(err.Error(), credentials) {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), credentials, "<credentials>"))
+}
+
+// sheetWriter writes results to a tab of a spreadsheet.
+type sheetWriter struct {
+	svc           *sheets.Service
+	spreadsheetID string
+	sheet         string
+}
+
+// write replaces contents of the tab (creating it if needed) with the header and rows
+// of the result. Rows are sent in batches, so large results are written page by page.
+// wrap is applied to the formatter of each page. Returns the number of written rows.
+func (w *sheetWriter) write(ctx context.Context, res *core.Result, wrap func(core.Formatter) core.Formatter) (int, error) {
+	if w.sheet == "" {
+		return 0, errors.New("no sheet name provided")
+	}
+
+	if err := w.prepare(ctx); err != nil {
+		return 0, err
+	}
+
+	rng := sheetRange(w.sheet)
+	written := 0
+	for {
+		page := &sheetValues{header: written == 0}
+		_, err := res.Format(wrap(page), written, written+sheetBatchRows)
+		if err != nil {
+			return written, fmt.Errorf("res.Format: %w", err)
+		}
+		if len(page.values) < 1 {
+			return written, nil
+		}
+
+		err = w.retry(ctx, func() error {
+			_, err := w.svc.Spreadsheets.Values.
+				Append(w.spreadsheetID, rng, &sheets.ValueRange{Values: page.values}).
+				ValueInputOption("RAW").
+				InsertDataOption("INSERT_ROWS").
+				Context(ctx).
+				Do()
+			return err
+		})
+		if err != nil {
+			return written, fmt.Errorf("values.Append: %w", err)
+		}
+
+		rows := len(page.values)
+		if page.header {
+			rows--
+		}
+		written += rows
+		if rows < sheetBatchRows {
+			return written, nil
+		}
+	}
+}
+
+// prepare clears the tab if it exists or creates it otherwise.
+func (w *sheetWriter) prepare(ctx context.Context) error {
+	var spreadsheet *sheets.Spreadsheet
+	err := w.retry(ctx, func() error {
+		var err error
+		spreadsheet, err = w.svc.Spreadsheets.Get(w.spreadsheetID).
+			Fields("sheets.properties.title").
+			Context(ctx).
+			Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("spreadsheets.Get: %w", err)
+	}
+
+	for _, s := range spreadsheet.Sheets {
+		if s.Properties == nil || s.Properties.Title != w.sheet {
+			continue
+		}
+
+		err := w.retry(ctx, func() error {
+			_, err := w.svc.Spreadsheets.Values.
+				Clear(w.spreadsheetID, sheetRange(w.sheet), &sheets.ClearValuesRequest{}).
+				Context(ctx).
+				Do()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("values.Clear: %w", err)
+		}
+		return nil
+	}
+
+	err = w.retry(ctx, func() error {
+		_, err := w.svc.Spreadsheets.BatchUpdate(w.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{
+				{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: w.sheet}}},
+			},
+		}).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("spreadsheets.BatchUpdate: %w", err)
+	}
+
+	return nil
+}
+
+// retry calls the request until it succeeds, fails with a non retryable
+// error or runs out of retries.
+func (w *sheetWriter) retry(ctx context.Context, request func() error) error {
+	delay := sheetRetryDelay
+	for i := 0; ; i++ {
+		err := request()
+		if err == nil || i >= sheetMaxRetries || !isRetryableSheetError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isRetryableSheetError reports whether the request was rate limited or failed on the server.
+func isRetryableSheetError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == 429 || apiErr.Code >= 500
+}
+
+// sheetRange returns the A1 notation of the whole tab.
+func sheetRange(sheet string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+}
+
+var _ core.Formatter = (*sheetValues)(nil)
+
+// sheetValues is a formatter which collects rows as cell values,
+// instead of encoding them.
+type sheetValues struct {
+	// whether to prepend the header
+	header bool
+	values [][]any
+}
+
+func (sv *sheetValues) Format(header core.Header, rows []core.Row, _ *core.FormatterOptions) ([]byte, error) {
+	sv.values = make([][]any, 0, len(rows)+1)
+	if sv.header {
+		cells := make([]any, len(header))
+		for i, name := range header {
+			cells[i] = name
+		}
+		sv.values = append(sv.values, cells)
+	}
+
+	for _, row := range rows {
+		cells := make([]any, len(row))
+		for i, v := range row {
+			cells[i] = sheetCell(v)
+		}
+		sv.values = append(sv.values, cells)
+	}
+
+	return nil, nil
+}
+
+// sheetCell converts the value to a cell value. Numbers and booleans are kept, so they
+// can be used in formulas, everything else is written as text.
+func sheetCell(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case bool, int8, int16, int32, uint8, uint16, uint32:
+		return val
+	// NaN and infinity can't be encoded as json numbers
+	case float32:
+		if math.IsNaN(float64(val)) || math.IsInf(float64(val), 0) {
+			return fmt.Sprint(val)
+		}
+		return val
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return fmt.Sprint(val)
+		}
+		return val
+	// cells are floating point numbers, so large integers would lose precision
+	case int:
+		return sheetInt(int64(val))
+	case int64:
+		return sheetInt(val)
+	case uint64:
+		if val > 1<<53 {
+			return fmt.Sprint(val)
+		}
+		return val
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	}
+	return fmt.Sprint(v)
+}
+
+func sheetInt(v int64) any {
+	if v > 1<<53 || v < -(1<<53) {
+		return fmt.Sprint(v)
+	}
+	return v
+}
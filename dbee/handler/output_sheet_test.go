@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+	"github.com/kndndrj/nvim-dbee/dbee/core/mock"
+)
+
+// fakeSheetsServer emulates the parts of Sheets API used by sheetWriter.
+type fakeSheetsServer struct {
+	mu        sync.Mutex
+	tabs      []string
+	cleared   []string
+	appends   []int
+	rows      [][]any
+	rateLimit int
+}
+
+func (s *fakeSheetsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/sheet-id")
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		sheetList := make([]*sheets.Sheet, len(s.tabs))
+		for i, title := range s.tabs {
+			sheetList[i] = &sheets.Sheet{Properties: &sheets.SheetProperties{Title: title}}
+		}
+		_ = json.NewEncoder(w).Encode(&sheets.Spreadsheet{Sheets: sheetList})
+	case path == ":batchUpdate":
+		var req sheets.BatchUpdateSpreadsheetRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.tabs = append(s.tabs, req.Requests[0].AddSheet.Properties.Title)
+		_, _ = w.Write([]byte("{}"))
+	case strings.HasSuffix(path, ":clear"):
+		s.cleared = append(s.cleared, strings.TrimSuffix(strings.TrimPrefix(path, "/values/"), ":clear"))
+		_, _ = w.Write([]byte("{}"))
+	case strings.HasSuffix(path, ":append"):
+		if s.rateLimit > 0 {
+			s.rateLimit--
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"code":429,"message":"quota exceeded"}}`))
+			return
+		}
+		var req sheets.ValueRange
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.appends = append(s.appends, len(req.Values))
+		s.rows = append(s.rows, req.Values...)
+		_, _ = w.Write([]byte("{}"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestSheetWriter(t *testing.T, server *fakeSheetsServer, sheet string) *sheetWriter {
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	svc, err := sheets.NewService(context.Background(),
+		option.WithEndpoint(srv.URL+"/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	return &sheetWriter{svc: svc, spreadsheetID: "sheet-id", sheet: sheet}
+}
+
+func newTestResult(t *testing.T, rows []core.Row) *core.Result {
+	res := core.NewResult(0)
+	require.NoError(t, res.SetIter(mock.NewResultStream(rows, mock.ResultStreamWithHeader(core.Header{"id", "name"})), nil))
+	return res
+}
+
+func noWrap(f core.Formatter) core.Formatter { return f }
+
+func TestSheetWriter(t *testing.T) {
+	r := require.New(t)
+
+	sheetRetryDelay = time.Millisecond
+	server := &fakeSheetsServer{tabs: []string{"Sheet1"}, rateLimit: 2}
+	w := newTestSheetWriter(t, server, "Export")
+
+	// new tab is created and rows are appended in batches
+	written, err := w.write(context.Background(), newTestResult(t, mock.NewRows(0, 2500)), noWrap)
+	r.NoError(err)
+	r.Equal(2500, written)
+	r.Equal([]string{"Sheet1", "Export"}, server.tabs)
+	r.Empty(server.cleared)
+	r.Equal([]int{1001, 1000, 500}, server.appends)
+	r.Equal([]any{"id", "name"}, server.rows[0])
+	r.Equal([]any{float64(2499), "row_2499"}, server.rows[2500])
+
+	// existing tab is cleared
+	server.appends, server.rows = nil, nil
+	written, err = w.write(context.Background(), newTestResult(t, nil), noWrap)
+	r.NoError(err)
+	r.Zero(written)
+	r.Equal([]string{"Sheet1", "Export"}, server.tabs)
+	r.Equal([]string{"'Export'"}, server.cleared)
+	r.Equal([][]any{{"id", "name"}}, server.rows)
+}
+
+func TestSheetWriter_RetryLimit(t *testing.T) {
+	r := require.New(t)
+
+	sheetRetryDelay = time.Millisecond
+	server := &fakeSheetsServer{rateLimit: sheetMaxRetries + 1}
+	w := newTestSheetWriter(t, server, "Export")
+
+	_, err := w.write(context.Background(), newTestResult(t, mock.NewRows(0, 10)), noWrap)
+	r.ErrorContains(err, "quota exceeded")
+	r.Empty(server.rows)
+}
+
+func TestSheetCell(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("", sheetCell(nil))
+	r.Equal(int64(42), sheetCell(int64(42)))
+	r.Equal("9007199254740993", sheetCell(int64(9007199254740993)))
+	r.Equal("NaN", sheetCell(math.NaN()))
+	r.Equal("abc", sheetCell([]byte("abc")))
+	r.Equal(true, sheetCell(true))
+	r.Equal("2024-01-02 00:00:00 +0000 UTC", sheetCell(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestMaskCredentials(t *testing.T) {
+	r := require.New(t)
+
+	creds := `{"private_key":"secret"}`
+	err := maskCredentials(errors.New("bad credentials: "+creds), creds)
+	r.Equal("bad credentials: <credentials>", err.Error())
+}
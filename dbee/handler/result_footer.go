@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// resultFooter describes the displayed page of a result.
+type resultFooter struct {
+	// displayed range (from inclusive, to exclusive)
+	from int
+	to   int
+	// number of retrieved rows
+	total int
+	// row limit added to the query (0 if none)
+	limit int
+	// more rows are being retrieved
+	streaming bool
+	elapsed   time.Duration
+	// server which ran the query (only set for connections with replicas)
+	endpoint string
+}
+
+func (f *resultFooter) String() string {
+	parts := []string{}
+
+	to := min(f.to, f.total)
+	switch {
+	case f.total == 0 || f.from >= to:
+		parts = append(parts, fmt.Sprintf("%d rows", f.total))
+	default:
+		parts = append(parts, fmt.Sprintf("Rows %d-%d of %d", f.from+1, to, f.total))
+	}
+
+	switch {
+	case f.streaming:
+		parts[0] += " so far"
+		parts = append(parts, "streaming…")
+	case f.limit > 0 && f.total >= f.limit:
+		parts = append(parts, fmt.Sprintf("truncated by the row limit of %d, more rows may exist", f.limit))
+	}
+
+	if f.endpoint != "" {
+		parts = append(parts, "via "+f.endpoint)
+	}
+
+	parts = append(parts, fmt.Sprintf("%.3fs", f.elapsed.Seconds()))
+
+	return strings.Join(parts, " · ")
+}
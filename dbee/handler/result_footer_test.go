@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultFooter(t *testing.T) {
+	type testCase struct {
+		name     string
+		footer   resultFooter
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "complete",
+			footer:   resultFooter{from: 100, to: 200, total: 150, elapsed: 1234 * time.Millisecond},
+			expected: "Rows 101-150 of 150 · 1.234s",
+		},
+		{
+			name:     "streaming",
+			footer:   resultFooter{from: 0, to: 100, total: 300, limit: 500, streaming: true, elapsed: 2 * time.Second},
+			expected: "Rows 1-100 of 300 so far · streaming… · 2.000s",
+		},
+		{
+			name:     "truncated",
+			footer:   resultFooter{from: 0, to: 100, total: 500, limit: 500},
+			expected: "Rows 1-100 of 500 · truncated by the row limit of 500, more rows may exist · 0.000s",
+		},
+		{
+			name:     "under limit",
+			footer:   resultFooter{from: 0, to: 100, total: 20, limit: 500},
+			expected: "Rows 1-20 of 20 · 0.000s",
+		},
+		{
+			name:     "replica",
+			footer:   resultFooter{from: 0, to: 100, total: 3, endpoint: "replica-1:5432"},
+			expected: "Rows 1-3 of 3 · via replica-1:5432 · 0.000s",
+		},
+		{
+			name:     "empty",
+			footer:   resultFooter{from: 0, to: 100, elapsed: 5 * time.Millisecond},
+			expected: "0 rows · 0.005s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.footer.String())
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kndndrj/nvim-dbee/dbee/core"
+)
+
+// structureLoad is a structure load in progress.
+type structureLoad struct {
+	cancel context.CancelFunc
+}
+
+// structureLoads holds structure loads in progress. Loads finish in their
+// own goroutines, so access is guarded by a mutex.
+type structureLoads struct {
+	mu     sync.Mutex
+	lookup map[core.ConnectionID]*structureLoad
+}
+
+// add registers the load of the connection, cancelling the previous one.
+func (sl *structureLoads) add(connID core.ConnectionID, l *structureLoad) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	if prev, ok := sl.lookup[connID]; ok {
+		prev.cancel()
+	}
+	sl.lookup[connID] = l
+}
+
+// remove removes the load if it's still registered under the connection.
+// It reports false if the load was cancelled or replaced in the meantime.
+func (sl *structureLoads) remove(connID core.ConnectionID, l *structureLoad) bool {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	if sl.lookup[connID] != l {
+		return false
+	}
+	delete(sl.lookup, connID)
+	return true
+}
+
+// stop cancels loads of connections matching the filter.
+func (sl *structureLoads) stop(match func(core.ConnectionID) bool) int {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	stopped := 0
+	for connID, l := range sl.lookup {
+		if match(connID) {
+			l.cancel()
+			delete(sl.lookup, connID)
+			stopped++
+		}
+	}
+
+	return stopped
+}
+
+// ConnectionLoadStructure loads the structure of the connection in the background
+// and triggers the "structure_loaded" event once it's done. A load that is still in
+// progress for the same connection is cancelled. Cancelled loads don't trigger the event.
+func (h *Handler) ConnectionLoadStructure(connID core.ConnectionID) error {
+	c, ok := h.lookupConnection[connID]
+	if !ok {
+		return fmt.Errorf("unknown connection with id: %q", connID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &structureLoad{cancel: cancel}
+	h.structureLoads.add(connID, l)
+
+	go func() {
+		defer cancel()
+
+		structure, err := c.GetStructureCtx(ctx)
+		if !h.structureLoads.remove(connID, l) {
+			return
+		}
+		if err != nil {
+			err = fmt.Errorf("c.GetStructureCtx: %w", err)
+		}
+		h.events.StructureLoaded(connID, structure, err)
+	}()
+
+	return nil
+}
+
+// ConnectionCancelStructure cancels the structure load of the connection.
+func (h *Handler) ConnectionCancelStructure(connID core.ConnectionID) error {
+	n := h.structureLoads.stop(func(id core.ConnectionID) bool { return id == connID })
+	if n < 1 {
+		return fmt.Errorf("no structure load in progress for connection: %q", connID)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 local install = require("dbee.install")
 local api = require("dbee.api")
 local config = require("dbee.config")
+local utils = require("dbee.utils")
 
 ---@toc dbee.ref.contents
 
@@ -76,9 +77,77 @@ function dbee.execute(query)
   dbee.open()
 end
 
+---Query local Parquet, CSV or JSON files with the built-in analytics connection
+---(an in-memory DuckDB database), e.g. results stored to a file.
+---The analytics connection becomes the current one, so the files can be queried
+---further with DuckDB functions (e.g. `SELECT count(*) FROM read_parquet('...')`).
+---@param path string path or glob of the files (e.g. "data/*.parquet")
+function dbee.analyze(path)
+  local conn_id = api.core.get_analytics_connection_id()
+  local query = api.core.analyze_file_query(vim.fn.fnamemodify(path, ":p"))
+
+  api.core.set_current_connection(conn_id)
+  local call = api.core.connection_execute(conn_id, query)
+  api.ui.result_set_call(call)
+
+  dbee.open()
+end
+
+---Run a query joining tables of different connections (referenced as
+---"<connection>.<schema>.<table>") on the analytics connection and show its result
+---(see `api.core.federate`).
+---@param query string
+function dbee.federate(query)
+  local call = api.core.federate(query)
+  api.ui.result_set_call(call)
+
+  dbee.open()
+end
+
+---Compare the result of a query on current connection with a snapshot file
+---and report whether it matches (see `api.core.connection_assert_result`).
+---@param query string
+---@param path string path of the snapshot file
+---@param opts? { unordered: boolean, update: boolean }
+---@return SnapshotDiff
+function dbee.assert_result(query, path, opts)
+  local conn = api.core.get_current_connection()
+  if not conn then
+    error("no connection currently selected")
+  end
+
+  local diff = api.core.connection_assert_result(conn.id, query, vim.fn.fnamemodify(path, ":p"), opts)
+  if diff.updated then
+    utils.log("info", "snapshot updated: " .. path, "assert")
+  elseif diff.match then
+    utils.log("info", "result matches snapshot: " .. path, "assert")
+  elseif diff.expected_columns then
+    utils.log(
+      "error",
+      string.format(
+        "columns differ: expected [%s], got [%s]",
+        table.concat(diff.expected_columns, ", "),
+        table.concat(diff.actual_columns, ", ")
+      ),
+      "assert"
+    )
+  else
+    local lines = {}
+    for _, r in ipairs(diff.missing) do
+      table.insert(lines, string.format("- %d: %s", r.row, r.values))
+    end
+    for _, r in ipairs(diff.unexpected) do
+      table.insert(lines, string.format("+ %d: %s", r.row, r.values))
+    end
+    utils.log("error", "result doesn't match snapshot:\n" .. table.concat(lines, "\n"), "assert")
+  end
+
+  return diff
+end
+
 ---Store currently displayed result.
 ---Convenience wrapper around some api functions.
----@param format string format of the output -> "csv"|"json"|"table"
+---@param format string format of the output -> "csv"|"tsv"|"markdown"|"json"|"yaml"|"table"
 ---@param output string where to pipe the results -> "file"|"yank"|"buffer"
 ---@param opts { from: integer, to: integer, extra_arg: any }
 function dbee.store(format, output, opts)
@@ -90,6 +159,64 @@ function dbee.store(format, output, opts)
   api.core.call_store_result(call.id, format, output, opts)
 end
 
+---Yank currently displayed result to a register.
+---At most `result.yank_max_rows` (see config) rows are yanked.
+---@param format string format of the output -> "csv"|"tsv"|"markdown"|"json"|"yaml"|"table"
+---@param register? string register to yank to (e.g. "+" for the clipboard)
+function dbee.yank(format, register)
+  local call = api.ui.result_get_call()
+  if not call then
+    error("no current call to yank")
+  end
+
+  api.core.call_yank_result(call.id, format, register, { max_rows = api.current_config().result.yank_max_rows })
+end
+
+---Listen for notifications on a channel of the current connection.
+---Notifications are displayed in a new split as they arrive.
+---@param channel string
+function dbee.listen(channel)
+  local conn = api.core.get_current_connection()
+  if not conn then
+    error("no connection currently selected")
+  end
+
+  local bufnr = vim.api.nvim_create_buf(false, true)
+  vim.api.nvim_buf_set_name(bufnr, "dbee-listen://" .. conn.id .. "/" .. channel)
+  vim.api.nvim_buf_set_lines(bufnr, 0, -1, true, { "-- listening on " .. channel })
+  vim.api.nvim_buf_set_option(bufnr, "bufhidden", "wipe")
+  vim.api.nvim_buf_set_option(bufnr, "modifiable", false)
+
+  local ok, err = pcall(api.core.connection_listen, conn.id, channel, bufnr)
+  if not ok then
+    vim.api.nvim_buf_delete(bufnr, { force = true })
+    error(err)
+  end
+
+  -- stop listening once the buffer is gone (closing the window wipes it)
+  vim.api.nvim_create_autocmd("BufWipeout", {
+    buffer = bufnr,
+    once = true,
+    callback = function()
+      pcall(api.core.connection_unlisten, conn.id, channel)
+    end,
+  })
+
+  vim.cmd("botright split")
+  vim.api.nvim_win_set_buf(0, bufnr)
+end
+
+---Stop listening for notifications on a channel of the current connection.
+---@param channel string
+function dbee.unlisten(channel)
+  local conn = api.core.get_current_connection()
+  if not conn then
+    error("no connection currently selected")
+  end
+
+  api.core.connection_unlisten(conn.id, channel)
+end
+
 ---Supported install commands.
 ---@alias install_command
 ---| '"wget"'
@@ -18,21 +18,71 @@ return function()
   -- Manifest
   vim.fn["remote#host#RegisterPlugin"]("nvim_dbee", "0", {
     { type = "function", name = "DbeeAddHelpers", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeAnalyzeFileQuery", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCallCancel", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallChartData", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCallDisplayResult", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallExportS3", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallExportSheet", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallHighlightRows", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallPageNext", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallProgress", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallResultFooter", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallSelectColumns", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallSortResult", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCallStoreResult", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeCallYankResult", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeClassifyStatement", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionAssertResult", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionCallProcedure", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionCancelStructure", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionCopyExport", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionCopyImport", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionDescribe", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionExecute", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExecuteAllDatabases", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExecuteJSON", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExecutePage", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExecuteParams", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExplainCost", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExplainPlan", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExportResumable", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionExportSchema", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetActiveQueries", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetCalls", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetColumns", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetGrants", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetHelpers", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetParams", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetProcedureParameters", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetSequences", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionGetStructure", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionGetTableSize", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionImport", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionListBranches", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionListDatabases", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionListProcedures", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionListSchemas", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionListen", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionLoadStructure", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionPeek", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionPreviewAffected", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSelectBranch", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeConnectionSelectDatabase", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSelectSchema", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSetMask", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSetSessionParam", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionSuggestIndexes", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionTopValues", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeConnectionUnlisten", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeCreateConnection", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeDeleteConnection", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeFederate", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeFormatSQL", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeGetConnections", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeGetCurrentConnection", sync = true, opts = vim.empty_dict() },
     { type = "function", name = "DbeeSetCurrentConnection", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeSetInterpolationTokens", sync = true, opts = vim.empty_dict() },
+    { type = "function", name = "DbeeValidateConnection", sync = true, opts = vim.empty_dict() },
   })
 end
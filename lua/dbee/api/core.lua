@@ -9,6 +9,7 @@
 ---@brief ]]
 
 local state = require("dbee.api.state")
+local utils = require("dbee.utils")
 
 local core = {}
 
@@ -52,6 +53,15 @@ function core.source_add_connection(id, details)
   return state.handler():source_add_connection(id, details)
 end
 
+---Check connection params (e.g. url format and required parameters) without connecting,
+---so they can be validated while they are being typed.
+---Only some adapters implement the checks, urls of others are always valid.
+---@param details ConnectionParams
+---@return string? # error message if the params are invalid
+function core.validate_connection(details)
+  return state.handler():validate_connection(details)
+end
+
 ---Remove a connection from the source.
 ---In case the source cannot delete connections, this call fails.
 ---@param id source_id
@@ -101,6 +111,157 @@ function core.connection_get_helpers(id, opts)
   return state.handler():connection_get_helpers(id, opts)
 end
 
+---Pretty-print a SQL query: keywords are uppercased and major clauses start on
+---a new line. Contents of strings and comments are kept as they are.
+---Queries which can't be tokenized (e.g. unterminated strings) are returned unchanged.
+---@param query string
+---@return string formatted
+function core.format_sql(query)
+  return state.handler():format_sql(query)
+end
+
+---Classify the statement of a query (e.g. to confirm destructive statements).
+---Comments are skipped and statements starting with a CTE are classified by the command
+---after it. Multiple statements of different kinds are "unknown", but transaction control
+---around statements of a single kind is ignored ("BEGIN; UPDATE ...; COMMIT;" is "update").
+---@param query string
+---@return statement_kind
+function core.classify_statement(query)
+  return state.handler():classify_statement(query)
+end
+
+---Describe a structure object (table, view, procedure, function or sequence).
+---Description is executed as a regular call, so it can be displayed in result.
+---@param id connection_id
+---@param opts TableOpts
+---@return CallDetails
+function core.connection_describe(id, opts)
+  return state.handler():connection_describe(id, opts)
+end
+
+---List the most common values of a table column with the number of their
+---occurrences ("cnt"). NULL is counted as a value of its own.
+---Executed as a regular call, so it can be displayed in result.
+---@param id connection_id
+---@param opts TableOpts table of the column
+---@param column string
+---@param n? integer number of values (default 10)
+---@return CallDetails
+function core.connection_top_values(id, opts, column, n)
+  return state.handler():connection_top_values(id, opts, column, n or 10)
+end
+
+---Run a query on a connection and load the result into a table of the scratch database,
+---an in-memory SQLite database which is registered as the "scratch" source on first use.
+---Results of different connections imported this way can be joined with plain SQL on the
+---scratch connection. Column types are inferred from the first rows. Existing table with
+---the same name is replaced (so importing again refreshes the data).
+---The scratch database is discarded when its source is reloaded or nvim exits.
+---@param id connection_id source connection
+---@param query string
+---@param table string name of the table in the scratch database
+---@return integer # number of imported rows
+function core.connection_import(id, query, table)
+  return state.handler():connection_import(id, query, table)
+end
+
+---Export rows of a query to a csv file, bypassing the formatting of results, so huge
+---results are exported quickly (and without holding them in memory).
+---Values are written in their text form as sent by the database (postgres only).
+---@param id connection_id
+---@param query string
+---@param path string path of the csv file (overwritten if it exists)
+---@param opts? CopyOpts
+---@return integer # number of exported rows
+function core.connection_copy_export(id, query, path, opts)
+  return state.handler():connection_copy_export(id, query, path, opts)
+end
+
+---Bulk load rows of a csv file into an existing table (with COPY ... FROM STDIN, postgres
+---only). Columns of the file have to be in the order of columns of the table. All rows
+---are loaded in a single transaction, so nothing is loaded if any of them fails.
+---@param id connection_id
+---@param table { table: string, schema?: string }
+---@param path string path of the csv file
+---@param opts? CopyOpts
+---@return integer # number of imported rows
+function core.connection_copy_import(id, table, path, opts)
+  return state.handler():connection_copy_import(id, table, path, opts)
+end
+
+---Export rows of a query to a file or an S3 object (see |core.call_export_s3|) as csv, tsv
+---or json (by the extension), resuming after transient failures (e.g. a dropped connection).
+---Rows are written in batches and the key of the last written row is remembered, so after
+---a failure the query is run again only for the rows after it and the export continues.
+---The query has to order rows of a single table by a unique key (e.g.
+---"SELECT * FROM events ORDER BY id") and the key columns have to be passed in opts.
+---@param id connection_id
+---@param query string
+---@param out string path of the file (overwritten if it exists) or s3 url of the object
+---@param opts ResumeOpts
+---@return integer # number of exported rows
+function core.connection_export_resumable(id, query, out, opts)
+  return state.handler():connection_export_resumable(id, query, out, opts)
+end
+
+---Run a query joining tables of different connections on the analytics connection
+---(see |core.get_analytics_connection_id|). Tables are referenced as
+---"<connection>.<schema>.<table>" by the name of their connection (quote names with
+---spaces, e.g. "My Db".public.users). All rows of the referenced tables are loaded into
+---the analytics database on each run, so filter large tables in their own database first.
+---@param query string
+---@return CallDetails
+function core.federate(query)
+  return state.handler():federate(query)
+end
+
+---Get the id of the scratch database connection (see |core.connection_import|).
+---@return connection_id
+function core.get_scratch_connection_id()
+  return state.handler():scratch_connection_id()
+end
+
+---Get the id of the analytics connection: an in-memory DuckDB database for querying
+---local Parquet, CSV and JSON files (e.g. stored results) without a database server.
+---@return connection_id
+function core.get_analytics_connection_id()
+  return state.handler():analytics_connection_id()
+end
+
+---Get a DuckDB query selecting all rows of local Parquet, CSV or JSON files, to be run
+---on the analytics connection (see |core.get_analytics_connection_id|).
+---The path can be a glob (e.g. "data/*.parquet"), matching files are read as a single table.
+---@param path string
+---@return string
+function core.analyze_file_query(path)
+  return state.handler():analyze_file_query(path)
+end
+
+---List stored procedures and functions of the connection (grouped by schema).
+---@param id connection_id
+---@return DBStructure[]
+function core.connection_list_procedures(id)
+  return state.handler():connection_list_procedures(id)
+end
+
+---Get input parameters of a procedure or function in the order of arguments.
+---@param id connection_id
+---@param opts TableOpts materialization should be either "procedure" or "function"
+---@return Column[]
+function core.connection_get_procedure_parameters(id, opts)
+  return state.handler():connection_get_procedure_parameters(id, opts)
+end
+
+---Call a procedure or function with positional arguments.
+---Procedure is executed as a regular call, so it can be displayed in result.
+---@param id connection_id
+---@param opts TableOpts materialization should be either "procedure" or "function"
+---@param args any[] arguments for input parameters (use vim.NIL for NULL)
+---@return CallDetails
+function core.connection_call_procedure(id, opts, args)
+  return state.handler():connection_call_procedure(id, opts, args)
+end
+
 ---Get the currently active connection.
 ---@return ConnectionParams|nil
 function core.get_current_connection()
@@ -114,11 +275,179 @@ function core.set_current_connection(id)
 end
 
 ---Execute a query on a connection.
+---Query can also reference a file with the query ("file:///path/to/query.sql" or "@/path/to/query.sql"),
+---which is read by the backend. This avoids sending large queries through rpc arguments.
+---@param id connection_id
+---@param query string
+---@param opts? { force: boolean } force runs statements refused by the "require_where" option
+---@return CallDetails
+function core.connection_execute(id, query, opts)
+  return state.handler():connection_execute(id, query, opts)
+end
+
+---Execute a parameterized query on a connection. Values are bound to placeholders
+---by the database driver (instead of being pasted into the query), so they can't
+---alter the statement. Both positional ("$1", "?") and named (":name", "@name")
+---placeholders are supported: a list holds positional values, keys of a table are
+---names or positions, e.g. { [1] = 10, name = "x" } or as json '{"1": 10, "name": "x"}'.
+---Values can be numbers, strings, booleans or vim.NIL.
+---Supported by postgres, redshift, mysql, sqlite, sqlserver, oracle, duckdb and clickhouse.
+---@param id connection_id
+---@param query string
+---@param params table|string values or json encoded values of placeholders
+---@return CallDetails
+function core.connection_execute_params(id, query, params)
+  return state.handler():connection_execute_params(id, query, params)
+end
+
+---Execute the first page of page_size rows of a select statement (see |core.call_page_next|).
+---If the query orders the rows of a single table by a unique key (e.g. "ORDER BY id"),
+---the following pages are selected by the key of the previous page's last row
+---(keyset pagination), which stays fast deep into the result. Otherwise pages are
+---selected by offset. The query must not limit its rows on its own.
+---Supported by connections that can limit queries. Keyset pagination is used with
+---postgres, mysql and sqlite.
+---@param id connection_id
+---@param query string
+---@param page_size integer
+---@return CallDetails
+function core.connection_execute_page(id, query, page_size)
+  return state.handler():connection_execute_page(id, query, page_size)
+end
+
+---Execute a query in every database of a connection (e.g. for auditing all tenants)
+---and union the results. Rows get a "__database" column with the name of their
+---database and columns of different databases are matched by name. Databases where
+---the query fails get a single row with the message in an "__error" column.
+---The current database is selected again afterwards.
+---Supported by connections that can switch databases.
 ---@param id connection_id
 ---@param query string
 ---@return CallDetails
-function core.connection_execute(id, query)
-  return state.handler():connection_execute(id, query)
+function core.connection_execute_all_databases(id, query)
+  return state.handler():connection_execute_all_databases(id, query)
+end
+
+---Execute a query on a connection and wait for the result.
+---Unlike connection_execute, this function blocks until the query finishes
+---and returns all rows as a list of tables keyed by column name, which is
+---handy for scripting. Duplicate column names are suffixed with their column
+---index (e.g. columns "id", "name", "id" become "id", "name", "id_2").
+---Rows are not stored in call history.
+---@param id connection_id
+---@param query string
+---@return table<string, any>[] rows
+function core.connection_execute_json(id, query)
+  return state.handler():connection_execute_json(id, query)
+end
+
+---Run the query and compare its result with the snapshot stored in a json file
+---(e.g. for data tests). Values are compared with their types (1 and "1" differ).
+---If opts.unordered is set, rows are matched regardless of their order.
+---If opts.update is set, the snapshot is (re)written with the result instead.
+---@param id connection_id
+---@param query string
+---@param path string path of the snapshot file
+---@param opts? { unordered: boolean, update: boolean }
+---@return SnapshotDiff
+function core.connection_assert_result(id, query, path, opts)
+  return state.handler():connection_assert_result(id, query, path, opts)
+end
+
+---Get a summary of the query plan (total cost, estimated and actual rows
+---and the most expensive node). Currently only supported by postgres.
+---If analyze is true, the query is actually executed.
+---@param id connection_id
+---@param query string
+---@param analyze? boolean
+---@return ExplainSummary
+function core.connection_explain_cost(id, query, analyze)
+  return state.handler():connection_explain_cost(id, query, analyze)
+end
+
+---Get the query plan as a tree of nodes (operation, cost, rows and child nodes).
+---Currently supported by postgres and mysql (mysql doesn't support analyze).
+---If analyze is true, the query is actually executed.
+---@param id connection_id
+---@param query string
+---@param analyze? boolean
+---@return PlanNode
+function core.connection_explain_plan(id, query, analyze)
+  return state.handler():connection_explain_plan(id, query, analyze)
+end
+
+---Suggest indexes for a query based on its plan. Sequential scans of large tables
+---with selective filters are listed with the filtered columns and a candidate
+---CREATE INDEX statement. The query is only planned, not executed. The suggestions
+---are heuristic and executed as a regular call, so they can be displayed in result.
+---Currently only supported by postgres.
+---@param id connection_id
+---@param query string
+---@return CallDetails
+function core.connection_suggest_indexes(id, query)
+  return state.handler():connection_suggest_indexes(id, query)
+end
+
+---Get the columns a select statement would return, without fetching any rows
+---(e.g. for completion or inline hints). Only supported by sql adapters.
+---@param id connection_id
+---@param query string
+---@return Column[]
+function core.connection_peek(id, query)
+  return state.handler():connection_peek(id, query)
+end
+
+---Get the number of rows a DELETE or UPDATE statement would change, without
+---executing it (dry-run). The statement is rewritten to a count query with the
+---same WHERE clause (e.g. "DELETE FROM t WHERE c" to "SELECT COUNT(*) FROM t WHERE c").
+---Joined tables are supported (postgres USING/FROM, mysql multi-table syntax);
+---for mysql and sql server joins, the rows of the join are counted.
+---@param id connection_id
+---@param query string
+---@return integer count
+function core.connection_preview_affected(id, query)
+  return state.handler():connection_preview_affected(id, query)
+end
+
+---Listen for notifications on a channel (e.g. postgres LISTEN/NOTIFY) using a dedicated
+---connection. Notifications are appended to the buffer as they arrive, one per line,
+---until |core.connection_unlisten| is called or the buffer is deleted.
+---@param id connection_id
+---@param channel string
+---@param bufnr integer
+function core.connection_listen(id, channel, bufnr)
+  state.handler():connection_listen(id, channel, bufnr)
+end
+
+---Stop listening for notifications on a channel.
+---@param id connection_id
+---@param channel string
+function core.connection_unlisten(id, channel)
+  state.handler():connection_unlisten(id, channel)
+end
+
+---Mask values of sensitive columns in all outputs of a connection's results
+---(displayed, stored, yanked and exported), e.g. when sharing the screen.
+---Patterns are column names (case insensitive) or regular expressions enclosed in slashes
+---(e.g. "/^(ssn|phone)$/"). Email addresses are masked partially ("j***@x.com"),
+---other values fully. Style "length" (default) keeps the length of values, "fixed" doesn't.
+---An empty list turns masking off. Results are masked once they are displayed again.
+---@param id connection_id
+---@param patterns string[]
+---@param style? mask_style style of the masks (defaults to the mask_style option)
+function core.connection_set_mask(id, patterns, style)
+  state.handler():connection_set_mask(id, patterns, style)
+end
+
+---Set a session parameter of a connection (e.g. a query tag).
+---The parameter is kept for the rest of the session and applied again
+---if the session is recreated (e.g. after switching databases).
+---Only supported by Snowflake.
+---@param id connection_id
+---@param name string
+---@param value string
+function core.connection_set_session_param(id, name, value)
+  state.handler():connection_set_session_param(id, name, value)
 end
 
 ---Get database structure of a connection.
@@ -128,14 +457,59 @@ function core.connection_get_structure(id)
   return state.handler():connection_get_structure(id)
 end
 
+---Start loading database structure of a connection in the background.
+---Once done, the "structure_loaded" event is triggered with the structure or an error.
+---A load in progress for the same connection is cancelled.
+---Cancelled loads don't trigger the event.
+---@param id connection_id
+function core.connection_load_structure(id)
+  state.handler():connection_load_structure(id)
+end
+
+---Cancel the structure load of a connection started with connection_load_structure.
+---Errors if there is no load in progress.
+---@param id connection_id
+function core.connection_cancel_structure(id)
+  state.handler():connection_cancel_structure(id)
+end
+
 ---Get columns of a table
 ---@param id connection_id
----@param opts { table: string, schema: string, materialization: string }
+---@param opts { table: string, schema: string, materialization: string, parent?: string }
 ---@return Column[]
 function core.connection_get_columns(id, opts)
   return state.handler():connection_get_columns(id, opts)
 end
 
+---Get the storage size of a table (total, data, indexes and overhead such as
+---TOAST), in bytes and human readable. Currently supported by postgres and mysql.
+---@param id connection_id
+---@param opts TableOpts
+---@return TableSize
+function core.connection_get_table_size(id, opts)
+  return state.handler():connection_get_table_size(id, opts)
+end
+
+---Get sequences (and auto increment counters) with their current value and the
+---remaining headroom. If opts are passed, only sequences owned by columns of the
+---table are returned. Currently supported by postgres, mysql and oracle.
+---@param id connection_id
+---@param opts? TableOpts
+---@return Sequence[]
+function core.connection_get_sequences(id, opts)
+  return state.handler():connection_get_sequences(id, opts)
+end
+
+---Get privileges on a table (who has access to it). If opts.effective is set, only
+---privileges of the current user are returned, otherwise grants to all users and roles.
+---Currently supported by postgres and mysql.
+---@param id connection_id
+---@param opts { table: string, schema: string, effective: boolean }
+---@return Grant[]
+function core.connection_get_grants(id, opts)
+  return state.handler():connection_get_grants(id, opts)
+end
+
 ---Get parameters that define the connection.
 ---@param id connection_id
 ---@return ConnectionParams|nil
@@ -162,6 +536,57 @@ function core.connection_select_database(id, database)
   state.handler():connection_select_database(id, database)
 end
 
+---List schemas of the current database of a connection.
+---Connections which don't support switching schemas return an empty string
+---and an empty list.
+---@param id connection_id
+---@return string current (default) schema
+---@return string[] other available schemas
+function core.connection_list_schemas(id)
+  return state.handler():connection_list_schemas(id)
+end
+
+---Select the default schema of a connection (the one unqualified names resolve to,
+---e.g. search_path in postgres), without reconnecting manually.
+---Some databases might not support this - in that case, a call to this
+---function returns an error.
+---@param id connection_id
+---@param schema string
+function core.connection_select_schema(id, schema)
+  state.handler():connection_select_schema(id, schema)
+end
+
+---List branches of the current database of a connection (e.g. dolt).
+---Connections which don't support switching branches return an empty string
+---and an empty list.
+---@param id connection_id
+---@return string current (checked out) branch
+---@return string[] other available branches
+function core.connection_list_branches(id)
+  return state.handler():connection_list_branches(id)
+end
+
+---Check out a branch of the current database of a connection.
+---Some databases might not support this - in that case, a call to this
+---function returns an error.
+---@param id connection_id
+---@param branch string
+function core.connection_select_branch(id, branch)
+  state.handler():connection_select_branch(id, branch)
+end
+
+---Export definitions (DDL) of all tables and views of a connection to a file.
+---Where supported (postgres), schemas, types and sequences come first, followed by tables,
+---views ordered by their dependencies and finally foreign keys and indexes.
+---Otherwise tables are ordered so that referenced tables come before the ones referencing them.
+---Some databases might not support this - in that case, a call to this
+---function returns an error.
+---@param id connection_id
+---@param path string path to the output file
+function core.connection_export_schema(id, path)
+  state.handler():connection_export_schema(id, path)
+end
+
 ---Get a list of past calls of a connection.
 ---@param id connection_id
 ---@return CallDetails[]
@@ -169,6 +594,14 @@ function core.connection_get_calls(id)
   return state.handler():connection_get_calls(id)
 end
 
+---Get a list of queries of a connection which are still running (oldest first).
+---Any of them can be canceled with |core.call_cancel|.
+---@param id connection_id
+---@return CallDetails[]
+function core.connection_get_active_queries(id)
+  return state.handler():connection_get_active_queries(id)
+end
+
 ---Cancel call execution.
 ---If call is finished, nothing happens.
 ---@param id call_id
@@ -176,23 +609,150 @@ function core.call_cancel(id)
   state.handler():call_cancel(id)
 end
 
+---Execute the page after the page of a finished paged call (see |core.connection_execute_page|).
+---The page starts after the provided key values (one for each column of ORDER BY)
+---or after the key of the call's last row if after is not set. Pages are selected by offset
+---if the key is not known (e.g. the key columns are not selected).
+---@param id call_id id of the previous page
+---@param after? any[] key values to start after
+---@return CallDetails
+function core.call_page_next(id, after)
+  return state.handler():call_page_next(id, after)
+end
+
+---Get the progress of a running call as reported by the server (e.g. clickhouse).
+---It can be polled while the call is executing or retrieving.
+---Returns nil if the driver doesn't report progress or nothing was reported yet.
+---@param id call_id
+---@return Progress?
+function core.call_progress(id)
+  return state.handler():call_progress(id)
+end
+
 ---Display the result of a call formatted as a table in a buffer.
+---Style "expanded" displays every row as a record of column name / value pairs instead.
+---If opts.columns is set, zero based byte offsets of column starts on every line are
+---stored in the "dbee_columns" buffer variable (the first one is the row index column).
 ---@param id call_id id of the call
 ---@param bufnr integer
 ---@param from integer
 ---@param to integer
+---@param opts? { style: table_style, columns: boolean }
 ---@return integer total number of rows
-function core.call_display_result(id, bufnr, from, to)
-  return state.handler():call_display_result(id, bufnr, from, to)
+function core.call_display_result(id, bufnr, from, to, opts)
+  return state.handler():call_display_result(id, bufnr, from, to, opts)
+end
+
+---Get the footer line of the displayed rows of a call's result: the number of rows,
+---whether the result was truncated by the row limit ("default_limit" option or "limit"
+---directive), the elapsed time and "streaming…" while more rows are being retrieved.
+---@param id call_id id of the call
+---@param from integer
+---@param to integer
+---@return string
+function core.call_result_footer(id, from, to)
+  return state.handler():call_result_footer(id, from, to)
+end
+
+---Sort the already fetched result of a call by column without re-running the query.
+---Fails if the result is still being streamed.
+---@param id call_id id of the call
+---@param column integer zero based column index
+---@param ascending boolean
+function core.call_sort_result(id, column, ascending)
+  state.handler():call_sort_result(id, column, ascending)
+end
+
+---Reduce a call's result to chart-ready series: values of the x column and
+---numeric values of each y column. Numeric strings are coerced to numbers and
+---rows without an x value or with a non-numeric y value are dropped.
+---Errors if any of the columns doesn't exist or a y column has no numeric values.
+---@param id call_id id of the call
+---@param x string name of the x column
+---@param y string[] names of the y columns
+---@return ChartData
+function core.call_chart_data(id, x, y)
+  return state.handler():call_chart_data(id, x, y)
+end
+
+---Get indexes of the result rows matching a simple predicate: "<column> <operator> <value>"
+---(e.g. "age", ">", "30"). Operators are "=", "!=", "<", "<=", ">", ">=" and "~" (contains,
+---case insensitive). Values are compared as numbers, dates or strings (in this order,
+---whichever both sides are). NULLs never match.
+---@param id call_id id of the call
+---@param column string
+---@param operator row_operator
+---@param value string
+---@return integer[] zero based indexes of matching rows (in the displayed order)
+function core.call_highlight_rows(id, column, operator, value)
+  return state.handler():call_highlight_rows(id, column, operator, value)
+end
+
+---Select which columns of a call's result are displayed and stored (and in which order)
+---without re-running the query. Unknown column names are ignored with a warning.
+---Empty list displays all columns again.
+---@param id call_id id of the call
+---@param names string[] column names
+function core.call_select_columns(id, names)
+  local ignored = state.handler():call_select_columns(id, names)
+  if #ignored > 0 then
+    utils.log("warn", "ignoring unknown columns: " .. table.concat(ignored, ", "), "result")
+  end
 end
 
 ---Store the result of a call.
 ---@param id call_id
----@param format string format of the output -> "csv"|"json"|"table"
----@param output string where to pipe the results -> "file"|"yank"|"buffer"
+---@param format string format of the output -> "csv"|"tsv"|"markdown"|"json"|"yaml"|"table"
+---@param output string where to pipe the results -> "file"|"yank"|"buffer"|"table:<path>"|"csv-append:<path>"
 ---@param opts { from: integer, to: integer, extra_arg: any }
 function core.call_store_result(id, format, output, opts)
   state.handler():call_store_result(id, format, output, opts)
 end
 
+---Yank the whole result of a call to a register.
+---Warns if the result has more rows than opts.max_rows and only the first ones were yanked.
+---@param id call_id
+---@param format string format of the output -> "csv"|"tsv"|"markdown"|"json"|"yaml"|"table"
+---@param register? string register to yank to (e.g. "+" for the clipboard, default is the unnamed register)
+---@param opts? { max_rows: integer } max_rows limits the number of yanked rows (0 for no limit)
+---@return integer # number of yanked rows
+function core.call_yank_result(id, format, register, opts)
+  local rows, truncated = state.handler():call_yank_result(id, format, register, opts)
+  if truncated then
+    utils.log("warn", "yanked only the first " .. rows .. " rows", "result")
+  end
+  return rows
+end
+
+---Export the result of a call to a tab of a Google Sheets spreadsheet.
+---Contents of the tab are replaced with the header and rows of the result (the tab is
+---created if it doesn't exist). Rows are written in batches and rate limited requests
+---are retried, so large results take a while.
+---Credentials are a path to a (service account) credentials file or its contents. If
+---omitted, application default credentials are used (e.g. from
+---`gcloud auth application-default login`).
+---@param id call_id
+---@param spreadsheet_id string id of the spreadsheet (from its url)
+---@param sheet string name of the tab
+---@param opts? { credentials: string }
+---@return integer # number of exported rows
+function core.call_export_sheet(id, spreadsheet_id, sheet, opts)
+  return state.handler():call_export_sheet(id, spreadsheet_id, sheet, opts)
+end
+
+---Upload the result of a call to an S3 object.
+---The url is in form of "s3://bucket/key?region=...&format=csv". Supported formats are
+---"csv", "tsv" and "json" (defaults to the extension of the key, then "csv"). The region
+---defaults to the AWS configuration and "endpoint" can point to an S3 compatible service
+---(e.g. "endpoint=http://localhost:9000").
+---The result is formatted and uploaded in parts (a multipart upload), so large results
+---aren't held in memory. Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+---and AWS_SESSION_TOKEN or the shared credentials file (profile from AWS_PROFILE).
+---@param id call_id
+---@param url string s3 url of the object
+---@return integer # number of exported rows
+function core.call_export_s3(id, url)
+  return state.handler():call_export_s3(id, url)
+end
+
 return core
@@ -37,6 +37,7 @@ local function setup_handler()
 
   m.handler = Handler:new(m.config.sources)
   m.handler:add_helpers(m.config.extra_helpers)
+  m.handler:set_interpolation_tokens(m.config.interpolation_tokens)
 
   -- activate default connection if present
   if m.config.default_connection then
@@ -210,6 +210,38 @@ function ui.result_page_first()
   state.result():page_first()
 end
 
+--- Toggle between borderless and box table style in results UI.
+function ui.result_toggle_table_style()
+  state.result():toggle_table_style()
+end
+
+--- Toggle expanded display in results UI.
+--- Every row is displayed as a record of column name / value pairs (like psql's "\x").
+function ui.result_toggle_expanded()
+  state.result():toggle_expanded()
+end
+
+--- Toggle the footer line in results UI.
+--- The footer shows the number of rows, whether the result was truncated by the row limit,
+--- the elapsed time and "streaming…" while more rows are being retrieved.
+function ui.result_toggle_footer()
+  state.result():toggle_footer()
+end
+
+--- Highlight rows of the result in results UI whose column value matches the condition
+--- (e.g. "total", ">", "100"). See |core.call_highlight_rows| for operators.
+---@param column string
+---@param operator row_operator
+---@param value string
+function ui.result_highlight_rows(column, operator, value)
+  state.result():highlight_rows(column, operator, value)
+end
+
+--- Clear row highlights in results UI.
+function ui.result_clear_row_highlights()
+  state.result():clear_row_highlights()
+end
+
 --- Open the result UI.
 ---@param winid integer
 function ui.result_show(winid)
@@ -7,6 +7,7 @@ local config = {}
 ---@field default_connection? string
 ---@field sources? Source[] list of connection sources
 ---@field extra_helpers? table<string, table<string, string>>
+---@field interpolation_tokens? table<string, string>
 ---@field float_options? table<string, any>
 ---@field drawer? drawer_config
 ---@field editor? editor_config
@@ -25,7 +26,7 @@ local config = {}
 ---@divider -
 
 ---Configuration for result UI tile.
----@alias result_config { mappings: key_mapping[], page_size: integer, progress: progress_config, window_options: table<string, any>, buffer_options: table<string, any> }
+---@alias result_config { mappings: key_mapping[], page_size: integer, table_style: table_style, yank_max_rows: integer, row_highlight: string, footer: boolean, footer_highlight: string, column_offsets: boolean, progress: progress_config, window_options: table<string, any>, buffer_options: table<string, any> }
 
 ---Configuration for editor UI tile.
 ---@alias editor_config { directory: string, mappings: key_mapping[], window_options: table<string, any>, buffer_options: table<string, any> }
@@ -60,6 +61,19 @@ config.default = {
     --   ["List All"] = "select * from {{ .Table }}",
     -- },
   },
+  -- tokens replaced in executed queries with SQL literals of their values
+  -- (e.g. "WHERE id = {{word}}"). Every token is a vim expression evaluated
+  -- in the current window, an empty string disables it. Scoped variables
+  -- (e.g. "{{g:my_var}}") can always be used.
+  interpolation_tokens = {
+    -- text of the last visual selection
+    selection = [[luaeval('require("dbee.utils").last_selection()')]],
+    -- word or WORD under the cursor
+    word = "expand('<cword>')",
+    WORD = "expand('<cWORD>')",
+    -- current line
+    line = "getline('.')",
+  },
   -- options passed to floating windows - :h nvim_open_win()
   float_options = {},
 
@@ -80,9 +94,9 @@ config.default = {
       -- manually refresh drawer
       { key = "r", mode = "n", action = "refresh" },
       -- actions perform different stuff depending on the node:
-      -- action_1 opens a note or executes a helper
+      -- action_1 opens a note, executes a helper or lists the most common values of a column
       { key = "<CR>", mode = "n", action = "action_1" },
-      -- action_2 renames a note or sets the connection as active manually
+      -- action_2 renames a note, sets the connection as active manually, describes a table or calls a procedure
       { key = "cw", mode = "n", action = "action_2" },
       -- action_3 deletes a note or connection (removes connection from the file if you configured it like so)
       { key = "dd", mode = "n", action = "action_3" },
@@ -119,16 +133,50 @@ config.default = {
         icon = "",
         icon_highlight = "Character",
       },
+      schema_switch = {
+        icon = "",
+        icon_highlight = "Character",
+      },
       table = {
         icon = "",
         icon_highlight = "Conditional",
         text_highlight = "",
       },
+      kudu_table = {
+        icon = "",
+        icon_highlight = "Constant",
+        text_highlight = "",
+      },
+      external_table = {
+        icon = "",
+        icon_highlight = "Special",
+        text_highlight = "",
+      },
       view = {
         icon = "",
         icon_highlight = "Debug",
         text_highlight = "",
       },
+      procedure = {
+        icon = "󰊕",
+        icon_highlight = "Function",
+        text_highlight = "",
+      },
+      function = {
+        icon = "󰡱",
+        icon_highlight = "Function",
+        text_highlight = "",
+      },
+      sequence = {
+        icon = "󰔚",
+        icon_highlight = "Number",
+        text_highlight = "",
+      },
+      index = {
+        icon = "",
+        icon_highlight = "Number",
+        text_highlight = "",
+      },
       column = {
         icon = "󰠵",
         icon_highlight = "WarningMsg",
@@ -192,6 +240,27 @@ config.default = {
     -- number of rows in the results set to display per page
     page_size = 100,
 
+    -- style of the displayed table: "borderless" or "box" (full box-drawing borders)
+    table_style = "borderless",
+
+    -- maximum number of rows yanked with "yank_all_*" actions or require("dbee").yank()
+    -- (large results would flood the clipboard). 0 means no limit.
+    yank_max_rows = 10000,
+
+    -- highlight group of rows matching the "highlight_rows" condition
+    row_highlight = "Search",
+
+    -- display a footer line below the rows with the number of rows, whether the result was
+    -- truncated by the row limit, the elapsed time and "streaming…" while rows arrive
+    footer = true,
+    -- highlight group of the footer line
+    footer_highlight = "Comment",
+
+    -- store byte offsets of column starts of the displayed table in the "dbee_columns"
+    -- buffer variable, which enables the "next_column" and "prev_column" actions
+    -- (e.g. { key = "w", mode = "n", action = "next_column" })
+    column_offsets = false,
+
     -- progress (loading) screen options
     progress = {
       -- spinner to use in progress display
@@ -207,13 +276,36 @@ config.default = {
       { key = "H", mode = "", action = "page_prev" },
       { key = "E", mode = "", action = "page_last" },
       { key = "F", mode = "", action = "page_first" },
-      -- yank rows as csv/json
+      -- toggle between borderless and box table style
+      { key = "B", mode = "", action = "toggle_table_style" },
+      -- toggle expanded display (one record of column name / value pairs per row)
+      { key = "X", mode = "", action = "toggle_expanded" },
+      -- toggle the footer line
+      { key = "T", mode = "", action = "toggle_footer" },
+      -- yank rows as csv/json/tsv/markdown
       { key = "yaj", mode = "n", action = "yank_current_json" },
       { key = "yaj", mode = "v", action = "yank_selection_json" },
       { key = "yaJ", mode = "", action = "yank_all_json" },
       { key = "yac", mode = "n", action = "yank_current_csv" },
       { key = "yac", mode = "v", action = "yank_selection_csv" },
       { key = "yaC", mode = "", action = "yank_all_csv" },
+      { key = "yat", mode = "n", action = "yank_current_tsv" },
+      { key = "yat", mode = "v", action = "yank_selection_tsv" },
+      { key = "yaT", mode = "", action = "yank_all_tsv" },
+      { key = "yam", mode = "n", action = "yank_current_markdown" },
+      { key = "yam", mode = "v", action = "yank_selection_markdown" },
+      { key = "yaM", mode = "", action = "yank_all_markdown" },
+
+      -- sort by the column under the cursor
+      { key = "sa", mode = "n", action = "sort_asc" },
+      { key = "sd", mode = "n", action = "sort_desc" },
+
+      -- display only the selected columns (comma separated, empty for all)
+      { key = "sc", mode = "", action = "select_columns" },
+
+      -- highlight rows matching a condition (e.g. "age >= 30") and clear the highlights
+      { key = "sh", mode = "", action = "highlight_rows" },
+      { key = "sH", mode = "", action = "clear_row_highlights" },
 
       -- cancel current call execution
       { key = "<C-c>", mode = "", action = "cancel_call" },
@@ -311,6 +403,7 @@ function config.validate(cfg)
   vim.validate {
     sources = { cfg.sources, "table" },
     extra_helpers = { cfg.extra_helpers, "table" },
+    interpolation_tokens = { cfg.interpolation_tokens, "table" },
     float_options = { cfg.float_options, "table" },
 
     drawer_disable_candies = { cfg.drawer.disable_candies, "boolean" },
@@ -318,6 +411,12 @@ function config.validate(cfg)
     drawer_candies = { cfg.drawer.candies, "table" },
     drawer_mappings = { cfg.drawer.mappings, "table" },
     result_page_size = { cfg.result.page_size, "number" },
+    result_table_style = { cfg.result.table_style, "string" },
+    result_yank_max_rows = { cfg.result.yank_max_rows, "number" },
+    result_row_highlight = { cfg.result.row_highlight, "string" },
+    result_footer = { cfg.result.footer, "boolean" },
+    result_footer_highlight = { cfg.result.footer_highlight, "string" },
+    result_column_offsets = { cfg.result.column_offsets, "boolean" },
     result_progress = { cfg.result.progress, "table" },
     result_mappings = { cfg.result.mappings, "table" },
     editor_mappings = { cfg.editor.mappings, "table" },
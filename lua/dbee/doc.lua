@@ -13,17 +13,30 @@
 ---@class Column
 ---@field name string name of the column
 ---@field type string database type of the column
+---@field max_length? integer declared maximum length of character types
+---@field precision? integer declared precision of numeric types
+---@field scale? integer declared scale of numeric types
+---@field is_generated? boolean value is computed by the database (e.g. GENERATED ALWAYS AS)
+---@field generation_expression? string expression of the generated column
 
 ---Table Materialization.
 ---@alias materialization
 ---| '"table"'
 ---| '"view"'
+---| '"procedure"'
+---| '"function"'
+---| '"sequence"'
+---| '"kudu_table"'
+---| '"external_table"'
+---| '"partition"'
+---| '"index"'
 
 ---Options for gathering table specific info.
 ---@class TableOpts
 ---@field table string
 ---@field schema string
 ---@field materialization materialization
+---@field parent? string parent table of a partition
 
 ---Table helpers queries by name.
 ---@alias table_helpers table<string, string>
@@ -56,6 +69,16 @@
 ---@field state call_state
 ---@field timestamp_us integer time in microseconds
 ---@field error? string error message in case of error
+---@field stats? table<string, string> query statistics reported by the database (e.g. execution time)
+---@field warnings? string[] non-fatal problems with the call (e.g. unknown directives or warnings reported by the database)
+---@field page? PageDetails page of a paged query (see |core.connection_execute_page|)
+
+---Page of a paged query.
+---@class PageDetails
+---@field number integer zero based number of the page
+---@field size integer
+---@field keyset boolean true if the page was selected by key (false if by offset)
+---@field keys? string[] ordering columns of keyset pagination
 
 ---@divider -
 ---@tag dbee.ref.types.connection
@@ -72,6 +95,34 @@
 ---@field name string
 ---@field type string
 ---@field url string
+---@field options? table<string, any> optional per-connection settings
+---@field values? table<string, any> template values available in other fields as {{ .key }}
+
+---Csv format of bulk copies (see |core.connection_copy_export|).
+---@class CopyOpts
+---@field delimiter? string single character separating fields (default ",")
+---@field null? string representation of NULL values (default empty)
+---@field header? boolean whether the first line is the header
+
+---Options of resumable exports (see |core.connection_export_resumable|).
+---@class ResumeOpts
+---@field key string[] columns the query orders its rows by (a unique key)
+---@field checkpoint_rows? integer number of rows written between checkpoints (default 1000)
+---@field max_retries? integer number of resumes after consecutive failures (default 3)
+
+---Style of masked values: "length" keeps the length of values, "fixed" doesn't.
+---@alias mask_style "length"|"fixed"
+
+---Kind of a statement (see |core.classify_statement|).
+---@alias statement_kind
+---| '"select"'
+---| '"insert"'
+---| '"update"'
+---| '"delete"'
+---| '"ddl"'
+---| '"transaction"'
+---| '"utility"'
+---| '"unknown"'
 
 ---@divider -
 ---@tag dbee.ref.types.structure
@@ -83,15 +134,100 @@
 ---@alias structure_type
 ---| '""'
 ---| '"table"'
+---| '"kudu_table"'
+---| '"external_table"'
+---| '"partition"'
+---| '"index"'
 ---| '"history"'
 ---| '"database_switch"'
+---| '"schema_switch"'
 ---| '"view"'
 
+---Compact summary of a query plan.
+---@class ExplainSummary
+---@field total_cost number estimated total cost of the plan
+---@field plan_rows integer estimated number of returned rows
+---@field actual_rows? integer actual number of returned rows (only if analyzed)
+---@field expensive_node string node with the highest cost of its own (excluding children)
+---@field expensive_node_cost number cost of the most expensive node
+
+---Row of a snapshot diff.
+---@class SnapshotRow
+---@field row integer row number in the snapshot (missing rows) or in the result (unexpected rows)
+---@field values string json encoded values of the row
+
+---Result of comparing a query result with a stored snapshot.
+---@class SnapshotDiff
+---@field match boolean true if the result matches the snapshot
+---@field updated boolean true if the snapshot was written instead of compared
+---@field expected_columns? string[] columns of the snapshot (only if columns differ)
+---@field actual_columns? string[] columns of the result (only if columns differ)
+---@field missing SnapshotRow[] rows of the snapshot which are not in the result
+---@field unexpected SnapshotRow[] rows of the result which are not in the snapshot
+
+---Storage size of a table.
+---@class TableSize
+---@field total integer total size in bytes (data, indexes and overhead)
+---@field data integer size of table data in bytes
+---@field index integer size of indexes in bytes
+---@field overhead integer size of other storage in bytes (e.g. TOAST of postgres)
+---@field total_pretty string human readable total size (e.g. "1.5 MiB")
+---@field data_pretty string human readable data size
+---@field index_pretty string human readable index size
+---@field overhead_pretty string human readable overhead size
+
+---Privilege on a table held by a user or role.
+---@class Grant
+---@field grantee string
+---@field privilege string e.g. "SELECT"
+---@field grantable boolean true if the grantee can grant the privilege to others
+---@field level string level at which the privilege was granted ("TABLE", "SCHEMA" or "GLOBAL", empty if unknown)
+
+---Progress of a running call reported by the server (totals since the call started).
+---@class Progress
+---@field rows_read integer
+---@field bytes_read integer
+---@field total_rows integer estimate of rows the query has to read (0 if unknown)
+---@field rows_written integer
+---@field bytes_written integer
+---@field bytes_read_pretty string human readable bytes read (e.g. "1.5 MiB")
+---@field bytes_written_pretty string human readable bytes written
+---@field stage string what the query is waiting for (e.g. "engine launching"), empty if unknown
+
+---Sequence or auto increment counter of a table.
+---@class Sequence
+---@field schema string
+---@field name string name of the sequence (or of the table for auto increment counters)
+---@field table string table owning the sequence (empty if not owned)
+---@field column string column owning the sequence (empty if not owned)
+---@field current? integer current value (nil if the sequence wasn't used yet)
+---@field increment integer
+---@field min integer
+---@field max integer
+---@field remaining integer number of values left before the sequence is exhausted
+---@field used_percent number used part of the sequence range in percent
+
+---Node of a query plan tree.
+---@class PlanNode
+---@field type string type of operation (e.g. "Seq Scan")
+---@field relation string relation (table) the node operates on
+---@field index string index used by the node
+---@field cost number estimated cost (including children)
+---@field rows integer estimated number of rows
+---@field actual_rows? integer actual number of rows (only if analyzed)
+---@field children PlanNode[] child nodes
+
+---Result reduced to series of numbers for plotting.
+---@class ChartData
+---@field x any[] values of the x column
+---@field series table<string, number[]> values of each y column (same length as x)
+
 ---Structure of database.
 ---@class DBStructure
 ---@field name string display name
 ---@field type structure_type type of node in structure
 ---@field schema string? parent schema
+---@field parent string? parent table of a partition
 ---@field children DBStructure[]? child layout nodes
 
 ---@divider -
@@ -105,6 +241,9 @@
 ---| '"call_state_changed"' {call}
 ---| '"current_connection_changed"' {conn_id}
 ---| '"database_selected"' {conn_id, database_name}
+---| '"schema_selected"' {conn_id, schema_name}
+---| '"branch_selected"' {conn_id, branch_name}
+---| '"structure_loaded"' {conn_id, structure, error?}
 
 ---Available editor events.
 ---@alias editor_event_name
@@ -1,6 +1,12 @@
 local event_bus = require("dbee.handler.__events")
+local MemorySource = require("dbee.sources").MemorySource
 local utils = require("dbee.utils")
 
+-- name of the source with the scratch database
+local SCRATCH_SOURCE = "scratch"
+-- name of the source with the analytics database
+local ANALYTICS_SOURCE = "analytics"
+
 -- Handler is an aggregator of connections
 ---@class Handler
 ---@field private sources table<source_id, Source>
@@ -84,6 +90,16 @@ function Handler:source_reload(id)
   end
 end
 
+---@param details ConnectionParams
+---@return string? # error message if the params are invalid
+function Handler:validate_connection(details)
+  local ok, err = pcall(vim.fn.DbeeValidateConnection, details)
+  if not ok then
+    -- strip the rpc prefix ("Vim:Error invoking ... on channel N:")
+    return (tostring(err):gsub("^.-channel %d+:%s*", ""))
+  end
+end
+
 ---@param id source_id
 ---@param details ConnectionParams
 ---@return connection_id
@@ -180,6 +196,11 @@ function Handler:add_helpers(helpers)
   end
 end
 
+---@param tokens table<string, string> token names to vim expressions
+function Handler:set_interpolation_tokens(tokens)
+  vim.fn.DbeeSetInterpolationTokens(vim.tbl_isempty(tokens) and vim.empty_dict() or tokens)
+end
+
 ---@param id connection_id
 ---@param opts TableOpts
 ---@return table_helpers helpers list of table helpers
@@ -188,6 +209,7 @@ function Handler:connection_get_helpers(id, opts)
     table = opts.table,
     schema = opts.schema,
     materialization = opts.materialization,
+    parent = opts.parent,
   })
   if not helpers or helpers == vim.NIL then
     return {}
@@ -196,6 +218,176 @@ function Handler:connection_get_helpers(id, opts)
   return helpers
 end
 
+---@param id connection_id
+---@param opts TableOpts
+---@return CallDetails
+function Handler:connection_describe(id, opts)
+  return vim.fn.DbeeConnectionDescribe(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+    parent = opts.parent,
+  })
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@param column string
+---@param n integer
+---@return CallDetails
+function Handler:connection_top_values(id, opts, column, n)
+  return vim.fn.DbeeConnectionTopValues(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+    parent = opts.parent,
+  }, column, n)
+end
+
+---Returns the id of the scratch database connection (an in-memory sqlite database),
+---which is registered on first use.
+---@return connection_id
+function Handler:scratch_connection_id()
+  if not self.sources[SCRATCH_SOURCE] then
+    self:add_source(MemorySource:new({
+      {
+        name = "scratch",
+        type = "sqlite",
+        -- shared cache keeps the database alive across pooled connections
+        url = "file:dbee_scratch?mode=memory&cache=shared",
+      },
+    }, SCRATCH_SOURCE))
+  end
+
+  local conns = self:source_get_connections(SCRATCH_SOURCE)
+  if #conns < 1 then
+    error("scratch database is not available")
+  end
+  return conns[1].id
+end
+
+---Returns the id of the analytics connection (an in-memory DuckDB database) used for
+---querying local files, which is registered on first use.
+---@return connection_id
+function Handler:analytics_connection_id()
+  if not self.sources[ANALYTICS_SOURCE] then
+    self:add_source(MemorySource:new({
+      {
+        name = "analytics",
+        type = "duckdb",
+        url = "",
+      },
+    }, ANALYTICS_SOURCE))
+  end
+
+  local conns = self:source_get_connections(ANALYTICS_SOURCE)
+  if #conns < 1 then
+    error("analytics database is not available")
+  end
+  return conns[1].id
+end
+
+---@param id connection_id
+---@param query string
+---@param path string
+---@param opts? CopyOpts
+---@return integer # number of exported rows
+function Handler:connection_copy_export(id, query, path, opts)
+  return vim.fn.DbeeConnectionCopyExport(id, query, vim.fn.expand(path), opts or {})
+end
+
+---@param id connection_id
+---@param table { table: string, schema?: string }
+---@param path string
+---@param opts? CopyOpts
+---@return integer # number of imported rows
+function Handler:connection_copy_import(id, table, path, opts)
+  return vim.fn.DbeeConnectionCopyImport(id, table, vim.fn.expand(path), opts or {})
+end
+
+---@param id connection_id
+---@param query string
+---@param out string path of the file or s3 url of the object
+---@param opts ResumeOpts
+---@return integer # number of exported rows
+function Handler:connection_export_resumable(id, query, out, opts)
+  if not vim.startswith(out, "s3://") then
+    out = vim.fn.expand(out)
+  end
+  return vim.fn.DbeeConnectionExportResumable(id, query, out, opts)
+end
+
+---@param query string query referencing tables as "<connection>.<schema>.<table>"
+---@return CallDetails
+function Handler:federate(query)
+  return vim.fn.DbeeFederate(self:analytics_connection_id(), query)
+end
+
+---@param path string path or glob of parquet, csv or json files
+---@return string # query selecting all rows of the files
+function Handler:analyze_file_query(path)
+  return vim.fn.DbeeAnalyzeFileQuery(path)
+end
+
+---@param id connection_id source connection
+---@param query string
+---@param table string name of the table in the scratch database
+---@return integer # number of imported rows
+function Handler:connection_import(id, query, table)
+  return vim.fn.DbeeConnectionImport(id, query, self:scratch_connection_id(), table)
+end
+
+---@param id connection_id
+---@return DBStructure[]
+function Handler:connection_list_procedures(id)
+  local ret = vim.fn.DbeeConnectionListProcedures(id)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@return Column[]
+function Handler:connection_get_procedure_parameters(id, opts)
+  local ret = vim.fn.DbeeConnectionGetProcedureParameters(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+    parent = opts.parent,
+  })
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
+---@param id connection_id
+---@param opts TableOpts
+---@param args any[] positional arguments (vim.NIL for NULL)
+---@return CallDetails
+function Handler:connection_call_procedure(id, opts, args)
+  return vim.fn.DbeeConnectionCallProcedure(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+    parent = opts.parent,
+  }, args)
+end
+
+---@param query string
+---@return string formatted
+function Handler:format_sql(query)
+  return vim.fn.DbeeFormatSQL(query)
+end
+
+---@param query string
+---@return statement_kind
+function Handler:classify_statement(query)
+  return vim.fn.DbeeClassifyStatement(query)
+end
+
 ---@return ConnectionParams?
 function Handler:get_current_connection()
   local ok, ret = pcall(vim.fn.DbeeGetCurrentConnection)
@@ -212,9 +404,126 @@ end
 
 ---@param id connection_id
 ---@param query string
+---@param opts? { force: boolean }
 ---@return CallDetails
-function Handler:connection_execute(id, query)
-  return vim.fn.DbeeConnectionExecute(id, query)
+function Handler:connection_execute(id, query, opts)
+  opts = opts or {}
+  local call = vim.fn.DbeeConnectionExecute(id, query, { force = opts.force or false })
+  for _, warning in ipairs(call.warnings or {}) do
+    utils.log("warn", warning, "core")
+  end
+  return call
+end
+
+---@param id connection_id
+---@param query string
+---@param params table|string values (or json encoded values) of placeholders
+---@return CallDetails
+function Handler:connection_execute_params(id, query, params)
+  if type(params) == "table" then
+    params = vim.json.encode(params)
+  end
+  return vim.fn.DbeeConnectionExecuteParams(id, query, params or "")
+end
+
+---@param id connection_id
+---@param query string
+---@param page_size integer
+---@return CallDetails
+function Handler:connection_execute_page(id, query, page_size)
+  return vim.fn.DbeeConnectionExecutePage(id, query, page_size)
+end
+
+---@param id connection_id
+---@param query string
+---@return CallDetails
+function Handler:connection_execute_all_databases(id, query)
+  return vim.fn.DbeeConnectionExecuteAllDatabases(id, query)
+end
+
+---@param id connection_id
+---@param query string
+---@param path string path of the snapshot file
+---@param opts? { unordered: boolean, update: boolean }
+---@return SnapshotDiff
+function Handler:connection_assert_result(id, query, path, opts)
+  return vim.fn.DbeeConnectionAssertResult(id, query, path, opts or vim.empty_dict())
+end
+
+---@param id connection_id
+---@param query string
+---@param analyze? boolean
+---@return ExplainSummary
+function Handler:connection_explain_cost(id, query, analyze)
+  return vim.fn.DbeeConnectionExplainCost(id, query, analyze or false)
+end
+
+---@param id connection_id
+---@param query string
+---@param analyze? boolean
+---@return PlanNode
+function Handler:connection_explain_plan(id, query, analyze)
+  return vim.fn.DbeeConnectionExplainPlan(id, query, analyze or false)
+end
+
+---@param id connection_id
+---@param query string
+---@return CallDetails
+function Handler:connection_suggest_indexes(id, query)
+  return vim.fn.DbeeConnectionSuggestIndexes(id, query)
+end
+
+---@param id connection_id
+---@param query string
+---@return Column[]
+function Handler:connection_peek(id, query)
+  return vim.fn.DbeeConnectionPeek(id, query)
+end
+
+---@param id connection_id
+---@param query string
+---@return integer
+function Handler:connection_preview_affected(id, query)
+  return vim.fn.DbeeConnectionPreviewAffected(id, query)
+end
+
+---@param id connection_id
+---@param channel string
+---@param bufnr integer buffer to append notifications to
+function Handler:connection_listen(id, channel, bufnr)
+  vim.fn.DbeeConnectionListen(id, channel, bufnr)
+end
+
+---@param id connection_id
+---@param channel string
+function Handler:connection_unlisten(id, channel)
+  vim.fn.DbeeConnectionUnlisten(id, channel)
+end
+
+---@param id connection_id
+---@param patterns string[]
+---@param style? mask_style
+function Handler:connection_set_mask(id, patterns, style)
+  vim.fn.DbeeConnectionSetMask(id, patterns, style or "")
+end
+
+---@param id connection_id
+---@param name string
+---@param value string
+function Handler:connection_set_session_param(id, name, value)
+  vim.fn.DbeeConnectionSetSessionParam(id, name, value)
+end
+
+---@param id connection_id
+---@param query string
+---@return table<string, any>[]
+function Handler:connection_execute_json(id, query)
+  local ret = vim.fn.DbeeConnectionExecuteJSON(id, query)
+  if not ret or ret == vim.NIL or ret == "" then
+    return {}
+  end
+
+  return vim.json.decode(ret, { luanil = { object = true, array = true } })
 end
 
 ---@param id connection_id
@@ -227,8 +536,20 @@ function Handler:connection_get_structure(id)
   return ret
 end
 
+-- Starts loading structure in the background, which can be cancelled.
+-- The result is sent with the "structure_loaded" event.
+---@param id connection_id
+function Handler:connection_load_structure(id)
+  vim.fn.DbeeConnectionLoadStructure(id)
+end
+
 ---@param id connection_id
----@param opts { table: string, schema: string, materialization: string }
+function Handler:connection_cancel_structure(id)
+  vim.fn.DbeeConnectionCancelStructure(id)
+end
+
+---@param id connection_id
+---@param opts { table: string, schema: string, materialization: string, parent?: string }
 ---@return Column[]
 function Handler:connection_get_columns(id, opts)
   local out = vim.fn.DbeeConnectionGetColumns(id, opts)
@@ -239,6 +560,50 @@ function Handler:connection_get_columns(id, opts)
   return out
 end
 
+---@param id connection_id
+---@param opts TableOpts
+---@return TableSize
+function Handler:connection_get_table_size(id, opts)
+  return vim.fn.DbeeConnectionGetTableSize(id, {
+    table = opts.table,
+    schema = opts.schema,
+    materialization = opts.materialization,
+    parent = opts.parent,
+  })
+end
+
+---@param id connection_id
+---@param opts? TableOpts
+---@return Sequence[]
+function Handler:connection_get_sequences(id, opts)
+  opts = opts or {}
+  local out = vim.fn.DbeeConnectionGetSequences(id, {
+    table = opts.table or "",
+    schema = opts.schema or "",
+  })
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
+---@param id connection_id
+---@param opts { table: string, schema: string, effective: boolean }
+---@return Grant[]
+function Handler:connection_get_grants(id, opts)
+  local out = vim.fn.DbeeConnectionGetGrants(id, {
+    table = opts.table,
+    schema = opts.schema,
+    effective = opts.effective or false,
+  })
+  if not out or out == vim.NIL then
+    return {}
+  end
+
+  return out
+end
+
 ---@param id connection_id
 ---@return ConnectionParams?
 function Handler:connection_get_params(id)
@@ -267,6 +632,58 @@ function Handler:connection_select_database(id, database)
   vim.fn.DbeeConnectionSelectDatabase(id, database)
 end
 
+---@param id connection_id
+---@return string current_schema
+---@return string[] available_schemas
+function Handler:connection_list_schemas(id)
+  local ret = vim.fn.DbeeConnectionListSchemas(id)
+  if not ret or ret == vim.NIL then
+    return "", {}
+  end
+
+  return unpack(ret)
+end
+
+---@param id connection_id
+---@param schema string
+function Handler:connection_select_schema(id, schema)
+  vim.fn.DbeeConnectionSelectSchema(id, schema)
+end
+
+---@param id connection_id
+---@return string current_branch
+---@return string[] available_branches
+function Handler:connection_list_branches(id)
+  local ret = vim.fn.DbeeConnectionListBranches(id)
+  if not ret or ret == vim.NIL then
+    return "", {}
+  end
+
+  return unpack(ret)
+end
+
+---@param id connection_id
+---@param branch string
+function Handler:connection_select_branch(id, branch)
+  vim.fn.DbeeConnectionSelectBranch(id, branch)
+end
+
+---@param id connection_id
+---@param path string
+function Handler:connection_export_schema(id, path)
+  vim.fn.DbeeConnectionExportSchema(id, path)
+end
+
+---@param id connection_id
+---@return CallDetails[]
+function Handler:connection_get_active_queries(id)
+  local ret = vim.fn.DbeeConnectionGetActiveQueries(id)
+  if not ret or ret == vim.NIL then
+    return {}
+  end
+  return ret
+end
+
 ---@param id connection_id
 ---@return CallDetails[]
 function Handler:connection_get_calls(id)
@@ -282,21 +699,97 @@ function Handler:call_cancel(id)
   vim.fn.DbeeCallCancel(id)
 end
 
+---@param id call_id
+---@param after? any[] key values of the last row of the page
+---@return CallDetails
+function Handler:call_page_next(id, after)
+  return vim.fn.DbeeCallPageNext(id, after or {})
+end
+
+---@param id call_id
+---@return Progress?
+function Handler:call_progress(id)
+  local ret = vim.fn.DbeeCallProgress(id)
+  if not ret or ret == vim.NIL then
+    return nil
+  end
+  return ret
+end
+
+---@alias table_style "borderless"|"box"|"expanded"
+
 ---@param id call_id
 ---@param bufnr integer
 ---@param from integer
 ---@param to integer
+---@param opts? { style: table_style, columns: boolean }
 ---@return integer # total number of rows
-function Handler:call_display_result(id, bufnr, from, to)
-  local length = vim.fn.DbeeCallDisplayResult(id, { buffer = bufnr, from = from, to = to })
+function Handler:call_display_result(id, bufnr, from, to, opts)
+  opts = opts or {}
+  local length = vim.fn.DbeeCallDisplayResult(id, {
+    buffer = bufnr,
+    from = from,
+    to = to,
+    style = opts.style,
+    columns = opts.columns or false,
+  })
   if not length or length == vim.NIL then
     return 0
   end
   return length
 end
 
----@alias store_format "csv"|"json"|"table"
----@alias store_output "file"|"yank"|"buffer"
+---@param id call_id
+---@param from integer
+---@param to integer
+---@return string # footer line of the displayed rows
+function Handler:call_result_footer(id, from, to)
+  return vim.fn.DbeeCallResultFooter(id, { from = from, to = to })
+end
+
+---@param id call_id
+---@param column integer zero based column index
+---@param ascending boolean
+function Handler:call_sort_result(id, column, ascending)
+  vim.fn.DbeeCallSortResult(id, { column = column, ascending = ascending })
+end
+
+---@param id call_id
+---@param names string[] names of displayed columns (empty to display all)
+---@return string[] # ignored (unknown) column names
+function Handler:call_select_columns(id, names)
+  local ignored = vim.fn.DbeeCallSelectColumns(id, names)
+  if not ignored or ignored == vim.NIL then
+    return {}
+  end
+  return ignored
+end
+
+---@param id call_id
+---@param x string name of the x column
+---@param y string[] names of numeric y columns
+---@return ChartData
+function Handler:call_chart_data(id, x, y)
+  return vim.fn.DbeeCallChartData(id, x, y)
+end
+
+---@alias row_operator "="|"!="|"<"|"<="|">"|">="|"~"
+
+---@param id call_id
+---@param column string
+---@param operator row_operator
+---@param value string
+---@return integer[] zero based indexes of matching rows
+function Handler:call_highlight_rows(id, column, operator, value)
+  local indexes = vim.fn.DbeeCallHighlightRows(id, column, operator, value)
+  if not indexes or indexes == vim.NIL then
+    return {}
+  end
+  return indexes
+end
+
+---@alias store_format "csv"|"tsv"|"markdown"|"json"|"yaml"|"table"
+---@alias store_output "file"|"yank"|"buffer"|string "table:<path>" writes the aligned text table to a file, "csv-append:<path>" appends rows to a csv file
 
 ---@param id call_id
 ---@param format store_format format of the output
@@ -315,4 +808,45 @@ function Handler:call_store_result(id, format, output, opts)
   })
 end
 
+---@param id call_id
+---@param format store_format format of the output
+---@param register string register to yank to (e.g. "+" for the clipboard)
+---@param opts? { max_rows: integer } max_rows limits the number of yanked rows (0 for no limit)
+---@return integer rows number of yanked rows
+---@return boolean truncated true if the result has more rows than max_rows
+function Handler:call_yank_result(id, format, register, opts)
+  opts = opts or {}
+
+  local ret = vim.fn.DbeeCallYankResult(id, format, register or "", {
+    max_rows = opts.max_rows or 0,
+  })
+  return ret[1], ret[2]
+end
+
+---@param id call_id
+---@param spreadsheet_id string id of the spreadsheet (from its url)
+---@param sheet string name of the tab
+---@param opts? { credentials: string } path to a credentials file or its contents
+---@return integer rows number of exported rows
+function Handler:call_export_sheet(id, spreadsheet_id, sheet, opts)
+  opts = opts or {}
+
+  local credentials = opts.credentials or ""
+  -- expand "~" and environment variables in paths
+  if credentials ~= "" and not vim.startswith(vim.trim(credentials), "{") then
+    credentials = vim.fn.expand(credentials)
+  end
+
+  return vim.fn.DbeeCallExportSheet(id, spreadsheet_id, sheet, {
+    credentials = credentials,
+  })
+end
+
+---@param id call_id
+---@param url string s3 url of the object ("s3://bucket/key?region=...&format=csv")
+---@return integer rows number of exported rows
+function Handler:call_export_s3(id, url)
+  return vim.fn.DbeeCallExportS3(id, url)
+end
+
 return Handler
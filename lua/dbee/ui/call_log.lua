@@ -271,6 +271,20 @@ function CallLogUI:configure_preview(bufnr)
         table.insert(call_summary, string.format("error:                %s", string.gsub(call.error, "\n", " ")))
       end
 
+      if call.stats and call.stats ~= vim.NIL then
+        local names = vim.tbl_keys(call.stats)
+        table.sort(names)
+        for _, name in ipairs(names) do
+          table.insert(call_summary, string.format("%-22s%s", name .. ":", call.stats[name]))
+        end
+      end
+
+      if call.warnings and call.warnings ~= vim.NIL then
+        for _, warning in ipairs(call.warnings) do
+          table.insert(call_summary, string.format("warning:              %s", string.gsub(warning, "\n", " ")))
+        end
+      end
+
       self.hover_close = common.float_hover(self.winid, call_summary)
     end,
   })
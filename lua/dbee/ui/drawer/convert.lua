@@ -6,18 +6,24 @@ local M = {}
 
 ---@param parent_id string
 ---@param columns Column[]
+---@param on_select? fun(column: Column): drawer_node_action action of column nodes
 ---@return DrawerUINode[]
-local function column_nodes(parent_id, columns)
+local function column_nodes(parent_id, columns, on_select)
   ---@type DrawerUINode[]
   local nodes = {}
 
   for _, column in ipairs(columns) do
+    local typ = column.type
+    if column.is_generated then
+      typ = typ .. ", generated"
+    end
     table.insert(
       nodes,
       NuiTree.Node {
         id = parent_id .. column.type .. column.name,
-        name = column.name .. "   [" .. column.type .. "]",
+        name = column.name .. "   [" .. typ .. "]",
         type = "column",
+        action_1 = on_select and on_select(column) or nil,
       }
     )
   end
@@ -25,11 +31,15 @@ local function column_nodes(parent_id, columns)
   return nodes
 end
 
+-- structure of a connection loaded in the background
+---@alias drawer_structure { state: "loading"|"loaded"|"error", structure?: DBStructure[], error?: string }
+
 ---@param handler Handler
 ---@param conn ConnectionParams
 ---@param result ResultUI
+---@param structures table<connection_id, drawer_structure>
 ---@return DrawerUINode[]
-local function connection_nodes(handler, conn, result)
+local function connection_nodes(handler, conn, result, structures)
   ---@param structs DBStructure[]
   ---@param parent_id string
   ---@return DrawerUINode[]
@@ -54,8 +64,72 @@ local function connection_nodes(handler, conn, result)
         type = struct.type,
       }, to_tree_nodes(struct.children, node_id)) --[[@as DrawerUINode]]
 
-      if struct.type == "table" or struct.type == "view" then
-        local table_opts = { table = struct.name, schema = struct.schema, materialization = struct.type }
+      local table_opts = {
+        table = struct.name,
+        schema = struct.schema,
+        materialization = struct.type,
+        parent = struct.parent,
+      }
+
+      -- describe object
+      ---@type drawer_node_action
+      local describe = function(cb)
+        local call = handler:connection_describe(conn.id, table_opts)
+        result:set_call(call)
+        cb()
+      end
+
+      if
+        struct.type == "procedure"
+        or struct.type == "function"
+        or struct.type == "sequence"
+        or struct.type == "index"
+      then
+        node.action_1 = describe
+      end
+
+      -- call procedure with prompted arguments (empty values are passed as NULL)
+      if struct.type == "procedure" or struct.type == "function" then
+        node.action_2 = function(cb)
+          local params = handler:connection_get_procedure_parameters(conn.id, table_opts)
+
+          local call_proc = function(args)
+            local call = handler:connection_call_procedure(conn.id, table_opts, args)
+            result:set_call(call)
+            cb()
+          end
+
+          if #params < 1 then
+            call_proc({})
+            return
+          end
+
+          local prompt = {}
+          for _, param in ipairs(params) do
+            table.insert(prompt, { name = param.name })
+          end
+          common.float_prompt(prompt, {
+            title = "Call " .. struct.name,
+            callback = function(res)
+              local args = {}
+              for _, param in ipairs(params) do
+                local val = res[param.name]
+                table.insert(args, (val == nil or val == "") and vim.NIL or val)
+              end
+              call_proc(args)
+            end,
+          })
+        end
+      end
+
+      if
+        struct.type == "table"
+        or struct.type == "kudu_table"
+        or struct.type == "external_table"
+        or struct.type == "partition"
+        or struct.type == "view"
+      then
+        node.action_2 = describe
 
         -- table helpers
         node.action_1 = function(cb, select)
@@ -77,8 +151,20 @@ local function connection_nodes(handler, conn, result)
           }
         end
 
+        -- most common values of the column
+        local top_values = function(column)
+          return function(cb)
+            local call = handler:connection_top_values(conn.id, table_opts, column.name, 10)
+            result:set_call(call)
+            cb()
+          end
+        end
+
+        -- columns are followed by partitions of the table
         node.lazy_children = function()
-          return column_nodes(node_id, handler:connection_get_columns(conn.id, table_opts))
+          local nodes = column_nodes(node_id, handler:connection_get_columns(conn.id, table_opts), top_values)
+          vim.list_extend(nodes, to_tree_nodes(struct.children, node_id))
+          return nodes
         end
       end
 
@@ -88,8 +174,45 @@ local function connection_nodes(handler, conn, result)
     return nodes
   end
 
-  -- recursively parse structure to drawer nodes
-  local nodes = to_tree_nodes(handler:connection_get_structure(conn.id), conn.id)
+  -- structure is loaded in the background, so a slow load can be cancelled
+  local loaded = structures[conn.id]
+  if not loaded then
+    loaded = { state = "loading" }
+    structures[conn.id] = loaded
+    handler:connection_load_structure(conn.id)
+  end
+
+  ---@type DrawerUINode[]
+  local nodes
+  if loaded.state == "loaded" then
+    -- recursively parse structure to drawer nodes
+    nodes = to_tree_nodes(loaded.structure, conn.id)
+  elseif loaded.state == "loading" then
+    nodes = {
+      NuiTree.Node {
+        id = conn.id .. "_structure_loading__",
+        name = "loading... (cancel)",
+        type = "",
+        action_1 = function(cb)
+          pcall(handler.connection_cancel_structure, handler, conn.id)
+          structures[conn.id] = { state = "error", error = "cancelled" }
+          cb()
+        end,
+      } --[[@as DrawerUINode]],
+    }
+  else
+    nodes = {
+      NuiTree.Node {
+        id = conn.id .. "_structure_error__",
+        name = loaded.error .. " (retry)",
+        type = "",
+        action_1 = function(cb)
+          structures[conn.id] = nil
+          cb()
+        end,
+      } --[[@as DrawerUINode]],
+    }
+  end
 
   -- database switching
   local current_db, available_dbs = handler:connection_list_databases(conn.id)
@@ -104,6 +227,7 @@ local function connection_nodes(handler, conn, result)
           items = available_dbs,
           on_confirm = function(selection)
             handler:connection_select_database(conn.id, selection)
+            structures[conn.id] = nil
             cb()
           end,
         }
@@ -112,13 +236,37 @@ local function connection_nodes(handler, conn, result)
     table.insert(nodes, 1, ly)
   end
 
+  -- schema switching (displayed below database switching)
+  local current_schema, available_schemas = handler:connection_list_schemas(conn.id)
+  if current_schema ~= "" and #available_schemas > 0 then
+    local ly = NuiTree.Node {
+      id = conn.id .. "_schema_switch__",
+      name = current_schema,
+      type = "schema_switch",
+      action_1 = function(cb, select)
+        select {
+          title = "Select a Schema",
+          items = available_schemas,
+          on_confirm = function(selection)
+            handler:connection_select_schema(conn.id, selection)
+            structures[conn.id] = nil
+            cb()
+          end,
+        }
+      end,
+    } --[[@as DrawerUINode]]
+    local pos = (nodes[1] and nodes[1].type == "database_switch") and 2 or 1
+    table.insert(nodes, pos, ly)
+  end
+
   return nodes
 end
 
 ---@param handler Handler
 ---@param result ResultUI
+---@param structures table<connection_id, drawer_structure>
 ---@return DrawerUINode[]
-local function handler_real_nodes(handler, result)
+local function handler_real_nodes(handler, result, structures)
   ---@type DrawerUINode[]
   local nodes = {}
 
@@ -150,6 +298,11 @@ local function handler_real_nodes(handler, result)
                   url = res.url,
                   type = res.type,
                 }
+                local err = handler:validate_connection(spec)
+                if err then
+                  utils.log("error", err, "add connection")
+                  return
+                end
                 pcall(handler.source_add_connection, handler, source_id, spec)
                 cb()
               end,
@@ -204,6 +357,11 @@ local function handler_real_nodes(handler, result)
                 url = res.url,
                 type = res.type,
               }
+              local err = handler:validate_connection(spec)
+              if err then
+                utils.log("error", err, "edit connection")
+                return
+              end
               pcall(handler.source_update_connection, handler, source_id, conn.id, spec)
               cb()
             end,
@@ -243,7 +401,7 @@ local function handler_real_nodes(handler, result)
         -- remove connection
         action_3 = delete_action,
         lazy_children = function()
-          return connection_nodes(handler, conn, result)
+          return connection_nodes(handler, conn, result, structures)
         end,
       } --[[@as DrawerUINode]]
 
@@ -296,13 +454,14 @@ end
 
 ---@param handler Handler
 ---@param result ResultUI
+---@param structures table<connection_id, drawer_structure> cache of loaded structures
 ---@return DrawerUINode[]
-function M.handler_nodes(handler, result)
+function M.handler_nodes(handler, result, structures)
   -- in case there are no sources defined, return helper nodes
   if #handler:get_sources() < 1 then
     return handler_help_nodes()
   end
-  return handler_real_nodes(handler, result)
+  return handler_real_nodes(handler, result, structures)
 end
 
 -- whitespace between nodes
@@ -12,7 +12,7 @@ local expansion = require("dbee.ui.drawer.expansion")
 ---@class DrawerUINode: NuiTree.Node
 ---@field id string unique identifier
 ---@field name string display name
----@field type ""|"table"|"view"|"column"|"history"|"note"|"connection"|"database_switch"|"add"|"edit"|"remove"|"help"|"source"|"separator" type of node
+---@field type ""|"table"|"kudu_table"|"external_table"|"partition"|"view"|"procedure"|"function"|"sequence"|"index"|"column"|"history"|"note"|"connection"|"database_switch"|"schema_switch"|"add"|"edit"|"remove"|"help"|"source"|"separator" type of node
 ---@field action_1? drawer_node_action primary action if function takes a second selection parameter, pick_items get picked before the call
 ---@field action_2? drawer_node_action secondary action if function takes a second selection parameter, pick_items get picked before the call
 ---@field action_3? drawer_node_action tertiary action if function takes a second selection parameter, pick_items get picked before the call
@@ -30,6 +30,7 @@ local expansion = require("dbee.ui.drawer.expansion")
 ---@field private bufnr integer
 ---@field private current_conn_id? connection_id current active connection
 ---@field private current_note_id? note_id current active note
+---@field private structures table<connection_id, drawer_structure> structures loaded in the background
 ---@field private window_options table<string, any> a table of window options.
 ---@field private buffer_options table<string, any> a table of buffer options.
 local DrawerUI = {}
@@ -70,6 +71,7 @@ function DrawerUI:new(handler, editor, result, opts)
     disable_help = opts.disable_help or false,
     current_conn_id = current_conn.id,
     current_note_id = current_note.id,
+    structures = {},
     window_options = vim.tbl_extend("force", {
       wrap = false,
       winfixheight = true,
@@ -101,6 +103,10 @@ function DrawerUI:new(handler, editor, result, opts)
     o:on_current_connection_changed(data)
   end)
 
+  handler:register_event_listener("structure_loaded", function(data)
+    o:on_structure_loaded(data)
+  end)
+
   editor:register_event_listener("current_note_changed", function(data)
     o:on_current_note_changed(data)
   end)
@@ -119,6 +125,18 @@ function DrawerUI:on_current_connection_changed(data)
   self:refresh()
 end
 
+-- event listener for finished structure loads
+---@private
+---@param data { conn_id: connection_id, structure: DBStructure[], error?: string }
+function DrawerUI:on_structure_loaded(data)
+  if data.error then
+    self.structures[data.conn_id] = { state = "error", error = data.error }
+  else
+    self.structures[data.conn_id] = { state = "loaded", structure = data.structure }
+  end
+  self:refresh()
+end
+
 -- event listener for current note change
 ---@private
 ---@param data { note_id: note_id }
@@ -245,6 +263,8 @@ function DrawerUI:get_actions()
 
   return {
     refresh = function()
+      -- reload structures of connections
+      self.structures = {}
       self:refresh()
     end,
     action_1 = function()
@@ -318,7 +338,7 @@ function DrawerUI:refresh()
     table.insert(nodes, ly)
   end
   table.insert(nodes, convert.separator_node())
-  for _, ly in ipairs(convert.handler_nodes(self.handler, self.result)) do
+  for _, ly in ipairs(convert.handler_nodes(self.handler, self.result, self.structures)) do
     table.insert(nodes, ly)
   end
 
@@ -127,34 +127,48 @@ end
 ---@private
 ---@return table<string, fun()>
 function EditorUI:get_actions()
+  ---@param query string
+  ---@param force boolean
+  local function run(query, force)
+    local conn = self.handler:get_current_connection()
+    if not conn then
+      return
+    end
+    local call = self.handler:connection_execute(conn.id, query, { force = force })
+    self.result:set_call(call)
+  end
+
+  ---@param force boolean
+  local function run_file(force)
+    if not self.winid or not vim.api.nvim_win_is_valid(self.winid) then
+      return
+    end
+    local bufnr = vim.api.nvim_win_get_buf(self.winid)
+    local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
+    run(table.concat(lines, "\n"), force)
+  end
+
+  ---@param force boolean
+  local function run_selection(force)
+    local srow, scol, erow, ecol = utils.visual_selection()
+
+    local selection = vim.api.nvim_buf_get_text(0, srow, scol, erow, ecol, {})
+    run(table.concat(selection, "\n"), force)
+  end
+
   return {
     run_file = function()
-      if not self.winid or not vim.api.nvim_win_is_valid(self.winid) then
-        return
-      end
-      local bufnr = vim.api.nvim_win_get_buf(self.winid)
-      local lines = vim.api.nvim_buf_get_lines(bufnr, 0, -1, false)
-      local query = table.concat(lines, "\n")
-
-      local conn = self.handler:get_current_connection()
-      if not conn then
-        return
-      end
-      local call = self.handler:connection_execute(conn.id, query)
-      self.result:set_call(call)
+      run_file(false)
     end,
     run_selection = function()
-      local srow, scol, erow, ecol = utils.visual_selection()
-
-      local selection = vim.api.nvim_buf_get_text(0, srow, scol, erow, ecol, {})
-      local query = table.concat(selection, "\n")
-
-      local conn = self.handler:get_current_connection()
-      if not conn then
-        return
-      end
-      local call = self.handler:connection_execute(conn.id, query)
-      self.result:set_call(call)
+      run_selection(false)
+    end,
+    -- run statements refused by the "require_where" connection option
+    run_file_force = function()
+      run_file(true)
+    end,
+    run_selection_force = function()
+      run_selection(true)
     end,
   }
 end
@@ -9,6 +9,17 @@ local common = require("dbee.ui.common")
 ---@field private bufnr integer
 ---@field private current_call? CallDetails
 ---@field private page_size integer
+---@field private table_style table_style
+---@field private yank_max_rows integer maximum number of rows yanked by "yank_all_*" actions
+---@field private expanded boolean display rows as records of column name / value pairs
+---@field private row_highlight string highlight group of rows matching the highlight rule
+---@field private highlight_rule? { column: string, operator: row_operator, value: string }
+---@field private footer boolean display the footer line with the state of the result
+---@field private column_offsets boolean store offsets of columns for column navigation
+---@field private footer_highlight string highlight group of the footer line
+---@field private footer_line? integer zero based index of the footer line in the buffer
+---@field private displayed_range { from: integer, to: integer } rows of the displayed page
+---@field private stop_footer_updates fun() function that stops redrawing the footer while streaming
 ---@field private mappings key_mapping[]
 ---@field private page_index integer index of the current page
 ---@field private page_ammount integer number of pages in the current result set
@@ -18,6 +29,13 @@ local common = require("dbee.ui.common")
 ---@field private buffer_options table<string, any> a table of buffer options.
 local ResultUI = {}
 
+-- namespace of highlighted rows
+local highlight_ns = vim.api.nvim_create_namespace("dbee_result_rows")
+-- namespace of the footer line
+local footer_ns = vim.api.nvim_create_namespace("dbee_result_footer")
+-- interval (ms) of redrawing the footer while rows are streaming
+local footer_interval = 500
+
 ---@param handler Handler
 ---@param opts? result_config
 ---@return ResultUI
@@ -32,6 +50,15 @@ function ResultUI:new(handler, opts)
   local o = {
     handler = handler,
     page_size = opts.page_size or 100,
+    table_style = opts.table_style or "borderless",
+    yank_max_rows = opts.yank_max_rows or 0,
+    expanded = false,
+    row_highlight = opts.row_highlight or "Search",
+    footer = opts.footer ~= false,
+    column_offsets = opts.column_offsets or false,
+    footer_highlight = opts.footer_highlight or "Comment",
+    displayed_range = { from = 0, to = 0 },
+    stop_footer_updates = function() end,
     page_index = 0,
     page_ammount = 0,
     mappings = opts.mappings or {},
@@ -89,11 +116,19 @@ function ResultUI:on_call_state_changed(data)
   elseif call.state == "retrieving" then
     self.stop_progress()
     self:page_current()
+    self:start_footer_updates()
   elseif call.state == "executing_failed" or call.state == "retrieving_failed" or call.state == "canceled" then
     self.stop_progress()
+    self.stop_footer_updates()
     self:display_status()
+  elseif call.state == "archived" then
+    self.stop_progress()
+    self.stop_footer_updates()
+    self:apply_row_highlights()
+    self:display_footer()
   else
     self.stop_progress()
+    self.stop_footer_updates()
   end
 end
 
@@ -103,7 +138,7 @@ function ResultUI:apply_highlight(winid)
   local current_win = vim.api.nvim_get_current_win()
   vim.api.nvim_set_current_win(winid)
   -- match table separators and leading row numbers
-  vim.cmd([[match NonText /^\s*\d\+\|─\|│\|┼/]])
+  vim.cmd([[match NonText /^│\=\s*\d\+\|─\|│\|┼\|┌\|┐\|└\|┘\|┬\|┴\|├\|┤/]])
   vim.api.nvim_set_current_win(current_win)
 end
 
@@ -118,7 +153,11 @@ end
 
 ---@private
 function ResultUI:display_progress()
-  self.stop_progress = progress.display(self.bufnr, self.progress_opts)
+  self.footer_line = nil
+  local call = self.current_call
+  self.stop_progress = progress.display(self.bufnr, self.progress_opts, function()
+    return self.handler:call_progress(call.id)
+  end)
 
   if self:has_window() then
     vim.api.nvim_set_current_win(self.winid)
@@ -153,6 +192,7 @@ function ResultUI:display_status()
     table.insert(lines, "    " .. string.gsub(self.current_call.error, "\n", " "))
   end
 
+  self.footer_line = nil
   vim.api.nvim_buf_set_option(self.bufnr, "modifiable", true)
   vim.api.nvim_buf_set_lines(self.bufnr, 0, -1, false, lines)
 
@@ -187,7 +227,18 @@ function ResultUI:display_result(page)
   local to = self.page_size * (page + 1)
 
   -- call go function
-  local length = self.handler:call_display_result(self.current_call.id, self.bufnr, from, to)
+  local style = self.table_style
+  if self.expanded then
+    style = "expanded"
+  end
+  local length = self.handler:call_display_result(self.current_call.id, self.bufnr, from, to, {
+    style = style,
+    columns = self.column_offsets,
+  })
+  self.displayed_range = { from = from, to = to }
+  self.footer_line = nil
+  self:apply_row_highlights()
+  self:display_footer()
 
   -- adjust page ammount
   self.page_ammount = math.floor(length / self.page_size)
@@ -216,6 +267,76 @@ function ResultUI:display_result(page)
   return page
 end
 
+-- Appends the footer line (rows shown, truncation, elapsed time or streaming state)
+-- to the displayed page or redraws it.
+---@private
+function ResultUI:display_footer()
+  vim.api.nvim_buf_clear_namespace(self.bufnr, footer_ns, 0, -1)
+  if not self.footer or not self.current_call then
+    return
+  end
+
+  local ok, text = pcall(
+    self.handler.call_result_footer,
+    self.handler,
+    self.current_call.id,
+    self.displayed_range.from,
+    self.displayed_range.to
+  )
+  if not ok then
+    return
+  end
+
+  local line = self.footer_line
+  if not line then
+    line = vim.api.nvim_buf_line_count(self.bufnr)
+  end
+
+  vim.api.nvim_buf_set_option(self.bufnr, "modifiable", true)
+  vim.api.nvim_buf_set_lines(self.bufnr, line, line + 1, false, { text })
+  vim.api.nvim_buf_set_option(self.bufnr, "modifiable", false)
+  vim.api.nvim_buf_set_option(self.bufnr, "modified", false)
+
+  self.footer_line = line
+  vim.api.nvim_buf_set_extmark(self.bufnr, footer_ns, line, 0, { line_hl_group = self.footer_highlight })
+end
+
+-- Removes the footer line from the buffer.
+---@private
+function ResultUI:remove_footer()
+  vim.api.nvim_buf_clear_namespace(self.bufnr, footer_ns, 0, -1)
+  if not self.footer_line then
+    return
+  end
+
+  vim.api.nvim_buf_set_option(self.bufnr, "modifiable", true)
+  vim.api.nvim_buf_set_lines(self.bufnr, self.footer_line, self.footer_line + 1, false, {})
+  vim.api.nvim_buf_set_option(self.bufnr, "modifiable", false)
+  vim.api.nvim_buf_set_option(self.bufnr, "modified", false)
+  self.footer_line = nil
+end
+
+-- Redraws the footer periodically while rows of the current call are retrieved.
+---@private
+function ResultUI:start_footer_updates()
+  self.stop_footer_updates()
+  if not self.footer then
+    return
+  end
+
+  local timer = vim.fn.timer_start(footer_interval, function()
+    if not self.current_call or self.current_call.state ~= "retrieving" then
+      self.stop_footer_updates()
+      return
+    end
+    self:display_footer()
+  end, { ["repeat"] = -1 })
+
+  self.stop_footer_updates = function()
+    pcall(vim.fn.timer_stop, timer)
+  end
+end
+
 ---@private
 ---@return table<string, fun()>
 function ResultUI:get_actions()
@@ -232,6 +353,15 @@ function ResultUI:get_actions()
     page_first = function()
       self:page_first()
     end,
+    toggle_table_style = function()
+      self:toggle_table_style()
+    end,
+    toggle_expanded = function()
+      self:toggle_expanded()
+    end,
+    toggle_footer = function()
+      self:toggle_footer()
+    end,
 
     -- yank functions
     yank_current_json = function()
@@ -252,6 +382,46 @@ function ResultUI:get_actions()
     yank_all_csv = function()
       self:store_all_wrapper("csv", vim.v.register)
     end,
+    yank_current_tsv = function()
+      self:store_current_wrapper("tsv", vim.v.register)
+    end,
+    yank_selection_tsv = function()
+      self:store_selection_wrapper("tsv", vim.v.register)
+    end,
+    yank_all_tsv = function()
+      self:store_all_wrapper("tsv", vim.v.register)
+    end,
+    yank_current_markdown = function()
+      self:store_current_wrapper("markdown", vim.v.register)
+    end,
+    yank_selection_markdown = function()
+      self:store_selection_wrapper("markdown", vim.v.register)
+    end,
+    yank_all_markdown = function()
+      self:store_all_wrapper("markdown", vim.v.register)
+    end,
+
+    sort_asc = function()
+      self:sort_current_column(true)
+    end,
+    sort_desc = function()
+      self:sort_current_column(false)
+    end,
+    select_columns = function()
+      self:select_columns()
+    end,
+    next_column = function()
+      self:jump_column(1)
+    end,
+    prev_column = function()
+      self:jump_column(-1)
+    end,
+    highlight_rows = function()
+      self:prompt_highlight_rows()
+    end,
+    clear_row_highlights = function()
+      self:clear_row_highlights()
+    end,
 
     cancel_call = function()
       if self.current_call then
@@ -275,10 +445,12 @@ end
 ---@param call CallDetails
 function ResultUI:set_call(call)
   self.page_index = 0
+  self.highlight_rule = nil
   self.page_ammount = 0
   self.current_call = call
 
   self.stop_progress()
+  self.stop_footer_updates()
 end
 
 -- Gets the currently displayed call.
@@ -299,6 +471,43 @@ function ResultUI:page_prev()
   self.page_index = self:display_result(self.page_index - 1)
 end
 
+-- Switches between borderless and box table style and redraws the current page.
+function ResultUI:toggle_table_style()
+  if self.table_style == "box" then
+    self.table_style = "borderless"
+  else
+    self.table_style = "box"
+  end
+
+  if self.current_call then
+    self:page_current()
+  end
+end
+
+-- Switches between table and expanded (record per row) display and redraws the current page.
+function ResultUI:toggle_expanded()
+  self.expanded = not self.expanded
+
+  if self.current_call then
+    self:page_current()
+  end
+end
+
+-- Shows or hides the footer line below the displayed page.
+function ResultUI:toggle_footer()
+  self.footer = not self.footer
+
+  if not self.footer then
+    self.stop_footer_updates()
+    self:remove_footer()
+  elseif self.current_call then
+    self:display_footer()
+    if self.current_call.state == "retrieving" then
+      self:start_footer_updates()
+    end
+  end
+end
+
 function ResultUI:page_last()
   self.page_index = self:display_result(self.page_ammount)
 end
@@ -307,6 +516,183 @@ function ResultUI:page_first()
   self.page_index = self:display_result(0)
 end
 
+-- Moves the cursor to the start of the next (or previous) column on the current line,
+-- using the column offsets stored while displaying the result.
+---@param direction integer 1 for the next column, -1 for the previous one
+function ResultUI:jump_column(direction)
+  if not self:has_window() then
+    error("result cannot operate without a valid window")
+  end
+  if not self.column_offsets then
+    error("column navigation requires the result.column_offsets option")
+  end
+
+  local row, col = unpack(vim.api.nvim_win_get_cursor(self.winid))
+  local ok, offsets = pcall(vim.api.nvim_buf_get_var, self.bufnr, "dbee_columns")
+  local line_offsets = ok and offsets[row] or {}
+
+  local target
+  for _, offset in ipairs(line_offsets) do
+    if direction > 0 and offset > col then
+      target = offset
+      break
+    elseif direction < 0 and offset < col then
+      target = offset
+    end
+  end
+  if target then
+    vim.api.nvim_win_set_cursor(self.winid, { row, target })
+  end
+end
+
+-- Sorts the result by the column under the cursor and redraws the first page.
+---@param ascending boolean
+function ResultUI:sort_current_column(ascending)
+  if not self.current_call then
+    error("no call set to result")
+  end
+  if not self:has_window() then
+    error("result cannot operate without a valid window")
+  end
+  if self.expanded then
+    error("sorting is not supported in expanded display")
+  end
+
+  -- columns are separated by vertical lines, the first column is the row index
+  local line = vim.api.nvim_get_current_line()
+  local col = vim.api.nvim_win_get_cursor(self.winid)[2]
+  local separators = 0
+  for _, sep in ipairs { "│", "┼", "├", "┤" } do
+    local _, n = line:sub(1, col + 1):gsub(sep, "")
+    separators = separators + n
+  end
+  if self.table_style == "box" then
+    separators = separators - 1
+  end
+  if separators < 1 then
+    error("cursor is not on a result column")
+  end
+
+  self.handler:call_sort_result(self.current_call.id, separators - 1, ascending)
+  self:page_first()
+end
+
+-- Prompts for a comma separated list of column names to display (empty displays all).
+function ResultUI:select_columns()
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  local id = self.current_call.id
+  common.float_prompt({ { name = "columns" } }, {
+    title = "Select Columns",
+    callback = function(res)
+      local names = {}
+      for name in (res.columns or ""):gmatch("[^,]+") do
+        name = vim.trim(name)
+        if name ~= "" then
+          table.insert(names, name)
+        end
+      end
+
+      local ignored = self.handler:call_select_columns(id, names)
+      if #ignored > 0 then
+        utils.log("warn", "ignoring unknown columns: " .. table.concat(ignored, ", "), "result")
+      end
+      self:page_current()
+    end,
+  })
+end
+
+-- Highlights rows of the result whose column value matches the predicate
+-- (e.g. "age", ">", "30") and redraws the current page. Replaces the previous rule.
+---@param column string
+---@param operator row_operator
+---@param value string
+function ResultUI:highlight_rows(column, operator, value)
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  self.highlight_rule = { column = column, operator = operator, value = value }
+  self:page_current()
+end
+
+-- Removes row highlights.
+function ResultUI:clear_row_highlights()
+  self.highlight_rule = nil
+  vim.api.nvim_buf_clear_namespace(self.bufnr, highlight_ns, 0, -1)
+end
+
+-- Prompts for a highlight condition in the form of "<column> <operator> <value>" (e.g. "age >= 30").
+---@private
+function ResultUI:prompt_highlight_rows()
+  if not self.current_call then
+    error("no call set to result")
+  end
+
+  common.float_prompt({ { name = "condition" } }, {
+    title = "Highlight Rows",
+    callback = function(res)
+      local condition = vim.trim(res.condition or "")
+      if condition == "" then
+        self:clear_row_highlights()
+        return
+      end
+
+      local column, operator, value = condition:match("^(%S-)%s*([=!<>~]+)%s*(.-)$")
+      if not column or column == "" then
+        error("invalid condition: " .. condition)
+      end
+      self:highlight_rows(column, operator, value)
+    end,
+  })
+end
+
+-- Applies the highlight rule to the displayed rows.
+---@private
+function ResultUI:apply_row_highlights()
+  vim.api.nvim_buf_clear_namespace(self.bufnr, highlight_ns, 0, -1)
+  -- matching waits for the whole result, so rows are highlighted once it's retrieved
+  if not self.highlight_rule or not self.current_call or self.current_call.state ~= "archived" then
+    return
+  end
+
+  local rule = self.highlight_rule
+  local ok, indexes =
+    pcall(self.handler.call_highlight_rows, self.handler, self.current_call.id, rule.column, rule.operator, rule.value)
+  if not ok then
+    self.highlight_rule = nil
+    utils.log("warn", "clearing row highlights: " .. tostring(indexes), "result")
+    return
+  end
+
+  -- displayed row numbers start with 1
+  local matching = {}
+  for _, index in ipairs(indexes) do
+    matching[index + 1] = true
+  end
+
+  -- in expanded display, all lines of a record are highlighted
+  local current
+  for i, line in ipairs(vim.api.nvim_buf_get_lines(self.bufnr, 0, -1, false)) do
+    local number
+    if self.expanded then
+      number = line:match("RECORD (%d+)")
+      if number then
+        current = tonumber(number)
+      end
+      number = current
+    else
+      number = tonumber(line:match("^%s*(%d+)") or line:match("^│%s*(%d+)"))
+    end
+
+    if number and matching[number] then
+      vim.api.nvim_buf_set_extmark(self.bufnr, highlight_ns, i - 1, 0, { line_hl_group = self.row_highlight })
+    end
+  end
+end
+
 -- wrapper for storing the current row
 ---@private
 ---@param format string
@@ -357,7 +743,7 @@ function ResultUI:store_selection_wrapper(format, register)
   )
 end
 
--- wrapper for storing all rows
+-- wrapper for storing all rows (up to the configured maximum)
 ---@private
 ---@param format string
 ---@param register string
@@ -365,14 +751,25 @@ function ResultUI:store_all_wrapper(format, register)
   if not self.current_call then
     error("no call set to result")
   end
-  self.handler:call_store_result(self.current_call.id, format, "yank", { extra_arg = register })
+
+  local rows, truncated =
+    self.handler:call_yank_result(self.current_call.id, format, register, { max_rows = self.yank_max_rows })
+  if truncated then
+    utils.log("warn", "yanked only the first " .. rows .. " rows (see result.yank_max_rows)", "result")
+  end
 end
 
 ---@private
 ---@return number # index of the current row
 function ResultUI:current_row_index()
+  -- in expanded display, rows are identified by record titles
+  local pattern = [[^\s*[0-9]\+]]
+  if self.expanded then
+    pattern = [=[\[ RECORD [0-9]\+ \]]=]
+  end
+
   -- get position of the current line identifier
-  local row = vim.fn.search([[^\s*[0-9]\+]], "bnc", 1)
+  local row = vim.fn.search(pattern, "bnc", 1)
   if row == 0 then
     error("couldn't retrieve current row number: row = 0")
   end
@@ -380,7 +777,7 @@ function ResultUI:current_row_index()
   -- get the line and extract the line number
   local line = vim.api.nvim_buf_get_lines(self.bufnr, row - 1, row, true)[1] or ""
 
-  local index = line:match("%d+")
+  local index = line:match("RECORD (%d+)") or line:match("%d+")
   if not index then
     error("couldn't retrieve current row number")
   end
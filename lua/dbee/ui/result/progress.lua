@@ -2,11 +2,32 @@ local M = {}
 
 ---@alias progress_config { text_prefix: string, spinner: string[] }
 
+---@param p Progress
+---@return string
+local function format_progress(p)
+  local parts = {}
+  if p.stage and p.stage ~= "" then
+    table.insert(parts, p.stage)
+  end
+  if p.rows_read > 0 or p.bytes_read > 0 then
+    local read = string.format("%d rows, %s read", p.rows_read, p.bytes_read_pretty)
+    if p.total_rows > 0 then
+      read = string.format("%s (%d%%)", read, math.min(100, math.floor(p.rows_read * 100 / p.total_rows)))
+    end
+    table.insert(parts, read)
+  end
+  if p.rows_written > 0 or p.bytes_written > 0 then
+    table.insert(parts, string.format("%d rows, %s written", p.rows_written, p.bytes_written_pretty))
+  end
+  return table.concat(parts, ", ")
+end
+
 --- Display an updated progress loader in the specified buffer
 ---@param bufnr integer -- buffer to display the progres in
 ---@param opts? progress_config
+---@param poll? fun():Progress? -- returns progress reported by the server (displayed if not nil)
 ---@return fun() # cancel function
-function M.display(bufnr, opts)
+function M.display(bufnr, opts, poll)
   if not bufnr then
     return function() end
   end
@@ -23,6 +44,12 @@ function M.display(bufnr, opts)
 
     vim.api.nvim_buf_set_option(bufnr, "modifiable", true)
     local line = string.format("%s %.3f seconds %s ", text_prefix, passed_time, spinner[icon_index])
+    if poll then
+      local ok, p = pcall(poll)
+      if ok and p then
+        line = line .. format_progress(p)
+      end
+    end
     vim.api.nvim_buf_set_lines(bufnr, 0, -1, false, { line })
     vim.api.nvim_buf_set_option(bufnr, "modifiable", false)
   end
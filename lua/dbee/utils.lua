@@ -40,6 +40,26 @@ function M.visual_selection()
   end
 end
 
+---Text of the last visual selection in the current buffer (empty if there is none).
+---@return string
+function M.last_selection()
+  local _, srow, scol, _ = unpack(vim.fn.getpos("'<"))
+  local _, erow, ecol, _ = unpack(vim.fn.getpos("'>"))
+  if srow == 0 or erow == 0 then
+    return ""
+  end
+  if srow > erow or (srow == erow and scol > ecol) then
+    srow, scol, erow, ecol = erow, ecol, srow, scol
+  end
+
+  -- clamp the end column of linewise selections to the end of the line
+  local last = vim.api.nvim_buf_get_lines(0, erow - 1, erow, false)[1] or ""
+  ecol = math.min(ecol, #last)
+
+  local lines = vim.api.nvim_buf_get_text(0, srow - 1, scol - 1, erow - 1, ecol, {})
+  return table.concat(lines, "\n")
+end
+
 ---@param level "info"|"warn"|"error"
 ---@param message string
 ---@param subtitle? string
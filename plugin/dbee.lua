@@ -20,6 +20,42 @@ local commands = {
 
     require("dbee").store(args[1], args[2], { extra_arg = args[3] })
   end,
+  analyze = function(args)
+    if #args < 1 then
+      error("no path provided")
+    end
+
+    require("dbee").analyze(table.concat(args, " "))
+  end,
+  federate = function(args)
+    if #args < 1 then
+      error("no query provided")
+    end
+
+    require("dbee").federate(table.concat(args, " "))
+  end,
+  listen = function(args)
+    if #args < 1 then
+      error("no channel provided")
+    end
+
+    require("dbee").listen(args[1])
+  end,
+  unlisten = function(args)
+    if #args < 1 then
+      error("no channel provided")
+    end
+
+    require("dbee").unlisten(args[1])
+  end,
+  yank = function(args)
+    -- args are "format" and optional "register"
+    if #args < 1 then
+      error("not enough arguments, got " .. #args .. " want at least 1")
+    end
+
+    require("dbee").yank(args[1], args[2])
+  end,
 }
 
 ---@param args string args in form of Dbee arg1 arg2 ...
@@ -72,6 +108,19 @@ end, {
       return vim.tbl_keys(commands)
     end
 
+    local formats = { "csv", "tsv", "markdown", "json", "yaml", "table" }
+
+    if line[1] == "analyze" then
+      return vim.fn.getcompletion(line[2] or "", "file")
+    end
+
+    if line[1] == "yank" then
+      if #line == 1 then
+        return formats
+      end
+      return {}
+    end
+
     if line[1] ~= "store" then
       return {}
     end
@@ -79,7 +128,7 @@ end, {
     local nargs = #line
     if nargs == 1 then
       -- format
-      return { "csv", "json", "table" }
+      return formats
     elseif nargs == 2 then
       -- output
       return { "file", "yank", "buffer" }